@@ -0,0 +1,437 @@
+// Package client is a Go client for the evaluation pipeline's HTTP API
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+)
+
+// Config configures a client. APIKey, Token and ProjectID are optional:
+// requests are sent with whichever credentials the server requires.
+type Config struct {
+	BaseURL   string        // e.g. http://localhost:8080
+	APIKey    string        // Sent as X-API-Key
+	Token     string        // Sent as a bearer token
+	ProjectID string        // Sent as X-Project-ID; ignored for project-bound API keys
+	Timeout   time.Duration // Per request; defaults to 30s
+}
+
+// Client calls the evaluation pipeline API
+type Client struct {
+	baseURL    string
+	apiKey     string
+	token      string
+	projectID  string
+	httpClient *http.Client
+}
+
+// Error is a non-2xx response of the API
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// New creates a client
+func New(cfg Config) *Client {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		apiKey:     cfg.APIKey,
+		token:      cfg.Token,
+		projectID:  cfg.ProjectID,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// TriggerResponse is the answer to a triggered evaluation
+type TriggerResponse struct {
+	TaskID         string                    `json:"task_id"`
+	ConversationID string                    `json:"conversation_id"`
+	Status         string                    `json:"status"` // queued or scheduled
+	EvaluatorTypes []string                  `json:"evaluator_types"`
+	Warnings       []models.EvaluatorWarning `json:"warnings"`
+	ScheduledAt    *time.Time                `json:"scheduled_at,omitempty"`
+}
+
+// EvaluationFilter selects evaluations to list, newest first
+type EvaluationFilter struct {
+	ConversationID string
+	TriggerSource  string
+	Verdict        string
+	MinScore       *float64
+	MaxScore       *float64
+	Limit          int
+	Offset         int
+}
+
+// IngestConversations stores conversations in one batch and returns the IDs
+// of those ingested. Conversations the server rejects are left out.
+func (c *Client) IngestConversations(ctx context.Context, convs []models.ConversationCreate, autoEvaluate bool) (*models.BatchIngestResponse, error) {
+	query := url.Values{"auto_evaluate": {strconv.FormatBool(autoEvaluate)}}
+	var resp models.BatchIngestResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/conversations/batch", query, convs, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ImportConversations streams conversations, one JSON object per line, to be
+// ingested as they arrive, and returns the outcome of each line
+func (c *Client) ImportConversations(ctx context.Context, jsonl io.Reader, autoEvaluate bool) (*models.StreamImportResponse, error) {
+	query := url.Values{"auto_evaluate": {strconv.FormatBool(autoEvaluate)}}
+	req, err := c.newRequest(ctx, http.MethodPost, "/api/v1/conversations/import", query, jsonl)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	// Imports can take longer than the per request timeout
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+
+	var resp models.StreamImportResponse
+	if err := c.send(&httpClient, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UploadConversations uploads a JSONL or CSV conversation file, which the
+// server imports in the background. The file is streamed rather than read
+// into memory; poll the returned import with GetConversationImport.
+func (c *Client) UploadConversations(ctx context.Context, filename string, file io.Reader, autoEvaluate bool) (*models.ConversationImport, error) {
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		part, err := form.CreateFormFile("file", filename)
+		if err == nil {
+			_, err = io.Copy(part, file)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	query := url.Values{"auto_evaluate": {strconv.FormatBool(autoEvaluate)}}
+	req, err := c.newRequest(ctx, http.MethodPost, "/api/v1/conversations/upload", query, body)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	// Uploads can take longer than the per request timeout
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+
+	var imp models.ConversationImport
+	if err := c.send(&httpClient, req, &imp); err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// GetConversationImport returns the progress of a conversation file import
+func (c *Client) GetConversationImport(ctx context.Context, importID string) (*models.ConversationImport, error) {
+	var imp models.ConversationImport
+	if err := c.do(ctx, http.MethodGet, "/api/v1/conversations/imports/"+url.PathEscape(importID), nil, nil, &imp); err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// TriggerEvaluation queues an evaluation of a conversation
+func (c *Client) TriggerEvaluation(ctx context.Context, req models.EvaluationRequest) (*TriggerResponse, error) {
+	var resp TriggerResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/evaluations/trigger", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetTask returns the status of a queued evaluation task
+func (c *Client) GetTask(ctx context.Context, taskID string) (*queue.TaskStatus, error) {
+	var status queue.TaskStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/tasks/"+url.PathEscape(taskID), nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// WaitForTask polls a task every interval until it completes or fails, and
+// returns its final status
+func (c *Client) WaitForTask(ctx context.Context, taskID string, interval time.Duration) (*queue.TaskStatus, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := c.GetTask(ctx, taskID)
+		if err != nil {
+			return nil, err
+		}
+		if status.State == queue.TaskStateCompleted || status.State == queue.TaskStateFailed {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetEvaluation returns an evaluation
+func (c *Client) GetEvaluation(ctx context.Context, evaluationID string) (*models.EvaluationResponse, error) {
+	var eval models.EvaluationResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/evaluations/"+url.PathEscape(evaluationID), nil, nil, &eval); err != nil {
+		return nil, err
+	}
+	return &eval, nil
+}
+
+// ListEvaluations lists evaluations, newest first
+func (c *Client) ListEvaluations(ctx context.Context, filter EvaluationFilter) ([]models.EvaluationResponse, error) {
+	query := url.Values{}
+	if filter.ConversationID != "" {
+		query.Set("conversation_id", filter.ConversationID)
+	}
+	if filter.TriggerSource != "" {
+		query.Set("trigger_source", filter.TriggerSource)
+	}
+	if filter.Verdict != "" {
+		query.Set("verdict", filter.Verdict)
+	}
+	if filter.MinScore != nil {
+		query.Set("min_score", strconv.FormatFloat(*filter.MinScore, 'f', -1, 64))
+	}
+	if filter.MaxScore != nil {
+		query.Set("max_score", strconv.FormatFloat(*filter.MaxScore, 'f', -1, 64))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		query.Set("offset", strconv.Itoa(filter.Offset))
+	}
+
+	var resp struct {
+		Evaluations []models.EvaluationResponse `json:"evaluations"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v2/evaluations", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Evaluations, nil
+}
+
+// GetStats returns system statistics, as of asOf unless it is zero
+func (c *Client) GetStats(ctx context.Context, asOf time.Time) (*models.SystemStats, error) {
+	query := url.Values{}
+	if !asOf.IsZero() {
+		query.Set("as_of", asOf.UTC().Format(time.RFC3339))
+	}
+	var stats models.SystemStats
+	if err := c.do(ctx, http.MethodGet, "/api/v1/stats", query, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// ListSuggestions lists pending improvement suggestions with at least
+// minConfidence, of one type unless suggestionType is empty
+func (c *Client) ListSuggestions(ctx context.Context, minConfidence float64, suggestionType string) ([]models.StoredSuggestion, error) {
+	query := url.Values{"min_confidence": {strconv.FormatFloat(minConfidence, 'f', -1, 64)}}
+	if suggestionType != "" {
+		query.Set("suggestion_type", suggestionType)
+	}
+
+	var resp struct {
+		Suggestions []models.StoredSuggestion `json:"suggestions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/improvements/suggestions", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Suggestions, nil
+}
+
+// AnalyzeSuggestions analyzes the failures of the last lookbackDays and
+// generates improvement suggestions. The analysis is returned as the
+// evaluator service reports it.
+func (c *Client) AnalyzeSuggestions(ctx context.Context, lookbackDays int) (json.RawMessage, error) {
+	query := url.Values{"lookback_days": {strconv.Itoa(lookbackDays)}}
+	var result json.RawMessage
+	if err := c.do(ctx, http.MethodPost, "/api/v1/improvements/analyze", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// MarkSuggestionImplemented marks a suggestion implemented, recording the
+// metrics before the change to measure its impact against
+func (c *Client) MarkSuggestionImplemented(ctx context.Context, suggestionID string, beforeMetrics map[string]interface{}) error {
+	body := map[string]interface{}{"before_metrics": beforeMetrics}
+	return c.do(ctx, http.MethodPost, "/api/v1/improvements/suggestions/"+url.PathEscape(suggestionID)+"/implement", nil, body, nil)
+}
+
+// StartDatasetRun queues evaluations of a dataset's items for an agent
+// version
+func (c *Client) StartDatasetRun(ctx context.Context, datasetID int64, req models.DatasetRunCreate) (*models.DatasetRun, error) {
+	var run models.DatasetRun
+	if err := c.do(ctx, http.MethodPost, datasetPath(datasetID)+"/runs", nil, req, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// GetDatasetRun returns a dataset run with the outcome of each item
+func (c *Client) GetDatasetRun(ctx context.Context, datasetID, runID int64) (*models.DatasetRun, error) {
+	var run models.DatasetRun
+	if err := c.do(ctx, http.MethodGet, datasetPath(datasetID)+"/runs/"+strconv.FormatInt(runID, 10), nil, nil, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// WaitForDatasetRun polls a dataset run every interval until it completes,
+// and returns it
+func (c *Client) WaitForDatasetRun(ctx context.Context, datasetID, runID int64, interval time.Duration) (*models.DatasetRun, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		run, err := c.GetDatasetRun(ctx, datasetID, runID)
+		if err != nil {
+			return nil, err
+		}
+		if run.Status == models.DatasetRunCompleted {
+			return run, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return run, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CompareDatasetRuns compares a candidate run of a dataset to a base run.
+// Zero IDs select the latest run as candidate and the latest completed run
+// before it as base; a negative threshold selects the server's default.
+func (c *Client) CompareDatasetRuns(ctx context.Context, datasetID, baseID, candidateID int64, threshold float64) (*models.DatasetRunComparison, error) {
+	query := url.Values{}
+	if baseID > 0 {
+		query.Set("base", strconv.FormatInt(baseID, 10))
+	}
+	if candidateID > 0 {
+		query.Set("candidate", strconv.FormatInt(candidateID, 10))
+	}
+	if threshold >= 0 {
+		query.Set("threshold", strconv.FormatFloat(threshold, 'f', -1, 64))
+	}
+
+	var comparison models.DatasetRunComparison
+	if err := c.do(ctx, http.MethodGet, datasetPath(datasetID)+"/runs/compare", query, nil, &comparison); err != nil {
+		return nil, err
+	}
+	return &comparison, nil
+}
+
+func datasetPath(datasetID int64) string {
+	return "/api/v1/datasets/" + strconv.FormatInt(datasetID, 10)
+}
+
+// do sends a JSON request and decodes the JSON response into out, if any
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.send(c.httpClient, req, out)
+}
+
+// newRequest creates a request carrying the client's credentials
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.projectID != "" {
+		req.Header.Set("X-Project-ID", c.projectID)
+	}
+	return req, nil
+}
+
+// send sends a request and decodes the JSON response into out, if any.
+// Error responses are returned as *Error.
+func (c *Client) send(httpClient *http.Client, req *http.Request, out interface{}) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var body struct {
+			Error string `json:"error"`
+		}
+		message := strings.TrimSpace(string(detail))
+		if json.Unmarshal(detail, &body) == nil && body.Error != "" {
+			message = body.Error
+		}
+		return &Error{StatusCode: resp.StatusCode, Message: message}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
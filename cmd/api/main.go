@@ -2,20 +2,44 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/ai-agent-eval/internal/api"
 	"github.com/ai-agent-eval/internal/config"
 	"github.com/ai-agent-eval/internal/database"
+	"github.com/ai-agent-eval/internal/faults"
+	"github.com/ai-agent-eval/internal/notify"
 	"github.com/ai-agent-eval/internal/queue"
+	"github.com/ai-agent-eval/internal/reevaluation"
+	"github.com/ai-agent-eval/internal/repository"
+	"github.com/ai-agent-eval/internal/scheduler"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/ai-agent-eval/internal/slack"
+	"github.com/ai-agent-eval/internal/telemetry"
+	"github.com/ai-agent-eval/internal/tracker"
+	"github.com/ai-agent-eval/internal/webhook"
+	"github.com/ai-agent-eval/internal/worker"
+	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
+// Subsystems that can be selected with --role
+const (
+	roleAPI       = "api"
+	roleWorker    = "worker"
+	roleScheduler = "scheduler"
+	roleAll       = "all"
+)
+
 // @title AI Agent Evaluation Pipeline API
 // @version 1.0
 // @description High-performance evaluation pipeline for AI agents
@@ -30,6 +54,23 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	role := flag.String("role", cfg.Role, "Subsystems to run: api, worker, scheduler or all (comma separated)")
+	flag.Parse()
+
+	roles, err := parseRoles(*role)
+	if err != nil {
+		log.Fatalf("Invalid role: %v", err)
+	}
+
+	// An empty secret would accept tokens anyone can sign
+	if cfg.JWTAuthEnabled && cfg.JWTSecret == "" {
+		log.Fatalf("JWT_SECRET is required when JWT_AUTH_ENABLED is set")
+	}
+
+	if err := services.RegisterRemoteEvaluators(services.Evaluators, cfg.CustomEvaluatorURLs); err != nil {
+		log.Fatalf("Invalid custom evaluator: %v", err)
+	}
+
 	// Initialize database
 	db, err := database.New(cfg.DatabaseURL, cfg.DBMaxConnections, cfg.DBMaxIdle)
 	if err != nil {
@@ -38,7 +79,7 @@ func main() {
 	defer db.Close()
 
 	// Run migrations
-	if err := database.Migrate(db); err != nil {
+	if err := database.Migrate(db.DB); err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
@@ -48,41 +89,535 @@ func main() {
 		log.Fatalf("Failed to connect to Redis: %v", err)
 	}
 	defer redisQueue.Close()
+	redisQueue.SetCompressionThreshold(cfg.TaskCompressionThreshold)
+
+	// Fault injection is for resilience testing and never runs in release mode
+	injector := newFaultInjector(cfg)
+	db.SetFaults(injector)
+	redisQueue.SetFaults(injector)
+
+	// Graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var wg sync.WaitGroup
+	run := func(name string, fn func(ctx context.Context) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(ctx); err != nil {
+				log.Fatalf("%s failed: %v", name, err)
+			}
+			log.Printf("%s stopped", name)
+		}()
+	}
+
+	// One metrics exporter for the API and worker of this process
+	metrics := telemetry.New(cfg)
+	if metrics != nil {
+		run("Metrics exporter", func(ctx context.Context) error {
+			metrics.Run(ctx)
+			return nil
+		})
+	}
+
+	if roles[roleAPI] {
+		run("API server", func(ctx context.Context) error {
+			return runAPI(ctx, cfg, db, redisQueue, injector, metrics)
+		})
+	}
+	if roles[roleScheduler] {
+		run("Scheduler", func(ctx context.Context) error {
+			return runScheduler(ctx, cfg, db, redisQueue)
+		})
+	}
+	if roles[roleWorker] {
+		run("Worker", func(ctx context.Context) error {
+			w := worker.New(cfg, db, redisQueue)
+			w.SetFaults(injector)
+			w.SetMetrics(metrics)
+			return w.Run(ctx)
+		})
+	}
 
+	wg.Wait()
+	log.Println("Exited gracefully")
+}
+
+// parseRoles parses a comma separated role list into a set
+func parseRoles(value string) (map[string]bool, error) {
+	roles := make(map[string]bool)
+	for _, role := range strings.Split(value, ",") {
+		switch role = strings.TrimSpace(role); role {
+		case roleAll:
+			roles[roleAPI] = true
+			roles[roleWorker] = true
+			roles[roleScheduler] = true
+		case roleAPI, roleWorker, roleScheduler:
+			roles[role] = true
+		default:
+			return nil, fmt.Errorf("unknown role %q", role)
+		}
+	}
+	return roles, nil
+}
+
+// newFaultInjector returns the fault injector when fault injection is enabled
+// outside release mode, and nil otherwise
+func newFaultInjector(cfg *config.Config) *faults.Injector {
+	if !cfg.FaultInjectionEnabled {
+		return nil
+	}
+	if cfg.GinMode == gin.ReleaseMode {
+		log.Println("Fault injection is enabled but refused in release mode")
+		return nil
+	}
+	log.Println("WARNING: Fault injection enabled: faults can be injected through /api/v1/admin/faults")
+	return faults.NewInjector()
+}
+
+// runAPI serves the HTTP API until ctx is cancelled
+func runAPI(ctx context.Context, cfg *config.Config, db *database.DB, redisQueue *queue.RedisQueue, injector *faults.Injector, metrics *telemetry.Exporter) error {
 	// Create API server
 	server := api.NewServer(cfg, db, redisQueue)
+	server.SetFaults(injector)
+	server.SetMetrics(metrics)
 
-	// Create HTTP server
-	httpServer := &http.Server{
+	// Pick up configuration changes made through other replicas
+	go func() {
+		if err := server.ListenForInvalidations(ctx); err != nil {
+			log.Printf("Cache invalidation listener stopped: %v", err)
+		}
+	}()
+
+	// Stream activity from every replica and worker to WebSocket clients
+	go func() {
+		if err := server.ListenForActivity(ctx); err != nil {
+			log.Printf("Activity listener stopped: %v", err)
+		}
+	}()
+
+	// Create HTTP servers
+	servers := []*http.Server{{
 		Addr:         cfg.ServerHost + ":" + cfg.ServerPort,
 		Handler:      server.Router(),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
+	}}
+	if cfg.PublicAPIPort != "" {
+		servers = append(servers, &http.Server{
+			Addr:         cfg.ServerHost + ":" + cfg.PublicAPIPort,
+			Handler:      server.PublicRouter(),
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 15 * time.Second,
+			IdleTimeout:  30 * time.Second,
+		})
 	}
 
-	// Start server in goroutine
-	go func() {
-		log.Printf("🚀 Server starting on %s:%s", cfg.ServerHost, cfg.ServerPort)
-		log.Printf("📚 API Docs available at http://%s:%s/swagger/index.html", cfg.ServerHost, cfg.ServerPort)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
-		}
-	}()
+	errCh := make(chan error, len(servers))
+	log.Printf("🚀 Server starting on %s:%s", cfg.ServerHost, cfg.ServerPort)
+	log.Printf("📚 API Docs available at http://%s:%s/swagger/index.html", cfg.ServerHost, cfg.ServerPort)
+	if cfg.PublicAPIPort != "" {
+		log.Printf("Public read-only API starting on %s:%s", cfg.ServerHost, cfg.PublicAPIPort)
+	}
+	for _, httpServer := range servers {
+		go func(httpServer *http.Server) {
+			if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				errCh <- err
+			}
+		}(httpServer)
+	}
 
-	// Graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
 
 	log.Println("Shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := httpServer.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	var shutdownErr error
+	for _, httpServer := range servers {
+		if err := httpServer.Shutdown(shutdownCtx); err != nil && shutdownErr == nil {
+			shutdownErr = err
+		}
+	}
+	return shutdownErr
+}
+
+// schedulerMaintenanceCheckInterval is how long the scheduler may take to
+// notice that maintenance mode was turned on or off
+const schedulerMaintenanceCheckInterval = 2 * time.Second
+
+// runScheduler runs periodic pipeline jobs until ctx is cancelled. Jobs are
+// skipped during maintenance.
+func runScheduler(ctx context.Context, cfg *config.Config, db *database.DB, redisQueue *queue.RedisQueue) error {
+	repo := repository.New(db)
+	evaluatorSvc := services.NewEvaluatorService(cfg.EvaluatorServiceURL, nil)
+	pipeline := services.NewConfigStore(cfg, services.NewToolRegistry(cfg.ToolLatencySLAs, cfg.LatencyThresholdMS))
+
+	// Jobs write to Postgres and queue tasks, so they pause with the queues
+	// during read-only maintenance
+	maintenance := queue.NewMaintenanceCache(redisQueue, schedulerMaintenanceCheckInterval)
+	s := scheduler.New()
+	s.SkipWhile(func() bool { return maintenance.Get() != nil })
+	s.Add(scheduler.Job{
+		Name:     "pattern_analysis",
+		Interval: cfg.AnalysisInterval,
+		Run: func(ctx context.Context) error {
+			_, err := evaluatorSvc.AnalyzePatterns(7)
+			return err
+		},
+	})
+	if cfg.MetaEvalEnabled {
+		s.Add(scheduler.Job{
+			Name:     "evaluator_calibration",
+			Interval: cfg.CalibrationInterval,
+			Run: func(ctx context.Context) error {
+				_, err := evaluatorSvc.CalibrateEvaluators(30)
+				return err
+			},
+		})
+	}
+
+	s.Add(scheduler.Job{
+		Name:     "evaluator_rollouts",
+		Interval: cfg.RolloutInterval,
+		Run: func(ctx context.Context) error {
+			decided, err := repo.DecideEvaluatorRollouts()
+			for _, rollout := range decided {
+				log.Printf("Evaluator rollout %d (%s %s) %s: %s", rollout.ID, rollout.EvaluatorType,
+					rollout.CandidateVersion, rollout.Status, rollout.DecisionReason.String)
+			}
+			return err
+		},
+	})
+	s.Add(scheduler.Job{
+		Name:     "annotator_specializations",
+		Interval: cfg.SpecializationInterval,
+		Run: func(ctx context.Context) error {
+			_, err := repo.RefreshAnnotatorSpecializations(cfg.SpecializationMinSamples, cfg.SpecializationMinAccuracy)
+			return err
+		},
+	})
+	s.Add(scheduler.Job{
+		Name:     "conversation_health",
+		Interval: cfg.HealthInterval,
+		Run: func(ctx context.Context) error {
+			policy := services.DefaultHealthPolicy
+			bundle, err := repo.GetLatestConfigBundle()
+			if err != nil {
+				return err
+			}
+			if bundle != nil && bundle.Config.Health.SeverityPenalties != nil {
+				policy = bundle.Config.Health
+			}
+
+			ids, err := repo.ListStaleHealthConversations(cfg.BatchSize)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if _, err := repo.RefreshConversationHealth(id, policy); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+	s.Add(scheduler.Job{
+		Name:     "issue_ownership",
+		Interval: cfg.OwnershipInterval,
+		Run: func(ctx context.Context) error {
+			bundle, err := repo.GetLatestConfigBundle()
+			if err != nil || bundle == nil {
+				return err
+			}
+			policy := bundle.Config.Ownership
+			if len(policy.Tools) == 0 && len(policy.IssueTypes) == 0 && policy.DefaultOwner == "" {
+				// Leave issues unassigned until owners are configured
+				return nil
+			}
+
+			if _, err := repo.AssignIssueOwners(policy, cfg.BatchSize); err != nil {
+				return err
+			}
+			_, err = repo.AssignPatternOwners(policy)
+			return err
+		},
+	})
+	s.Add(scheduler.Job{
+		Name:     "annotation_coverage",
+		Interval: cfg.CoverageInterval,
+		Run: func(ctx context.Context) error {
+			if err := loadPipelineConfig(repo, pipeline); err != nil {
+				return err
+			}
+
+			created, err := repo.GenerateAnnotationTasks(pipeline.Get().Routing, cfg.BatchSize)
+			if created > 0 {
+				log.Printf("Assigned %d annotation tasks to reach minimum annotator counts", created)
+			}
+			return err
+		},
+	})
+	if cfg.StaleReevaluationSampleSize > 0 {
+		refresher := reevaluation.New(cfg, repo, redisQueue, pipeline)
+		s.Add(scheduler.Job{
+			Name:     "stale_reevaluation",
+			Interval: cfg.StaleReevaluationInterval,
+			Run: func(ctx context.Context) error {
+				if err := loadPipelineConfig(repo, pipeline); err != nil {
+					return err
+				}
+
+				result, err := refresher.Run(ctx)
+				if result.Detected {
+					log.Printf("Scoring configuration changed to %s; re-evaluating a sample of older conversations", result.Fingerprint)
+				}
+				if result.Enqueued > 0 {
+					log.Printf("Queued %d stale conversations for re-evaluation", result.Enqueued)
+				}
+				return err
+			},
+		})
+	}
+	dispatcher := webhook.NewDispatcher(cfg, repo)
+	slackNotifier := slack.New(cfg, repo)
+	notifier := notify.New(slackNotifier, dispatcher, redisQueue)
+	s.Add(scheduler.Job{
+		Name:     "webhook_deliveries",
+		Interval: cfg.WebhookDeliveryInterval,
+		Run: func(ctx context.Context) error {
+			_, _, err := dispatcher.Deliver(ctx)
+			return err
+		},
+	})
+	if cfg.ReviewReprioritizeInterval > 0 {
+		s.Add(scheduler.Job{
+			Name:     "review_reprioritization",
+			Interval: cfg.ReviewReprioritizeInterval,
+			Run: func(ctx context.Context) error {
+				tasks, err := repo.ListOpenAnnotationTasks()
+				if err != nil {
+					return err
+				}
+				escalated, err := repo.EscalateAnnotationTasks(services.TasksToEscalate(tasks, time.Now().UTC(), cfg.ReviewAtRiskFraction))
+				if escalated > 0 {
+					log.Printf("Raised %d annotation tasks nearing their deadline to high priority", escalated)
+				}
+				return err
+			},
+		})
+	}
+	s.Add(scheduler.Job{
+		Name:     "owner_digests",
+		Interval: cfg.DigestInterval,
+		Run: func(ctx context.Context) error {
+			prefs, err := repo.ListNotificationPreferences("")
+			if err != nil {
+				return err
+			}
+
+			now := time.Now().UTC()
+			unresolved := false
+			for i := range prefs {
+				pref := &prefs[i]
+				if !services.DigestDue(pref, now) || services.InQuietHours(pref, now) {
+					continue
+				}
+
+				since := services.DigestSince(pref, now)
+				projectRepo := repo.ForProject(pref.ProjectID)
+				issues, err := projectRepo.ListOwnerIssues(pref.Team, "", since, false, 0, 0)
+				if err != nil {
+					return err
+				}
+				patterns, err := projectRepo.GetFailurePatterns(&unresolved, "", pref.Team, 10)
+				if err != nil {
+					return err
+				}
+
+				// An undelivered digest isn't marked sent, so it is retried
+				// on the next run
+				digest := services.BuildOwnerDigest(pref, issues, patterns, since, now)
+				if digest.IssueCount > 0 || len(digest.UnresolvedPatterns) > 0 {
+					if err := notifier.Digest(ctx, &digest); err != nil {
+						return fmt.Errorf("failed to deliver digest for %s/%s: %w", pref.ProjectID, pref.Team, err)
+					}
+				}
+				if err := repo.MarkDigestSent(pref.ID, now); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+	issueTracker, err := tracker.New(cfg)
+	if err != nil {
+		log.Printf("Issue tracker sync disabled: %v", err)
+	}
+	if issueTracker != nil {
+		syncer := tracker.NewSyncer(repo, issueTracker, tracker.Thresholds{
+			MinSeverity:    cfg.TrackerMinSeverity,
+			MinOccurrences: cfg.TrackerMinOccurrences,
+		})
+		s.Add(scheduler.Job{
+			Name:     "issue_tracker_sync",
+			Interval: cfg.TrackerSyncInterval,
+			Run: func(ctx context.Context) error {
+				result, err := syncer.Sync(ctx, cfg.BatchSize)
+				if result != nil && (result.Created > 0 || result.ClosedRemote > 0 || result.ResolvedLocal > 0) {
+					log.Printf("Issue tracker sync: %d created, %d updated, %d closed, %d reopened, %d resolved locally",
+						result.Created, result.Updated, result.ClosedRemote, result.ReopenedRemote, result.ResolvedLocal)
+				}
+				return err
+			},
+		})
+	}
+	if slackNotifier != nil {
+		s.Add(scheduler.Job{
+			Name:     "slack_pattern_alerts",
+			Interval: cfg.SlackPatternInterval,
+			Run: func(ctx context.Context) error {
+				posted, err := slackNotifier.NotifyPatterns(ctx, cfg.BatchSize)
+				if posted > 0 {
+					log.Printf("Posted %d failure patterns to Slack", posted)
+				}
+				return err
+			},
+		})
+	}
+	s.Add(scheduler.Job{
+		Name:     "quality_anomalies",
+		Interval: cfg.AnomalyInterval,
+		Run: func(ctx context.Context) error {
+			if err := loadPipelineConfig(repo, pipeline); err != nil {
+				return err
+			}
+			alerts := pipeline.Get().Alerts
+			anomalyPolicy := services.DefaultAnomalyPolicy
+			anomalyPolicy.ZThreshold = alerts.AnomalyZThreshold
+			anomalyPolicy.MinEvaluations = alerts.AnomalyMinEvaluations
+
+			// Only complete hours are checked
+			until := time.Now().UTC().Truncate(time.Hour)
+			buckets, err := repo.GetHourlyQuality(until.Add(-cfg.AnomalyLookback), until)
+			if err != nil {
+				return err
+			}
+
+			recorded, err := repo.RecordAnomalies(services.DetectAnomalies(buckets, anomalyPolicy))
+			if err != nil {
+				return err
+			}
+			for _, event := range recorded {
+				// Older anomalies found on the first run are recorded without alerting
+				if until.Sub(event.Bucket) > 24*time.Hour {
+					continue
+				}
+				alert := services.NewAnomalyAlert(event)
+				log.Printf("ALERT: %s", alert.Alert)
+				if err := deliverAlert(ctx, repo, notifier, event.Severity, alert.Alert, alert); err != nil {
+					log.Printf("Failed to deliver quality anomaly alert: %v", err)
+				}
+			}
+			return nil
+		},
+	})
+	s.Add(scheduler.Job{
+		Name:     "annotation_reliability",
+		Interval: cfg.ReliabilityInterval,
+		Run: func(ctx context.Context) error {
+			if err := loadPipelineConfig(repo, pipeline); err != nil {
+				return err
+			}
+			alerts := pipeline.Get().Alerts
+			reliabilityPolicy := services.ReliabilityPolicy{
+				KappaThreshold: alerts.ReliabilityKappaThreshold,
+				MinItems:       alerts.ReliabilityMinItems,
+			}
+
+			// Weekly periods smooth out the small daily samples of most types
+			now := time.Now().UTC()
+			counts, err := repo.GetReliabilityLabelCounts(now.AddDate(0, 0, -28), services.ReliabilityWeekly, "")
+			if err != nil {
+				return err
+			}
+
+			current := services.ReliabilityPeriodStart(services.ReliabilityWeekly, now)
+			drops := services.DetectReliabilityDrops(services.ReliabilityTrend(counts, reliabilityPolicy), current, reliabilityPolicy)
+			recorded, err := repo.RecordReliabilityEvents(drops)
+			if err != nil {
+				return err
+			}
+			for _, event := range recorded {
+				alert := services.NewReliabilityAlert(event)
+				log.Printf("ALERT: %s", alert.Alert)
+				if err := deliverAlert(ctx, repo, notifier, services.ReliabilityAlertSeverity, alert.Alert, alert); err != nil {
+					log.Printf("Failed to deliver annotation reliability alert: %v", err)
+				}
+			}
+			return nil
+		},
+	})
+	s.Add(scheduler.Job{
+		Name:     "queue_lag",
+		Interval: cfg.QueueLagCheckInterval,
+		Run: func(ctx context.Context) error {
+			if err := loadPipelineConfig(repo, pipeline); err != nil {
+				return err
+			}
+			health, err := services.CollectQueueHealth(redisQueue, repo, pipeline.Get().Alerts.QueueLagThresholds)
+			if err != nil {
+				return err
+			}
+			for _, h := range health {
+				if !h.Lagging {
+					continue
+				}
+				log.Printf("ALERT: %s", h.Alert)
+				if err := deliverAlert(ctx, repo, notifier, services.QueueLagSeverity, h.Alert, h); err != nil {
+					log.Printf("Failed to deliver queue lag alert: %v", err)
+				}
+			}
+			return nil
+		},
+	})
+
+	return s.Run(ctx)
+}
+
+// loadPipelineConfig applies the latest stored configuration bundle, if
+// there is one, so jobs see changes imported on any replica
+func loadPipelineConfig(repo *repository.Repository, pipeline *services.ConfigStore) error {
+	bundle, err := repo.GetLatestConfigBundle()
+	if err != nil {
+		return err
+	}
+	if bundle != nil {
+		pipeline.Set(bundle.Config)
 	}
+	return nil
+}
 
-	log.Println("Server exited gracefully")
+// deliverAlert delivers an alert on the channels of each team whose
+// notification preferences accept its severity now. Every team is attempted;
+// the errors of those that failed are returned together.
+func deliverAlert(ctx context.Context, repo *repository.Repository, notifier *notify.Notifier, severity, message string, alert interface{}) error {
+	prefs, err := repo.ListNotificationPreferences("")
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, delivery := range services.RouteAlert(prefs, severity, message, alert, time.Now().UTC()) {
+		if err := notifier.Alert(ctx, &delivery); err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", delivery.ProjectID, delivery.Team, err))
+		}
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ai-agent-eval/client"
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+)
+
+// command is an evalctl subcommand. It parses its own flags from args.
+type command struct {
+	summary string
+	run     func(ctx context.Context, c *client.Client, args []string) error
+}
+
+var commands = map[string]command{
+	"ingest":      {"Upload JSONL or CSV conversation files", runIngest},
+	"trigger":     {"Trigger evaluations of conversations", runTrigger},
+	"tail":        {"Print evaluation results as they complete", runTail},
+	"regress":     {"Run a dataset for an agent version and compare with the last run", runRegress},
+	"stats":       {"Print system statistics", runStats},
+	"suggestions": {"List, generate or implement improvement suggestions", runSuggestions},
+}
+
+// errFailed reports that a command ran but what it checked failed, e.g. an
+// evaluation scored below --fail-below; evalctl exits with status 1
+var errFailed = errors.New("check failed")
+
+// evalctl drives the pipeline through its API for scripting and CI. Results
+// are written to stdout as JSON lines, progress to stderr.
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("evalctl: ")
+
+	baseURL := flag.String("url", getEnv("EVALCTL_URL", "http://localhost:8080"), "Base URL of the API")
+	apiKey := flag.String("api-key", os.Getenv("EVALCTL_API_KEY"), "API key")
+	token := flag.String("token", os.Getenv("EVALCTL_TOKEN"), "Bearer token")
+	project := flag.String("project", os.Getenv("EVALCTL_PROJECT"), "Project ID")
+	timeout := flag.Duration("timeout", 30*time.Second, "Timeout of each API request")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	name := flag.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		log.Printf("unknown command %q", name)
+		usage()
+		os.Exit(2)
+	}
+
+	c := client.New(client.Config{
+		BaseURL:   *baseURL,
+		APIKey:    *apiKey,
+		Token:     *token,
+		ProjectID: *project,
+		Timeout:   *timeout,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err := cmd.run(ctx, c, flag.Args()[1:])
+	if err == errFailed {
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "Usage: evalctl [flags] <command> [command flags] [args]")
+	fmt.Fprintln(out, "\nCommands:")
+	for _, name := range []string{"ingest", "trigger", "regress", "tail", "stats", "suggestions"} {
+		fmt.Fprintf(out, "  %-12s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(out, "\nRun evalctl <command> -h for the flags of a command.")
+}
+
+// runIngest uploads conversation files and waits for their imports
+func runIngest(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("ingest", flag.ExitOnError)
+	fs.Usage = commandUsage(fs, "ingest [flags] FILE...")
+	autoEvaluate := fs.Bool("auto-evaluate", true, "Evaluate the imported conversations")
+	wait := fs.Bool("wait", true, "Wait for each import to finish")
+	interval := fs.Duration("interval", 2*time.Second, "How often to poll import progress")
+	failOnError := fs.Bool("fail-on-error", false, "Exit with status 1 when any record fails to import")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("ingest: no files given")
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	failed := false
+	for _, path := range fs.Args() {
+		imp, err := uploadFile(ctx, c, path, *autoEvaluate)
+		if err != nil {
+			return err
+		}
+		log.Printf("Uploaded %s as import %s", path, imp.ImportID)
+
+		for *wait && imp.Status == models.ImportRunning {
+			if err := sleep(ctx, *interval); err != nil {
+				return err
+			}
+			importID := imp.ImportID
+			if imp, err = c.GetConversationImport(ctx, importID); err != nil {
+				return fmt.Errorf("failed to get import %s: %w", importID, err)
+			}
+		}
+		if imp.Status == models.ImportFailed || imp.Failed > 0 {
+			failed = true
+		}
+		if err := out.Encode(imp); err != nil {
+			return err
+		}
+	}
+
+	if *failOnError && failed {
+		return errFailed
+	}
+	return nil
+}
+
+// uploadFile uploads one conversation file
+func uploadFile(ctx context.Context, c *client.Client, path string, autoEvaluate bool) (*models.ConversationImport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	imp, err := c.UploadConversations(ctx, filepath.Base(path), file, autoEvaluate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload %s: %w", path, err)
+	}
+	return imp, nil
+}
+
+// runTrigger triggers evaluations of the conversations given as arguments,
+// or read one per line from stdin, and optionally waits for their results
+func runTrigger(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("trigger", flag.ExitOnError)
+	fs.Usage = commandUsage(fs, "trigger [flags] [CONVERSATION_ID...]")
+	evaluators := fs.String("evaluators", "", "Evaluator types to run (comma separated); the pipeline's defaults if empty")
+	strict := fs.Bool("strict", false, "Fail rather than skip evaluators that cannot run")
+	maxCost := fs.Float64("max-cost", -1, "Budget of each evaluation; the configured budget if negative")
+	wait := fs.Bool("wait", false, "Wait for the evaluations and print their results")
+	waitTimeout := fs.Duration("wait-timeout", 10*time.Minute, "How long to wait for the evaluations")
+	interval := fs.Duration("interval", 2*time.Second, "How often to poll task status")
+	failBelow := fs.Float64("fail-below", 0, "With --wait, exit with status 1 when an overall score is below this")
+	failOnVerdict := fs.String("fail-on-verdict", "", "With --wait, exit with status 1 when a verdict is one of these (comma separated, e.g. fail,needs_review)")
+	fs.Parse(args)
+
+	conversationIDs := fs.Args()
+	if len(conversationIDs) == 0 {
+		var err error
+		if conversationIDs, err = readLines(os.Stdin); err != nil {
+			return fmt.Errorf("failed to read conversation IDs: %w", err)
+		}
+	}
+	if len(conversationIDs) == 0 {
+		return fmt.Errorf("trigger: no conversation IDs given")
+	}
+
+	failVerdicts := make(map[string]bool)
+	for _, verdict := range strings.Split(*failOnVerdict, ",") {
+		if verdict = strings.TrimSpace(verdict); verdict != "" {
+			failVerdicts[verdict] = true
+		}
+	}
+
+	req := models.EvaluationRequest{Strict: *strict}
+	if *evaluators != "" {
+		req.EvaluatorTypes = strings.Split(*evaluators, ",")
+	}
+	if *maxCost >= 0 {
+		req.MaxCost = maxCost
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	tasks := make([]*client.TriggerResponse, 0, len(conversationIDs))
+	for _, conversationID := range conversationIDs {
+		req.ConversationID = conversationID
+		resp, err := c.TriggerEvaluation(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to trigger evaluation of %s: %w", conversationID, err)
+		}
+		for _, warning := range resp.Warnings {
+			log.Printf("%s: %s: %s", conversationID, warning.EvaluatorType, warning.Message)
+		}
+		tasks = append(tasks, resp)
+		if !*wait {
+			if err := out.Encode(resp); err != nil {
+				return err
+			}
+		}
+	}
+	if !*wait {
+		return nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, *waitTimeout)
+	defer cancel()
+
+	failed := false
+	for _, task := range tasks {
+		status, err := c.WaitForTask(waitCtx, task.TaskID, *interval)
+		if err != nil {
+			return fmt.Errorf("failed to wait for evaluation of %s: %w", task.ConversationID, err)
+		}
+		if status.State == queue.TaskStateFailed {
+			log.Printf("Evaluation of %s failed: %s", task.ConversationID, status.Error)
+			failed = true
+			if err := out.Encode(status); err != nil {
+				return err
+			}
+			continue
+		}
+
+		eval, err := c.GetEvaluation(ctx, status.EvaluationID)
+		if err != nil {
+			return fmt.Errorf("failed to get evaluation %s: %w", status.EvaluationID, err)
+		}
+		if eval.Scores.Overall < *failBelow {
+			log.Printf("Evaluation of %s scored %.3f, below %.3f", task.ConversationID, eval.Scores.Overall, *failBelow)
+			failed = true
+		}
+		if failVerdicts[eval.Verdict] {
+			log.Printf("Evaluation of %s has verdict %s (rule %q)", task.ConversationID, eval.Verdict, eval.VerdictRule)
+			failed = true
+		}
+		if err := out.Encode(eval); err != nil {
+			return err
+		}
+	}
+
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+// runRegress starts a regression run of a dataset for an agent version,
+// waits for it and compares it with the dataset's previous completed run
+func runRegress(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("regress", flag.ExitOnError)
+	fs.Usage = commandUsage(fs, "regress [flags] DATASET_ID AGENT_VERSION")
+	base := fs.Int64("base", 0, "Run to compare with; the previous completed run if 0")
+	threshold := fs.Float64("threshold", -1, "Score drop that counts as a regression; the server's default if negative")
+	waitTimeout := fs.Duration("wait-timeout", 30*time.Minute, "How long to wait for the run")
+	interval := fs.Duration("interval", 5*time.Second, "How often to poll the run")
+	failOnRegression := fs.Bool("fail-on-regression", true, "Exit with status 1 when any item regressed")
+	failOnMissing := fs.Bool("fail-on-missing", false, "Exit with status 1 when any item has no conversation or failed to evaluate")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("regress: expected a dataset ID and an agent version")
+	}
+	datasetID, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("regress: invalid dataset ID %q", fs.Arg(0))
+	}
+
+	started, err := c.StartDatasetRun(ctx, datasetID, models.DatasetRunCreate{AgentVersion: fs.Arg(1)})
+	if err != nil {
+		return fmt.Errorf("failed to start run of dataset %d: %w", datasetID, err)
+	}
+	log.Printf("Started run %d of dataset %d for %s", started.ID, datasetID, started.AgentVersion)
+
+	waitCtx, cancel := context.WithTimeout(ctx, *waitTimeout)
+	defer cancel()
+	run, err := c.WaitForDatasetRun(waitCtx, datasetID, started.ID, *interval)
+	if err != nil {
+		return fmt.Errorf("failed to wait for run %d: %w", started.ID, err)
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	failed := false
+	if *failOnMissing && run.MissingCount+run.FailedCount > 0 {
+		log.Printf("Run %d has %d missing and %d failed items", run.ID, run.MissingCount, run.FailedCount)
+		failed = true
+	}
+
+	comparison, err := c.CompareDatasetRuns(ctx, datasetID, *base, run.ID, *threshold)
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound && *base == 0 {
+		// The dataset's first run has nothing to compare with
+		log.Printf("No earlier completed run of dataset %d to compare with", datasetID)
+		if err := out.Encode(run); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to compare run %d: %w", run.ID, err)
+	} else {
+		if comparison.Regressions > 0 {
+			log.Printf("Run %d regressed on %d items since run %d", run.ID, comparison.Regressions, comparison.Base.ID)
+			failed = failed || *failOnRegression
+		}
+		if err := out.Encode(comparison); err != nil {
+			return err
+		}
+	}
+
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+// runTail prints the latest evaluations, then new ones as they are stored
+func runTail(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	fs.Usage = commandUsage(fs, "tail [flags]")
+	n := fs.Int("n", 10, "Number of latest evaluations to print first")
+	follow := fs.Bool("follow", true, "Keep printing new evaluations")
+	interval := fs.Duration("interval", 5*time.Second, "How often to poll for new evaluations")
+	conversationID := fs.String("conversation", "", "Only evaluations of this conversation")
+	triggerSource := fs.String("trigger-source", "", "Only evaluations with this trigger source")
+	verdict := fs.String("verdict", "", "Only evaluations with this verdict (pass, fail or needs_review)")
+	fs.Parse(args)
+
+	filter := client.EvaluationFilter{
+		ConversationID: *conversationID,
+		TriggerSource:  *triggerSource,
+		Verdict:        *verdict,
+		Limit:          100,
+	}
+	out := json.NewEncoder(os.Stdout)
+
+	// Evaluations are listed newest first; a poll prints those the previous
+	// poll didn't see, oldest first
+	seen := make(map[string]bool)
+	first := true
+	for {
+		evals, err := c.ListEvaluations(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list evaluations: %w", err)
+		}
+
+		var fresh []models.EvaluationResponse
+		for _, eval := range evals {
+			if !seen[eval.EvaluationID] {
+				fresh = append(fresh, eval)
+			}
+		}
+		if first && len(fresh) > *n {
+			fresh = fresh[:*n]
+		}
+		for i := len(fresh) - 1; i >= 0; i-- {
+			if err := out.Encode(fresh[i]); err != nil {
+				return err
+			}
+		}
+
+		seen = make(map[string]bool, len(evals))
+		for _, eval := range evals {
+			seen[eval.EvaluationID] = true
+		}
+		first = false
+
+		if !*follow {
+			return nil
+		}
+		if err := sleep(ctx, *interval); err != nil {
+			return nil // Interrupted
+		}
+	}
+}
+
+// runStats prints system statistics
+func runStats(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	fs.Usage = commandUsage(fs, "stats [flags]")
+	asOf := fs.String("as-of", "", "Only use data that existed at this time (RFC3339)")
+	fs.Parse(args)
+
+	var at time.Time
+	if *asOf != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, *asOf); err != nil {
+			return fmt.Errorf("invalid --as-of: %w", err)
+		}
+	}
+
+	stats, err := c.GetStats(ctx, at)
+	if err != nil {
+		return fmt.Errorf("failed to get stats: %w", err)
+	}
+	return printJSON(stats)
+}
+
+// runSuggestions lists, generates or implements improvement suggestions
+func runSuggestions(ctx context.Context, c *client.Client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("suggestions: expected list, analyze or implement")
+	}
+
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("suggestions list", flag.ExitOnError)
+		fs.Usage = commandUsage(fs, "suggestions list [flags]")
+		minConfidence := fs.Float64("min-confidence", 0.7, "Minimum confidence")
+		suggestionType := fs.String("type", "", "Only suggestions of this type")
+		fs.Parse(args[1:])
+
+		suggestions, err := c.ListSuggestions(ctx, *minConfidence, *suggestionType)
+		if err != nil {
+			return fmt.Errorf("failed to list suggestions: %w", err)
+		}
+		out := json.NewEncoder(os.Stdout)
+		for _, suggestion := range suggestions {
+			if err := out.Encode(suggestion); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "analyze":
+		fs := flag.NewFlagSet("suggestions analyze", flag.ExitOnError)
+		fs.Usage = commandUsage(fs, "suggestions analyze [flags]")
+		lookbackDays := fs.Int("lookback-days", 7, "Days of evaluations to analyze")
+		fs.Parse(args[1:])
+
+		result, err := c.AnalyzeSuggestions(ctx, *lookbackDays)
+		if err != nil {
+			return fmt.Errorf("failed to analyze: %w", err)
+		}
+		return printJSON(result)
+
+	case "implement":
+		fs := flag.NewFlagSet("suggestions implement", flag.ExitOnError)
+		fs.Usage = commandUsage(fs, "suggestions implement [flags] SUGGESTION_ID...")
+		before := fs.String("before-metrics", "", "Metrics before the change as a JSON object, to measure its impact against")
+		fs.Parse(args[1:])
+
+		if fs.NArg() == 0 {
+			return fmt.Errorf("suggestions implement: no suggestion IDs given")
+		}
+		var beforeMetrics map[string]interface{}
+		if *before != "" {
+			if err := json.Unmarshal([]byte(*before), &beforeMetrics); err != nil {
+				return fmt.Errorf("invalid --before-metrics: %w", err)
+			}
+		}
+		for _, suggestionID := range fs.Args() {
+			if err := c.MarkSuggestionImplemented(ctx, suggestionID, beforeMetrics); err != nil {
+				return fmt.Errorf("failed to mark %s implemented: %w", suggestionID, err)
+			}
+			log.Printf("Marked %s implemented", suggestionID)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("suggestions: unknown subcommand %q, expected list, analyze or implement", args[0])
+	}
+}
+
+// commandUsage returns the usage function of a command's flag set
+func commandUsage(fs *flag.FlagSet, synopsis string) func() {
+	return func() {
+		fmt.Fprintf(fs.Output(), "Usage: evalctl %s\n", synopsis)
+		fs.PrintDefaults()
+	}
+}
+
+// readLines reads the non-empty lines of r
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Println(string(data))
+	return err
+}
+
+// sleep waits for d, or returns early with the error of a cancelled ctx
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
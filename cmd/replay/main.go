@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/database"
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/repository"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/joho/godotenv"
+)
+
+// replay re-sends recorded evaluator requests to a candidate evaluator build
+// and diffs its output against the recorded responses. Diffs of changed
+// recordings are written to stdout as JSON lines, followed by a summary.
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+	cfg := config.Load()
+
+	target := flag.String("target", "", "Base URL of the candidate evaluator service")
+	since := flag.Duration("since", 7*24*time.Hour, "Replay recordings made within this window")
+	version := flag.String("version", "", "Only replay recordings answered by this evaluator version")
+	limit := flag.Int("limit", 500, "Maximum number of recordings to replay")
+	tolerance := flag.Float64("tolerance", 0.01, "Ignore score changes up to this size")
+	concurrency := flag.Int("concurrency", 4, "Requests sent to the candidate at once")
+	all := flag.Bool("all", false, "Also write diffs of unchanged recordings")
+	failOnDiff := flag.Bool("fail-on-diff", false, "Exit with status 1 when any output changed")
+	flag.Parse()
+
+	if *target == "" {
+		log.Fatal("--target is required")
+	}
+	if *concurrency < 1 {
+		*concurrency = 1
+	}
+
+	db, err := database.New(cfg.DatabaseURL, cfg.DBMaxConnections, cfg.DBMaxIdle)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	repo := repository.New(db)
+	recordings, err := repo.ListReplayableRecordings(time.Now().Add(-*since), *version, *limit)
+	if err != nil {
+		log.Fatalf("Failed to load recordings: %v", err)
+	}
+	log.Printf("Replaying %d recordings against %s", len(recordings), *target)
+
+	candidate := services.NewEvaluatorService(*target, nil)
+	diffs := make([]*services.ReplayDiff, len(recordings))
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < *concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				diffs[i] = replay(candidate, &recordings[i], *tolerance)
+			}
+		}()
+	}
+	for i := range recordings {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	out := json.NewEncoder(os.Stdout)
+	for _, diff := range diffs {
+		if *all || diff.Changed() || diff.Error != "" {
+			if err := out.Encode(diff); err != nil {
+				log.Fatalf("Failed to write diff: %v", err)
+			}
+		}
+	}
+
+	summary := services.SummarizeReplay(diffs)
+	if err := out.Encode(map[string]interface{}{"summary": summary}); err != nil {
+		log.Fatalf("Failed to write summary: %v", err)
+	}
+	log.Printf("Replayed %d recordings: %d changed, %d failed", summary.Replayed, summary.Changed, summary.Failed)
+
+	if *failOnDiff && (summary.Changed > 0 || summary.Failed > 0) {
+		os.Exit(1)
+	}
+}
+
+// replay sends one recorded request to the candidate and diffs the result
+func replay(candidate *services.EvaluatorService, recording *models.EvaluatorRecording, tolerance float64) *services.ReplayDiff {
+	failed := func(err error) *services.ReplayDiff {
+		return &services.ReplayDiff{
+			RecordingID:     recording.ID,
+			ConversationID:  recording.ConversationID,
+			BaselineVersion: recording.EvaluatorVersion,
+			Error:           err.Error(),
+		}
+	}
+
+	result, err := candidate.EvaluateRaw(recording.Request)
+	if err != nil {
+		return failed(err)
+	}
+	diff, err := services.DiffRecording(recording, result, tolerance)
+	if err != nil {
+		return failed(err)
+	}
+	return diff
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/database"
+	"github.com/ai-agent-eval/internal/queue"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/ai-agent-eval/internal/telemetry"
+	"github.com/ai-agent-eval/internal/worker"
+	"github.com/joho/godotenv"
+)
+
+// worker consumes the evaluations queue on its own, so evaluation capacity
+// can be scaled separately from the API. The API runs its migrations.
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+	cfg := config.Load()
+
+	concurrency := flag.Int("concurrency", cfg.WorkerConcurrency, "Tasks evaluated at once")
+	flag.Parse()
+	cfg.WorkerConcurrency = *concurrency
+
+	if err := services.RegisterRemoteEvaluators(services.Evaluators, cfg.CustomEvaluatorURLs); err != nil {
+		log.Fatalf("Invalid custom evaluator: %v", err)
+	}
+
+	db, err := database.New(cfg.DatabaseURL, cfg.DBMaxConnections, cfg.DBMaxIdle)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	redisQueue, err := queue.NewRedisQueue(cfg.RedisURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	defer redisQueue.Close()
+	redisQueue.SetCompressionThreshold(cfg.TaskCompressionThreshold)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	metrics := telemetry.New(cfg)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		metrics.Run(ctx)
+	}()
+
+	w := worker.New(cfg, db, redisQueue)
+	w.SetMetrics(metrics)
+	err = w.Run(ctx)
+	stop()
+	<-done // Exports what the worker recorded last
+	if err != nil {
+		log.Fatalf("Worker failed: %v", err)
+	}
+	log.Println("Exited gracefully")
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/ai-agent-eval/internal/telemetry"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// Bounds on live activity streaming
+const (
+	maxActivityClients   = 1000
+	activityClientQueue  = 256 // Events buffered per client; later ones are dropped until it catches up
+	activityWriteTimeout = 10 * time.Second
+)
+
+// activityHub fans activity events out to the WebSocket clients whose
+// project and filter they match
+type activityHub struct {
+	mu      sync.Mutex
+	clients map[*activityClient]bool
+}
+
+type activityClient struct {
+	projectID string
+	events    chan models.ActivityEvent
+
+	mu     sync.Mutex
+	filter models.ActivityFilter
+}
+
+func newActivityHub() *activityHub {
+	return &activityHub{clients: make(map[*activityClient]bool)}
+}
+
+// add registers a client, or returns false if the hub is full
+func (h *activityHub) add(client *activityClient) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.clients) >= maxActivityClients {
+		return false
+	}
+	h.clients[client] = true
+	return true
+}
+
+func (h *activityHub) remove(client *activityClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, client)
+}
+
+// broadcast queues an event for every matching client without blocking
+func (h *activityHub) broadcast(event models.ActivityEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.clients {
+		if client.projectID != event.ProjectID || !services.ActivityMatches(client.getFilter(), event) {
+			continue
+		}
+		select {
+		case client.events <- event:
+		default:
+		}
+	}
+}
+
+func (c *activityClient) getFilter() models.ActivityFilter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.filter
+}
+
+func (c *activityClient) setFilter(filter models.ActivityFilter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.filter = filter
+}
+
+// streamActivity streams ingestion, evaluation and annotation events of the
+// request's project over a WebSocket
+// @Summary Live system activity
+// @Description Upgrades to a WebSocket streaming activity events as JSON messages. Send a filter as a JSON message at any time to replace the one given in the query. min_severity only filters evaluation events, by their most severe issue.
+// @Tags Analytics
+// @Param agent_version query string false "Only events of this agent version"
+// @Param min_severity query string false "Only evaluations with an issue at least this severe (low, medium, high, critical)"
+// @Param types query string false "Comma separated event types: conversation_ingested, evaluation_completed, annotation_created"
+// @Success 101 {object} models.ActivityEvent
+// @Router /api/v1/ws [get]
+func (s *Server) streamActivity(c *gin.Context) {
+	filter := models.ActivityFilter{
+		AgentVersion: c.Query("agent_version"),
+		MinSeverity:  c.Query("min_severity"),
+	}
+	if types := c.Query("types"); types != "" {
+		filter.Types = strings.Split(types, ",")
+	}
+	if err := services.ValidateActivityFilter(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client := &activityClient{
+		projectID: c.GetString(projectKey),
+		events:    make(chan models.ActivityEvent, activityClientQueue),
+		filter:    filter,
+	}
+	if !s.activity.add(client) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many activity streams"})
+		return
+	}
+	defer s.activity.remove(client)
+
+	server := websocket.Server{
+		// Requests are authenticated like any other; browsers on other
+		// origins are allowed like the CORS policy allows them
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			// Lifts the deadlines the HTTP server set for ordinary requests
+			ws.SetDeadline(time.Time{})
+			closed := make(chan struct{})
+			go s.receiveActivityFilters(ws, client, closed)
+
+			for {
+				select {
+				case <-closed:
+					return
+				case event := <-client.events:
+					ws.SetWriteDeadline(time.Now().Add(activityWriteTimeout))
+					if err := websocket.JSON.Send(ws, event); err != nil {
+						return
+					}
+				}
+			}
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// receiveActivityFilters applies the filters a client sends until it
+// disconnects, then closes closed. Invalid filters are answered with an
+// error message and ignored.
+func (s *Server) receiveActivityFilters(ws *websocket.Conn, client *activityClient, closed chan struct{}) {
+	defer close(closed)
+	for {
+		var message string
+		if err := websocket.Message.Receive(ws, &message); err != nil {
+			return
+		}
+		var filter models.ActivityFilter
+		err := json.Unmarshal([]byte(message), &filter)
+		if err == nil {
+			err = services.ValidateActivityFilter(filter)
+		}
+		if err != nil {
+			websocket.JSON.Send(ws, gin.H{"error": err.Error()})
+			continue
+		}
+		client.setFilter(filter)
+	}
+}
+
+// publishActivity publishes an activity event to every replica's streams.
+// Activity is best effort and must not fail the request.
+func (s *Server) publishActivity(event models.ActivityEvent) {
+	if err := s.queue.Publish(queue.ActivityChannel, event); err != nil {
+		log.Printf("Failed to publish %s activity: %v", event.Type, err)
+	}
+}
+
+// ListenForActivity streams the activity events published by every replica
+// and worker to this replica's WebSocket clients, until ctx is cancelled
+func (s *Server) ListenForActivity(ctx context.Context) error {
+	return s.queue.Subscribe(ctx, queue.ActivityChannel, func(message []byte) {
+		var event models.ActivityEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			log.Printf("Ignoring malformed activity event: %v", err)
+			return
+		}
+		s.activity.broadcast(event)
+	})
+}
+
+// SetMetrics exports the evaluations the API stores as metrics
+func (s *Server) SetMetrics(metrics *telemetry.Exporter) {
+	s.metrics = metrics
+}
+
+// publishEvaluation publishes the activity event and metrics of an
+// evaluation being stored or completed
+func (s *Server) publishEvaluation(eval *models.Evaluation) {
+	agentVersion, err := s.repo.GetConversationAgentVersion(eval.ConversationID)
+	if err != nil {
+		log.Printf("Failed to look up agent version of %s: %v", eval.ConversationID, err)
+	}
+	s.metrics.Record(eval, agentVersion)
+	s.publishActivity(services.EvaluationActivity(eval, agentVersion))
+}
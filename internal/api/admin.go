@@ -0,0 +1,419 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/repository"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// configBundleVersion is the format version of exported configuration bundles
+const configBundleVersion = "1"
+
+// maxHardDeleteConversations caps how many conversations one hard delete may remove
+const maxHardDeleteConversations = 10000
+
+// maxReassignConversations caps how many conversations one agent version
+// reassignment may change
+const maxReassignConversations = 10000
+
+// exportConfig exports the pipeline configuration as a bundle
+// @Summary Export pipeline configuration
+// @Tags Admin
+// @Produce json
+// @Produce x-yaml
+// @Param format query string false "Bundle format (json or yaml)" default(json)
+// @Success 200 {object} models.ConfigBundle
+// @Router /api/v1/admin/config/export [get]
+func (s *Server) exportConfig(c *gin.Context) {
+	bundle := models.ConfigBundle{
+		Version:    configBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Config:     s.pipeline.Get(),
+	}
+
+	if c.DefaultQuery("format", "json") == "yaml" {
+		c.YAML(http.StatusOK, bundle)
+		return
+	}
+
+	c.JSON(http.StatusOK, bundle)
+}
+
+// importConfig imports a pipeline configuration bundle. Sections the bundle
+// leaves out keep their current values.
+// @Summary Import pipeline configuration
+// @Tags Admin
+// @Accept json
+// @Accept x-yaml
+// @Produce json
+// @Param bundle body models.ConfigBundle true "Configuration bundle"
+// @Success 200 {object} models.ConfigBundle
+// @Router /api/v1/admin/config/export [post]
+func (s *Server) importConfig(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The configuration is decoded generically so that what it leaves out
+	// can be told apart from zero values
+	var raw struct {
+		Version string                 `json:"version" yaml:"version"`
+		Config  map[string]interface{} `json:"config" yaml:"config"`
+	}
+	if strings.Contains(c.ContentType(), "yaml") {
+		err = yaml.Unmarshal(body, &raw)
+	} else {
+		err = json.Unmarshal(body, &raw)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if raw.Version != configBundleVersion {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported bundle version"})
+		return
+	}
+
+	merged, err := services.MergePipelineConfig(s.pipeline.Get(), raw.Config)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	bundle := models.ConfigBundle{Version: raw.Version, ExportedAt: time.Now().UTC(), Config: merged}
+
+	if err := s.repo.SaveConfigBundle(&bundle); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	s.pipeline.Set(bundle.Config)
+	s.publishInvalidation(cacheScopeConfig)
+
+	c.JSON(http.StatusOK, models.ConfigBundle{
+		Version:    configBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Config:     s.pipeline.Get(),
+	})
+}
+
+// getHealthFormula describes how conversation health is computed
+// @Summary Get conversation health formula
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/config/health [get]
+func (s *Server) getHealthFormula(c *gin.Context) {
+	cfg := s.pipeline.Get()
+
+	c.JSON(http.StatusOK, gin.H{
+		"policy":           cfg.Health,
+		"formula":          services.HealthFormula(cfg.Health),
+		"review_threshold": cfg.Routing.HealthThreshold,
+	})
+}
+
+// getSeverityPriorities returns the severity to routing priority mapping
+// @Summary Get severity routing priorities
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} models.SeverityPriorityMapping
+// @Router /api/v1/admin/routing/severity-priorities [get]
+func (s *Server) getSeverityPriorities(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SeverityPriorityMapping{
+		SeverityPriorities: s.pipeline.Get().Routing.SeverityPriorities,
+	})
+}
+
+// setSeverityPriorities replaces the severity to routing priority mapping.
+// New routing decisions use it immediately; the configuration is saved as a
+// new bundle so every replica picks it up.
+// @Summary Set severity routing priorities
+// @Description Optionally re-prioritizes open annotation tasks for conversations evaluated in the last recompute_hours whose routing no reviewer has approved.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.SeverityPriorityUpdate true "Severity to priority mapping"
+// @Success 200 {object} models.SeverityPriorityMapping
+// @Router /api/v1/admin/routing/severity-priorities [put]
+func (s *Server) setSeverityPriorities(c *gin.Context) {
+	var req models.SeverityPriorityUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateSeverityPriorities(req.SeverityPriorities); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bundle := models.ConfigBundle{
+		Version:    configBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Config:     s.pipeline.Get(),
+	}
+	bundle.Config.Routing.SeverityPriorities = req.SeverityPriorities
+	if err := s.repo.SaveConfigBundle(&bundle); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	s.pipeline.Set(bundle.Config)
+	s.publishInvalidation(cacheScopeConfig)
+
+	cfg := s.pipeline.Get()
+	resp := models.SeverityPriorityMapping{SeverityPriorities: cfg.Routing.SeverityPriorities}
+	if req.RecomputeHours > 0 {
+		since := time.Now().UTC().Add(-time.Duration(req.RecomputeHours) * time.Hour)
+		recomputed, err := s.repo.RecomputeTaskPriorities(since, cfg.Routing)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		resp.RecomputedTasks = &recomputed
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// getToolGoldens returns the golden tool call examples
+// @Summary Get golden tool call examples
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} models.ToolGoldenUpdate
+// @Router /api/v1/admin/tools/goldens [get]
+func (s *Server) getToolGoldens(c *gin.Context) {
+	c.JSON(http.StatusOK, models.ToolGoldenUpdate{
+		ToolGoldens: s.pipeline.Get().ToolGoldens,
+	})
+}
+
+// setToolGoldens replaces the golden tool call examples. Evaluations check
+// recorded tool calls against them immediately; the configuration is saved
+// as a new bundle so every replica and worker picks it up.
+// @Summary Set golden tool call examples
+// @Description Each example applies to calls of its tool whose last user message matches scenario.user_message (a case-insensitive regular expression) and whose parameters include scenario.parameters. Matching calls must include expected_parameters and, if given, return a result that includes expected_result. Evaluations report the share of matching calls that passed as the tool_golden score.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.ToolGoldenUpdate true "Golden examples"
+// @Success 200 {object} models.ToolGoldenUpdate
+// @Router /api/v1/admin/tools/goldens [put]
+func (s *Server) setToolGoldens(c *gin.Context) {
+	var req models.ToolGoldenUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ToolGoldens == nil {
+		req.ToolGoldens = []models.ToolGolden{}
+	}
+	if err := services.ValidateToolGoldens(req.ToolGoldens); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bundle := models.ConfigBundle{
+		Version:    configBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Config:     s.pipeline.Get(),
+	}
+	bundle.Config.ToolGoldens = req.ToolGoldens
+	if err := s.repo.SaveConfigBundle(&bundle); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	s.pipeline.Set(bundle.Config)
+	s.publishInvalidation(cacheScopeConfig)
+
+	c.JSON(http.StatusOK, models.ToolGoldenUpdate{
+		ToolGoldens: s.pipeline.Get().ToolGoldens,
+	})
+}
+
+// getVerdictPolicy returns the policy deciding evaluation verdicts
+// @Summary Get verdict policy
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} models.VerdictPolicyReport
+// @Router /api/v1/admin/verdicts/policy [get]
+func (s *Server) getVerdictPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, models.VerdictPolicyReport{
+		VerdictPolicy: s.pipeline.Get().Verdicts,
+	})
+}
+
+// setVerdictPolicy replaces the policy deciding evaluation verdicts.
+// Evaluations stored from now on get their verdict from it; the
+// configuration is saved as a new bundle so every replica and worker picks
+// it up.
+// @Summary Set verdict policy
+// @Description Rules are checked in order and the first whose conditions all hold gives the verdict (pass, fail or needs_review); evaluations matching no rule get the default. A rule may require an overall score below overall_below, any component score below its components_below threshold, any issue at least min_issue_severity and of one of issue_types, and partial to match. Optionally decides the verdicts of evaluations stored in the last recompute_hours again.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.VerdictPolicyUpdate true "Verdict policy"
+// @Success 200 {object} models.VerdictPolicyReport
+// @Router /api/v1/admin/verdicts/policy [put]
+func (s *Server) setVerdictPolicy(c *gin.Context) {
+	var req models.VerdictPolicyUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateVerdictPolicy(req.VerdictPolicy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Rules == nil {
+		req.Rules = []models.VerdictRule{}
+	}
+
+	bundle := models.ConfigBundle{
+		Version:    configBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Config:     s.pipeline.Get(),
+	}
+	bundle.Config.Verdicts = req.VerdictPolicy
+	if err := s.repo.SaveConfigBundle(&bundle); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	s.pipeline.Set(bundle.Config)
+	s.publishInvalidation(cacheScopeConfig)
+
+	resp := models.VerdictPolicyReport{VerdictPolicy: s.pipeline.Get().Verdicts}
+	if req.RecomputeHours > 0 {
+		since := time.Now().UTC().Add(-time.Duration(req.RecomputeHours) * time.Hour)
+		recomputed, err := s.repo.RecomputeVerdicts(since, resp.VerdictPolicy)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		resp.RecomputedEvaluations = &recomputed
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// getStorageReport reports table and index sizes, JSONB payload sizes and
+// projected growth, with suggested retention and partitioning actions
+// @Summary Get storage usage
+// @Tags Admin
+// @Produce json
+// @Param growth_days query int false "Days of growth to project from" default(7)
+// @Success 200 {object} models.StorageReport
+// @Router /api/v1/admin/storage [get]
+func (s *Server) getStorageReport(c *gin.Context) {
+	growthDays, _ := strconv.Atoi(c.DefaultQuery("growth_days", "7"))
+	if growthDays <= 0 {
+		growthDays = 7
+	}
+
+	report, err := s.repo.GetStorageReport(growthDays)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// hardDeleteConversations permanently deletes conversations, with their
+// feedback, evaluations, annotations, every other row that depends on them and
+// the attachments only they reference
+// @Summary Hard delete conversations
+// @Description Deletes test data for good, including attachment blobs no other conversation references. Dataset run items are kept with their conversation cleared. Requests are dry runs unless dry_run is false, returning the rows that would be deleted per table and the attachments that would go.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.ConversationDeletion true "Conversations to delete"
+// @Success 200 {object} models.ConversationDeletionResult
+// @Router /api/v1/admin/conversations/hard-delete [post]
+func (s *Server) hardDeleteConversations(c *gin.Context) {
+	var req models.ConversationDeletion
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.ConversationIDs) == 0 && req.AgentVersion == "" && req.IDPrefix == "" && req.CreatedBefore == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one of conversation_ids, agent_version, id_prefix or created_before is required"})
+		return
+	}
+	dryRun := req.DryRun == nil || *req.DryRun
+
+	result, err := s.repo.HardDeleteConversations(&req, dryRun, maxHardDeleteConversations)
+	if errors.Is(err, repository.ErrTooManyConversations) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("More than %d conversations match; narrow the selection", maxHardDeleteConversations)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !dryRun {
+		log.Printf("Hard deleted %d conversations: %v", len(result.ConversationIDs), result.Rows)
+		for _, hash := range result.Attachments {
+			// The rows are gone either way; a blob left behind is only storage
+			if err := s.attachments.Delete(c.Request.Context(), hash); err != nil {
+				log.Printf("Failed to delete attachment %s of hard deleted conversations: %v", hash, err)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// reassignAgentVersion corrects the agent version of conversations that were
+// ingested with the wrong one
+// @Summary Reassign agent version
+// @Description Sets the agent version of the selected conversations and corrects the affected versions of failure patterns they are examples of. Dataset runs of the reassigned conversations are marked stale and are no longer picked as the base of a comparison. Analytics are computed from conversations, so they follow at once; cached public API responses are dropped on every replica. Requests are dry runs unless dry_run is false.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.AgentVersionReassignment true "Conversations to reassign and their version"
+// @Success 200 {object} models.AgentVersionReassignmentResult
+// @Router /api/v1/admin/conversations/reassign-version [post]
+func (s *Server) reassignAgentVersion(c *gin.Context) {
+	var req models.AgentVersionReassignment
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.ConversationIDs) == 0 && req.AgentVersion == "" && req.IDPrefix == "" && req.CreatedAfter == nil && req.CreatedBefore == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one of conversation_ids, agent_version, id_prefix, created_after or created_before is required"})
+		return
+	}
+	dryRun := req.DryRun == nil || *req.DryRun
+
+	result, err := s.repo.ReassignAgentVersion(&req, dryRun, maxReassignConversations)
+	if errors.Is(err, repository.ErrTooManyConversations) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("More than %d conversations match; narrow the selection", maxReassignConversations)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !dryRun && len(result.ConversationIDs) > 0 {
+		log.Printf("Reassigned %d conversations to agent version %s: %v", len(result.ConversationIDs), req.NewAgentVersion, result.FromVersions)
+		s.invalidateCache(cacheScopeAnalytics)
+		s.publishInvalidation(cacheScopeAnalytics)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
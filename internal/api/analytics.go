@@ -0,0 +1,614 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/repository"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// getToolLatencyStats returns per-tool latency SLA breach rates
+// @Summary Get tool latency SLA breaches
+// @Tags Analytics
+// @Produce json
+// @Param days query int false "Days to analyze" default(7)
+// @Param as_of query string false "Only use data that existed at this time (RFC3339)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/analytics/tool-latency [get]
+func (s *Server) getToolLatencyStats(c *gin.Context) {
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "7"))
+	since := asOf.AddDate(0, 0, -days)
+
+	slas := make(map[string]int)
+	for _, tool := range s.tools.Tools() {
+		slas[tool.Name] = tool.ExpectedLatencyMS
+	}
+
+	stats, err := s.projectRepo(c).GetToolLatencyStats(slas, s.pipeline.Get().LatencyThresholdMS, since, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tools": stats,
+		"count": len(stats),
+		"as_of": asOf,
+	})
+}
+
+// getThroughput returns ingestion and evaluation volume over time
+// @Summary Get pipeline throughput
+// @Tags Analytics
+// @Produce json
+// @Param interval query string false "Bucket size (hour or day)" default(hour)
+// @Param days query int false "Days to analyze" default(14)
+// @Param as_of query string false "Only use data that existed at this time (RFC3339)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/analytics/throughput [get]
+func (s *Server) getThroughput(c *gin.Context) {
+	interval := c.DefaultQuery("interval", "hour")
+	if interval != "hour" && interval != "day" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be hour or day"})
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "14"))
+	if days < 1 || days > 90 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+		return
+	}
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	since := asOf.AddDate(0, 0, -days)
+
+	buckets, err := s.projectRepo(c).GetThroughput(interval, since, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"interval": interval,
+		"days":     days,
+		"as_of":    asOf,
+		"buckets":  buckets,
+	})
+}
+
+// maxHourlyTimeSeriesDays caps hourly time series, which have a bucket per
+// hour
+const maxHourlyTimeSeriesDays = 31
+
+// getTimeSeries returns a metric's average, count and percentiles per period
+// @Summary Get metric time series
+// @Description Aggregates a metric over evaluations per hour, day or week, for trend charts. Every period in the range is returned; periods without evaluations have a count of 0 and null statistics. Hourly series cover at most 31 days.
+// @Tags Analytics
+// @Produce json
+// @Param metric query string false "Metric (overall_score, response_quality_score, tool_accuracy_score, coherence_score or evaluation_duration_ms)" default(overall_score)
+// @Param interval query string false "Bucket size (hour, day or week)" default(day)
+// @Param agent_version query string false "Filter by agent version"
+// @Param days query int false "Days to look back" default(30)
+// @Param as_of query string false "Only use data that existed at this time (RFC3339)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/analytics/timeseries [get]
+func (s *Server) getTimeSeries(c *gin.Context) {
+	metric := c.DefaultQuery("metric", "overall_score")
+	if err := repository.ValidateSliceMetric(metric); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	interval := c.DefaultQuery("interval", "day")
+	if !repository.TimeSeriesIntervals[interval] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be hour, day or week"})
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days < 1 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+		return
+	}
+	if interval == "hour" && days > maxHourlyTimeSeriesDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("hourly series cover at most %d days", maxHourlyTimeSeriesDays)})
+		return
+	}
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	since := asOf.AddDate(0, 0, -days)
+	agentVersion := c.Query("agent_version")
+
+	buckets, err := s.projectRepo(c).GetTimeSeries(metric, interval, agentVersion, since, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"metric":        metric,
+		"interval":      interval,
+		"agent_version": agentVersion,
+		"days":          days,
+		"as_of":         asOf,
+		"buckets":       buckets,
+	})
+}
+
+// getSlice aggregates a metric grouped by whitelisted dimensions
+// @Summary Get sliced analytics
+// @Tags Analytics
+// @Produce json
+// @Param metric query string false "Metric to aggregate" default(overall_score)
+// @Param dims query string true "Comma separated dimensions (agent_version, evaluator_version, trigger_source, language, intent, model, tag, day)"
+// @Param from query string false "Start time (RFC3339)"
+// @Param to query string false "End time (RFC3339)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/analytics/slice [get]
+func (s *Server) getSlice(c *gin.Context) {
+	metric := c.DefaultQuery("metric", "overall_score")
+
+	var dims []string
+	for _, dim := range strings.Split(c.Query("dims"), ",") {
+		if dim = strings.TrimSpace(dim); dim != "" {
+			dims = append(dims, dim)
+		}
+	}
+
+	if err := repository.ValidateSlice(metric, dims); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := s.projectRepo(c).GetSlice(metric, dims, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"metric":     metric,
+		"dimensions": dims,
+		"rows":       rows,
+		"count":      len(rows),
+	})
+}
+
+// getAnomalies lists detected quality anomalies
+// @Summary Get quality anomalies
+// @Tags Analytics
+// @Produce json
+// @Param metric query string false "Filter by metric (avg_score or issue_rate)"
+// @Param days query int false "Days to look back" default(7)
+// @Param limit query int false "Limit" default(100)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/analytics/anomalies [get]
+func (s *Server) getAnomalies(c *gin.Context) {
+	metric := c.Query("metric")
+	if metric != "" && metric != services.MetricAvgScore && metric != services.MetricIssueRate {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "metric must be avg_score or issue_rate"})
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "7"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	since := time.Now().AddDate(0, 0, -days)
+
+	events, err := s.repo.ListAnomalyEvents(metric, since, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"anomalies": events,
+		"count":     len(events),
+	})
+}
+
+// getPipelineLatency returns stage-wise latency percentiles of the evaluation pipeline
+// @Summary Get evaluation pipeline latency
+// @Tags Analytics
+// @Produce json
+// @Param hours query int false "Hours to look back" default(24)
+// @Param trigger_source query string false "Filter by trigger source"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/analytics/pipeline-latency [get]
+func (s *Server) getPipelineLatency(c *gin.Context) {
+	hours, _ := strconv.Atoi(c.DefaultQuery("hours", "24"))
+	if hours <= 0 {
+		hours = 24
+	}
+	since := time.Now().UTC().Add(-time.Duration(hours) * time.Hour)
+
+	segments, err := s.projectRepo(c).GetPipelineLatency(since, c.Query("trigger_source"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":    since,
+		"segments": segments,
+		"count":    len(segments),
+	})
+}
+
+// getFeedbackThemes aggregates themes of user feedback comments against automated scores
+// @Summary Get feedback themes
+// @Description Groups feedback comments by extracted theme, with sentiment, user rating and the automated score and issue types of the commented conversations, to show whether user complaints match detected issues
+// @Tags Analytics
+// @Produce json
+// @Param days query int false "Days to look back" default(30)
+// @Param agent_version query string false "Filter by agent version"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/analytics/feedback-themes [get]
+func (s *Server) getFeedbackThemes(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+
+	signals, err := s.projectRepo(c).ListFeedbackSignals(since, c.Query("agent_version"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	themes := services.AggregateFeedbackThemes(signals, 5)
+	c.JSON(http.StatusOK, gin.H{
+		"since":              since,
+		"commented_feedback": len(signals),
+		"themes":             themes,
+		"count":              len(themes),
+	})
+}
+
+// getIntentQuality breaks conversation quality down by classified intent
+// @Summary Get quality by intent
+// @Description Scores, failure rates and top issue types per conversation intent. A conversation fails when its latest score is below the routing low score threshold or it has critical issues.
+// @Tags Analytics
+// @Produce json
+// @Param days query int false "Days to look back" default(30)
+// @Param agent_version query string false "Filter by agent version"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/analytics/intents [get]
+func (s *Server) getIntentQuality(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	threshold := s.pipeline.Get().Routing.LowScoreThreshold
+
+	intents, err := s.projectRepo(c).GetIntentQuality(since, c.Query("agent_version"), threshold, 5)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":               since,
+		"low_score_threshold": threshold,
+		"intents":             intents,
+		"count":               len(intents),
+	})
+}
+
+// getJourneys analyzes user journeys across conversations
+// @Summary Get journey outcomes
+// @Description Links conversations into journeys by the subject_id or session_id in their metadata. A journey is resolved when its latest conversation completed its mission, and escalation chains are runs of consecutive conversations marked escalated. The completion funnel counts journeys by conversation reached and resolved; the quality funnel narrows from evaluated to passing (no conversation below the routing low score threshold or with critical issues) to resolved without escalation. Only conversations created in the window are linked.
+// @Tags Analytics
+// @Produce json
+// @Param key query string false "Metadata field linking a journey's conversations (subject_id or session_id)" default(subject_id)
+// @Param days query int false "Days to look back" default(30)
+// @Param agent_version query string false "Filter by agent version"
+// @Param contacts query int false "Conversations the completion funnel follows" default(5)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/analytics/journeys [get]
+func (s *Server) getJourneys(c *gin.Context) {
+	key := c.DefaultQuery("key", models.JourneyKeySubject)
+	if key != models.JourneyKeySubject && key != models.JourneyKeySession {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "key must be subject_id or session_id"})
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+	contacts, _ := strconv.Atoi(c.DefaultQuery("contacts", strconv.Itoa(services.DefaultJourneyContacts)))
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	threshold := s.pipeline.Get().Routing.LowScoreThreshold
+
+	analyzer := services.NewJourneyAnalyzer(threshold, contacts)
+	err := s.projectRepo(c).StreamJourneyConversations(since, c.Query("agent_version"), key, func(conv *models.JourneyConversation) error {
+		analyzer.Add(conv)
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":               since,
+		"key":                 key,
+		"low_score_threshold": threshold,
+		"journeys":            analyzer.Result(),
+	})
+}
+
+// getVersionMatrix crosses agent version, model and tool version against
+// scores, to tell whether a regression came from the prompt, the model or a
+// tool
+// @Summary Get version matrix
+// @Description Latest scores per agent_version x model x tool_version, from the model and tool_versions conversation metadata. Effects rank the dimensions by the spread of their average scores.
+// @Tags Analytics
+// @Produce json
+// @Param days query int false "Days to look back" default(30)
+// @Param agent_version query string false "Filter by agent version"
+// @Param tool query string false "Only include versions of this tool"
+// @Param min_evaluations query int false "Leave out cells and values with fewer evaluations" default(5)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/analytics/version-matrix [get]
+func (s *Server) getVersionMatrix(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+	minEvaluations, _ := strconv.Atoi(c.DefaultQuery("min_evaluations", "5"))
+	if minEvaluations < 1 {
+		minEvaluations = 1
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	agentVersion := c.Query("agent_version")
+	tool := c.Query("tool")
+
+	cells, err := s.projectRepo(c).GetVersionMatrix(since, agentVersion, tool, minEvaluations)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	marginals, err := s.projectRepo(c).GetVersionMarginals(since, agentVersion, tool)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":     since,
+		"cells":     cells,
+		"marginals": marginals,
+		"effects":   services.VersionEffects(marginals, minEvaluations),
+		"count":     len(cells),
+	})
+}
+
+// compareVersions tests whether an agent version scores differently from
+// another, metric by metric
+// @Summary Compare agent versions
+// @Description Compares the latest scores of conversations of version_b to those of version_a for the overall score and each component. Each metric reports both versions' means, the delta (b minus a), Welch's t-test and the Mann-Whitney U test; it is significant when both p-values are below alpha.
+// @Tags Analytics
+// @Produce json
+// @Param version_a query string true "Baseline agent version"
+// @Param version_b query string true "Agent version compared to the baseline"
+// @Param days query int false "Days to look back" default(30)
+// @Param alpha query number false "Significance level" default(0.05)
+// @Success 200 {object} models.VersionComparison
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/analytics/compare [get]
+func (s *Server) compareVersions(c *gin.Context) {
+	versionA, versionB := c.Query("version_a"), c.Query("version_b")
+	if versionA == "" || versionB == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version_a and version_b are required"})
+		return
+	}
+	if versionA == versionB {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version_a and version_b must differ"})
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+	alpha := services.DefaultVersionComparisonAlpha
+	if value := c.Query("alpha"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed >= 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "alpha must be between 0 and 1"})
+			return
+		}
+		alpha = parsed
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+
+	scores, err := s.projectRepo(c).GetVersionScores(since, []string{versionA, versionB})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.VersionComparison{
+		VersionA: versionA,
+		VersionB: versionB,
+		Since:    since,
+		Alpha:    alpha,
+		Metrics:  services.CompareVersionScores(scores[versionA], scores[versionB], alpha),
+	})
+}
+
+// getTokenCost attributes estimated token usage and cost to agent versions
+// and models
+// @Summary Get token usage and cost
+// @Description Token counts are estimated at ingestion with the tokenizer of each conversation's model and split into content, tool call and tool result tokens. Costs use MODEL_TOKEN_PRICES and are left out for unpriced models.
+// @Tags Analytics
+// @Produce json
+// @Param days query int false "Days to look back" default(30)
+// @Param agent_version query string false "Filter by agent version"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/analytics/token-cost [get]
+func (s *Server) getTokenCost(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+
+	usage, err := s.projectRepo(c).GetTokenUsage(since, c.Query("agent_version"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	services.PriceTokenUsage(usage, s.cfg.ModelTokenPrices)
+
+	var totalTokens int64
+	var totalCost float64
+	for _, u := range usage {
+		totalTokens += u.TotalTokens
+		if u.EstimatedCostUSD != nil {
+			totalCost += *u.EstimatedCostUSD
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":                    since,
+		"usage":                    usage,
+		"total_tokens":             totalTokens,
+		"total_estimated_cost_usd": totalCost,
+		"count":                    len(usage),
+	})
+}
+
+// getCosts aggregates what running the agent cost by agent version or day
+// @Summary Get conversation costs
+// @Description Conversations are costed at ingestion from the token usage the agent reports per turn, or else in its metadata, at MODEL_TOKEN_PRICES and MODEL_OUTPUT_TOKEN_PRICES. Conversations without reported usage are costed from their estimated tokens at the input price, and conversations on unpriced models are counted but not costed.
+// @Tags Analytics
+// @Produce json
+// @Param group_by query string false "agent_version or day" default(agent_version)
+// @Param days query int false "Days to look back" default(30)
+// @Param agent_version query string false "Filter by agent version"
+// @Success 200 {object} models.CostReport
+// @Router /api/v1/analytics/costs [get]
+func (s *Server) getCosts(c *gin.Context) {
+	groupBy := c.DefaultQuery("group_by", "agent_version")
+	if _, ok := repository.CostGroupings[groupBy]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be agent_version or day"})
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+
+	buckets, err := s.projectRepo(c).GetCosts(groupBy, since, c.Query("agent_version"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	report := models.CostReport{GroupBy: groupBy, Since: since, Buckets: buckets}
+	for _, bucket := range buckets {
+		report.Conversations += bucket.Conversations
+		report.PricedConversations += bucket.PricedConversations
+		report.CostUSD += bucket.CostUSD
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// parseAsOf parses the optional as_of parameter that restricts aggregates to
+// data created at or before it, defaulting to now
+func parseAsOf(c *gin.Context) (time.Time, error) {
+	now := time.Now().UTC()
+	asOf, err := parseTimeQuery(c, "as_of")
+	if err != nil || asOf == nil {
+		return now, err
+	}
+	if asOf.After(now) {
+		return now, errors.New("as_of must not be in the future")
+	}
+	return asOf.UTC(), nil
+}
+
+// parseTimeQuery parses an optional RFC3339 query parameter
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: must be RFC3339", key)
+	}
+
+	return &t, nil
+}
+
+// getHealthLeaderboard ranks agent versions by average conversation health
+// @Summary Get conversation health leaderboard
+// @Tags Analytics
+// @Produce json
+// @Param days query int false "Days to analyze" default(30)
+// @Param limit query int false "Limit" default(20)
+// @Param as_of query string false "Recompute health from data that existed at this time (RFC3339)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/analytics/health-leaderboard [get]
+func (s *Server) getHealthLeaderboard(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cfg := s.pipeline.Get()
+
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	since := asOf.AddDate(0, 0, -days)
+
+	var entries []models.HealthLeaderboardEntry
+	if c.Query("as_of") != "" {
+		entries, err = s.projectRepo(c).GetHealthLeaderboardAsOf(cfg.Health, cfg.Routing.HealthThreshold, since, asOf, limit)
+	} else {
+		entries, err = s.projectRepo(c).GetHealthLeaderboard(cfg.Routing.HealthThreshold, since, limit)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"leaderboard": entries,
+		"count":       len(entries),
+		"as_of":       asOf,
+	})
+}
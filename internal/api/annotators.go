@@ -0,0 +1,260 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ai-agent-eval/internal/embeddings"
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maxReferenceCandidates bounds how many adjudicated conversations are compared
+const maxReferenceCandidates = 500
+
+// listAnnotatorPerformance returns annotator performance and specializations
+// @Summary List annotator performance
+// @Tags Annotations
+// @Produce json
+// @Param anonymize_annotators query bool false "Replace annotator IDs with the project's pseudonyms"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/annotations/annotators [get]
+func (s *Server) listAnnotatorPerformance(c *gin.Context) {
+	pseudonymizer, err := s.requestAnnotatorPseudonymizer(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	performance, err := s.repo.ListAnnotatorPerformance()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if pseudonymizer != nil {
+		pseudonymizer.Performance(performance)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"annotators": performance,
+		"count":      len(performance),
+	})
+}
+
+// inferAnnotatorSpecializations recomputes annotator specializations
+// @Summary Infer annotator specializations
+// @Tags Annotations
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/annotations/annotators/specializations [post]
+func (s *Server) inferAnnotatorSpecializations(c *gin.Context) {
+	performance, err := s.repo.RefreshAnnotatorSpecializations(s.cfg.SpecializationMinSamples, s.cfg.SpecializationMinAccuracy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"annotators": performance,
+		"count":      len(performance),
+	})
+}
+
+// getReliabilityTrend returns inter-rater reliability per annotation type over time
+// @Summary Get annotation reliability trend
+// @Description Percent agreement and Fleiss' kappa per annotation type and period, over conversations annotated by at least two annotators, with the recorded drops below the reliability threshold.
+// @Tags Annotations
+// @Produce json
+// @Param annotation_type query string false "Filter by annotation type"
+// @Param interval query string false "Period (day or week)" default(week)
+// @Param days query int false "Days to look back" default(90)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/annotations/reliability-trend [get]
+func (s *Server) getReliabilityTrend(c *gin.Context) {
+	interval := c.DefaultQuery("interval", services.ReliabilityWeekly)
+	if interval != services.ReliabilityDaily && interval != services.ReliabilityWeekly {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be day or week"})
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if days <= 0 {
+		days = 90
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	annotationType := c.Query("annotation_type")
+
+	counts, err := s.projectRepo(c).GetReliabilityLabelCounts(since, interval, annotationType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	events, err := s.repo.ListReliabilityEvents(annotationType, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := s.reliabilityPolicy()
+	points := services.ReliabilityTrend(counts, policy)
+	c.JSON(http.StatusOK, gin.H{
+		"interval":        interval,
+		"since":           since,
+		"kappa_threshold": policy.KappaThreshold,
+		"min_items":       policy.MinItems,
+		"trend":           points,
+		"count":           len(points),
+		"drops":           events,
+	})
+}
+
+// deanonymizeAnnotators maps annotator pseudonyms back to annotator IDs
+// @Summary De-anonymize annotators
+// @Description Resolves pseudonyms from the request project's exports and reports made with anonymize_annotators against the project's known annotators. Pseudonyms that match no annotator are listed as unresolved.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.AnnotatorDeanonymization true "Pseudonyms"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/annotators/deanonymize [post]
+func (s *Server) deanonymizeAnnotators(c *gin.Context) {
+	var req models.AnnotatorDeanonymization
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	projectID := c.GetString(projectKey)
+	pseudonymizer, err := s.annotatorPseudonymizer(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	annotatorIDs, err := s.projectRepo(c).ListAnnotatorIDs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resolved := pseudonymizer.Resolve(annotatorIDs, req.Pseudonyms)
+	unresolved := []string{}
+	for _, pseudonym := range req.Pseudonyms {
+		if _, ok := resolved[pseudonym]; !ok {
+			unresolved = append(unresolved, pseudonym)
+		}
+	}
+	log.Printf("De-anonymized %d annotator pseudonyms of project %s", len(resolved), projectID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"annotators": resolved,
+		"unresolved": unresolved,
+	})
+}
+
+// requestAnnotatorPseudonymizer returns the pseudonymizer of the request's
+// project when the request asks for anonymize_annotators, else nil
+func (s *Server) requestAnnotatorPseudonymizer(c *gin.Context) (*services.AnnotatorPseudonymizer, error) {
+	if anonymize, _ := strconv.ParseBool(c.Query("anonymize_annotators")); !anonymize {
+		return nil, nil
+	}
+	return s.annotatorPseudonymizer(c.GetString(projectKey))
+}
+
+// annotatorPseudonymizer returns the pseudonymizer of a project, giving the
+// project a salt the first time
+func (s *Server) annotatorPseudonymizer(projectID string) (*services.AnnotatorPseudonymizer, error) {
+	salt, err := services.NewAnnotatorSalt()
+	if err != nil {
+		return nil, err
+	}
+	if salt, err = s.repo.GetAnnotatorSalt(projectID, salt); err != nil {
+		return nil, err
+	}
+	return services.NewAnnotatorPseudonymizer(salt), nil
+}
+
+// reliabilityPolicy returns the configured reliability thresholds
+func (s *Server) reliabilityPolicy() services.ReliabilityPolicy {
+	alerts := s.pipeline.Get().Alerts
+	return services.ReliabilityPolicy{
+		KappaThreshold: alerts.ReliabilityKappaThreshold,
+		MinItems:       alerts.ReliabilityMinItems,
+	}
+}
+
+// getReferenceExamples returns the most similar adjudicated conversations and
+// their final labels, for annotators labeling a conversation
+// @Summary Get reference examples for annotation
+// @Tags Annotations
+// @Produce json
+// @Param conversation_id path string true "Conversation ID"
+// @Param annotation_type query string true "Annotation type"
+// @Param k query int false "Number of examples" default(5)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/annotations/examples/{conversation_id} [get]
+func (s *Server) getReferenceExamples(c *gin.Context) {
+	conversationID := c.Param("conversation_id")
+	annotationType := c.Query("annotation_type")
+	if annotationType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "annotation_type is required"})
+		return
+	}
+	k, _ := strconv.Atoi(c.DefaultQuery("k", "5"))
+	if k <= 0 {
+		k = 5
+	}
+
+	conv, err := s.projectRepo(c).GetConversation(conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if conv == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	candidates, err := s.projectRepo(c).ListAdjudicatedConversations(
+		annotationType, conversationID,
+		services.RequiredAnnotators(s.pipeline.Get().Routing, annotationType), maxReferenceCandidates,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	target := embeddings.EmbedTurns(conv.Turns)
+	examples := make([]models.ReferenceExample, 0, len(candidates))
+	for _, candidate := range candidates {
+		examples = append(examples, models.ReferenceExample{
+			AdjudicatedConversation: candidate,
+			Similarity:              embeddings.Cosine(target, embeddings.EmbedTurns(candidate.Turns)),
+		})
+	}
+
+	sort.SliceStable(examples, func(i, j int) bool { return examples[i].Similarity > examples[j].Similarity })
+	if len(examples) > k {
+		examples = examples[:k]
+	}
+
+	profile := s.maskingProfile(c)
+	for i := range examples {
+		turns, err := services.MaskTurnsJSON(examples[i].Turns, profile)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		examples[i].Turns = turns
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"conversation_id": conversationID,
+		"annotation_type": annotationType,
+		"examples":        examples,
+		"count":           len(examples),
+	})
+}
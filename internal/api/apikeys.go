@@ -0,0 +1,171 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// apiKeyHeader carries the API key of a request
+const apiKeyHeader = "X-API-Key"
+
+// apiKeyNameKey is the context key of the authenticated API key's name
+const apiKeyNameKey = "api_key"
+
+// apiKeyProjectKey is the context key of the project the authenticated API
+// key is bound to, if any
+const apiKeyProjectKey = "api_key_project"
+
+// apiKeyScopesKey is the context key of the authenticated API key's scopes
+const apiKeyScopesKey = "api_key_scopes"
+
+// adminAPIKeyName names requests authenticated by the bootstrap admin key
+const adminAPIKeyName = "admin"
+
+// apiKeyMiddleware authenticates requests by API key when API key
+// authentication is enabled, and rejects keys that lack the route's scope.
+// Internal routes authenticate service accounts instead.
+func (s *Server) apiKeyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if !s.cfg.APIKeyAuthEnabled || route == "" || strings.Contains(route, "/internal/") {
+			c.Next()
+			return
+		}
+		// Already authenticated by bearer token
+		if _, ok := c.Get(jwtSubjectKey); ok {
+			c.Next()
+			return
+		}
+
+		key := c.GetHeader(apiKeyHeader)
+		if key == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing API key"})
+			return
+		}
+		required := services.RequiredAPIKeyScope(c.Request.Method, route)
+
+		if s.cfg.AdminAPIKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(s.cfg.AdminAPIKey)) == 1 {
+			c.Set(apiKeyNameKey, adminAPIKeyName)
+			c.Next()
+			return
+		}
+
+		apiKey, err := s.repo.GetAPIKeyByHash(services.HashAPIKey(key))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if apiKey == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			return
+		}
+
+		var scopes []string
+		json.Unmarshal(apiKey.Scopes, &scopes)
+		if !services.APIKeyGrants(scopes, required) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks scope " + required})
+			return
+		}
+
+		c.Set(apiKeyNameKey, apiKey.Name)
+		c.Set(apiKeyProjectKey, apiKey.ProjectID)
+		c.Set(apiKeyScopesKey, scopes)
+		c.Next()
+	}
+}
+
+// createAPIKey creates an API key and returns the key
+// @Summary Create API key
+// @Description The key is only returned in this response; store it securely. Send it in the X-API-Key header. Keys bound to a project only see that project's data.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param key body models.APIKeyCreate true "API key"
+// @Success 201 {object} models.APIKeySecret
+// @Router /api/v1/apikeys [post]
+func (s *Server) createAPIKey(c *gin.Context) {
+	var req models.APIKeyCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateAPIKeyScopes(req.Scopes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ProjectID != "" {
+		if services.APIKeyGrants(req.Scopes, services.APIKeyScopeAdmin) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Project API keys may not have the admin scope"})
+			return
+		}
+		project, err := s.repo.GetProject(req.ProjectID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if project == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Project not found"})
+			return
+		}
+	}
+
+	key, prefix, hash, err := services.NewAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	apiKey, err := s.repo.CreateAPIKey(uuid.New().String(), req.Name, prefix, hash, req.Scopes, req.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIKeySecret{APIKey: *apiKey, Key: key})
+}
+
+// listAPIKeys lists API keys
+// @Summary List API keys
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/apikeys [get]
+func (s *Server) listAPIKeys(c *gin.Context) {
+	keys, err := s.repo.ListAPIKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"api_keys": keys,
+		"count":    len(keys),
+	})
+}
+
+// revokeAPIKey revokes an API key
+// @Summary Revoke API key
+// @Tags Admin
+// @Param id path string true "API key ID"
+// @Success 204
+// @Router /api/v1/apikeys/{id} [delete]
+func (s *Server) revokeAPIKey(c *gin.Context) {
+	revoked, err := s.repo.RevokeAPIKey(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
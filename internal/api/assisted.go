@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// assistedLabeling returns the assisted labeling settings of the request's
+// project
+func (s *Server) assistedLabeling(c *gin.Context) (models.AssistedLabeling, error) {
+	project, err := s.repo.GetProject(c.GetString(projectKey))
+	if err != nil || project == nil {
+		return models.AssistedLabeling{}, err
+	}
+	return services.ParseAssistedLabeling(project.AssistedLabeling), nil
+}
+
+// annotationAssist decides the assisted labeling arm of an annotator's form
+// for a conversation and the machine suggestion for it. The arm is nil when
+// the project doesn't use assisted labeling, and the suggestion nil when the
+// conversation hasn't been evaluated; it is returned in both arms so that
+// the control arm's annotations can be compared with it too.
+func (s *Server) annotationAssist(c *gin.Context, conversationID, annotatorID string) (*bool, *models.AnnotationSuggestion, error) {
+	settings, err := s.assistedLabeling(c)
+	if err != nil || !settings.Enabled {
+		return nil, nil, err
+	}
+	assisted := services.AssistedArm(settings, conversationID, annotatorID)
+
+	eval, err := s.projectRepo(c).GetLatestEvaluationForConversation(conversationID)
+	if err != nil || eval == nil {
+		return &assisted, nil, err
+	}
+	return &assisted, services.NewAnnotationSuggestion(eval), nil
+}
+
+// getAnnotationForm returns what an annotator's form for a conversation
+// starts with
+// @Summary Get annotation form
+// @Description For projects using assisted labeling, forms in the assisted arm are pre-filled with the latest automated evaluation's issue types, score and issues to highlight, marked machine_suggested. Forms in the control arm and forms of other projects start empty. The arm is fixed per conversation and annotator, and annotations submitted from the form are recorded with it.
+// @Tags Annotations
+// @Produce json
+// @Param conversation_id path string true "Conversation ID"
+// @Param annotator_id query string true "Annotator ID"
+// @Param annotation_type query string true "Annotation type"
+// @Success 200 {object} models.AnnotationForm
+// @Router /api/v1/annotations/form/{conversation_id} [get]
+func (s *Server) getAnnotationForm(c *gin.Context) {
+	form := models.AnnotationForm{
+		ConversationID: c.Param("conversation_id"),
+		AnnotatorID:    c.Query("annotator_id"),
+		AnnotationType: c.Query("annotation_type"),
+	}
+	if form.AnnotatorID == "" || form.AnnotationType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "annotator_id and annotation_type are required"})
+		return
+	}
+
+	conv, err := s.projectRepo(c).GetConversation(form.ConversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if conv == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	assisted, suggestion, err := s.annotationAssist(c, form.ConversationID, form.AnnotatorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	form.Assisted = assisted
+	if assisted != nil && *assisted {
+		form.Suggestion = suggestion
+	}
+
+	c.JSON(http.StatusOK, form)
+}
+
+// setAssistedLabeling turns assisted labeling on or off for a project
+// @Summary Set project assisted labeling
+// @Description assisted_fraction is the share of annotation forms pre-filled with the machine suggestion; the rest form the control arm the bias report compares against.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param project_id path string true "Project ID"
+// @Param settings body models.AssistedLabeling true "Assisted labeling settings"
+// @Success 200 {object} models.Project
+// @Router /api/v1/projects/{project_id}/assisted-labeling [put]
+func (s *Server) setAssistedLabeling(c *gin.Context) {
+	var settings models.AssistedLabeling
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateAssistedLabeling(settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := s.repo.SetProjectAssistedLabeling(c.Param("project_id"), settings)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if project == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// getAssistedLabelingReport compares the arms of the project's assisted
+// labeling
+// @Summary Get assisted labeling bias
+// @Description Compares how far annotations in the assisted and control arms agree with the machine suggestion. A positive bias means annotators shown the suggestion follow it more than those who weren't.
+// @Tags Annotations
+// @Produce json
+// @Param days query int false "Days to look back" default(30)
+// @Success 200 {object} models.AssistedLabelingReport
+// @Router /api/v1/annotations/assisted-labeling [get]
+func (s *Server) getAssistedLabelingReport(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+
+	settings, err := s.assistedLabeling(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	annotations, err := s.projectRepo(c).ListAssistedAnnotations(since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, services.CompareAssistedLabeling(c.GetString(projectKey), since, settings, annotations))
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ai-agent-eval/internal/blobstore"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// getAttachment serves a stored attachment's content
+// @Summary Get attachment content
+// @Description Serves attachment content stored at ingestion, by the SHA-256 in its blob:// URL. Multimodal evaluators fetch attachments through this endpoint. Only callers who see full transcripts may fetch content, and only of attachments of their project's conversations.
+// @Tags Conversations
+// @Produce application/octet-stream
+// @Param sha256 path string true "Hex SHA-256 of the content"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/attachments/{sha256} [get]
+func (s *Server) getAttachment(c *gin.Context) {
+	if s.maskingProfile(c) != services.MaskingFull {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Attachment content requires the full masking profile"})
+		return
+	}
+	referenced, err := s.projectRepo(c).AttachmentReferenced(c.Param("sha256"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !referenced {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return
+	}
+
+	data, contentType, err := s.attachments.Get(c.Request.Context(), c.Param("sha256"))
+	if errors.Is(err, blobstore.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	// Content is addressed by its hash, so it never changes
+	c.Header("Cache-Control", "private, max-age=31536000, immutable")
+	c.Data(http.StatusOK, contentType, data)
+}
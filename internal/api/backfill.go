@@ -0,0 +1,332 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Backfill states
+const (
+	backfillIdle        = "idle"
+	backfillRunning     = "running"
+	backfillCompleted   = "completed"
+	backfillInterrupted = "interrupted" // Its replica stopped before finishing
+)
+
+// The backfill rate starts at backfillStartFraction of the target and ramps
+// up by the same fraction as evaluations succeed
+const backfillStartFraction = 0.1
+
+// backfillOutcomeBatch caps how many queued tasks are checked for an outcome
+// per enqueued task
+const backfillOutcomeBatch = 20
+
+// backfillLease is how long a running backfill holds its lock without
+// renewing it. Progress renews it, and so does a heartbeat while the rate
+// limit waits.
+const backfillLease = time.Minute
+
+// evaluatorProbeTimeout bounds the evaluator health check before a backfill
+const evaluatorProbeTimeout = 5 * time.Second
+
+// backfillTracker records the progress of the evaluation backfill this
+// replica runs. Progress is saved to Redis, where every replica reads it and
+// where the lock keeps replicas from running two backfills at once.
+type backfillTracker struct {
+	mu     sync.Mutex
+	token  string // Of the lock held while running
+	status models.BackfillStatus
+	lost   bool // The lock expired and another backfill may have started
+}
+
+// start takes the shared lock for a backfill with the given progress. It
+// returns false if another backfill is running.
+func (t *backfillTracker) start(q *queue.RedisQueue, status models.BackfillStatus) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	token, err := q.StartBackfill(&status, backfillLease)
+	if err != nil || token == "" {
+		return false, err
+	}
+	t.token = token
+	t.status = status
+	t.lost = false
+	return true, nil
+}
+
+// update applies fn to the progress under the lock and saves it, renewing
+// the shared lock for lease, or releasing it when lease is 0
+func (t *backfillTracker) update(q *queue.RedisQueue, lease time.Duration, fn func(status *models.BackfillStatus)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fn(&t.status)
+	if t.lost {
+		return
+	}
+	saved, err := q.SaveBackfill(t.token, &t.status, lease)
+	if err != nil {
+		// The next update saves it again
+		log.Printf("Failed to save backfill progress: %v", err)
+		return
+	}
+	if !saved {
+		log.Printf("Backfill lock expired; stopping the backfill")
+		t.lost = true
+	}
+}
+
+// stopped reports whether the backfill lost its lock and must stop
+func (t *backfillTracker) stopped() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lost
+}
+
+// backfillEvaluations enqueues evaluations for conversations that never got one
+// @Summary Backfill missing evaluations
+// @Description Finds conversations without an evaluation and enqueues them at a throttled rate. The rate ramps up to the requested one as evaluations succeed and backs off when they fail. Only one backfill runs at a time across replicas; starting another returns 409 with its progress. Returns 503 if the evaluator service is unhealthy.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.BackfillRequest false "Backfill options"
+// @Success 202 {object} models.BackfillStatus
+// @Router /api/v1/admin/backfill/evaluations [post]
+func (s *Server) backfillEvaluations(c *gin.Context) {
+	var req models.BackfillRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.RatePerSecond == 0 {
+		req.RatePerSecond = s.cfg.BackfillRatePerSecond
+	}
+
+	// Don't start a burst against an evaluator service that is already down
+	probeCtx, cancel := context.WithTimeout(c.Request.Context(), evaluatorProbeTimeout)
+	_, err := s.evaluatorSvc.Probe(probeCtx)
+	cancel()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids, err := s.repo.ListUnevaluatedConversationIDs(time.Now().UTC().Add(-s.cfg.BackfillMinAge), req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	startedAt := time.Now().UTC()
+	status := models.BackfillStatus{
+		Status:               backfillRunning,
+		Total:                len(ids),
+		RatePerSecond:        req.RatePerSecond,
+		CurrentRatePerSecond: req.RatePerSecond * backfillStartFraction,
+		StartedAt:            &startedAt,
+	}
+	started, err := s.backfill.start(s.queue, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !started {
+		current, err := s.backfillStatus()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusConflict, current)
+		return
+	}
+
+	go s.runBackfill(ids, req.RatePerSecond)
+
+	c.JSON(http.StatusAccepted, status)
+}
+
+// getBackfillStatus reports the progress of the latest evaluation backfill
+// @Summary Get evaluation backfill progress
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} models.BackfillStatus
+// @Router /api/v1/admin/backfill/evaluations [get]
+func (s *Server) getBackfillStatus(c *gin.Context) {
+	status, err := s.backfillStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// backfillStatus returns the progress of the latest backfill on any replica
+func (s *Server) backfillStatus() (models.BackfillStatus, error) {
+	status, locked, err := s.queue.GetBackfill()
+	if err != nil {
+		return models.BackfillStatus{}, err
+	}
+	if status == nil {
+		return models.BackfillStatus{Status: backfillIdle}, nil
+	}
+	if status.Status == backfillRunning && !locked {
+		status.Status = backfillInterrupted
+	}
+	return *status, nil
+}
+
+// listReevaluationBaselines lists the scoring configurations seen by the
+// stale re-evaluation policy
+// @Summary List stale re-evaluation baselines
+// @Description Each time the evaluator version, evaluator types or their versions, score weights or judge model change, up to STALE_REEVALUATION_SAMPLE_SIZE conversations last evaluated before the change are re-evaluated, annotated ones first. Lists the configurations newest first with how many conversations were queued and evaluated under each.
+// @Tags Admin
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/reevaluation/baselines [get]
+func (s *Server) listReevaluationBaselines(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 {
+		limit = 20
+	}
+
+	baselines, err := s.repo.ListReevaluationBaselines(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"baselines": baselines,
+		"count":     len(baselines),
+	})
+}
+
+// runBackfill enqueues the conversations no faster than ratePerSecond,
+// starting slower and ramping up while the queued evaluations succeed
+func (s *Server) runBackfill(conversationIDs []string, ratePerSecond float64) {
+	step := ratePerSecond * backfillStartFraction
+	limit := services.NewAdaptiveLimit(services.RampPolicy{
+		Start:        step,
+		Max:          ratePerSecond,
+		Step:         step,
+		MaxErrorRate: s.cfg.DispatchErrorRateThreshold,
+		Cooldown:     s.cfg.DispatchBackoffCooldown,
+	})
+
+	// Keep the lock while the rate limit holds off progress
+	done := make(chan struct{})
+	heartbeat := make(chan struct{})
+	go func() {
+		defer close(heartbeat)
+		ticker := time.NewTicker(backfillLease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				s.backfill.update(s.queue, backfillLease, func(*models.BackfillStatus) {})
+			}
+		}
+	}()
+
+	var pending []string // Queued tasks without an outcome yet
+	var lastErr error
+	for i, conversationID := range conversationIDs {
+		if s.backfill.stopped() {
+			break
+		}
+		if i > 0 {
+			limit.Wait(context.Background())
+			time.Sleep(time.Duration(float64(time.Second) / limit.Limit()))
+		}
+		pending = s.recordBackfillOutcomes(pending, limit)
+
+		projectID, err := s.repo.GetConversationProject(conversationID)
+		taskID := ""
+		if err == nil {
+			taskID, err = s.queueEvaluation(conversationID, projectID, queue.TriggerBackfill, 0, time.Time{})
+		}
+		if err != nil {
+			log.Printf("Backfill failed to queue evaluation for %s: %v", conversationID, err)
+			lastErr = err
+		} else {
+			pending = append(pending, taskID)
+		}
+		s.backfill.update(s.queue, backfillLease, func(status *models.BackfillStatus) {
+			if err != nil {
+				status.Failed++
+			} else {
+				status.Enqueued++
+			}
+			status.CurrentRatePerSecond = limit.Limit()
+		})
+	}
+
+	close(done)
+	<-heartbeat
+
+	s.backfill.update(s.queue, 0, func(status *models.BackfillStatus) {
+		finishedAt := time.Now().UTC()
+		status.FinishedAt = &finishedAt
+		status.Status = backfillCompleted
+		if lastErr != nil {
+			status.Error = lastErr.Error()
+		}
+	})
+}
+
+// recordBackfillOutcomes feeds the outcomes of finished backfill tasks into
+// the rate limit and returns the tasks still pending. Tasks whose status
+// can't be read are kept for the next check.
+func (s *Server) recordBackfillOutcomes(pending []string, limit *services.AdaptiveLimit) []string {
+	checked := len(pending)
+	if checked > backfillOutcomeBatch {
+		checked = backfillOutcomeBatch
+	}
+
+	remaining := pending[:0]
+	for i, taskID := range pending {
+		if i >= checked {
+			remaining = append(remaining, taskID)
+			continue
+		}
+
+		status, err := s.queue.GetTaskStatus(taskID)
+		if err != nil {
+			remaining = append(remaining, taskID)
+			continue
+		}
+		if status == nil {
+			continue // Expired
+		}
+
+		switch status.State {
+		case queue.TaskStateCompleted, queue.TaskStateFailed:
+			if limit.Record(status.State == queue.TaskStateFailed, time.Now()) {
+				log.Printf("Backfill evaluations are failing; slowing to %.2f/s for %s", limit.Limit(), s.cfg.DispatchBackoffCooldown)
+				s.backfill.update(s.queue, backfillLease, func(status *models.BackfillStatus) {
+					status.Backoffs++
+				})
+			}
+		default:
+			remaining = append(remaining, taskID)
+		}
+	}
+
+	return remaining
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"log"
+
+	"github.com/ai-agent-eval/internal/database"
+	"github.com/ai-agent-eval/internal/repository"
+)
+
+// Cache scopes published on the invalidation channel
+const (
+	cacheScopeConfig    = "config"
+	cacheScopeAnalytics = "analytics" // Aggregates cached by the public API
+	cacheScopeRollouts  = repository.InvalidationScopeRollouts
+)
+
+// reloadPipelineConfig reloads the pipeline configuration from the latest stored bundle
+func (s *Server) reloadPipelineConfig() {
+	bundle, err := s.repo.GetLatestConfigBundle()
+	if err != nil {
+		log.Printf("Failed to load pipeline configuration: %v", err)
+		return
+	}
+	if bundle != nil {
+		s.pipeline.Set(bundle.Config)
+	}
+}
+
+// invalidateCache reloads the cache for a scope. An empty scope reloads everything.
+func (s *Server) invalidateCache(scope string) {
+	switch scope {
+	case cacheScopeConfig:
+		s.reloadPipelineConfig()
+	case cacheScopeAnalytics:
+		s.publicCache.clear()
+	case cacheScopeRollouts:
+		s.rollouts.invalidate()
+	case "":
+		s.reloadPipelineConfig()
+		s.publicCache.clear()
+		s.rollouts.invalidate()
+	default:
+		log.Printf("Ignoring invalidation for unknown cache scope %q", scope)
+	}
+}
+
+// publishInvalidation notifies other replicas that a cache scope changed
+func (s *Server) publishInvalidation(scope string) {
+	if err := s.repo.PublishInvalidation(scope); err != nil {
+		log.Printf("Failed to publish cache invalidation: %v", err)
+	}
+}
+
+// ListenForInvalidations reloads in-process caches when another replica
+// publishes an invalidation, until ctx is cancelled
+func (s *Server) ListenForInvalidations(ctx context.Context) error {
+	return database.Listen(ctx, s.cfg.DatabaseURL, database.InvalidationChannel, s.invalidateCache)
+}
@@ -0,0 +1,394 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// requestActor names who is acting: the bearer token's subject, the API
+// key's name, or else the name given in the request
+func requestActor(c *gin.Context, given string) string {
+	if subject := c.GetString(jwtSubjectKey); subject != "" {
+		return subject
+	}
+	if name := c.GetString(apiKeyNameKey); name != "" {
+		return name
+	}
+	return given
+}
+
+// canChangeComment reports whether the caller may edit or delete a comment.
+// Callers with a bearer token may only change their own comments.
+func canChangeComment(c *gin.Context, comment *models.Comment) bool {
+	subject := c.GetString(jwtSubjectKey)
+	return subject == "" || subject == comment.Author
+}
+
+// createComment comments on a conversation, one of its turns or one of its
+// evaluations, or replies to a comment thread
+// @Summary Comment on a conversation
+// @Description Starts a comment thread on the conversation, on one of its turns (turn_id) or on one of its evaluations (evaluation_id), or replies to a thread (parent_id). Replies to a reply join its thread, and replies take their thread's target. Names mentioned with @name are recorded and published in a comment_created activity event.
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param conversation_id path string true "Conversation ID"
+// @Param comment body models.CommentCreate true "Comment"
+// @Success 201 {object} models.Comment
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/conversations/{conversation_id}/comments [post]
+func (s *Server) createComment(c *gin.Context) {
+	var req models.CommentCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	repo := s.projectRepo(c)
+	conv, err := repo.GetConversation(c.Param("conversation_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if conv == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	comment := models.Comment{
+		ConversationID: conv.ConversationID,
+		Author:         requestActor(c, req.Author),
+		Body:           req.Body,
+	}
+	if comment.Author == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "author is required"})
+		return
+	}
+
+	if req.ParentID != nil {
+		parent, err := repo.GetComment(*req.ParentID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if parent == nil || parent.ConversationID != conv.ConversationID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parent_id is not a comment on this conversation"})
+			return
+		}
+		comment.ParentID = &parent.ID
+		if parent.ParentID != nil {
+			comment.ParentID = parent.ParentID
+		}
+		comment.TurnID = parent.TurnID
+		comment.EvaluationID = parent.EvaluationID
+	} else {
+		if req.TurnID != nil {
+			var turns []models.Turn
+			if err := json.Unmarshal(conv.Turns, &turns); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			found := false
+			for _, turn := range turns {
+				found = found || turn.TurnID == *req.TurnID
+			}
+			if !found {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "turn_id is not a turn of this conversation"})
+				return
+			}
+			comment.TurnID = req.TurnID
+		}
+		if req.EvaluationID != "" {
+			eval, err := repo.GetEvaluation(req.EvaluationID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if eval == nil || eval.ConversationID != conv.ConversationID {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "evaluation_id is not an evaluation of this conversation"})
+				return
+			}
+			comment.EvaluationID = &req.EvaluationID
+		}
+	}
+
+	mentions := services.ParseMentions(req.Body)
+	created, err := repo.CreateComment(&comment, mentions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	event := models.ActivityEvent{
+		Type:           models.ActivityCommentCreated,
+		ProjectID:      conv.ProjectID,
+		ConversationID: conv.ConversationID,
+		AgentVersion:   conv.AgentVersion,
+		CommentID:      created.ID,
+		Mentions:       mentions,
+		At:             time.Now().UTC(),
+	}
+	if created.EvaluationID != nil {
+		event.EvaluationID = *created.EvaluationID
+	}
+	s.publishActivity(event)
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// listComments lists a conversation's comment threads
+// @Summary List comment threads
+// @Tags Comments
+// @Produce json
+// @Param conversation_id path string true "Conversation ID"
+// @Param turn_id query int false "Only threads on this turn"
+// @Param evaluation_id query string false "Only threads on this evaluation"
+// @Param resolved query bool false "Only resolved or only open threads"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/conversations/{conversation_id}/comments [get]
+func (s *Server) listComments(c *gin.Context) {
+	filter := models.CommentFilter{EvaluationID: c.Query("evaluation_id")}
+	if value := c.Query("turn_id"); value != "" {
+		turnID, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid turn_id"})
+			return
+		}
+		filter.TurnID = &turnID
+	}
+	resolved, err := resolvedQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.Resolved = resolved
+
+	repo := s.projectRepo(c)
+	conversationID := c.Param("conversation_id")
+	projectID, err := repo.GetConversationProject(conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if projectID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	comments, err := repo.ListComments(conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	threads := services.CommentThreads(comments, filter)
+
+	c.JSON(http.StatusOK, gin.H{
+		"conversation_id": conversationID,
+		"threads":         threads,
+		"count":           len(threads),
+	})
+}
+
+// updateComment edits a comment
+// @Summary Edit a comment
+// @Description Mentions are taken again from the new body. Callers with a bearer token may only edit their own comments.
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param comment_id path int true "Comment ID"
+// @Param comment body models.CommentUpdate true "Comment"
+// @Success 200 {object} models.Comment
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/comments/{comment_id} [put]
+func (s *Server) updateComment(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var req models.CommentUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	repo := s.projectRepo(c)
+	comment, err := repo.GetComment(commentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if comment == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if !canChangeComment(c, comment) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can edit a comment"})
+		return
+	}
+
+	updated, err := repo.UpdateComment(commentID, req.Body, services.ParseMentions(req.Body))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if updated == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// deleteComment deletes a comment
+// @Summary Delete a comment
+// @Description Deleting a thread's first comment deletes its replies. Callers with a bearer token may only delete their own comments.
+// @Tags Comments
+// @Produce json
+// @Param comment_id path int true "Comment ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/comments/{comment_id} [delete]
+func (s *Server) deleteComment(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	repo := s.projectRepo(c)
+	comment, err := repo.GetComment(commentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if comment == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if !canChangeComment(c, comment) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can delete a comment"})
+		return
+	}
+
+	if _, err := repo.DeleteComment(commentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "deleted",
+		"comment_id": commentID,
+	})
+}
+
+// resolveComment resolves or reopens a comment thread
+// @Summary Resolve a comment thread
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param comment_id path int true "ID of the thread's first comment"
+// @Param resolution body models.CommentResolution true "Resolution"
+// @Success 200 {object} models.Comment
+// @Router /api/v1/comments/{comment_id}/resolution [put]
+func (s *Server) resolveComment(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var req models.CommentResolution
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	repo := s.projectRepo(c)
+	resolved, err := repo.ResolveComment(commentID, req.Resolved, requestActor(c, req.ResolvedBy))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if resolved != nil {
+		c.JSON(http.StatusOK, resolved)
+		return
+	}
+
+	comment, err := repo.GetComment(commentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if comment == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Only a thread's first comment can be resolved"})
+}
+
+// listCommentMentions lists the comments that mention someone
+// @Summary List comment mentions
+// @Description Lists comments mentioning name with @name, newest first, across the project's conversations. name defaults to the caller's bearer token subject or API key name.
+// @Tags Comments
+// @Produce json
+// @Param name query string false "Mentioned name"
+// @Param resolved query bool false "Only comments in resolved or only in open threads"
+// @Param limit query int false "Limit" default(100)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/comments/mentions [get]
+func (s *Server) listCommentMentions(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	name := c.Query("name")
+	if name == "" {
+		name = requestActor(c, "")
+	}
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	resolved, err := resolvedQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comments, err := s.projectRepo(c).ListCommentMentions(strings.ToLower(strings.TrimPrefix(name, "@")), resolved, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":     name,
+		"comments": comments,
+		"count":    len(comments),
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// resolvedQuery reads the optional resolved query parameter
+func resolvedQuery(c *gin.Context) (*bool, error) {
+	value := c.Query("resolved")
+	if value == "" {
+		return nil, nil
+	}
+	resolved, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, errors.New("resolved must be true or false")
+	}
+	return &resolved, nil
+}
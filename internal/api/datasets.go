@@ -0,0 +1,468 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// createDataset creates a dataset for the request's project
+// @Summary Create a dataset
+// @Description Creates a named set of items agent releases are regression tested on. Conversations produced from an item are ingested with its item_key in metadata.dataset_item.
+// @Tags Datasets
+// @Accept json
+// @Produce json
+// @Param dataset body models.DatasetCreate true "Dataset"
+// @Success 201 {object} models.Dataset
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/datasets [post]
+func (s *Server) createDataset(c *gin.Context) {
+	var req models.DatasetCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateDatasetItems(req.Items); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.CreatedBy = requestActor(c, req.CreatedBy)
+
+	dataset, err := s.repo.CreateDataset(c.GetString(projectKey), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if dataset == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "A dataset with this name already exists"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dataset)
+}
+
+// listDatasets lists the project's datasets
+// @Summary List datasets
+// @Tags Datasets
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/datasets [get]
+func (s *Server) listDatasets(c *gin.Context) {
+	datasets, err := s.repo.ListDatasets(c.GetString(projectKey))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"datasets": datasets,
+		"count":    len(datasets),
+	})
+}
+
+// getDataset retrieves a dataset with its items
+// @Summary Get a dataset
+// @Tags Datasets
+// @Produce json
+// @Param dataset_id path int true "Dataset ID"
+// @Success 200 {object} models.Dataset
+// @Router /api/v1/datasets/{dataset_id} [get]
+func (s *Server) getDataset(c *gin.Context) {
+	dataset, ok := s.requestDataset(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, dataset)
+}
+
+// replaceDatasetItems replaces the items of a dataset
+// @Summary Replace dataset items
+// @Description Later runs evaluate the new items; past runs keep the items they evaluated.
+// @Tags Datasets
+// @Accept json
+// @Produce json
+// @Param dataset_id path int true "Dataset ID"
+// @Param items body models.DatasetItemsUpdate true "Items"
+// @Success 200 {object} models.Dataset
+// @Router /api/v1/datasets/{dataset_id}/items [put]
+func (s *Server) replaceDatasetItems(c *gin.Context) {
+	datasetID, ok := datasetIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.DatasetItemsUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateDatasetItems(req.Items); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dataset, err := s.repo.ReplaceDatasetItems(c.GetString(projectKey), datasetID, req.Items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if dataset == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dataset not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dataset)
+}
+
+// deleteDataset deletes a dataset with its items and runs
+// @Summary Delete a dataset
+// @Tags Datasets
+// @Param dataset_id path int true "Dataset ID"
+// @Success 204
+// @Router /api/v1/datasets/{dataset_id} [delete]
+func (s *Server) deleteDataset(c *gin.Context) {
+	datasetID, ok := datasetIDParam(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := s.repo.DeleteDataset(c.GetString(projectKey), datasetID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dataset not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// createDatasetRun evaluates a dataset's items for an agent version
+// @Summary Start a regression run
+// @Description Queues an evaluation of the latest conversation of the agent version produced from each dataset item, matched by metadata.dataset_item. Items without such a conversation are recorded as missing. The run's aggregate scores fill in as the evaluations are stored, and it completes once none is pending.
+// @Tags Datasets
+// @Accept json
+// @Produce json
+// @Param dataset_id path int true "Dataset ID"
+// @Param run body models.DatasetRunCreate true "Run"
+// @Success 202 {object} models.DatasetRun
+// @Router /api/v1/datasets/{dataset_id}/runs [post]
+func (s *Server) createDatasetRun(c *gin.Context) {
+	dataset, ok := s.requestDataset(c)
+	if !ok {
+		return
+	}
+
+	var req models.DatasetRunCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(dataset.Items) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Dataset has no items"})
+		return
+	}
+
+	keys := make([]string, len(dataset.Items))
+	for i, item := range dataset.Items {
+		keys[i] = item.ItemKey
+	}
+	conversations, err := s.repo.GetDatasetItemConversations(dataset.ProjectID, req.AgentVersion, keys)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	run := &models.DatasetRun{
+		DatasetID:    dataset.ID,
+		AgentVersion: req.AgentVersion,
+		CreatedBy:    requestActor(c, req.CreatedBy),
+	}
+	items := make([]models.DatasetRunItem, len(keys))
+	for i, key := range keys {
+		items[i] = models.DatasetRunItem{ItemKey: key, Status: models.DatasetRunItemMissing}
+		if conversationID, ok := conversations[key]; ok {
+			items[i].ConversationID = &conversationID
+			items[i].Status = models.DatasetRunItemPending
+		} else {
+			run.MissingCount++
+		}
+	}
+
+	run, err = s.repo.CreateDatasetRun(run, items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, item := range items {
+		if item.ConversationID == nil {
+			continue
+		}
+		taskID, err := s.queueEvaluation(*item.ConversationID, dataset.ProjectID, queue.TriggerRegression, 0, time.Time{})
+		if err != nil {
+			log.Printf("Dataset run %d failed to queue evaluation for %s: %v", run.ID, item.ItemKey, err)
+		}
+		if err := s.repo.SetDatasetRunItemTask(run.ID, item.ItemKey, taskID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Completes right away when nothing could be queued
+	run, err = s.repo.RefreshDatasetRun(run.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
+
+// listDatasetRuns lists a dataset's runs with their aggregate scores
+// @Summary List regression runs
+// @Description Runs are listed newest first, to follow a dataset's scores over releases.
+// @Tags Datasets
+// @Produce json
+// @Param dataset_id path int true "Dataset ID"
+// @Param agent_version query string false "Only runs of this agent version"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/datasets/{dataset_id}/runs [get]
+func (s *Server) listDatasetRuns(c *gin.Context) {
+	dataset, ok := s.requestDataset(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	runs, err := s.repo.ListDatasetRuns(dataset.ID, c.Query("agent_version"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range runs {
+		if runs[i].Status != models.DatasetRunRunning {
+			continue
+		}
+		refreshed, err := s.refreshDatasetRun(&runs[i])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		runs[i] = *refreshed
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runs":   runs,
+		"count":  len(runs),
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// getDatasetRun retrieves a run with the outcome of each item
+// @Summary Get a regression run
+// @Tags Datasets
+// @Produce json
+// @Param dataset_id path int true "Dataset ID"
+// @Param run_id path int true "Run ID"
+// @Success 200 {object} models.DatasetRun
+// @Router /api/v1/datasets/{dataset_id}/runs/{run_id} [get]
+func (s *Server) getDatasetRun(c *gin.Context) {
+	dataset, ok := s.requestDataset(c)
+	if !ok {
+		return
+	}
+	runID, err := strconv.ParseInt(c.Param("run_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+		return
+	}
+
+	run, ok := s.datasetRunWithItems(c, dataset.ID, runID)
+	if !ok {
+		return
+	}
+	if run == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// compareDatasetRuns compares a run of a dataset to an earlier one
+// @Summary Compare regression runs
+// @Description Compares the candidate run's items to the base run's. An item regresses when its overall score drops by more than threshold or its verdict gets worse. candidate defaults to the latest run and base to the latest completed run before it that isn't stale. Gate a release on regressions being 0.
+// @Tags Datasets
+// @Produce json
+// @Param dataset_id path int true "Dataset ID"
+// @Param base query int false "Base run ID"
+// @Param candidate query int false "Candidate run ID"
+// @Param threshold query number false "Score drop that counts as a regression" default(0.05)
+// @Success 200 {object} models.DatasetRunComparison
+// @Router /api/v1/datasets/{dataset_id}/runs/compare [get]
+func (s *Server) compareDatasetRuns(c *gin.Context) {
+	dataset, ok := s.requestDataset(c)
+	if !ok {
+		return
+	}
+
+	threshold := services.DefaultRegressionThreshold
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be a non-negative number"})
+			return
+		}
+		threshold = parsed
+	}
+	var baseID, candidateID int64
+	for _, param := range []struct {
+		name string
+		dest *int64
+	}{{"base", &baseID}, {"candidate", &candidateID}} {
+		if value := c.Query(param.name); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || id <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param.name + " must be a run ID"})
+				return
+			}
+			*param.dest = id
+		}
+	}
+
+	if candidateID == 0 {
+		latest, err := s.repo.GetLatestDatasetRun(dataset.ID, 0, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if latest == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dataset has no runs"})
+			return
+		}
+		candidateID = latest.ID
+	}
+	if baseID == 0 {
+		previous, err := s.repo.GetLatestDatasetRun(dataset.ID, candidateID, true)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if previous == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No completed run before the candidate to compare with"})
+			return
+		}
+		baseID = previous.ID
+	}
+
+	base, ok := s.datasetRunWithItems(c, dataset.ID, baseID)
+	if !ok {
+		return
+	}
+	candidate, ok := s.datasetRunWithItems(c, dataset.ID, candidateID)
+	if !ok {
+		return
+	}
+	if base == nil || candidate == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, services.CompareDatasetRuns(base, candidate, threshold))
+}
+
+// datasetIDParam parses the dataset ID in the path, responding with an
+// error if it isn't one
+func datasetIDParam(c *gin.Context) (int64, bool) {
+	datasetID, err := strconv.ParseInt(c.Param("dataset_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dataset ID"})
+		return 0, false
+	}
+	return datasetID, true
+}
+
+// requestDataset loads the project's dataset named in the path, responding
+// with an error if it can't
+func (s *Server) requestDataset(c *gin.Context) (*models.Dataset, bool) {
+	datasetID, ok := datasetIDParam(c)
+	if !ok {
+		return nil, false
+	}
+
+	dataset, err := s.repo.GetDataset(c.GetString(projectKey), datasetID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if dataset == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dataset not found"})
+		return nil, false
+	}
+	return dataset, true
+}
+
+// datasetRunWithItems loads a dataset's run, brought up to date, with its
+// items, responding with an error if it can't. The run is nil if it doesn't
+// exist.
+func (s *Server) datasetRunWithItems(c *gin.Context, datasetID, runID int64) (*models.DatasetRun, bool) {
+	run, err := s.repo.GetDatasetRun(datasetID, runID)
+	if err == nil && run != nil && run.Status == models.DatasetRunRunning {
+		run, err = s.refreshDatasetRun(run)
+	}
+	if err == nil && run != nil {
+		run.Items, err = s.repo.ListDatasetRunItems(run.ID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return run, true
+}
+
+// refreshDatasetRun brings a running run up to date with the evaluations
+// stored for it, and fails the items whose evaluation task failed or
+// expired without storing one
+func (s *Server) refreshDatasetRun(run *models.DatasetRun) (*models.DatasetRun, error) {
+	refreshed, err := s.repo.RefreshDatasetRun(run.ID)
+	if err != nil || refreshed == nil || refreshed.Status != models.DatasetRunRunning {
+		return refreshed, err
+	}
+
+	pending, err := s.repo.ListPendingDatasetRunTasks(run.ID)
+	if err != nil {
+		return nil, err
+	}
+	var failed []string
+	for _, taskID := range pending {
+		status, err := s.queue.GetTaskStatus(taskID)
+		if err != nil {
+			return nil, err
+		}
+		if status == nil || status.State == queue.TaskStateFailed {
+			failed = append(failed, taskID)
+		}
+	}
+	if len(failed) == 0 {
+		return refreshed, nil
+	}
+
+	if err := s.repo.FailDatasetRunTasks(run.ID, failed); err != nil {
+		return nil, err
+	}
+	return s.repo.RefreshDatasetRun(run.ID)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+// debugTurnTimeout bounds the evaluation after each turn of a debugging
+// session, so feedback stays interactive
+const debugTurnTimeout = 30 * time.Second
+
+// debugIdleTimeout is how long a debugging session waits for the next turn.
+// It replaces the server's read and write timeouts, which are meant for
+// ordinary requests.
+const debugIdleTimeout = 10 * time.Minute
+
+// streamDebugEvaluation evaluates turns as a developer streams them in, for
+// testing an agent before its conversations are ingested. Nothing is stored.
+// @Summary Interactive turn-by-turn evaluation
+// @Description Stream turns as JSON lines in the request body; a feedback line with heuristic and coherence scores and newly found issues is streamed back after each one. Clients that can't stream a request body can send all turns at once.
+// @Tags Evaluation
+// @Accept x-ndjson
+// @Produce x-ndjson
+// @Param conversation_id query string false "Conversation ID the turns are evaluated under"
+// @Success 200 {object} models.DebugTurnFeedback
+// @Router /api/v1/debug/evaluate [post]
+func (s *Server) streamDebugEvaluation(c *gin.Context) {
+	// Lets HTTP/1.1 clients keep sending turns after the first feedback is
+	// written. HTTP/2 is full duplex already and reports it unsupported.
+	rc := http.NewResponseController(c.Writer)
+	rc.EnableFullDuplex()
+
+	session := services.NewDebugSession(c.DefaultQuery("conversation_id", "debug-"+uuid.New().String()))
+	decoder := json.NewDecoder(c.Request.Body)
+	encoder := json.NewEncoder(c.Writer)
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	rc.Flush()
+
+	send := func(feedback models.DebugTurnFeedback) bool {
+		if err := encoder.Encode(feedback); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	for {
+		rc.SetReadDeadline(time.Now().Add(debugIdleTimeout))
+		rc.SetWriteDeadline(time.Now().Add(debugIdleTimeout + debugTurnTimeout))
+
+		var turn models.Turn
+		if err := decoder.Decode(&turn); err != nil {
+			if !errors.Is(err, io.EOF) {
+				send(models.DebugTurnFeedback{Error: "invalid turn: " + err.Error()})
+			}
+			return
+		}
+		if err := binding.Validator.ValidateStruct(&turn); err != nil {
+			if !send(models.DebugTurnFeedback{TurnID: turn.TurnID, Error: err.Error()}) {
+				return
+			}
+			continue
+		}
+
+		turn, req, err := session.Add(turn)
+		if err != nil {
+			if !send(models.DebugTurnFeedback{TurnID: turn.TurnID, Error: err.Error()}) {
+				return
+			}
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), debugTurnTimeout)
+		result, err := s.evaluatorSvc.EvaluateContext(ctx, req, nil)
+		cancel()
+
+		feedback := models.DebugTurnFeedback{TurnID: turn.TurnID}
+		if err != nil {
+			feedback.Error = err.Error()
+		} else {
+			feedback = session.Feedback(turn.TurnID, result)
+		}
+		if !send(feedback) {
+			return
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/columnar"
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maxAnnotationExportLimit caps how many conversations one export covers
+const maxAnnotationExportLimit = 5000
+
+// exportAnnotations exports conversations as import-ready annotation tasks
+// @Summary Export annotation tasks
+// @Description Label Studio tasks are a JSON array with automated evaluations as predictions; Prodigy tasks are JSONL with detected issues pre-selected. Transcripts are masked with the caller's masking profile, named in X-Masking-Profile.
+// @Tags Annotations
+// @Produce json
+// @Produce x-ndjson
+// @Param format query string true "Task format (labelstudio or prodigy)"
+// @Param agent_version query string false "Filter by agent version"
+// @Param anonymize_annotators query bool false "Replace annotator IDs with the project's pseudonyms"
+// @Param limit query int false "Limit" default(1000)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} models.LabelStudioTask
+// @Router /api/v1/export/annotations [get]
+func (s *Server) exportAnnotations(c *gin.Context) {
+	format := c.Query("format")
+	if format != models.ExportFormatLabelStudio && format != models.ExportFormatProdigy {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be labelstudio or prodigy"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "1000"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > maxAnnotationExportLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxAnnotationExportLimit)})
+		return
+	}
+
+	pseudonymizer, err := s.requestAnnotatorPseudonymizer(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := s.projectRepo(c).ListAnnotationExportItems(c.Query("agent_version"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if pseudonymizer != nil {
+		pseudonymizer.ExportItems(items)
+	}
+	profile := s.maskingProfile(c)
+	for i := range items {
+		turns, err := services.MaskTurnsJSON(items[i].Conversation.Turns, profile)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		items[i].Conversation.Turns = turns
+	}
+
+	filename := fmt.Sprintf("annotations-%s-%s", format, time.Now().UTC().Format("20060102T150405Z"))
+
+	if format == models.ExportFormatProdigy {
+		tasks, err := services.ProdigyTasks(items)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`.jsonl"`)
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+		encoder := json.NewEncoder(c.Writer)
+		for _, task := range tasks {
+			if err := encoder.Encode(task); err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	tasks, err := services.LabelStudioTasks(items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+	c.JSON(http.StatusOK, tasks)
+}
+
+// evaluationExportBatchRows is how many rows go into each Parquet row group
+// or Arrow record batch
+const evaluationExportBatchRows = 65536
+
+// evaluationExportSchema is the column layout of columnar evaluation exports
+var evaluationExportSchema = []columnar.Column{
+	{Name: "evaluation_id", Type: columnar.String},
+	{Name: "conversation_id", Type: columnar.String},
+	{Name: "agent_version", Type: columnar.String},
+	{Name: "overall_score", Type: columnar.Float64},
+	{Name: "response_quality_score", Type: columnar.Float64},
+	{Name: "tool_accuracy_score", Type: columnar.Float64},
+	{Name: "coherence_score", Type: columnar.Float64},
+	{Name: "issue_count", Type: columnar.Int64},
+	{Name: "evaluator_version", Type: columnar.String},
+	{Name: "trigger_source", Type: columnar.String},
+	{Name: "evaluation_duration_ms", Type: columnar.Int64},
+	{Name: "created_at", Type: columnar.Timestamp},
+	{Name: "verdict", Type: columnar.String},
+}
+
+// exportEvaluationsParquet streams evaluation scores as a Parquet file
+// @Summary Export evaluations as Parquet
+// @Description Streams one row per evaluation, filtered in the database by creation time, agent version and the filters of the evaluation list
+// @Tags Analytics
+// @Produce application/vnd.apache.parquet
+// @Param from query string false "Only evaluations created at or after this time (RFC3339)"
+// @Param to query string false "Only evaluations created before this time (RFC3339)"
+// @Param agent_version query []string false "Only these agent versions (repeatable or comma separated)"
+// @Param conversation_id query string false "Filter by conversation"
+// @Param trigger_source query string false "Filter by trigger source"
+// @Param verdict query string false "Filter by verdict (pass, fail, needs_review)"
+// @Param min_score query number false "Minimum overall score"
+// @Param max_score query number false "Maximum overall score"
+// @Param limit query int false "Maximum rows; all matching rows by default"
+// @Param offset query int false "Rows to skip" default(0)
+// @Success 200 {file} file
+// @Router /api/v1/export/evaluations.parquet [get]
+func (s *Server) exportEvaluationsParquet(c *gin.Context) {
+	s.exportEvaluationsColumnar(c, "application/vnd.apache.parquet", "evaluations.parquet", func(w io.Writer) columnar.Writer {
+		return columnar.NewParquetWriter(w, evaluationExportSchema, "ai-agent-eval")
+	})
+}
+
+// exportEvaluationsArrow streams evaluation scores as an Arrow IPC stream
+// @Summary Export evaluations as an Arrow IPC stream
+// @Description Streams one row per evaluation, filtered in the database by creation time, agent version and the filters of the evaluation list
+// @Tags Analytics
+// @Produce application/vnd.apache.arrow.stream
+// @Param from query string false "Only evaluations created at or after this time (RFC3339)"
+// @Param to query string false "Only evaluations created before this time (RFC3339)"
+// @Param agent_version query []string false "Only these agent versions (repeatable or comma separated)"
+// @Param conversation_id query string false "Filter by conversation"
+// @Param trigger_source query string false "Filter by trigger source"
+// @Param verdict query string false "Filter by verdict (pass, fail, needs_review)"
+// @Param min_score query number false "Minimum overall score"
+// @Param max_score query number false "Maximum overall score"
+// @Param limit query int false "Maximum rows; all matching rows by default"
+// @Param offset query int false "Rows to skip" default(0)
+// @Success 200 {file} file
+// @Router /api/v1/export/evaluations.arrow [get]
+func (s *Server) exportEvaluationsArrow(c *gin.Context) {
+	s.exportEvaluationsColumnar(c, "application/vnd.apache.arrow.stream", "evaluations.arrow", func(w io.Writer) columnar.Writer {
+		return columnar.NewArrowWriter(w, evaluationExportSchema)
+	})
+}
+
+// exportEvaluations streams evaluation scores as a CSV or Parquet file
+// @Summary Export evaluations
+// @Description Streams one row per evaluation, oldest first, with the filters of the evaluation list. Rows are written as they are read, so exports of any size take constant memory.
+// @Tags Evaluation
+// @Produce text/csv
+// @Produce application/vnd.apache.parquet
+// @Param format query string true "File format (csv or parquet)"
+// @Param conversation_id query string false "Filter by conversation"
+// @Param trigger_source query string false "Filter by trigger source"
+// @Param verdict query string false "Filter by verdict (pass, fail, needs_review)"
+// @Param min_score query number false "Minimum overall score"
+// @Param max_score query number false "Maximum overall score"
+// @Param from query string false "Only evaluations created at or after this time (RFC3339)"
+// @Param to query string false "Only evaluations created before this time (RFC3339)"
+// @Param agent_version query []string false "Only these agent versions (repeatable or comma separated)"
+// @Param limit query int false "Maximum rows; all matching rows by default"
+// @Param offset query int false "Rows to skip" default(0)
+// @Success 200 {file} file
+// @Router /api/v1/evaluations/export [get]
+func (s *Server) exportEvaluations(c *gin.Context) {
+	switch c.Query("format") {
+	case "csv":
+		s.exportEvaluationsColumnar(c, "text/csv", "evaluations.csv", func(w io.Writer) columnar.Writer {
+			return columnar.NewCSVWriter(w, evaluationExportSchema)
+		})
+	case "parquet":
+		s.exportEvaluationsParquet(c)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or parquet"})
+	}
+}
+
+// exportEvaluationsColumnar streams the evaluations selected by the query
+// through a columnar writer, one batch at a time
+func (s *Server) exportEvaluationsColumnar(c *gin.Context, contentType, filename string, newWriter func(io.Writer) columnar.Writer) {
+	filter, err := parseEvaluationExportFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Large exports outlive the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Failed to lift write deadline for evaluation export: %v", err)
+	}
+
+	writer := newWriter(c.Writer)
+	batch := columnar.NewBatch(evaluationExportSchema)
+	started := false
+	flush := func() error {
+		if !started {
+			c.Header("Content-Type", contentType)
+			c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+			c.Status(http.StatusOK)
+			started = true
+		}
+		err := writer.WriteBatch(batch)
+		batch.Reset()
+		return err
+	}
+
+	err = s.projectRepo(c).StreamEvaluationExport(filter, func(row *models.EvaluationExportRow) error {
+		err := batch.Append(
+			row.EvaluationID, row.ConversationID, row.AgentVersion,
+			row.OverallScore, row.ResponseQualityScore, row.ToolAccuracyScore, row.CoherenceScore,
+			row.IssueCount, row.EvaluatorVersion, row.TriggerSource, row.EvaluationDurationMS,
+			row.CreatedAt, row.Verdict,
+		)
+		if err != nil {
+			return err
+		}
+		if batch.Len() >= evaluationExportBatchRows {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		if err = flush(); err == nil {
+			err = writer.Close()
+		}
+	}
+
+	if err != nil {
+		if !started {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		// Headers are gone; the truncated file is left without its footer
+		log.Printf("Evaluation export failed mid-stream: %v", err)
+	}
+}
+
+// parseEvaluationExportFilter reads the export filters from the query
+func parseEvaluationExportFilter(c *gin.Context) (models.EvaluationExportFilter, error) {
+	var filter models.EvaluationExportFilter
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		return filter, err
+	}
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		return filter, err
+	}
+	if from != nil {
+		filter.From = from.UTC()
+	}
+	if to != nil {
+		filter.To = to.UTC()
+	}
+	if from != nil && to != nil && !to.After(*from) {
+		return filter, errors.New("to must be after from")
+	}
+
+	for _, value := range c.QueryArray("agent_version") {
+		for _, version := range strings.Split(value, ",") {
+			if version = strings.TrimSpace(version); version != "" {
+				filter.AgentVersions = append(filter.AgentVersions, version)
+			}
+		}
+	}
+
+	filter.ConversationID = c.Query("conversation_id")
+	filter.TriggerSource = c.Query("trigger_source")
+	filter.Verdict = c.Query("verdict")
+	if filter.Verdict != "" && !services.IsVerdict(filter.Verdict) {
+		return filter, errors.New("verdict must be pass, fail or needs_review")
+	}
+	filter.MinScore, filter.MaxScore = scoreRangeQuery(c)
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return filter, errors.New("limit must be a non-negative integer")
+		}
+		filter.Limit = limit
+	}
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return filter, errors.New("offset must be a non-negative integer")
+		}
+		filter.Offset = offset
+	}
+
+	return filter, nil
+}
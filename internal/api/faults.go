@@ -0,0 +1,134 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/faults"
+	"github.com/gin-gonic/gin"
+)
+
+// SetFaults enables fault injection into API requests and evaluator calls,
+// and its admin endpoints
+func (s *Server) SetFaults(injector *faults.Injector) {
+	s.faults = injector
+	s.evaluatorSvc.SetFaults(injector)
+}
+
+// faultMiddleware injects the API fault into requests. Health checks and the
+// fault endpoints themselves are exempt so faults can always be cleared.
+func (s *Server) faultMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if s.faults == nil || path == "/health" || strings.Contains(path, "/admin/faults") {
+			c.Next()
+			return
+		}
+
+		if err := s.faults.Inject(c.Request.Context(), faults.TargetAPI); err != nil {
+			var injected *faults.Error
+			if errors.As(err, &injected) {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			} else {
+				c.Abort() // The client went away during the injected latency
+			}
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireFaults rejects fault endpoint requests when fault injection is off
+func (s *Server) requireFaults(c *gin.Context) bool {
+	if s.faults == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fault injection is disabled"})
+		return false
+	}
+	return true
+}
+
+// listFaults lists the active faults
+// @Summary List injected faults
+// @Description Only available when FAULT_INJECTION_ENABLED is set outside release mode.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/faults [get]
+func (s *Server) listFaults(c *gin.Context) {
+	if !s.requireFaults(c) {
+		return
+	}
+
+	active := s.faults.List(time.Now())
+	c.JSON(http.StatusOK, gin.H{
+		"faults": active,
+		"count":  len(active),
+	})
+}
+
+// setFault injects latency and errors at a target until the fault expires or
+// is cleared. Targets are api, evaluator, redis and database.
+// @Summary Inject fault
+// @Description Only available when FAULT_INJECTION_ENABLED is set outside release mode. Faults expire after ttl_seconds (default 300, max 3600).
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param target path string true "Target (api, evaluator, redis or database)"
+// @Param fault body faults.Fault true "Latency, error rate and TTL"
+// @Success 200 {object} faults.Fault
+// @Router /api/v1/admin/faults/{target} [put]
+func (s *Server) setFault(c *gin.Context) {
+	if !s.requireFaults(c) {
+		return
+	}
+
+	var req faults.Fault
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Target = c.Param("target")
+
+	fault, err := s.faults.Set(req, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fault)
+}
+
+// clearFault clears the fault at a target
+// @Summary Clear fault
+// @Tags Admin
+// @Param target path string true "Target"
+// @Success 204
+// @Router /api/v1/admin/faults/{target} [delete]
+func (s *Server) clearFault(c *gin.Context) {
+	if !s.requireFaults(c) {
+		return
+	}
+
+	if !s.faults.Clear(c.Param("target")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No active fault at target"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// clearFaults clears every fault
+// @Summary Clear all faults
+// @Tags Admin
+// @Success 204
+// @Router /api/v1/admin/faults [delete]
+func (s *Server) clearFaults(c *gin.Context) {
+	if !s.requireFaults(c) {
+		return
+	}
+
+	s.faults.ClearAll()
+	c.Status(http.StatusNoContent)
+}
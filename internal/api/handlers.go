@@ -1,14 +1,22 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ai-agent-eval/internal/models"
 	"github.com/ai-agent-eval/internal/queue"
+	"github.com/ai-agent-eval/internal/repository"
+	services "github.com/ai-agent-eval/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 )
 
@@ -16,10 +24,17 @@ import (
 // @Summary Get system statistics
 // @Tags Analytics
 // @Produce json
+// @Param as_of query string false "Only use data that existed at this time (RFC3339)"
 // @Success 200 {object} models.SystemStats
 // @Router /api/v1/stats [get]
 func (s *Server) getStats(c *gin.Context) {
-	stats, err := s.repo.GetSystemStats()
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := s.projectRepo(c).GetSystemStats(asOf)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -29,43 +44,51 @@ func (s *Server) getStats(c *gin.Context) {
 
 // createConversation ingests a new conversation
 // @Summary Ingest a conversation
+// @Description Re-submitting an existing conversation_id appends only the new turns
 // @Tags Ingestion
 // @Accept json
 // @Produce json
 // @Param conversation body models.ConversationCreate true "Conversation data"
+// @Param format query string false "Set to openai to send messages in the OpenAI chat completions format (models.OpenAIConversation)"
 // @Param auto_evaluate query bool false "Auto trigger evaluation" default(true)
 // @Success 201 {object} models.Conversation
+// @Success 200 {object} models.Conversation
 // @Router /api/v1/conversations [post]
 func (s *Server) createConversation(c *gin.Context) {
 	var conv models.ConversationCreate
-	if err := c.ShouldBindJSON(&conv); err != nil {
+	if err := bindConversation(c, &conv); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateTurns(conv.Turns); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.attachments.Validate(conv.Turns); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	created, err := s.repo.CreateConversation(&conv)
+	autoEvaluate := c.DefaultQuery("auto_evaluate", "true") == "true"
+	result, created, err := s.ingestConversation(s.projectRepo(c), &conv, autoEvaluate, queue.TriggerAutoIngest)
+	if err == errConversationInOtherProject {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err == errConversationQuotaReached {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Auto evaluate if requested
-	autoEvaluate := c.DefaultQuery("auto_evaluate", "true") == "true"
-	if autoEvaluate {
-		task := &queue.Task{
-			ID:             uuid.New().String(),
-			Type:           "evaluate",
-			ConversationID: conv.ConversationID,
-			EvaluatorTypes: []string{"llm_judge", "tool_call", "coherence", "heuristic"},
-			CreatedAt:      time.Now(),
-		}
-		if err := s.queue.Enqueue("evaluations", task); err != nil {
-			// Log but don't fail
-			_ = err
-		}
+	if !created {
+		c.JSON(http.StatusOK, result)
+		return
 	}
-
-	c.JSON(http.StatusCreated, created)
+	c.JSON(http.StatusCreated, result)
 }
 
 // batchCreateConversations ingests multiple conversations
@@ -74,36 +97,32 @@ func (s *Server) createConversation(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param conversations body []models.ConversationCreate true "Conversations data"
+// @Param format query string false "Set to openai to send messages in the OpenAI chat completions format (models.OpenAIConversation)"
 // @Param auto_evaluate query bool false "Auto trigger evaluation" default(true)
 // @Success 201 {object} models.BatchIngestResponse
 // @Router /api/v1/conversations/batch [post]
 func (s *Server) batchCreateConversations(c *gin.Context) {
 	var convs []models.ConversationCreate
-	if err := c.ShouldBindJSON(&convs); err != nil {
+	if err := bindConversations(c, &convs); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	conversationIDs := make([]string, 0, len(convs))
 	autoEvaluate := c.DefaultQuery("auto_evaluate", "true") == "true"
+	repo := s.projectRepo(c)
 
 	for _, conv := range convs {
-		_, err := s.repo.CreateConversation(&conv)
-		if err != nil {
+		if err := services.ValidateTurns(conv.Turns); err != nil {
+			continue
+		}
+		if err := s.attachments.Validate(conv.Turns); err != nil {
+			continue
+		}
+		if _, _, err := s.ingestConversation(repo, &conv, autoEvaluate, queue.TriggerBatch); err != nil {
 			continue // Skip failed ones
 		}
 		conversationIDs = append(conversationIDs, conv.ConversationID)
-
-		if autoEvaluate {
-			task := &queue.Task{
-				ID:             uuid.New().String(),
-				Type:           "evaluate",
-				ConversationID: conv.ConversationID,
-				EvaluatorTypes: []string{"llm_judge", "tool_call", "coherence", "heuristic"},
-				CreatedAt:      time.Now(),
-			}
-			_ = s.queue.Enqueue("evaluations", task)
-		}
 	}
 
 	c.JSON(http.StatusCreated, models.BatchIngestResponse{
@@ -112,11 +131,234 @@ func (s *Server) batchCreateConversations(c *gin.Context) {
 	})
 }
 
+// bindConversation reads a conversation in the format named by the format
+// query parameter: the ingestion format by default, or openai
+func bindConversation(c *gin.Context, conv *models.ConversationCreate) error {
+	switch c.Query("format") {
+	case "":
+		return c.ShouldBindJSON(conv)
+	case models.IngestFormatOpenAI:
+		var openAI models.OpenAIConversation
+		if err := c.ShouldBindJSON(&openAI); err != nil {
+			return err
+		}
+		converted, err := services.ConvertOpenAIConversation(&openAI)
+		if err != nil {
+			return err
+		}
+		*conv = *converted
+		return binding.Validator.ValidateStruct(conv)
+	}
+	return errors.New("format must be openai or omitted")
+}
+
+// bindConversations reads a batch of conversations like bindConversation.
+// OpenAI conversations that fail to convert are left out, as the batch
+// skips invalid conversations.
+func bindConversations(c *gin.Context, convs *[]models.ConversationCreate) error {
+	switch c.Query("format") {
+	case "":
+		return c.ShouldBindJSON(convs)
+	case models.IngestFormatOpenAI:
+		var openAI []models.OpenAIConversation
+		if err := c.ShouldBindJSON(&openAI); err != nil {
+			return err
+		}
+		for i := range openAI {
+			converted, err := services.ConvertOpenAIConversation(&openAI[i])
+			if err != nil || binding.Validator.ValidateStruct(converted) != nil {
+				continue
+			}
+			*convs = append(*convs, *converted)
+		}
+		return nil
+	}
+	return errors.New("format must be openai or omitted")
+}
+
+// errConversationQuotaReached rejects new conversations once the project has
+// ingested its daily quota
+var errConversationQuotaReached = errors.New("project conversation quota reached for today")
+
+// errConversationInOtherProject rejects ingesting a conversation whose ID
+// another project already uses
+var errConversationInOtherProject = errors.New("conversation_id is used by another project")
+
+// ingestConversation stores a conversation in repo's project, or appends its
+// new turns if the conversation already exists, and optionally queues an
+// evaluation of what changed. Inline attachment content is moved to the
+// attachment store first. It reports whether a new conversation was created.
+func (s *Server) ingestConversation(repo *repository.Repository, conv *models.ConversationCreate, autoEvaluate bool, triggerSource string) (*models.Conversation, bool, error) {
+	ingestedAt := time.Now()
+	if err := s.attachments.Store(context.Background(), conv.Turns); err != nil {
+		return nil, false, err
+	}
+
+	updated, newTurns, err := repo.AppendConversationTurns(conv)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if updated != nil {
+		s.ingestAppendedTurns(updated, newTurns, autoEvaluate, ingestedAt)
+		return updated, false, nil
+	}
+
+	reached, err := repo.ConversationQuotaReached(ingestedAt)
+	if err != nil {
+		return nil, false, err
+	}
+	if reached {
+		return nil, false, errConversationQuotaReached
+	}
+
+	if conv.Intent == "" {
+		conv.Intent = services.ClassifyIntent(s.pipeline.Get().Intents, conv.Turns)
+	}
+	created, err := repo.CreateConversation(conv)
+	if err != nil {
+		return nil, false, err
+	}
+	if created == nil {
+		// Either another project uses the ID or a concurrent ingestion of
+		// the same conversation created it first, whose turns this one
+		// appends to
+		updated, newTurns, err := repo.AppendConversationTurns(conv)
+		if err != nil {
+			return nil, false, err
+		}
+		if updated == nil {
+			return nil, false, errConversationInOtherProject
+		}
+		s.ingestAppendedTurns(updated, newTurns, autoEvaluate, ingestedAt)
+		return updated, false, nil
+	}
+
+	if autoEvaluate {
+		s.enqueueEvaluation(created.ConversationID, created.ProjectID, triggerSource, 0, ingestedAt)
+	}
+	s.publishIngestion(created, len(conv.Turns))
+
+	return created, true, nil
+}
+
+// ingestAppendedTurns queues an evaluation of turns appended to a
+// conversation, if asked to, and publishes their ingestion
+func (s *Server) ingestAppendedTurns(conv *models.Conversation, newTurns []models.Turn, autoEvaluate bool, ingestedAt time.Time) {
+	if len(newTurns) == 0 {
+		return
+	}
+	if autoEvaluate {
+		// Only the appended turns need evaluating
+		fromTurnID := newTurns[0].TurnID
+		for _, turn := range newTurns {
+			if turn.TurnID < fromTurnID {
+				fromTurnID = turn.TurnID
+			}
+		}
+		s.enqueueEvaluation(conv.ConversationID, conv.ProjectID, queue.TriggerReevaluation, fromTurnID, ingestedAt)
+	}
+	s.publishIngestion(conv, len(newTurns))
+}
+
+// publishIngestion publishes the activity event of turns being ingested
+func (s *Server) publishIngestion(conv *models.Conversation, turns int) {
+	s.publishActivity(models.ActivityEvent{
+		Type:           models.ActivityConversationIngested,
+		ProjectID:      conv.ProjectID,
+		ConversationID: conv.ConversationID,
+		AgentVersion:   conv.AgentVersion,
+		Turns:          turns,
+		At:             time.Now().UTC(),
+	})
+}
+
+// enqueueEvaluation queues an evaluation with the default evaluators.
+// Failures are logged but don't fail ingestion.
+func (s *Server) enqueueEvaluation(conversationID, projectID, triggerSource string, fromTurnID int, ingestedAt time.Time) {
+	if _, err := s.queueEvaluation(conversationID, projectID, triggerSource, fromTurnID, ingestedAt); err != nil {
+		log.Printf("Failed to queue evaluation for %s: %v", conversationID, err)
+	}
+}
+
+// queueEvaluation queues an evaluation with the default evaluators on the
+// conversation's project's sub-queue and returns the task ID. ingestedAt is
+// when the triggering conversation was ingested, or zero.
+func (s *Server) queueEvaluation(conversationID, projectID, triggerSource string, fromTurnID int, ingestedAt time.Time) (string, error) {
+	evaluatorTypes, warnings := services.CheckEvaluatorTypes(
+		s.pipeline.Get().DefaultEvaluatorTypes, services.HasLLMCredentials(s.cfg),
+	)
+	for _, warning := range warnings {
+		log.Printf("Skipping evaluator for %s: %s", conversationID, warning.Message)
+	}
+	if len(evaluatorTypes) == 0 {
+		return "", errors.New("no runnable evaluators")
+	}
+
+	task := &queue.Task{
+		ID:                uuid.New().String(),
+		Type:              queue.TaskEvaluate,
+		ConversationID:    conversationID,
+		ProjectID:         projectID,
+		EvaluatorTypes:    evaluatorTypes,
+		EvaluatorVersions: s.evaluatorVersions(evaluatorTypes),
+		TriggerSource:     triggerSource,
+		FromTurnID:        fromTurnID,
+		CreatedAt:         time.Now(),
+	}
+	s.setTaskTimeouts(task)
+	s.setTaskBudget(task, nil)
+	if err := s.queue.Enqueue(queue.QueueEvaluations, task); err != nil {
+		return "", err
+	}
+
+	s.recordPipelineTask(task, ingestedAt)
+	return task.ID, nil
+}
+
+// setTaskTimeouts sets the configured deadline of an evaluation task and the
+// timeouts of its evaluator types
+func (s *Server) setTaskTimeouts(task *queue.Task) {
+	task.TimeoutMS = s.cfg.EvaluationTimeoutSeconds * 1000
+	for _, evaluatorType := range task.EvaluatorTypes {
+		if seconds := s.cfg.EvaluatorTimeoutSeconds[evaluatorType]; seconds > 0 {
+			if task.EvaluatorTimeoutsMS == nil {
+				task.EvaluatorTimeoutsMS = make(map[string]int)
+			}
+			task.EvaluatorTimeoutsMS[evaluatorType] = seconds * 1000
+		}
+	}
+}
+
+// setTaskBudget attaches the configured evaluation budget to a task, with
+// its maximum cost overridden if maxCost is given. Tasks are left without a
+// budget when it wouldn't change how their evaluators run.
+func (s *Server) setTaskBudget(task *queue.Task, maxCost *float64) {
+	budget := s.pipeline.Get().Budget
+	if maxCost != nil {
+		budget.MaxCost = *maxCost
+	}
+	if services.BudgetActive(&budget) {
+		task.Budget = &budget
+	}
+}
+
+// recordPipelineTask records when a queued task was ingested and queued.
+// Timings are best effort, so failures are only logged.
+func (s *Server) recordPipelineTask(task *queue.Task, ingestedAt time.Time) {
+	err := s.repo.RecordPipelineTask(task.ID, task.ConversationID, task.TriggerSource, ingestedAt, task.CreatedAt)
+	if err != nil {
+		log.Printf("Failed to record pipeline timings for task %s: %v", task.ID, err)
+	}
+}
+
 // listConversations lists conversations
 // @Summary List conversations
+// @Description Turns are masked by the caller's masking profile, reported in the X-Masking-Profile header.
 // @Tags Query
 // @Produce json
 // @Param agent_version query string false "Filter by agent version"
+// @Param include query string false "Set to evaluation_summary to attach the latest evaluation summary"
 // @Param limit query int false "Limit" default(100)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {object} map[string]interface{}
@@ -126,11 +368,29 @@ func (s *Server) listConversations(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	convs, err := s.repo.ListConversations(agentVersion, limit, offset)
+	convs, err := s.projectRepo(c).ListConversations(agentVersion, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if err := s.maskConversations(c, convs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("include") == "evaluation_summary" {
+		withSummary, err := s.attachEvaluationSummaries(convs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"conversations": withSummary,
+			"count":         len(withSummary),
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"conversations": convs,
@@ -138,8 +398,38 @@ func (s *Server) listConversations(c *gin.Context) {
 	})
 }
 
+// attachEvaluationSummaries pairs conversations with their latest evaluation summary
+func (s *Server) attachEvaluationSummaries(convs []models.Conversation) ([]models.ConversationWithSummary, error) {
+	ids := make([]string, len(convs))
+	for i, conv := range convs {
+		ids[i] = conv.ConversationID
+	}
+
+	summaries, err := s.repo.GetConversationSummaries(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := s.pipeline.Get().Routing
+	result := make([]models.ConversationWithSummary, len(convs))
+	for i, conv := range convs {
+		result[i].Conversation = conv
+		if summary, ok := summaries[conv.ConversationID]; ok {
+			summary.NeedsHumanReview, summary.Priority, _ = services.DecideRouting(
+				summary.LatestOverallScore,
+				services.SummarySeverityCounts(summary.SeverityCounts, summary.CriticalIssueCount),
+				summary.HealthScore, summary.LatestVerdict, policy,
+			)
+			result[i].EvaluationSummary = summary
+		}
+	}
+
+	return result, nil
+}
+
 // getConversation retrieves a conversation by ID
 // @Summary Get conversation
+// @Description Turns are masked by the caller's masking profile, reported in the X-Masking-Profile header.
 // @Tags Query
 // @Produce json
 // @Param conversation_id path string true "Conversation ID"
@@ -148,7 +438,7 @@ func (s *Server) listConversations(c *gin.Context) {
 func (s *Server) getConversation(c *gin.Context) {
 	conversationID := c.Param("conversation_id")
 
-	conv, err := s.repo.GetConversation(conversationID)
+	conv, err := s.projectRepo(c).GetConversation(conversationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -157,8 +447,53 @@ func (s *Server) getConversation(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
 		return
 	}
+	convs := []models.Conversation{*conv}
+	if err := s.maskConversations(c, convs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	c.JSON(http.StatusOK, conv)
+	c.JSON(http.StatusOK, convs[0])
+}
+
+// getConversationEvaluations returns the evaluation history of a conversation
+// @Summary Get conversation evaluation history
+// @Tags Query
+// @Produce json
+// @Param conversation_id path string true "Conversation ID"
+// @Param Accept-Language header string false "Language to translate issues and suggestions into"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/conversations/{conversation_id}/evaluations [get]
+func (s *Server) getConversationEvaluations(c *gin.Context) {
+	conversationID := c.Param("conversation_id")
+
+	conv, err := s.projectRepo(c).GetConversation(conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if conv == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	evals, err := s.projectRepo(c).ListConversationEvaluations(conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	results := make([]*models.EvaluationResponse, 0, len(evals))
+	for i := range evals {
+		results = append(results, models.NewEvaluationResponse(&evals[i]))
+	}
+	s.localizeEvaluations(c, results)
+
+	c.JSON(http.StatusOK, gin.H{
+		"conversation_id": conversationID,
+		"evaluations":     results,
+		"count":           len(results),
+	})
 }
 
 // addFeedback adds feedback to a conversation
@@ -179,15 +514,37 @@ func (s *Server) addFeedback(c *gin.Context) {
 		return
 	}
 
-	// This would normally add to the feedbacks table
+	repo := s.projectRepo(c)
+	conv, err := repo.GetConversation(req.ConversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if conv == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	if err := repo.AddFeedback(req.ConversationID, &req.Feedback); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	health, err := s.repo.RefreshConversationHealth(req.ConversationID, s.pipeline.Get().Health)
+	if err != nil {
+		log.Printf("Failed to refresh health for %s: %v", req.ConversationID, err)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"status":          "success",
 		"conversation_id": req.ConversationID,
+		"health":          health,
 	})
 }
 
 // triggerEvaluation triggers an evaluation
 // @Summary Trigger evaluation
+// @Description With scheduled_at in the future the evaluation is queued at that time instead, and the response status is scheduled.
 // @Tags Evaluation
 // @Accept json
 // @Produce json
@@ -200,9 +557,13 @@ func (s *Server) triggerEvaluation(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.MaxCost != nil && *req.MaxCost < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_cost must not be negative"})
+		return
+	}
 
 	// Check if conversation exists
-	conv, err := s.repo.GetConversation(req.ConversationID)
+	conv, err := s.projectRepo(c).GetConversation(req.ConversationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -215,29 +576,79 @@ func (s *Server) triggerEvaluation(c *gin.Context) {
 	// Default evaluator types
 	evaluatorTypes := req.EvaluatorTypes
 	if len(evaluatorTypes) == 0 {
-		evaluatorTypes = []string{"llm_judge", "tool_call", "coherence", "heuristic"}
+		evaluatorTypes = s.pipeline.Get().DefaultEvaluatorTypes
+	}
+
+	// Drop evaluators that would fail in the worker
+	evaluatorTypes, warnings := services.CheckEvaluatorTypes(evaluatorTypes, services.HasLLMCredentials(s.cfg))
+	if len(evaluatorTypes) == 0 || (req.Strict && len(warnings) > 0) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":    "Requested evaluators cannot run",
+			"warnings": warnings,
+		})
+		return
 	}
 
 	// Queue the evaluation
 	taskID := uuid.New().String()
 	task := &queue.Task{
-		ID:             taskID,
-		Type:           "evaluate",
-		ConversationID: req.ConversationID,
-		EvaluatorTypes: evaluatorTypes,
-		CreatedAt:      time.Now(),
-	}
-
-	if err := s.queue.Enqueue("evaluations", task); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue evaluation"})
-		return
+		ID:                taskID,
+		Type:              queue.TaskEvaluate,
+		ConversationID:    req.ConversationID,
+		ProjectID:         conv.ProjectID,
+		EvaluatorTypes:    evaluatorTypes,
+		EvaluatorVersions: s.evaluatorVersions(evaluatorTypes),
+		TriggerSource:     queue.TriggerManual,
+		CreatedAt:         time.Now(),
 	}
+	s.setTaskTimeouts(task)
+	s.setTaskBudget(task, req.MaxCost)
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"task_id":         taskID,
 		"conversation_id": req.ConversationID,
 		"status":          "queued",
-	})
+		"evaluator_types": evaluatorTypes,
+		"warnings":        warnings,
+	}
+	if req.ScheduledAt != nil && req.ScheduledAt.After(time.Now()) {
+		if err := s.queue.EnqueueAt(queue.QueueEvaluations, task, *req.ScheduledAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule evaluation"})
+			return
+		}
+		response["status"] = "scheduled"
+		response["scheduled_at"] = req.ScheduledAt.UTC()
+	} else {
+		if err := s.queue.Enqueue(queue.QueueEvaluations, task); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue evaluation"})
+			return
+		}
+		s.recordPipelineTask(task, time.Time{})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// getTask gets the status of a queued evaluation task
+// @Summary Get task status
+// @Description State is queued (with scheduled_at while a scheduled task waits for its time, or retry_at and error while a failed task waits to be retried), running, completed (with evaluation_id) or failed (with error, once dead-lettered). Statuses expire a week after their last update.
+// @Tags Evaluation
+// @Produce json
+// @Param task_id path string true "Task ID"
+// @Success 200 {object} queue.TaskStatus
+// @Router /api/v1/tasks/{task_id} [get]
+func (s *Server) getTask(c *gin.Context) {
+	status, err := s.queue.GetTaskStatus(c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if status == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
 }
 
 // listEvaluations lists evaluations
@@ -245,6 +656,8 @@ func (s *Server) triggerEvaluation(c *gin.Context) {
 // @Tags Evaluation
 // @Produce json
 // @Param conversation_id query string false "Filter by conversation ID"
+// @Param trigger_source query string false "Filter by trigger source (auto_ingest, manual, batch, reevaluation)"
+// @Param verdict query string false "Filter by verdict (pass, fail, needs_review)"
 // @Param min_score query number false "Minimum overall score"
 // @Param max_score query number false "Maximum overall score"
 // @Param limit query int false "Limit" default(100)
@@ -253,22 +666,17 @@ func (s *Server) triggerEvaluation(c *gin.Context) {
 // @Router /api/v1/evaluations [get]
 func (s *Server) listEvaluations(c *gin.Context) {
 	conversationID := c.Query("conversation_id")
+	triggerSource := c.Query("trigger_source")
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	var minScore, maxScore *float64
-	if min := c.Query("min_score"); min != "" {
-		if v, err := strconv.ParseFloat(min, 64); err == nil {
-			minScore = &v
-		}
-	}
-	if max := c.Query("max_score"); max != "" {
-		if v, err := strconv.ParseFloat(max, 64); err == nil {
-			maxScore = &v
-		}
+	minScore, maxScore := scoreRangeQuery(c)
+	verdict, ok := verdictQuery(c)
+	if !ok {
+		return
 	}
 
-	evals, err := s.repo.ListEvaluations(conversationID, minScore, maxScore, limit, offset)
+	evals, err := s.projectRepo(c).ListEvaluations(conversationID, triggerSource, verdict, minScore, maxScore, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -281,6 +689,10 @@ func (s *Server) listEvaluations(c *gin.Context) {
 			"evaluation_id":   e.EvaluationID,
 			"conversation_id": e.ConversationID,
 			"overall_score":   e.OverallScore,
+			"partial":         e.Partial,
+			"verdict":         e.Verdict,
+			"task_id":         e.TaskID,
+			"trigger_source":  e.TriggerSource,
 			"created_at":      e.CreatedAt,
 		})
 	}
@@ -291,17 +703,141 @@ func (s *Server) listEvaluations(c *gin.Context) {
 	})
 }
 
+// maxEvaluationSearchLength caps the length of evaluation search queries
+const maxEvaluationSearchLength = 500
+
+// searchEvaluations finds evaluations by the text of their issues and
+// suggestions
+// @Summary Search evaluations
+// @Description Full-text search over issue descriptions and improvement suggestions and their rationales, best match first. q takes words, "quoted phrases", or and -excluded words, e.g. "date parsing" -timezone. Headlines mark matches <<like this>>.
+// @Tags Evaluation
+// @Produce json
+// @Param q query string true "Search query"
+// @Param verdict query string false "Filter by verdict (pass, fail, needs_review)"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/evaluations/search [get]
+func (s *Server) searchEvaluations(c *gin.Context) {
+	search := strings.TrimSpace(c.Query("q"))
+	if search == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	if len(search) > maxEvaluationSearchLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("q must be at most %d characters", maxEvaluationSearchLength)})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+	verdict, ok := verdictQuery(c)
+	if !ok {
+		return
+	}
+
+	results, err := s.projectRepo(c).SearchEvaluations(search, verdict, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":       search,
+		"evaluations": results,
+		"count":       len(results),
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+// listEvaluationsV2 lists evaluations in the full evaluation response format
+// @Summary List evaluations
+// @Tags Evaluation
+// @Produce json
+// @Param conversation_id query string false "Filter by conversation"
+// @Param trigger_source query string false "Filter by trigger source"
+// @Param verdict query string false "Filter by verdict (pass, fail, needs_review)"
+// @Param min_score query number false "Minimum overall score"
+// @Param max_score query number false "Maximum overall score"
+// @Param limit query int false "Limit" default(100)
+// @Param offset query int false "Offset" default(0)
+// @Param Accept-Language header string false "Language to translate issues and suggestions into"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v2/evaluations [get]
+func (s *Server) listEvaluationsV2(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	minScore, maxScore := scoreRangeQuery(c)
+	verdict, ok := verdictQuery(c)
+	if !ok {
+		return
+	}
+
+	evals, err := s.projectRepo(c).ListEvaluations(c.Query("conversation_id"), c.Query("trigger_source"), verdict, minScore, maxScore, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	results := make([]*models.EvaluationResponse, 0, len(evals))
+	for i := range evals {
+		results = append(results, models.NewEvaluationResponse(&evals[i]))
+	}
+	s.localizeEvaluations(c, results)
+
+	c.JSON(http.StatusOK, gin.H{
+		"evaluations": results,
+		"count":       len(results),
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+// scoreRangeQuery parses the optional min_score and max_score query parameters
+func scoreRangeQuery(c *gin.Context) (minScore, maxScore *float64) {
+	if min := c.Query("min_score"); min != "" {
+		if v, err := strconv.ParseFloat(min, 64); err == nil {
+			minScore = &v
+		}
+	}
+	if max := c.Query("max_score"); max != "" {
+		if v, err := strconv.ParseFloat(max, 64); err == nil {
+			maxScore = &v
+		}
+	}
+	return minScore, maxScore
+}
+
+// verdictQuery parses the optional verdict query parameter, responding with
+// an error if it isn't a verdict
+func verdictQuery(c *gin.Context) (string, bool) {
+	verdict := c.Query("verdict")
+	if verdict != "" && !services.IsVerdict(verdict) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "verdict must be pass, fail or needs_review"})
+		return "", false
+	}
+	return verdict, true
+}
+
 // getEvaluation retrieves an evaluation by ID
 // @Summary Get evaluation
 // @Tags Evaluation
 // @Produce json
 // @Param evaluation_id path string true "Evaluation ID"
+// @Param Accept-Language header string false "Language to translate issues and suggestions into"
 // @Success 200 {object} models.EvaluationResponse
 // @Router /api/v1/evaluations/{evaluation_id} [get]
 func (s *Server) getEvaluation(c *gin.Context) {
 	evaluationID := c.Param("evaluation_id")
 
-	eval, err := s.repo.GetEvaluation(evaluationID)
+	eval, err := s.projectRepo(c).GetEvaluation(evaluationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -311,32 +847,143 @@ func (s *Server) getEvaluation(c *gin.Context) {
 		return
 	}
 
-	// Parse JSON fields
-	var toolEval models.ToolEvaluation
-	var issues []models.IssueDetected
-	var suggestions []models.ImprovementSuggestion
+	resp := models.NewEvaluationResponse(eval)
+	s.localizeEvaluations(c, []*models.EvaluationResponse{resp})
+	c.JSON(http.StatusOK, resp)
+}
 
-	json.Unmarshal(eval.ToolEvaluation, &toolEval)
-	json.Unmarshal(eval.IssuesDetected, &issues)
-	json.Unmarshal(eval.ImprovementSuggestions, &suggestions)
-
-	response := models.EvaluationResponse{
-		EvaluationID:   eval.EvaluationID,
-		ConversationID: eval.ConversationID,
-		Scores: models.EvaluationScores{
-			Overall:         eval.OverallScore,
-			ResponseQuality: eval.ResponseQualityScore,
-			ToolAccuracy:    eval.ToolAccuracyScore,
-			Coherence:       eval.CoherenceScore,
-		},
-		ToolEvaluation:         &toolEval,
-		IssuesDetected:         issues,
-		ImprovementSuggestions: suggestions,
-		EvaluationDurationMS:   eval.EvaluationDurationMS,
-		CreatedAt:              eval.CreatedAt,
+// getEvaluationManifest returns what influenced an evaluation's scores, and
+// what changed since the conversation's previous evaluation
+// @Summary Get evaluation manifest
+// @Description Evaluator types and versions, rubric, score weights, truncation policy, model names and the hash of the scoring configuration. Changes lists the manifest fields that differ from the conversation's previous evaluation. Evaluations stored before manifests existed have an empty manifest.
+// @Tags Evaluation
+// @Produce json
+// @Param evaluation_id path string true "Evaluation ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/evaluations/{evaluation_id}/manifest [get]
+func (s *Server) getEvaluationManifest(c *gin.Context) {
+	eval, err := s.projectRepo(c).GetEvaluation(c.Param("evaluation_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if eval == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Evaluation not found"})
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	history, err := s.projectRepo(c).ListConversationEvaluations(eval.ConversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// History is oldest first
+	var previous *models.Evaluation
+	for i := range history {
+		if history[i].EvaluationID == eval.EvaluationID {
+			break
+		}
+		previous = &history[i]
+	}
+
+	resp := gin.H{
+		"evaluation_id": eval.EvaluationID,
+		"manifest":      eval.Manifest,
+		"changes":       []models.ManifestChange{},
+	}
+	if previous != nil {
+		changes, err := services.ManifestChanges(previous.Manifest, eval.Manifest)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		resp["previous_evaluation_id"] = previous.EvaluationID
+		resp["changes"] = changes
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// verifyEvaluation checks that a stored evaluation still matches its signature
+// @Summary Verify evaluation integrity
+// @Tags Evaluation
+// @Produce json
+// @Param evaluation_id path string true "Evaluation ID"
+// @Success 200 {object} models.SignatureVerification
+// @Router /api/v1/evaluations/{evaluation_id}/verify [get]
+func (s *Server) verifyEvaluation(c *gin.Context) {
+	if s.signer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Evaluation signing is not configured"})
+		return
+	}
+
+	eval, err := s.projectRepo(c).GetEvaluation(c.Param("evaluation_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if eval == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Evaluation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, s.signer.Verify(eval))
+}
+
+// completeEvaluation queues a follow-up task that re-runs the evaluator types
+// a partial evaluation is missing
+// @Summary Complete partial evaluation
+// @Tags Evaluation
+// @Produce json
+// @Param evaluation_id path string true "Evaluation ID"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/evaluations/{evaluation_id}/complete [post]
+func (s *Server) completeEvaluation(c *gin.Context) {
+	eval, err := s.projectRepo(c).GetEvaluation(c.Param("evaluation_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if eval == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Evaluation not found"})
+		return
+	}
+	if !eval.Partial {
+		c.JSON(http.StatusConflict, gin.H{"error": "Evaluation is not partial"})
+		return
+	}
+
+	evaluatorTypes, err := services.FailedEvaluatorTypes(eval)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	task := &queue.Task{
+		ID:                uuid.New().String(),
+		Type:              queue.TaskCompleteEvaluation,
+		ConversationID:    eval.ConversationID,
+		ProjectID:         eval.ProjectID,
+		EvaluatorTypes:    evaluatorTypes,
+		EvaluatorVersions: s.evaluatorVersions(evaluatorTypes),
+		TriggerSource:     eval.TriggerSource,
+		Payload:           map[string]interface{}{"evaluation_id": eval.EvaluationID},
+		CreatedAt:         time.Now(),
+	}
+	s.setTaskTimeouts(task)
+	if err := s.queue.Enqueue(queue.QueueEvaluations, task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue evaluation"})
+		return
+	}
+	s.recordPipelineTask(task, time.Time{})
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"task_id":         task.ID,
+		"evaluation_id":   eval.EvaluationID,
+		"status":          "queued",
+		"evaluator_types": evaluatorTypes,
+	})
 }
 
 // createAnnotation creates a new annotation
@@ -346,6 +993,7 @@ func (s *Server) getEvaluation(c *gin.Context) {
 // @Produce json
 // @Param annotation body models.AnnotationCreate true "Annotation data"
 // @Success 201 {object} models.Annotation
+// @Failure 404 {object} map[string]interface{}
 // @Router /api/v1/annotations [post]
 func (s *Server) createAnnotation(c *gin.Context) {
 	var ann models.AnnotationCreate
@@ -353,12 +1001,45 @@ func (s *Server) createAnnotation(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := services.NormalizeAnnotationLabels(&ann); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	created, err := s.repo.CreateAnnotation(&ann)
+	// Recorded so the assisted labeling arms can be compared
+	assisted, suggestion, err := s.annotationAssist(c, ann.ConversationID, ann.AnnotatorID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	ann.Assisted = assisted
+	if suggestion != nil {
+		ann.SuggestedLabels = suggestion.Labels
+	}
+
+	created, err := s.projectRepo(c).CreateAnnotation(&ann)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if created == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	agentVersion, err := s.projectRepo(c).GetConversationAgentVersion(created.ConversationID)
+	if err != nil {
+		log.Printf("Failed to look up agent version of %s: %v", created.ConversationID, err)
+	}
+	s.publishActivity(models.ActivityEvent{
+		Type:           models.ActivityAnnotationCreated,
+		ProjectID:      created.ProjectID,
+		ConversationID: created.ConversationID,
+		AgentVersion:   agentVersion,
+		AnnotationType: created.AnnotationType,
+		AnnotatorID:    created.AnnotatorID,
+		At:             time.Now().UTC(),
+	})
 
 	c.JSON(http.StatusCreated, created)
 }
@@ -380,7 +1061,7 @@ func (s *Server) getAnnotatorAgreement(c *gin.Context) {
 		return
 	}
 
-	annotations, err := s.repo.GetAnnotationsForConversation(conversationID, annotationType)
+	annotations, err := s.projectRepo(c).GetAnnotationsForConversation(conversationID, annotationType)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -388,38 +1069,40 @@ func (s *Server) getAnnotatorAgreement(c *gin.Context) {
 
 	// Calculate agreement
 	annotators := make([]string, 0)
-	labelCounts := make(map[string]int)
-
+	labelSets := make([][]string, 0, len(annotations))
 	for _, ann := range annotations {
 		annotators = append(annotators, ann.AnnotatorID)
-		labelCounts[ann.Label]++
+		labelSets = append(labelSets, services.AnnotationLabels(ann))
 	}
+	agreement := services.ComputeLabelAgreement(labelSets)
 
-	// Find majority label and agreement
-	var majorityLabel string
-	maxCount := 0
-	for label, count := range labelCounts {
-		if count > maxCount {
-			maxCount = count
-			majorityLabel = label
-		}
-	}
+	routing := s.pipeline.Get().Routing
+	needsTiebreaker := agreement.Score < routing.AgreementThreshold
 
-	agreementScore := 1.0
-	if len(annotations) > 1 {
-		agreementScore = float64(maxCount) / float64(len(annotations))
+	assignees, err := s.projectRepo(c).ListAnnotationTaskAssignees(conversationID, annotationType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	completion := services.AnnotationCoverage(routing, annotationType, annotators, assignees)
+	if !completion.Complete {
+		// Not adjudicated until enough annotators have weighed in
+		agreement.MajorityLabel = ""
+		agreement.MajorityLabels = nil
+		needsTiebreaker = false
 	}
-
-	needsTiebreaker := agreementScore < s.cfg.AnnotatorAgreementThreshold
 
 	c.JSON(http.StatusOK, models.AnnotatorAgreement{
 		ConversationID:        conversationID,
 		AnnotationType:        annotationType,
 		Annotators:            annotators,
-		AgreementScore:        agreementScore,
-		MajorityLabel:         majorityLabel,
+		AgreementScore:        agreement.Score,
+		AgreementMethod:       agreement.Method,
+		MajorityLabel:         agreement.MajorityLabel,
+		MajorityLabels:        agreement.MajorityLabels,
 		NeedsTiebreaker:       needsTiebreaker,
 		IndividualAnnotations: annotations,
+		Completion:            completion,
 	})
 }
 
@@ -433,7 +1116,7 @@ func (s *Server) getAnnotatorAgreement(c *gin.Context) {
 func (s *Server) getRoutingDecision(c *gin.Context) {
 	conversationID := c.Param("conversation_id")
 
-	eval, err := s.repo.GetLatestEvaluationForConversation(conversationID)
+	eval, err := s.projectRepo(c).GetLatestEvaluationForConversation(conversationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -447,28 +1130,25 @@ func (s *Server) getRoutingDecision(c *gin.Context) {
 	var issues []models.IssueDetected
 	json.Unmarshal(eval.IssuesDetected, &issues)
 
-	// Determine routing
-	needsReview := false
-	routingReason := []string{}
-	priority := "low"
-
-	if eval.OverallScore < 0.4 {
-		needsReview = true
-		routingReason = append(routingReason, "Low quality score")
-		priority = "high"
+	pipeline := s.pipeline.Get()
+	health, err := s.repo.RefreshConversationHealth(conversationID, pipeline.Health)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
-
-	criticalCount := 0
-	for _, issue := range issues {
-		if issue.Severity == "critical" {
-			criticalCount++
-		}
+	var healthScore *float64
+	if health != nil {
+		healthScore = &health.HealthScore
 	}
 
-	if criticalCount > 0 {
-		needsReview = true
-		routingReason = append(routingReason, "Critical issues detected")
-		priority = "high"
+	// Determine routing
+	needsReview, priority, routingReason := services.DecideRouting(
+		eval.OverallScore, services.SeverityCounts(issues), healthScore, eval.Verdict, pipeline.Routing,
+	)
+
+	var reviewDueAt *time.Time
+	if needsReview {
+		reviewDueAt = services.ReviewDeadline(pipeline.Routing, priority, time.Now())
 	}
 
 	suggestedTypes := []string{"general_quality"}
@@ -481,13 +1161,46 @@ func (s *Server) getRoutingDecision(c *gin.Context) {
 		}
 	}
 
+	// Prefer annotators specialized in the suggested annotation types
+	suggestedAnnotators, err := s.repo.GetSpecializedAnnotators(suggestedTypes, 3)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Types needing several independent annotations can't be auto-labelled
+	// until they have them
+	autoLabel := !needsReview
+	checked := make(map[string]bool, len(suggestedTypes))
+	for _, annotationType := range suggestedTypes {
+		required := services.RequiredAnnotators(pipeline.Routing, annotationType)
+		if checked[annotationType] || required <= 1 {
+			continue
+		}
+		checked[annotationType] = true
+
+		completed, err := s.projectRepo(c).CountAnnotators(conversationID, annotationType)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if completed < required {
+			autoLabel = false
+			routingReason = append(routingReason, fmt.Sprintf(
+				"%s needs %d annotators, has %d", annotationType, required, completed,
+			))
+		}
+	}
+
 	c.JSON(http.StatusOK, models.RoutingDecision{
 		ConversationID:           conversationID,
 		NeedsHumanReview:         needsReview,
 		Priority:                 priority,
 		RoutingReason:            routingReason,
-		AutoLabel:                !needsReview,
+		AutoLabel:                autoLabel,
 		SuggestedAnnotationTypes: suggestedTypes,
+		SuggestedAnnotators:      suggestedAnnotators,
+		ReviewDueAt:              reviewDueAt,
 	})
 }
 
@@ -517,17 +1230,19 @@ func (s *Server) analyzeAndGenerateSuggestions(c *gin.Context) {
 // @Produce json
 // @Param min_confidence query number false "Minimum confidence" default(0.7)
 // @Param suggestion_type query string false "Filter by type"
+// @Param Accept-Language header string false "Language to translate issues and suggestions into"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/improvements/suggestions [get]
 func (s *Server) getSuggestions(c *gin.Context) {
 	minConfidence, _ := strconv.ParseFloat(c.DefaultQuery("min_confidence", "0.7"), 64)
 	suggestionType := c.Query("suggestion_type")
 
-	suggestions, err := s.repo.GetPendingSuggestions(minConfidence, suggestionType)
+	suggestions, err := s.projectRepo(c).GetPendingSuggestions(minConfidence, suggestionType)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	s.localizeSuggestions(c, suggestions)
 
 	c.JSON(http.StatusOK, gin.H{
 		"suggestions": suggestions,
@@ -552,7 +1267,7 @@ func (s *Server) markSuggestionImplemented(c *gin.Context) {
 
 	beforeMetrics, _ := json.Marshal(req.BeforeMetrics)
 
-	if err := s.repo.MarkSuggestionImplemented(suggestionID, beforeMetrics); err != nil {
+	if err := s.projectRepo(c).MarkSuggestionImplemented(suggestionID, beforeMetrics); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -569,12 +1284,14 @@ func (s *Server) markSuggestionImplemented(c *gin.Context) {
 // @Produce json
 // @Param resolved query bool false "Filter by resolved status"
 // @Param severity query string false "Filter by severity"
+// @Param owner query string false "Filter by owner"
 // @Param limit query int false "Limit" default(50)
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/improvements/patterns [get]
 func (s *Server) getFailurePatterns(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	severity := c.Query("severity")
+	owner := c.Query("owner")
 
 	var resolved *bool
 	if r := c.Query("resolved"); r != "" {
@@ -582,7 +1299,7 @@ func (s *Server) getFailurePatterns(c *gin.Context) {
 		resolved = &v
 	}
 
-	patterns, err := s.repo.GetFailurePatterns(resolved, severity, limit)
+	patterns, err := s.projectRepo(c).GetFailurePatterns(resolved, severity, owner, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -594,6 +1311,79 @@ func (s *Server) getFailurePatterns(c *gin.Context) {
 	})
 }
 
+// resolveFailurePattern marks a failure pattern resolved. A linked issue
+// tracker ticket is closed on the next sync.
+// @Summary Resolve failure pattern
+// @Tags Self-Improvement
+// @Accept json
+// @Produce json
+// @Param pattern_id path string true "Pattern ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/improvements/patterns/{pattern_id}/resolve [post]
+func (s *Server) resolveFailurePattern(c *gin.Context) {
+	patternID := c.Param("pattern_id")
+
+	var req struct {
+		Notes string `json:"notes"`
+	}
+	c.ShouldBindJSON(&req)
+
+	found, err := s.projectRepo(c).SetFailurePatternResolved(patternID, true, req.Notes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pattern not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "success",
+		"pattern_id": patternID,
+	})
+}
+
+// getSimilarPatterns finds failure patterns related to a given pattern
+// @Summary Get similar failure patterns
+// @Tags Self-Improvement
+// @Produce json
+// @Param pattern_id path string true "Pattern ID"
+// @Param min_similarity query number false "Minimum similarity" default(0.2)
+// @Param limit query int false "Limit" default(10)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/improvements/patterns/{pattern_id}/similar [get]
+func (s *Server) getSimilarPatterns(c *gin.Context) {
+	patternID := c.Param("pattern_id")
+	minSimilarity, _ := strconv.ParseFloat(c.DefaultQuery("min_similarity", "0.2"), 64)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	repo := s.projectRepo(c)
+	pattern, err := repo.GetFailurePattern(patternID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if pattern == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pattern not found"})
+		return
+	}
+
+	candidates, err := repo.GetFailurePatterns(nil, "", "", 1000)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	similar := services.FindSimilarPatterns(pattern, candidates, minSimilarity, limit)
+
+	c.JSON(http.StatusOK, gin.H{
+		"pattern_id": patternID,
+		"similar":    similar,
+		"count":      len(similar),
+	})
+}
+
 // calibrateEvaluators triggers evaluator calibration
 // @Summary Calibrate evaluators
 // @Tags Meta-Evaluation
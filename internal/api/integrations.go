@@ -0,0 +1,86 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// labelStudioSource identifies Label Studio annotations in external_annotations
+const labelStudioSource = "labelstudio"
+
+// webhookTokenHeader carries the shared secret of inbound webhooks. It is
+// kept apart from Authorization, which authenticates the caller as usual.
+const webhookTokenHeader = "X-Webhook-Token"
+
+// labelStudioWebhook applies Label Studio annotation events to internal annotations
+// @Summary Receive Label Studio annotation events
+// @Description Handles ANNOTATION_CREATED, ANNOTATION_UPDATED and ANNOTATIONS_DELETED; other actions are acknowledged and ignored. Requires the token in X-Webhook-Token when LABELSTUDIO_WEBHOOK_TOKEN is set; the caller is authenticated by JWT or API key like on any other route, which also picks the project.
+// @Tags Integrations
+// @Accept json
+// @Produce json
+// @Param event body models.LabelStudioWebhookEvent true "Label Studio webhook event"
+// @Success 200 {object} models.LabelStudioWebhookResponse
+// @Router /api/v1/integrations/labelstudio/webhook [post]
+func (s *Server) labelStudioWebhook(c *gin.Context) {
+	if token := s.cfg.LabelStudioWebhookToken; token != "" {
+		provided := c.GetHeader(webhookTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook token"})
+			return
+		}
+	}
+
+	var event models.LabelStudioWebhookEvent
+	if err := c.ShouldBindJSON(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := models.LabelStudioWebhookResponse{Action: event.Action}
+	switch event.Action {
+	case models.LabelStudioAnnotationCreated, models.LabelStudioAnnotationUpdated:
+		// Skipped tasks carry no labels
+		if event.Annotation != nil && event.Annotation.WasCancelled {
+			resp.Ignored = true
+			break
+		}
+
+		anns, err := services.LabelStudioAnnotations(event.Task, event.Annotation)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		externalID := strconv.FormatInt(event.Annotation.ID, 10)
+		resp.Annotations, err = s.projectRepo(c).ReplaceExternalAnnotations(labelStudioSource, externalID, anns)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if resp.Annotations == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+			return
+		}
+
+	case models.LabelStudioAnnotationsDeleted:
+		externalIDs := make([]string, len(event.Annotations))
+		for i, ann := range event.Annotations {
+			externalIDs[i] = strconv.FormatInt(ann.ID, 10)
+		}
+		deleted, err := s.projectRepo(c).DeleteExternalAnnotations(labelStudioSource, externalIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		resp.Deleted = deleted
+
+	default:
+		resp.Ignored = true
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
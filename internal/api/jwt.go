@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys of the authenticated JWT's subject and roles
+const (
+	jwtSubjectKey = "jwt_subject"
+	jwtRolesKey   = "jwt_roles"
+)
+
+// jwtMiddleware authenticates requests by JWT bearer token when JWT
+// authentication is enabled, and rejects tokens whose roles may not call the
+// route. Requests carrying an API key instead are left to apiKeyMiddleware
+// when API key authentication is enabled too. Internal routes authenticate
+// service accounts instead.
+func (s *Server) jwtMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if !s.cfg.JWTAuthEnabled || route == "" || strings.Contains(route, "/internal/") {
+			c.Next()
+			return
+		}
+
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			if s.cfg.APIKeyAuthEnabled && c.GetHeader(apiKeyHeader) != "" {
+				c.Next()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing bearer token"})
+			return
+		}
+
+		claims, err := services.ParseJWT(token, s.cfg.JWTSecret, s.cfg.JWTIssuer, time.Now())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if !services.RolesGrant(claims.Roles, c.Request.Method, route) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Token roles may not call this endpoint"})
+			return
+		}
+
+		c.Set(jwtSubjectKey, claims.Subject)
+		c.Set(jwtRolesKey, claims.Roles)
+		c.Next()
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/llm"
+	"github.com/gin-gonic/gin"
+)
+
+// getLLMUsage reports the calls made to each LLM provider by this replica
+// @Summary Get LLM provider usage
+// @Description Providers are listed in fallback order: LLM_PROVIDER first, then the others with an API key. Counts cover this replica since it started. Providers that returned 429 are passed over until their cooldown ends.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /api/v1/admin/llm/usage [get]
+func (s *Server) getLLMUsage(c *gin.Context) {
+	if s.llm == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "No LLM provider has an API key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"providers": s.llm.Usage()})
+}
+
+// writeLLMMetrics writes LLM provider usage in the Prometheus text format
+func writeLLMMetrics(b *strings.Builder, usage []llm.ProviderUsage) {
+	metrics := []struct {
+		name, help, kind string
+		value            func(u llm.ProviderUsage) float64
+	}{
+		{"llm_requests_total", "Requests made to the provider", "counter", func(u llm.ProviderUsage) float64 { return float64(u.Requests) }},
+		{"llm_failures_total", "Requests that failed", "counter", func(u llm.ProviderUsage) float64 { return float64(u.Failures) }},
+		{"llm_rate_limited_total", "Requests rejected with status 429", "counter", func(u llm.ProviderUsage) float64 { return float64(u.RateLimited) }},
+		{"llm_fallbacks_total", "Failed requests retried on the next provider", "counter", func(u llm.ProviderUsage) float64 { return float64(u.Fallbacks) }},
+		{"llm_input_tokens_total", "Input tokens reported by the provider", "counter", func(u llm.ProviderUsage) float64 { return float64(u.InputTokens) }},
+		{"llm_output_tokens_total", "Output tokens reported by the provider", "counter", func(u llm.ProviderUsage) float64 { return float64(u.OutputTokens) }},
+		{"llm_avg_latency_ms", "Average request latency", "gauge", func(u llm.ProviderUsage) float64 { return u.AvgLatencyMS }},
+		{"llm_cooling_down", "1 while the provider is passed over after a 429", "gauge", func(u llm.ProviderUsage) float64 {
+			if u.CooldownUntil != nil {
+				return 1
+			}
+			return 0
+		}},
+	}
+
+	for _, metric := range metrics {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+		for _, u := range usage {
+			fmt.Fprintf(b, "%s{provider=%q,model=%q} %g\n", metric.name, u.Provider, u.Model, metric.value(u))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"log"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// localize translates texts in place into the language the request's
+// Accept-Language header prefers. Text stays untranslated when localization
+// is disabled, the client prefers the source language or translation fails.
+func (s *Server) localize(c *gin.Context, texts []*string) {
+	if s.translator == nil {
+		return
+	}
+	c.Header("Vary", "Accept-Language")
+
+	language := s.translator.Language(c.GetHeader("Accept-Language"))
+	if language == "" || len(texts) == 0 {
+		return
+	}
+
+	values := make([]string, len(texts))
+	for i, text := range texts {
+		values[i] = *text
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), s.cfg.LocalizationTimeout)
+	defer cancel()
+	translated, err := s.translator.Translate(ctx, language, values)
+	if err != nil {
+		log.Printf("Failed to translate into %s: %v", language, err)
+		return
+	}
+
+	for i, text := range texts {
+		*text = translated[i]
+	}
+	c.Header("Content-Language", language)
+}
+
+// localizeEvaluations translates the issue descriptions and suggestions of
+// evaluation responses
+func (s *Server) localizeEvaluations(c *gin.Context, evals []*models.EvaluationResponse) {
+	var texts []*string
+	for _, eval := range evals {
+		for i := range eval.IssuesDetected {
+			texts = append(texts, &eval.IssuesDetected[i].Description)
+		}
+		for i := range eval.ImprovementSuggestions {
+			suggestion := &eval.ImprovementSuggestions[i]
+			texts = append(texts, &suggestion.Suggestion, &suggestion.Rationale)
+		}
+	}
+	s.localize(c, texts)
+}
+
+// localizeSuggestions translates stored improvement suggestions
+func (s *Server) localizeSuggestions(c *gin.Context, suggestions []models.StoredSuggestion) {
+	texts := make([]*string, 0, 2*len(suggestions))
+	for i := range suggestions {
+		texts = append(texts, &suggestions[i].Suggestion, &suggestions[i].Rationale)
+	}
+	s.localize(c, texts)
+}
+
+// localizeIssueAssignments translates the descriptions of assigned issues
+func (s *Server) localizeIssueAssignments(c *gin.Context, issues []models.IssueAssignment) {
+	texts := make([]*string, len(issues))
+	for i := range issues {
+		texts[i] = &issues[i].Description
+	}
+	s.localize(c, texts)
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+	"github.com/gin-gonic/gin"
+)
+
+// maintenanceCheckInterval is how long a replica may take to notice that
+// maintenance mode was turned on or off by another replica
+const maintenanceCheckInterval = 2 * time.Second
+
+// maintenanceMiddleware rejects writes with 503 while maintenance mode is on.
+// The maintenance endpoints themselves are exempt so it can always be ended.
+func (s *Server) maintenanceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if strings.HasSuffix(c.Request.URL.Path, "/admin/maintenance") {
+			c.Next()
+			return
+		}
+
+		m := s.maintenance.Get()
+		if m == nil {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", strconv.Itoa(m.RetryAfterSeconds))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":       "The API is read-only for maintenance",
+			"maintenance": m,
+		})
+	}
+}
+
+// getMaintenance reports whether maintenance mode is on
+// @Summary Get maintenance mode
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/maintenance [get]
+func (s *Server) getMaintenance(c *gin.Context) {
+	m, err := s.queue.GetMaintenance()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"read_only":   m != nil,
+		"maintenance": m,
+	})
+}
+
+// startMaintenance puts the API in read-only maintenance mode
+// @Summary Start maintenance mode
+// @Description Every replica rejects writes with 503 and a Retry-After header, and workers stop taking tasks, until maintenance is ended or its duration runs out. Replicas pick the change up within a few seconds.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.MaintenanceStart true "Maintenance window"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/maintenance [put]
+func (s *Server) startMaintenance(c *gin.Context) {
+	var req models.MaintenanceStart
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	m := &queue.Maintenance{
+		Reason:            req.Reason,
+		StartedBy:         c.GetString(jwtSubjectKey),
+		StartedAt:         time.Now().UTC(),
+		RetryAfterSeconds: req.RetryAfterSeconds,
+	}
+	if m.StartedBy == "" {
+		m.StartedBy = c.GetString(apiKeyNameKey)
+	}
+	if m.RetryAfterSeconds == 0 {
+		m.RetryAfterSeconds = int(queue.DefaultMaintenanceRetryAfter / time.Second)
+	}
+
+	if err := s.queue.SetMaintenance(m, time.Duration(req.DurationMinutes)*time.Minute); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	s.maintenance.Refresh()
+
+	c.JSON(http.StatusOK, gin.H{
+		"read_only":   true,
+		"maintenance": m,
+	})
+}
+
+// endMaintenance takes the API out of maintenance mode
+// @Summary End maintenance mode
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/maintenance [delete]
+func (s *Server) endMaintenance(c *gin.Context) {
+	if err := s.queue.ClearMaintenance(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	s.maintenance.Refresh()
+
+	c.JSON(http.StatusOK, gin.H{"read_only": false})
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maskingProfileHeader tells the caller which masking profile a response's
+// transcripts were read with
+const maskingProfileHeader = "X-Masking-Profile"
+
+// maskingProfile returns the masking profile of the caller's JWT roles or API
+// key scopes. Unauthenticated requests and the bootstrap admin key read
+// transcripts in full.
+func (s *Server) maskingProfile(c *gin.Context) string {
+	profile := services.MaskingFull
+	if roles, ok := c.Get(jwtRolesKey); ok {
+		grants, _ := roles.([]string)
+		profile = services.MaskingProfileFor(grants, s.cfg.MaskingProfiles)
+	} else if scopes, ok := c.Get(apiKeyScopesKey); ok {
+		grants, _ := scopes.([]string)
+		profile = services.MaskingProfileFor(grants, s.cfg.MaskingProfiles)
+	}
+
+	c.Header(maskingProfileHeader, profile)
+	return profile
+}
+
+// maskConversations applies the caller's masking profile to the turns of
+// conversations
+func (s *Server) maskConversations(c *gin.Context, convs []models.Conversation) error {
+	profile := s.maskingProfile(c)
+	for i := range convs {
+		turns, err := services.MaskTurnsJSON(convs[i].Turns, profile)
+		if err != nil {
+			return err
+		}
+		convs[i].Turns = turns
+	}
+	return nil
+}
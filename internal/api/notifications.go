@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// setNotificationPreference sets a team's notification preferences for a project
+// @Summary Set notification preferences
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param project_id path string true "Project ID"
+// @Param team path string true "Team"
+// @Param preferences body models.NotificationPreferenceUpdate true "Notification preferences"
+// @Success 200 {object} models.NotificationPreference
+// @Router /api/v1/projects/{project_id}/notifications/{team} [put]
+func (s *Server) setNotificationPreference(c *gin.Context) {
+	var req models.NotificationPreferenceUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.NormalizeNotificationPreference(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pref, err := s.repo.UpsertNotificationPreference(c.Param("project_id"), c.Param("team"), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
+
+// listNotificationPreferences lists the notification preferences of a project's teams
+// @Summary List notification preferences
+// @Tags Notifications
+// @Produce json
+// @Param project_id path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/projects/{project_id}/notifications [get]
+func (s *Server) listNotificationPreferences(c *gin.Context) {
+	prefs, err := s.repo.ListNotificationPreferences(c.Param("project_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"preferences": prefs,
+		"count":       len(prefs),
+	})
+}
+
+// getNotificationPreference retrieves a team's notification preferences
+// @Summary Get notification preferences
+// @Tags Notifications
+// @Produce json
+// @Param project_id path string true "Project ID"
+// @Param team path string true "Team"
+// @Success 200 {object} models.NotificationPreference
+// @Router /api/v1/projects/{project_id}/notifications/{team} [get]
+func (s *Server) getNotificationPreference(c *gin.Context) {
+	pref, err := s.repo.GetNotificationPreference(c.Param("project_id"), c.Param("team"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if pref == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification preferences not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
+
+// deleteNotificationPreference removes a team's notification preferences
+// @Summary Delete notification preferences
+// @Tags Notifications
+// @Produce json
+// @Param project_id path string true "Project ID"
+// @Param team path string true "Team"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/projects/{project_id}/notifications/{team} [delete]
+func (s *Server) deleteNotificationPreference(c *gin.Context) {
+	deleted, err := s.repo.DeleteNotificationPreference(c.Param("project_id"), c.Param("team"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification preferences not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "deleted",
+		"project_id": c.Param("project_id"),
+		"team":       c.Param("team"),
+	})
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/queue"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// ingestTraces ingests agent runs exported as OpenTelemetry traces
+// @Summary Ingest OTLP traces
+// @Description An OTLP/HTTP trace receiver: point an exporter's endpoint at /api/v1/otlp with JSON encoding (the protobuf encoding isn't supported). Spans are assembled into conversations following the generative AI semantic conventions: model call spans give the messages, tool spans the call latencies and results. Spans are grouped by gen_ai.conversation.id or session.id, or else by trace, and exports of the same conversation append its new turns. The response is an OTLP export response whose partial success counts the spans of conversations that couldn't be ingested.
+// @Tags Ingestion
+// @Accept json
+// @Produce json
+// @Param agent_version query string false "Agent version of traces whose resource has no service.version"
+// @Param auto_evaluate query bool false "Auto trigger evaluation" default(true)
+// @Success 200 {object} map[string]interface{}
+// @Failure 415 {object} map[string]interface{}
+// @Router /api/v1/otlp/v1/traces [post]
+func (s *Server) ingestTraces(c *gin.Context) {
+	if mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type")); mediaType != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Only OTLP/HTTP JSON is supported; set the exporter's encoding to json"})
+		return
+	}
+
+	body := io.Reader(http.MaxBytesReader(c.Writer, c.Request.Body, s.cfg.UploadMaxBytes))
+	if strings.EqualFold(c.GetHeader("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer gz.Close()
+		// The limit applies to the decompressed body as well
+		body = http.MaxBytesReader(c.Writer, gz, s.cfg.UploadMaxBytes)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("export exceeds the limit of %d bytes", s.cfg.UploadMaxBytes)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conversations, err := services.ConvertOTLPTraces(data, c.Query("agent_version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	autoEvaluate := c.DefaultQuery("auto_evaluate", "true") == "true"
+	repo := s.projectRepo(c)
+	rejectedSpans := 0
+	var rejections []string
+	for _, conv := range conversations {
+		err := conv.Err
+		if err == nil {
+			err = binding.Validator.ValidateStruct(conv.Conversation)
+		}
+		if err == nil {
+			err = services.ValidateTurns(conv.Conversation.Turns)
+		}
+		if err == nil {
+			err = s.attachments.Validate(conv.Conversation.Turns)
+		}
+		if err == nil {
+			_, _, err = s.ingestConversation(repo, conv.Conversation, autoEvaluate, queue.TriggerOTLP)
+		}
+		if err != nil {
+			log.Printf("Rejected OTLP conversation %s: %v", conv.ConversationID, err)
+			rejectedSpans += conv.Spans
+			rejections = append(rejections, fmt.Sprintf("%s: %v", conv.ConversationID, err))
+		}
+	}
+
+	if rejectedSpans == 0 {
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"partialSuccess": gin.H{
+			"rejectedSpans": strconv.Itoa(rejectedSpans),
+			"errorMessage":  strings.Join(rejections, "; "),
+		},
+	})
+}
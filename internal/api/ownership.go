@@ -0,0 +1,124 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// unownedOwner is the owner name that addresses work no owner was assigned to
+const unownedOwner = "unowned"
+
+// listOwners summarizes open issues and unresolved patterns per owner
+// @Summary List issue owners
+// @Description Work without an owner is reported under "unowned"
+// @Tags Ownership
+// @Produce json
+// @Param days query int false "Days of issues to count" default(30)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/owners [get]
+func (s *Server) listOwners(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	since := time.Now().AddDate(0, 0, -days)
+
+	summaries, err := s.projectRepo(c).GetOwnerSummaries(since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range summaries {
+		if summaries[i].Owner == "" {
+			summaries[i].Owner = unownedOwner
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"owners": summaries,
+		"count":  len(summaries),
+	})
+}
+
+// getOwnerIssues lists the detected issues assigned to an owner
+// @Summary List an owner's issues
+// @Tags Ownership
+// @Produce json
+// @Param owner path string true "Owner, or unowned"
+// @Param severity query string false "Filter by severity"
+// @Param days query int false "Days to look back" default(30)
+// @Param include_dismissed query bool false "Include dismissed issues" default(false)
+// @Param limit query int false "Limit" default(100)
+// @Param offset query int false "Offset" default(0)
+// @Param Accept-Language header string false "Language to translate issues and suggestions into"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/owners/{owner}/issues [get]
+func (s *Server) getOwnerIssues(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	since := time.Now().AddDate(0, 0, -days)
+
+	issues, err := s.projectRepo(c).ListOwnerIssues(ownerParam(c), c.Query("severity"), since,
+		c.Query("include_dismissed") == "true", limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	s.localizeIssueAssignments(c, issues)
+
+	c.JSON(http.StatusOK, gin.H{
+		"owner":  c.Param("owner"),
+		"issues": issues,
+		"count":  len(issues),
+	})
+}
+
+// getOwnerPatterns lists the failure patterns assigned to an owner
+// @Summary List an owner's failure patterns
+// @Tags Ownership
+// @Produce json
+// @Param owner path string true "Owner"
+// @Param resolved query bool false "Filter by resolved status"
+// @Param limit query int false "Limit" default(50)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/owners/{owner}/patterns [get]
+func (s *Server) getOwnerPatterns(c *gin.Context) {
+	owner := ownerParam(c)
+	if owner == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unowned patterns can't be listed by owner"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	var resolved *bool
+	if r := c.Query("resolved"); r != "" {
+		v := r == "true"
+		resolved = &v
+	}
+
+	patterns, err := s.projectRepo(c).GetFailurePatterns(resolved, "", owner, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if patterns == nil {
+		patterns = []models.FailurePattern{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"owner":    owner,
+		"patterns": patterns,
+		"count":    len(patterns),
+	})
+}
+
+// ownerParam returns the owner path parameter, mapping unowned to the empty owner
+func ownerParam(c *gin.Context) string {
+	owner := c.Param("owner")
+	if owner == unownedOwner {
+		return ""
+	}
+	return owner
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// recordPipelineStage records that an evaluation task reached a pipeline
+// stage. Workers report the stages that happen outside the API: dequeued and
+// evaluator_started, and persisted when they store evaluations themselves.
+// @Summary Record evaluation pipeline stage
+// @Tags Evaluation
+// @Accept json
+// @Produce json
+// @Param task_id path string true "Task ID"
+// @Param request body models.PipelineStageReport true "Stage reached"
+// @Success 204
+// @Router /api/v1/pipeline/tasks/{task_id}/stages [post]
+func (s *Server) recordPipelineStage(c *gin.Context) {
+	var req models.PipelineStageReport
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	known := false
+	for _, stage := range models.PipelineStages {
+		known = known || stage == req.Stage
+	}
+	if !known {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "stage must be one of " + strings.Join(models.PipelineStages, ", "),
+		})
+		return
+	}
+	if req.At.IsZero() {
+		req.At = time.Now()
+	}
+
+	if err := s.repo.RecordPipelineStage(c.Param("task_id"), req.Stage, req.At); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
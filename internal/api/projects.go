@@ -0,0 +1,216 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/repository"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// projectHeader names the project a request reads and writes
+const projectHeader = "X-Project-ID"
+
+// projectKey is the context key of the request's project
+const projectKey = "project_id"
+
+// projectMiddleware resolves the project a request is scoped to: the one its
+// API key is bound to, else the X-Project-ID header, else the default
+// project. Keys bound to a project may not name another one, either in the
+// header or in a /projects/:project_id route. Internal routes are unscoped.
+func (s *Server) projectMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" || strings.Contains(route, "/internal/") {
+			c.Next()
+			return
+		}
+
+		projectID := c.GetHeader(projectHeader)
+		if bound := c.GetString(apiKeyProjectKey); bound != "" {
+			pathProject := c.Param("project_id")
+			if (projectID != "" && projectID != bound) || (pathProject != "" && pathProject != bound) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key is bound to project " + bound})
+				return
+			}
+			projectID = bound
+		}
+		if projectID == "" {
+			projectID = models.DefaultProjectID
+		}
+
+		if _, known := s.projects.Load(projectID); !known {
+			project, err := s.repo.GetProject(projectID)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if project == nil {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+				return
+			}
+			s.projects.Store(projectID, true)
+		}
+
+		c.Set(projectKey, projectID)
+		c.Next()
+	}
+}
+
+// projectRepo returns the repository scoped to the request's project
+func (s *Server) projectRepo(c *gin.Context) *repository.Repository {
+	return s.repo.ForProject(c.GetString(projectKey))
+}
+
+// onboardingAPIKeyScopes are the scopes of the API keys an onboarded project
+// starts with, by key name
+var onboardingAPIKeyScopes = []struct {
+	name  string
+	scope string
+}{
+	{name: "ingest", scope: services.APIKeyScopeIngest},
+	{name: "read", scope: services.APIKeyScopeRead},
+}
+
+// onboardingWebhooks are the disabled webhooks an onboarded project starts
+// with, enabled by giving them a URL
+var onboardingWebhooks = []models.ProjectWebhookCreate{
+	{},
+	{Filter: models.WebhookFilter{CriticalOnly: true}},
+}
+
+// createProject onboards a project: it creates the project with its evaluator
+// profile, routing policy, quotas and assisted labeling settings, an ingest
+// and a read API key bound to it and placeholder webhooks, all in one
+// transaction
+// @Summary Onboard project
+// @Description Settings left out default to the deployment's. The API keys are only returned in this response. Webhooks are disabled until given a URL with PUT /projects/{project_id}/webhooks/{webhook_id}.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param project body models.ProjectCreate true "Project"
+// @Success 201 {object} models.ProjectOnboarding
+// @Router /api/v1/projects [post]
+func (s *Server) createProject(c *gin.Context) {
+	var req models.ProjectCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pipeline := s.pipeline.Get()
+	evaluatorTypes := req.EvaluatorTypes
+	if len(evaluatorTypes) == 0 {
+		evaluatorTypes = pipeline.DefaultEvaluatorTypes
+	}
+	evaluatorTypes, warnings := services.CheckEvaluatorTypes(evaluatorTypes, services.HasLLMCredentials(s.cfg))
+	if len(evaluatorTypes) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":    "Requested evaluators cannot run",
+			"warnings": warnings,
+		})
+		return
+	}
+	req.EvaluatorTypes = evaluatorTypes
+	if req.Routing == nil {
+		req.Routing = &pipeline.Routing
+	}
+	if req.Quotas == nil {
+		req.Quotas = &models.ProjectQuotas{MaxConversationsPerDay: s.cfg.ProjectMaxConversationsPerDay}
+	}
+	if req.AssistedLabeling == nil {
+		req.AssistedLabeling = &models.AssistedLabeling{}
+	}
+	if err := services.ValidateAssistedLabeling(*req.AssistedLabeling); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	keys := make([]models.APIKeySecret, len(onboardingAPIKeyScopes))
+	for i, k := range onboardingAPIKeyScopes {
+		key, prefix, hash, err := services.NewAPIKey()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		keys[i] = models.APIKeySecret{
+			APIKey: models.APIKey{
+				ID:      uuid.New().String(),
+				Name:    req.ProjectID + "-" + k.name,
+				Prefix:  prefix,
+				KeyHash: hash,
+				Scopes:  json.RawMessage(`["` + k.scope + `"]`),
+			},
+			Key: key,
+		}
+	}
+
+	onboarding, err := s.repo.ProvisionProject(&req, keys, onboardingWebhooks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if onboarding == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project already exists"})
+		return
+	}
+	onboarding.Warnings = warnings
+
+	s.projects.Store(req.ProjectID, true)
+	c.JSON(http.StatusCreated, onboarding)
+}
+
+// listProjects lists projects
+// @Summary List projects
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/projects [get]
+func (s *Server) listProjects(c *gin.Context) {
+	projects, err := s.repo.ListProjects()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"projects": projects,
+		"count":    len(projects),
+	})
+}
+
+// setProjectPublic opts a project in to or out of the public API
+// @Summary Set project public access
+// @Description Only projects that opt in have their aggregates served on the public API; others get 404 there. Cached public responses are dropped on every replica.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param project_id path string true "Project ID"
+// @Param access body models.PublicAccess true "Public access"
+// @Success 200 {object} models.Project
+// @Router /api/v1/projects/{project_id}/public [put]
+func (s *Server) setProjectPublic(c *gin.Context) {
+	var access models.PublicAccess
+	if err := c.ShouldBindJSON(&access); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := s.repo.SetProjectPublic(c.Param("project_id"), *access.Public)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if project == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+	s.publicCache.clear()
+	s.publishInvalidation(cacheScopeAnalytics)
+
+	c.JSON(http.StatusOK, project)
+}
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Bounds on the public API's in-memory state
+const (
+	maxPublicCacheEntries = 1000
+	maxRateLimitedClients = 10000
+)
+
+// PublicRouter returns the router of the public API: a read-only subset of
+// aggregate endpoints, with no raw conversations, annotations or
+// configuration, for sharing quality metrics outside the team. Every request
+// is scoped to one project, named by the X-Project-ID header or else the
+// default project, so no response aggregates several teams' data, and only
+// projects that opted in are served. Responses are cached per project and
+// every client is rate limited.
+func (s *Server) PublicRouter() *gin.Engine {
+	gin.SetMode(s.cfg.GinMode)
+	r := gin.New()
+
+	limiter := newRateLimiter(s.cfg.PublicAPIRateLimit, s.cfg.PublicAPIBurst)
+
+	r.Use(gin.Logger())
+	r.Use(gin.Recovery())
+	r.Use(readOnlyMiddleware())
+	r.Use(limiter.middleware())
+
+	r.GET("/health", s.healthCheck)
+
+	public := r.Group("/public/v1", s.projectMiddleware(), s.publicProjectMiddleware(), s.publicCache.middleware())
+	public.GET("/stats", s.getStats)
+	public.GET("/analytics/throughput", s.getThroughput)
+	public.GET("/analytics/tool-latency", s.getToolLatencyStats)
+	public.GET("/analytics/health-leaderboard", s.getHealthLeaderboard)
+	public.GET("/analytics/intents", s.getIntentQuality)
+
+	r.NoRoute(func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+	})
+
+	return r
+}
+
+// publicProjectMiddleware serves only projects that opted in to the public
+// API. Others look like they don't exist, so their IDs aren't confirmed.
+func (s *Server) publicProjectMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		project, err := s.repo.GetProject(c.GetString(projectKey))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if project == nil || !project.Public {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// readOnlyMiddleware rejects anything but reads and allows cross-origin reads
+func readOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead:
+			c.Next()
+		case http.MethodOptions:
+			c.AbortWithStatus(http.StatusNoContent)
+		default:
+			c.Header("Allow", "GET, HEAD, OPTIONS")
+			c.AbortWithStatusJSON(http.StatusMethodNotAllowed, gin.H{"error": "The public API is read-only"})
+		}
+	}
+}
+
+// rateLimiter limits each client IP with a token bucket
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // Tokens per second
+	burst   float64
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter allows perMinute requests per minute per client, in bursts
+// of up to burst requests
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes a token from the client's bucket. When none is left it returns
+// false and how long until one is.
+func (l *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		if len(l.buckets) >= maxRateLimitedClients {
+			l.prune(now)
+		}
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = bucket
+	}
+
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	if l.rate <= 0 {
+		return false, time.Minute
+	}
+	return false, time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+}
+
+// prune forgets clients whose buckets have refilled, as they are the same
+// as new ones
+func (l *rateLimiter) prune(now time.Time) {
+	for client, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+func (l *rateLimiter) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter := l.allow(c.ClientIP(), time.Now())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// responseCache caches successful responses by path and query
+type responseCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	status      int
+	contentType string
+	body        []byte
+	expires     time.Time
+}
+
+func newResponseCache(ttl time.Duration, maxEntries int) *responseCache {
+	return &responseCache{ttl: ttl, maxEntries: maxEntries, entries: make(map[string]cachedResponse)}
+}
+
+func (rc *responseCache) get(key string, now time.Time) (cachedResponse, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[key]
+	if !ok || now.After(entry.expires) {
+		return cachedResponse{}, false
+	}
+	return entry, true
+}
+
+// clear drops every cached response
+func (rc *responseCache) clear() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries = make(map[string]cachedResponse)
+}
+
+func (rc *responseCache) put(key string, entry cachedResponse, now time.Time) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.entries) >= rc.maxEntries {
+		// Drop expired entries, and everything if that isn't enough
+		for k, e := range rc.entries {
+			if now.After(e.expires) {
+				delete(rc.entries, k)
+			}
+		}
+		if len(rc.entries) >= rc.maxEntries {
+			rc.entries = make(map[string]cachedResponse)
+		}
+	}
+	rc.entries[key] = entry
+}
+
+// recordingWriter keeps a copy of the response body and marks only
+// successful responses cacheable
+type recordingWriter struct {
+	gin.ResponseWriter
+	cacheControl string
+	body         bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(code int) {
+	if code == http.StatusOK {
+		w.Header().Set("Cache-Control", w.cacheControl)
+	} else {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+func (rc *responseCache) middleware() gin.HandlerFunc {
+	cacheControl := "public, max-age=" + strconv.Itoa(int(rc.ttl.Seconds()))
+	return func(c *gin.Context) {
+		// Encoding sorts the query so equivalent requests share an entry
+		key := c.GetString(projectKey) + " " + c.Request.URL.Path + "?" + c.Request.URL.Query().Encode()
+		c.Header("Vary", projectHeader)
+		now := time.Now()
+
+		if entry, ok := rc.get(key, now); ok {
+			c.Header("X-Cache", "HIT")
+			c.Header("Cache-Control", cacheControl)
+			c.Data(entry.status, entry.contentType, entry.body)
+			c.Abort()
+			return
+		}
+
+		c.Header("X-Cache", "MISS")
+		writer := &recordingWriter{ResponseWriter: c.Writer, cacheControl: cacheControl}
+		c.Writer = writer
+		c.Next()
+
+		if writer.Status() == http.StatusOK {
+			rc.put(key, cachedResponse{
+				status:      http.StatusOK,
+				contentType: writer.Header().Get("Content-Type"),
+				body:        writer.body.Bytes(),
+				expires:     now.Add(rc.ttl),
+			}, now)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/queue"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// listQueues reports backlog, throughput and lag for each queue, and how
+// evenly queues split by project are shared between projects
+// @Summary Get queue health
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/queues [get]
+func (s *Server) listQueues(c *gin.Context) {
+	health, err := services.CollectQueueHealth(s.queue, s.repo, s.pipeline.Get().Alerts.QueueLagThresholds)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	fairness, err := services.CollectQueueFairness(s.queue, services.NewFairnessPolicy(s.cfg))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"queues":   health,
+		"count":    len(health),
+		"fairness": fairness,
+	})
+}
+
+// maxDeadLetterListLimit caps how many dead letters one listing returns
+const maxDeadLetterListLimit = 1000
+
+// knownQueue reports whether a queue name is one of the monitored queues
+func knownQueue(name string) bool {
+	for _, q := range queue.MonitoredQueues {
+		if q == name {
+			return true
+		}
+	}
+	return false
+}
+
+// listDeadLetters lists the tasks a queue dead-lettered, oldest first
+// @Summary List dead-lettered tasks
+// @Tags Admin
+// @Produce json
+// @Param queue path string true "Queue name"
+// @Param limit query int false "Limit" default(100)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/queues/{queue}/dead-letters [get]
+func (s *Server) listDeadLetters(c *gin.Context) {
+	queueName := c.Param("queue")
+	if !knownQueue(queueName) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > maxDeadLetterListLimit || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d and offset non-negative", maxDeadLetterListLimit)})
+		return
+	}
+
+	letters, err := s.queue.ListDeadLetters(queueName, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	total, err := s.queue.DeadLetterCount(queueName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"queue":        queueName,
+		"dead_letters": letters,
+		"count":        len(letters),
+		"total":        total,
+	})
+}
+
+// requeueDeadLetter puts a dead-lettered task back on its queue with its
+// attempts reset
+// @Summary Requeue dead-lettered task
+// @Tags Admin
+// @Produce json
+// @Param queue path string true "Queue name"
+// @Param task_id path string true "Task ID"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/admin/queues/{queue}/dead-letters/{task_id}/requeue [post]
+func (s *Server) requeueDeadLetter(c *gin.Context) {
+	queueName := c.Param("queue")
+	if !knownQueue(queueName) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Queue not found"})
+		return
+	}
+
+	task, err := s.queue.RequeueDeadLetter(queueName, c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if task == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dead-lettered task not found"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"task_id":         task.ID,
+		"conversation_id": task.ConversationID,
+		"status":          "queued",
+	})
+}
+
+// getTaskJournal returns the journal entry of a dequeued task with its state
+// transitions
+// @Summary Get task journal entry
+// @Tags Admin
+// @Produce json
+// @Param task_id path string true "Task ID"
+// @Success 200 {object} models.TaskJournalEntry
+// @Router /api/v1/admin/tasks/{task_id}/journal [get]
+func (s *Server) getTaskJournal(c *gin.Context) {
+	entry, err := s.repo.GetJournaledTask(c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if entry == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not journaled"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// queueMetrics exposes queue health in the Prometheus text format
+// @Summary Queue metrics
+// @Tags Health
+// @Produce plain
+// @Success 200 {string} string
+// @Router /metrics [get]
+func (s *Server) queueMetrics(c *gin.Context) {
+	health, err := services.CollectQueueHealth(s.queue, s.repo, s.pipeline.Get().Alerts.QueueLagThresholds)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	metrics := []struct {
+		name, help string
+		value      func(h services.QueueHealth) float64
+	}{
+		{"queue_length", "Tasks waiting in the queue", func(h services.QueueHealth) float64 { return float64(h.Length) }},
+		{"queue_enqueued_per_minute", "Average tasks enqueued per minute", func(h services.QueueHealth) float64 { return h.EnqueuedPerMinute }},
+		{"queue_dequeued_per_minute", "Average tasks dequeued per minute", func(h services.QueueHealth) float64 { return h.DequeuedPerMinute }},
+		{"queue_oldest_task_age_seconds", "Age of the oldest pending task", func(h services.QueueHealth) float64 { return h.OldestTaskAgeSeconds }},
+		{"queue_delayed", "Tasks waiting to be retried or for their scheduled time", func(h services.QueueHealth) float64 { return float64(h.Delayed) }},
+		{"queue_dead_letters", "Tasks that failed every attempt", func(h services.QueueHealth) float64 { return float64(h.DeadLetters) }},
+		{"queue_compressed_tasks", "Tasks whose payload was compressed", func(h services.QueueHealth) float64 { return float64(h.Compression.Tasks) }},
+		{"queue_compression_saved_bytes", "Bytes saved by compressing task payloads", func(h services.QueueHealth) float64 { return float64(h.Compression.SavedBytes) }},
+		{"queue_compression_ratio", "Compressed over uncompressed size of compressed payloads", func(h services.QueueHealth) float64 { return h.Compression.Ratio }},
+		{"queue_lag_threshold_seconds", "Lag alert threshold", func(h services.QueueHealth) float64 { return float64(h.LagThresholdSeconds) }},
+		{"queue_lagging", "1 if the queue lag exceeds its threshold", func(h services.QueueHealth) float64 {
+			if h.Lagging {
+				return 1
+			}
+			return 0
+		}},
+	}
+
+	var b strings.Builder
+	for _, metric := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", metric.name, metric.help, metric.name)
+		for _, h := range health {
+			fmt.Fprintf(&b, "%s{queue=%q} %g\n", metric.name, h.Queue, metric.value(h))
+		}
+	}
+	if s.llm != nil {
+		writeLLMMetrics(&b, s.llm.Usage())
+	}
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4", []byte(b.String()))
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// bulkApproveRouting approves the routing decisions of many conversations
+// @Summary Bulk approve routing decisions
+// @Tags Review
+// @Accept json
+// @Produce json
+// @Param request body models.BulkRoutingApproval true "Conversations to approve"
+// @Success 200 {object} models.BulkActionResponse
+// @Router /api/v1/review/bulk/routing-approvals [post]
+func (s *Server) bulkApproveRouting(c *gin.Context) {
+	var req models.BulkRoutingApproval
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := s.projectRepo(c).BulkApproveRouting(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// bulkDismissIssues dismisses many low-severity issues
+// @Summary Bulk dismiss low-severity issues
+// @Tags Review
+// @Accept json
+// @Produce json
+// @Param request body models.BulkIssueDismissal true "Issues to dismiss"
+// @Success 200 {object} models.BulkActionResponse
+// @Router /api/v1/review/bulk/issue-dismissals [post]
+func (s *Server) bulkDismissIssues(c *gin.Context) {
+	var req models.BulkIssueDismissal
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := s.projectRepo(c).BulkDismissIssues(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// bulkAssignAnnotationTasks assigns annotation tasks for many conversations to an annotator
+// @Summary Bulk assign annotation tasks
+// @Tags Review
+// @Accept json
+// @Produce json
+// @Param request body models.BulkAnnotationAssignment true "Tasks to assign"
+// @Success 200 {object} models.BulkActionResponse
+// @Router /api/v1/review/bulk/annotation-assignments [post]
+func (s *Server) bulkAssignAnnotationTasks(c *gin.Context) {
+	var req models.BulkAnnotationAssignment
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := s.projectRepo(c).BulkAssignAnnotationTasks(&req, s.pipeline.Get().Routing)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// listAnnotationTasks lists annotation tasks as annotators' queues: highest
+// routing priority first, then the soonest due
+// @Summary List annotation tasks
+// @Tags Review
+// @Produce json
+// @Param annotator_id query string false "Annotator ID"
+// @Param status query string false "open (default), assigned, completed or all"
+// @Param limit query int false "Maximum tasks" default(100)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/review/tasks [get]
+func (s *Server) listAnnotationTasks(c *gin.Context) {
+	status := c.DefaultQuery("status", "open")
+	if status == "all" {
+		status = ""
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	tasks, err := s.projectRepo(c).ListAnnotationTasks(c.Query("annotator_id"), status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tasks": tasks,
+		"count": len(tasks),
+	})
+}
+
+// getReviewAging reports the review backlog by age and priority, deadline
+// breaches and per-annotator load
+// @Summary Get review queue aging
+// @Description Open annotation tasks bucketed by age and priority. Breached tasks are past their deadline; at-risk tasks have less than at_risk_fraction of the time they were given left. When REVIEW_REPRIORITIZE_INTERVAL is set, at-risk tasks are raised to high priority automatically.
+// @Tags Review
+// @Produce json
+// @Param at_risk_fraction query number false "Fraction of the deadline left below which a task is at risk" default(0.25)
+// @Param anonymize_annotators query bool false "Replace annotator IDs with the project's pseudonyms"
+// @Success 200 {object} models.ReviewAgingReport
+// @Router /api/v1/annotations/aging [get]
+func (s *Server) getReviewAging(c *gin.Context) {
+	atRiskFraction, err := strconv.ParseFloat(c.DefaultQuery("at_risk_fraction", strconv.FormatFloat(s.cfg.ReviewAtRiskFraction, 'f', -1, 64)), 64)
+	if err != nil || atRiskFraction <= 0 || atRiskFraction >= 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at_risk_fraction must be between 0 and 1"})
+		return
+	}
+
+	pseudonymizer, err := s.requestAnnotatorPseudonymizer(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	tasks, err := s.projectRepo(c).ListOpenAnnotationTasks()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	report := services.ReviewAging(tasks, time.Now().UTC(), atRiskFraction)
+	if pseudonymizer != nil {
+		pseudonymizer.ReviewAging(report)
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// listOverdueAnnotationTasks reports open annotation tasks past their
+// deadline, most overdue first, with counts per annotator
+// @Summary List overdue annotation tasks
+// @Tags Review
+// @Produce json
+// @Param priority query string false "high (default), medium, low or all"
+// @Param limit query int false "Maximum tasks" default(100)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/review/tasks/overdue [get]
+func (s *Server) listOverdueAnnotationTasks(c *gin.Context) {
+	priority := c.DefaultQuery("priority", services.PriorityHigh)
+	if priority == "all" {
+		priority = ""
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	tasks, err := s.projectRepo(c).ListOverdueAnnotationTasks(priority, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	byAnnotator := make(map[string]int)
+	for _, task := range tasks {
+		byAnnotator[task.AnnotatorID]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tasks":        tasks,
+		"count":        len(tasks),
+		"by_annotator": byAnnotator,
+	})
+}
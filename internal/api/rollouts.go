@@ -0,0 +1,142 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// createEvaluatorRollout starts an A/B test of a candidate evaluator version
+// @Summary Start evaluator rollout
+// @Tags Meta-Evaluation
+// @Accept json
+// @Produce json
+// @Param rollout body models.EvaluatorRolloutCreate true "Rollout data"
+// @Success 201 {object} models.EvaluatorRollout
+// @Router /api/v1/meta-evaluation/rollouts [post]
+func (s *Server) createEvaluatorRollout(c *gin.Context) {
+	var req models.EvaluatorRolloutCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	active, err := s.repo.ListEvaluatorRollouts(models.RolloutActive)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, rollout := range active {
+		if rollout.EvaluatorType == req.EvaluatorType {
+			c.JSON(http.StatusConflict, gin.H{"error": "A rollout is already active for this evaluator type"})
+			return
+		}
+	}
+
+	rollout, err := s.repo.CreateEvaluatorRollout(&req, s.cfg.RolloutMinMargin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	s.rollouts.invalidate()
+	s.publishInvalidation(cacheScopeRollouts)
+
+	c.JSON(http.StatusCreated, rollout)
+}
+
+// listEvaluatorRollouts lists evaluator rollouts
+// @Summary List evaluator rollouts
+// @Tags Meta-Evaluation
+// @Produce json
+// @Param status query string false "Filter by status (active, promoted, rolled_back)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/meta-evaluation/rollouts [get]
+func (s *Server) listEvaluatorRollouts(c *gin.Context) {
+	rollouts, err := s.repo.ListEvaluatorRollouts(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rollouts": rollouts,
+		"count":    len(rollouts),
+	})
+}
+
+// decideEvaluatorRollouts promotes or rolls back conclusive rollouts
+// @Summary Decide evaluator rollouts
+// @Tags Meta-Evaluation
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/meta-evaluation/rollouts/decide [post]
+func (s *Server) decideEvaluatorRollouts(c *gin.Context) {
+	decided, err := s.repo.DecideEvaluatorRollouts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	s.rollouts.invalidate()
+
+	c.JSON(http.StatusOK, gin.H{
+		"decided": decided,
+		"count":   len(decided),
+	})
+}
+
+// rolloutCacheMaxAge is how long the promoted versions and active rollouts
+// new tasks are routed by are reused. Starting or deciding a rollout, here or
+// in the scheduler, publishes the rollouts scope to reload them at once; the
+// age only bounds how long a missed invalidation goes unnoticed.
+const rolloutCacheMaxAge = 30 * time.Second
+
+// rolloutCache keeps the promoted evaluator versions and active rollouts, so
+// queuing a task doesn't query them each time
+type rolloutCache struct {
+	mu       sync.Mutex
+	promoted map[string]string
+	active   []models.EvaluatorRollout
+	loadedAt time.Time
+}
+
+// invalidate makes the next task load the versions and rollouts again
+func (rc *rolloutCache) invalidate() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.loadedAt = time.Time{}
+}
+
+// evaluatorVersions picks evaluator versions for a new task, routing part of
+// the traffic to candidates of active rollouts. If they can't be loaded the
+// last known ones are used.
+func (s *Server) evaluatorVersions(evaluatorTypes []string) map[string]string {
+	rc := &s.rollouts
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if time.Since(rc.loadedAt) >= rolloutCacheMaxAge {
+		promoted, err := s.repo.GetPromotedEvaluatorVersions()
+		if err != nil {
+			log.Printf("Failed to load promoted evaluator versions: %v", err)
+		}
+		var active []models.EvaluatorRollout
+		if err == nil {
+			active, err = s.repo.ListEvaluatorRollouts(models.RolloutActive)
+			if err != nil {
+				log.Printf("Failed to load evaluator rollouts: %v", err)
+			}
+		}
+		if err == nil {
+			rc.promoted, rc.active, rc.loadedAt = promoted, active, time.Now()
+		} else if rc.loadedAt.IsZero() {
+			return nil
+		}
+	}
+
+	return services.ChooseEvaluatorVersions(evaluatorTypes, rc.promoted, rc.active)
+}
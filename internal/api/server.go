@@ -1,15 +1,23 @@
 package api
 
 import (
+	"log"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/ai-agent-eval/internal/blobstore"
 	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/database"
+	"github.com/ai-agent-eval/internal/faults"
+	"github.com/ai-agent-eval/internal/llm"
+	"github.com/ai-agent-eval/internal/models"
 	"github.com/ai-agent-eval/internal/queue"
 	"github.com/ai-agent-eval/internal/repository"
-	"github.com/ai-agent-eval/internal/services"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/ai-agent-eval/internal/telemetry"
+	"github.com/ai-agent-eval/internal/webhook"
 	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
 )
 
 // Server represents the API server
@@ -17,17 +25,89 @@ type Server struct {
 	cfg         *config.Config
 	repo        *repository.Repository
 	queue       *queue.RedisQueue
+	tools       *services.ToolRegistry
+	pipeline    *services.ConfigStore
 	evaluatorSvc *services.EvaluatorService
+	signer       *services.Signer
+	translator   *services.Translator // Nil unless localization is enabled
+	backfill     backfillTracker
+	rollouts     rolloutCache
+	faults       *faults.Injector
+	projects     sync.Map // Project IDs known to exist
+	publicCache  *responseCache
+	activity     *activityHub
+	webhooks     *webhook.Dispatcher
+	metrics      *telemetry.Exporter // Nil unless metrics are exported
+	maintenance  *queue.MaintenanceCache
+	attachments  *services.AttachmentStore
+	llm          *llm.Client // Nil without LLM credentials
 }
 
 // NewServer creates a new API server
-func NewServer(cfg *config.Config, db *sqlx.DB, redisQueue *queue.RedisQueue) *Server {
-	return &Server{
+func NewServer(cfg *config.Config, db *database.DB, redisQueue *queue.RedisQueue) *Server {
+	repo := repository.New(db)
+	tools := services.NewToolRegistry(cfg.ToolLatencySLAs, cfg.LatencyThresholdMS)
+	pipeline := services.NewConfigStore(cfg, tools)
+
+	signer, err := services.NewSigner(cfg.SigningAlgorithm, cfg.SigningKey)
+	if err != nil {
+		log.Printf("Evaluation signing disabled: %v", err)
+	}
+	repo.SetSigner(signer)
+	repo.SetVerdictPolicy(func() models.VerdictPolicy { return pipeline.Get().Verdicts })
+	repo.SetTokenPrices(services.TokenPrices{Input: cfg.ModelTokenPrices, Output: cfg.ModelOutputTokenPrices})
+
+	if err := services.ValidateMaskingProfiles(cfg.MaskingProfiles); err != nil {
+		log.Printf("Masking profiles: %v; conversations are read metadata only", err)
+	}
+
+	evaluatorSvc := services.NewEvaluatorService(cfg.EvaluatorServiceURL, tools)
+	evaluatorSvc.SetSegmentPolicy(services.SegmentPolicy{
+		MaxTurns:     cfg.SegmentMaxTurns,
+		OverlapTurns: cfg.SegmentOverlapTurns,
+		MaxTokens:    cfg.SegmentMaxTokens,
+		Tokenizer:    services.TokenizerFor(cfg.LLMModel),
+	})
+	evaluatorSvc.SetRecorder(repo, cfg.EvaluatorRecordSampleRate)
+	evaluatorSvc.SetAttachmentBaseURL(cfg.AttachmentBaseURL)
+
+	blobs, err := blobstore.New(cfg)
+	if err != nil {
+		log.Printf("Attachment storage disabled: %v", err)
+	}
+
+	s := &Server{
 		cfg:         cfg,
-		repo:        repository.New(db),
+		repo:        repo,
 		queue:       redisQueue,
-		evaluatorSvc: services.NewEvaluatorService(cfg.EvaluatorServiceURL),
+		tools:       tools,
+		pipeline:    pipeline,
+		evaluatorSvc: evaluatorSvc,
+		signer:       signer,
+		publicCache:  newResponseCache(cfg.PublicAPICacheTTL, maxPublicCacheEntries),
+		activity:     newActivityHub(),
+		webhooks:     webhook.NewDispatcher(cfg, repo),
+		maintenance:  queue.NewMaintenanceCache(redisQueue, maintenanceCheckInterval),
+		attachments:  services.NewAttachmentStore(blobs, cfg.AttachmentMaxBytes),
+	}
+
+	s.llm, err = llm.New(cfg)
+	if err != nil {
+		log.Printf("LLM client disabled: %v", err)
 	}
+
+	if cfg.LocalizationEnabled {
+		var backend services.TranslationBackend = evaluatorSvc
+		if s.llm != nil {
+			backend = services.NewLLMTranslation(s.llm)
+		}
+		s.translator = services.NewTranslator(backend, cfg.LocalizationSource, cfg.LocalizationCacheSize)
+	}
+
+	// Apply the most recently imported configuration, if any
+	s.reloadPipelineConfig()
+
+	return s
 }
 
 // Router returns the configured router
@@ -39,55 +119,217 @@ func (s *Server) Router() *gin.Engine {
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 	r.Use(corsMiddleware())
+	r.Use(s.faultMiddleware())
+	r.Use(s.maintenanceMiddleware())
 
 	// Health check
 	r.GET("/health", s.healthCheck)
+	r.GET("/metrics", s.queueMetrics)
 
-	// API v1
-	v1 := r.Group("/api/v1")
-	{
-		// Stats
-		v1.GET("/stats", s.getStats)
-
-		// Conversations
-		v1.POST("/conversations", s.createConversation)
-		v1.POST("/conversations/batch", s.batchCreateConversations)
-		v1.GET("/conversations", s.listConversations)
-		v1.GET("/conversations/:conversation_id", s.getConversation)
-
-		// Feedback
-		v1.POST("/feedback", s.addFeedback)
-
-		// Evaluations
-		v1.POST("/evaluations/trigger", s.triggerEvaluation)
-		v1.GET("/evaluations", s.listEvaluations)
-		v1.GET("/evaluations/:evaluation_id", s.getEvaluation)
-
-		// Annotations
-		v1.POST("/annotations", s.createAnnotation)
-		v1.GET("/annotations/agreement/:conversation_id", s.getAnnotatorAgreement)
-		v1.GET("/annotations/routing/:conversation_id", s.getRoutingDecision)
-
-		// Improvements
-		v1.POST("/improvements/analyze", s.analyzeAndGenerateSuggestions)
-		v1.GET("/improvements/suggestions", s.getSuggestions)
-		v1.POST("/improvements/suggestions/:suggestion_id/implement", s.markSuggestionImplemented)
-		v1.GET("/improvements/patterns", s.getFailurePatterns)
-
-		// Meta-Evaluation
-		v1.POST("/meta-evaluation/calibrate", s.calibrateEvaluators)
-		v1.GET("/meta-evaluation/performance", s.getEvaluatorPerformance)
-	}
+	// Versioned API
+	s.registerVersions(r)
 
 	return r
 }
 
+// registerV1Routes registers the v1 API
+func (s *Server) registerV1Routes(v1 *gin.RouterGroup) {
+	// Stats
+	v1.GET("/stats", s.getStats)
+	v1.GET("/ws", s.streamActivity)
+
+	// Analytics
+	v1.GET("/analytics/tool-latency", s.getToolLatencyStats)
+	v1.GET("/analytics/throughput", s.getThroughput)
+	v1.GET("/analytics/timeseries", s.getTimeSeries)
+	v1.GET("/analytics/slice", s.getSlice)
+	v1.GET("/analytics/health-leaderboard", s.getHealthLeaderboard)
+	v1.GET("/analytics/anomalies", s.getAnomalies)
+	v1.GET("/analytics/pipeline-latency", s.getPipelineLatency)
+	v1.GET("/analytics/feedback-themes", s.getFeedbackThemes)
+	v1.GET("/analytics/intents", s.getIntentQuality)
+	v1.GET("/analytics/journeys", s.getJourneys)
+	v1.GET("/analytics/version-matrix", s.getVersionMatrix)
+	v1.GET("/analytics/compare", s.compareVersions)
+	v1.GET("/analytics/token-cost", s.getTokenCost)
+	v1.GET("/analytics/costs", s.getCosts)
+
+	// Conversations
+	v1.POST("/conversations", s.createConversation)
+	v1.POST("/conversations/batch", s.batchCreateConversations)
+	v1.POST("/conversations/upload", s.uploadConversations)
+	v1.POST("/conversations/import", s.importConversations)
+	v1.POST("/otlp/v1/traces", s.ingestTraces)
+	v1.GET("/conversations/imports/:import_id", s.getConversationImport)
+	v1.GET("/conversations/imports/:import_id/errors", s.getConversationImportErrors)
+	v1.GET("/conversations", s.listConversations)
+	v1.GET("/conversations/:conversation_id", s.getConversation)
+	v1.GET("/conversations/:conversation_id/evaluations", s.getConversationEvaluations)
+	v1.GET("/attachments/:sha256", s.getAttachment)
+	v1.GET("/conversations/:conversation_id/view", s.getConversationView)
+	v1.GET("/conversations/:conversation_id/view/payloads/:token", s.expandConversationViewPayload)
+
+	// Comments
+	v1.POST("/conversations/:conversation_id/comments", s.createComment)
+	v1.GET("/conversations/:conversation_id/comments", s.listComments)
+	v1.GET("/comments/mentions", s.listCommentMentions)
+	v1.PUT("/comments/:comment_id", s.updateComment)
+	v1.DELETE("/comments/:comment_id", s.deleteComment)
+	v1.PUT("/comments/:comment_id/resolution", s.resolveComment)
+
+	// Feedback
+	v1.POST("/feedback", s.addFeedback)
+
+	// Evaluations
+	v1.POST("/evaluations/trigger", s.triggerEvaluation)
+	v1.GET("/evaluations", s.listEvaluations)
+	v1.GET("/evaluations/export", s.exportEvaluations)
+	v1.GET("/evaluations/search", s.searchEvaluations)
+	v1.GET("/evaluations/:evaluation_id", s.getEvaluation)
+	v1.GET("/evaluations/:evaluation_id/manifest", s.getEvaluationManifest)
+	v1.GET("/evaluations/:evaluation_id/verify", s.verifyEvaluation)
+	v1.POST("/evaluations/:evaluation_id/complete", s.completeEvaluation)
+	v1.POST("/debug/evaluate", s.streamDebugEvaluation)
+	v1.GET("/tasks/:task_id", s.getTask)
+	v1.POST("/pipeline/tasks/:task_id/stages", s.recordPipelineStage)
+
+	// Saved views
+	v1.POST("/views", s.createSavedView)
+	v1.GET("/views", s.listSavedViews)
+	v1.GET("/views/:name", s.getSavedView)
+	v1.PUT("/views/:name", s.updateSavedView)
+	v1.DELETE("/views/:name", s.deleteSavedView)
+	v1.GET("/views/:name/results", s.runSavedView)
+
+	// Datasets and regression runs
+	v1.POST("/datasets", s.createDataset)
+	v1.GET("/datasets", s.listDatasets)
+	v1.GET("/datasets/:dataset_id", s.getDataset)
+	v1.DELETE("/datasets/:dataset_id", s.deleteDataset)
+	v1.PUT("/datasets/:dataset_id/items", s.replaceDatasetItems)
+	v1.POST("/datasets/:dataset_id/runs", s.createDatasetRun)
+	v1.GET("/datasets/:dataset_id/runs", s.listDatasetRuns)
+	v1.GET("/datasets/:dataset_id/runs/compare", s.compareDatasetRuns)
+	v1.GET("/datasets/:dataset_id/runs/:run_id", s.getDatasetRun)
+
+	// Annotations
+	v1.POST("/annotations", s.createAnnotation)
+	v1.GET("/annotations/agreement/:conversation_id", s.getAnnotatorAgreement)
+	v1.GET("/annotations/routing/:conversation_id", s.getRoutingDecision)
+	v1.GET("/annotations/annotators", s.listAnnotatorPerformance)
+	v1.POST("/annotations/annotators/specializations", s.inferAnnotatorSpecializations)
+	v1.GET("/annotations/reliability-trend", s.getReliabilityTrend)
+	v1.GET("/annotations/examples/:conversation_id", s.getReferenceExamples)
+	v1.GET("/annotations/aging", s.getReviewAging)
+	v1.GET("/annotations/form/:conversation_id", s.getAnnotationForm)
+	v1.GET("/annotations/assisted-labeling", s.getAssistedLabelingReport)
+
+	// Ownership
+	v1.GET("/owners", s.listOwners)
+	v1.GET("/owners/:owner/issues", s.getOwnerIssues)
+	v1.GET("/owners/:owner/patterns", s.getOwnerPatterns)
+
+	// Export
+	v1.GET("/export/annotations", s.exportAnnotations)
+	v1.GET("/export/evaluations.parquet", s.exportEvaluationsParquet)
+	v1.GET("/export/evaluations.arrow", s.exportEvaluationsArrow)
+
+	// Integrations
+	v1.POST("/integrations/labelstudio/webhook", s.labelStudioWebhook)
+
+	// Review console bulk actions
+	v1.POST("/review/bulk/routing-approvals", s.bulkApproveRouting)
+	v1.POST("/review/bulk/issue-dismissals", s.bulkDismissIssues)
+	v1.POST("/review/bulk/annotation-assignments", s.bulkAssignAnnotationTasks)
+	v1.GET("/review/tasks", s.listAnnotationTasks)
+	v1.GET("/review/tasks/overdue", s.listOverdueAnnotationTasks)
+
+	// Improvements
+	v1.POST("/improvements/analyze", s.analyzeAndGenerateSuggestions)
+	v1.GET("/improvements/suggestions", s.getSuggestions)
+	v1.POST("/improvements/suggestions/:suggestion_id/implement", s.markSuggestionImplemented)
+	v1.GET("/improvements/patterns", s.getFailurePatterns)
+	v1.GET("/improvements/patterns/:pattern_id/similar", s.getSimilarPatterns)
+	v1.POST("/improvements/patterns/:pattern_id/resolve", s.resolveFailurePattern)
+
+	// Meta-Evaluation
+	v1.POST("/meta-evaluation/calibrate", s.calibrateEvaluators)
+	v1.GET("/meta-evaluation/performance", s.getEvaluatorPerformance)
+	v1.POST("/meta-evaluation/rollouts", s.createEvaluatorRollout)
+	v1.GET("/meta-evaluation/rollouts", s.listEvaluatorRollouts)
+	v1.POST("/meta-evaluation/rollouts/decide", s.decideEvaluatorRollouts)
+
+	// Projects
+	v1.POST("/projects", s.createProject)
+	v1.GET("/projects", s.listProjects)
+	v1.PUT("/projects/:project_id/assisted-labeling", s.setAssistedLabeling)
+	v1.PUT("/projects/:project_id/public", s.setProjectPublic)
+
+	// Project webhooks
+	v1.POST("/projects/:project_id/webhooks", s.createProjectWebhook)
+	v1.GET("/projects/:project_id/webhooks", s.listProjectWebhooks)
+	v1.PUT("/projects/:project_id/webhooks/:webhook_id", s.updateProjectWebhook)
+	v1.DELETE("/projects/:project_id/webhooks/:webhook_id", s.deleteProjectWebhook)
+	v1.GET("/projects/:project_id/webhooks/:webhook_id/preview", s.previewProjectWebhook)
+	v1.GET("/projects/:project_id/webhooks/:webhook_id/deliveries", s.listWebhookDeliveries)
+
+	// Notification preferences
+	v1.GET("/projects/:project_id/notifications", s.listNotificationPreferences)
+	v1.GET("/projects/:project_id/notifications/:team", s.getNotificationPreference)
+	v1.PUT("/projects/:project_id/notifications/:team", s.setNotificationPreference)
+	v1.DELETE("/projects/:project_id/notifications/:team", s.deleteNotificationPreference)
+
+	// Admin
+	v1.GET("/admin/config/export", s.exportConfig)
+	v1.POST("/admin/config/export", s.importConfig)
+	v1.GET("/admin/config/health", s.getHealthFormula)
+	v1.GET("/admin/routing/severity-priorities", s.getSeverityPriorities)
+	v1.PUT("/admin/routing/severity-priorities", s.setSeverityPriorities)
+	v1.GET("/admin/tools/goldens", s.getToolGoldens)
+	v1.PUT("/admin/tools/goldens", s.setToolGoldens)
+	v1.GET("/admin/verdicts/policy", s.getVerdictPolicy)
+	v1.PUT("/admin/verdicts/policy", s.setVerdictPolicy)
+	v1.POST("/admin/backfill/evaluations", s.backfillEvaluations)
+	v1.GET("/admin/backfill/evaluations", s.getBackfillStatus)
+	v1.GET("/admin/reevaluation/baselines", s.listReevaluationBaselines)
+	v1.GET("/admin/queues", s.listQueues)
+	v1.GET("/admin/queues/:queue/dead-letters", s.listDeadLetters)
+	v1.POST("/admin/queues/:queue/dead-letters/:task_id/requeue", s.requeueDeadLetter)
+	v1.GET("/admin/tasks/:task_id/journal", s.getTaskJournal)
+	v1.GET("/admin/storage", s.getStorageReport)
+	v1.GET("/admin/maintenance", s.getMaintenance)
+	v1.PUT("/admin/maintenance", s.startMaintenance)
+	v1.DELETE("/admin/maintenance", s.endMaintenance)
+	v1.POST("/admin/conversations/hard-delete", s.hardDeleteConversations)
+	v1.POST("/admin/conversations/reassign-version", s.reassignAgentVersion)
+	v1.POST("/admin/annotators/deanonymize", s.deanonymizeAnnotators)
+	v1.POST("/admin/service-accounts", s.createServiceAccount)
+	v1.GET("/admin/service-accounts", s.listServiceAccounts)
+	v1.DELETE("/admin/service-accounts/:name", s.revokeServiceAccount)
+	v1.POST("/admin/snapshots", s.createSnapshot)
+	v1.POST("/admin/snapshots/restore", s.restoreSnapshot)
+	v1.GET("/admin/llm/usage", s.getLLMUsage)
+	v1.GET("/admin/faults", s.listFaults)
+	v1.PUT("/admin/faults/:target", s.setFault)
+	v1.DELETE("/admin/faults/:target", s.clearFault)
+	v1.DELETE("/admin/faults", s.clearFaults)
+
+	// API keys
+	v1.POST("/apikeys", s.createAPIKey)
+	v1.GET("/apikeys", s.listAPIKeys)
+	v1.DELETE("/apikeys/:id", s.revokeAPIKey)
+
+	// Internal, for service accounts
+	v1.POST("/internal/evaluations", s.requireScope(services.ScopeEvaluationsWrite), s.receiveEvaluation)
+}
+
 // corsMiddleware handles CORS
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+apiKeyHeader+", "+apiVersionHeader+", "+projectHeader)
+		c.Writer.Header().Set("Access-Control-Expose-Headers", apiVersionHeader+", Deprecation, Sunset, Link")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 
 		if c.Request.Method == "OPTIONS" {
@@ -101,15 +343,18 @@ func corsMiddleware() gin.HandlerFunc {
 
 // healthCheck returns health status
 // @Summary Health check
-// @Description Check if the API is healthy
+// @Description Check if the API is healthy, and whether it is read-only for maintenance
 // @Tags Health
 // @Produce json
 // @Success 200 {object} map[string]interface{}
 // @Router /health [get]
 func (s *Server) healthCheck(c *gin.Context) {
+	m := s.maintenance.Get()
 	c.JSON(http.StatusOK, gin.H{
-		"status":    "healthy",
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
-		"version":   "1.0.0",
+		"status":      "healthy",
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+		"version":     "1.0.0",
+		"read_only":   m != nil,
+		"maintenance": m,
 	})
 }
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// serviceAccountKey is the context key of the authenticated service account
+const serviceAccountKey = "service_account"
+
+// requireScope authenticates a service account from its bearer token and
+// rejects requests whose account lacks the scope
+func (s *Server) requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing service account token"})
+			return
+		}
+
+		account, err := s.repo.GetServiceAccountByToken(services.HashServiceAccountToken(token))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if account == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid service account token"})
+			return
+		}
+
+		var scopes []string
+		json.Unmarshal(account.Scopes, &scopes)
+		granted := false
+		for _, have := range scopes {
+			granted = granted || have == scope
+		}
+		if !granted {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Service account lacks scope " + scope})
+			return
+		}
+
+		c.Set(serviceAccountKey, account.Name)
+		c.Next()
+	}
+}
+
+// createServiceAccount creates a service account and returns its token
+// @Summary Create service account
+// @Description The token is only returned in this response; store it securely.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param account body models.ServiceAccountCreate true "Service account"
+// @Success 201 {object} models.ServiceAccountToken
+// @Router /api/v1/admin/service-accounts [post]
+func (s *Server) createServiceAccount(c *gin.Context) {
+	var req models.ServiceAccountCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateServiceAccountScopes(req.Scopes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, hash, err := services.NewServiceAccountToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	account, err := s.repo.CreateServiceAccount(req.Name, req.Scopes, hash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if account == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Service account already exists"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.ServiceAccountToken{ServiceAccount: *account, Token: token})
+}
+
+// listServiceAccounts lists service accounts
+// @Summary List service accounts
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/service-accounts [get]
+func (s *Server) listServiceAccounts(c *gin.Context) {
+	accounts, err := s.repo.ListServiceAccounts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"service_accounts": accounts,
+		"count":            len(accounts),
+	})
+}
+
+// revokeServiceAccount revokes a service account's token
+// @Summary Revoke service account
+// @Tags Admin
+// @Param name path string true "Service account name"
+// @Success 204
+// @Router /api/v1/admin/service-accounts/{name} [delete]
+func (s *Server) revokeServiceAccount(c *gin.Context) {
+	revoked, err := s.repo.RevokeServiceAccount(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// receiveEvaluation stores an evaluation result pushed by the evaluator
+// service, so it can evaluate queued tasks at its own pace instead of within
+// a request. Pushing the same result again returns the stored evaluation.
+// @Summary Receive evaluation result
+// @Description Requires "Authorization: Bearer <token>" of a service account with the evaluations:write scope.
+// @Tags Internal
+// @Accept json
+// @Produce json
+// @Param callback body services.EvaluationCallback true "Evaluation result"
+// @Success 201 {object} models.EvaluationResponse
+// @Success 200 {object} models.EvaluationResponse
+// @Router /api/v1/internal/evaluations [post]
+func (s *Server) receiveEvaluation(c *gin.Context) {
+	var req services.EvaluationCallback
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.CompletesEvaluationID != "" {
+		if len(req.RetriedEvaluators) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "retried_evaluators is required to complete an evaluation"})
+			return
+		}
+		eval, err := s.repo.CompleteEvaluation(req.CompletesEvaluationID, req.RetriedEvaluators, &req.Result)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if eval == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Evaluation not found"})
+			return
+		}
+		s.markCallbackTaskCompleted(&req, queue.TaskCompleteEvaluation, eval)
+		s.publishEvaluation(eval)
+		s.dispatchWebhooks(eval)
+		c.JSON(http.StatusOK, models.NewEvaluationResponse(eval))
+		return
+	}
+
+	if req.Result.EvaluationID == "" || req.Result.ConversationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "result.evaluation_id and result.conversation_id are required"})
+		return
+	}
+
+	existing, err := s.repo.GetEvaluation(req.Result.EvaluationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusOK, models.NewEvaluationResponse(existing))
+		return
+	}
+
+	conv, err := s.repo.GetConversation(req.Result.ConversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if conv == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	eval, err := services.NewEvaluation(&req.Result, req.TaskID, req.TriggerSource)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	eval.Manifest, err = services.NewEvaluationManifest(services.ManifestSource{Result: &req.Result})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.TaskID == "" {
+		err = s.repo.CreateEvaluation(eval)
+	} else {
+		// Results pushed for a task are stored once, however often it ran
+		var evaluationID string
+		evaluationID, err = s.repo.PersistTaskEvaluation(req.TaskID, queue.QueueEvaluations, eval)
+		if err == nil && evaluationID != eval.EvaluationID {
+			existing, err := s.repo.GetEvaluation(evaluationID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if existing == nil {
+				c.JSON(http.StatusConflict, gin.H{"error": "Task already completed"})
+				return
+			}
+			c.JSON(http.StatusOK, models.NewEvaluationResponse(existing))
+			return
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	s.markCallbackTaskCompleted(&req, queue.TaskEvaluate, eval)
+	s.publishActivity(services.EvaluationActivity(eval, conv.AgentVersion))
+	s.dispatchWebhooks(eval)
+
+	c.JSON(http.StatusCreated, models.NewEvaluationResponse(eval))
+}
+
+// markCallbackTaskCompleted records that the task a pushed result belongs
+// to, if any, is completed. Status updates are best effort.
+func (s *Server) markCallbackTaskCompleted(req *services.EvaluationCallback, taskType string, eval *models.Evaluation) {
+	if req.TaskID == "" {
+		return
+	}
+	task := &queue.Task{
+		ID:             req.TaskID,
+		Type:           taskType,
+		ConversationID: eval.ConversationID,
+		TriggerSource:  req.TriggerSource,
+	}
+	if err := s.queue.MarkTaskCompleted(task, eval.EvaluationID); err != nil {
+		log.Printf("Failed to update status of task %s: %v", req.TaskID, err)
+	}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// snapshotVersion is the format version of snapshot archives
+const snapshotVersion = "1"
+
+// maxSnapshotConversations caps how many conversations one snapshot may hold
+const maxSnapshotConversations = 10000
+
+// maxSnapshotInflation bounds the decompressed size of a restored snapshot
+// as a multiple of the upload limit
+const maxSnapshotInflation = 20
+
+// createSnapshot packages the pipeline configuration and a filtered subset of
+// conversations, with their evaluations and annotations, into a gzipped JSON
+// archive
+// @Summary Create snapshot
+// @Tags Admin
+// @Accept json
+// @Produce application/gzip
+// @Param filter body models.SnapshotFilter true "Conversations to include"
+// @Success 200 {file} file
+// @Router /api/v1/admin/snapshots [post]
+func (s *Server) createSnapshot(c *gin.Context) {
+	var filter models.SnapshotFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conversations, evaluations, annotations, err := s.repo.GetSnapshotData(&filter, maxSnapshotConversations)
+	if errors.Is(err, repository.ErrTooManyConversations) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("more than %d conversations match; narrow the filter", maxSnapshotConversations),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	snapshot := models.Snapshot{
+		Version:       snapshotVersion,
+		CreatedAt:     time.Now().UTC(),
+		Filter:        filter,
+		Config:        s.pipeline.Get(),
+		Conversations: conversations,
+		Evaluations:   evaluations,
+		Annotations:   annotations,
+	}
+
+	filename := fmt.Sprintf("snapshot-%s.json.gz", snapshot.CreatedAt.Format("20060102-150405"))
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	gz := gzip.NewWriter(c.Writer)
+	if err := json.NewEncoder(gz).Encode(snapshot); err != nil {
+		// Headers are already sent, so the truncated archive is the only signal
+		log.Printf("Failed to write snapshot: %v", err)
+		return
+	}
+	if err := gz.Close(); err != nil {
+		log.Printf("Failed to write snapshot: %v", err)
+	}
+}
+
+// restoreSnapshot loads a snapshot archive. Conversations that already
+// exist are skipped with their evaluations and annotations, so a snapshot
+// can be restored again safely.
+// @Summary Restore snapshot
+// @Description Accepts the gzipped archive from POST /admin/snapshots, or its uncompressed JSON. The pipeline configuration is only applied with apply_config=true.
+// @Tags Admin
+// @Accept application/gzip
+// @Accept json
+// @Produce json
+// @Param apply_config query bool false "Also apply the snapshot's pipeline configuration" default(false)
+// @Success 200 {object} models.SnapshotRestoreResult
+// @Router /api/v1/admin/snapshots/restore [post]
+func (s *Server) restoreSnapshot(c *gin.Context) {
+	applyConfig, _ := strconv.ParseBool(c.DefaultQuery("apply_config", "false"))
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, s.cfg.UploadMaxBytes)
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		s.uploadError(c, err)
+		return
+	}
+
+	var reader io.Reader = bytes.NewReader(body)
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer gz.Close()
+		reader = io.LimitReader(gz, s.cfg.UploadMaxBytes*maxSnapshotInflation)
+	}
+
+	var snapshot models.Snapshot
+	if err := json.NewDecoder(reader).Decode(&snapshot); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid snapshot: " + err.Error()})
+		return
+	}
+	if snapshot.Version != snapshotVersion {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported snapshot version"})
+		return
+	}
+
+	result, err := s.repo.RestoreSnapshot(&snapshot)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if applyConfig {
+		bundle := models.ConfigBundle{
+			Version:    configBundleVersion,
+			ExportedAt: snapshot.CreatedAt,
+			Config:     snapshot.Config,
+		}
+		if err := s.repo.SaveConfigBundle(&bundle); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		s.pipeline.Set(bundle.Config)
+		s.publishInvalidation(cacheScopeConfig)
+		result.ConfigApplied = true
+	}
+
+	log.Printf("Restored snapshot created %s: %d conversations (%d skipped), %d evaluations, %d annotations",
+		snapshot.CreatedAt.Format(time.RFC3339), result.Conversations, result.SkippedConversations,
+		result.Evaluations, result.Annotations)
+
+	c.JSON(http.StatusOK, result)
+}
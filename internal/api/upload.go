@@ -0,0 +1,312 @@
+package api
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+	"github.com/ai-agent-eval/internal/repository"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+// importProgressInterval is how many records are processed between progress updates
+const importProgressInterval = 100
+
+// uploadConversations accepts a conversation file and imports it in the background
+// @Summary Upload a conversation file
+// @Description Accepts a JSONL file of conversations in the ingestion API format, or a CSV file with one turn per row. The file is imported in the background; poll the returned import for progress.
+// @Tags Ingestion
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "JSONL or CSV file"
+// @Param format query string false "File format (jsonl or csv); inferred from the file name by default"
+// @Param auto_evaluate query bool false "Auto trigger evaluation" default(true)
+// @Success 202 {object} models.ConversationImport
+// @Failure 413 {object} map[string]interface{}
+// @Router /api/v1/conversations/upload [post]
+func (s *Server) uploadConversations(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, s.cfg.UploadMaxBytes)
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expected a multipart/form-data upload"})
+		return
+	}
+
+	// Stream the file part to disk without buffering it in memory
+	var tmp *os.File
+	var filename string
+	var size int64
+	for tmp == nil {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing file field"})
+			return
+		}
+		if err != nil {
+			s.uploadError(c, err)
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		filename = part.FileName()
+		tmp, err = os.CreateTemp("", "conversation-import-*")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		size, err = io.Copy(tmp, part)
+		part.Close()
+		if err == nil {
+			err = tmp.Close()
+		}
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			s.uploadError(c, err)
+			return
+		}
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = services.UploadFormat(filename)
+	}
+	if format != models.ImportFormatJSONL && format != models.ImportFormatCSV {
+		os.Remove(tmp.Name())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be jsonl or csv"})
+		return
+	}
+
+	imp, err := s.projectRepo(c).CreateConversationImport(&models.ConversationImport{
+		ImportID:     uuid.New().String(),
+		Filename:     filename,
+		Format:       format,
+		SizeBytes:    size,
+		AutoEvaluate: c.DefaultQuery("auto_evaluate", "true") == "true",
+		Status:       models.ImportRunning,
+	})
+	if err != nil {
+		os.Remove(tmp.Name())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	go s.runImport(s.projectRepo(c), *imp, tmp.Name())
+
+	c.JSON(http.StatusAccepted, imp)
+}
+
+// uploadError reports a failure to read an upload
+func (s *Server) uploadError(c *gin.Context, err error) {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("upload exceeds the limit of %d bytes", s.cfg.UploadMaxBytes),
+		})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// runImport ingests the conversations of an uploaded file into repo's project
+// and removes the file
+func (s *Server) runImport(repo *repository.Repository, imp models.ConversationImport, path string) {
+	defer os.Remove(path)
+
+	processed, imported, failed := 0, 0, 0
+	err := func() error {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		return services.ParseUpload(file, imp.Format, func(record services.UploadRecord) error {
+			processed++
+			if err := s.importRecord(repo, record, imp.AutoEvaluate); err != nil {
+				failed++
+				importErr := &models.ConversationImportError{
+					ImportID:       imp.ImportID,
+					Line:           record.Line,
+					ConversationID: record.ConversationID,
+					Error:          err.Error(),
+				}
+				if err := s.repo.AddConversationImportError(importErr); err != nil {
+					return err
+				}
+			} else {
+				imported++
+			}
+
+			if processed%importProgressInterval == 0 {
+				return s.repo.UpdateConversationImportProgress(imp.ImportID, processed, imported, failed)
+			}
+			return nil
+		})
+	}()
+
+	status, errMsg := models.ImportCompleted, ""
+	if err != nil {
+		log.Printf("Conversation import %s failed: %v", imp.ImportID, err)
+		status, errMsg = models.ImportFailed, err.Error()
+	}
+	if err := s.repo.FinishConversationImport(imp.ImportID, status, processed, imported, failed, errMsg); err != nil {
+		log.Printf("Failed to finish conversation import %s: %v", imp.ImportID, err)
+	}
+}
+
+// importRecord validates and ingests one uploaded conversation
+func (s *Server) importRecord(repo *repository.Repository, record services.UploadRecord, autoEvaluate bool) error {
+	if record.Err != nil {
+		return record.Err
+	}
+	if err := binding.Validator.ValidateStruct(record.Conversation); err != nil {
+		return err
+	}
+	if err := services.ValidateTurns(record.Conversation.Turns); err != nil {
+		return err
+	}
+	if err := s.attachments.Validate(record.Conversation.Turns); err != nil {
+		return err
+	}
+
+	_, _, err := s.ingestConversation(repo, record.Conversation, autoEvaluate, queue.TriggerUpload)
+	return err
+}
+
+// importConversations ingests conversations streamed one per line in the
+// request body
+// @Summary Stream a conversation import
+// @Description Reads one conversation per line in the ingestion API format, validating and ingesting each as it arrives so the import is never held in memory. Send Content-Encoding: gzip for a compressed body. If the body can't be read to the end, the lines before the failure stay ingested and the response reports them with the error.
+// @Tags Ingestion
+// @Accept application/x-ndjson
+// @Produce json
+// @Param auto_evaluate query bool false "Auto trigger evaluation" default(true)
+// @Success 200 {object} models.StreamImportResponse
+// @Failure 400 {object} models.StreamImportResponse
+// @Failure 413 {object} models.StreamImportResponse
+// @Router /api/v1/conversations/import [post]
+func (s *Server) importConversations(c *gin.Context) {
+	body := io.Reader(http.MaxBytesReader(c.Writer, c.Request.Body, s.cfg.UploadMaxBytes))
+	if strings.EqualFold(c.GetHeader("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			s.uploadError(c, err)
+			return
+		}
+		defer gz.Close()
+		// The limit applies to the decompressed body as well
+		body = http.MaxBytesReader(c.Writer, gz, s.cfg.UploadMaxBytes)
+	}
+
+	autoEvaluate := c.DefaultQuery("auto_evaluate", "true") == "true"
+	repo := s.projectRepo(c)
+	resp := models.StreamImportResponse{Results: []models.ImportLineResult{}}
+
+	err := services.ParseUpload(body, models.ImportFormatJSONL, func(record services.UploadRecord) error {
+		result := models.ImportLineResult{
+			Line:           record.Line,
+			ConversationID: record.ConversationID,
+			Status:         models.ImportLineImported,
+		}
+		if err := s.importRecord(repo, record, autoEvaluate); err != nil {
+			result.Status, result.Error = models.ImportLineFailed, err.Error()
+			resp.Failed++
+		} else {
+			resp.Imported++
+		}
+		resp.Processed++
+		resp.Results = append(resp.Results, result)
+		return nil
+	})
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			resp.Error = fmt.Sprintf("import exceeds the limit of %d bytes", s.cfg.UploadMaxBytes)
+			c.JSON(http.StatusRequestEntityTooLarge, resp)
+			return
+		}
+		resp.Error = err.Error()
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// getConversationImport reports the progress of a conversation file import
+// @Summary Get conversation import progress
+// @Tags Ingestion
+// @Produce json
+// @Param import_id path string true "Import ID"
+// @Success 200 {object} models.ConversationImport
+// @Router /api/v1/conversations/imports/{import_id} [get]
+func (s *Server) getConversationImport(c *gin.Context) {
+	imp, err := s.projectRepo(c).GetConversationImport(c.Param("import_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if imp == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Import not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, imp)
+}
+
+// getConversationImportErrors downloads the records of an import that failed
+// @Summary Download conversation import error report
+// @Tags Ingestion
+// @Produce text/csv
+// @Param import_id path string true "Import ID"
+// @Success 200 {file} file
+// @Router /api/v1/conversations/imports/{import_id}/errors [get]
+func (s *Server) getConversationImportErrors(c *gin.Context) {
+	importID := c.Param("import_id")
+	imp, err := s.projectRepo(c).GetConversationImport(importID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if imp == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Import not found"})
+		return
+	}
+
+	importErrors, err := s.repo.ListConversationImportErrors(importID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+importID+`-errors.csv"`)
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"line", "conversation_id", "error"})
+	for _, e := range importErrors {
+		w.Write([]string{strconv.Itoa(e.Line), e.ConversationID, e.Error})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("Failed to write error report for import %s: %v", importID, err)
+	}
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiVersionHeader lets clients pick a version for unversioned /api paths and
+// reports the version that served a request
+const apiVersionHeader = "API-Version"
+
+// defaultAPIVersion serves unversioned requests that don't ask for a version
+const defaultAPIVersion = "v1"
+
+// apiVersion is a set of routes registered under /api/<name>. A version only
+// registers the routes it changes; anything else falls back to the closest
+// earlier version.
+type apiVersion struct {
+	name     string
+	register func(s *Server, g *gin.RouterGroup)
+}
+
+// apiVersions lists the API versions, oldest first
+var apiVersions = []apiVersion{
+	{name: "v1", register: (*Server).registerV1Routes},
+	{name: "v2", register: (*Server).registerV2Routes},
+}
+
+// deprecation describes a route slated for removal or change
+type deprecation struct {
+	since     time.Time
+	sunset    time.Time
+	successor string
+}
+
+// deprecatedRoutes maps "METHOD /full/route" to its deprecation
+var deprecatedRoutes = map[string]deprecation{
+	"GET /api/v1/evaluations": {
+		since:     time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+		sunset:    time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC),
+		successor: "/api/v2/evaluations",
+	},
+}
+
+// registerV2Routes registers the routes whose v2 response format differs from v1
+func (s *Server) registerV2Routes(v2 *gin.RouterGroup) {
+	// Evaluations
+	v2.GET("/evaluations", s.listEvaluationsV2)
+}
+
+// registerVersions mounts every API version and the fallback that negotiates
+// unversioned requests and routes missing ones to earlier versions
+func (s *Server) registerVersions(r *gin.Engine) {
+	for _, version := range apiVersions {
+		group := r.Group("/api/"+version.name, versionMiddleware(version.name), s.jwtMiddleware(), s.apiKeyMiddleware(), s.projectMiddleware())
+		version.register(s, group)
+	}
+
+	r.NoRoute(func(c *gin.Context) {
+		if path, ok := fallbackPath(c); ok {
+			c.Request.URL.Path = path
+			r.HandleContext(c)
+			// HandleContext swaps in the fallback route's handlers; stop the
+			// original chain from resuming into them
+			c.Abort()
+			return
+		}
+		if c.IsAborted() {
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+	})
+}
+
+// fallbackPath returns where an unmatched /api request should be served from.
+// Unversioned paths go to the negotiated version and versioned paths to the
+// previous version. The requested version is recorded in the API-Version
+// request header so the serving version can report it.
+func fallbackPath(c *gin.Context) (string, bool) {
+	rest, ok := strings.CutPrefix(c.Request.URL.Path, "/api/")
+	if !ok {
+		return "", false
+	}
+
+	name, route, _ := strings.Cut(rest, "/")
+	index := versionIndex(name)
+	if index < 0 {
+		// Unversioned path: negotiate from the request header
+		requested := c.GetHeader(apiVersionHeader)
+		if requested == "" {
+			requested = defaultAPIVersion
+		}
+		if versionIndex(requested) < 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":              "Unsupported API version",
+				"supported_versions": supportedVersions(),
+			})
+			return "", false
+		}
+		c.Request.Header.Set(apiVersionHeader, requested)
+		return "/api/" + requested + "/" + rest, true
+	}
+
+	if index == 0 {
+		return "", false
+	}
+	if versionIndex(c.GetHeader(apiVersionHeader)) < index {
+		c.Request.Header.Set(apiVersionHeader, name)
+	}
+	return "/api/" + apiVersions[index-1].name + "/" + route, true
+}
+
+// versionMiddleware reports the requested API version and adds deprecation
+// headers to routes being phased out of it
+func versionMiddleware(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// A newer requested version means this request fell back from it
+		requested := c.GetHeader(apiVersionHeader)
+		if versionIndex(requested) <= versionIndex(version) {
+			requested = version
+		}
+		c.Header(apiVersionHeader, requested)
+
+		// Routes reached by falling back aren't deprecated in the newer version
+		if requested == version {
+			if dep, ok := deprecatedRoutes[c.Request.Method+" "+c.FullPath()]; ok {
+				c.Header("Deprecation", "@"+strconv.FormatInt(dep.since.Unix(), 10))
+				c.Header("Sunset", dep.sunset.Format(http.TimeFormat))
+				if dep.successor != "" {
+					c.Header("Link", "<"+dep.successor+">; rel=\"successor-version\"")
+				}
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// versionIndex returns the position of a version in apiVersions, or -1
+func versionIndex(name string) int {
+	for i, version := range apiVersions {
+		if version.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// supportedVersions lists the names of all API versions
+func supportedVersions() []string {
+	names := make([]string, 0, len(apiVersions))
+	for _, version := range apiVersions {
+		names = append(names, version.name)
+	}
+	return names
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maxViewCollapseBytes caps the collapse_bytes a viewer may ask for
+const maxViewCollapseBytes = 1 << 16
+
+// getConversationView returns a conversation prepared for display
+// @Summary Get a conversation prepared for display
+// @Description Returns turns with markdown-safe content, pretty-printed tool parameters and results, and the issues of an evaluation marked on the turns they refer to. Payloads longer than collapse_bytes are cut to a preview with an expansion token. Turns are masked by the caller's masking profile, reported in the X-Masking-Profile header.
+// @Tags Query
+// @Produce json
+// @Param conversation_id path string true "Conversation ID"
+// @Param evaluation_id query string false "Evaluation whose issues are marked (defaults to the latest)"
+// @Param collapse_bytes query int false "Payload length past which payloads are collapsed (defaults to 2048, 0 never collapses)"
+// @Success 200 {object} models.ConversationView
+// @Router /api/v1/conversations/{conversation_id}/view [get]
+func (s *Server) getConversationView(c *gin.Context) {
+	conversationID := c.Param("conversation_id")
+
+	collapseBytes := services.DefaultViewCollapseBytes
+	if raw := c.Query("collapse_bytes"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > maxViewCollapseBytes {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "collapse_bytes must be between 0 and " + strconv.Itoa(maxViewCollapseBytes)})
+			return
+		}
+		collapseBytes = n
+	}
+
+	conv, turns, ok := s.viewedConversation(c, conversationID)
+	if !ok {
+		return
+	}
+
+	var eval *models.Evaluation
+	var err error
+	if evaluationID := c.Query("evaluation_id"); evaluationID != "" {
+		eval, err = s.projectRepo(c).GetEvaluation(evaluationID)
+		if err == nil && (eval == nil || eval.ConversationID != conversationID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Evaluation not found"})
+			return
+		}
+	} else {
+		eval, err = s.projectRepo(c).GetLatestEvaluationForConversation(conversationID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := services.BuildConversationView(conv, turns, eval, collapseBytes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// expandConversationViewPayload returns the full text of a collapsed payload
+// @Summary Expand a collapsed conversation view payload
+// @Tags Query
+// @Produce json
+// @Param conversation_id path string true "Conversation ID"
+// @Param token path string true "Expansion token"
+// @Success 200 {object} models.ViewPayload
+// @Router /api/v1/conversations/{conversation_id}/view/payloads/{token} [get]
+func (s *Server) expandConversationViewPayload(c *gin.Context) {
+	_, turns, ok := s.viewedConversation(c, c.Param("conversation_id"))
+	if !ok {
+		return
+	}
+
+	payload, err := services.ExpandViewPayload(turns, c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if payload == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payload not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, payload)
+}
+
+// viewedConversation loads a conversation and its turns, writing the error
+// response and returning false if it can't
+func (s *Server) viewedConversation(c *gin.Context, conversationID string) (*models.Conversation, []models.Turn, bool) {
+	conv, err := s.projectRepo(c).GetConversation(conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	if conv == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return nil, nil, false
+	}
+
+	var turns []models.Turn
+	if err := json.Unmarshal(conv.Turns, &turns); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse turns"})
+		return nil, nil, false
+	}
+	services.MaskTurns(turns, s.maskingProfile(c))
+
+	return conv, turns, true
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// createSavedView saves a named view for the request's project
+// @Summary Save a view
+// @Description Saves a named filter and sort order over conversations or evaluations, e.g. critical tool failures of one agent version in the last 7 days, for the project's team to run by name. sort_by defaults to created_at and sort_order to desc.
+// @Tags Views
+// @Accept json
+// @Produce json
+// @Param view body models.SavedViewCreate true "View"
+// @Success 201 {object} models.SavedView
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/views [post]
+func (s *Server) createSavedView(c *gin.Context) {
+	var req models.SavedViewCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.NormalizeViewFilters(req.Resource, &req.Filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := s.repo.CreateSavedView(c.GetString(projectKey), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if view == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "A view with this name already exists"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, view)
+}
+
+// updateSavedView changes a saved view
+// @Summary Update a saved view
+// @Tags Views
+// @Accept json
+// @Produce json
+// @Param name path string true "View name"
+// @Param view body models.SavedViewUpdate true "View"
+// @Success 200 {object} models.SavedView
+// @Router /api/v1/views/{name} [put]
+func (s *Server) updateSavedView(c *gin.Context) {
+	var req models.SavedViewUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.NormalizeViewFilters(req.Resource, &req.Filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := s.repo.UpdateSavedView(c.GetString(projectKey), c.Param("name"), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if view == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// listSavedViews lists the project's saved views
+// @Summary List saved views
+// @Tags Views
+// @Produce json
+// @Param resource query string false "Only views of conversations or evaluations"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/views [get]
+func (s *Server) listSavedViews(c *gin.Context) {
+	views, err := s.repo.ListSavedViews(c.GetString(projectKey), c.Query("resource"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"views": views,
+		"count": len(views),
+	})
+}
+
+// getSavedView retrieves a saved view
+// @Summary Get a saved view
+// @Tags Views
+// @Produce json
+// @Param name path string true "View name"
+// @Success 200 {object} models.SavedView
+// @Router /api/v1/views/{name} [get]
+func (s *Server) getSavedView(c *gin.Context) {
+	view, err := s.repo.GetSavedView(c.GetString(projectKey), c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if view == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// deleteSavedView deletes a saved view
+// @Summary Delete a saved view
+// @Tags Views
+// @Produce json
+// @Param name path string true "View name"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/views/{name} [delete]
+func (s *Server) deleteSavedView(c *gin.Context) {
+	deleted, err := s.repo.DeleteSavedView(c.GetString(projectKey), c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "deleted",
+		"name":   c.Param("name"),
+	})
+}
+
+// runSavedView lists the conversations or evaluations a saved view selects
+// @Summary Run a saved view
+// @Description Conversations are masked as in the conversation list, and evaluations are in the full evaluation response format. Relative windows are resolved at the time of the request.
+// @Tags Views
+// @Produce json
+// @Param name path string true "View name"
+// @Param limit query int false "Limit" default(100)
+// @Param offset query int false "Offset" default(0)
+// @Param Accept-Language header string false "Language to translate issues and suggestions into"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/views/{name}/results [get]
+func (s *Server) runSavedView(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	view, err := s.repo.GetSavedView(c.GetString(projectKey), c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if view == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+		return
+	}
+	filters, err := services.ParseViewFilters(view.Filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"view":   view,
+		"limit":  limit,
+		"offset": offset,
+	}
+	repo := s.projectRepo(c)
+
+	switch view.Resource {
+	case models.ViewResourceConversations:
+		convs, err := repo.ListConversationsForView(filters, limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := s.maskConversations(c, convs); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		response["conversations"] = convs
+		response["count"] = len(convs)
+
+	case models.ViewResourceEvaluations:
+		evals, err := repo.ListEvaluationsForView(filters, limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		results := make([]*models.EvaluationResponse, 0, len(evals))
+		for i := range evals {
+			results = append(results, models.NewEvaluationResponse(&evals[i]))
+		}
+		s.localizeEvaluations(c, results)
+		response["evaluations"] = results
+		response["count"] = len(results)
+
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "View has unknown resource " + view.Resource})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+// createProjectWebhook registers an evaluation result webhook for a project
+// @Summary Create project webhook
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param project_id path string true "Project ID"
+// @Param webhook body models.ProjectWebhookCreate true "Webhook data"
+// @Success 201 {object} models.ProjectWebhook
+// @Router /api/v1/projects/{project_id}/webhooks [post]
+func (s *Server) createProjectWebhook(c *gin.Context) {
+	projectID := c.Param("project_id")
+
+	var req models.ProjectWebhookCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := webhook.ParseTemplate(req.PayloadTemplate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hook, err := s.repo.CreateProjectWebhook(projectID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, hook)
+}
+
+// listProjectWebhooks lists a project's webhooks
+// @Summary List project webhooks
+// @Tags Webhooks
+// @Produce json
+// @Param project_id path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/projects/{project_id}/webhooks [get]
+func (s *Server) listProjectWebhooks(c *gin.Context) {
+	hooks, err := s.repo.ListProjectWebhooks(c.Param("project_id"), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhooks": hooks,
+		"count":    len(hooks),
+	})
+}
+
+// updateProjectWebhook sets a project webhook's URL, template and filter and
+// enables it, such as to activate a placeholder webhook
+// @Summary Update project webhook
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param project_id path string true "Project ID"
+// @Param webhook_id path int true "Webhook ID"
+// @Param webhook body models.ProjectWebhookCreate true "Webhook data"
+// @Success 200 {object} models.ProjectWebhook
+// @Router /api/v1/projects/{project_id}/webhooks/{webhook_id} [put]
+func (s *Server) updateProjectWebhook(c *gin.Context) {
+	webhookID, err := strconv.ParseInt(c.Param("webhook_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	var req models.ProjectWebhookCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := webhook.ParseTemplate(req.PayloadTemplate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hook, err := s.repo.UpdateProjectWebhook(c.Param("project_id"), webhookID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hook == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+// deleteProjectWebhook deletes a project webhook
+// @Summary Delete project webhook
+// @Tags Webhooks
+// @Produce json
+// @Param project_id path string true "Project ID"
+// @Param webhook_id path int true "Webhook ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/projects/{project_id}/webhooks/{webhook_id} [delete]
+func (s *Server) deleteProjectWebhook(c *gin.Context) {
+	webhookID, err := strconv.ParseInt(c.Param("webhook_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	deleted, err := s.repo.DeleteProjectWebhook(c.Param("project_id"), webhookID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "deleted",
+		"webhook_id": webhookID,
+	})
+}
+
+// previewProjectWebhook renders a webhook payload for an existing evaluation
+// of the project
+// @Summary Preview project webhook payload
+// @Tags Webhooks
+// @Produce json
+// @Param project_id path string true "Project ID"
+// @Param webhook_id path int true "Webhook ID"
+// @Param evaluation_id query string true "Evaluation ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/projects/{project_id}/webhooks/{webhook_id}/preview [get]
+func (s *Server) previewProjectWebhook(c *gin.Context) {
+	webhookID, err := strconv.ParseInt(c.Param("webhook_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	hooks, err := s.repo.ListProjectWebhooks(c.Param("project_id"), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var hook *models.ProjectWebhook
+	for i := range hooks {
+		if hooks[i].ID == webhookID {
+			hook = &hooks[i]
+		}
+	}
+	if hook == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	// Only the project's own evaluations can be rendered into its payloads
+	eval, err := s.repo.ForProject(c.Param("project_id")).GetEvaluation(c.Query("evaluation_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if eval == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Evaluation not found"})
+		return
+	}
+
+	resp := models.NewEvaluationResponse(eval)
+	payload, err := webhook.Render(hook, resp)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"matches_filter": webhook.Matches(hook, resp),
+		"payload":        string(payload),
+	})
+}
+
+// listWebhookDeliveries lists a project webhook's deliveries, newest first
+// @Summary List project webhook deliveries
+// @Tags Webhooks
+// @Produce json
+// @Param project_id path string true "Project ID"
+// @Param webhook_id path int true "Webhook ID"
+// @Param status query string false "Delivery status (pending, delivered or failed)"
+// @Param limit query int false "Maximum deliveries" default(50)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/projects/{project_id}/webhooks/{webhook_id}/deliveries [get]
+func (s *Server) listWebhookDeliveries(c *gin.Context) {
+	webhookID, err := strconv.ParseInt(c.Param("webhook_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliveryDelivered, models.WebhookDeliveryFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, delivered or failed"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	deliveries, err := s.repo.ListWebhookDeliveries(c.Param("project_id"), webhookID, status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
+
+// dispatchWebhooks queues deliveries of an evaluation to its project's
+// webhooks. Deliveries are best effort and must not fail the request.
+func (s *Server) dispatchWebhooks(eval *models.Evaluation) {
+	if err := s.webhooks.Dispatch(eval); err != nil {
+		log.Printf("Failed to dispatch webhooks for evaluation %s: %v", eval.EvaluationID, err)
+	}
+}
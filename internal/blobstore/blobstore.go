@@ -0,0 +1,47 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/config"
+)
+
+// Supported blob stores
+const (
+	BackendFS = "fs"
+	BackendS3 = "s3"
+)
+
+// ErrNotFound is returned by Get when no blob is stored under a key
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs by key along with their content type. Keys are content
+// hashes, so putting a key that already exists leaves the same content.
+type Store interface {
+	Backend() string
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	Delete(ctx context.Context, key string) error // Deleting a missing blob is not an error
+}
+
+// New creates the blob store selected in the configuration. It returns nil
+// when no store is configured.
+func New(cfg *config.Config) (Store, error) {
+	switch cfg.AttachmentStore {
+	case "":
+		return nil, nil
+	case BackendFS:
+		if cfg.AttachmentStoreDir == "" {
+			return nil, fmt.Errorf("fs attachment store requires ATTACHMENT_STORE_DIR")
+		}
+		return newFS(cfg.AttachmentStoreDir), nil
+	case BackendS3:
+		if cfg.AttachmentS3Bucket == "" || cfg.AttachmentS3AccessKey == "" || cfg.AttachmentS3SecretKey == "" {
+			return nil, fmt.Errorf("s3 attachment store requires ATTACHMENT_S3_BUCKET, ATTACHMENT_S3_ACCESS_KEY_ID and ATTACHMENT_S3_SECRET_ACCESS_KEY")
+		}
+		return newS3(cfg), nil
+	}
+	return nil, fmt.Errorf("unsupported attachment store %q", cfg.AttachmentStore)
+}
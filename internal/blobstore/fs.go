@@ -0,0 +1,99 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fs stores blobs as files under a directory, fanned out by the first two
+// characters of the key. The content type is kept in a sidecar file.
+type fs struct {
+	dir string
+}
+
+func newFS(dir string) *fs {
+	return &fs{dir: dir}
+}
+
+func (f *fs) Backend() string { return BackendFS }
+
+// Put writes a blob through a temporary file so readers never see it half
+// written
+func (f *fs) Put(ctx context.Context, key, contentType string, data []byte) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	if err := writeAtomic(path+".type", []byte(contentType)); err != nil {
+		return err
+	}
+	return writeAtomic(path, data)
+}
+
+// Get reads a blob and its content type
+func (f *fs) Get(ctx context.Context, key string) ([]byte, string, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read blob: %w", err)
+	}
+	contentType, err := os.ReadFile(path + ".type")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, "", fmt.Errorf("failed to read blob content type: %w", err)
+	}
+	return data, string(contentType), nil
+}
+
+// Delete removes a blob and its content type
+func (f *fs) Delete(ctx context.Context, key string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	if err := os.Remove(path + ".type"); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete blob content type: %w", err)
+	}
+	return nil
+}
+
+func (f *fs) path(key string) (string, error) {
+	if len(key) < 2 || strings.ContainsAny(key, `/\.`) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(f.dir, key[:2], key), nil
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store blob: %w", err)
+	}
+	return nil
+}
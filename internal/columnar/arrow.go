@@ -0,0 +1,358 @@
+package columnar
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Arrow enum values from Schema.fbs and Message.fbs
+const (
+	arrowMetadataV5 = 4
+
+	arrowHeaderSchema      = 1
+	arrowHeaderRecordBatch = 3
+
+	arrowTypeInt           = 2
+	arrowTypeFloatingPoint = 3
+	arrowTypeUtf8          = 5
+	arrowTypeTimestamp     = 10
+
+	arrowPrecisionDouble = 2
+	arrowMicrosecond     = 2
+)
+
+// arrowContinuation prefixes every message in the IPC stream format
+const arrowContinuation = 0xffffffff
+
+// ArrowWriter writes an Arrow IPC stream: the schema, then one record batch
+// per written batch, then the end-of-stream marker on Close.
+type ArrowWriter struct {
+	w             io.Writer
+	schema        []Column
+	wroteSchema   bool
+	scratchBuffer []byte
+}
+
+// NewArrowWriter creates an Arrow IPC stream writer
+func NewArrowWriter(w io.Writer, schema []Column) *ArrowWriter {
+	return &ArrowWriter{w: w, schema: schema}
+}
+
+// WriteBatch writes a batch as a record batch. Empty batches are skipped.
+func (a *ArrowWriter) WriteBatch(batch *Batch) error {
+	if err := a.writeSchema(); err != nil {
+		return err
+	}
+	if batch.rows == 0 {
+		return nil
+	}
+
+	// Lay out the body: per column a validity bitmap (omitted, since nothing
+	// is null), then offsets for strings, then values; each 8-byte aligned
+	body := a.scratchBuffer[:0]
+	type bufferRef struct{ offset, length int64 }
+	var buffers []bufferRef
+	addBuffer := func(data []byte) {
+		buffers = append(buffers, bufferRef{offset: int64(len(body)), length: int64(len(data))})
+		body = append(body, data...)
+		for len(body)%8 != 0 {
+			body = append(body, 0)
+		}
+	}
+
+	for i, col := range a.schema {
+		addBuffer(nil)
+		switch col.Type {
+		case Int64, Timestamp:
+			data := make([]byte, 0, 8*batch.rows)
+			for _, v := range batch.ints[i] {
+				data = binary.LittleEndian.AppendUint64(data, uint64(v))
+			}
+			addBuffer(data)
+		case Float64:
+			data := make([]byte, 0, 8*batch.rows)
+			for _, v := range batch.floats[i] {
+				data = binary.LittleEndian.AppendUint64(data, math.Float64bits(v))
+			}
+			addBuffer(data)
+		case String:
+			offsets := make([]byte, 0, 4*(batch.rows+1))
+			var data []byte
+			offsets = binary.LittleEndian.AppendUint32(offsets, 0)
+			for _, v := range batch.strings[i] {
+				data = append(data, v...)
+				offsets = binary.LittleEndian.AppendUint32(offsets, uint32(len(data)))
+			}
+			addBuffer(offsets)
+			addBuffer(data)
+		}
+	}
+	a.scratchBuffer = body
+
+	var b fbBuilder
+	b.startVector(16, len(buffers), 8)
+	for i := len(buffers) - 1; i >= 0; i-- {
+		b.prependInt64(buffers[i].length)
+		b.prependInt64(buffers[i].offset)
+	}
+	buffersVec := b.endVector(len(buffers))
+
+	b.startVector(16, len(a.schema), 8)
+	for range a.schema {
+		b.prependInt64(0) // null_count
+		b.prependInt64(int64(batch.rows))
+	}
+	nodesVec := b.endVector(len(a.schema))
+
+	b.startTable(3)
+	b.addInt64(0, int64(batch.rows))
+	b.addOffset(1, nodesVec)
+	b.addOffset(2, buffersVec)
+	recordBatch := b.endTable()
+
+	if err := a.writeMessage(&b, arrowHeaderRecordBatch, recordBatch, int64(len(body))); err != nil {
+		return err
+	}
+	if _, err := a.w.Write(body); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Close writes the end-of-stream marker
+func (a *ArrowWriter) Close() error {
+	if err := a.writeSchema(); err != nil {
+		return err
+	}
+	var eos [8]byte
+	binary.LittleEndian.PutUint32(eos[:4], arrowContinuation)
+	_, err := a.w.Write(eos[:])
+	return err
+}
+
+// writeSchema writes the schema message once, before any record batch
+func (a *ArrowWriter) writeSchema() error {
+	if a.wroteSchema {
+		return nil
+	}
+	a.wroteSchema = true
+
+	var b fbBuilder
+	fields := make([]int, len(a.schema))
+	for i, col := range a.schema {
+		name := b.createString(col.Name)
+
+		var typeType byte
+		var typeTable int
+		switch col.Type {
+		case Int64:
+			b.startTable(2)
+			b.addInt32(0, 64)
+			b.addBool(1, true)
+			typeType, typeTable = arrowTypeInt, b.endTable()
+		case Float64:
+			b.startTable(1)
+			b.addInt16(0, arrowPrecisionDouble)
+			typeType, typeTable = arrowTypeFloatingPoint, b.endTable()
+		case String:
+			b.startTable(0)
+			typeType, typeTable = arrowTypeUtf8, b.endTable()
+		case Timestamp:
+			timezone := b.createString("UTC")
+			b.startTable(2)
+			b.addInt16(0, arrowMicrosecond)
+			b.addOffset(1, timezone)
+			typeType, typeTable = arrowTypeTimestamp, b.endTable()
+		}
+
+		// Readers require the children vector even for flat types
+		b.startVector(4, 0, 4)
+		children := b.endVector(0)
+
+		b.startTable(6)
+		b.addOffset(0, name)
+		b.addBool(1, false)
+		b.addByte(2, typeType)
+		b.addOffset(3, typeTable)
+		b.addOffset(5, children)
+		fields[i] = b.endTable()
+	}
+
+	b.startVector(4, len(fields), 4)
+	for i := len(fields) - 1; i >= 0; i-- {
+		b.prependOffset(fields[i])
+	}
+	fieldsVec := b.endVector(len(fields))
+
+	b.startTable(2)
+	b.addInt16(0, 0) // Little endian
+	b.addOffset(1, fieldsVec)
+	schema := b.endTable()
+
+	return a.writeMessage(&b, arrowHeaderSchema, schema, 0)
+}
+
+// writeMessage wraps a message header in a Message table and writes it with
+// the stream framing; the caller writes the body
+func (a *ArrowWriter) writeMessage(b *fbBuilder, headerType byte, header int, bodyLength int64) error {
+	b.startTable(4)
+	b.addInt16(0, arrowMetadataV5)
+	b.addByte(1, headerType)
+	b.addOffset(2, header)
+	b.addInt64(3, bodyLength)
+	message := b.finish(b.endTable())
+
+	// The metadata is padded so the body starts 8-byte aligned
+	padded := (len(message) + 7) &^ 7
+	frame := make([]byte, 8, 8+padded)
+	binary.LittleEndian.PutUint32(frame[:4], arrowContinuation)
+	binary.LittleEndian.PutUint32(frame[4:], uint32(padded))
+	frame = append(frame, message...)
+	frame = append(frame, make([]byte, padded-len(message))...)
+
+	_, err := a.w.Write(frame)
+	return err
+}
+
+// fbBuilder builds a FlatBuffer back to front, like the reference builders:
+// children are written before their parents, and offsets are measured from
+// the end of the buffer. Only what Arrow's schema and record batch messages
+// need is implemented.
+type fbBuilder struct {
+	buf      []byte // The tail of the finished buffer
+	minAlign int
+	vtable   []int // Offsets of the fields of the table being built
+	tableEnd int
+}
+
+// offset is the distance from the current front to the end of the buffer
+func (b *fbBuilder) offset() int {
+	return len(b.buf)
+}
+
+// prep pads the front so that after writing additional bytes the front is
+// aligned to size
+func (b *fbBuilder) prep(size, additional int) {
+	if size > b.minAlign {
+		b.minAlign = size
+	}
+	pad := (-(len(b.buf) + additional)) & (size - 1)
+	b.prepend(make([]byte, pad))
+}
+
+func (b *fbBuilder) prepend(data []byte) {
+	b.buf = append(data, b.buf...)
+}
+
+func (b *fbBuilder) prependByte(v byte) {
+	b.prep(1, 0)
+	b.prepend([]byte{v})
+}
+
+func (b *fbBuilder) prependInt16(v int16) {
+	b.prep(2, 0)
+	b.prepend(binary.LittleEndian.AppendUint16(nil, uint16(v)))
+}
+
+func (b *fbBuilder) prependInt32(v int32) {
+	b.prep(4, 0)
+	b.prepend(binary.LittleEndian.AppendUint32(nil, uint32(v)))
+}
+
+func (b *fbBuilder) prependInt64(v int64) {
+	b.prep(8, 0)
+	b.prepend(binary.LittleEndian.AppendUint64(nil, uint64(v)))
+}
+
+// prependOffset writes a reference to an earlier written object
+func (b *fbBuilder) prependOffset(target int) {
+	b.prep(4, 0)
+	b.prepend(binary.LittleEndian.AppendUint32(nil, uint32(b.offset()-target+4)))
+}
+
+func (b *fbBuilder) createString(s string) int {
+	b.prep(4, len(s)+1)
+	b.prepend(append([]byte(s), 0))
+	b.prepend(binary.LittleEndian.AppendUint32(nil, uint32(len(s))))
+	return b.offset()
+}
+
+// startVector prepares for n elements of elemSize bytes, which are then
+// prepended last to first
+func (b *fbBuilder) startVector(elemSize, n, alignment int) {
+	b.prep(4, elemSize*n)
+	b.prep(alignment, elemSize*n)
+}
+
+func (b *fbBuilder) endVector(n int) int {
+	b.prep(4, 0)
+	b.prepend(binary.LittleEndian.AppendUint32(nil, uint32(n)))
+	return b.offset()
+}
+
+func (b *fbBuilder) startTable(numFields int) {
+	b.vtable = make([]int, numFields)
+	b.tableEnd = b.offset()
+}
+
+func (b *fbBuilder) addByte(field int, v byte) {
+	b.prependByte(v)
+	b.vtable[field] = b.offset()
+}
+
+func (b *fbBuilder) addBool(field int, v bool) {
+	var byteValue byte
+	if v {
+		byteValue = 1
+	}
+	b.addByte(field, byteValue)
+}
+
+func (b *fbBuilder) addInt16(field int, v int16) {
+	b.prependInt16(v)
+	b.vtable[field] = b.offset()
+}
+
+func (b *fbBuilder) addInt32(field int, v int32) {
+	b.prependInt32(v)
+	b.vtable[field] = b.offset()
+}
+
+func (b *fbBuilder) addInt64(field int, v int64) {
+	b.prependInt64(v)
+	b.vtable[field] = b.offset()
+}
+
+func (b *fbBuilder) addOffset(field int, target int) {
+	b.prependOffset(target)
+	b.vtable[field] = b.offset()
+}
+
+// endTable writes the table's vtable just before it and returns the table
+func (b *fbBuilder) endTable() int {
+	b.prependInt32(0) // Replaced by the offset to the vtable below
+	table := b.offset()
+
+	for i := len(b.vtable) - 1; i >= 0; i-- {
+		var fieldOffset int16
+		if b.vtable[i] != 0 {
+			fieldOffset = int16(table - b.vtable[i])
+		}
+		b.prependInt16(fieldOffset)
+	}
+	b.prependInt16(int16(table - b.tableEnd))
+	b.prependInt16(int16((len(b.vtable) + 2) * 2))
+
+	vtable := b.offset()
+	binary.LittleEndian.PutUint32(b.buf[len(b.buf)-table:], uint32(int32(vtable-table)))
+	b.vtable = nil
+	return table
+}
+
+// finish writes the root reference and returns the buffer
+func (b *fbBuilder) finish(root int) []byte {
+	b.prep(b.minAlign, 4)
+	b.prependOffset(root)
+	return b.buf
+}
@@ -0,0 +1,125 @@
+// Package columnar writes row batches as Apache Parquet files, Apache Arrow
+// IPC streams and CSV for bulk export to analytics tools. Only the subset of
+// each format needed for flat tables of non-null values is implemented:
+// int64, float64, UTF-8 string and UTC timestamp columns, uncompressed.
+package columnar
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Type is the type of a column
+type Type int
+
+// Supported column types
+const (
+	Int64 Type = iota
+	Float64
+	String
+	Timestamp // Stored as microseconds since the Unix epoch, UTC
+)
+
+// Column describes one column of a schema
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Writer writes batches in a columnar format. Close writes any trailing
+// metadata; it doesn't close the underlying writer.
+type Writer interface {
+	WriteBatch(batch *Batch) error
+	Close() error
+}
+
+// Batch holds rows of a schema column by column
+type Batch struct {
+	schema  []Column
+	rows    int
+	ints    [][]int64
+	floats  [][]float64
+	strings [][]string
+}
+
+// NewBatch creates an empty batch for a schema
+func NewBatch(schema []Column) *Batch {
+	return &Batch{
+		schema:  schema,
+		ints:    make([][]int64, len(schema)),
+		floats:  make([][]float64, len(schema)),
+		strings: make([][]string, len(schema)),
+	}
+}
+
+// Len returns the number of rows in the batch
+func (b *Batch) Len() int {
+	return b.rows
+}
+
+// Reset empties the batch so it can be reused
+func (b *Batch) Reset() {
+	b.rows = 0
+	for i := range b.schema {
+		b.ints[i] = b.ints[i][:0]
+		b.floats[i] = b.floats[i][:0]
+		b.strings[i] = b.strings[i][:0]
+	}
+}
+
+// Append adds a row. Values must match the schema: int64 or int for Int64,
+// float64 for Float64, string for String and time.Time for Timestamp.
+func (b *Batch) Append(values ...interface{}) error {
+	if len(values) != len(b.schema) {
+		return fmt.Errorf("row has %d values, schema has %d columns", len(values), len(b.schema))
+	}
+
+	// Validate the whole row first so a bad value doesn't leave columns uneven
+	for i, col := range b.schema {
+		ok := false
+		switch values[i].(type) {
+		case int64, int:
+			ok = col.Type == Int64
+		case float64:
+			ok = col.Type == Float64
+		case string:
+			ok = col.Type == String
+		case time.Time:
+			ok = col.Type == Timestamp
+		}
+		if !ok {
+			return fmt.Errorf("column %s: unexpected value of type %T", col.Name, values[i])
+		}
+	}
+
+	for i, value := range values {
+		switch v := value.(type) {
+		case int64:
+			b.ints[i] = append(b.ints[i], v)
+		case int:
+			b.ints[i] = append(b.ints[i], int64(v))
+		case float64:
+			b.floats[i] = append(b.floats[i], v)
+		case string:
+			b.strings[i] = append(b.strings[i], v)
+		case time.Time:
+			b.ints[i] = append(b.ints[i], v.UnixMicro())
+		}
+	}
+	b.rows++
+
+	return nil
+}
+
+// countingWriter tracks how many bytes have been written
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
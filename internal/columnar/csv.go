@@ -0,0 +1,74 @@
+package columnar
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// CSVWriter writes batches as CSV rows under a header of the column names.
+// Timestamps are written in RFC 3339 format, UTC.
+type CSVWriter struct {
+	w             *csv.Writer
+	schema        []Column
+	headerWritten bool
+}
+
+// NewCSVWriter creates a CSV writer
+func NewCSVWriter(w io.Writer, schema []Column) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w), schema: schema}
+}
+
+// writeHeader writes the column names before the first row
+func (c *CSVWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+
+	header := make([]string, len(c.schema))
+	for i, col := range c.schema {
+		header[i] = col.Name
+	}
+	return c.w.Write(header)
+}
+
+// WriteBatch writes a batch's rows and flushes them to the underlying writer
+func (c *CSVWriter) WriteBatch(batch *Batch) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	record := make([]string, len(c.schema))
+	for row := 0; row < batch.rows; row++ {
+		for i, col := range c.schema {
+			switch col.Type {
+			case Int64:
+				record[i] = strconv.FormatInt(batch.ints[i][row], 10)
+			case Float64:
+				record[i] = strconv.FormatFloat(batch.floats[i][row], 'g', -1, 64)
+			case String:
+				record[i] = batch.strings[i][row]
+			case Timestamp:
+				record[i] = time.UnixMicro(batch.ints[i][row]).UTC().Format(time.RFC3339Nano)
+			}
+		}
+		if err := c.w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// Close writes the header if no batch was written, so an empty export is
+// still a valid CSV file
+func (c *CSVWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
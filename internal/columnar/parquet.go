@@ -0,0 +1,325 @@
+package columnar
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// parquetMagic starts and ends every Parquet file
+const parquetMagic = "PAR1"
+
+// Parquet enum values from parquet.thrift
+const (
+	parquetTypeInt64     = 2
+	parquetTypeDouble    = 5
+	parquetTypeByteArray = 6
+
+	parquetRequired = 0
+
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMicros = 10
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetCodecUncompressed = 0
+	parquetPageData          = 0
+)
+
+// parquetColumnChunk records where a column chunk was written
+type parquetColumnChunk struct {
+	offset int64
+	size   int64
+	values int64
+}
+
+// parquetRowGroup records the column chunks of one written batch
+type parquetRowGroup struct {
+	rows    int64
+	columns []parquetColumnChunk
+}
+
+// ParquetWriter writes each batch as a Parquet row group with one plain
+// encoded, uncompressed data page per column. The file footer is written on
+// Close.
+type ParquetWriter struct {
+	w         *countingWriter
+	schema    []Column
+	createdBy string
+	rowGroups []parquetRowGroup
+}
+
+// NewParquetWriter creates a Parquet writer. createdBy is recorded in the
+// file metadata.
+func NewParquetWriter(w io.Writer, schema []Column, createdBy string) *ParquetWriter {
+	return &ParquetWriter{w: &countingWriter{w: w}, schema: schema, createdBy: createdBy}
+}
+
+// start writes the leading magic bytes before the first row group
+func (p *ParquetWriter) start() error {
+	if p.w.n > 0 {
+		return nil
+	}
+	_, err := io.WriteString(p.w, parquetMagic)
+	return err
+}
+
+// WriteBatch writes a batch as a row group. Empty batches are skipped.
+func (p *ParquetWriter) WriteBatch(batch *Batch) error {
+	if batch.rows == 0 {
+		return nil
+	}
+	if err := p.start(); err != nil {
+		return err
+	}
+
+	group := parquetRowGroup{rows: int64(batch.rows)}
+	for i, col := range p.schema {
+		values := parquetPlainValues(batch, i, col.Type)
+
+		var header thriftWriter
+		header.fieldI32(1, parquetPageData)
+		header.fieldI32(2, int32(len(values)))
+		header.fieldI32(3, int32(len(values)))
+		header.fieldStruct(5)
+		header.fieldI32(1, int32(batch.rows))
+		header.fieldI32(2, parquetEncodingPlain)
+		header.fieldI32(3, parquetEncodingRLE)
+		header.fieldI32(4, parquetEncodingRLE)
+		header.structEnd()
+		header.structEnd()
+
+		chunk := parquetColumnChunk{
+			offset: p.w.n,
+			size:   int64(len(header.buf) + len(values)),
+			values: int64(batch.rows),
+		}
+		if _, err := p.w.Write(header.buf); err != nil {
+			return err
+		}
+		if _, err := p.w.Write(values); err != nil {
+			return err
+		}
+		group.columns = append(group.columns, chunk)
+	}
+
+	p.rowGroups = append(p.rowGroups, group)
+	return nil
+}
+
+// Close writes the file footer
+func (p *ParquetWriter) Close() error {
+	if err := p.start(); err != nil {
+		return err
+	}
+
+	var numRows int64
+	for _, group := range p.rowGroups {
+		numRows += group.rows
+	}
+
+	var meta thriftWriter
+	meta.fieldI32(1, 1)
+
+	// The schema is a root group followed by one element per column
+	meta.fieldList(2, thriftStruct, len(p.schema)+1)
+	meta.elemStructBegin()
+	meta.fieldString(4, "schema")
+	meta.fieldI32(5, int32(len(p.schema)))
+	meta.structEnd()
+	for _, col := range p.schema {
+		meta.elemStructBegin()
+		meta.fieldI32(1, parquetPhysicalType(col.Type))
+		meta.fieldI32(3, parquetRequired)
+		meta.fieldString(4, col.Name)
+		switch col.Type {
+		case String:
+			meta.fieldI32(6, parquetConvertedUTF8)
+		case Timestamp:
+			meta.fieldI32(6, parquetConvertedTimestampMicros)
+		}
+		meta.structEnd()
+	}
+
+	meta.fieldI64(3, numRows)
+
+	meta.fieldList(4, thriftStruct, len(p.rowGroups))
+	for _, group := range p.rowGroups {
+		var groupSize int64
+		for _, chunk := range group.columns {
+			groupSize += chunk.size
+		}
+
+		meta.elemStructBegin()
+		meta.fieldList(1, thriftStruct, len(group.columns))
+		for i, chunk := range group.columns {
+			col := p.schema[i]
+			meta.elemStructBegin()
+			meta.fieldI64(2, chunk.offset)
+			meta.fieldStruct(3)
+			meta.fieldI32(1, parquetPhysicalType(col.Type))
+			meta.fieldList(2, thriftI32, 1)
+			meta.elemI32(parquetEncodingPlain)
+			meta.fieldList(3, thriftBinary, 1)
+			meta.elemString(col.Name)
+			meta.fieldI32(4, parquetCodecUncompressed)
+			meta.fieldI64(5, chunk.values)
+			meta.fieldI64(6, chunk.size)
+			meta.fieldI64(7, chunk.size)
+			meta.fieldI64(9, chunk.offset)
+			meta.structEnd()
+			meta.structEnd()
+		}
+		meta.fieldI64(2, groupSize)
+		meta.fieldI64(3, group.rows)
+		meta.structEnd()
+	}
+
+	if p.createdBy != "" {
+		meta.fieldString(6, p.createdBy)
+	}
+	meta.structEnd()
+
+	if _, err := p.w.Write(meta.buf); err != nil {
+		return err
+	}
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(meta.buf)))
+	if _, err := p.w.Write(length[:]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(p.w, parquetMagic)
+	return err
+}
+
+// parquetPhysicalType maps a column type to its Parquet physical type
+func parquetPhysicalType(t Type) int32 {
+	switch t {
+	case Float64:
+		return parquetTypeDouble
+	case String:
+		return parquetTypeByteArray
+	}
+	return parquetTypeInt64
+}
+
+// parquetPlainValues plain encodes one column of a batch
+func parquetPlainValues(batch *Batch, col int, t Type) []byte {
+	var buf []byte
+	switch t {
+	case Int64, Timestamp:
+		buf = make([]byte, 0, 8*batch.rows)
+		for _, v := range batch.ints[col] {
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(v))
+		}
+	case Float64:
+		buf = make([]byte, 0, 8*batch.rows)
+		for _, v := range batch.floats[col] {
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+		}
+	case String:
+		for _, v := range batch.strings[col] {
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(len(v)))
+			buf = append(buf, v...)
+		}
+	default:
+		panic(fmt.Sprintf("columnar: unsupported column type %d", t))
+	}
+	return buf
+}
+
+// Thrift compact protocol type codes
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs with the Thrift compact protocol, which
+// Parquet uses for its page headers and footer. Struct fields must be
+// written in increasing ID order.
+type thriftWriter struct {
+	buf     []byte
+	lastID  int16
+	idStack []int16
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	t.buf = binary.AppendUvarint(t.buf, v)
+}
+
+func (t *thriftWriter) zigzag(v int64) {
+	t.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+// fieldHeader writes a field header, using the short form when the ID
+// delta allows
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.zigzag(int64(id))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) fieldI32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.zigzag(int64(v))
+}
+
+func (t *thriftWriter) fieldI64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.zigzag(v)
+}
+
+func (t *thriftWriter) fieldString(id int16, v string) {
+	t.fieldHeader(id, thriftBinary)
+	t.elemString(v)
+}
+
+// fieldStruct starts a struct field; end it with structEnd
+func (t *thriftWriter) fieldStruct(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.elemStructBegin()
+}
+
+// fieldList starts a list field of n elements, which are written next
+func (t *thriftWriter) fieldList(id int16, elemType byte, n int) {
+	t.fieldHeader(id, thriftList)
+	if n < 15 {
+		t.buf = append(t.buf, byte(n)<<4|elemType)
+	} else {
+		t.buf = append(t.buf, 0xf0|elemType)
+		t.varint(uint64(n))
+	}
+}
+
+func (t *thriftWriter) elemI32(v int32) {
+	t.zigzag(int64(v))
+}
+
+func (t *thriftWriter) elemString(v string) {
+	t.varint(uint64(len(v)))
+	t.buf = append(t.buf, v...)
+}
+
+// elemStructBegin starts a nested struct; end it with structEnd
+func (t *thriftWriter) elemStructBegin() {
+	t.idStack = append(t.idStack, t.lastID)
+	t.lastID = 0
+}
+
+// structEnd writes the stop marker of the current struct
+func (t *thriftWriter) structEnd() {
+	t.buf = append(t.buf, 0)
+	if n := len(t.idStack); n > 0 {
+		t.lastID = t.idStack[n-1]
+		t.idStack = t.idStack[:n-1]
+	}
+}
@@ -7,8 +7,8 @@ import (
 	_ "github.com/lib/pq"
 )
 
-// New creates a new database connection
-func New(databaseURL string, maxConnections, maxIdle int) (*sqlx.DB, error) {
+// New creates a new database connection pool that retries transient errors
+func New(databaseURL string, maxConnections, maxIdle int) (*DB, error) {
 	db, err := sqlx.Connect("postgres", databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -22,12 +22,26 @@ func New(databaseURL string, maxConnections, maxIdle int) (*sqlx.DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return db, nil
+	return &DB{DB: db, policy: DefaultRetryPolicy, maxIdle: maxIdle}, nil
 }
 
 // Migrate runs database migrations
 func Migrate(db *sqlx.DB) error {
 	migrations := []string{
+		// Projects, which scope conversations and everything recorded about
+		// them. Data from before projects existed belongs to the default one.
+		`CREATE TABLE IF NOT EXISTS projects (
+			project_id VARCHAR(255) PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`INSERT INTO projects (project_id, name) VALUES ('default', 'Default') ON CONFLICT DO NOTHING`,
+		// Settings provisioned when a project is onboarded
+		`ALTER TABLE projects ADD COLUMN IF NOT EXISTS evaluator_types JSONB DEFAULT '[]'`,
+		`ALTER TABLE projects ADD COLUMN IF NOT EXISTS routing_policy JSONB DEFAULT '{}'`,
+		`ALTER TABLE projects ADD COLUMN IF NOT EXISTS quotas JSONB DEFAULT '{}'`,
+		`ALTER TABLE projects ADD COLUMN IF NOT EXISTS assisted_labeling JSONB DEFAULT '{}'`,
+
 		// Conversations table
 		`CREATE TABLE IF NOT EXISTS conversations (
 			id SERIAL PRIMARY KEY,
@@ -42,6 +56,14 @@ func Migrate(db *sqlx.DB) error {
 		// Indexes for conversations
 		`CREATE INDEX IF NOT EXISTS idx_conversations_agent_version ON conversations(agent_version)`,
 		`CREATE INDEX IF NOT EXISTS idx_conversations_created_at ON conversations(created_at)`,
+		`ALTER TABLE conversations ADD COLUMN IF NOT EXISTS intent VARCHAR(100) NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS idx_conversations_intent ON conversations(intent)`,
+		`ALTER TABLE conversations ADD COLUMN IF NOT EXISTS project_id VARCHAR(255) NOT NULL DEFAULT 'default'`,
+		`CREATE INDEX IF NOT EXISTS idx_conversations_project_created ON conversations(project_id, created_at)`,
+		`ALTER TABLE conversations ADD COLUMN IF NOT EXISTS token_counts JSONB NOT NULL DEFAULT '{}'`,
+		// Journeys link conversations by the session or subject in their metadata
+		`CREATE INDEX IF NOT EXISTS idx_conversations_session_id ON conversations((metadata->>'session_id'))`,
+		`CREATE INDEX IF NOT EXISTS idx_conversations_subject_id ON conversations((metadata->>'subject_id'))`,
 		
 		// Feedbacks table
 		`CREATE TABLE IF NOT EXISTS feedbacks (
@@ -54,6 +76,13 @@ func Migrate(db *sqlx.DB) error {
 		)`,
 		
 		`CREATE INDEX IF NOT EXISTS idx_feedbacks_conversation_id ON feedbacks(conversation_id)`,
+
+		// Free-text feedback comments and their extracted sentiment and themes
+		`ALTER TABLE feedbacks ADD COLUMN IF NOT EXISTS comment TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE feedbacks ADD COLUMN IF NOT EXISTS sentiment VARCHAR(20) NOT NULL DEFAULT ''`,
+		`ALTER TABLE feedbacks ADD COLUMN IF NOT EXISTS sentiment_score FLOAT`,
+		`ALTER TABLE feedbacks ADD COLUMN IF NOT EXISTS themes JSONB NOT NULL DEFAULT '[]'`,
+		`CREATE INDEX IF NOT EXISTS idx_feedbacks_created_at ON feedbacks(created_at) WHERE comment <> ''`,
 		
 		// Evaluations table
 		`CREATE TABLE IF NOT EXISTS evaluations (
@@ -75,6 +104,36 @@ func Migrate(db *sqlx.DB) error {
 		`CREATE INDEX IF NOT EXISTS idx_evaluations_conversation_id ON evaluations(conversation_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_evaluations_overall_score ON evaluations(overall_score)`,
 		`CREATE INDEX IF NOT EXISTS idx_evaluations_created_at ON evaluations(created_at)`,
+
+		// Raw (pre-deduplication) issues
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS raw_issues_detected JSONB DEFAULT '[]'`,
+
+		// Originating task and trigger source
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS task_id VARCHAR(255) NOT NULL DEFAULT ''`,
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS trigger_source VARCHAR(50) NOT NULL DEFAULT ''`,
+
+		// Evaluator types that failed or timed out, for partial results
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS failed_evaluators JSONB NOT NULL DEFAULT '[]'`,
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS component_scores JSONB NOT NULL DEFAULT '{}'`,
+
+		// Project of the evaluated conversation
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS project_id VARCHAR(255) NOT NULL DEFAULT 'default'`,
+		`CREATE INDEX IF NOT EXISTS idx_evaluations_project_created ON evaluations(project_id, created_at)`,
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS partial BOOLEAN NOT NULL DEFAULT FALSE`,
+		`CREATE INDEX IF NOT EXISTS idx_evaluations_partial ON evaluations(created_at) WHERE partial`,
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS signature TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS signature_algorithm VARCHAR(50) NOT NULL DEFAULT ''`,
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS skipped_evaluators JSONB NOT NULL DEFAULT '[]'`,
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS evaluation_cost FLOAT NOT NULL DEFAULT 0`,
+
+		// What influenced the scores, to reproduce or explain them
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS manifest JSONB NOT NULL DEFAULT '{}'`,
+		`CREATE INDEX IF NOT EXISTS idx_evaluations_trigger_source ON evaluations(trigger_source)`,
+
+		// Verdict of the verdict policy when the evaluation was stored
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS verdict VARCHAR(20) NOT NULL DEFAULT ''`,
+		`ALTER TABLE evaluations ADD COLUMN IF NOT EXISTS verdict_rule VARCHAR(255) NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS idx_evaluations_verdict ON evaluations(verdict, created_at)`,
 		
 		// Annotations table
 		`CREATE TABLE IF NOT EXISTS annotations (
@@ -93,6 +152,20 @@ func Migrate(db *sqlx.DB) error {
 		`CREATE INDEX IF NOT EXISTS idx_annotations_conversation_id ON annotations(conversation_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_annotations_annotator_id ON annotations(annotator_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_annotations_type ON annotations(annotation_type)`,
+
+		// Multi-label annotations. label keeps the labels joined by commas for
+		// single-label readers; existing rows get their labels split from it.
+		`ALTER TABLE annotations ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '[]'`,
+		`UPDATE annotations SET labels = to_jsonb(string_to_array(label, ',')) WHERE labels = '[]'::jsonb AND label <> ''`,
+
+		// Project of the annotated conversation
+		`ALTER TABLE annotations ADD COLUMN IF NOT EXISTS project_id VARCHAR(255) NOT NULL DEFAULT 'default'`,
+		`CREATE INDEX IF NOT EXISTS idx_annotations_project_id ON annotations(project_id)`,
+
+		// Assisted labeling arm of an annotation and the machine suggestion
+		// for its conversation when it was made, whether shown or not
+		`ALTER TABLE annotations ADD COLUMN IF NOT EXISTS assisted BOOLEAN`,
+		`ALTER TABLE annotations ADD COLUMN IF NOT EXISTS suggested_labels JSONB`,
 		
 		// Annotator Performance table
 		`CREATE TABLE IF NOT EXISTS annotator_performance (
@@ -152,6 +225,8 @@ func Migrate(db *sqlx.DB) error {
 		`CREATE INDEX IF NOT EXISTS idx_suggestions_type ON improvement_suggestions(suggestion_type)`,
 		`CREATE INDEX IF NOT EXISTS idx_suggestions_status ON improvement_suggestions(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_suggestions_confidence ON improvement_suggestions(confidence)`,
+		`ALTER TABLE improvement_suggestions ADD COLUMN IF NOT EXISTS project_id VARCHAR(255) NOT NULL DEFAULT 'default'`,
+		`CREATE INDEX IF NOT EXISTS idx_suggestions_project_id ON improvement_suggestions(project_id)`,
 		
 		// Evaluator Calibration table
 		`CREATE TABLE IF NOT EXISTS evaluator_calibration (
@@ -171,6 +246,488 @@ func Migrate(db *sqlx.DB) error {
 		)`,
 		
 		`CREATE INDEX IF NOT EXISTS idx_calibration_evaluator_type ON evaluator_calibration(evaluator_type)`,
+
+		// Evaluator version rollouts (A/B tests)
+		`CREATE TABLE IF NOT EXISTS evaluator_rollouts (
+			id SERIAL PRIMARY KEY,
+			evaluator_type VARCHAR(100) NOT NULL,
+			incumbent_version VARCHAR(50) NOT NULL,
+			candidate_version VARCHAR(50) NOT NULL,
+			traffic_percent FLOAT NOT NULL,
+			min_samples INTEGER DEFAULT 30,
+			status VARCHAR(50) DEFAULT 'active',
+			decision_reason TEXT,
+			decided_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_rollouts_status ON evaluator_rollouts(status)`,
+
+		// Background imports of uploaded conversation files
+		`CREATE TABLE IF NOT EXISTS conversation_imports (
+			import_id VARCHAR(255) PRIMARY KEY,
+			filename VARCHAR(255) NOT NULL,
+			format VARCHAR(20) NOT NULL,
+			size_bytes BIGINT NOT NULL DEFAULT 0,
+			auto_evaluate BOOLEAN NOT NULL DEFAULT TRUE,
+			status VARCHAR(20) NOT NULL,
+			processed INTEGER NOT NULL DEFAULT 0,
+			imported INTEGER NOT NULL DEFAULT 0,
+			failed INTEGER NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			finished_at TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS conversation_import_errors (
+			id SERIAL PRIMARY KEY,
+			import_id VARCHAR(255) NOT NULL REFERENCES conversation_imports(import_id) ON DELETE CASCADE,
+			line INTEGER NOT NULL,
+			conversation_id VARCHAR(255) NOT NULL DEFAULT '',
+			error TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_conversation_import_errors_import_id ON conversation_import_errors(import_id, line)`,
+
+		// Sampled evaluator service exchanges, kept for offline replay
+		`CREATE TABLE IF NOT EXISTS evaluator_recordings (
+			id SERIAL PRIMARY KEY,
+			conversation_id VARCHAR(255) NOT NULL,
+			evaluator_version VARCHAR(50) NOT NULL DEFAULT '',
+			request JSONB NOT NULL,
+			response JSONB,
+			status_code INTEGER NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			duration_ms INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_evaluator_recordings_created_at ON evaluator_recordings(created_at)`,
+
+		// When each evaluation task reached each stage of the pipeline
+		`CREATE TABLE IF NOT EXISTS pipeline_timings (
+			task_id VARCHAR(255) PRIMARY KEY,
+			conversation_id VARCHAR(255) NOT NULL DEFAULT '',
+			trigger_source VARCHAR(50) NOT NULL DEFAULT '',
+			ingested_at TIMESTAMP,
+			queued_at TIMESTAMP,
+			dequeued_at TIMESTAMP,
+			evaluator_started_at TIMESTAMP,
+			persisted_at TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_pipeline_timings_queued_at ON pipeline_timings(queued_at)`,
+
+		// Durable record of dequeued tasks, completed in the same transaction
+		// as the evaluation they persist
+		`CREATE TABLE IF NOT EXISTS task_journal (
+			task_id VARCHAR(255) PRIMARY KEY,
+			queue VARCHAR(100) NOT NULL,
+			task_type VARCHAR(50) NOT NULL DEFAULT '',
+			conversation_id VARCHAR(255) NOT NULL DEFAULT '',
+			state VARCHAR(20) NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			payload JSONB NOT NULL DEFAULT '{}',
+			evaluation_id VARCHAR(255) NOT NULL DEFAULT '',
+			last_error TEXT NOT NULL DEFAULT '',
+			worker_id VARCHAR(255) NOT NULL DEFAULT '',
+			transitions JSONB NOT NULL DEFAULT '[]',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_task_journal_state_updated ON task_journal(queue, state, updated_at)`,
+
+		// Project evaluation result webhooks
+		`CREATE TABLE IF NOT EXISTS project_webhooks (
+			id SERIAL PRIMARY KEY,
+			project_id VARCHAR(255) NOT NULL,
+			url TEXT NOT NULL,
+			payload_template TEXT NOT NULL DEFAULT '',
+			filter JSONB DEFAULT '{}',
+			enabled BOOLEAN DEFAULT TRUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_project_webhooks_project_id ON project_webhooks(project_id)`,
+
+		// Secrets deliveries are signed with; each existing webhook gets its own
+		`ALTER TABLE project_webhooks ADD COLUMN IF NOT EXISTS secret VARCHAR(64) NOT NULL
+			DEFAULT replace(gen_random_uuid()::text || gen_random_uuid()::text, '-', '')`,
+
+		// Project webhook deliveries, pending and past
+		`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id SERIAL PRIMARY KEY,
+			webhook_id INTEGER NOT NULL REFERENCES project_webhooks(id) ON DELETE CASCADE,
+			event VARCHAR(50) NOT NULL,
+			evaluation_id VARCHAR(255) NOT NULL,
+			payload TEXT NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			response_status INTEGER,
+			last_error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			delivered_at TIMESTAMP
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE status = 'pending'`,
+		`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at)`,
+
+		// Imported pipeline configuration bundles
+		`CREATE TABLE IF NOT EXISTS config_bundles (
+			id SERIAL PRIMARY KEY,
+			bundle JSONB NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// Per-team notification preferences
+		`CREATE TABLE IF NOT EXISTS notification_preferences (
+			id SERIAL PRIMARY KEY,
+			project_id VARCHAR(255) NOT NULL,
+			team VARCHAR(255) NOT NULL,
+			channels JSONB DEFAULT '[]',
+			severities JSONB DEFAULT '[]',
+			digest_frequency VARCHAR(20) NOT NULL DEFAULT 'none',
+			quiet_hours_start VARCHAR(5) NOT NULL DEFAULT '',
+			quiet_hours_end VARCHAR(5) NOT NULL DEFAULT '',
+			timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+			last_digest_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(project_id, team)
+		)`,
+
+		// Named filters over conversations and evaluations, shared per project
+		`CREATE TABLE IF NOT EXISTS saved_views (
+			id SERIAL PRIMARY KEY,
+			project_id VARCHAR(255) NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			resource VARCHAR(50) NOT NULL,
+			filters JSONB NOT NULL DEFAULT '{}',
+			created_by VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(project_id, name)
+		)`,
+
+		// Reviewer approvals of routing decisions
+		`CREATE TABLE IF NOT EXISTS routing_approvals (
+			id SERIAL PRIMARY KEY,
+			conversation_id VARCHAR(255) NOT NULL REFERENCES conversations(conversation_id),
+			evaluation_id VARCHAR(255) NOT NULL REFERENCES evaluations(evaluation_id),
+			reviewer_id VARCHAR(255) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(conversation_id, evaluation_id)
+		)`,
+
+		// Issues dismissed by reviewers, by position in issues_detected
+		`CREATE TABLE IF NOT EXISTS issue_dismissals (
+			id SERIAL PRIMARY KEY,
+			evaluation_id VARCHAR(255) NOT NULL REFERENCES evaluations(evaluation_id),
+			issue_index INTEGER NOT NULL,
+			reviewer_id VARCHAR(255) NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(evaluation_id, issue_index)
+		)`,
+
+		// Annotation work assigned to annotators
+		`CREATE TABLE IF NOT EXISTS annotation_tasks (
+			id SERIAL PRIMARY KEY,
+			conversation_id VARCHAR(255) NOT NULL REFERENCES conversations(conversation_id),
+			annotation_type VARCHAR(100) NOT NULL,
+			annotator_id VARCHAR(255) NOT NULL,
+			assigned_by VARCHAR(255) NOT NULL DEFAULT '',
+			status VARCHAR(20) NOT NULL DEFAULT 'assigned',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(conversation_id, annotation_type)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_annotation_tasks_annotator_id ON annotation_tasks(annotator_id, status)`,
+
+		// One task per annotator, so a conversation can be annotated independently
+		// by several annotators
+		`ALTER TABLE annotation_tasks DROP CONSTRAINT IF EXISTS annotation_tasks_conversation_id_annotation_type_key`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_annotation_tasks_unique ON annotation_tasks(conversation_id, annotation_type, annotator_id)`,
+
+		// Routing priority inherited when a task is assigned, and its deadline
+		`ALTER TABLE annotation_tasks ADD COLUMN IF NOT EXISTS priority VARCHAR(10) NOT NULL DEFAULT 'low'`,
+		`ALTER TABLE annotation_tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMP`,
+		`CREATE INDEX IF NOT EXISTS idx_annotation_tasks_due_at ON annotation_tasks(due_at) WHERE status <> 'completed'`,
+
+		// Unusual hourly quality metric values
+		`CREATE TABLE IF NOT EXISTS anomaly_events (
+			id SERIAL PRIMARY KEY,
+			metric VARCHAR(50) NOT NULL,
+			bucket TIMESTAMP NOT NULL,
+			value FLOAT NOT NULL,
+			expected FLOAT NOT NULL,
+			z_score FLOAT NOT NULL,
+			severity VARCHAR(20) NOT NULL,
+			evaluations INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(metric, bucket)
+		)`,
+
+		// Owners of detected issues, by position in issues_detected. Unowned
+		// issues are stored with an empty owner.
+		`CREATE TABLE IF NOT EXISTS issue_assignments (
+			evaluation_id VARCHAR(255) NOT NULL REFERENCES evaluations(evaluation_id),
+			issue_index INTEGER NOT NULL,
+			conversation_id VARCHAR(255) NOT NULL,
+			issue_type VARCHAR(100) NOT NULL DEFAULT '',
+			severity VARCHAR(50) NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			tool VARCHAR(255) NOT NULL DEFAULT '',
+			owner VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (evaluation_id, issue_index)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_issue_assignments_owner ON issue_assignments(owner, created_at)`,
+
+		`ALTER TABLE failure_patterns ADD COLUMN IF NOT EXISTS owner VARCHAR(255)`,
+
+		// Issue tracker tickets opened for failure patterns
+		`ALTER TABLE failure_patterns ADD COLUMN IF NOT EXISTS external_provider VARCHAR(20)`,
+		`ALTER TABLE failure_patterns ADD COLUMN IF NOT EXISTS external_issue_key VARCHAR(255)`,
+		`ALTER TABLE failure_patterns ADD COLUMN IF NOT EXISTS external_issue_url TEXT`,
+		`ALTER TABLE failure_patterns ADD COLUMN IF NOT EXISTS external_synced_at TIMESTAMP`,
+
+		// When a failure pattern was announced in Slack
+		`ALTER TABLE failure_patterns ADD COLUMN IF NOT EXISTS slack_notified_at TIMESTAMP`,
+
+		// Annotations made in external labeling tools, by the tool's annotation ID
+		`CREATE TABLE IF NOT EXISTS external_annotations (
+			source VARCHAR(50) NOT NULL,
+			external_id VARCHAR(255) NOT NULL,
+			annotation_id INTEGER NOT NULL REFERENCES annotations(id) ON DELETE CASCADE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (source, external_id, annotation_id)
+		)`,
+
+		// Denormalized latest evaluation state per conversation
+		`CREATE TABLE IF NOT EXISTS conversation_summaries (
+			conversation_id VARCHAR(255) PRIMARY KEY REFERENCES conversations(conversation_id),
+			latest_evaluation_id VARCHAR(255) NOT NULL,
+			latest_overall_score FLOAT NOT NULL,
+			open_issue_count INTEGER NOT NULL DEFAULT 0,
+			critical_issue_count INTEGER NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// Composite conversation health
+		`CREATE TABLE IF NOT EXISTS conversation_health (
+			conversation_id VARCHAR(255) PRIMARY KEY REFERENCES conversations(conversation_id),
+			health_score FLOAT NOT NULL,
+			components JSONB NOT NULL DEFAULT '{}',
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`INSERT INTO conversation_summaries (
+			conversation_id, latest_evaluation_id, latest_overall_score,
+			open_issue_count, critical_issue_count, updated_at
+		)
+		SELECT DISTINCT ON (e.conversation_id)
+			e.conversation_id, e.evaluation_id, e.overall_score,
+			COALESCE(jsonb_array_length(e.issues_detected), 0),
+			(SELECT COUNT(*) FROM jsonb_array_elements(COALESCE(e.issues_detected, '[]'::jsonb)) AS i(value)
+				WHERE i.value->>'severity' = 'critical'),
+			e.created_at
+		FROM evaluations e
+		WHERE e.conversation_id IS NOT NULL
+			AND NOT EXISTS (SELECT 1 FROM conversation_summaries)
+		ORDER BY e.conversation_id, e.created_at DESC
+		ON CONFLICT (conversation_id) DO NOTHING`,
+
+		// Issue counts by severity, for routing by configurable severity
+		`ALTER TABLE conversation_summaries ADD COLUMN IF NOT EXISTS severity_counts JSONB NOT NULL DEFAULT '{}'`,
+		`UPDATE conversation_summaries s SET severity_counts = counts.severity_counts
+		FROM (
+			SELECT e.evaluation_id, jsonb_object_agg(i.severity, i.n) AS severity_counts
+			FROM evaluations e
+			CROSS JOIN LATERAL (
+				SELECT value->>'severity' AS severity, COUNT(*) AS n
+				FROM jsonb_array_elements(COALESCE(e.issues_detected, '[]'::jsonb))
+				WHERE value->>'severity' IS NOT NULL
+				GROUP BY value->>'severity'
+			) i
+			GROUP BY e.evaluation_id
+		) counts
+		WHERE counts.evaluation_id = s.latest_evaluation_id
+			AND s.severity_counts = '{}' AND s.open_issue_count > 0`,
+
+		// Verdict of each conversation's latest evaluation, for routing
+		`ALTER TABLE conversation_summaries ADD COLUMN IF NOT EXISTS latest_verdict VARCHAR(20) NOT NULL DEFAULT ''`,
+
+		// Periods in which an annotation type's inter-rater reliability fell
+		// below the threshold
+		`CREATE TABLE IF NOT EXISTS reliability_events (
+			id SERIAL PRIMARY KEY,
+			annotation_type VARCHAR(100) NOT NULL,
+			bucket TIMESTAMP NOT NULL,
+			kappa FLOAT NOT NULL,
+			previous_kappa FLOAT,
+			percent_agreement FLOAT NOT NULL,
+			items INTEGER NOT NULL,
+			threshold FLOAT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(annotation_type, bucket)
+		)`,
+
+		// Tokens of non-human API clients, stored as SHA-256 hashes
+		`CREATE TABLE IF NOT EXISTS service_accounts (
+			name VARCHAR(255) PRIMARY KEY,
+			token_hash VARCHAR(64) UNIQUE NOT NULL,
+			scopes JSONB NOT NULL DEFAULT '[]',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			last_used_at TIMESTAMP,
+			revoked_at TIMESTAMP
+		)`,
+		// Keys bound to a project only see its data; empty for every project
+		`ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS project_id VARCHAR(255) NOT NULL DEFAULT ''`,
+
+		`CREATE TABLE IF NOT EXISTS api_keys (
+			id VARCHAR(36) PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			key_prefix VARCHAR(16) NOT NULL,
+			key_hash VARCHAR(64) UNIQUE NOT NULL,
+			scopes JSONB NOT NULL DEFAULT '[]',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			last_used_at TIMESTAMP,
+			revoked_at TIMESTAMP
+		)`,
+
+		// Reviewer comment threads on conversations, their turns and evaluations
+		`CREATE TABLE IF NOT EXISTS comments (
+			id SERIAL PRIMARY KEY,
+			conversation_id VARCHAR(255) NOT NULL REFERENCES conversations(conversation_id) ON DELETE CASCADE,
+			turn_id INTEGER,
+			evaluation_id VARCHAR(255) REFERENCES evaluations(evaluation_id) ON DELETE CASCADE,
+			parent_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+			author VARCHAR(255) NOT NULL,
+			body TEXT NOT NULL,
+			mentions JSONB NOT NULL DEFAULT '[]',
+			resolved BOOLEAN NOT NULL DEFAULT FALSE,
+			resolved_by VARCHAR(255) NOT NULL DEFAULT '',
+			resolved_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_comments_conversation ON comments(conversation_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_comments_mentions ON comments USING GIN (mentions)`,
+
+		// Datasets of items agent releases are regression tested on. Runs keep
+		// their scores when the evaluated conversations are deleted.
+		`CREATE TABLE IF NOT EXISTS datasets (
+			id SERIAL PRIMARY KEY,
+			project_id VARCHAR(255) NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			created_by VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(project_id, name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS dataset_items (
+			id SERIAL PRIMARY KEY,
+			dataset_id INTEGER NOT NULL REFERENCES datasets(id) ON DELETE CASCADE,
+			item_key VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			metadata JSONB NOT NULL DEFAULT '{}',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(dataset_id, item_key)
+		)`,
+		`CREATE TABLE IF NOT EXISTS dataset_runs (
+			id SERIAL PRIMARY KEY,
+			dataset_id INTEGER NOT NULL REFERENCES datasets(id) ON DELETE CASCADE,
+			agent_version VARCHAR(100) NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			item_count INTEGER NOT NULL DEFAULT 0,
+			evaluated_count INTEGER NOT NULL DEFAULT 0,
+			missing_count INTEGER NOT NULL DEFAULT 0,
+			failed_count INTEGER NOT NULL DEFAULT 0,
+			mean_scores JSONB NOT NULL DEFAULT '{}',
+			verdict_counts JSONB NOT NULL DEFAULT '{}',
+			pass_rate FLOAT,
+			created_by VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			completed_at TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_dataset_runs_dataset ON dataset_runs(dataset_id, created_at)`,
+		`CREATE TABLE IF NOT EXISTS dataset_run_items (
+			run_id INTEGER NOT NULL REFERENCES dataset_runs(id) ON DELETE CASCADE,
+			item_key VARCHAR(255) NOT NULL,
+			conversation_id VARCHAR(255) REFERENCES conversations(conversation_id) ON DELETE SET NULL,
+			task_id VARCHAR(255) NOT NULL DEFAULT '',
+			evaluation_id VARCHAR(255) REFERENCES evaluations(evaluation_id) ON DELETE SET NULL,
+			status VARCHAR(20) NOT NULL,
+			overall_score FLOAT,
+			scores JSONB NOT NULL DEFAULT '{}',
+			verdict VARCHAR(20) NOT NULL DEFAULT '',
+			PRIMARY KEY (run_id, item_key)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_dataset_run_items_task ON dataset_run_items(task_id) WHERE status = 'pending'`,
+
+		// Conversations produced from a dataset item, matched by regression runs
+		`CREATE INDEX IF NOT EXISTS idx_conversations_dataset_item ON conversations(agent_version, (metadata->>'dataset_item'), created_at)
+			WHERE metadata ? 'dataset_item'`,
+
+		// Salts of the annotator pseudonyms in a project's exports and reports
+		`CREATE TABLE IF NOT EXISTS annotator_salts (
+			project_id VARCHAR(255) PRIMARY KEY REFERENCES projects(project_id) ON DELETE CASCADE,
+			salt VARCHAR(64) NOT NULL,
+			created_at TIMESTAMP DEFAULT NOW()
+		)`,
+
+		// Full-text search over issue descriptions and improvement suggestions
+		`CREATE INDEX IF NOT EXISTS idx_evaluations_search ON evaluations USING GIN ((` + EvaluationSearchVector + `))`,
+
+		// What running the agent cost per conversation, priced at ingestion
+		`ALTER TABLE conversations ADD COLUMN IF NOT EXISTS cost_usd FLOAT`,
+
+		// Scoring configurations seen by the stale re-evaluation policy, and
+		// the conversations it sampled to re-evaluate under each
+		`CREATE TABLE IF NOT EXISTS reevaluation_baselines (
+			fingerprint VARCHAR(64) PRIMARY KEY,
+			manifest JSONB NOT NULL,
+			target INTEGER NOT NULL,
+			enqueued INTEGER NOT NULL DEFAULT 0,
+			detected_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			completed_at TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS reevaluation_samples (
+			fingerprint VARCHAR(64) NOT NULL REFERENCES reevaluation_baselines(fingerprint) ON DELETE CASCADE,
+			conversation_id VARCHAR(255) NOT NULL,
+			enqueued_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (fingerprint, conversation_id)
+		)`,
+
+		// Imports belong to the project they were uploaded to
+		`ALTER TABLE conversation_imports ADD COLUMN IF NOT EXISTS project_id VARCHAR(255) NOT NULL DEFAULT 'default'`,
+
+		// Rollouts are decided only on a clear difference in correlation
+		`ALTER TABLE evaluator_rollouts ADD COLUMN IF NOT EXISTS min_margin FLOAT NOT NULL DEFAULT 0.02`,
+
+		// Dataset runs whose conversations were reassigned to another agent
+		// version no longer measure the version they were run for
+		`ALTER TABLE dataset_runs ADD COLUMN IF NOT EXISTS stale_at TIMESTAMP`,
+
+		// Projects' aggregates are kept off the public API until they opt in
+		`ALTER TABLE projects ADD COLUMN IF NOT EXISTS public BOOLEAN NOT NULL DEFAULT FALSE`,
+
+		// Deleting a conversation deletes everything recorded about it
+		foreignKey("feedbacks", "conversation_id", "conversations(conversation_id)", "CASCADE"),
+		foreignKey("evaluations", "conversation_id", "conversations(conversation_id)", "CASCADE"),
+		foreignKey("annotations", "conversation_id", "conversations(conversation_id)", "CASCADE"),
+		foreignKey("annotation_tasks", "conversation_id", "conversations(conversation_id)", "CASCADE"),
+		foreignKey("routing_approvals", "conversation_id", "conversations(conversation_id)", "CASCADE"),
+		foreignKey("routing_approvals", "evaluation_id", "evaluations(evaluation_id)", "CASCADE"),
+		foreignKey("issue_dismissals", "evaluation_id", "evaluations(evaluation_id)", "CASCADE"),
+		foreignKey("issue_assignments", "evaluation_id", "evaluations(evaluation_id)", "CASCADE"),
+		foreignKey("conversation_summaries", "conversation_id", "conversations(conversation_id)", "CASCADE"),
+		foreignKey("conversation_health", "conversation_id", "conversations(conversation_id)", "CASCADE"),
+		foreignKey("reevaluation_samples", "conversation_id", "conversations(conversation_id)", "CASCADE"),
 	}
 
 	for _, migration := range migrations {
@@ -181,3 +738,35 @@ func Migrate(db *sqlx.DB) error {
 
 	return nil
 }
+
+// EvaluationSearchVector is the text search document of an evaluation: its
+// issue descriptions and its improvement suggestions and their rationales.
+// Queries must use it verbatim for the search index to apply.
+const EvaluationSearchVector = `
+	to_tsvector('english', jsonb_path_query_array(COALESCE(issues_detected, '[]'::jsonb), '$[*].description')) ||
+	to_tsvector('english', jsonb_path_query_array(COALESCE(improvement_suggestions, '[]'::jsonb), '$[*].suggestion')) ||
+	to_tsvector('english', jsonb_path_query_array(COALESCE(improvement_suggestions, '[]'::jsonb), '$[*].rationale'))`
+
+// foreignKeyDeleteActions maps ON DELETE actions to pg_constraint.confdeltype
+var foreignKeyDeleteActions = map[string]string{
+	"NO ACTION": "a",
+	"RESTRICT":  "r",
+	"CASCADE":   "c",
+	"SET NULL":  "n",
+}
+
+// foreignKey returns a migration that (re)creates the default-named foreign
+// key on table.column with the given ON DELETE action, unless it already has
+// it. The constraint is added NOT VALID so rows that predate it, such as
+// annotations of conversations that no longer exist, don't block startup;
+// new rows are still checked.
+func foreignKey(table, column, references, onDelete string) string {
+	name := table + "_" + column + "_fkey"
+	return fmt.Sprintf(`DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = '%[1]s' AND confdeltype = '%[6]s') THEN
+				ALTER TABLE %[2]s DROP CONSTRAINT IF EXISTS %[1]s;
+				ALTER TABLE %[2]s ADD CONSTRAINT %[1]s FOREIGN KEY (%[3]s) REFERENCES %[4]s ON DELETE %[5]s NOT VALID;
+			END IF;
+		END $$`, name, table, column, references, onDelete, foreignKeyDeleteActions[onDelete])
+}
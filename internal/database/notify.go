@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// InvalidationChannel is the NOTIFY channel used to invalidate in-process caches across replicas
+const InvalidationChannel = "cache_invalidation"
+
+// Notify publishes a payload on a NOTIFY channel
+func Notify(db *sqlx.DB, channel, payload string) error {
+	if _, err := db.Exec(`SELECT pg_notify($1, $2)`, channel, payload); err != nil {
+		return fmt.Errorf("failed to notify %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Listen calls handler for every payload published on channel until ctx is cancelled.
+// After a reconnect handler is called with an empty payload, since notifications
+// sent while disconnected are lost.
+func Listen(ctx context.Context, databaseURL, channel string, handler func(payload string)) error {
+	listener := pq.NewListener(databaseURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Listener on %s: %v", channel, err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(channel); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			if n == nil {
+				handler("")
+				continue
+			}
+			handler(n.Extra)
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
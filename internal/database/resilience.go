@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ai-agent-eval/internal/faults"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// RetryPolicy controls how transient database errors are retried
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy rides out a typical managed-Postgres failover of a few seconds
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    3 * time.Second,
+}
+
+// failoverResetInterval limits how often the connection pool is flushed
+const failoverResetInterval = 5 * time.Second
+
+// DB wraps a connection pool with retries for transient errors. Get and
+// Select are reads and are retried on any transient error. Exec is only
+// retried when Postgres guarantees the statement had no effect (serialization
+// failures, deadlocks, writes rejected by a demoted primary). Transactions and
+// QueryRowx are not retried; callers own their retry semantics.
+type DB struct {
+	*sqlx.DB
+	policy  RetryPolicy
+	maxIdle int
+
+	mu        sync.Mutex
+	lastReset time.Time
+
+	faults *faults.Injector
+}
+
+// SetFaults injects faults into Get, Select and Exec, before retries apply
+func (db *DB) SetFaults(injector *faults.Injector) {
+	db.faults = injector
+}
+
+// Get runs a single-row read, retrying transient errors
+func (db *DB) Get(dest interface{}, query string, args ...interface{}) error {
+	return db.retry(true, func() error {
+		if err := db.faults.Inject(context.Background(), faults.TargetDatabase); err != nil {
+			return err
+		}
+		return db.DB.Get(dest, query, args...)
+	})
+}
+
+// Select runs a multi-row read, retrying transient errors
+func (db *DB) Select(dest interface{}, query string, args ...interface{}) error {
+	return db.retry(true, func() error {
+		if err := db.faults.Inject(context.Background(), faults.TargetDatabase); err != nil {
+			return err
+		}
+		return db.DB.Select(dest, query, args...)
+	})
+}
+
+// Exec runs a statement, retrying only errors that guarantee it had no effect
+func (db *DB) Exec(query string, args ...interface{}) (result sql.Result, err error) {
+	err = db.retry(false, func() error {
+		if err := db.faults.Inject(context.Background(), faults.TargetDatabase); err != nil {
+			return err
+		}
+		result, err = db.DB.Exec(query, args...)
+		return err
+	})
+	return result, err
+}
+
+// retry runs fn until it succeeds, fails permanently or attempts run out
+func (db *DB) retry(idempotent bool, fn func() error) error {
+	delay := db.policy.BaseDelay
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+
+		failover := isFailoverError(err)
+		if failover {
+			db.resetPool(err)
+		}
+
+		retryable := isNoEffectError(err) || (idempotent && (failover || isConnectionError(err)))
+		if !retryable || attempt >= db.policy.MaxAttempts {
+			return err
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+		if delay > db.policy.MaxDelay {
+			delay = db.policy.MaxDelay
+		}
+	}
+}
+
+// resetPool drops idle connections so new ones are opened against the
+// current primary. Prepared statements are per connection in database/sql
+// and are re-prepared on the new connections automatically.
+func (db *DB) resetPool(cause error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if time.Since(db.lastReset) < failoverResetInterval {
+		return
+	}
+	db.lastReset = time.Now()
+
+	log.Printf("Database failover detected (%v), resetting connection pool", cause)
+	db.DB.SetMaxIdleConns(0)
+	db.DB.SetMaxIdleConns(db.maxIdle)
+}
+
+// isNoEffectError reports errors after which Postgres guarantees the
+// statement was not applied, so retrying even a write is safe
+func isNoEffectError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return errors.Is(err, driver.ErrBadConn) // Returned before the statement is sent
+	}
+
+	switch pqErr.Code {
+	case "40001", // serialization_failure
+		"40P01", // deadlock_detected
+		"25006", // read_only_sql_transaction: connected to a demoted primary
+		"57P03": // cannot_connect_now: server starting up or in recovery
+		return true
+	}
+	return false
+}
+
+// isFailoverError reports errors that indicate the server went away or
+// changed role, so existing connections should not be reused
+func isFailoverError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "25006", // read_only_sql_transaction
+			"57P01", // admin_shutdown
+			"57P02", // crash_shutdown
+			"57P03": // cannot_connect_now
+			return true
+		}
+		return pqErr.Code.Class() == "08" // connection_exception
+	}
+	return isConnectionError(err)
+}
+
+// isConnectionError reports network-level failures talking to the server
+func isConnectionError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,85 @@
+// Package embeddings computes lightweight text embeddings for similarity search.
+//
+// Vectors are built with feature hashing over lower-cased word tokens, so no
+// external model or service is required.
+package embeddings
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Dimensions is the length of every embedding vector
+const Dimensions = 256
+
+// Embed returns an L2-normalized embedding of text
+func Embed(text string) []float64 {
+	vector := make([]float64, Dimensions)
+
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, token := range tokens {
+		h := fnv.New32a()
+		h.Write([]byte(token))
+		sum := h.Sum32()
+
+		// Use one hash bit as the sign to reduce collision bias
+		if sum&1 == 0 {
+			vector[(sum>>1)%Dimensions]++
+		} else {
+			vector[(sum>>1)%Dimensions]--
+		}
+	}
+
+	normalize(vector)
+	return vector
+}
+
+// EmbedTurns embeds the concatenated content of a conversation's turns
+func EmbedTurns(turns json.RawMessage) []float64 {
+	var parsed []struct {
+		Content string `json:"content"`
+	}
+	json.Unmarshal(turns, &parsed)
+
+	var sb strings.Builder
+	for _, turn := range parsed {
+		sb.WriteString(turn.Content)
+		sb.WriteByte('\n')
+	}
+
+	return Embed(sb.String())
+}
+
+// Cosine returns the cosine similarity of two normalized vectors
+func Cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	dot := 0.0
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return dot
+}
+
+// normalize scales v to unit length in place
+func normalize(v []float64) {
+	norm := 0.0
+	for _, x := range v {
+		norm += x * x
+	}
+	if norm == 0 {
+		return
+	}
+
+	norm = math.Sqrt(norm)
+	for i := range v {
+		v[i] /= norm
+	}
+}
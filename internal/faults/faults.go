@@ -0,0 +1,174 @@
+// Package faults injects latency and errors into the API, the evaluator
+// client, the Redis queue and the database, so retry and failure handling can
+// be exercised end to end. It is only wired up outside release mode.
+package faults
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Injection targets
+const (
+	TargetAPI       = "api"       // Incoming API requests fail with 500
+	TargetEvaluator = "evaluator" // Evaluator service calls fail with 500
+	TargetRedis     = "redis"     // Queue operations fail
+	TargetDatabase  = "database"  // Database calls time out
+)
+
+// Targets lists the injection targets
+var Targets = []string{TargetAPI, TargetEvaluator, TargetRedis, TargetDatabase}
+
+// Bounds on how long a fault stays active
+const (
+	DefaultTTL = 5 * time.Minute
+	MaxTTL     = time.Hour
+)
+
+// Fault describes the latency and errors injected at a target
+type Fault struct {
+	Target     string    `json:"target"`
+	LatencyMS  int       `json:"latency_ms"`
+	ErrorRate  float64   `json:"error_rate"`            // Probability that a call fails
+	TTLSeconds int       `json:"ttl_seconds,omitempty"` // Defaults to five minutes
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Error is returned for injected failures. It reports itself as a timeout
+// so callers treat it like the network failure it simulates.
+type Error struct {
+	Target string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("injected %s fault", e.Target)
+}
+
+// Timeout implements net.Error
+func (e *Error) Timeout() bool { return true }
+
+// Temporary implements net.Error
+func (e *Error) Temporary() bool { return true }
+
+// Injector holds the active faults. A nil Injector injects nothing, so
+// callers don't need to check whether fault injection is enabled.
+type Injector struct {
+	mu     sync.Mutex
+	faults map[string]Fault
+	rand   *rand.Rand
+}
+
+// NewInjector creates an injector with no active faults
+func NewInjector() *Injector {
+	return &Injector{
+		faults: make(map[string]Fault),
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Set activates a fault, replacing any active fault at its target
+func (i *Injector) Set(fault Fault, now time.Time) (Fault, error) {
+	known := false
+	for _, target := range Targets {
+		known = known || target == fault.Target
+	}
+	if !known {
+		return Fault{}, fmt.Errorf("unknown fault target %q", fault.Target)
+	}
+	if fault.LatencyMS < 0 {
+		return Fault{}, fmt.Errorf("latency_ms must not be negative")
+	}
+	if fault.ErrorRate < 0 || fault.ErrorRate > 1 {
+		return Fault{}, fmt.Errorf("error_rate must be between 0 and 1")
+	}
+
+	ttl := DefaultTTL
+	if fault.TTLSeconds > 0 {
+		ttl = time.Duration(fault.TTLSeconds) * time.Second
+	}
+	if ttl > MaxTTL {
+		return Fault{}, fmt.Errorf("ttl_seconds must be at most %d", int(MaxTTL.Seconds()))
+	}
+	fault.TTLSeconds = int(ttl.Seconds())
+	fault.ExpiresAt = now.Add(ttl)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults[fault.Target] = fault
+
+	return fault, nil
+}
+
+// Clear deactivates the fault at a target. It returns false if none was active.
+func (i *Injector) Clear(target string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	_, ok := i.faults[target]
+	delete(i.faults, target)
+	return ok
+}
+
+// ClearAll deactivates every fault
+func (i *Injector) ClearAll() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults = make(map[string]Fault)
+}
+
+// List returns the active faults by target
+func (i *Injector) List(now time.Time) []Fault {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	active := make([]Fault, 0, len(i.faults))
+	for target, fault := range i.faults {
+		if !now.Before(fault.ExpiresAt) {
+			delete(i.faults, target)
+			continue
+		}
+		active = append(active, fault)
+	}
+	sort.Slice(active, func(a, b int) bool { return active[a].Target < active[b].Target })
+
+	return active
+}
+
+// Inject applies the active fault at a target: it waits out the latency,
+// or until ctx is done, and then fails at the fault's error rate
+func (i *Injector) Inject(ctx context.Context, target string) error {
+	if i == nil {
+		return nil
+	}
+
+	i.mu.Lock()
+	fault, ok := i.faults[target]
+	if ok && !time.Now().Before(fault.ExpiresAt) {
+		delete(i.faults, target)
+		ok = false
+	}
+	fail := ok && i.rand.Float64() < fault.ErrorRate
+	i.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	if fault.LatencyMS > 0 {
+		timer := time.NewTimer(time.Duration(fault.LatencyMS) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if fail {
+		return &Error{Target: target}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// anthropicVersion is the Messages API version requests are made against
+const anthropicVersion = "2023-06-01"
+
+// anthropic completes chats with the Anthropic Messages API
+type anthropic struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+func newAnthropic(baseURL, apiKey, model string, httpClient *http.Client) *anthropic {
+	return &anthropic{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: httpClient,
+	}
+}
+
+func (a *anthropic) Name() string { return ProviderAnthropic }
+
+func (a *anthropic) Model() string { return a.model }
+
+// anthropicResponse is the part of a message the client reads
+type anthropicResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// Complete sends a chat to the Messages API. The completion is the text of
+// every text block of the reply.
+func (a *anthropic) Complete(ctx context.Context, req *Request) (*Response, error) {
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
+	payload := map[string]interface{}{
+		"model":      a.model,
+		"messages":   req.Messages,
+		"max_tokens": maxTokens,
+	}
+	if req.System != "" {
+		payload["system"] = req.System
+	}
+	if req.Temperature != nil {
+		payload["temperature"] = *req.Temperature
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/messages", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("x-api-key", a.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, &StatusError{
+			Provider:   ProviderAnthropic,
+			StatusCode: resp.StatusCode,
+			RetryAfter: retryAfter(resp.Header),
+			Body:       string(detail),
+		}
+	}
+
+	var message anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&message); err != nil {
+		return nil, fmt.Errorf("failed to decode anthropic response: %w", err)
+	}
+
+	var content strings.Builder
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+
+	return &Response{
+		Provider:     ProviderAnthropic,
+		Model:        message.Model,
+		Content:      content.String(),
+		InputTokens:  message.Usage.InputTokens,
+		OutputTokens: message.Usage.OutputTokens,
+	}, nil
+}
@@ -0,0 +1,193 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimitedCooldown is how long a provider that returned 429 without a
+// Retry-After header is passed over
+const rateLimitedCooldown = 10 * time.Second
+
+// ProviderUsage counts the calls made to a provider since the client was
+// created
+type ProviderUsage struct {
+	Provider      string     `json:"provider"`
+	Model         string     `json:"model"`
+	Requests      int64      `json:"requests"`
+	Failures      int64      `json:"failures"`
+	RateLimited   int64      `json:"rate_limited"` // Failures with status 429
+	Fallbacks     int64      `json:"fallbacks"`    // Failures retried on the next provider
+	InputTokens   int64      `json:"input_tokens"` // As reported by the provider
+	OutputTokens  int64      `json:"output_tokens"`
+	AvgLatencyMS  float64    `json:"avg_latency_ms"` // Of every request, successful or not
+	CooldownUntil *time.Time `json:"cooldown_until,omitempty"`
+}
+
+// Client completes chats with the first of its providers that answers
+type Client struct {
+	providers []*providerState
+}
+
+// providerState is a provider with its rate limit, usage and cooldown
+type providerState struct {
+	provider Provider
+	limiter  *limiter
+
+	mu            sync.Mutex
+	usage         ProviderUsage
+	latency       time.Duration
+	cooldownUntil time.Time
+}
+
+// NewClient creates a client trying providers in order, each limited to
+// requestsPerMinute requests. Zero is unlimited.
+func NewClient(providers []Provider, requestsPerMinute int) *Client {
+	c := &Client{}
+	for _, provider := range providers {
+		c.providers = append(c.providers, &providerState{provider: provider, limiter: newLimiter(requestsPerMinute)})
+	}
+	return c
+}
+
+// Complete completes a chat. Providers cooling down after a 429 are passed
+// over while another is available. A provider that is rate limited, fails
+// with a server error or doesn't respond is fallen back from; other errors
+// are returned as is, as are those of the last provider.
+func (c *Client) Complete(ctx context.Context, req *Request) (*Response, error) {
+	now := time.Now()
+	available := make([]*providerState, 0, len(c.providers))
+	for _, p := range c.providers {
+		if !p.coolingDown(now) {
+			available = append(available, p)
+		}
+	}
+	if len(available) == 0 {
+		available = c.providers
+	}
+
+	var lastErr error
+	for i, p := range available {
+		if err := p.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := p.provider.Complete(ctx, req)
+		p.record(resp, err, time.Since(start))
+		if err == nil {
+			return resp, nil
+		}
+
+		lastErr = err
+		if !shouldFallBack(ctx, err) || i == len(available)-1 {
+			break
+		}
+		p.mu.Lock()
+		p.usage.Fallbacks++
+		p.mu.Unlock()
+		log.Printf("LLM provider %s failed, falling back to %s: %v", p.provider.Name(), available[i+1].provider.Name(), err)
+	}
+	return nil, lastErr
+}
+
+// Usage returns the usage of each provider, in fallback order
+func (c *Client) Usage() []ProviderUsage {
+	usage := make([]ProviderUsage, len(c.providers))
+	now := time.Now()
+	for i, p := range c.providers {
+		p.mu.Lock()
+		usage[i] = p.usage
+		usage[i].Provider = p.provider.Name()
+		usage[i].Model = p.provider.Model()
+		if p.usage.Requests > 0 {
+			usage[i].AvgLatencyMS = float64(p.latency.Milliseconds()) / float64(p.usage.Requests)
+		}
+		if p.cooldownUntil.After(now) {
+			until := p.cooldownUntil.UTC()
+			usage[i].CooldownUntil = &until
+		}
+		p.mu.Unlock()
+	}
+	return usage
+}
+
+// coolingDown reports whether the provider is being passed over after a 429
+func (p *providerState) coolingDown(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return now.Before(p.cooldownUntil)
+}
+
+// record counts a request, and starts a cooldown when it was rate limited
+func (p *providerState) record(resp *Response, err error, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.usage.Requests++
+	p.latency += latency
+	if err == nil {
+		p.usage.InputTokens += int64(resp.InputTokens)
+		p.usage.OutputTokens += int64(resp.OutputTokens)
+		return
+	}
+
+	p.usage.Failures++
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests {
+		p.usage.RateLimited++
+		cooldown := statusErr.RetryAfter
+		if cooldown <= 0 {
+			cooldown = rateLimitedCooldown
+		}
+		p.cooldownUntil = time.Now().Add(cooldown)
+	}
+}
+
+// limiter spaces requests evenly to stay under a rate
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration // Zero when unlimited
+	next     time.Time     // When the next request may start
+}
+
+// newLimiter allows perMinute requests a minute
+func newLimiter(perMinute int) *limiter {
+	l := &limiter{}
+	if perMinute > 0 {
+		l.interval = time.Minute / time.Duration(perMinute)
+	}
+	return l
+}
+
+// wait blocks until a request may start or ctx ends
+func (l *limiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,155 @@
+// Package llm calls LLM providers for chat completions.
+//
+// A Client tries the configured provider first and falls back to the other
+// providers with credentials when a call is rate limited, fails with a server
+// error or doesn't get a response. Each provider's requests are rate limited
+// and its usage counted.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ai-agent-eval/internal/config"
+)
+
+// Supported LLM providers
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+)
+
+// DefaultModels are the models providers fall back to when
+// LLM_FALLBACK_MODELS doesn't name one
+var DefaultModels = map[string]string{
+	ProviderOpenAI:    "gpt-4o-mini",
+	ProviderAnthropic: "claude-3-5-haiku-latest",
+}
+
+// defaultMaxTokens caps completions of requests that don't set MaxTokens
+const defaultMaxTokens = 1024
+
+// Message is one message of a chat
+type Message struct {
+	Role    string `json:"role"` // user or assistant
+	Content string `json:"content"`
+}
+
+// Request asks for a completion of a chat, on whichever model the provider
+// answering it is configured with
+type Request struct {
+	System      string
+	Messages    []Message
+	MaxTokens   int
+	Temperature *float64
+}
+
+// Response is a completion and the tokens it took
+type Response struct {
+	Provider     string `json:"provider"`
+	Model        string `json:"model"`
+	Content      string `json:"content"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+}
+
+// Provider completes chats with one LLM provider's API, on one model
+type Provider interface {
+	Name() string
+	Model() string
+	Complete(ctx context.Context, req *Request) (*Response, error)
+}
+
+// StatusError is an error status returned by a provider's API
+type StatusError struct {
+	Provider   string
+	StatusCode int
+	RetryAfter time.Duration // From the Retry-After header; 0 when absent
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned status %d: %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// Retryable reports whether another provider may succeed where this one
+// failed: the request was rate limited or hit a server error
+func (e *StatusError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// retryAfter parses a Retry-After header given in seconds
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// shouldFallBack reports whether a failed call may be retried on the next
+// provider. Calls whose context ended are not.
+func shouldFallBack(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Retryable()
+	}
+	// Transport errors and timeouts
+	return true
+}
+
+// NewProvider creates a provider's client for a model
+func NewProvider(name, model string, cfg *config.Config) (Provider, error) {
+	httpClient := &http.Client{Timeout: cfg.LLMTimeout}
+	switch name {
+	case ProviderOpenAI:
+		if cfg.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("openai provider requires OPENAI_API_KEY")
+		}
+		return newOpenAI(cfg.OpenAIBaseURL, cfg.OpenAIAPIKey, model, httpClient), nil
+	case ProviderAnthropic:
+		if cfg.AnthropicAPIKey == "" {
+			return nil, fmt.Errorf("anthropic provider requires ANTHROPIC_API_KEY")
+		}
+		return newAnthropic(cfg.AnthropicBaseURL, cfg.AnthropicAPIKey, model, httpClient), nil
+	}
+	return nil, fmt.Errorf("unsupported LLM provider %q", name)
+}
+
+// New creates a client for the configured provider and model, falling back
+// to the other providers with an API key. It returns nil when no provider
+// has one.
+func New(cfg *config.Config) (*Client, error) {
+	var providers []Provider
+	primary, err := NewProvider(cfg.LLMProvider, cfg.LLMModel, cfg)
+	if err == nil {
+		providers = append(providers, primary)
+	} else if cfg.LLMProvider != ProviderOpenAI && cfg.LLMProvider != ProviderAnthropic {
+		return nil, err
+	}
+
+	for _, name := range []string{ProviderOpenAI, ProviderAnthropic} {
+		if name == cfg.LLMProvider {
+			continue
+		}
+		model := cfg.LLMFallbackModels[name]
+		if model == "" {
+			model = DefaultModels[name]
+		}
+		if fallback, err := NewProvider(name, model, cfg); err == nil {
+			providers = append(providers, fallback)
+		}
+	}
+
+	if len(providers) == 0 {
+		return nil, nil
+	}
+	return NewClient(providers, cfg.LLMRequestsPerMinute), nil
+}
@@ -6,21 +6,54 @@ import (
 	"time"
 )
 
+// Turn roles
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleSystem    = "system"
+	RoleTool      = "tool"
+	RoleFunction  = "function" // Legacy name for tool results
+)
+
 // ToolCall represents a tool call made by the agent
 type ToolCall struct {
+	ID         string                 `json:"id,omitempty"`
 	ToolName   string                 `json:"tool_name"`
 	Parameters map[string]interface{} `json:"parameters"`
 	Result     map[string]interface{} `json:"result,omitempty"`
 	LatencyMS  int                    `json:"latency_ms,omitempty"`
 }
 
-// Turn represents a single turn in a conversation
+// Attachment describes an image or file attached to a turn. Content sent
+// inline as base64 Data is stored in the attachment blob store at ingestion
+// and replaced by a blob:// URL; otherwise only metadata is stored and the
+// content stays wherever URL points.
+type Attachment struct {
+	Type      string `json:"type" binding:"required,oneof=image file audio video"`
+	MimeType  string `json:"mime_type,omitempty"`
+	Name      string `json:"name,omitempty"`
+	URL       string `json:"url,omitempty"`
+	SizeBytes int64  `json:"size_bytes,omitempty"`
+	SHA256    string `json:"sha256,omitempty"` // Hex digest of the content
+	Data      string `json:"data,omitempty"`   // Base64 content, only on ingestion
+}
+
+// Turn represents a single turn in a conversation.
+// Tool and function turns carry a tool result; ToolCallID links them to the
+// assistant tool call they answer.
 type Turn struct {
-	TurnID    int        `json:"turn_id"`
-	Role      string     `json:"role"`
-	Content   string     `json:"content"`
-	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
-	Timestamp time.Time  `json:"timestamp"`
+	TurnID       int                    `json:"turn_id"`
+	Role         string                 `json:"role" binding:"required,oneof=user assistant system tool function"`
+	Content      string                 `json:"content"`
+	ToolCalls    []ToolCall             `json:"tool_calls,omitempty"`
+	ToolCallID   string                 `json:"tool_call_id,omitempty"`
+	Name         string                 `json:"name,omitempty"`
+	Result       map[string]interface{} `json:"result,omitempty"`
+	Attachments  []Attachment           `json:"attachments,omitempty" binding:"dive"`
+	Model        string                 `json:"model,omitempty"`                        // LLM that produced the turn, if not the conversation's
+	InputTokens  int                    `json:"input_tokens,omitempty" binding:"min=0"` // Reported by the provider for the call that produced the turn
+	OutputTokens int                    `json:"output_tokens,omitempty" binding:"min=0"`
+	Timestamp    time.Time              `json:"timestamp"`
 }
 
 // OpsReview represents an operations review
@@ -43,12 +76,21 @@ type Feedback struct {
 	UserRating  int              `json:"user_rating,omitempty"`
 	OpsReview   *OpsReview       `json:"ops_review,omitempty"`
 	Annotations []AnnotationItem `json:"annotations,omitempty"`
+	Comment     string           `json:"comment,omitempty"`
 }
 
 // ConversationMetadata represents conversation metadata
 type ConversationMetadata struct {
-	TotalLatencyMS   int  `json:"total_latency_ms,omitempty"`
-	MissionCompleted bool `json:"mission_completed,omitempty"`
+	TotalLatencyMS   int               `json:"total_latency_ms,omitempty"`
+	MissionCompleted bool              `json:"mission_completed,omitempty"`
+	Model            string            `json:"model,omitempty"`         // LLM the agent ran on
+	ToolVersions     map[string]string `json:"tool_versions,omitempty"` // Version of each tool, by tool name
+	SessionID        string            `json:"session_id,omitempty"`    // Links the conversations of one session into a journey
+	SubjectID        string            `json:"subject_id,omitempty"`    // Links the conversations of one end user into a journey
+	Escalated        bool              `json:"escalated,omitempty"`     // Handed off to a human or another tier
+	DatasetItem      string            `json:"dataset_item,omitempty"`  // Key of the dataset item the conversation was produced from, for regression runs
+	InputTokens      int               `json:"input_tokens,omitempty" binding:"min=0"`
+	OutputTokens     int               `json:"output_tokens,omitempty" binding:"min=0"`
 }
 
 // Conversation represents a conversation to be evaluated
@@ -58,17 +100,68 @@ type Conversation struct {
 	AgentVersion   string               `json:"agent_version" db:"agent_version"`
 	Turns          json.RawMessage      `json:"turns" db:"turns"`
 	Metadata       json.RawMessage      `json:"metadata" db:"metadata"`
+	Intent         string               `json:"intent" db:"intent"`
+	ProjectID      string               `json:"project_id" db:"project_id"`
+	TokenCounts    json.RawMessage      `json:"token_counts" db:"token_counts"` // TokenCounts, estimated at ingestion
+	CostUSD        *float64             `json:"cost_usd,omitempty" db:"cost_usd"`
 	CreatedAt      time.Time            `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time            `json:"updated_at" db:"updated_at"`
 }
 
+// TurnTokenCount is the estimated token count of a turn. Total includes the
+// tokens chat formats add around each message.
+type TurnTokenCount struct {
+	TurnID      int    `json:"turn_id"`
+	Role        string `json:"role"`
+	Content     int    `json:"content"`
+	ToolCalls   int    `json:"tool_calls"`   // Tool names and arguments
+	ToolResults int    `json:"tool_results"` // Tool result payloads
+	Total       int    `json:"total"`
+}
+
+// TokenCounts is the estimated token count of a conversation's turns, using
+// the tokenizer of the model the agent ran on
+type TokenCounts struct {
+	Tokenizer   string           `json:"tokenizer"`
+	Turns       []TurnTokenCount `json:"turns"`
+	Content     int              `json:"content"`
+	ToolCalls   int              `json:"tool_calls"`
+	ToolResults int              `json:"tool_results"`
+	Total       int              `json:"total"`
+	// Token counts reported by the agent, from its turns or else its
+	// metadata; zero when it reports none
+	InputTokens  int `json:"input_tokens,omitempty"`
+	OutputTokens int `json:"output_tokens,omitempty"`
+}
+
+// EvaluationSummary is the denormalized latest evaluation state of a conversation
+type EvaluationSummary struct {
+	LatestEvaluationID string    `json:"latest_evaluation_id" db:"latest_evaluation_id"`
+	LatestOverallScore float64   `json:"latest_overall_score" db:"latest_overall_score"`
+	OpenIssueCount     int       `json:"open_issue_count" db:"open_issue_count"`
+	CriticalIssueCount int       `json:"critical_issue_count" db:"critical_issue_count"`
+	SeverityCounts     json.RawMessage `json:"severity_counts" db:"severity_counts"` // Issues by severity
+	HealthScore        *float64  `json:"health_score,omitempty" db:"health_score"`
+	LatestVerdict      string    `json:"latest_verdict,omitempty" db:"latest_verdict"`
+	NeedsHumanReview   bool      `json:"needs_human_review" db:"-"`
+	Priority           string    `json:"priority" db:"-"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ConversationWithSummary is a conversation list row with its evaluation summary
+type ConversationWithSummary struct {
+	Conversation
+	EvaluationSummary *EvaluationSummary `json:"evaluation_summary"`
+}
+
 // ConversationCreate represents the input for creating a conversation
 type ConversationCreate struct {
 	ConversationID string               `json:"conversation_id" binding:"required"`
 	AgentVersion   string               `json:"agent_version" binding:"required"`
-	Turns          []Turn               `json:"turns" binding:"required,min=1"`
+	Turns          []Turn               `json:"turns" binding:"required,min=1,dive"`
 	Feedback       *Feedback            `json:"feedback,omitempty"`
 	Metadata       *ConversationMetadata `json:"metadata,omitempty"`
+	Intent         string               `json:"intent,omitempty"` // Classified at ingestion when empty
 }
 
 // EvaluationScores represents evaluation scores
@@ -109,18 +202,99 @@ type Evaluation struct {
 	ID                     int64           `json:"id" db:"id"`
 	EvaluationID           string          `json:"evaluation_id" db:"evaluation_id"`
 	ConversationID         string          `json:"conversation_id" db:"conversation_id"`
+	ProjectID              string          `json:"project_id" db:"project_id"`
 	OverallScore           float64         `json:"overall_score" db:"overall_score"`
 	ResponseQualityScore   float64         `json:"response_quality_score" db:"response_quality_score"`
 	ToolAccuracyScore      float64         `json:"tool_accuracy_score" db:"tool_accuracy_score"`
 	CoherenceScore         float64         `json:"coherence_score" db:"coherence_score"`
 	ToolEvaluation         json.RawMessage `json:"tool_evaluation" db:"tool_evaluation"`
 	IssuesDetected         json.RawMessage `json:"issues_detected" db:"issues_detected"`
+	RawIssuesDetected      json.RawMessage `json:"raw_issues_detected" db:"raw_issues_detected"`
 	ImprovementSuggestions json.RawMessage `json:"improvement_suggestions" db:"improvement_suggestions"`
 	EvaluatorVersion       string          `json:"evaluator_version" db:"evaluator_version"`
 	EvaluationDurationMS   int             `json:"evaluation_duration_ms" db:"evaluation_duration_ms"`
+	TaskID                 string          `json:"task_id" db:"task_id"`
+	TriggerSource          string          `json:"trigger_source" db:"trigger_source"`
+	FailedEvaluators       json.RawMessage `json:"failed_evaluators" db:"failed_evaluators"`
+	ComponentScores        json.RawMessage `json:"component_scores" db:"component_scores"` // Scores by component, including unweighted ones
+	Partial                bool            `json:"partial" db:"partial"`
+	SkippedEvaluators      json.RawMessage `json:"skipped_evaluators" db:"skipped_evaluators"`
+	EvaluationCost         float64         `json:"evaluation_cost" db:"evaluation_cost"` // Estimated; 0 unless a budget applied
+	Signature              string          `json:"signature,omitempty" db:"signature"`
+	SignatureAlgorithm     string          `json:"signature_algorithm,omitempty" db:"signature_algorithm"`
+	Manifest               json.RawMessage `json:"manifest,omitempty" db:"manifest"`         // EvaluationManifest
+	Verdict                string          `json:"verdict" db:"verdict"`                     // Empty for evaluations stored before verdicts existed
+	VerdictRule            string          `json:"verdict_rule,omitempty" db:"verdict_rule"` // Policy rule that decided the verdict; empty for the default
 	CreatedAt              time.Time       `json:"created_at" db:"created_at"`
 }
 
+// EvaluationSearchResult is an evaluation whose issues or suggestions match
+// a text search
+type EvaluationSearchResult struct {
+	EvaluationID   string    `json:"evaluation_id" db:"evaluation_id"`
+	ConversationID string    `json:"conversation_id" db:"conversation_id"`
+	OverallScore   float64   `json:"overall_score" db:"overall_score"`
+	Verdict        string    `json:"verdict" db:"verdict"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	Rank           float64   `json:"rank" db:"rank"`
+	Headline       string    `json:"headline" db:"headline"` // Matching text with matches marked <<like this>>
+}
+
+// EvaluationManifest records everything that influenced an evaluation's
+// scores, so they can be reproduced or explained later. Evaluations with the
+// same ConfigHash were scored with the same configuration.
+type EvaluationManifest struct {
+	ConfigHash          string             `json:"config_hash"`
+	EvaluatorTypes      []string           `json:"evaluator_types"`
+	EvaluatorVersions   map[string]string  `json:"evaluator_versions"`  // Requested versions; other types ran the service's default
+	EvaluatorVersion    string             `json:"evaluator_version"`   // Reported by the evaluator service
+	RubricID            string             `json:"rubric_id,omitempty"` // From the conversation's metadata
+	ScoreWeights        map[string]float64 `json:"score_weights"`       // Weight of each component in the overall score
+	JudgeModel          string             `json:"judge_model"`
+	Tokenizer           string             `json:"tokenizer"`
+	Truncation          ManifestTruncation `json:"truncation"`
+	Budget              *EvaluationBudget  `json:"budget,omitempty"`
+	EvaluatorTimeoutsMS map[string]int     `json:"evaluator_timeouts_ms,omitempty"`
+	External            bool               `json:"external"` // Evaluated by an external consumer, which reports only its results
+}
+
+// ManifestTruncation is how a conversation was cut down for evaluation
+type ManifestTruncation struct {
+	FromTurnID          int `json:"from_turn_id,omitempty"` // Earlier turns were not evaluated
+	Turns               int `json:"turns"`                  // Turns sent to the evaluators
+	SegmentMaxTurns     int `json:"segment_max_turns"`
+	SegmentOverlapTurns int `json:"segment_overlap_turns"`
+	SegmentMaxTokens    int `json:"segment_max_tokens"`
+	Segments            int `json:"segments,omitempty"` // Set when the conversation was split
+}
+
+// ReevaluationBaseline is a scoring configuration seen by the stale
+// re-evaluation policy, and its progress re-evaluating a sample of
+// conversations last evaluated under an earlier configuration
+type ReevaluationBaseline struct {
+	Fingerprint string          `json:"fingerprint" db:"fingerprint"`
+	Manifest    json.RawMessage `json:"manifest" db:"manifest"` // Of the evaluation it was first seen on
+	Target      int             `json:"target" db:"target"`     // Conversations to re-evaluate
+	Enqueued    int             `json:"enqueued" db:"enqueued"`
+	Evaluated   int             `json:"evaluated" db:"evaluated"` // Sampled conversations evaluated since they were queued
+	DetectedAt  time.Time       `json:"detected_at" db:"detected_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty" db:"completed_at"` // Set once sampling ends or a newer configuration supersedes it
+}
+
+// StaleConversation is a conversation sampled for re-evaluation
+type StaleConversation struct {
+	ConversationID string `db:"conversation_id"`
+	ProjectID      string `db:"project_id"`
+}
+
+// ManifestChange is a manifest field that differs from the previous
+// evaluation of the same conversation
+type ManifestChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
 // EvaluationResponse represents the full evaluation response
 type EvaluationResponse struct {
 	EvaluationID           string                  `json:"evaluation_id"`
@@ -128,11 +302,89 @@ type EvaluationResponse struct {
 	Scores                 EvaluationScores        `json:"scores"`
 	ToolEvaluation         *ToolEvaluation         `json:"tool_evaluation,omitempty"`
 	IssuesDetected         []IssueDetected         `json:"issues_detected"`
+	RawIssuesDetected      []IssueDetected         `json:"raw_issues_detected,omitempty"`
 	ImprovementSuggestions []ImprovementSuggestion `json:"improvement_suggestions"`
+	EvaluatorVersion       string                  `json:"evaluator_version,omitempty"`
 	EvaluationDurationMS   int                     `json:"evaluation_duration_ms,omitempty"`
+	TaskID                 string                  `json:"task_id,omitempty"`
+	TriggerSource          string                  `json:"trigger_source,omitempty"`
+	Partial                bool                    `json:"partial"`
+	FailedEvaluators       []EvaluatorFailure      `json:"failed_evaluators,omitempty"` // Components missing from a partial result
+	SkippedEvaluators      []SkippedEvaluator      `json:"skipped_evaluators,omitempty"`
+	EvaluationCost         float64                 `json:"evaluation_cost,omitempty"`
+	Signature              string                  `json:"signature,omitempty"`
+	SignatureAlgorithm     string                  `json:"signature_algorithm,omitempty"`
+	Verdict                string                  `json:"verdict,omitempty"`
+	VerdictRule            string                  `json:"verdict_rule,omitempty"`
 	CreatedAt              time.Time               `json:"created_at"`
 }
 
+// NewEvaluationResponse builds the API representation of a stored evaluation
+func NewEvaluationResponse(eval *Evaluation) *EvaluationResponse {
+	// Parse JSON fields
+	var toolEval ToolEvaluation
+	var issues []IssueDetected
+	var rawIssues []IssueDetected
+	var suggestions []ImprovementSuggestion
+	var failed []EvaluatorFailure
+	var skipped []SkippedEvaluator
+
+	json.Unmarshal(eval.ToolEvaluation, &toolEval)
+	json.Unmarshal(eval.IssuesDetected, &issues)
+	json.Unmarshal(eval.RawIssuesDetected, &rawIssues)
+	json.Unmarshal(eval.ImprovementSuggestions, &suggestions)
+	json.Unmarshal(eval.FailedEvaluators, &failed)
+	json.Unmarshal(eval.SkippedEvaluators, &skipped)
+
+	return &EvaluationResponse{
+		EvaluationID:   eval.EvaluationID,
+		ConversationID: eval.ConversationID,
+		Scores: EvaluationScores{
+			Overall:         eval.OverallScore,
+			ResponseQuality: eval.ResponseQualityScore,
+			ToolAccuracy:    eval.ToolAccuracyScore,
+			Coherence:       eval.CoherenceScore,
+		},
+		ToolEvaluation:         &toolEval,
+		IssuesDetected:         issues,
+		RawIssuesDetected:      rawIssues,
+		ImprovementSuggestions: suggestions,
+		EvaluatorVersion:       eval.EvaluatorVersion,
+		EvaluationDurationMS:   eval.EvaluationDurationMS,
+		TaskID:                 eval.TaskID,
+		TriggerSource:          eval.TriggerSource,
+		Partial:                eval.Partial,
+		FailedEvaluators:       failed,
+		SkippedEvaluators:      skipped,
+		EvaluationCost:         eval.EvaluationCost,
+		Signature:              eval.Signature,
+		SignatureAlgorithm:     eval.SignatureAlgorithm,
+		Verdict:                eval.Verdict,
+		VerdictRule:            eval.VerdictRule,
+		CreatedAt:              eval.CreatedAt,
+	}
+}
+
+// EvaluatorFailure records an evaluator type that failed or timed out, so its
+// component is missing from an evaluation
+type EvaluatorFailure struct {
+	EvaluatorType string `json:"evaluator_type"`
+	Error         string `json:"error"`
+	TimedOut      bool   `json:"timed_out,omitempty"`
+}
+
+// DebugTurnFeedback is the feedback on one turn of an interactive debugging
+// session. NewIssues holds the issues no earlier turn's feedback reported.
+// Error is set instead when the turn was rejected or couldn't be evaluated.
+type DebugTurnFeedback struct {
+	TurnID           int                      `json:"turn_id"`
+	Scores           map[string]float64       `json:"scores,omitempty"`
+	NewIssues        []map[string]interface{} `json:"new_issues"`
+	FailedEvaluators []EvaluatorFailure       `json:"failed_evaluators,omitempty"`
+	DurationMS       int                      `json:"duration_ms,omitempty"`
+	Error            string                   `json:"error,omitempty"`
+}
+
 // FeedbackRecord represents stored feedback
 type FeedbackRecord struct {
 	ID             int64           `json:"id" db:"id"`
@@ -140,6 +392,10 @@ type FeedbackRecord struct {
 	UserRating     sql.NullInt32   `json:"user_rating" db:"user_rating"`
 	OpsReview      json.RawMessage `json:"ops_review" db:"ops_review"`
 	Annotations    json.RawMessage `json:"annotations" db:"annotations"`
+	Comment        string          `json:"comment" db:"comment"`
+	Sentiment      string          `json:"sentiment" db:"sentiment"`
+	SentimentScore sql.NullFloat64 `json:"sentiment_score" db:"sentiment_score"`
+	Themes         json.RawMessage `json:"themes" db:"themes"`
 	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
 }
 
@@ -149,24 +405,75 @@ type Annotation struct {
 	ConversationID   string          `json:"conversation_id" db:"conversation_id"`
 	AnnotatorID      string          `json:"annotator_id" db:"annotator_id"`
 	AnnotationType   string          `json:"annotation_type" db:"annotation_type"`
-	Label            string          `json:"label" db:"label"`
+	Label            string          `json:"label" db:"label"`   // Labels joined by commas
+	Labels           json.RawMessage `json:"labels" db:"labels"` // Label paths, such as tool_error/timeout
+	ProjectID        string          `json:"project_id" db:"project_id"`
 	Score            sql.NullFloat64 `json:"score" db:"score"`
 	Confidence       sql.NullFloat64 `json:"confidence" db:"confidence"`
 	Notes            sql.NullString  `json:"notes" db:"notes"`
 	TimeSpentSeconds sql.NullInt32   `json:"time_spent_seconds" db:"time_spent_seconds"`
+	Assisted         sql.NullBool    `json:"assisted" db:"assisted"`                           // Assisted labeling arm; null outside assisted labeling
+	SuggestedLabels  json.RawMessage `json:"suggested_labels,omitempty" db:"suggested_labels"` // Machine suggestion when annotated, shown or not
 	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
 }
 
+// AdjudicatedConversation is a conversation with its final (majority) annotation label
+type AdjudicatedConversation struct {
+	ConversationID string          `json:"conversation_id" db:"conversation_id"`
+	FinalLabel     string          `json:"final_label" db:"final_label"`
+	Turns          json.RawMessage `json:"turns" db:"turns"`
+}
+
+// AnnotatorCandidate is an annotator who could be given an annotation task
+type AnnotatorCandidate struct {
+	AnnotatorID   string  `json:"annotator_id" db:"annotator_id"`
+	Specialized   bool    `json:"specialized" db:"specialized"` // Specializes in the task's annotation type
+	OpenTasks     int     `json:"open_tasks" db:"open_tasks"`
+	AgreementRate float64 `json:"agreement_rate" db:"agreement_rate"`
+}
+
+// ReferenceExample is a similar adjudicated conversation shown to annotators
+type ReferenceExample struct {
+	AdjudicatedConversation
+	Similarity float64 `json:"similarity"`
+}
+
+// AnnotatorPerformance represents aggregated performance of an annotator
+type AnnotatorPerformance struct {
+	ID                    int64           `json:"id" db:"id"`
+	AnnotatorID           string          `json:"annotator_id" db:"annotator_id"`
+	TotalAnnotations      int             `json:"total_annotations" db:"total_annotations"`
+	AgreementRate         *float64        `json:"agreement_rate" db:"agreement_rate"`
+	ConsistencyScore      *float64        `json:"consistency_score" db:"consistency_score"`
+	AccuracyVsGroundTruth *float64        `json:"accuracy_vs_ground_truth" db:"accuracy_vs_ground_truth"`
+	Specializations       json.RawMessage `json:"specializations" db:"specializations"`
+	UpdatedAt             time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// AnnotatorTypeAccuracy represents how often an annotator agreed with the
+// majority label for one annotation type
+type AnnotatorTypeAccuracy struct {
+	AnnotatorID    string `db:"annotator_id"`
+	AnnotationType string `db:"annotation_type"`
+	Total          int    `db:"total"`
+	Agreed         int    `db:"agreed"`
+}
+
 // AnnotationCreate represents input for creating annotation
 type AnnotationCreate struct {
 	ConversationID   string   `json:"conversation_id" binding:"required"`
 	AnnotatorID      string   `json:"annotator_id" binding:"required"`
 	AnnotationType   string   `json:"annotation_type" binding:"required"`
-	Label            string   `json:"label" binding:"required"`
+	Label            string   `json:"label,omitempty"`  // A single label; label or labels is required
+	Labels           []string `json:"labels,omitempty"` // Several labels, each of which may be a path such as tool_error/timeout
 	Score            *float64 `json:"score,omitempty"`
 	Confidence       *float64 `json:"confidence,omitempty"`
 	Notes            string   `json:"notes,omitempty"`
 	TimeSpentSeconds int      `json:"time_spent_seconds,omitempty"`
+
+	// Set by the server for projects using assisted labeling
+	Assisted        *bool    `json:"-"`
+	SuggestedLabels []string `json:"-"`
 }
 
 // FailurePattern represents a detected failure pattern
@@ -184,10 +491,22 @@ type FailurePattern struct {
 	Resolved             bool            `json:"resolved" db:"resolved"`
 	ResolutionNotes      sql.NullString  `json:"resolution_notes" db:"resolution_notes"`
 	RelatedSuggestionID  sql.NullString  `json:"related_suggestion_id" db:"related_suggestion_id"`
+	Owner                sql.NullString  `json:"owner" db:"owner"`
+	ExternalProvider     sql.NullString  `json:"external_provider" db:"external_provider"`
+	ExternalIssueKey     sql.NullString  `json:"external_issue_key" db:"external_issue_key"`
+	ExternalIssueURL     sql.NullString  `json:"external_issue_url" db:"external_issue_url"`
+	ExternalSyncedAt     sql.NullTime    `json:"external_synced_at" db:"external_synced_at"`
+	SlackNotifiedAt      sql.NullTime    `json:"slack_notified_at" db:"slack_notified_at"`
 	CreatedAt            time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time       `json:"updated_at" db:"updated_at"`
 }
 
+// SimilarPattern represents a failure pattern related to another one
+type SimilarPattern struct {
+	Pattern    FailurePattern `json:"pattern"`
+	Similarity float64        `json:"similarity"`
+}
+
 // StoredSuggestion represents a stored improvement suggestion
 type StoredSuggestion struct {
 	ID                    int64           `json:"id" db:"id"`
@@ -204,6 +523,7 @@ type StoredSuggestion struct {
 	ImpactMeasured        bool            `json:"impact_measured" db:"impact_measured"`
 	BeforeMetrics         json.RawMessage `json:"before_metrics" db:"before_metrics"`
 	AfterMetrics          json.RawMessage `json:"after_metrics" db:"after_metrics"`
+	ProjectID             string          `json:"project_id" db:"project_id"`
 	CreatedAt             time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt             time.Time       `json:"updated_at" db:"updated_at"`
 }
@@ -225,27 +545,264 @@ type EvaluatorCalibration struct {
 	UpdatedAt           time.Time       `json:"updated_at" db:"updated_at"`
 }
 
+// Evaluator rollout statuses
+const (
+	RolloutActive     = "active"
+	RolloutPromoted   = "promoted"
+	RolloutRolledBack = "rolled_back"
+)
+
+// EvaluatorRollout represents an A/B test of a candidate evaluator version
+type EvaluatorRollout struct {
+	ID               int64          `json:"id" db:"id"`
+	EvaluatorType    string         `json:"evaluator_type" db:"evaluator_type"`
+	IncumbentVersion string         `json:"incumbent_version" db:"incumbent_version"`
+	CandidateVersion string         `json:"candidate_version" db:"candidate_version"`
+	TrafficPercent   float64        `json:"traffic_percent" db:"traffic_percent"`
+	MinSamples       int            `json:"min_samples" db:"min_samples"`
+	MinMargin        float64        `json:"min_margin" db:"min_margin"` // Correlation difference needed to decide
+	Status           string         `json:"status" db:"status"`
+	DecisionReason   sql.NullString `json:"decision_reason" db:"decision_reason"`
+	DecidedAt        sql.NullTime   `json:"decided_at" db:"decided_at"`
+	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// EvaluatorRolloutCreate represents input for starting a rollout
+type EvaluatorRolloutCreate struct {
+	EvaluatorType    string   `json:"evaluator_type" binding:"required"`
+	IncumbentVersion string   `json:"incumbent_version" binding:"required"`
+	CandidateVersion string   `json:"candidate_version" binding:"required"`
+	TrafficPercent   float64  `json:"traffic_percent" binding:"required,gt=0,lte=100"`
+	MinSamples       int      `json:"min_samples,omitempty"`
+	MinMargin        *float64 `json:"min_margin,omitempty" binding:"omitempty,gte=0,lte=2"` // Defaults to ROLLOUT_MIN_MARGIN
+}
+
+// EvaluatorRecording is a sampled request to the evaluator service and the
+// response it returned. Response is null when the call failed before a
+// response was received or the response wasn't JSON.
+type EvaluatorRecording struct {
+	ID               int64           `json:"id" db:"id"`
+	ConversationID   string          `json:"conversation_id" db:"conversation_id"`
+	EvaluatorVersion string          `json:"evaluator_version" db:"evaluator_version"`
+	Request          json.RawMessage `json:"request" db:"request"`
+	Response         json.RawMessage `json:"response" db:"response"`
+	StatusCode       int             `json:"status_code" db:"status_code"`
+	Error            string          `json:"error,omitempty" db:"error"`
+	DurationMS       int             `json:"duration_ms" db:"duration_ms"`
+	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
+}
+
 // SystemStats represents system statistics
 type SystemStats struct {
-	TotalConversations      int      `json:"total_conversations"`
-	TotalEvaluations        int      `json:"total_evaluations"`
-	TotalAnnotations        int      `json:"total_annotations"`
-	AverageQualityScore     *float64 `json:"average_quality_score"`
-	AverageUserRating       *float64 `json:"average_user_rating"`
-	OpenIssuesCount         int      `json:"open_issues_count"`
-	PendingSuggestionsCount int      `json:"pending_suggestions_count"`
-	EvaluationsLast24H      int      `json:"evaluations_last_24h"`
+	TotalConversations      int       `json:"total_conversations"`
+	TotalEvaluations        int       `json:"total_evaluations"`
+	TotalAnnotations        int       `json:"total_annotations"`
+	AverageQualityScore     *float64  `json:"average_quality_score"`
+	AverageUserRating       *float64  `json:"average_user_rating"`
+	OpenIssuesCount         int       `json:"open_issues_count"`
+	PendingSuggestionsCount int       `json:"pending_suggestions_count"`
+	EvaluationsLast24H      int       `json:"evaluations_last_24h"`
+	AsOf                    time.Time `json:"as_of"`
+}
+
+// ToolLatencyStats represents SLA breach statistics for a tool
+type ToolLatencyStats struct {
+	ToolName          string  `json:"tool_name" db:"tool_name"`
+	ExpectedLatencyMS int     `json:"expected_latency_ms" db:"expected_latency_ms"`
+	TotalCalls        int     `json:"total_calls" db:"total_calls"`
+	Breaches          int     `json:"breaches" db:"breaches"`
+	BreachRate        float64 `json:"breach_rate" db:"-"`
+	AvgLatencyMS      float64 `json:"avg_latency_ms" db:"avg_latency_ms"`
+}
+
+// ThroughputBucket represents ingestion and evaluation volume for one time bucket
+type ThroughputBucket struct {
+	Bucket                  time.Time `json:"bucket" db:"bucket"`
+	ConversationsIngested   int       `json:"conversations_ingested" db:"conversations_ingested"`
+	EvaluationsCompleted    int       `json:"evaluations_completed" db:"evaluations_completed"`
+	AvgEvaluationDurationMS *float64  `json:"avg_evaluation_duration_ms" db:"avg_evaluation_duration_ms"`
+}
+
+// TimeSeriesBucket aggregates a metric over the evaluations of one period.
+// Statistics are nil for periods without evaluations.
+type TimeSeriesBucket struct {
+	Bucket time.Time `json:"bucket" db:"bucket"`
+	Count  int       `json:"count" db:"count"`
+	Avg    *float64  `json:"avg" db:"avg"`
+	Min    *float64  `json:"min" db:"min"`
+	Max    *float64  `json:"max" db:"max"`
+	P50    *float64  `json:"p50" db:"p50"`
+	P90    *float64  `json:"p90" db:"p90"`
+	P95    *float64  `json:"p95" db:"p95"`
+	P99    *float64  `json:"p99" db:"p99"`
+}
+
+// QualityBucket represents evaluation quality for one hour
+type QualityBucket struct {
+	Bucket      time.Time `json:"bucket" db:"bucket"`
+	Evaluations int       `json:"evaluations" db:"evaluations"`
+	AvgScore    float64   `json:"avg_score" db:"avg_score"`
+	IssueRate   float64   `json:"issue_rate" db:"issue_rate"` // Fraction of evaluations with issues
+}
+
+// AnomalyEvent records a statistically unusual value of a quality metric
+type AnomalyEvent struct {
+	ID          int64     `json:"id" db:"id"`
+	Metric      string    `json:"metric" db:"metric"`
+	Bucket      time.Time `json:"bucket" db:"bucket"`
+	Value       float64   `json:"value" db:"value"`
+	Expected    float64   `json:"expected" db:"expected"`
+	ZScore      float64   `json:"z_score" db:"z_score"`
+	Severity    string    `json:"severity" db:"severity"`
+	Evaluations int       `json:"evaluations" db:"evaluations"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// Evaluation pipeline stages, in the order a task passes through them
+const (
+	PipelineStageIngested         = "ingested"
+	PipelineStageQueued           = "queued"
+	PipelineStageDequeued         = "dequeued"
+	PipelineStageEvaluatorStarted = "evaluator_started"
+	PipelineStagePersisted        = "persisted"
+)
+
+// PipelineStages lists the evaluation pipeline stages in order
+var PipelineStages = []string{
+	PipelineStageIngested,
+	PipelineStageQueued,
+	PipelineStageDequeued,
+	PipelineStageEvaluatorStarted,
+	PipelineStagePersisted,
+}
+
+// Task journal states. A task is journaled as dequeued when a worker takes
+// it from the queue and leaves that state exactly once: completed in the
+// transaction that persists its evaluation, or retrying, dead-lettered,
+// requeued on shutdown or recovered after its worker stopped.
+const (
+	TaskStateDequeued     = "dequeued"
+	TaskStateCompleted    = "completed"
+	TaskStateRetrying     = "retrying"
+	TaskStateDeadLettered = "dead_lettered"
+	TaskStateRequeued     = "requeued"
+	TaskStateRecovered    = "recovered"
+)
+
+// TaskJournalEntry is the durable record of a dequeued task. Transitions
+// lists each state it entered with when and by which worker.
+type TaskJournalEntry struct {
+	TaskID         string          `json:"task_id" db:"task_id"`
+	Queue          string          `json:"queue" db:"queue"`
+	TaskType       string          `json:"task_type" db:"task_type"`
+	ConversationID string          `json:"conversation_id" db:"conversation_id"`
+	State          string          `json:"state" db:"state"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	Payload        json.RawMessage `json:"payload" db:"payload"` // The task as queued
+	EvaluationID   string          `json:"evaluation_id,omitempty" db:"evaluation_id"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	WorkerID       string          `json:"worker_id" db:"worker_id"`
+	Transitions    json.RawMessage `json:"transitions" db:"transitions"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// PipelineStageReport reports that an evaluation task reached a stage
+type PipelineStageReport struct {
+	Stage string    `json:"stage" binding:"required"`
+	At    time.Time `json:"at"` // Defaults to when the report is received
+}
+
+// PipelineSegmentLatency summarizes the time tasks took between two
+// consecutive pipeline stages, in milliseconds
+type PipelineSegmentLatency struct {
+	From  string  `json:"from" db:"from_stage"`
+	To    string  `json:"to" db:"to_stage"`
+	Count int     `json:"count" db:"count"`
+	P50MS float64 `json:"p50_ms" db:"p50_ms"`
+	P90MS float64 `json:"p90_ms" db:"p90_ms"`
+	P99MS float64 `json:"p99_ms" db:"p99_ms"`
+	MaxMS float64 `json:"max_ms" db:"max_ms"`
+}
+
+// SliceRow represents aggregated metric values for one combination of dimensions
+type SliceRow struct {
+	Dimensions map[string]string `json:"dimensions"`
+	Count      int               `json:"count"`
+	Avg        float64           `json:"avg"`
+	Min        float64           `json:"min"`
+	Max        float64           `json:"max"`
 }
 
 // AnnotatorAgreement represents agreement analysis result
+
 type AnnotatorAgreement struct {
-	ConversationID        string        `json:"conversation_id"`
-	AnnotationType        string        `json:"annotation_type"`
-	Annotators            []string      `json:"annotators"`
-	AgreementScore        float64       `json:"agreement_score"`
-	MajorityLabel         string        `json:"majority_label,omitempty"`
-	NeedsTiebreaker       bool          `json:"needs_tiebreaker"`
-	IndividualAnnotations []Annotation  `json:"individual_annotations"`
+	ConversationID        string             `json:"conversation_id"`
+	AnnotationType        string             `json:"annotation_type"`
+	Annotators            []string           `json:"annotators"`
+	AgreementScore        float64            `json:"agreement_score"`
+	AgreementMethod       string             `json:"agreement_method"` // majority, or jaccard once an annotation has several labels
+	MajorityLabel         string             `json:"majority_label,omitempty"`
+	MajorityLabels        []string           `json:"majority_labels,omitempty"`
+	NeedsTiebreaker       bool               `json:"needs_tiebreaker"`
+	IndividualAnnotations []Annotation       `json:"individual_annotations"`
+	Completion            AnnotationCoverage `json:"completion"`
+}
+
+// AnnotationCoverage reports whether a conversation has the independent
+// annotations its annotation type requires
+type AnnotationCoverage struct {
+	RequiredAnnotators  int      `json:"required_annotators"`
+	CompletedAnnotators int      `json:"completed_annotators"`
+	PendingAnnotators   []string `json:"pending_annotators"` // Assigned but not yet annotated
+	Complete            bool     `json:"complete"`
+}
+
+// AnnotationTask is a conversation assigned to an annotator for an
+// annotation type. It inherits the routing priority of the conversation when
+// assigned, and is due by DueAt if that priority has a deadline.
+type AnnotationTask struct {
+	ID             int64      `json:"id" db:"id"`
+	ConversationID string     `json:"conversation_id" db:"conversation_id"`
+	AnnotationType string     `json:"annotation_type" db:"annotation_type"`
+	AnnotatorID    string     `json:"annotator_id" db:"annotator_id"`
+	AssignedBy     string     `json:"assigned_by" db:"assigned_by"`
+	Status         string     `json:"status" db:"status"`
+	Priority       string     `json:"priority" db:"priority"`
+	DueAt          *time.Time `json:"due_at" db:"due_at"`
+	Overdue        bool       `json:"overdue" db:"overdue"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ReviewAgeBucket counts open annotation tasks of an age range by priority
+type ReviewAgeBucket struct {
+	Bucket     string         `json:"bucket"`
+	Total      int            `json:"total"`
+	ByPriority map[string]int `json:"by_priority"`
+}
+
+// AnnotatorReviewLoad is the open review work of an annotator
+type AnnotatorReviewLoad struct {
+	AnnotatorID    string  `json:"annotator_id"`
+	Open           int     `json:"open"`
+	Breached       int     `json:"breached"`
+	AtRisk         int     `json:"at_risk"`
+	OldestAgeHours float64 `json:"oldest_age_hours"`
+}
+
+// ReviewAgingReport describes the backlog of open annotation tasks: how old
+// they are, how many missed their deadline (breached) or are close to it
+// (at risk), and how the work is spread across annotators
+type ReviewAgingReport struct {
+	GeneratedAt        time.Time             `json:"generated_at"`
+	Open               int                   `json:"open"`
+	Buckets            []ReviewAgeBucket     `json:"buckets"`
+	Breached           int                   `json:"breached"`
+	BreachedByPriority map[string]int        `json:"breached_by_priority"`
+	AtRisk             int                   `json:"at_risk"`
+	Annotators         []AnnotatorReviewLoad `json:"annotators"`
 }
 
 // RoutingDecision represents routing decision for human review
@@ -256,12 +813,484 @@ type RoutingDecision struct {
 	RoutingReason          []string `json:"routing_reason"`
 	AutoLabel              bool     `json:"auto_label"`
 	SuggestedAnnotationTypes []string `json:"suggested_annotation_types"`
+	SuggestedAnnotators    []string `json:"suggested_annotators"`
+	ReviewDueAt            *time.Time `json:"review_due_at,omitempty"` // When a review assigned now would be due
 }
 
 // EvaluationRequest represents a request to evaluate
 type EvaluationRequest struct {
-	ConversationID string   `json:"conversation_id" binding:"required"`
-	EvaluatorTypes []string `json:"evaluator_types,omitempty"`
+	ConversationID string     `json:"conversation_id" binding:"required"`
+	EvaluatorTypes []string   `json:"evaluator_types,omitempty"`
+	Strict         bool       `json:"strict,omitempty"`
+	MaxCost        *float64   `json:"max_cost,omitempty"`     // Overrides the configured budget
+	ScheduledAt    *time.Time `json:"scheduled_at,omitempty"` // Queue at this time rather than now, e.g. after an evaluator calibration
+}
+
+// EvaluatorWarning explains why a requested evaluator will not run
+type EvaluatorWarning struct {
+	EvaluatorType string `json:"evaluator_type"`
+	Code          string `json:"code"`
+	Message       string `json:"message"`
+}
+
+// RoutingPolicy controls when conversations are routed to human review
+type RoutingPolicy struct {
+	LowScoreThreshold  float64 `json:"low_score_threshold" yaml:"low_score_threshold"`
+	AgreementThreshold float64 `json:"agreement_threshold" yaml:"agreement_threshold"`
+	HealthThreshold    float64 `json:"health_threshold" yaml:"health_threshold"`
+	// Independent annotations required per annotation type before a
+	// conversation is adjudicated or auto-labelled; unlisted types need one
+	MinAnnotators map[string]int `json:"min_annotators,omitempty" yaml:"min_annotators,omitempty"`
+	// Hours an annotation task has to be completed in, by routing priority;
+	// tasks of unlisted priorities have no deadline
+	ReviewDeadlineHours map[string]int `json:"review_deadline_hours,omitempty" yaml:"review_deadline_hours,omitempty"`
+	// Routing priority of conversations with issues of a severity; issues
+	// of unlisted severities don't route
+	SeverityPriorities map[string]string `json:"severity_priorities,omitempty" yaml:"severity_priorities,omitempty"`
+}
+
+// HealthPolicy weights the components of the conversation health metric
+type HealthPolicy struct {
+	AutomatedScoreWeight float64            `json:"automated_score_weight" yaml:"automated_score_weight"`
+	UserRatingWeight     float64            `json:"user_rating_weight" yaml:"user_rating_weight"`
+	IssueSeverityWeight  float64            `json:"issue_severity_weight" yaml:"issue_severity_weight"`
+	ToolFailureWeight    float64            `json:"tool_failure_weight" yaml:"tool_failure_weight"`
+	SeverityPenalties    map[string]float64 `json:"severity_penalties" yaml:"severity_penalties"`
+}
+
+// ConversationHealth is the stored composite health of a conversation
+type ConversationHealth struct {
+	ConversationID string          `json:"conversation_id" db:"conversation_id"`
+	HealthScore    float64         `json:"health_score" db:"health_score"`
+	Components     json.RawMessage `json:"components" db:"components"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// HealthLeaderboardEntry ranks an agent version by conversation health
+type HealthLeaderboardEntry struct {
+	AgentVersion      string  `json:"agent_version" db:"agent_version"`
+	AverageHealth     float64 `json:"average_health" db:"average_health"`
+	Conversations     int     `json:"conversations" db:"conversations"`
+	UnhealthyFraction float64 `json:"unhealthy_fraction" db:"unhealthy_fraction"`
+}
+
+// PipelineConfig represents the runtime configuration of the evaluation pipeline
+type PipelineConfig struct {
+	DefaultEvaluatorTypes []string         `json:"default_evaluator_types" yaml:"default_evaluator_types"`
+	Routing               RoutingPolicy    `json:"routing" yaml:"routing"`
+	LatencyThresholdMS    int              `json:"latency_threshold_ms" yaml:"latency_threshold_ms"`
+	ToolLatencySLAs       map[string]int   `json:"tool_latency_slas" yaml:"tool_latency_slas"`
+	ToolGoldens           []ToolGolden     `json:"tool_goldens" yaml:"tool_goldens"`
+	Health                HealthPolicy     `json:"health" yaml:"health"`
+	Ownership             OwnershipPolicy  `json:"ownership" yaml:"ownership"`
+	Intents               IntentTaxonomy   `json:"intents" yaml:"intents"`
+	Budget                EvaluationBudget `json:"budget" yaml:"budget"`
+	Verdicts              VerdictPolicy    `json:"verdicts" yaml:"verdicts"`
+	Alerts                AlertRules       `json:"alerts" yaml:"alerts"`
+}
+
+// AlertRules sets the thresholds at which scheduled checks raise alerts
+type AlertRules struct {
+	QueueLagThresholds        map[string]int `json:"queue_lag_thresholds" yaml:"queue_lag_thresholds"` // Seconds, by queue or backlog
+	AnomalyZThreshold         float64        `json:"anomaly_z_threshold" yaml:"anomaly_z_threshold"`
+	AnomalyMinEvaluations     int            `json:"anomaly_min_evaluations" yaml:"anomaly_min_evaluations"`
+	ReliabilityKappaThreshold float64        `json:"reliability_kappa_threshold" yaml:"reliability_kappa_threshold"`
+	ReliabilityMinItems       int            `json:"reliability_min_items" yaml:"reliability_min_items"`
+}
+
+// Evaluation verdicts
+const (
+	VerdictPass        = "pass"
+	VerdictFail        = "fail"
+	VerdictNeedsReview = "needs_review"
+)
+
+// VerdictPolicy decides the verdict stored with each evaluation. Rules are
+// checked in order and the first that matches decides; evaluations matching
+// no rule get the default verdict.
+type VerdictPolicy struct {
+	Rules   []VerdictRule `json:"rules" yaml:"rules"`
+	Default string        `json:"default" yaml:"default"`
+}
+
+// VerdictRule gives evaluations meeting every condition it sets a verdict. A
+// rule setting no conditions matches every evaluation.
+type VerdictRule struct {
+	Name             string             `json:"name" yaml:"name"`
+	Verdict          string             `json:"verdict" yaml:"verdict"`
+	OverallBelow     *float64           `json:"overall_below,omitempty" yaml:"overall_below,omitempty"`
+	ComponentsBelow  map[string]float64 `json:"components_below,omitempty" yaml:"components_below,omitempty"`     // Any listed component score below its threshold
+	MinIssueSeverity string             `json:"min_issue_severity,omitempty" yaml:"min_issue_severity,omitempty"` // Any issue at least this severe
+	IssueTypes       []string           `json:"issue_types,omitempty" yaml:"issue_types,omitempty"`               // Any issue of these types, at least MinIssueSeverity if set
+	Partial          *bool              `json:"partial,omitempty" yaml:"partial,omitempty"`
+}
+
+// EvaluationBudget caps what evaluating one conversation may cost. When a
+// budget or an early exit is set, evaluators run one at a time in Order and
+// those that would exceed MaxCost, or that come after a confident failing
+// verdict, are skipped.
+type EvaluationBudget struct {
+	MaxCost             float64            `json:"max_cost" yaml:"max_cost"`                             // 0 means unlimited
+	Costs               map[string]float64 `json:"costs" yaml:"costs"`                                   // Estimated cost of each evaluator type
+	Order               []string           `json:"order" yaml:"order"`                                   // Unlisted evaluator types run last
+	EarlyExitScore      float64            `json:"early_exit_score" yaml:"early_exit_score"`             // Stop once an evaluator scores at or below this; 0 disables
+	EarlyExitOnCritical bool               `json:"early_exit_on_critical" yaml:"early_exit_on_critical"` // Stop once an evaluator reports a critical issue
+}
+
+// Reasons an evaluator was skipped
+const (
+	SkipReasonBudget    = "budget_exceeded"
+	SkipReasonEarlyExit = "early_exit"
+)
+
+// SkippedEvaluator is an evaluator type that was not run for a conversation
+type SkippedEvaluator struct {
+	EvaluatorType string `json:"evaluator_type"`
+	Reason        string `json:"reason"`
+	Detail        string `json:"detail"`
+}
+
+// IntentTaxonomy lists the intents conversations are classified into at
+// ingestion. Conversations matching no intent get the fallback.
+type IntentTaxonomy struct {
+	Intents  []IntentDefinition `json:"intents" yaml:"intents"`
+	Fallback string             `json:"fallback" yaml:"fallback"`
+}
+
+// IntentDefinition recognises an intent by keywords in user turns and by the
+// tools the agent calls
+type IntentDefinition struct {
+	Name     string   `json:"name" yaml:"name"`
+	Keywords []string `json:"keywords,omitempty" yaml:"keywords,omitempty"`
+	Tools    []string `json:"tools,omitempty" yaml:"tools,omitempty"`
+}
+
+// OwnershipPolicy maps tools and issue types to the team that owns them.
+// Tool owners take precedence over issue type owners; DefaultOwner catches
+// everything else.
+type OwnershipPolicy struct {
+	Tools        map[string]string `json:"tools,omitempty" yaml:"tools,omitempty"`
+	IssueTypes   map[string]string `json:"issue_types,omitempty" yaml:"issue_types,omitempty"`
+	DefaultOwner string            `json:"default_owner,omitempty" yaml:"default_owner,omitempty"`
+}
+
+// ConfigBundle represents an exported pipeline configuration
+type ConfigBundle struct {
+	Version    string         `json:"version" yaml:"version"`
+	ExportedAt time.Time      `json:"exported_at" yaml:"exported_at"`
+	Config     PipelineConfig `json:"config" yaml:"config"`
+}
+
+// Project webhook events. Every stored evaluation is an
+// evaluation.completed event; one with critical issues is also an
+// evaluation.critical_issue event. Alerts and digests for teams notified by
+// webhook are notification events.
+const (
+	WebhookEventEvaluationCompleted = "evaluation.completed"
+	WebhookEventCriticalIssue       = "evaluation.critical_issue"
+	WebhookEventAlert               = "notification.alert"
+	WebhookEventDigest              = "notification.digest"
+)
+
+// WebhookFilter restricts which evaluations trigger a webhook
+type WebhookFilter struct {
+	MaxScore     *float64 `json:"max_score,omitempty"`
+	CriticalOnly bool     `json:"critical_only,omitempty"`
+	Events       []string `json:"events,omitempty" binding:"omitempty,dive,oneof=evaluation.completed evaluation.critical_issue notification.alert notification.digest"` // Empty for every evaluation event; notification events are sent only when listed
+}
+
+// ProjectWebhook represents a project's evaluation result webhook
+type ProjectWebhook struct {
+	ID              int64           `json:"id" db:"id"`
+	ProjectID       string          `json:"project_id" db:"project_id"`
+	URL             string          `json:"url" db:"url"`
+	PayloadTemplate string          `json:"payload_template" db:"payload_template"`
+	Filter          json.RawMessage `json:"filter" db:"filter"`
+	Enabled         bool            `json:"enabled" db:"enabled"`
+	Secret          string          `json:"secret" db:"secret"` // Signs deliveries with HMAC-SHA256
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed" // Every attempt failed
+)
+
+// WebhookDelivery is a payload sent, or to be sent, to a project webhook
+type WebhookDelivery struct {
+	ID             int64      `json:"id" db:"id"`
+	WebhookID      int64      `json:"webhook_id" db:"webhook_id"`
+	Event          string     `json:"event" db:"event"`
+	EvaluationID   string     `json:"evaluation_id" db:"evaluation_id"` // Empty for notification events
+	Payload        string     `json:"payload" db:"payload"`
+	Status         string     `json:"status" db:"status"`
+	Attempts       int        `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	ResponseStatus *int       `json:"response_status,omitempty" db:"response_status"`
+	LastError      string     `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// PendingWebhookDelivery is a delivery claimed for sending, with where to
+// send it and the secret to sign it with
+type PendingWebhookDelivery struct {
+	WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// ProjectWebhookCreate represents input for creating a project webhook
+type ProjectWebhookCreate struct {
+	URL             string        `json:"url" binding:"required,url"`
+	PayloadTemplate string        `json:"payload_template,omitempty"`
+	Filter          WebhookFilter `json:"filter"`
+}
+
+// NotificationPreference represents a team's notification settings within a project
+type NotificationPreference struct {
+	ID              int64           `json:"id" db:"id"`
+	ProjectID       string          `json:"project_id" db:"project_id"`
+	Team            string          `json:"team" db:"team"`
+	Channels        json.RawMessage `json:"channels" db:"channels"`
+	Severities      json.RawMessage `json:"severities" db:"severities"`
+	DigestFrequency string          `json:"digest_frequency" db:"digest_frequency"`
+	QuietHoursStart string          `json:"quiet_hours_start" db:"quiet_hours_start"`
+	QuietHoursEnd   string          `json:"quiet_hours_end" db:"quiet_hours_end"`
+	Timezone        string          `json:"timezone" db:"timezone"`
+	LastDigestAt    sql.NullTime    `json:"last_digest_at" db:"last_digest_at"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// NotificationPreferenceUpdate represents input for setting a team's notification preferences
+type NotificationPreferenceUpdate struct {
+	Channels        []string `json:"channels" binding:"required,min=1,dive,oneof=email slack webhook"`
+	Severities      []string `json:"severities,omitempty" binding:"dive,oneof=low medium high critical"`
+	DigestFrequency string   `json:"digest_frequency,omitempty" binding:"omitempty,oneof=none hourly daily weekly"`
+	QuietHoursStart string   `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd   string   `json:"quiet_hours_end,omitempty"`
+	Timezone        string   `json:"timezone,omitempty"`
+}
+
+// SignatureVerification reports the integrity check of a stored evaluation
+type SignatureVerification struct {
+	EvaluationID string `json:"evaluation_id"`
+	Signed       bool   `json:"signed"`
+	Valid        bool   `json:"valid"`
+	Algorithm    string `json:"algorithm,omitempty"`
+	PublicKey    string `json:"public_key,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// BackfillRequest represents input for an evaluation backfill
+type BackfillRequest struct {
+	Limit         int     `json:"limit,omitempty" binding:"omitempty,min=1"`
+	RatePerSecond float64 `json:"rate_per_second,omitempty" binding:"omitempty,gt=0"`
+}
+
+// BackfillStatus reports the progress of an evaluation backfill
+type BackfillStatus struct {
+	Status               string     `json:"status"`
+	Total                int        `json:"total"`
+	Enqueued             int        `json:"enqueued"`
+	Failed               int        `json:"failed"`
+	RatePerSecond        float64    `json:"rate_per_second"`         // Target rate
+	CurrentRatePerSecond float64    `json:"current_rate_per_second"` // Ramps up to the target and backs off on evaluation failures
+	Backoffs             int        `json:"backoffs"`
+	StartedAt            *time.Time `json:"started_at,omitempty"`
+	FinishedAt           *time.Time `json:"finished_at,omitempty"`
+	Error                string     `json:"error,omitempty"`
+}
+
+// BulkRoutingApproval represents a reviewer approving routing decisions in bulk
+type BulkRoutingApproval struct {
+	ReviewerID      string   `json:"reviewer_id" binding:"required"`
+	ConversationIDs []string `json:"conversation_ids" binding:"required,min=1,max=500"`
+	Atomic          bool     `json:"atomic,omitempty"` // Roll back every item if any fails
+}
+
+// IssueRef identifies an issue by its position in an evaluation's issues
+type IssueRef struct {
+	EvaluationID string `json:"evaluation_id" binding:"required"`
+	IssueIndex   int    `json:"issue_index" binding:"min=0"`
+}
+
+// BulkIssueDismissal represents a reviewer dismissing low-severity issues in bulk
+type BulkIssueDismissal struct {
+	ReviewerID string     `json:"reviewer_id" binding:"required"`
+	Reason     string     `json:"reason,omitempty"`
+	Issues     []IssueRef `json:"issues" binding:"required,min=1,max=500,dive"`
+	Atomic     bool       `json:"atomic,omitempty"`
+}
+
+// BulkAnnotationAssignment represents assigning annotation tasks to an annotator in bulk
+type BulkAnnotationAssignment struct {
+	AnnotatorID     string   `json:"annotator_id" binding:"required"`
+	AnnotationType  string   `json:"annotation_type" binding:"required"`
+	AssignedBy      string   `json:"assigned_by,omitempty"`
+	ConversationIDs []string `json:"conversation_ids" binding:"required,min=1,max=500"`
+	Atomic          bool     `json:"atomic,omitempty"`
+}
+
+// BulkItemResult reports the outcome of one item of a bulk action
+type BulkItemResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkActionResponse reports the outcome of a bulk action
+type BulkActionResponse struct {
+	Succeeded  int              `json:"succeeded"`
+	Failed     int              `json:"failed"`
+	RolledBack bool             `json:"rolled_back"`
+	Results    []BulkItemResult `json:"results"`
+}
+
+// ConversationDeletion selects conversations to hard delete, with everything
+// recorded about them. Selectors combine with AND and at least one is required.
+type ConversationDeletion struct {
+	ConversationIDs []string   `json:"conversation_ids,omitempty"`
+	AgentVersion    string     `json:"agent_version,omitempty"`
+	IDPrefix        string     `json:"id_prefix,omitempty"`
+	CreatedBefore   *time.Time `json:"created_before,omitempty"`
+	DryRun          *bool      `json:"dry_run,omitempty"` // Defaults to true, so only counts are returned
+}
+
+// ConversationDeletionResult reports the rows a hard delete removed, or
+// would remove on a dry run. Dataset run items are kept with their
+// conversation cleared and are counted with the rest.
+type ConversationDeletionResult struct {
+	DryRun          bool             `json:"dry_run"`
+	ConversationIDs []string         `json:"conversation_ids"`
+	Rows            map[string]int64 `json:"rows"`        // By table
+	Attachments     []string         `json:"attachments"` // Digests of attachment blobs no other conversation references, deleted with them
+}
+
+// AgentVersionReassignment selects conversations that were ingested with the
+// wrong agent version and gives the version they should have. At least one
+// selector is required.
+type AgentVersionReassignment struct {
+	ConversationIDs []string   `json:"conversation_ids,omitempty"`
+	AgentVersion    string     `json:"agent_version,omitempty"` // Current, wrong version
+	IDPrefix        string     `json:"id_prefix,omitempty"`
+	CreatedAfter    *time.Time `json:"created_after,omitempty"`
+	CreatedBefore   *time.Time `json:"created_before,omitempty"`
+	NewAgentVersion string     `json:"new_agent_version" binding:"required,max=100"`
+	DryRun          *bool      `json:"dry_run,omitempty"` // Defaults to true, so only the selection is returned
+}
+
+// AgentVersionReassignmentResult reports the conversations a reassignment
+// changed, or would change on a dry run
+type AgentVersionReassignmentResult struct {
+	DryRun          bool           `json:"dry_run"`
+	ConversationIDs []string       `json:"conversation_ids"`
+	FromVersions    map[string]int `json:"from_versions"`    // Reassigned conversations by previous version
+	FailurePatterns []string       `json:"failure_patterns"` // Patterns whose affected versions were corrected
+	DatasetRuns     []int64        `json:"dataset_runs"`     // Runs of reassigned conversations, marked stale
+}
+
+// SeverityPriorityUpdate replaces the severity to routing priority mapping.
+// Open annotation tasks for conversations evaluated in the last
+// RecomputeHours, and not yet approved, are re-prioritized with it.
+type SeverityPriorityUpdate struct {
+	SeverityPriorities map[string]string `json:"severity_priorities" binding:"required"`
+	RecomputeHours     int               `json:"recompute_hours,omitempty" binding:"min=0"`
+}
+
+// SeverityPriorityMapping reports the severity to routing priority mapping,
+// and after an update how many annotation tasks were re-prioritized
+type SeverityPriorityMapping struct {
+	SeverityPriorities map[string]string `json:"severity_priorities"`
+	RecomputedTasks    *int              `json:"recomputed_tasks,omitempty"`
+}
+
+// VerdictPolicyUpdate replaces the verdict policy. Evaluations stored in the
+// last RecomputeHours get their verdict again from the new policy.
+type VerdictPolicyUpdate struct {
+	VerdictPolicy
+	RecomputeHours int `json:"recompute_hours,omitempty" binding:"min=0"`
+}
+
+// VerdictPolicyReport reports the verdict policy, and after an update how
+// many evaluations got their verdict again
+type VerdictPolicyReport struct {
+	VerdictPolicy
+	RecomputedEvaluations *int `json:"recomputed_evaluations,omitempty"`
+}
+
+// MaintenanceStart represents input for starting read-only maintenance mode
+type MaintenanceStart struct {
+	Reason            string `json:"reason"`
+	DurationMinutes   int    `json:"duration_minutes" binding:"min=0"`    // Ends automatically after this long; 0 lasts until ended
+	RetryAfterSeconds int    `json:"retry_after_seconds" binding:"min=0"` // Sent in Retry-After; defaults to 60
+}
+
+// ToolGolden is a golden example of calling a tool. Recorded calls of the
+// tool that match the scenario must have at least the expected parameters
+// and result; nested objects are compared the same way.
+type ToolGolden struct {
+	Name               string                 `json:"name" yaml:"name" binding:"required"`
+	Tool               string                 `json:"tool" yaml:"tool" binding:"required"`
+	Scenario           ToolGoldenScenario     `json:"scenario" yaml:"scenario"`
+	ExpectedParameters map[string]interface{} `json:"expected_parameters,omitempty" yaml:"expected_parameters,omitempty"`
+	ExpectedResult     map[string]interface{} `json:"expected_result,omitempty" yaml:"expected_result,omitempty"`
+}
+
+// ToolGoldenScenario decides which recorded calls a golden example applies
+// to. Empty fields match every call of the tool.
+type ToolGoldenScenario struct {
+	UserMessage string                 `json:"user_message,omitempty" yaml:"user_message,omitempty"` // Regular expression matched case-insensitively against the last user turn before the call
+	Parameters  map[string]interface{} `json:"parameters,omitempty" yaml:"parameters,omitempty"`     // Parameters the call must have
+}
+
+// ToolGoldenUpdate represents input for replacing the golden tool examples
+type ToolGoldenUpdate struct {
+	ToolGoldens []ToolGolden `json:"tool_goldens" binding:"dive"`
+}
+
+// IngestFormatOpenAI selects the OpenAI chat completions message format on
+// the ingestion endpoints
+const IngestFormatOpenAI = "openai"
+
+// OpenAIConversation represents a conversation logged in the OpenAI chat
+// completions message format
+type OpenAIConversation struct {
+	ConversationID string                `json:"conversation_id" binding:"required"`
+	AgentVersion   string                `json:"agent_version" binding:"required"`
+	Messages       []OpenAIMessage       `json:"messages" binding:"required,min=1"`
+	Feedback       *Feedback             `json:"feedback,omitempty"`
+	Metadata       *ConversationMetadata `json:"metadata,omitempty"`
+	Intent         string                `json:"intent,omitempty"`
+}
+
+// OpenAIMessage is a chat completions message. Content is a string, an
+// array of content parts or null.
+type OpenAIMessage struct {
+	Role         string              `json:"role" binding:"required"`
+	Content      json.RawMessage     `json:"content,omitempty"`
+	Name         string              `json:"name,omitempty"`
+	ToolCalls    []OpenAIToolCall    `json:"tool_calls,omitempty"`
+	ToolCallID   string              `json:"tool_call_id,omitempty"`
+	FunctionCall *OpenAIFunctionCall `json:"function_call,omitempty"` // Legacy single function call
+	Timestamp    time.Time           `json:"timestamp,omitempty"`     // Not part of the format, but kept when logged
+}
+
+// OpenAIToolCall is a tool call of an assistant message
+type OpenAIToolCall struct {
+	ID       string             `json:"id"`
+	Type     string             `json:"type"`
+	Function OpenAIFunctionCall `json:"function"`
+}
+
+// OpenAIFunctionCall names a function and its arguments, a JSON encoded object
+type OpenAIFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 // BatchIngestResponse represents batch ingestion response
@@ -269,3 +1298,1069 @@ type BatchIngestResponse struct {
 	Ingested        int      `json:"ingested"`
 	ConversationIDs []string `json:"conversation_ids"`
 }
+
+// Conversation upload formats
+const (
+	ImportFormatJSONL = "jsonl"
+	ImportFormatCSV   = "csv"
+)
+
+// Conversation import states
+const (
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// ConversationImport tracks the background import of an uploaded conversation file
+type ConversationImport struct {
+	ImportID     string       `json:"import_id" db:"import_id"`
+	ProjectID    string       `json:"project_id" db:"project_id"`
+	Filename     string       `json:"filename" db:"filename"`
+	Format       string       `json:"format" db:"format"`
+	SizeBytes    int64        `json:"size_bytes" db:"size_bytes"`
+	AutoEvaluate bool         `json:"auto_evaluate" db:"auto_evaluate"`
+	Status       string       `json:"status" db:"status"`
+	Processed    int          `json:"processed" db:"processed"`
+	Imported     int          `json:"imported" db:"imported"`
+	Failed       int          `json:"failed" db:"failed"`
+	Error        string       `json:"error,omitempty" db:"error"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+	FinishedAt   sql.NullTime `json:"finished_at" db:"finished_at"`
+}
+
+// ConversationImportError is a record of an uploaded file that wasn't imported
+type ConversationImportError struct {
+	ImportID       string `json:"import_id" db:"import_id"`
+	Line           int    `json:"line" db:"line"`
+	ConversationID string `json:"conversation_id" db:"conversation_id"`
+	Error          string `json:"error" db:"error"`
+}
+
+// Outcomes of a line of a streamed conversation import
+const (
+	ImportLineImported = "imported"
+	ImportLineFailed   = "failed"
+)
+
+// ImportLineResult is the outcome of one line of a streamed conversation import
+type ImportLineResult struct {
+	Line           int    `json:"line"`
+	ConversationID string `json:"conversation_id,omitempty"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+}
+
+// StreamImportResponse reports a streamed conversation import
+type StreamImportResponse struct {
+	Processed int                `json:"processed"`
+	Imported  int                `json:"imported"`
+	Failed    int                `json:"failed"`
+	Results   []ImportLineResult `json:"results"`
+	Error     string             `json:"error,omitempty"` // Set when reading the body failed; later lines weren't processed
+}
+
+// EvaluationExportFilter selects the evaluations of a columnar export.
+// Zero times leave the range open; an empty version list matches all.
+type EvaluationExportFilter struct {
+	From           time.Time
+	To             time.Time
+	AgentVersions  []string
+	ConversationID string
+	TriggerSource  string
+	Verdict        string
+	MinScore       *float64
+	MaxScore       *float64
+	Limit          int
+	Offset         int
+}
+
+// EvaluationExportRow is one evaluation in a columnar export
+type EvaluationExportRow struct {
+	EvaluationID         string    `db:"evaluation_id"`
+	ConversationID       string    `db:"conversation_id"`
+	AgentVersion         string    `db:"agent_version"`
+	OverallScore         float64   `db:"overall_score"`
+	ResponseQualityScore float64   `db:"response_quality_score"`
+	ToolAccuracyScore    float64   `db:"tool_accuracy_score"`
+	CoherenceScore       float64   `db:"coherence_score"`
+	IssueCount           int64     `db:"issue_count"`
+	EvaluatorVersion     string    `db:"evaluator_version"`
+	TriggerSource        string    `db:"trigger_source"`
+	EvaluationDurationMS int64     `db:"evaluation_duration_ms"`
+	CreatedAt            time.Time `db:"created_at"`
+	Verdict              string    `db:"verdict"`
+}
+
+// Annotation export formats
+const (
+	ExportFormatLabelStudio = "labelstudio"
+	ExportFormatProdigy     = "prodigy"
+)
+
+// AnnotationExportItem is a conversation with the inputs used to build an annotation task
+type AnnotationExportItem struct {
+	Conversation     Conversation
+	LatestEvaluation *Evaluation // Nil when the conversation hasn't been evaluated
+	Annotations      []Annotation
+}
+
+// LabelStudioTask is a task in Label Studio's JSON import format
+type LabelStudioTask struct {
+	Data        map[string]interface{}  `json:"data"`
+	Predictions []LabelStudioAnnotation `json:"predictions,omitempty"`
+	Annotations []LabelStudioAnnotation `json:"annotations,omitempty"`
+}
+
+// LabelStudioAnnotation is a prediction or completed annotation of a Label Studio task
+type LabelStudioAnnotation struct {
+	ModelVersion string              `json:"model_version,omitempty"`
+	CompletedBy  string              `json:"completed_by,omitempty"`
+	Score        *float64            `json:"score,omitempty"`
+	Result       []LabelStudioResult `json:"result"`
+}
+
+// LabelStudioResult is a single region or control value of a Label Studio annotation
+type LabelStudioResult struct {
+	ID       string                 `json:"id"`
+	FromName string                 `json:"from_name"`
+	ToName   string                 `json:"to_name"`
+	Type     string                 `json:"type"`
+	Value    map[string]interface{} `json:"value"`
+}
+
+// ProdigyTask is a task in Prodigy's JSONL format for choice interfaces
+type ProdigyTask struct {
+	Text    string                 `json:"text"`
+	Options []ProdigyOption        `json:"options"`
+	Accept  []string               `json:"accept"`
+	Meta    map[string]interface{} `json:"meta"`
+}
+
+// ProdigyOption is a selectable option of a Prodigy choice task
+type ProdigyOption struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// Label Studio webhook actions handled by the integration
+const (
+	LabelStudioAnnotationCreated  = "ANNOTATION_CREATED"
+	LabelStudioAnnotationUpdated  = "ANNOTATION_UPDATED"
+	LabelStudioAnnotationsDeleted = "ANNOTATIONS_DELETED"
+)
+
+// LabelStudioWebhookEvent is the payload Label Studio sends to webhooks
+type LabelStudioWebhookEvent struct {
+	Action      string                           `json:"action" binding:"required"`
+	Task        *LabelStudioWebhookTask          `json:"task,omitempty"`
+	Annotation  *LabelStudioWebhookAnnotation    `json:"annotation,omitempty"`
+	Annotations []LabelStudioWebhookAnnotationID `json:"annotations,omitempty"`
+}
+
+// LabelStudioWebhookTask is the task an event refers to
+type LabelStudioWebhookTask struct {
+	ID   int64                  `json:"id"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// LabelStudioWebhookAnnotation is a completed annotation in a webhook event.
+// CompletedBy is a user ID, or a user object when the full payload is sent.
+type LabelStudioWebhookAnnotation struct {
+	ID           int64               `json:"id"`
+	CompletedBy  json.RawMessage     `json:"completed_by"`
+	Result       []LabelStudioResult `json:"result"`
+	LeadTime     float64             `json:"lead_time"` // Seconds
+	WasCancelled bool                `json:"was_cancelled"`
+}
+
+// LabelStudioWebhookAnnotationID identifies a deleted annotation
+type LabelStudioWebhookAnnotationID struct {
+	ID int64 `json:"id"`
+}
+
+// LabelStudioWebhookResponse reports how a webhook event was applied
+type LabelStudioWebhookResponse struct {
+	Action      string       `json:"action"`
+	Ignored     bool         `json:"ignored,omitempty"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+	Deleted     int          `json:"deleted,omitempty"`
+}
+
+// IssueAssignment is a detected issue with the owner it was assigned to.
+// Issues are identified by their position in the evaluation's issues_detected.
+type IssueAssignment struct {
+	EvaluationID   string    `json:"evaluation_id" db:"evaluation_id"`
+	IssueIndex     int       `json:"issue_index" db:"issue_index"`
+	ConversationID string    `json:"conversation_id" db:"conversation_id"`
+	IssueType      string    `json:"issue_type" db:"issue_type"`
+	Severity       string    `json:"severity" db:"severity"`
+	Description    string    `json:"description" db:"description"`
+	Tool           string    `json:"tool,omitempty" db:"tool"`
+	Owner          string    `json:"owner" db:"owner"`
+	Dismissed      bool      `json:"dismissed" db:"dismissed"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// OwnerSummary counts the open work assigned to an owner
+type OwnerSummary struct {
+	Owner              string `json:"owner" db:"owner"`
+	OpenIssues         int    `json:"open_issues" db:"open_issues"`
+	CriticalIssues     int    `json:"critical_issues" db:"critical_issues"`
+	UnresolvedPatterns int    `json:"unresolved_patterns" db:"unresolved_patterns"`
+}
+
+// OwnerDigest summarizes the issues assigned to a team since its last digest
+type OwnerDigest struct {
+	ProjectID          string            `json:"project_id"`
+	Team               string            `json:"team"`
+	Channels           []string          `json:"channels"`
+	Since              time.Time         `json:"since"`
+	Until              time.Time         `json:"until"`
+	IssueCount         int               `json:"issue_count"`
+	SeverityCounts     map[string]int    `json:"severity_counts"`
+	Issues             []IssueAssignment `json:"issues"`
+	UnresolvedPatterns []FailurePattern  `json:"unresolved_patterns"`
+}
+
+// TableStorage reports the size and growth of a table. Row counts are the
+// planner's estimates, so they are cheap to read but approximate.
+type TableStorage struct {
+	Table       string  `json:"table" db:"table_name"`
+	RowEstimate int64   `json:"row_estimate" db:"row_estimate"`
+	TableBytes  int64   `json:"table_bytes" db:"table_bytes"`
+	IndexBytes  int64   `json:"index_bytes" db:"index_bytes"`
+	TotalBytes  int64   `json:"total_bytes" db:"total_bytes"`
+	HasCreated  bool    `json:"-" db:"has_created_at"`
+	RowsPerDay  float64 `json:"rows_per_day"`  // Over the growth window; 0 for tables without created_at
+	BytesPerDay float64 `json:"bytes_per_day"` // RowsPerDay at the table's current average row size
+	Projected30 int64   `json:"projected_30d_bytes"`
+	Projected90 int64   `json:"projected_90d_bytes"`
+}
+
+// JSONBColumnStats summarizes the stored size of a JSONB column over a
+// sample of its rows
+type JSONBColumnStats struct {
+	Table       string  `json:"table" db:"table_name"`
+	Column      string  `json:"column" db:"column_name"`
+	SampledRows int64   `json:"sampled_rows" db:"sampled_rows"`
+	AvgBytes    float64 `json:"avg_bytes" db:"avg_bytes"`
+	P50Bytes    float64 `json:"p50_bytes" db:"p50_bytes"`
+	P90Bytes    float64 `json:"p90_bytes" db:"p90_bytes"`
+	P99Bytes    float64 `json:"p99_bytes" db:"p99_bytes"`
+	MaxBytes    int64   `json:"max_bytes" db:"max_bytes"`
+}
+
+// StorageAction suggests a retention or partitioning change for a table
+type StorageAction struct {
+	Table  string `json:"table"`
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// StorageReport describes database storage usage and growth
+type StorageReport struct {
+	GeneratedAt   time.Time          `json:"generated_at"`
+	GrowthDays    int                `json:"growth_days"`
+	DatabaseBytes int64              `json:"database_bytes"`
+	Tables        []TableStorage     `json:"tables"`
+	JSONBColumns  []JSONBColumnStats `json:"jsonb_columns"`
+	Actions       []StorageAction    `json:"actions"`
+}
+
+// FeedbackAnalysis is the sentiment and themes extracted from a feedback comment
+type FeedbackAnalysis struct {
+	Sentiment      string   `json:"sentiment"`
+	SentimentScore float64  `json:"sentiment_score"` // -1 (negative) to 1 (positive)
+	Themes         []string `json:"themes"`
+}
+
+// FeedbackSignal pairs a commented feedback with the automated evaluation of
+// its conversation
+type FeedbackSignal struct {
+	ConversationID string          `db:"conversation_id"`
+	Sentiment      string          `db:"sentiment"`
+	UserRating     sql.NullInt32   `db:"user_rating"`
+	Themes         json.RawMessage `db:"themes"`
+	OverallScore   sql.NullFloat64 `db:"overall_score"`
+	IssueTypes     json.RawMessage `db:"issue_types"` // Of the latest evaluation
+}
+
+// IssueTypeCount counts conversations whose evaluation reported an issue type
+type IssueTypeCount struct {
+	IssueType     string `json:"issue_type"`
+	Conversations int    `json:"conversations"`
+}
+
+// FeedbackThemeSummary aggregates the feedback comments of one theme against
+// automated evaluation
+type FeedbackThemeSummary struct {
+	Theme            string           `json:"theme"`
+	FeedbackCount    int              `json:"feedback_count"`
+	Conversations    int              `json:"conversations"`
+	NegativeShare    float64          `json:"negative_share"`
+	AvgUserRating    *float64         `json:"avg_user_rating"`
+	AvgOverallScore  *float64         `json:"avg_overall_score"`
+	ScoreDelta       *float64         `json:"score_delta"`        // Against all commented conversations
+	MatchedIssueRate *float64         `json:"matched_issue_rate"` // Share of conversations with a related issue detected; nil for themes without related issue types
+	TopIssueTypes    []IssueTypeCount `json:"top_issue_types"`
+}
+
+// ServiceAccount is a non-human API client, such as the evaluator service,
+// authenticated by a bearer token and limited to its scopes
+type ServiceAccount struct {
+	Name       string          `json:"name" db:"name"`
+	TokenHash  string          `json:"-" db:"token_hash"`
+	Scopes     json.RawMessage `json:"scopes" db:"scopes"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time      `json:"last_used_at" db:"last_used_at"`
+	RevokedAt  *time.Time      `json:"revoked_at" db:"revoked_at"`
+}
+
+// ServiceAccountCreate represents input for creating a service account
+type ServiceAccountCreate struct {
+	Name   string   `json:"name" binding:"required,max=255"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+}
+
+// ServiceAccountToken is a created service account with its token, which is
+// only ever returned here
+type ServiceAccountToken struct {
+	ServiceAccount
+	Token string `json:"token"`
+}
+
+// APIKey is a static key that authenticates API clients, limited to its
+// scopes. Only a hash of the key is stored; Prefix identifies it in listings.
+type APIKey struct {
+	ID         string          `json:"id" db:"id"`
+	Name       string          `json:"name" db:"name"`
+	Prefix     string          `json:"prefix" db:"key_prefix"`
+	KeyHash    string          `json:"-" db:"key_hash"`
+	Scopes     json.RawMessage `json:"scopes" db:"scopes"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time      `json:"last_used_at" db:"last_used_at"`
+	RevokedAt  *time.Time      `json:"revoked_at" db:"revoked_at"`
+	ProjectID  string          `json:"project_id" db:"project_id"` // Empty for every project
+}
+
+// APIKeyCreate represents input for creating an API key
+type APIKeyCreate struct {
+	Name      string   `json:"name" binding:"required,max=255"`
+	Scopes    []string `json:"scopes" binding:"required,min=1"`
+	ProjectID string   `json:"project_id,omitempty"` // Restricts the key to one project
+}
+
+// DefaultProjectID is the project of requests that don't name one, and of
+// data recorded before projects existed
+const DefaultProjectID = "default"
+
+// Project scopes conversations and everything recorded about them, so teams
+// sharing a deployment only see their own data
+type Project struct {
+	ProjectID        string          `json:"project_id" db:"project_id"`
+	Name             string          `json:"name" db:"name"`
+	EvaluatorTypes   json.RawMessage `json:"evaluator_types" db:"evaluator_types"` // Evaluator profile
+	RoutingPolicy    json.RawMessage `json:"routing_policy" db:"routing_policy"`
+	Quotas           json.RawMessage `json:"quotas" db:"quotas"`
+	AssistedLabeling json.RawMessage `json:"assisted_labeling" db:"assisted_labeling"`
+	Public           bool            `json:"public" db:"public"` // Aggregates are served on the public API
+	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
+}
+
+// ProjectQuotas caps a project's daily usage, counted by UTC day. Zero means
+// unlimited.
+type ProjectQuotas struct {
+	MaxConversationsPerDay int `json:"max_conversations_per_day" binding:"min=0"`
+}
+
+// PublicAccess opts a project in to or out of the public API
+type PublicAccess struct {
+	Public *bool `json:"public" binding:"required"`
+}
+
+// AssistedLabeling decides whether a project's annotation forms are pre-filled
+// with the automated evaluation. Annotators are split per conversation into
+// an assisted arm, shown the machine suggestion, and a control arm, not
+// shown it, so that the suggestion's sway on labels can be measured.
+type AssistedLabeling struct {
+	Enabled          bool    `json:"enabled"`
+	AssistedFraction float64 `json:"assisted_fraction" binding:"min=0,max=1"` // Of forms pre-filled, e.g. 0.5; the rest are the control arm
+}
+
+// AnnotationForm is what an annotator's form for a conversation starts with
+type AnnotationForm struct {
+	ConversationID string `json:"conversation_id"`
+	AnnotationType string `json:"annotation_type"`
+	AnnotatorID    string `json:"annotator_id"`
+	// Assisted labeling arm of the form; null when the project doesn't use
+	// assisted labeling. Only assisted forms carry a suggestion.
+	Assisted   *bool                 `json:"assisted"`
+	Suggestion *AnnotationSuggestion `json:"suggestion,omitempty"`
+}
+
+// AnnotationSuggestion pre-fills an annotation form from the latest automated
+// evaluation of a conversation
+type AnnotationSuggestion struct {
+	MachineSuggested bool            `json:"machine_suggested"` // Always true; forms must show the suggestion as such
+	Source           string          `json:"source"`
+	EvaluationID     string          `json:"evaluation_id"`
+	EvaluatorVersion string          `json:"evaluator_version"`
+	Labels           []string        `json:"labels"` // Types of the issues found
+	Score            float64         `json:"score"`
+	Issues           []IssueDetected `json:"issues"` // To highlight, at their turns
+}
+
+// AssistedLabelingArm summarizes the annotations made in one arm of assisted
+// labeling that had a machine suggestion
+type AssistedLabelingArm struct {
+	Annotations          int     `json:"annotations"`
+	SuggestionAgreement  float64 `json:"suggestion_agreement"` // Mean Jaccard similarity of the labels and the suggestion
+	ExactMatchRate       float64 `json:"exact_match_rate"`     // Of annotations with exactly the suggested labels
+	MeanTimeSpentSeconds float64 `json:"mean_time_spent_seconds"`
+}
+
+// AssistedLabelingReport compares the arms of a project's assisted labeling.
+// Bias is how much more the assisted arm agrees with the machine suggestion
+// than the control arm, which never saw it; near 0 the suggestion doesn't
+// sway annotators.
+type AssistedLabelingReport struct {
+	ProjectID string                          `json:"project_id"`
+	Since     time.Time                       `json:"since"`
+	Settings  AssistedLabeling                `json:"settings"`
+	Assisted  AssistedLabelingArm             `json:"assisted"`
+	Control   AssistedLabelingArm             `json:"control"`
+	Bias      float64                         `json:"bias"`
+	ByType    map[string]AssistedLabelingBias `json:"by_annotation_type"`
+}
+
+// AssistedLabelingBias compares the arms for one annotation type
+type AssistedLabelingBias struct {
+	Assisted AssistedLabelingArm `json:"assisted"`
+	Control  AssistedLabelingArm `json:"control"`
+	Bias     float64             `json:"bias"`
+}
+
+// ProjectCreate represents input for onboarding a project. Settings left
+// out default to the deployment's.
+type ProjectCreate struct {
+	ProjectID        string            `json:"project_id" binding:"required,max=255"`
+	Name             string            `json:"name" binding:"required,max=255"`
+	EvaluatorTypes   []string          `json:"evaluator_types,omitempty"`
+	Routing          *RoutingPolicy    `json:"routing,omitempty"`
+	Quotas           *ProjectQuotas    `json:"quotas,omitempty"`
+	AssistedLabeling *AssistedLabeling `json:"assisted_labeling,omitempty"`
+}
+
+// ProjectOnboarding is a newly provisioned project with what its team needs
+// to start sending data. API keys are only ever returned here; webhooks are
+// disabled placeholders until given a URL.
+type ProjectOnboarding struct {
+	Project  Project            `json:"project"`
+	APIKeys  []APIKeySecret     `json:"api_keys"`
+	Webhooks []ProjectWebhook   `json:"webhooks"`
+	Warnings []EvaluatorWarning `json:"warnings,omitempty"` // Evaluators dropped from the profile
+}
+
+// APIKeySecret is a created API key with the key itself, which is only
+// ever returned here
+type APIKeySecret struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// IntentQuality breaks conversation quality down for one intent, using the
+// latest evaluation of each conversation
+type IntentQuality struct {
+	Intent                  string           `json:"intent" db:"intent"`
+	Conversations           int              `json:"conversations" db:"conversations"`
+	Evaluated               int              `json:"evaluated" db:"evaluated"`
+	Failed                  int              `json:"failed" db:"failed"` // Low scoring or with critical issues
+	FailureRate             *float64         `json:"failure_rate"`
+	AvgOverallScore         *float64         `json:"avg_overall_score" db:"avg_overall_score"`
+	AvgResponseQualityScore *float64         `json:"avg_response_quality_score" db:"avg_response_quality_score"`
+	AvgToolAccuracyScore    *float64         `json:"avg_tool_accuracy_score" db:"avg_tool_accuracy_score"`
+	AvgCoherenceScore       *float64         `json:"avg_coherence_score" db:"avg_coherence_score"`
+	TopIssueTypes           []IssueTypeCount `json:"top_issue_types"`
+}
+
+// Journey keys: the conversation metadata field that links the conversations
+// of a journey
+const (
+	JourneyKeySubject = "subject_id"
+	JourneyKeySession = "session_id"
+)
+
+// JourneyConversation is a conversation of a journey with its latest
+// evaluation
+type JourneyConversation struct {
+	JourneyID          string    `db:"journey_id"`
+	ConversationID     string    `db:"conversation_id"`
+	CreatedAt          time.Time `db:"created_at"`
+	MissionCompleted   bool      `db:"mission_completed"`
+	Escalated          bool      `db:"escalated"`
+	OverallScore       *float64  `db:"overall_score"` // Nil when not evaluated
+	CriticalIssueCount int       `db:"critical_issue_count"`
+}
+
+// JourneyAnalysis summarizes the outcomes of journeys across conversations.
+// A journey is resolved when its latest conversation completed its mission.
+type JourneyAnalysis struct {
+	Journeys                int                     `json:"journeys"`
+	Conversations           int                     `json:"conversations"`
+	Resolved                int                     `json:"resolved"`
+	ResolutionRate          *float64                `json:"resolution_rate"`
+	FirstContactResolutions int                     `json:"first_contact_resolutions"` // Resolved in a single conversation
+	AvgContactsToResolution *float64                `json:"avg_contacts_to_resolution"`
+	Reopened                int                     `json:"reopened"`          // Continued after a conversation completed its mission
+	AvgJourneyScore         *float64                `json:"avg_journey_score"` // Mean of each journey's average latest score
+	Escalated               int                     `json:"escalated"`
+	EscalationChainLengths  []EscalationChainLength `json:"escalation_chain_lengths"`
+	CompletionFunnel        []JourneyContactStep    `json:"completion_funnel"`
+	QualityFunnel           []JourneyFunnelStep     `json:"quality_funnel"`
+	LongestEscalationChains []EscalationChain       `json:"longest_escalation_chains"`
+}
+
+// JourneyContactStep counts the journeys that reached their nth
+// conversation and those resolved there. The last step also counts every
+// later conversation.
+type JourneyContactStep struct {
+	Contact                  int     `json:"contact"`
+	Reached                  int     `json:"reached"`
+	Resolved                 int     `json:"resolved"`
+	CumulativeResolutionRate float64 `json:"cumulative_resolution_rate"` // Share of all journeys resolved by this contact
+}
+
+// JourneyFunnelStep counts the journeys that passed a quality funnel step
+// and every step before it
+type JourneyFunnelStep struct {
+	Step     string  `json:"step"`
+	Journeys int     `json:"journeys"`
+	Rate     float64 `json:"rate"` // Share of all journeys
+}
+
+// EscalationChainLength counts the journeys whose longest run of
+// consecutive escalated conversations has a length
+type EscalationChainLength struct {
+	Length   int `json:"length"`
+	Journeys int `json:"journeys"`
+}
+
+// EscalationChain is the longest run of consecutive escalated conversations
+// of a journey
+type EscalationChain struct {
+	JourneyID       string   `json:"journey_id"`
+	ConversationIDs []string `json:"conversation_ids"`
+	Resolved        bool     `json:"resolved"` // Whether the journey was resolved in the end
+}
+
+// ReliabilityLabelCount counts the annotators who gave a label to a
+// conversation annotated by several annotators. Bucket is the period in
+// which its last annotation was made.
+type ReliabilityLabelCount struct {
+	AnnotationType string    `db:"annotation_type"`
+	Bucket         time.Time `db:"bucket"`
+	ConversationID string    `db:"conversation_id"`
+	Label          string    `db:"label"`
+	Annotators     int       `db:"annotators"`
+}
+
+// ReliabilityPoint is the inter-rater reliability of one annotation type over
+// one period
+type ReliabilityPoint struct {
+	AnnotationType   string    `json:"annotation_type"`
+	Bucket           time.Time `json:"bucket"`
+	Items            int       `json:"items"` // Conversations with at least two annotators
+	Annotations      int       `json:"annotations"`
+	PercentAgreement float64   `json:"percent_agreement"` // Share of annotator pairs that agree
+	Kappa            *float64  `json:"kappa"`             // Fleiss' kappa; nil when every annotation has the same label
+	BelowThreshold   bool      `json:"below_threshold"`
+}
+
+// ReliabilityEvent records an annotation type whose reliability fell below
+// the threshold in a period
+type ReliabilityEvent struct {
+	ID               int64     `json:"id" db:"id"`
+	AnnotationType   string    `json:"annotation_type" db:"annotation_type"`
+	Bucket           time.Time `json:"bucket" db:"bucket"`
+	Kappa            float64   `json:"kappa" db:"kappa"`
+	PreviousKappa    *float64  `json:"previous_kappa" db:"previous_kappa"`
+	PercentAgreement float64   `json:"percent_agreement" db:"percent_agreement"`
+	Items            int       `json:"items" db:"items"`
+	Threshold        float64   `json:"threshold" db:"threshold"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+}
+
+// VersionMatrixCell is the quality of conversations sharing an agent
+// version, model and tool version, using the latest evaluation of each
+// conversation. Conversations using several tools appear once per tool.
+type VersionMatrixCell struct {
+	AgentVersion            string  `json:"agent_version" db:"agent_version"`
+	Model                   string  `json:"model" db:"model"`
+	Tool                    string  `json:"tool" db:"tool"`
+	ToolVersion             string  `json:"tool_version" db:"tool_version"`
+	Evaluations             int     `json:"evaluations" db:"evaluations"`
+	AvgOverallScore         float64 `json:"avg_overall_score" db:"avg_overall_score"`
+	AvgResponseQualityScore float64 `json:"avg_response_quality_score" db:"avg_response_quality_score"`
+	AvgToolAccuracyScore    float64 `json:"avg_tool_accuracy_score" db:"avg_tool_accuracy_score"`
+	AvgCoherenceScore       float64 `json:"avg_coherence_score" db:"avg_coherence_score"`
+}
+
+// TokenUsage is the estimated token usage of the conversations of an agent
+// version on a model. EstimatedCostUSD is only set for priced models.
+type TokenUsage struct {
+	AgentVersion     string   `json:"agent_version" db:"agent_version"`
+	Model            string   `json:"model" db:"model"`
+	Conversations    int      `json:"conversations" db:"conversations"`
+	Turns            int      `json:"turns" db:"turns"`
+	ContentTokens    int64    `json:"content_tokens" db:"content_tokens"`
+	ToolCallTokens   int64    `json:"tool_call_tokens" db:"tool_call_tokens"`
+	ToolResultTokens int64    `json:"tool_result_tokens" db:"tool_result_tokens"`
+	TotalTokens      int64    `json:"total_tokens" db:"total_tokens"`
+	AvgTokens        float64  `json:"avg_tokens_per_conversation" db:"avg_tokens"`
+	EstimatedCostUSD *float64 `json:"estimated_cost_usd,omitempty" db:"-"`
+}
+
+// CostBucket is what running the agent cost for the conversations created
+// for one agent version or on one day. Tokens are those the agent reported;
+// conversations on unpriced models count toward Conversations only.
+// EvaluationCost is in the evaluation budget's units, not USD.
+type CostBucket struct {
+	AgentVersion        string     `json:"agent_version,omitempty" db:"agent_version"`
+	Day                 *time.Time `json:"day,omitempty" db:"day"`
+	Conversations       int        `json:"conversations" db:"conversations"`
+	PricedConversations int        `json:"priced_conversations" db:"priced_conversations"`
+	InputTokens         int64      `json:"input_tokens" db:"input_tokens"`
+	OutputTokens        int64      `json:"output_tokens" db:"output_tokens"`
+	CostUSD             float64    `json:"cost_usd" db:"cost_usd"`
+	AvgCostUSD          *float64   `json:"avg_cost_usd" db:"avg_cost_usd"` // Per priced conversation
+	Evaluations         int        `json:"evaluations" db:"evaluations"`
+	EvaluationCost      float64    `json:"evaluation_cost" db:"evaluation_cost"`
+}
+
+// CostReport is the cost of an agent's conversations over a period, by
+// agent version or day
+type CostReport struct {
+	GroupBy             string       `json:"group_by"`
+	Since               time.Time    `json:"since"`
+	Conversations       int          `json:"conversations"`
+	PricedConversations int          `json:"priced_conversations"`
+	CostUSD             float64      `json:"cost_usd"`
+	Buckets             []CostBucket `json:"buckets"`
+}
+
+// VersionMarginal is the average score of one value of a version dimension
+// across every other dimension. Tool is set for the tool_version dimension.
+type VersionMarginal struct {
+	Dimension       string  `json:"dimension" db:"dimension"`
+	Tool            string  `json:"tool,omitempty" db:"tool"`
+	Value           string  `json:"value" db:"value"`
+	Evaluations     int     `json:"evaluations" db:"evaluations"`
+	AvgOverallScore float64 `json:"avg_overall_score" db:"avg_overall_score"`
+}
+
+// VersionEffect is how much the average score varies between the values of a
+// version dimension. The dimension with the largest spread is the likeliest
+// source of a regression.
+type VersionEffect struct {
+	Dimension string  `json:"dimension"`
+	Tool      string  `json:"tool,omitempty"`
+	Spread    float64 `json:"spread"` // Best minus worst average overall score
+	Best      string  `json:"best"`
+	Worst     string  `json:"worst"`
+	Values    int     `json:"values"`
+}
+
+// VersionComparison compares the latest scores of two agent versions'
+// conversations metric by metric
+type VersionComparison struct {
+	VersionA string                    `json:"version_a"`
+	VersionB string                    `json:"version_b"`
+	Since    time.Time                 `json:"since"`
+	Alpha    float64                   `json:"alpha"`
+	Metrics  []VersionMetricComparison `json:"metrics"`
+}
+
+// VersionMetricComparison is how one metric differs between two agent
+// versions. Tests are left out when either version has fewer than two
+// scores or a test is undefined, e.g. every score is the same.
+type VersionMetricComparison struct {
+	Metric      string    `json:"metric"`
+	CountA      int       `json:"count_a"`
+	CountB      int       `json:"count_b"`
+	MeanA       float64   `json:"mean_a"`
+	MeanB       float64   `json:"mean_b"`
+	StdDevA     float64   `json:"std_dev_a"`
+	StdDevB     float64   `json:"std_dev_b"`
+	Delta       float64   `json:"delta"`                  // Mean of B minus mean of A
+	TTest       *StatTest `json:"t_test,omitempty"`       // Welch's t-test
+	MannWhitney *StatTest `json:"mann_whitney,omitempty"` // Mann-Whitney U test, normal approximation
+	Significant bool      `json:"significant"`            // Both tests' p-values are below alpha
+	Better      string    `json:"better,omitempty"`       // version_a or version_b when significant
+}
+
+// StatTest is the outcome of a two-sided statistical test
+type StatTest struct {
+	Statistic float64 `json:"statistic"`
+	PValue    float64 `json:"p_value"`
+}
+
+// SnapshotFilter selects the conversations packaged into a snapshot. Empty
+// fields don't filter.
+type SnapshotFilter struct {
+	ConversationIDs []string   `json:"conversation_ids,omitempty"`
+	AgentVersion    string     `json:"agent_version,omitempty"`
+	IDPrefix        string     `json:"id_prefix,omitempty"`
+	CreatedAfter    *time.Time `json:"created_after,omitempty"`
+	CreatedBefore   *time.Time `json:"created_before,omitempty"`
+}
+
+// Snapshot is a portable archive of the pipeline configuration and a subset
+// of conversations with their evaluations and annotations, for reproducing
+// analyses in another environment
+type Snapshot struct {
+	Version       string         `json:"version"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Filter        SnapshotFilter `json:"filter"`
+	Config        PipelineConfig `json:"config"`
+	Conversations []Conversation `json:"conversations"`
+	Evaluations   []Evaluation   `json:"evaluations"`
+	Annotations   []Annotation   `json:"annotations"`
+}
+
+// SnapshotRestoreResult reports what a snapshot restore loaded. Conversations
+// that already exist are skipped along with their evaluations and
+// annotations, so restoring a snapshot twice loads it once.
+type SnapshotRestoreResult struct {
+	Conversations        int  `json:"conversations"`
+	SkippedConversations int  `json:"skipped_conversations"`
+	Evaluations          int  `json:"evaluations"`
+	Annotations          int  `json:"annotations"`
+	ConfigApplied        bool `json:"config_applied"`
+}
+
+// ViewPayload is a tool parameter or result object pretty-printed for
+// display. Payloads longer than the viewer's limit are cut to a preview,
+// and the full text can be fetched with ExpansionToken.
+type ViewPayload struct {
+	Language       string `json:"language"` // Syntax highlighting hint
+	Text           string `json:"text"`
+	Bytes          int    `json:"bytes"` // Length of the full text
+	Collapsed      bool   `json:"collapsed"`
+	ExpansionToken string `json:"expansion_token,omitempty"`
+}
+
+// ViewToolCall is a tool call prepared for display
+type ViewToolCall struct {
+	ID         string       `json:"id,omitempty"`
+	ToolName   string       `json:"tool_name"`
+	Parameters *ViewPayload `json:"parameters,omitempty"`
+	Result     *ViewPayload `json:"result,omitempty"`
+	LatencyMS  int          `json:"latency_ms,omitempty"`
+}
+
+// ViewIssue marks an issue detected by an evaluation
+type ViewIssue struct {
+	Type        string `json:"type"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+// ViewTurn is a turn prepared for display. Content is markdown with raw
+// HTML escaped, so it can be rendered as is.
+type ViewTurn struct {
+	TurnID      int            `json:"turn_id"`
+	Role        string         `json:"role"`
+	Content     string         `json:"content"`
+	ToolCalls   []ViewToolCall `json:"tool_calls,omitempty"`
+	ToolCallID  string         `json:"tool_call_id,omitempty"`
+	Name        string         `json:"name,omitempty"`
+	Result      *ViewPayload   `json:"result,omitempty"`
+	Attachments []Attachment   `json:"attachments,omitempty"`
+	Issues      []ViewIssue    `json:"issues"`
+	Timestamp   time.Time      `json:"timestamp"`
+}
+
+// ConversationView is a conversation prepared for display, with the issues
+// of an evaluation marked on the turns they refer to. Issues that don't
+// refer to a turn are listed in Issues.
+type ConversationView struct {
+	ConversationID string      `json:"conversation_id"`
+	AgentVersion   string      `json:"agent_version"`
+	EvaluationID   string      `json:"evaluation_id,omitempty"`
+	Turns          []ViewTurn  `json:"turns"`
+	Issues         []ViewIssue `json:"issues"`
+}
+
+// Activity event types streamed to the live ops view
+const (
+	ActivityConversationIngested = "conversation_ingested"
+	ActivityEvaluationCompleted  = "evaluation_completed"
+	ActivityAnnotationCreated    = "annotation_created"
+	ActivityCommentCreated       = "comment_created"
+)
+
+// ActivityEvent is something that happened in the system, published for
+// live activity views
+type ActivityEvent struct {
+	Type           string    `json:"type"`
+	ProjectID      string    `json:"project_id"`
+	ConversationID string    `json:"conversation_id"`
+	AgentVersion   string    `json:"agent_version"`
+	Turns          int       `json:"turns,omitempty"` // Turns ingested
+	EvaluationID   string    `json:"evaluation_id,omitempty"`
+	OverallScore   *float64  `json:"overall_score,omitempty"`
+	IssueCount     int       `json:"issue_count,omitempty"`
+	Severity       string    `json:"severity,omitempty"` // Highest issue severity of an evaluation
+	AnnotationType string    `json:"annotation_type,omitempty"`
+	AnnotatorID    string    `json:"annotator_id,omitempty"`
+	CommentID      int64     `json:"comment_id,omitempty"`
+	Mentions       []string  `json:"mentions,omitempty"` // Names mentioned in a comment
+	At             time.Time `json:"at"`
+}
+
+// ActivityFilter selects the activity events a client receives. Empty
+// fields match everything; MinSeverity only filters evaluation events.
+type ActivityFilter struct {
+	AgentVersion string   `json:"agent_version,omitempty"`
+	MinSeverity  string   `json:"min_severity,omitempty"`
+	Types        []string `json:"types,omitempty"`
+}
+
+// Resources a saved view can list
+const (
+	ViewResourceConversations = "conversations"
+	ViewResourceEvaluations   = "evaluations"
+)
+
+// SavedView is a named filter and sort order over conversations or
+// evaluations, shared by a project's team
+type SavedView struct {
+	ID          int64           `json:"id" db:"id"`
+	ProjectID   string          `json:"project_id" db:"project_id"`
+	Name        string          `json:"name" db:"name"`
+	Description string          `json:"description" db:"description"`
+	Resource    string          `json:"resource" db:"resource"`
+	Filters     json.RawMessage `json:"filters" db:"filters"`
+	CreatedBy   string          `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// ViewFilters select and order the items of a saved view. Empty fields
+// match everything. WithinDays is resolved when the view is run, so a view
+// of the last 7 days stays current.
+type ViewFilters struct {
+	AgentVersion  string   `json:"agent_version,omitempty"`
+	Intent        string   `json:"intent,omitempty"`
+	TriggerSource string   `json:"trigger_source,omitempty"` // Evaluations only
+	MinScore      *float64 `json:"min_score,omitempty"`      // Of conversations, their latest evaluation
+	MaxScore      *float64 `json:"max_score,omitempty"`
+	IssueType     string   `json:"issue_type,omitempty"`
+	IssueSeverity string   `json:"issue_severity,omitempty" binding:"omitempty,oneof=low medium high critical"`
+	Verdict       string   `json:"verdict,omitempty" binding:"omitempty,oneof=pass fail needs_review"`
+	WithinDays    int      `json:"within_days,omitempty"` // Created in the last days
+	SortBy        string   `json:"sort_by,omitempty"`     // created_at or score, or updated_at for conversations
+	SortOrder     string   `json:"sort_order,omitempty" binding:"omitempty,oneof=asc desc"`
+}
+
+// SavedViewUpdate represents input for changing a saved view
+type SavedViewUpdate struct {
+	Description string      `json:"description"`
+	Resource    string      `json:"resource" binding:"required,oneof=conversations evaluations"`
+	Filters     ViewFilters `json:"filters"`
+}
+
+// SavedViewCreate represents input for saving a view
+type SavedViewCreate struct {
+	Name      string `json:"name" binding:"required"`
+	CreatedBy string `json:"created_by"`
+	SavedViewUpdate
+}
+
+// Comment is a reviewer comment on a conversation, one of its turns or one
+// of its evaluations. Top-level comments start threads and carry the
+// thread's resolve state; replies share their thread's target.
+type Comment struct {
+	ID             int64           `json:"id" db:"id"`
+	ConversationID string          `json:"conversation_id" db:"conversation_id"`
+	TurnID         *int            `json:"turn_id,omitempty" db:"turn_id"`
+	EvaluationID   *string         `json:"evaluation_id,omitempty" db:"evaluation_id"`
+	ParentID       *int64          `json:"parent_id,omitempty" db:"parent_id"`
+	Author         string          `json:"author" db:"author"`
+	Body           string          `json:"body" db:"body"`
+	Mentions       json.RawMessage `json:"mentions" db:"mentions"` // Names mentioned with @name in the body
+	Resolved       bool            `json:"resolved" db:"resolved"`
+	ResolvedBy     string          `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt     *time.Time      `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// CommentThread is a top-level comment with its replies, oldest first
+type CommentThread struct {
+	Comment
+	Replies []Comment `json:"replies"`
+}
+
+// CommentCreate represents input for commenting. A comment targets the
+// conversation, or one of its turns or evaluations; a reply names its
+// thread's comment in ParentID and takes the thread's target. Author is
+// ignored for authenticated callers.
+type CommentCreate struct {
+	Body         string `json:"body" binding:"required"`
+	Author       string `json:"author"`
+	TurnID       *int   `json:"turn_id"`
+	EvaluationID string `json:"evaluation_id"`
+	ParentID     *int64 `json:"parent_id"`
+}
+
+// CommentUpdate represents input for editing a comment
+type CommentUpdate struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// CommentResolution resolves or reopens a comment thread. ResolvedBy is
+// ignored for authenticated callers.
+type CommentResolution struct {
+	Resolved   bool   `json:"resolved"`
+	ResolvedBy string `json:"resolved_by"`
+}
+
+// CommentFilter selects comment threads of a conversation. Empty fields
+// match every thread.
+type CommentFilter struct {
+	TurnID       *int
+	EvaluationID string
+	Resolved     *bool
+}
+
+// Dataset is a named set of items an agent release is regression tested
+// on. Conversations are produced from an item by the caller and ingested
+// with the item's key in metadata.dataset_item.
+type Dataset struct {
+	ID          int64         `json:"id" db:"id"`
+	ProjectID   string        `json:"project_id" db:"project_id"`
+	Name        string        `json:"name" db:"name"`
+	Description string        `json:"description" db:"description"`
+	CreatedBy   string        `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
+	Items       []DatasetItem `json:"items,omitempty" db:"-"`
+}
+
+// DatasetItem is a case of a dataset, such as a scripted user request
+type DatasetItem struct {
+	ItemKey     string          `json:"item_key" db:"item_key" binding:"required,max=255"`
+	Description string          `json:"description" db:"description"`
+	Metadata    json.RawMessage `json:"metadata,omitempty" db:"metadata"` // Free-form, e.g. the input and expected outcome
+}
+
+// DatasetCreate represents input for creating a dataset. CreatedBy is
+// ignored for authenticated callers.
+type DatasetCreate struct {
+	Name        string        `json:"name" binding:"required,max=255"`
+	Description string        `json:"description"`
+	CreatedBy   string        `json:"created_by"`
+	Items       []DatasetItem `json:"items" binding:"dive"`
+}
+
+// DatasetItemsUpdate replaces the items of a dataset
+type DatasetItemsUpdate struct {
+	Items []DatasetItem `json:"items" binding:"dive"`
+}
+
+// Dataset run states
+const (
+	DatasetRunRunning   = "running"
+	DatasetRunCompleted = "completed"
+)
+
+// Dataset run item states
+const (
+	DatasetRunItemPending   = "pending"   // Evaluation queued
+	DatasetRunItemEvaluated = "evaluated" // Evaluation stored
+	DatasetRunItemMissing   = "missing"   // No conversation of the agent version for the item
+	DatasetRunItemFailed    = "failed"    // Evaluation failed or couldn't be queued
+)
+
+// DatasetRun evaluates a dataset's items for an agent version. The scores
+// are aggregated over the evaluated items as their evaluations are stored.
+type DatasetRun struct {
+	ID             int64            `json:"id" db:"id"`
+	DatasetID      int64            `json:"dataset_id" db:"dataset_id"`
+	AgentVersion   string           `json:"agent_version" db:"agent_version"`
+	Status         string           `json:"status" db:"status"`
+	ItemCount      int              `json:"item_count" db:"item_count"`
+	EvaluatedCount int              `json:"evaluated_count" db:"evaluated_count"`
+	MissingCount   int              `json:"missing_count" db:"missing_count"`
+	FailedCount    int              `json:"failed_count" db:"failed_count"`
+	MeanScores     json.RawMessage  `json:"mean_scores" db:"mean_scores"`       // Mean of each score, overall included
+	VerdictCounts  json.RawMessage  `json:"verdict_counts" db:"verdict_counts"` // Evaluated items by verdict
+	PassRate       *float64         `json:"pass_rate" db:"pass_rate"`           // Share of evaluated items that passed; nil until one is
+	CreatedBy      string           `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+	CompletedAt    *time.Time       `json:"completed_at,omitempty" db:"completed_at"`
+	StaleAt        *time.Time       `json:"stale_at,omitempty" db:"stale_at"` // When conversations of the run were reassigned to another agent version
+	Items          []DatasetRunItem `json:"items,omitempty" db:"-"`
+}
+
+// DatasetRunItem is the outcome of a dataset item in a run
+type DatasetRunItem struct {
+	RunID          int64           `json:"-" db:"run_id"`
+	ItemKey        string          `json:"item_key" db:"item_key"`
+	ConversationID *string         `json:"conversation_id" db:"conversation_id"`
+	TaskID         string          `json:"task_id,omitempty" db:"task_id"`
+	EvaluationID   *string         `json:"evaluation_id" db:"evaluation_id"`
+	Status         string          `json:"status" db:"status"`
+	OverallScore   *float64        `json:"overall_score" db:"overall_score"`
+	Scores         json.RawMessage `json:"scores" db:"scores"`
+	Verdict        string          `json:"verdict,omitempty" db:"verdict"`
+}
+
+// DatasetRunCreate represents input for starting a dataset run. CreatedBy
+// is ignored for authenticated callers.
+type DatasetRunCreate struct {
+	AgentVersion string `json:"agent_version" binding:"required,max=100"`
+	CreatedBy    string `json:"created_by"`
+}
+
+// Changes of a dataset item between two runs
+const (
+	DatasetItemRegressed = "regressed"
+	DatasetItemImproved  = "improved"
+	DatasetItemUnchanged = "unchanged"
+	DatasetItemAdded     = "added"   // Evaluated only in the candidate run
+	DatasetItemRemoved   = "removed" // Evaluated only in the base run
+)
+
+// DatasetRunComparison compares a candidate run of a dataset to a base run.
+// Items regress when their score drops by more than the threshold or their
+// verdict gets worse, and improve on the reverse.
+type DatasetRunComparison struct {
+	Base          DatasetRun          `json:"base"`
+	Candidate     DatasetRun          `json:"candidate"`
+	Threshold     float64             `json:"threshold"`
+	ScoreDeltas   map[string]float64  `json:"score_deltas"` // Candidate minus base mean, for scores both runs have
+	PassRateDelta *float64            `json:"pass_rate_delta"`
+	Regressions   int                 `json:"regressions"`
+	Improvements  int                 `json:"improvements"`
+	Items         []DatasetItemChange `json:"items"`
+}
+
+// DatasetItemChange compares a dataset item's outcomes in two runs
+type DatasetItemChange struct {
+	ItemKey          string   `json:"item_key"`
+	Change           string   `json:"change"`
+	BaseScore        *float64 `json:"base_score"`
+	CandidateScore   *float64 `json:"candidate_score"`
+	Delta            *float64 `json:"delta"`
+	BaseVerdict      string   `json:"base_verdict,omitempty"`
+	CandidateVerdict string   `json:"candidate_verdict,omitempty"`
+}
+
+// AnnotatorDeanonymization names the annotator pseudonyms of a project's
+// exports and reports to map back to annotator IDs
+type AnnotatorDeanonymization struct {
+	Pseudonyms []string `json:"pseudonyms" binding:"required,min=1,max=1000"`
+}
@@ -0,0 +1,77 @@
+// Package notify delivers alerts and digests to the channels teams chose in
+// their notification preferences. Slack messages are posted right away;
+// webhook deliveries are stored and sent, with retries, by the webhook
+// dispatcher; email is queued on a Redis outbox for the mail relay, as the
+// service doesn't send mail itself.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/ai-agent-eval/internal/slack"
+	"github.com/ai-agent-eval/internal/webhook"
+)
+
+// Notifier delivers notifications on each channel they are addressed to
+type Notifier struct {
+	slack    *slack.Notifier // Nil without a Slack webhook
+	webhooks *webhook.Dispatcher
+	queue    *queue.RedisQueue
+}
+
+// New creates a notifier. A nil Slack notifier drops Slack deliveries.
+func New(slackNotifier *slack.Notifier, webhooks *webhook.Dispatcher, redisQueue *queue.RedisQueue) *Notifier {
+	return &Notifier{slack: slackNotifier, webhooks: webhooks, queue: redisQueue}
+}
+
+// Alert delivers an alert on each of its channels. Alerts addressed to no
+// team, because no team has preferences, are posted to Slack. Every channel
+// is attempted; the errors of those that failed are returned together.
+func (n *Notifier) Alert(ctx context.Context, delivery *services.AlertDelivery) error {
+	if delivery.Team == "" {
+		return n.slack.NotifyAlert(ctx, delivery)
+	}
+
+	var errs []error
+	for _, channel := range delivery.Channels {
+		var err error
+		switch channel {
+		case services.ChannelSlack:
+			err = n.slack.NotifyAlert(ctx, delivery)
+		case services.ChannelWebhook:
+			_, err = n.webhooks.DispatchNotification(delivery.ProjectID, models.WebhookEventAlert, delivery)
+		case services.ChannelEmail:
+			err = n.queue.PushOutbox(services.ChannelEmail, delivery)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Digest delivers a team's digest on each of its channels. Every channel is
+// attempted; the errors of those that failed are returned together.
+func (n *Notifier) Digest(ctx context.Context, digest *models.OwnerDigest) error {
+	var errs []error
+	for _, channel := range digest.Channels {
+		var err error
+		switch channel {
+		case services.ChannelSlack:
+			err = n.slack.NotifyDigest(ctx, digest)
+		case services.ChannelWebhook:
+			_, err = n.webhooks.DispatchNotification(digest.ProjectID, models.WebhookEventDigest, digest)
+		case services.ChannelEmail:
+			err = n.queue.PushOutbox(services.ChannelEmail, digest)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// Keys of the evaluation backfill shared by every API replica. The lock
+// names the replica running it and expires unless renewed, so a backfill
+// whose replica died doesn't block the next one.
+const (
+	backfillStatusKey = "backfill_status"
+	backfillLockKey   = "backfill_lock"
+)
+
+// saveBackfillScript stores a backfill's progress and renews its lock, or
+// releases the lock when ARGV[3] is 0, only while the caller still holds it
+var saveBackfillScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[2], ARGV[2])
+if tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+else
+	redis.call('DEL', KEYS[1])
+end
+return 1
+`)
+
+// StartBackfill takes the backfill lock for lease and stores the starting
+// progress. It returns an empty token if another backfill holds the lock.
+func (q *RedisQueue) StartBackfill(status *models.BackfillStatus, lease time.Duration) (string, error) {
+	token := uuid.New().String()
+	locked, err := q.client.SetNX(q.ctx, backfillLockKey, token, lease).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to lock backfill: %w", err)
+	}
+	if !locked {
+		return "", nil
+	}
+	if err := q.Set(backfillStatusKey, status, 0); err != nil {
+		q.client.Del(q.ctx, backfillLockKey)
+		return "", fmt.Errorf("failed to store backfill progress: %w", err)
+	}
+	return token, nil
+}
+
+// SaveBackfill stores the progress of the backfill holding token and renews
+// its lock for lease. A lease of 0 releases the lock once the backfill is
+// done. It returns false if the lock was lost.
+func (q *RedisQueue) SaveBackfill(token string, status *models.BackfillStatus, lease time.Duration) (bool, error) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal backfill progress: %w", err)
+	}
+	saved, err := saveBackfillScript.Run(q.ctx, q.client, []string{backfillLockKey, backfillStatusKey},
+		token, data, lease.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to store backfill progress: %w", err)
+	}
+	return saved == 1, nil
+}
+
+// GetBackfill returns the progress of the latest backfill, or nil if there
+// has been none, and whether a replica still holds its lock
+func (q *RedisQueue) GetBackfill() (*models.BackfillStatus, bool, error) {
+	pipe := q.client.Pipeline()
+	statusCmd := pipe.Get(q.ctx, backfillStatusKey)
+	lockCmd := pipe.Exists(q.ctx, backfillLockKey)
+	if _, err := pipe.Exec(q.ctx); err != nil && err != redis.Nil {
+		return nil, false, fmt.Errorf("failed to get backfill progress: %w", err)
+	}
+
+	data, err := statusCmd.Bytes()
+	if err == redis.Nil {
+		return nil, lockCmd.Val() > 0, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get backfill progress: %w", err)
+	}
+	var status models.BackfillStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal backfill progress: %w", err)
+	}
+	return &status, lockCmd.Val() > 0, nil
+}
@@ -0,0 +1,146 @@
+package queue
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Payload encodings a task's envelope may name. Tasks without one carry
+// their payload as is.
+const (
+	PayloadEncodingGzip = "gzip"
+)
+
+// CompressionStats are the lifetime totals of a queue's compressed tasks
+type CompressionStats struct {
+	Tasks        int64   `json:"tasks"`         // Tasks whose payload was compressed
+	RawBytes     int64   `json:"raw_bytes"`     // Size of those payloads before compression
+	EncodedBytes int64   `json:"encoded_bytes"` // and after
+	SavedBytes   int64   `json:"saved_bytes"`
+	Ratio        float64 `json:"ratio"` // EncodedBytes over RawBytes
+}
+
+// compressionKey is the hash of a queue's compression totals
+func compressionKey(queueName string) string {
+	return "queue_compression:" + queueName
+}
+
+// SetCompressionThreshold gzips the payloads of tasks queued from now on
+// whose JSON is larger than threshold bytes. Zero disables compression.
+// Consumers decompress tasks by their envelope, whatever the threshold.
+func (q *RedisQueue) SetCompressionThreshold(threshold int) {
+	q.compressionThreshold = threshold
+}
+
+// marshalTask encodes a task for Redis, compressing its payload when it is
+// over the queue's threshold
+func (q *RedisQueue) marshalTask(queueName string, task *Task) ([]byte, error) {
+	encoded, err := q.encodeTask(queueName, task)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encoded)
+}
+
+// encodeTask returns a copy of a task with its payload compressed when it is
+// over the queue's threshold, or the task itself
+func (q *RedisQueue) encodeTask(queueName string, task *Task) (*Task, error) {
+	if q.compressionThreshold <= 0 || len(task.Payload) == 0 || task.PayloadEncoding != "" {
+		return task, nil
+	}
+
+	raw, err := json.Marshal(task.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	if len(raw) <= q.compressionThreshold {
+		return task, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, fmt.Errorf("failed to compress payload: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress payload: %w", err)
+	}
+	if buf.Len() >= len(raw) {
+		return task, nil
+	}
+
+	encoded := *task
+	encoded.Payload = nil
+	encoded.PayloadEncoding = PayloadEncodingGzip
+	encoded.EncodedPayload = buf.Bytes()
+
+	// Stats are best effort and must not fail the enqueue
+	q.recordCompression(queueName, len(raw), buf.Len())
+	return &encoded, nil
+}
+
+// unmarshalTask decodes a task from Redis, decompressing its payload
+func unmarshalTask(data []byte, task *Task) error {
+	if err := json.Unmarshal(data, task); err != nil {
+		return err
+	}
+	return task.decodePayload()
+}
+
+// decodePayload restores a payload compressed by marshalTask. Tasks named
+// with an encoding this build doesn't know fail rather than run without
+// their payload.
+func (t *Task) decodePayload() error {
+	switch t.PayloadEncoding {
+	case "":
+		return nil
+	case PayloadEncodingGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(t.EncodedPayload))
+		if err != nil {
+			return fmt.Errorf("failed to decompress payload: %w", err)
+		}
+		raw, err := io.ReadAll(zr)
+		if err != nil {
+			return fmt.Errorf("failed to decompress payload: %w", err)
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+		t.Payload, t.PayloadEncoding, t.EncodedPayload = payload, "", nil
+		return nil
+	}
+	return fmt.Errorf("unsupported payload encoding %q", t.PayloadEncoding)
+}
+
+// recordCompression adds a compressed payload to a queue's totals
+func (q *RedisQueue) recordCompression(queueName string, rawBytes, encodedBytes int) error {
+	pipe := q.client.TxPipeline()
+	pipe.HIncrBy(q.ctx, compressionKey(queueName), "tasks", 1)
+	pipe.HIncrBy(q.ctx, compressionKey(queueName), "raw_bytes", int64(rawBytes))
+	pipe.HIncrBy(q.ctx, compressionKey(queueName), "encoded_bytes", int64(encodedBytes))
+	_, err := pipe.Exec(q.ctx)
+	return err
+}
+
+// Compression returns a queue's compression totals
+func (q *RedisQueue) Compression(queueName string) (CompressionStats, error) {
+	values, err := q.client.HGetAll(q.ctx, compressionKey(queueName)).Result()
+	if err != nil {
+		return CompressionStats{}, fmt.Errorf("failed to get compression stats: %w", err)
+	}
+
+	var stats CompressionStats
+	stats.Tasks, _ = strconv.ParseInt(values["tasks"], 10, 64)
+	stats.RawBytes, _ = strconv.ParseInt(values["raw_bytes"], 10, 64)
+	stats.EncodedBytes, _ = strconv.ParseInt(values["encoded_bytes"], 10, 64)
+	stats.SavedBytes = stats.RawBytes - stats.EncodedBytes
+	if stats.RawBytes > 0 {
+		stats.Ratio = float64(stats.EncodedBytes) / float64(stats.RawBytes)
+	}
+	return stats, nil
+}
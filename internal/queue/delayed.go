@@ -0,0 +1,125 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ai-agent-eval/internal/faults"
+	"github.com/go-redis/redis/v8"
+)
+
+// delayedKey is the sorted set of tasks waiting to be queued, either for a
+// retry or at the time they were scheduled for, scored by when they are due
+func delayedKey(queueName string) string {
+	return "queue_delayed:" + queueName
+}
+
+// promoteScript moves due tasks from the delayed set onto the queue
+// atomically, so each is moved by exactly one replica
+var promoteScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, task in ipairs(due) do
+	redis.call('ZREM', KEYS[1], task)
+	redis.call('RPUSH', KEYS[2], task)
+end
+return #due
+`)
+
+// promoteBatchSize caps how many due tasks one promotion moves
+const promoteBatchSize = 100
+
+// EnqueueAt schedules a task to be queued at when. Tasks due already are
+// queued right away. Scheduled tasks are queued by PromoteDelayed, which
+// workers call every second.
+func (q *RedisQueue) EnqueueAt(queueName string, task *Task, when time.Time) error {
+	if !when.After(time.Now()) {
+		return q.Enqueue(queueName, task)
+	}
+
+	if err := q.delay(queueName, task, when); err != nil {
+		return fmt.Errorf("failed to schedule task: %w", err)
+	}
+
+	// Status is best effort and must not fail the enqueue
+	q.markTaskScheduled(task, when)
+	return nil
+}
+
+// delay adds a task to the queue's delayed set, due at when
+func (q *RedisQueue) delay(queueName string, task *Task, when time.Time) error {
+	if err := q.faults.Inject(q.ctx, faults.TargetRedis); err != nil {
+		return err
+	}
+
+	data, err := q.marshalTask(queueName, task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+	member := &redis.Z{Score: float64(when.UnixMilli()), Member: data}
+	return q.client.ZAdd(q.ctx, delayedKey(queueName), member).Err()
+}
+
+// PromoteDelayed queues the tasks that are due, whether retries or
+// scheduled tasks, and returns how many were queued
+func (q *RedisQueue) PromoteDelayed(queueName string) (int, error) {
+	if fairQueue(queueName) {
+		return q.promoteDelayedProjects(queueName)
+	}
+
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	moved, err := promoteScript.Run(q.ctx, q.client, []string{delayedKey(queueName), queueName}, now, promoteBatchSize).Int()
+	if err != nil {
+		return 0, fmt.Errorf("failed to promote delayed tasks: %w", err)
+	}
+	return moved, nil
+}
+
+// promoteDelayedProjects queues the due tasks of a fair queue on their
+// projects' sub-queues. Only the replica that removes a task from the
+// delayed set queues it; tasks that can't be decoded are dead-lettered as
+// stored.
+func (q *RedisQueue) promoteDelayedProjects(queueName string) (int, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	due, err := q.client.ZRangeByScore(q.ctx, delayedKey(queueName), &redis.ZRangeBy{
+		Min: "-inf", Max: now, Count: promoteBatchSize,
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to promote delayed tasks: %w", err)
+	}
+
+	moved := 0
+	for _, member := range due {
+		removed, err := q.client.ZRem(q.ctx, delayedKey(queueName), member).Result()
+		if err != nil {
+			return moved, fmt.Errorf("failed to promote delayed tasks: %w", err)
+		}
+		if removed == 0 {
+			continue // Promoted by another replica
+		}
+
+		var task Task
+		if err := json.Unmarshal([]byte(member), &task); err != nil {
+			if dlqErr := q.deadLetterRaw(queueName, member, fmt.Errorf("failed to unmarshal delayed task: %w", err)); dlqErr != nil {
+				// Put it back rather than lose it
+				q.client.ZAdd(q.ctx, delayedKey(queueName), &redis.Z{Score: 0, Member: member})
+				return moved, fmt.Errorf("failed to promote delayed tasks: %w", dlqErr)
+			}
+			continue
+		}
+		if err := q.enqueueProject(queueName, &task, []byte(member)); err != nil {
+			// Put it back rather than lose it
+			q.client.ZAdd(q.ctx, delayedKey(queueName), &redis.Z{Score: 0, Member: member})
+			return moved, fmt.Errorf("failed to promote delayed tasks: %w", err)
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// DelayedCount returns how many of a queue's tasks are waiting to be
+// retried or for their scheduled time
+func (q *RedisQueue) DelayedCount(queueName string) (int64, error) {
+	return q.client.ZCard(q.ctx, delayedKey(queueName)).Result()
+}
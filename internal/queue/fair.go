@@ -0,0 +1,236 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ai-agent-eval/internal/faults"
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/go-redis/redis/v8"
+)
+
+// FairQueues are the queues split into a sub-queue per project, so one
+// project's backlog can't starve the others. Consumers choose which
+// project to dequeue from next with DequeueProject.
+var FairQueues = []string{QueueEvaluations}
+
+// fairQueue reports whether a queue is split by project
+func fairQueue(queueName string) bool {
+	for _, name := range FairQueues {
+		if name == queueName {
+			return true
+		}
+	}
+	return false
+}
+
+// projectQueueKey is the sub-queue of a project's tasks
+func projectQueueKey(queueName, projectID string) string {
+	return queueName + ":project:" + projectID
+}
+
+// projectsKey is the set of projects with tasks in a queue's sub-queues
+func projectsKey(queueName string) string {
+	return "queue_projects:" + queueName
+}
+
+// inFlightKey is the sorted set of a project's tasks being worked on,
+// scored by when their lease ends. Leases end on their own, so a worker that
+// stops mid-task doesn't hold its slot forever.
+func inFlightKey(queueName, projectID string) string {
+	return "queue_in_flight:" + queueName + ":" + projectID
+}
+
+// taskProject returns the project a task's sub-queue belongs to
+func taskProject(task *Task) string {
+	if task.ProjectID == "" {
+		return models.DefaultProjectID
+	}
+	return task.ProjectID
+}
+
+// dequeueProjectScript pops a project's next task unless the project
+// already has maxInFlight tasks in flight, and leases it, atomically so
+// concurrent workers can't exceed the cap. A project whose sub-queue is
+// drained is dropped from the queue's projects; enqueues add it back in the
+// same transaction as the push, so none is missed. A task that isn't valid
+// JSON is still popped, unleased, for the caller to dead-letter.
+var dequeueProjectScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+local cap = tonumber(ARGV[3])
+if cap > 0 and redis.call('ZCARD', KEYS[2]) >= cap then
+	return false
+end
+local task = redis.call('LPOP', KEYS[1])
+if redis.call('LLEN', KEYS[1]) == 0 then
+	redis.call('SREM', KEYS[3], ARGV[4])
+end
+if not task then
+	return false
+end
+local ok, decoded = pcall(cjson.decode, task)
+if ok and type(decoded) == 'table' and type(decoded.id) == 'string' then
+	redis.call('ZADD', KEYS[2], ARGV[2], decoded.id)
+end
+return task
+`)
+
+// enqueueProject adds a task to its project's sub-queue
+func (q *RedisQueue) enqueueProject(queueName string, task *Task, data []byte) error {
+	projectID := taskProject(task)
+	pipe := q.client.TxPipeline()
+	pipe.RPush(q.ctx, projectQueueKey(queueName, projectID), data)
+	pipe.SAdd(q.ctx, projectsKey(queueName), projectID)
+	_, err := pipe.Exec(q.ctx)
+	return err
+}
+
+// QueueProjects lists the projects with tasks in a queue's sub-queues
+func (q *RedisQueue) QueueProjects(queueName string) ([]string, error) {
+	projects, err := q.client.SMembers(q.ctx, projectsKey(queueName)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queue projects: %w", err)
+	}
+	sort.Strings(projects)
+	return projects, nil
+}
+
+// DequeueProject removes and returns the next task of a project without
+// blocking, leasing it for lease. It returns nil if the project has no
+// tasks or already has maxInFlight tasks in flight; 0 means no cap. Workers
+// release the lease with Release once the task is done.
+func (q *RedisQueue) DequeueProject(queueName, projectID string, maxInFlight int, lease time.Duration) (*Task, error) {
+	if err := q.faults.Inject(q.ctx, faults.TargetRedis); err != nil {
+		return nil, fmt.Errorf("failed to dequeue task: %w", err)
+	}
+
+	now := time.Now()
+	keys := []string{projectQueueKey(queueName, projectID), inFlightKey(queueName, projectID), projectsKey(queueName)}
+	result, err := dequeueProjectScript.Run(q.ctx, q.client, keys,
+		now.UnixMilli(), now.Add(lease).UnixMilli(), maxInFlight, projectID).Text()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to dequeue task: %w", err)
+	}
+
+	var task Task
+	if err := unmarshalTask([]byte(result), &task); err != nil {
+		if task.ID != "" {
+			q.client.ZRem(q.ctx, inFlightKey(queueName, projectID), task.ID)
+		}
+		return nil, q.undecodable(queueName, result, err)
+	}
+
+	// The task is already popped, so a stats failure must not lose it
+	q.recordEvent(queueName, "dequeued")
+	q.recordEvent(projectQueueKey(queueName, projectID), "dequeued")
+
+	return &task, nil
+}
+
+// DequeueUnassigned removes and returns, without blocking, a task queued on
+// a fair queue itself rather than a project's sub-queue, as tasks were
+// before queues were split by project. It returns nil if there is none.
+func (q *RedisQueue) DequeueUnassigned(queueName string) (*Task, error) {
+	result, err := q.client.LPop(q.ctx, queueName).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to dequeue task: %w", err)
+	}
+
+	var task Task
+	if err := unmarshalTask([]byte(result), &task); err != nil {
+		return nil, q.undecodable(queueName, result, err)
+	}
+
+	q.recordEvent(queueName, "dequeued")
+	return &task, nil
+}
+
+// Release ends the lease of a task dequeued with DequeueProject
+func (q *RedisQueue) Release(queueName string, task *Task) error {
+	return q.client.ZRem(q.ctx, inFlightKey(queueName, taskProject(task)), task.ID).Err()
+}
+
+// ProjectQueueStats describes the backlog and throughput of a project's
+// sub-queue
+type ProjectQueueStats struct {
+	ProjectID            string  `json:"project_id"`
+	Length               int64   `json:"length"`
+	InFlight             int64   `json:"in_flight"`
+	DequeuedPerMinute    float64 `json:"dequeued_per_minute"`
+	OldestTaskAgeSeconds float64 `json:"oldest_task_age_seconds"`
+}
+
+// ProjectStats returns the backlog and throughput of each project with
+// tasks queued or in flight on a fair queue
+func (q *RedisQueue) ProjectStats(queueName string) ([]ProjectQueueStats, error) {
+	projects, err := q.QueueProjects(queueName)
+	if err != nil {
+		return nil, err
+	}
+	// Projects whose sub-queue is drained still have tasks in flight
+	seen := make(map[string]bool, len(projects))
+	for _, projectID := range projects {
+		seen[projectID] = true
+	}
+	prefix := len(inFlightKey(queueName, ""))
+	iter := q.client.Scan(q.ctx, 0, inFlightKey(queueName, "*"), 100).Iterator()
+	for iter.Next(q.ctx) {
+		if projectID := iter.Val()[prefix:]; !seen[projectID] {
+			seen[projectID] = true
+			projects = append(projects, projectID)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list in-flight tasks: %w", err)
+	}
+	sort.Strings(projects)
+
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	stats := make([]ProjectQueueStats, 0, len(projects))
+	for _, projectID := range projects {
+		s := ProjectQueueStats{ProjectID: projectID}
+		key := projectQueueKey(queueName, projectID)
+		if s.Length, err = q.client.LLen(q.ctx, key).Result(); err != nil {
+			return nil, fmt.Errorf("failed to get queue length: %w", err)
+		}
+		if s.InFlight, err = q.client.ZCount(q.ctx, inFlightKey(queueName, projectID), "("+now, "+inf").Result(); err != nil {
+			return nil, fmt.Errorf("failed to count in-flight tasks: %w", err)
+		}
+		if s.DequeuedPerMinute, err = q.eventRate(key, "dequeued"); err != nil {
+			return nil, fmt.Errorf("failed to get dequeue rate: %w", err)
+		}
+		if s.OldestTaskAgeSeconds, err = q.headAge(key); err != nil {
+			return nil, err
+		}
+		if s.Length == 0 && s.InFlight == 0 {
+			continue
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// headAge returns the age of the oldest task of a list. Tasks are pushed on
+// the right and popped from the left, so the head is the oldest.
+func (q *RedisQueue) headAge(key string) (float64, error) {
+	head, err := q.client.LIndex(q.ctx, key, 0).Result()
+	if err != nil && err != redis.Nil {
+		return 0, fmt.Errorf("failed to peek queue: %w", err)
+	}
+	if head != "" {
+		var task Task
+		if err := json.Unmarshal([]byte(head), &task); err == nil && !task.CreatedAt.IsZero() {
+			return time.Since(task.CreatedAt).Seconds(), nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,110 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// maintenanceKey holds the maintenance mode flag shared by every API replica
+// and worker
+const maintenanceKey = "maintenance_mode"
+
+// DefaultMaintenanceRetryAfter is how long clients are told to wait before
+// retrying a write rejected during maintenance
+const DefaultMaintenanceRetryAfter = 60 * time.Second
+
+// Maintenance describes an active read-only maintenance window. Writes are
+// rejected and queues are paused until it is cleared or expires.
+type Maintenance struct {
+	Reason            string     `json:"reason,omitempty"`
+	StartedBy         string     `json:"started_by,omitempty"`
+	StartedAt         time.Time  `json:"started_at"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"` // Nil when it lasts until cleared
+	RetryAfterSeconds int        `json:"retry_after_seconds"`
+}
+
+// SetMaintenance puts every replica and worker in read-only maintenance
+// mode. A positive ttl ends it automatically.
+func (q *RedisQueue) SetMaintenance(m *Maintenance, ttl time.Duration) error {
+	if ttl > 0 {
+		expiresAt := m.StartedAt.Add(ttl)
+		m.ExpiresAt = &expiresAt
+	}
+	if err := q.Set(maintenanceKey, m, ttl); err != nil {
+		return fmt.Errorf("failed to set maintenance mode: %w", err)
+	}
+	return nil
+}
+
+// ClearMaintenance ends maintenance mode
+func (q *RedisQueue) ClearMaintenance() error {
+	if err := q.Delete(maintenanceKey); err != nil {
+		return fmt.Errorf("failed to clear maintenance mode: %w", err)
+	}
+	return nil
+}
+
+// GetMaintenance returns the active maintenance window, or nil if there is none
+func (q *RedisQueue) GetMaintenance() (*Maintenance, error) {
+	var m Maintenance
+	data, err := q.client.Get(q.ctx, maintenanceKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance mode: %w", err)
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse maintenance mode: %w", err)
+	}
+	return &m, nil
+}
+
+// MaintenanceCache caches the maintenance mode flag so it can be checked on
+// every request or dequeue without a Redis round trip each time
+type MaintenanceCache struct {
+	queue  *RedisQueue
+	maxAge time.Duration
+
+	mu        sync.Mutex
+	current   *Maintenance
+	checkedAt time.Time
+}
+
+// NewMaintenanceCache creates a cache that reads the flag again once it is
+// older than maxAge
+func NewMaintenanceCache(q *RedisQueue, maxAge time.Duration) *MaintenanceCache {
+	return &MaintenanceCache{queue: q, maxAge: maxAge}
+}
+
+// Get returns the active maintenance window, or nil if there is none. If
+// Redis can't be reached the last known state is kept.
+func (c *MaintenanceCache) Get() *Maintenance {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checkedAt) < c.maxAge {
+		return c.current
+	}
+	c.checkedAt = time.Now()
+
+	m, err := c.queue.GetMaintenance()
+	if err != nil {
+		log.Printf("Failed to check maintenance mode: %v", err)
+		return c.current
+	}
+	c.current = m
+	return m
+}
+
+// Refresh makes the next Get read the flag from Redis
+func (c *MaintenanceCache) Refresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkedAt = time.Time{}
+}
@@ -0,0 +1,26 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// outboxKey is the list of notifications waiting, oldest first, for an
+// external relay to deliver them on a channel the service doesn't deliver on
+// itself, such as email
+func outboxKey(channel string) string {
+	return "notification_outbox:" + channel
+}
+
+// PushOutbox appends a notification to a channel's outbox. Unlike pub/sub
+// messages, notifications stay queued until the relay pops them.
+func (q *RedisQueue) PushOutbox(channel string, message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	if err := q.client.RPush(q.ctx, outboxKey(channel), data).Err(); err != nil {
+		return fmt.Errorf("failed to queue notification: %w", err)
+	}
+	return nil
+}
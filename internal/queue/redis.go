@@ -6,23 +6,81 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ai-agent-eval/internal/faults"
+	"github.com/ai-agent-eval/internal/models"
 	"github.com/go-redis/redis/v8"
 )
 
+// Trigger sources recorded on evaluation tasks
+const (
+	TriggerAutoIngest   = "auto_ingest"
+	TriggerManual       = "manual"
+	TriggerBatch        = "batch"
+	TriggerReevaluation = "reevaluation"
+	TriggerBackfill     = "backfill"
+	TriggerUpload       = "upload"
+	TriggerOTLP         = "otlp"
+	TriggerRegression   = "regression"
+	TriggerStale        = "stale_reevaluation"
+)
+
+// Task types
+const (
+	TaskEvaluate = "evaluate"
+	// TaskCompleteEvaluation re-runs the failed evaluator types of the partial
+	// evaluation named by the evaluation_id payload key and merges the results
+	TaskCompleteEvaluation = "complete_evaluation"
+)
+
 // Task represents a queue task
 type Task struct {
-	ID             string                 `json:"id"`
-	Type           string                 `json:"type"`
-	ConversationID string                 `json:"conversation_id"`
-	EvaluatorTypes []string               `json:"evaluator_types,omitempty"`
-	Payload        map[string]interface{} `json:"payload,omitempty"`
-	CreatedAt      time.Time              `json:"created_at"`
+	ID                  string                   `json:"id"`
+	Type                string                   `json:"type"`
+	ConversationID      string                   `json:"conversation_id"`
+	ProjectID           string                   `json:"project_id,omitempty"` // Sub-queue of fair queues; the default project if empty
+	EvaluatorTypes      []string                 `json:"evaluator_types,omitempty"`
+	EvaluatorVersions   map[string]string        `json:"evaluator_versions,omitempty"`
+	TriggerSource       string                   `json:"trigger_source,omitempty"`
+	FromTurnID          int                      `json:"from_turn_id,omitempty"` // Only evaluate turns from this one on
+	EvaluatorTimeoutsMS map[string]int           `json:"evaluator_timeouts_ms,omitempty"`
+	TimeoutMS           int                      `json:"timeout_ms,omitempty"` // Counted from when a worker starts the task
+	Budget              *models.EvaluationBudget `json:"budget,omitempty"`     // Evaluators run one at a time within it when set
+	Payload             map[string]interface{}   `json:"payload,omitempty"`
+	PayloadEncoding     string                   `json:"payload_encoding,omitempty"` // Set when the payload travels compressed in EncodedPayload
+	EncodedPayload      []byte                   `json:"encoded_payload,omitempty"`
+	Attempts            int                      `json:"attempts,omitempty"`   // Failed attempts so far
+	LastError           string                   `json:"last_error,omitempty"` // Error of the last failed attempt
+	CreatedAt           time.Time                `json:"created_at"`
+}
+
+// Context returns the context a worker evaluates the task under, ending at
+// the task's overall deadline if it has one
+func (t *Task) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	if t.TimeoutMS <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, time.Duration(t.TimeoutMS)*time.Millisecond)
+}
+
+// EvaluatorTimeouts returns the time each evaluator type may take. Types
+// without a timeout are bounded only by the task's deadline.
+func (t *Task) EvaluatorTimeouts() map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(t.EvaluatorTimeoutsMS))
+	for evaluatorType, ms := range t.EvaluatorTimeoutsMS {
+		if ms > 0 {
+			timeouts[evaluatorType] = time.Duration(ms) * time.Millisecond
+		}
+	}
+	return timeouts
 }
 
 // RedisQueue implements queue operations using Redis
 type RedisQueue struct {
 	client *redis.Client
 	ctx    context.Context
+	faults *faults.Injector
+
+	compressionThreshold int
 }
 
 // NewRedisQueue creates a new Redis queue
@@ -51,18 +109,47 @@ func (q *RedisQueue) Close() error {
 	return q.client.Close()
 }
 
-// Enqueue adds a task to the queue
+// SetFaults injects faults into Enqueue and Dequeue
+func (q *RedisQueue) SetFaults(injector *faults.Injector) {
+	q.faults = injector
+}
+
+// Enqueue adds a task to the queue, or to its project's sub-queue of a
+// fair queue
 func (q *RedisQueue) Enqueue(queueName string, task *Task) error {
-	data, err := json.Marshal(task)
+	if err := q.faults.Inject(q.ctx, faults.TargetRedis); err != nil {
+		return err
+	}
+
+	data, err := q.marshalTask(queueName, task)
 	if err != nil {
 		return fmt.Errorf("failed to marshal task: %w", err)
 	}
 
-	return q.client.RPush(q.ctx, queueName, data).Err()
+	if fairQueue(queueName) {
+		err = q.enqueueProject(queueName, task, data)
+	} else {
+		err = q.client.RPush(q.ctx, queueName, data).Err()
+	}
+	if err != nil {
+		return err
+	}
+	if fairQueue(queueName) {
+		q.recordEvent(projectQueueKey(queueName, taskProject(task)), "enqueued")
+	}
+
+	// Stats and status are best effort and must not fail the enqueue
+	q.recordEvent(queueName, "enqueued")
+	q.markTaskQueued(task)
+	return nil
 }
 
 // Dequeue removes and returns a task from the queue
 func (q *RedisQueue) Dequeue(queueName string, timeout time.Duration) (*Task, error) {
+	if err := q.faults.Inject(q.ctx, faults.TargetRedis); err != nil {
+		return nil, fmt.Errorf("failed to dequeue task: %w", err)
+	}
+
 	result, err := q.client.BLPop(q.ctx, timeout, queueName).Result()
 	if err != nil {
 		if err == redis.Nil {
@@ -76,16 +163,36 @@ func (q *RedisQueue) Dequeue(queueName string, timeout time.Duration) (*Task, er
 	}
 
 	var task Task
-	if err := json.Unmarshal([]byte(result[1]), &task); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal task: %w", err)
+	if err := unmarshalTask([]byte(result[1]), &task); err != nil {
+		return nil, q.undecodable(queueName, result[1], err)
 	}
 
+	// The task is already popped, so a stats failure must not lose it
+	q.recordEvent(queueName, "dequeued")
+
 	return &task, nil
 }
 
-// QueueLength returns the number of tasks in the queue
+// QueueLength returns the number of tasks in the queue, including every
+// project's sub-queue of a fair queue
 func (q *RedisQueue) QueueLength(queueName string) (int64, error) {
-	return q.client.LLen(q.ctx, queueName).Result()
+	length, err := q.client.LLen(q.ctx, queueName).Result()
+	if err != nil || !fairQueue(queueName) {
+		return length, err
+	}
+
+	projects, err := q.QueueProjects(queueName)
+	if err != nil {
+		return 0, err
+	}
+	for _, projectID := range projects {
+		n, err := q.client.LLen(q.ctx, projectQueueKey(queueName, projectID)).Result()
+		if err != nil {
+			return 0, err
+		}
+		length += n
+	}
+	return length, nil
 }
 
 // Set stores a value with expiration
@@ -114,6 +221,10 @@ func (q *RedisQueue) Delete(key string) error {
 	return q.client.Del(q.ctx, key).Err()
 }
 
+// ActivityChannel is the pub/sub channel ingestion, evaluation and annotation
+// events are published on for live activity views
+const ActivityChannel = "system_activity"
+
 // Publish publishes a message to a channel
 func (q *RedisQueue) Publish(channel string, message interface{}) error {
 	data, err := json.Marshal(message)
@@ -122,3 +233,27 @@ func (q *RedisQueue) Publish(channel string, message interface{}) error {
 	}
 	return q.client.Publish(q.ctx, channel, data).Err()
 }
+
+// Subscribe calls handler with every message published on a channel until
+// ctx is cancelled. Messages published while disconnected are lost.
+func (q *RedisQueue) Subscribe(ctx context.Context, channel string, handler func(message []byte)) error {
+	pubsub := q.client.Subscribe(ctx, channel)
+	defer pubsub.Close()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", channel, err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			handler([]byte(msg.Payload))
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy decides when a failed task is attempted again. Retries back
+// off exponentially from BaseDelay up to MaxDelay; a task that has failed
+// MaxAttempts times is moved to the queue's dead-letter list.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Backoff returns how long to wait before retrying a task that has failed
+// attempts times
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// DeadLetter is a task that failed every attempt, or one that couldn't be
+// decoded, kept as Raw
+type DeadLetter struct {
+	Task     Task      `json:"task"`
+	Raw      string    `json:"raw,omitempty"` // Undecodable task as stored in Redis
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// deadLetterKey is the list of a queue's dead-lettered tasks, oldest first
+func deadLetterKey(queueName string) string {
+	return "queue_dead_letters:" + queueName
+}
+
+// Retry records a failed attempt of a task. Below the policy's attempt limit
+// the task is scheduled to be queued again after its backoff; otherwise it
+// is dead-lettered. It reports whether the task was dead-lettered.
+func (q *RedisQueue) Retry(queueName string, task *Task, taskErr error, policy RetryPolicy) (bool, error) {
+	task.Attempts++
+	task.LastError = taskErr.Error()
+
+	if task.Attempts >= policy.MaxAttempts {
+		return true, q.deadLetter(queueName, task, taskErr)
+	}
+
+	retryAt := time.Now().Add(policy.Backoff(task.Attempts))
+	if err := q.delay(queueName, task, retryAt); err != nil {
+		return false, fmt.Errorf("failed to schedule retry: %w", err)
+	}
+
+	// Status is best effort and must not fail the retry
+	q.markTaskRetrying(task, taskErr, retryAt)
+	return false, nil
+}
+
+// deadLetter appends a task to the queue's dead-letter list
+func (q *RedisQueue) deadLetter(queueName string, task *Task, taskErr error) error {
+	encoded, err := q.encodeTask(queueName, task)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(DeadLetter{Task: *encoded, Error: taskErr.Error(), FailedAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+	if err := q.client.RPush(q.ctx, deadLetterKey(queueName), data).Err(); err != nil {
+		return fmt.Errorf("failed to dead-letter task: %w", err)
+	}
+
+	q.MarkTaskFailed(task, taskErr)
+	return nil
+}
+
+// deadLetterRaw appends a task that couldn't be decoded to the queue's
+// dead-letter list as stored, so it can be inspected rather than lost
+func (q *RedisQueue) deadLetterRaw(queueName, raw string, decodeErr error) error {
+	data, err := json.Marshal(DeadLetter{Raw: raw, Error: decodeErr.Error(), FailedAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+	if err := q.client.RPush(q.ctx, deadLetterKey(queueName), data).Err(); err != nil {
+		return fmt.Errorf("failed to dead-letter task: %w", err)
+	}
+	return nil
+}
+
+// undecodable dead-letters a popped task that couldn't be decoded, since
+// it is already off its queue, and returns the error to report for it
+func (q *RedisQueue) undecodable(queueName, raw string, decodeErr error) error {
+	err := fmt.Errorf("failed to unmarshal task: %w", decodeErr)
+	if dlqErr := q.deadLetterRaw(queueName, raw, err); dlqErr != nil {
+		return errors.Join(err, dlqErr)
+	}
+	return err
+}
+
+// ListDeadLetters returns up to limit of a queue's dead-lettered tasks,
+// oldest first, after skipping offset of them
+func (q *RedisQueue) ListDeadLetters(queueName string, limit, offset int) ([]DeadLetter, error) {
+	values, err := q.client.LRange(q.ctx, deadLetterKey(queueName), int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	letters := make([]DeadLetter, 0, len(values))
+	for _, value := range values {
+		// An entry this build can't read shouldn't hide the others
+		var letter DeadLetter
+		if err := json.Unmarshal([]byte(value), &letter); err != nil {
+			continue
+		}
+		if err := letter.Task.decodePayload(); err != nil {
+			continue
+		}
+		letters = append(letters, letter)
+	}
+	return letters, nil
+}
+
+// RequeueDeadLetter moves a dead-lettered task back onto its queue with its
+// attempts reset. It returns nil if the task is not dead-lettered.
+func (q *RedisQueue) RequeueDeadLetter(queueName, taskID string) (*Task, error) {
+	values, err := q.client.LRange(q.ctx, deadLetterKey(queueName), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	for _, value := range values {
+		var letter DeadLetter
+		if err := json.Unmarshal([]byte(value), &letter); err != nil || letter.Task.ID != taskID {
+			continue
+		}
+		if err := letter.Task.decodePayload(); err != nil {
+			return nil, fmt.Errorf("failed to decode dead letter: %w", err)
+		}
+
+		// Only the caller that removes the entry requeues it
+		removed, err := q.client.LRem(q.ctx, deadLetterKey(queueName), 1, value).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove dead letter: %w", err)
+		}
+		if removed == 0 {
+			return nil, nil
+		}
+
+		task := letter.Task
+		task.Attempts = 0
+		task.LastError = ""
+		if err := q.Enqueue(queueName, &task); err != nil {
+			// Put it back rather than lose it
+			q.client.RPush(q.ctx, deadLetterKey(queueName), value)
+			return nil, fmt.Errorf("failed to requeue task: %w", err)
+		}
+		return &task, nil
+	}
+
+	return nil, nil
+}
+
+// DeadLetterCount returns how many tasks a queue has dead-lettered
+func (q *RedisQueue) DeadLetterCount(queueName string) (int64, error) {
+	return q.client.LLen(q.ctx, deadLetterKey(queueName)).Result()
+}
@@ -0,0 +1,130 @@
+package queue
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Queue names
+const (
+	QueueEvaluations = "evaluations"
+)
+
+// Backlogs reported alongside the queues. They are measured in the database:
+// ingest is the conversation imports still running and annotations the
+// annotation tasks not yet completed.
+const (
+	BacklogIngest      = "ingest"
+	BacklogAnnotations = "annotations"
+)
+
+// MonitoredQueues are the Redis queues reported by Stats callers
+var MonitoredQueues = []string{QueueEvaluations}
+
+// RateWindow is the period over which enqueue and dequeue rates are averaged.
+// Counts are kept in per-minute buckets so every replica contributes.
+const RateWindow = 5 * time.Minute
+
+// QueueStats describes the throughput and backlog of a queue
+type QueueStats struct {
+	Queue                string           `json:"queue"`
+	Length               int64            `json:"length"`
+	EnqueuedPerMinute    float64          `json:"enqueued_per_minute"`
+	DequeuedPerMinute    float64          `json:"dequeued_per_minute"`
+	OldestTaskAgeSeconds float64          `json:"oldest_task_age_seconds"`
+	Delayed              int64            `json:"delayed"`      // Tasks waiting to be retried or for their scheduled time
+	DeadLetters          int64            `json:"dead_letters"` // Tasks that failed every attempt
+	Compression          CompressionStats `json:"compression"`
+}
+
+// rateKey is the counter for one minute of enqueues or dequeues
+func rateKey(queueName, event string, minute int64) string {
+	return fmt.Sprintf("queue_stats:%s:%s:%d", queueName, event, minute)
+}
+
+// recordEvent counts an enqueue or dequeue in the current minute bucket
+func (q *RedisQueue) recordEvent(queueName, event string) error {
+	key := rateKey(queueName, event, time.Now().Unix()/60)
+
+	pipe := q.client.TxPipeline()
+	pipe.Incr(q.ctx, key)
+	pipe.Expire(q.ctx, key, RateWindow+2*time.Minute)
+	_, err := pipe.Exec(q.ctx)
+	return err
+}
+
+// eventRate returns the average per-minute count over the last complete minutes of the window
+func (q *RedisQueue) eventRate(queueName, event string) (float64, error) {
+	minutes := int64(RateWindow / time.Minute)
+	current := time.Now().Unix() / 60
+
+	keys := make([]string, 0, minutes)
+	for minute := current - minutes; minute < current; minute++ {
+		keys = append(keys, rateKey(queueName, event, minute))
+	}
+
+	values, err := q.client.MGet(q.ctx, keys...).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			count, _ := strconv.ParseInt(s, 10, 64)
+			total += count
+		}
+	}
+
+	return float64(total) / float64(minutes), nil
+}
+
+// Stats returns the current backlog and throughput of a queue
+func (q *RedisQueue) Stats(queueName string) (*QueueStats, error) {
+	stats := &QueueStats{Queue: queueName}
+
+	length, err := q.QueueLength(queueName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue length: %w", err)
+	}
+	stats.Length = length
+
+	if stats.Delayed, err = q.DelayedCount(queueName); err != nil {
+		return nil, fmt.Errorf("failed to count delayed tasks: %w", err)
+	}
+	if stats.DeadLetters, err = q.DeadLetterCount(queueName); err != nil {
+		return nil, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+	if stats.Compression, err = q.Compression(queueName); err != nil {
+		return nil, err
+	}
+
+	if stats.EnqueuedPerMinute, err = q.eventRate(queueName, "enqueued"); err != nil {
+		return nil, fmt.Errorf("failed to get enqueue rate: %w", err)
+	}
+	if stats.DequeuedPerMinute, err = q.eventRate(queueName, "dequeued"); err != nil {
+		return nil, fmt.Errorf("failed to get dequeue rate: %w", err)
+	}
+
+	if stats.OldestTaskAgeSeconds, err = q.headAge(queueName); err != nil {
+		return nil, err
+	}
+	if fairQueue(queueName) {
+		projects, err := q.QueueProjects(queueName)
+		if err != nil {
+			return nil, err
+		}
+		for _, projectID := range projects {
+			age, err := q.headAge(projectQueueKey(queueName, projectID))
+			if err != nil {
+				return nil, err
+			}
+			if age > stats.OldestTaskAgeSeconds {
+				stats.OldestTaskAgeSeconds = age
+			}
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,140 @@
+package queue
+
+import (
+	"fmt"
+	"time"
+)
+
+// Task states, in the order a task passes through them
+const (
+	TaskStateQueued    = "queued"
+	TaskStateRunning   = "running"
+	TaskStateCompleted = "completed"
+	TaskStateFailed    = "failed"
+)
+
+// taskStatusTTL is how long a task's status is kept after its last update
+const taskStatusTTL = 7 * 24 * time.Hour
+
+// TaskStatus is the progress of a queued task
+type TaskStatus struct {
+	TaskID         string     `json:"task_id"`
+	Type           string     `json:"type"`
+	ConversationID string     `json:"conversation_id"`
+	TriggerSource  string     `json:"trigger_source,omitempty"`
+	State          string     `json:"state"`
+	EvaluationID   string     `json:"evaluation_id,omitempty"` // Set once completed
+	Error          string     `json:"error,omitempty"`         // Set once failed
+	Attempts       int        `json:"attempts"`                // Times a worker started the task
+	QueuedAt       time.Time  `json:"queued_at"`
+	RetryAt        *time.Time `json:"retry_at,omitempty"`     // Set while a failed task waits to be retried
+	ScheduledAt    *time.Time `json:"scheduled_at,omitempty"` // Set while a scheduled task waits to be queued
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func taskStatusKey(taskID string) string {
+	return "task_status:" + taskID
+}
+
+// GetTaskStatus returns the status of a task, or nil if it is unknown or
+// its status has expired
+func (q *RedisQueue) GetTaskStatus(taskID string) (*TaskStatus, error) {
+	var status *TaskStatus
+	if err := q.Get(taskStatusKey(taskID), &status); err != nil {
+		return nil, fmt.Errorf("failed to get task status: %w", err)
+	}
+	return status, nil
+}
+
+// MarkTaskRunning records that a worker started a task
+func (q *RedisQueue) MarkTaskRunning(task *Task) error {
+	return q.updateTaskStatus(task, func(status *TaskStatus, now time.Time) {
+		status.State = TaskStateRunning
+		status.Attempts++
+		status.StartedAt = &now
+		status.RetryAt = nil
+		status.ScheduledAt = nil
+		status.Error = ""
+	})
+}
+
+// MarkTaskCompleted records that a task stored its result
+func (q *RedisQueue) MarkTaskCompleted(task *Task, evaluationID string) error {
+	return q.updateTaskStatus(task, func(status *TaskStatus, now time.Time) {
+		status.State = TaskStateCompleted
+		status.EvaluationID = evaluationID
+		status.FinishedAt = &now
+	})
+}
+
+// MarkTaskFailed records that a task failed
+func (q *RedisQueue) MarkTaskFailed(task *Task, taskErr error) error {
+	return q.updateTaskStatus(task, func(status *TaskStatus, now time.Time) {
+		status.State = TaskStateFailed
+		status.Error = taskErr.Error()
+		status.FinishedAt = &now
+	})
+}
+
+// markTaskRetrying records that a failed task is queued again at retryAt.
+// The error of the failed attempt is kept until the retry starts.
+func (q *RedisQueue) markTaskRetrying(task *Task, taskErr error, retryAt time.Time) error {
+	return q.updateTaskStatus(task, func(status *TaskStatus, now time.Time) {
+		status.State = TaskStateQueued
+		status.Error = taskErr.Error()
+		retryAt = retryAt.UTC()
+		status.RetryAt = &retryAt
+		status.StartedAt = nil
+	})
+}
+
+// markTaskScheduled records that a task will be queued at scheduledAt
+func (q *RedisQueue) markTaskScheduled(task *Task, scheduledAt time.Time) error {
+	return q.updateTaskStatus(task, func(status *TaskStatus, now time.Time) {
+		status.State = TaskStateQueued
+		scheduledAt = scheduledAt.UTC()
+		status.ScheduledAt = &scheduledAt
+		status.StartedAt = nil
+		status.FinishedAt = nil
+	})
+}
+
+// markTaskQueued records that a task was queued, keeping its attempts if it
+// is queued again
+func (q *RedisQueue) markTaskQueued(task *Task) error {
+	return q.updateTaskStatus(task, func(status *TaskStatus, now time.Time) {
+		status.State = TaskStateQueued
+		status.QueuedAt = now
+		status.StartedAt = nil
+		status.FinishedAt = nil
+	})
+}
+
+// updateTaskStatus applies an update to a task's stored status, starting
+// from the task itself when none is stored
+func (q *RedisQueue) updateTaskStatus(task *Task, update func(status *TaskStatus, now time.Time)) error {
+	status, err := q.GetTaskStatus(task.ID)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		status = &TaskStatus{
+			TaskID:         task.ID,
+			Type:           task.Type,
+			ConversationID: task.ConversationID,
+			TriggerSource:  task.TriggerSource,
+			QueuedAt:       task.CreatedAt,
+		}
+	}
+
+	now := time.Now().UTC()
+	update(status, now)
+	status.UpdatedAt = now
+
+	if err := q.Set(taskStatusKey(task.ID), status, taskStatusTTL); err != nil {
+		return fmt.Errorf("failed to set task status: %w", err)
+	}
+	return nil
+}
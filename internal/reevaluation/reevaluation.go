@@ -0,0 +1,158 @@
+// Package reevaluation keeps a calibration baseline fresh. When the scoring
+// configuration changes materially, a sample of old conversations last
+// evaluated under an earlier one is queued for re-evaluation, a batch at a
+// time, so that scores before and after the change can be compared without
+// a manual backfill.
+package reevaluation
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+	"github.com/ai-agent-eval/internal/repository"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/google/uuid"
+)
+
+// Result reports what a run did
+type Result struct {
+	Fingerprint string // Of the newest evaluation's scoring configuration
+	Detected    bool   // The configuration was seen for the first time
+	Enqueued    int
+}
+
+// Refresher queues stale conversations for re-evaluation
+type Refresher struct {
+	cfg      *config.Config
+	repo     *repository.Repository
+	queue    *queue.RedisQueue
+	pipeline *services.ConfigStore
+}
+
+// New creates a refresher queuing evaluations with the pipeline's current
+// evaluator types and budget
+func New(cfg *config.Config, repo *repository.Repository, redisQueue *queue.RedisQueue, pipeline *services.ConfigStore) *Refresher {
+	return &Refresher{cfg: cfg, repo: repo, queue: redisQueue, pipeline: pipeline}
+}
+
+// Run records the scoring configuration of the newest evaluation and queues
+// up to BatchSize conversations evaluated before it was first seen, until
+// StaleReevaluationSampleSize have been queued for it. Conversations are
+// re-evaluated at most once per configuration.
+func (r *Refresher) Run(ctx context.Context) (*Result, error) {
+	result := &Result{}
+
+	manifest, err := r.repo.GetLatestScoringManifest()
+	if err != nil || manifest == nil {
+		return result, err
+	}
+	result.Fingerprint, err = services.ScoringFingerprint(manifest)
+	if err != nil || result.Fingerprint == "" {
+		return result, err
+	}
+	result.Detected, err = r.repo.RecordReevaluationBaseline(result.Fingerprint, manifest, r.cfg.StaleReevaluationSampleSize)
+	if err != nil {
+		return result, err
+	}
+
+	baseline, err := r.repo.GetOpenReevaluationBaseline()
+	if err != nil || baseline == nil {
+		return result, err
+	}
+	limit := baseline.Target - baseline.Enqueued
+	if limit > r.cfg.BatchSize {
+		limit = r.cfg.BatchSize
+	}
+
+	stale, err := r.repo.SampleStaleConversations(baseline.Fingerprint, baseline.DetectedAt, time.Now().UTC().Add(-r.cfg.StaleReevaluationMinAge), limit)
+	if err != nil {
+		return result, err
+	}
+	if len(stale) == 0 {
+		return result, r.repo.CompleteReevaluationBaseline(baseline.Fingerprint)
+	}
+
+	evaluatorTypes, warnings := services.CheckEvaluatorTypes(r.pipeline.Get().DefaultEvaluatorTypes, services.HasLLMCredentials(r.cfg))
+	for _, warning := range warnings {
+		log.Printf("Skipping evaluator for stale re-evaluation: %s", warning.Message)
+	}
+	if len(evaluatorTypes) == 0 {
+		return result, errors.New("no runnable evaluators")
+	}
+	versions, err := r.evaluatorVersions(evaluatorTypes)
+	if err != nil {
+		return result, err
+	}
+
+	enqueued := make([]string, 0, len(stale))
+	for _, conv := range stale {
+		if ctx.Err() != nil {
+			break
+		}
+		task := r.newTask(conv, evaluatorTypes, versions)
+		if err = r.queue.Enqueue(queue.QueueEvaluations, task); err != nil {
+			break
+		}
+		if recordErr := r.repo.RecordPipelineTask(task.ID, task.ConversationID, task.TriggerSource, time.Time{}, task.CreatedAt); recordErr != nil {
+			log.Printf("Failed to record pipeline timings for task %s: %v", task.ID, recordErr)
+		}
+		enqueued = append(enqueued, conv.ConversationID)
+	}
+	result.Enqueued = len(enqueued)
+
+	// Queued conversations are recorded even if the batch was cut short, so
+	// they aren't queued again
+	if len(enqueued) > 0 {
+		if recordErr := r.repo.RecordReevaluationSamples(baseline.Fingerprint, enqueued); recordErr != nil {
+			return result, recordErr
+		}
+	}
+	return result, err
+}
+
+// evaluatorVersions picks the evaluator versions of a batch of tasks the
+// way the API does for the tasks it queues
+func (r *Refresher) evaluatorVersions(evaluatorTypes []string) (map[string]string, error) {
+	promoted, err := r.repo.GetPromotedEvaluatorVersions()
+	if err != nil {
+		return nil, err
+	}
+	rollouts, err := r.repo.ListEvaluatorRollouts(models.RolloutActive)
+	if err != nil {
+		return nil, err
+	}
+	return services.ChooseEvaluatorVersions(evaluatorTypes, promoted, rollouts), nil
+}
+
+// newTask builds the evaluation task of a stale conversation with the
+// configured timeouts and budget
+func (r *Refresher) newTask(conv models.StaleConversation, evaluatorTypes []string, versions map[string]string) *queue.Task {
+	task := &queue.Task{
+		ID:                uuid.New().String(),
+		Type:              queue.TaskEvaluate,
+		ConversationID:    conv.ConversationID,
+		ProjectID:         conv.ProjectID,
+		EvaluatorTypes:    evaluatorTypes,
+		EvaluatorVersions: versions,
+		TriggerSource:     queue.TriggerStale,
+		TimeoutMS:         r.cfg.EvaluationTimeoutSeconds * 1000,
+		CreatedAt:         time.Now(),
+	}
+	for _, evaluatorType := range evaluatorTypes {
+		if seconds := r.cfg.EvaluatorTimeoutSeconds[evaluatorType]; seconds > 0 {
+			if task.EvaluatorTimeoutsMS == nil {
+				task.EvaluatorTimeoutsMS = make(map[string]int)
+			}
+			task.EvaluatorTimeoutsMS[evaluatorType] = seconds * 1000
+		}
+	}
+	if budget := r.pipeline.Get().Budget; services.BudgetActive(&budget) {
+		task.Budget = &budget
+	}
+	return task
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/lib/pq"
+)
+
+// ReassignAgentVersion sets the agent version of the selected conversations,
+// in one transaction, and corrects the affected versions of failure patterns
+// whose example conversations were reassigned. Dataset runs that measured
+// reassigned conversations are marked stale, as their agent version and
+// scores no longer describe one version. Conversations that already
+// have the new version aren't selected. On a dry run everything is rolled
+// back, so the result reports exactly what would change. It returns
+// ErrTooManyConversations without changing anything when more than
+// maxConversations match.
+func (r *Repository) ReassignAgentVersion(req *models.AgentVersionReassignment, dryRun bool, maxConversations int) (*models.AgentVersionReassignmentResult, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var selected []struct {
+		ConversationID string `db:"conversation_id"`
+		AgentVersion   string `db:"agent_version"`
+	}
+	query := `
+		SELECT conversation_id, agent_version FROM conversations
+		WHERE (cardinality($1::text[]) = 0 OR conversation_id = ANY($1))
+			AND ($2 = '' OR agent_version = $2)
+			AND ($3 = '' OR left(conversation_id, length($3)) = $3)
+			AND ($4::timestamp IS NULL OR created_at >= $4)
+			AND ($5::timestamp IS NULL OR created_at < $5)
+			AND agent_version <> $6
+		ORDER BY conversation_id
+		LIMIT $7
+		FOR UPDATE
+	`
+	err = tx.Select(&selected, query, pq.Array(req.ConversationIDs), req.AgentVersion, req.IDPrefix,
+		req.CreatedAfter, req.CreatedBefore, req.NewAgentVersion, maxConversations+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select conversations: %w", err)
+	}
+	if len(selected) > maxConversations {
+		return nil, ErrTooManyConversations
+	}
+
+	result := &models.AgentVersionReassignmentResult{
+		DryRun:          dryRun,
+		ConversationIDs: make([]string, len(selected)),
+		FromVersions:    make(map[string]int),
+		FailurePatterns: []string{},
+		DatasetRuns:     []int64{},
+	}
+	from := make(map[string]bool)
+	for i, conv := range selected {
+		result.ConversationIDs[i] = conv.ConversationID
+		result.FromVersions[conv.AgentVersion]++
+		from[conv.AgentVersion] = true
+	}
+	if len(selected) == 0 {
+		return result, nil
+	}
+
+	_, err = tx.Exec(`UPDATE conversations SET agent_version = $1, updated_at = NOW() WHERE conversation_id = ANY($2)`,
+		req.NewAgentVersion, pq.Array(result.ConversationIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reassign agent version: %w", err)
+	}
+
+	var patterns []models.FailurePattern
+	err = tx.Select(&patterns, `
+		SELECT * FROM failure_patterns
+		WHERE jsonb_exists_any(COALESCE(example_conversations, '[]'), $1)
+		ORDER BY pattern_id
+		FOR UPDATE
+	`, pq.Array(result.ConversationIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to select failure patterns: %w", err)
+	}
+	for _, pattern := range patterns {
+		// Versions of the examples, reassigned ones included
+		var versions []string
+		err := tx.Select(&versions, `
+			SELECT DISTINCT agent_version FROM conversations
+			WHERE conversation_id IN (SELECT jsonb_array_elements_text($1::jsonb))
+		`, pattern.ExampleConversations)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get example versions of pattern %s: %w", pattern.PatternID, err)
+		}
+		exampleVersions := make(map[string]bool, len(versions))
+		for _, version := range versions {
+			exampleVersions[version] = true
+		}
+
+		affected, changed, err := services.CorrectAffectedVersions(pattern.AffectedVersions, from, exampleVersions, req.NewAgentVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse affected versions of pattern %s: %w", pattern.PatternID, err)
+		}
+		if !changed {
+			continue
+		}
+		_, err = tx.Exec(`UPDATE failure_patterns SET affected_versions = $1, updated_at = NOW() WHERE id = $2`, affected, pattern.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to correct pattern %s: %w", pattern.PatternID, err)
+		}
+		result.FailurePatterns = append(result.FailurePatterns, pattern.PatternID)
+	}
+
+	err = tx.Select(&result.DatasetRuns, `
+		UPDATE dataset_runs SET stale_at = COALESCE(stale_at, NOW())
+		WHERE id IN (SELECT run_id FROM dataset_run_items WHERE conversation_id = ANY($1))
+		RETURNING id
+	`, pq.Array(result.ConversationIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark dataset runs stale: %w", err)
+	}
+	sort.Slice(result.DatasetRuns, func(i, j int) bool { return result.DatasetRuns[i] < result.DatasetRuns[j] })
+
+	if dryRun {
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit reassignment: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/lib/pq"
+)
+
+// GetToolLatencyStats aggregates tool call latency SLA breaches per tool over
+// conversations created between since and asOf.
+// Tools missing from slas are measured against defaultLatencyMS.
+func (r *Repository) GetToolLatencyStats(slas map[string]int, defaultLatencyMS int, since, asOf time.Time) ([]models.ToolLatencyStats, error) {
+	toolNames := make([]string, 0, len(slas))
+	expected := make([]int64, 0, len(slas))
+	for name, latency := range slas {
+		toolNames = append(toolNames, name)
+		expected = append(expected, int64(latency))
+	}
+
+	args := []interface{}{pq.Array(toolNames), pq.Array(expected), defaultLatencyMS, since, asOf}
+	query := `
+		SELECT
+			tc->>'tool_name' AS tool_name,
+			COALESCE(sla.expected_ms, $3) AS expected_latency_ms,
+			COUNT(*) AS total_calls,
+			COUNT(*) FILTER (WHERE (tc->>'latency_ms')::int > COALESCE(sla.expected_ms, $3)) AS breaches,
+			AVG((tc->>'latency_ms')::int) AS avg_latency_ms
+		FROM conversations c
+		CROSS JOIN LATERAL jsonb_array_elements(c.turns) t
+		CROSS JOIN LATERAL jsonb_array_elements(COALESCE(t->'tool_calls', '[]'::jsonb)) tc
+		LEFT JOIN unnest($1::text[], $2::int[]) AS sla(tool_name, expected_ms)
+			ON sla.tool_name = tc->>'tool_name'
+		WHERE c.created_at >= $4 AND c.created_at <= $5 AND tc ? 'latency_ms'` + r.projectFilter("c.project_id", &args) + `
+		GROUP BY tc->>'tool_name', sla.expected_ms
+		ORDER BY tool_name
+	`
+
+	var stats []models.ToolLatencyStats
+	if err := r.db.Select(&stats, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get tool latency stats: %w", err)
+	}
+
+	for i := range stats {
+		if stats[i].TotalCalls > 0 {
+			stats[i].BreachRate = float64(stats[i].Breaches) / float64(stats[i].TotalCalls)
+		}
+	}
+
+	return stats, nil
+}
+
+// GetThroughput returns ingestion and evaluation counts per time bucket
+// between since and asOf.
+// interval must be a valid date_trunc field such as "hour" or "day".
+func (r *Repository) GetThroughput(interval string, since, asOf time.Time) ([]models.ThroughputBucket, error) {
+	args := []interface{}{interval, since, asOf}
+	query := `
+		WITH buckets AS (
+			SELECT generate_series(date_trunc($1, $2::timestamp), date_trunc($1, $3::timestamp), ('1 ' || $1)::interval) AS bucket
+		),
+		ingested AS (
+			SELECT date_trunc($1, created_at) AS bucket, COUNT(*) AS cnt
+			FROM conversations
+			WHERE created_at >= $2 AND created_at <= $3` + r.projectFilter("project_id", &args) + `
+			GROUP BY 1
+		),
+		evaluated AS (
+			SELECT date_trunc($1, created_at) AS bucket, COUNT(*) AS cnt, AVG(evaluation_duration_ms) AS avg_duration
+			FROM evaluations
+			WHERE created_at >= $2 AND created_at <= $3` + r.projectFilter("project_id", &args) + `
+			GROUP BY 1
+		)
+		SELECT
+			b.bucket,
+			COALESCE(i.cnt, 0) AS conversations_ingested,
+			COALESCE(e.cnt, 0) AS evaluations_completed,
+			e.avg_duration AS avg_evaluation_duration_ms
+		FROM buckets b
+		LEFT JOIN ingested i ON i.bucket = b.bucket
+		LEFT JOIN evaluated e ON e.bucket = b.bucket
+		ORDER BY b.bucket
+	`
+
+	var buckets []models.ThroughputBucket
+	if err := r.db.Select(&buckets, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get throughput: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// TimeSeriesIntervals are the bucket sizes of metric time series
+var TimeSeriesIntervals = map[string]bool{"hour": true, "day": true, "week": true}
+
+// GetTimeSeries aggregates a metric over evaluations created between since
+// and asOf per time bucket, with percentiles. Every bucket in the range is
+// returned, with nil statistics when it has no evaluations. interval must
+// be one of TimeSeriesIntervals and metric one of the slice metrics; an empty
+// agentVersion includes every agent version.
+func (r *Repository) GetTimeSeries(metric, interval, agentVersion string, since, asOf time.Time) ([]models.TimeSeriesBucket, error) {
+	if err := ValidateSliceMetric(metric); err != nil {
+		return nil, err
+	}
+	if !TimeSeriesIntervals[interval] {
+		return nil, fmt.Errorf("unsupported interval %q", interval)
+	}
+	metricExpr := sliceMetrics[metric]
+
+	args := []interface{}{interval, since, asOf, agentVersion}
+	query := `
+		WITH buckets AS (
+			SELECT generate_series(date_trunc($1, $2::timestamp), date_trunc($1, $3::timestamp), ('1 ' || $1)::interval) AS bucket
+		),
+		stats AS (
+			SELECT date_trunc($1, e.created_at) AS bucket,
+				COUNT(*) AS count,
+				AVG(` + metricExpr + `) AS avg,
+				MIN(` + metricExpr + `) AS min,
+				MAX(` + metricExpr + `) AS max,
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY ` + metricExpr + `) AS p50,
+				percentile_cont(0.9) WITHIN GROUP (ORDER BY ` + metricExpr + `) AS p90,
+				percentile_cont(0.95) WITHIN GROUP (ORDER BY ` + metricExpr + `) AS p95,
+				percentile_cont(0.99) WITHIN GROUP (ORDER BY ` + metricExpr + `) AS p99
+			FROM evaluations e
+			JOIN conversations c ON c.conversation_id = e.conversation_id
+			WHERE e.created_at >= $2 AND e.created_at <= $3 AND ` + metricExpr + ` IS NOT NULL
+			  AND ($4 = '' OR c.agent_version = $4)` + r.projectFilter("e.project_id", &args) + `
+			GROUP BY 1
+		)
+		SELECT b.bucket, COALESCE(s.count, 0) AS count, s.avg, s.min, s.max, s.p50, s.p90, s.p95, s.p99
+		FROM buckets b
+		LEFT JOIN stats s ON s.bucket = b.bucket
+		ORDER BY b.bucket
+	`
+
+	buckets := []models.TimeSeriesBucket{}
+	if err := r.db.Select(&buckets, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get time series: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// sliceMetrics maps metric names to evaluation columns
+var sliceMetrics = map[string]string{
+	"overall_score":          "e.overall_score",
+	"response_quality_score": "e.response_quality_score",
+	"tool_accuracy_score":    "e.tool_accuracy_score",
+	"coherence_score":        "e.coherence_score",
+	"evaluation_duration_ms": "e.evaluation_duration_ms",
+}
+
+// sliceDimensions maps dimension names to SQL expressions
+var sliceDimensions = map[string]string{
+	"agent_version":     "c.agent_version",
+	"evaluator_version": "e.evaluator_version",
+	"trigger_source":    "e.trigger_source",
+	"language":          "COALESCE(c.metadata->>'language', '')",
+	"intent":            "c.intent",
+	"model":             "COALESCE(c.metadata->>'model', '')",
+	"tag":               "COALESCE(tag.value, '')",
+	"day":               "to_char(date_trunc('day', e.created_at), 'YYYY-MM-DD')",
+}
+
+// maxSliceDimensions caps the number of group-by dimensions per query
+const maxSliceDimensions = 3
+
+// ValidateSliceMetric checks a metric against the whitelist
+func ValidateSliceMetric(metric string) error {
+	if _, ok := sliceMetrics[metric]; !ok {
+		return fmt.Errorf("unsupported metric %q", metric)
+	}
+	return nil
+}
+
+// ValidateSlice checks a metric and dimensions against the whitelist
+func ValidateSlice(metric string, dims []string) error {
+	if err := ValidateSliceMetric(metric); err != nil {
+		return err
+	}
+	if len(dims) == 0 || len(dims) > maxSliceDimensions {
+		return fmt.Errorf("between 1 and %d dimensions are required", maxSliceDimensions)
+	}
+	for _, dim := range dims {
+		if _, ok := sliceDimensions[dim]; !ok {
+			return fmt.Errorf("unsupported dimension %q", dim)
+		}
+	}
+	return nil
+}
+
+// GetSlice aggregates a metric grouped by whitelisted dimensions
+func (r *Repository) GetSlice(metric string, dims []string, from, to *time.Time) ([]models.SliceRow, error) {
+	if err := ValidateSlice(metric, dims); err != nil {
+		return nil, err
+	}
+	metricExpr := sliceMetrics[metric]
+
+	selects := make([]string, 0, len(dims))
+	groups := make([]string, 0, len(dims))
+	joinTags := false
+	for i, dim := range dims {
+		selects = append(selects, fmt.Sprintf("%s::text AS d%d", sliceDimensions[dim], i))
+		groups = append(groups, fmt.Sprintf("d%d", i))
+		if dim == "tag" {
+			joinTags = true
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, COUNT(*) AS count, AVG(%s) AS avg, MIN(%s) AS min, MAX(%s) AS max
+		FROM evaluations e
+		JOIN conversations c ON c.conversation_id = e.conversation_id
+	`, strings.Join(selects, ", "), metricExpr, metricExpr, metricExpr)
+
+	if joinTags {
+		query += ` LEFT JOIN LATERAL jsonb_array_elements_text(
+			CASE WHEN jsonb_typeof(c.metadata->'tags') = 'array' THEN c.metadata->'tags' ELSE '[]'::jsonb END
+		) AS tag(value) ON TRUE`
+	}
+
+	query += fmt.Sprintf(" WHERE %s IS NOT NULL", metricExpr)
+	args := []interface{}{}
+	query += r.projectFilter("e.project_id", &args)
+	argIndex := len(args) + 1
+
+	if from != nil {
+		query += fmt.Sprintf(" AND e.created_at >= $%d", argIndex)
+		args = append(args, *from)
+		argIndex++
+	}
+
+	if to != nil {
+		query += fmt.Sprintf(" AND e.created_at < $%d", argIndex)
+		args = append(args, *to)
+		argIndex++
+	}
+
+	query += fmt.Sprintf(" GROUP BY %s ORDER BY count DESC", strings.Join(groups, ", "))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slice: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]models.SliceRow, 0)
+	for rows.Next() {
+		values := make([]sql.NullString, len(dims))
+		dest := make([]interface{}, 0, len(dims)+4)
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+
+		var row models.SliceRow
+		dest = append(dest, &row.Count, &row.Avg, &row.Min, &row.Max)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan slice: %w", err)
+		}
+
+		row.Dimensions = make(map[string]string, len(dims))
+		for i, dim := range dims {
+			row.Dimensions[dim] = values[i].String
+		}
+		result = append(result, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get slice: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetHourlyQuality returns the average score and issue rate of evaluations
+// per hour between since and until. Hours without evaluations are omitted.
+func (r *Repository) GetHourlyQuality(since, until time.Time) ([]models.QualityBucket, error) {
+	query := `
+		SELECT date_trunc('hour', created_at) AS bucket,
+			COUNT(*) AS evaluations,
+			AVG(overall_score) AS avg_score,
+			AVG(CASE WHEN jsonb_array_length(COALESCE(issues_detected, '[]'::jsonb)) > 0 THEN 1.0 ELSE 0.0 END) AS issue_rate
+		FROM evaluations
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	var buckets []models.QualityBucket
+	if err := r.db.Select(&buckets, query, since, until); err != nil {
+		return nil, fmt.Errorf("failed to get hourly quality: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// RecordAnomalies stores anomaly events and returns the ones that weren't
+// recorded before
+func (r *Repository) RecordAnomalies(events []models.AnomalyEvent) ([]models.AnomalyEvent, error) {
+	recorded := []models.AnomalyEvent{}
+	for _, event := range events {
+		query := `
+			INSERT INTO anomaly_events (metric, bucket, value, expected, z_score, severity, evaluations)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (metric, bucket) DO NOTHING
+			RETURNING *
+		`
+
+		var stored models.AnomalyEvent
+		err := r.db.QueryRowx(query, event.Metric, event.Bucket, event.Value, event.Expected,
+			event.ZScore, event.Severity, event.Evaluations).StructScan(&stored)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return recorded, fmt.Errorf("failed to record anomaly: %w", err)
+		}
+		recorded = append(recorded, stored)
+	}
+
+	return recorded, nil
+}
+
+// ListAnomalyEvents lists anomaly events since a time, newest first. An empty
+// metric lists every metric.
+func (r *Repository) ListAnomalyEvents(metric string, since time.Time, limit int) ([]models.AnomalyEvent, error) {
+	events := []models.AnomalyEvent{}
+	query := `SELECT * FROM anomaly_events WHERE bucket >= $1`
+	args := []interface{}{since}
+
+	if metric != "" {
+		query += ` AND metric = $2`
+		args = append(args, metric)
+	}
+	query += fmt.Sprintf(" ORDER BY bucket DESC LIMIT $%d", len(args)+1)
+	args = append(args, limit)
+
+	if err := r.db.Select(&events, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list anomaly events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/lib/pq"
+)
+
+// GetAnnotatorTypeAccuracy returns, per annotator and annotation type, how many
+// annotations agreed with the majority label on multiply-annotated conversations
+func (r *Repository) GetAnnotatorTypeAccuracy() ([]models.AnnotatorTypeAccuracy, error) {
+	query := `
+		WITH label_counts AS (
+			SELECT conversation_id, annotation_type, label, COUNT(*) AS cnt
+			FROM annotations
+			GROUP BY conversation_id, annotation_type, label
+		),
+		majority AS (
+			SELECT DISTINCT ON (conversation_id, annotation_type)
+				conversation_id, annotation_type, label
+			FROM label_counts
+			ORDER BY conversation_id, annotation_type, cnt DESC, label
+		),
+		multi AS (
+			SELECT conversation_id, annotation_type
+			FROM annotations
+			GROUP BY conversation_id, annotation_type
+			HAVING COUNT(*) > 1
+		)
+		SELECT
+			a.annotator_id,
+			a.annotation_type,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE a.label = m.label) AS agreed
+		FROM annotations a
+		JOIN multi USING (conversation_id, annotation_type)
+		JOIN majority m USING (conversation_id, annotation_type)
+		GROUP BY a.annotator_id, a.annotation_type
+	`
+
+	var accuracies []models.AnnotatorTypeAccuracy
+	if err := r.db.Select(&accuracies, query); err != nil {
+		return nil, fmt.Errorf("failed to get annotator accuracy: %w", err)
+	}
+
+	return accuracies, nil
+}
+
+// RefreshAnnotatorSpecializations infers specializations for every annotator
+// and rebuilds annotator_performance from them in one transaction, so
+// annotators no longer meeting the thresholds, or with no multiply-annotated
+// conversations left, lose their specializations
+func (r *Repository) RefreshAnnotatorSpecializations(minSamples int, minAccuracy float64) ([]models.AnnotatorPerformance, error) {
+	accuracies, err := r.GetAnnotatorTypeAccuracy()
+	if err != nil {
+		return nil, err
+	}
+	profiles := services.InferSpecializations(accuracies, minSamples, minAccuracy)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	annotatorIDs := make([]string, len(profiles))
+	for i, profile := range profiles {
+		annotatorIDs[i] = profile.AnnotatorID
+	}
+	if _, err := tx.Exec(`DELETE FROM annotator_performance WHERE NOT (annotator_id = ANY($1))`, pq.Array(annotatorIDs)); err != nil {
+		return nil, fmt.Errorf("failed to delete stale annotator performance: %w", err)
+	}
+
+	query := `
+		INSERT INTO annotator_performance (annotator_id, total_annotations, agreement_rate, specializations, updated_at)
+		VALUES ($1, (SELECT COUNT(*) FROM annotations WHERE annotator_id = $1), $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (annotator_id) DO UPDATE SET
+			total_annotations = EXCLUDED.total_annotations,
+			agreement_rate = EXCLUDED.agreement_rate,
+			specializations = EXCLUDED.specializations,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	for _, profile := range profiles {
+		specializationsJSON, err := json.Marshal(profile.Specializations)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal specializations: %w", err)
+		}
+		if _, err := tx.Exec(query, profile.AnnotatorID, profile.AgreementRate, specializationsJSON); err != nil {
+			return nil, fmt.Errorf("failed to update annotator performance: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit annotator performance: %w", err)
+	}
+
+	return r.ListAnnotatorPerformance()
+}
+
+// ListAnnotatorPerformance lists stored annotator performance records
+func (r *Repository) ListAnnotatorPerformance() ([]models.AnnotatorPerformance, error) {
+	var performance []models.AnnotatorPerformance
+	query := `SELECT * FROM annotator_performance ORDER BY agreement_rate DESC NULLS LAST`
+
+	if err := r.db.Select(&performance, query); err != nil {
+		return nil, fmt.Errorf("failed to list annotator performance: %w", err)
+	}
+
+	return performance, nil
+}
+
+// GetSpecializedAnnotators returns annotators specialized in any of the given
+// annotation types, best agreement first
+func (r *Repository) GetSpecializedAnnotators(annotationTypes []string, limit int) ([]string, error) {
+	var annotators []string
+	query := `
+		SELECT annotator_id FROM annotator_performance
+		WHERE specializations ?| $1
+		ORDER BY agreement_rate DESC NULLS LAST
+		LIMIT $2
+	`
+
+	if err := r.db.Select(&annotators, query, pq.Array(annotationTypes), limit); err != nil {
+		return nil, fmt.Errorf("failed to get specialized annotators: %w", err)
+	}
+
+	return annotators, nil
+}
+
+// ListAdjudicatedConversations returns recently annotated conversations with
+// their majority label for an annotation type, excluding one conversation.
+// Only conversations with at least minAnnotators distinct annotators count as
+// adjudicated.
+func (r *Repository) ListAdjudicatedConversations(annotationType, excludeConversationID string, minAnnotators, limit int) ([]models.AdjudicatedConversation, error) {
+	var conversations []models.AdjudicatedConversation
+
+	args := []interface{}{annotationType, excludeConversationID, limit, minAnnotators}
+	query := `
+		WITH covered AS (
+			SELECT conversation_id FROM annotations
+			WHERE annotation_type = $1 AND conversation_id <> $2` + r.projectFilter("project_id", &args) + `
+			GROUP BY conversation_id
+			HAVING COUNT(DISTINCT annotator_id) >= $4
+		),
+		label_counts AS (
+			SELECT conversation_id, label, COUNT(*) AS cnt, MAX(created_at) AS last_annotated
+			FROM annotations
+			WHERE annotation_type = $1 AND conversation_id IN (SELECT conversation_id FROM covered)
+			GROUP BY conversation_id, label
+		),
+		final_labels AS (
+			SELECT DISTINCT ON (conversation_id) conversation_id, label, last_annotated
+			FROM label_counts
+			ORDER BY conversation_id, cnt DESC, label
+		)
+		SELECT f.conversation_id, f.label AS final_label, c.turns
+		FROM final_labels f
+		JOIN conversations c ON c.conversation_id = f.conversation_id
+		ORDER BY f.last_annotated DESC
+		LIMIT $3
+	`
+
+	if err := r.db.Select(&conversations, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list adjudicated conversations: %w", err)
+	}
+
+	return conversations, nil
+}
+
+// ListAssistedAnnotations lists the annotations made under assisted labeling
+// since a time, with the machine suggestion each could be compared against
+func (r *Repository) ListAssistedAnnotations(since time.Time) ([]models.Annotation, error) {
+	annotations := []models.Annotation{}
+	args := []interface{}{since}
+	query := `
+		SELECT * FROM annotations
+		WHERE created_at >= $1 AND assisted IS NOT NULL AND suggested_labels IS NOT NULL
+	` + r.projectFilter("project_id", &args) + ` ORDER BY created_at`
+
+	if err := r.db.Select(&annotations, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list assisted annotations: %w", err)
+	}
+
+	return annotations, nil
+}
+
+// GetAnnotatorSalt returns the salt of a project's annotator pseudonyms,
+// storing salt as the project's first if it has none yet
+func (r *Repository) GetAnnotatorSalt(projectID, salt string) (string, error) {
+	var stored string
+	err := r.db.Get(&stored, `
+		INSERT INTO annotator_salts (project_id, salt) VALUES ($1, $2)
+		ON CONFLICT (project_id) DO UPDATE SET project_id = EXCLUDED.project_id
+		RETURNING salt
+	`, projectID, salt)
+	if err != nil {
+		return "", fmt.Errorf("failed to get annotator salt: %w", err)
+	}
+
+	return stored, nil
+}
+
+// ListAnnotatorIDs lists every annotator who annotated or was assigned a
+// conversation, or has performance recorded
+func (r *Repository) ListAnnotatorIDs() ([]string, error) {
+	args := []interface{}{}
+	query := `
+		SELECT annotator_id FROM annotations WHERE TRUE` + r.projectFilter("project_id", &args) + `
+		UNION
+		SELECT annotator_id FROM annotation_tasks WHERE TRUE` + r.conversationFilter("conversation_id", &args) + `
+		UNION
+		SELECT annotator_id FROM annotator_performance
+	`
+
+	annotatorIDs := []string{}
+	if err := r.db.Select(&annotatorIDs, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list annotator IDs: %w", err)
+	}
+
+	return annotatorIDs, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// CreateAPIKey stores a new API key by its hash. An empty projectID lets the
+// key use every project.
+func (r *Repository) CreateAPIKey(id, name, prefix, keyHash string, scopes []string, projectID string) (*models.APIKey, error) {
+	scopesJSON, err := json.Marshal(scopes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal scopes: %w", err)
+	}
+
+	var key models.APIKey
+	err = r.db.QueryRowx(`
+		INSERT INTO api_keys (id, name, key_prefix, key_hash, scopes, project_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *
+	`, id, name, prefix, keyHash, scopesJSON, projectID).StructScan(&key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// ListAPIKeys lists API keys, including revoked ones, newest first
+func (r *Repository) ListAPIKeys() ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	if err := r.db.Select(&keys, `SELECT * FROM api_keys ORDER BY created_at DESC, id`); err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key. It returns false if no active key has the ID.
+func (r *Repository) RevokeAPIKey(id string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetAPIKeyByHash gets the active API key with a hash and records its use.
+// It returns nil if there is none.
+func (r *Repository) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.QueryRowx(`
+		UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP
+		WHERE key_hash = $1 AND revoked_at IS NULL
+		RETURNING *
+	`, keyHash).StructScan(&key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"fmt"
+)
+
+// AttachmentReferenced reports whether a conversation of the repository's
+// project has an attachment with the given content digest
+func (r *Repository) AttachmentReferenced(sha256 string) (bool, error) {
+	args := []interface{}{sha256}
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM conversations
+			WHERE turns @> jsonb_build_array(jsonb_build_object('attachments', jsonb_build_array(jsonb_build_object('sha256', $1::text))))` +
+		r.projectFilter("project_id", &args) + `
+		)
+	`
+
+	var referenced bool
+	if err := r.db.Get(&referenced, query, args...); err != nil {
+		return false, fmt.Errorf("failed to check attachment references: %w", err)
+	}
+	return referenced, nil
+}
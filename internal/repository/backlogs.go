@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+)
+
+// backlogRow is a backlog's size, age and throughput over the rate window
+type backlogRow struct {
+	Length           int64   `db:"length"`
+	OldestAgeSeconds float64 `db:"oldest_age_seconds"`
+	Started          int64   `db:"started"`
+	Finished         int64   `db:"finished"`
+}
+
+// GetBacklogStats measures the backlogs kept in the database as queue
+// stats: running conversation imports and open annotation tasks. Rates are
+// averaged over window, as for the Redis queues.
+func (r *Repository) GetBacklogStats(window time.Duration) ([]queue.QueueStats, error) {
+	since := time.Now().Add(-window)
+	backlogs := []struct {
+		name  string
+		query string
+		args  []interface{}
+	}{
+		{
+			name: queue.BacklogIngest,
+			query: `
+				SELECT COUNT(*) FILTER (WHERE status = $1) AS length,
+					   COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(created_at) FILTER (WHERE status = $1)), 0) AS oldest_age_seconds,
+					   COUNT(*) FILTER (WHERE created_at >= $2) AS started,
+					   COUNT(*) FILTER (WHERE finished_at >= $2) AS finished
+				FROM conversation_imports
+				WHERE status = $1 OR created_at >= $2 OR finished_at >= $2
+			`,
+			args: []interface{}{models.ImportRunning, since},
+		},
+		{
+			name: queue.BacklogAnnotations,
+			query: `
+				SELECT COUNT(*) FILTER (WHERE status <> 'completed') AS length,
+					   COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(created_at) FILTER (WHERE status <> 'completed')), 0) AS oldest_age_seconds,
+					   COUNT(*) FILTER (WHERE created_at >= $1) AS started,
+					   COUNT(*) FILTER (WHERE status = 'completed' AND updated_at >= $1) AS finished
+				FROM annotation_tasks
+				WHERE status <> 'completed' OR created_at >= $1 OR updated_at >= $1
+			`,
+			args: []interface{}{since},
+		},
+	}
+
+	minutes := window.Minutes()
+	stats := make([]queue.QueueStats, 0, len(backlogs))
+	for _, backlog := range backlogs {
+		var row backlogRow
+		if err := r.db.Get(&row, backlog.query, backlog.args...); err != nil {
+			return nil, fmt.Errorf("failed to measure %s backlog: %w", backlog.name, err)
+		}
+		stats = append(stats, queue.QueueStats{
+			Queue:                backlog.name,
+			Length:               row.Length,
+			EnqueuedPerMinute:    float64(row.Started) / minutes,
+			DequeuedPerMinute:    float64(row.Finished) / minutes,
+			OldestTaskAgeSeconds: row.OldestAgeSeconds,
+		})
+	}
+	return stats, nil
+}
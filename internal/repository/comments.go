@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// CreateComment stores a comment with the names it mentions
+func (r *Repository) CreateComment(comment *models.Comment, mentions []string) (*models.Comment, error) {
+	mentionsJSON, err := json.Marshal(mentions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal mentions: %w", err)
+	}
+
+	query := `
+		INSERT INTO comments (conversation_id, turn_id, evaluation_id, parent_id, author, body, mentions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING *
+	`
+
+	var result models.Comment
+	err = r.db.QueryRowx(query, comment.ConversationID, comment.TurnID, comment.EvaluationID, comment.ParentID,
+		comment.Author, comment.Body, mentionsJSON).StructScan(&result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetComment retrieves a comment
+func (r *Repository) GetComment(id int64) (*models.Comment, error) {
+	var comment models.Comment
+	args := []interface{}{id}
+	query := `SELECT * FROM comments WHERE id = $1` + r.conversationFilter("conversation_id", &args)
+
+	if err := r.db.Get(&comment, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	return &comment, nil
+}
+
+// ListComments lists a conversation's comments, oldest first
+func (r *Repository) ListComments(conversationID string) ([]models.Comment, error) {
+	comments := []models.Comment{}
+	args := []interface{}{conversationID}
+	query := `SELECT * FROM comments WHERE conversation_id = $1` + r.conversationFilter("conversation_id", &args) + `
+		ORDER BY created_at, id`
+
+	if err := r.db.Select(&comments, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+// UpdateComment changes a comment's body and the names it mentions. It
+// returns nil if the comment doesn't exist.
+func (r *Repository) UpdateComment(id int64, body string, mentions []string) (*models.Comment, error) {
+	mentionsJSON, err := json.Marshal(mentions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal mentions: %w", err)
+	}
+
+	args := []interface{}{body, mentionsJSON, id}
+	query := `
+		UPDATE comments SET body = $1, mentions = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3` + r.conversationFilter("conversation_id", &args) + `
+		RETURNING *
+	`
+
+	var result models.Comment
+	err = r.db.QueryRowx(query, args...).StructScan(&result)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ResolveComment resolves or reopens a comment thread. It returns nil if
+// the thread's comment doesn't exist or is a reply.
+func (r *Repository) ResolveComment(id int64, resolved bool, resolvedBy string) (*models.Comment, error) {
+	args := []interface{}{resolved, resolvedBy, id}
+	query := `
+		UPDATE comments
+		SET resolved = $1,
+			resolved_by = CASE WHEN $1 THEN $2 ELSE '' END,
+			resolved_at = CASE WHEN $1 THEN CURRENT_TIMESTAMP END
+		WHERE id = $3 AND parent_id IS NULL` + r.conversationFilter("conversation_id", &args) + `
+		RETURNING *
+	`
+
+	var result models.Comment
+	err := r.db.QueryRowx(query, args...).StructScan(&result)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve comment: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DeleteComment deletes a comment and, for a thread's comment, its
+// replies. It reports whether a row was deleted.
+func (r *Repository) DeleteComment(id int64) (bool, error) {
+	args := []interface{}{id}
+	query := `DELETE FROM comments WHERE id = $1` + r.conversationFilter("conversation_id", &args)
+
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// ListCommentMentions lists the comments mentioning a name, newest first.
+// A resolved filter applies to the state of each comment's thread.
+func (r *Repository) ListCommentMentions(name string, resolved *bool, limit, offset int) ([]models.Comment, error) {
+	comments := []models.Comment{}
+	args := []interface{}{name, resolved, limit, offset}
+	query := `
+		SELECT c.* FROM comments c
+		LEFT JOIN comments t ON t.id = c.parent_id
+		WHERE c.mentions @> jsonb_build_array($1::text)
+			AND ($2::boolean IS NULL OR COALESCE(t.resolved, c.resolved) = $2)` +
+		r.conversationFilter("c.conversation_id", &args) + `
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	if err := r.db.Select(&comments, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list comment mentions: %w", err)
+	}
+
+	return comments, nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// SaveConfigBundle stores an imported configuration bundle
+func (r *Repository) SaveConfigBundle(bundle *models.ConfigBundle) error {
+	bundleJSON, err := json.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config bundle: %w", err)
+	}
+
+	if _, err := r.db.Exec(`INSERT INTO config_bundles (bundle) VALUES ($1)`, bundleJSON); err != nil {
+		return fmt.Errorf("failed to save config bundle: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatestConfigBundle returns the most recently imported configuration bundle
+func (r *Repository) GetLatestConfigBundle() (*models.ConfigBundle, error) {
+	var bundleJSON []byte
+	query := `SELECT bundle FROM config_bundles ORDER BY created_at DESC, id DESC LIMIT 1`
+
+	if err := r.db.Get(&bundleJSON, query); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get config bundle: %w", err)
+	}
+
+	var bundle models.ConfigBundle
+	if err := json.Unmarshal(bundleJSON, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config bundle: %w", err)
+	}
+
+	return &bundle, nil
+}
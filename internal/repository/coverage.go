@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/lib/pq"
+)
+
+// AutoAssignedBy marks annotation tasks created to reach the minimum number
+// of annotators of an annotation type
+const AutoAssignedBy = "auto:min_annotators"
+
+// ListAnnotationTaskAssignees returns the annotators with a task for a
+// conversation and annotation type, in assignment order
+func (r *Repository) ListAnnotationTaskAssignees(conversationID, annotationType string) ([]string, error) {
+	assignees := []string{}
+	args := []interface{}{conversationID, annotationType}
+	query := `
+		SELECT annotator_id FROM annotation_tasks
+		WHERE conversation_id = $1 AND annotation_type = $2` + r.conversationFilter("conversation_id", &args) + `
+		ORDER BY created_at, id
+	`
+	if err := r.db.Select(&assignees, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list annotation task assignees: %w", err)
+	}
+
+	return assignees, nil
+}
+
+// CountAnnotators returns how many distinct annotators have annotated a
+// conversation for an annotation type
+func (r *Repository) CountAnnotators(conversationID, annotationType string) (int, error) {
+	var count int
+	args := []interface{}{conversationID, annotationType}
+	query := `
+		SELECT COUNT(DISTINCT annotator_id) FROM annotations
+		WHERE conversation_id = $1 AND annotation_type = $2
+	` + r.projectFilter("project_id", &args)
+	if err := r.db.Get(&count, query, args...); err != nil {
+		return 0, fmt.Errorf("failed to count annotators: %w", err)
+	}
+
+	return count, nil
+}
+
+// GenerateAnnotationTasks assigns additional annotation tasks to conversations
+// that have entered annotation for a type, by being annotated or assigned, but
+// have fewer annotators than the type requires. At most limit conversations
+// are topped up per annotation type. Tasks inherit the conversation's routing
+// priority and deadline. It returns the number of tasks created.
+func (r *Repository) GenerateAnnotationTasks(policy models.RoutingPolicy, limit int) (int, error) {
+	created := 0
+	for annotationType, required := range policy.MinAnnotators {
+		if required <= 1 {
+			continue
+		}
+
+		var shortfalls []struct {
+			ConversationID string         `db:"conversation_id"`
+			Annotators     pq.StringArray `db:"annotators"`
+		}
+		query := `
+			SELECT conversation_id, array_agg(DISTINCT annotator_id) AS annotators
+			FROM (
+				SELECT conversation_id, annotator_id FROM annotations WHERE annotation_type = $1
+				UNION
+				SELECT conversation_id, annotator_id FROM annotation_tasks WHERE annotation_type = $1
+			) covered
+			GROUP BY conversation_id
+			HAVING COUNT(DISTINCT annotator_id) < $2
+			ORDER BY conversation_id
+			LIMIT $3
+		`
+		if err := r.db.Select(&shortfalls, query, annotationType, required, limit); err != nil {
+			return created, fmt.Errorf("failed to find under-annotated conversations: %w", err)
+		}
+		if len(shortfalls) == 0 {
+			continue
+		}
+
+		var candidates []models.AnnotatorCandidate
+		query = `
+			SELECT p.annotator_id,
+				   COALESCE(p.specializations ? $1, FALSE) AS specialized,
+				   COUNT(t.id) AS open_tasks,
+				   COALESCE(p.agreement_rate, 0) AS agreement_rate
+			FROM annotator_performance p
+			LEFT JOIN annotation_tasks t ON t.annotator_id = p.annotator_id AND t.status <> 'completed'
+			GROUP BY p.annotator_id, p.specializations, p.agreement_rate
+			ORDER BY p.annotator_id
+		`
+		if err := r.db.Select(&candidates, query, annotationType); err != nil {
+			return created, fmt.Errorf("failed to list annotator candidates: %w", err)
+		}
+
+		for _, shortfall := range shortfalls {
+			priority, dueAt, err := taskRouting(r.db.Get, shortfall.ConversationID, policy, time.Now())
+			if err != nil {
+				return created, err
+			}
+
+			picked := services.PickAnnotators(candidates, shortfall.Annotators, required-len(shortfall.Annotators))
+			for _, annotatorID := range picked {
+				res, err := r.db.Exec(`
+					INSERT INTO annotation_tasks (conversation_id, annotation_type, annotator_id, assigned_by, priority, due_at)
+					VALUES ($1, $2, $3, $4, $5, $6)
+					ON CONFLICT (conversation_id, annotation_type, annotator_id) DO NOTHING
+				`, shortfall.ConversationID, annotationType, annotatorID, AutoAssignedBy, priority, dueAt)
+				if err != nil {
+					return created, fmt.Errorf("failed to create annotation task: %w", err)
+				}
+				if inserted, _ := res.RowsAffected(); inserted > 0 {
+					created++
+				}
+			}
+		}
+	}
+
+	return created, nil
+}
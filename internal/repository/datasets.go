@@ -0,0 +1,402 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// CreateDataset creates a dataset with its items for a project. It returns
+// nil if the project has a dataset with the same name.
+func (r *Repository) CreateDataset(projectID string, dataset *models.DatasetCreate) (*models.Dataset, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO datasets (project_id, name, description, created_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (project_id, name) DO NOTHING
+		RETURNING *
+	`
+
+	var result models.Dataset
+	err = tx.QueryRowx(query, projectID, dataset.Name, dataset.Description, dataset.CreatedBy).StructScan(&result)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dataset: %w", err)
+	}
+
+	if err := insertDatasetItems(tx, result.ID, dataset.Items); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	result.Items = normalizedDatasetItems(dataset.Items)
+	return &result, nil
+}
+
+// GetDataset retrieves a project's dataset with its items
+func (r *Repository) GetDataset(projectID string, id int64) (*models.Dataset, error) {
+	var dataset models.Dataset
+	if err := r.db.Get(&dataset, `SELECT * FROM datasets WHERE project_id = $1 AND id = $2`, projectID, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get dataset: %w", err)
+	}
+
+	dataset.Items = []models.DatasetItem{}
+	query := `SELECT item_key, description, metadata FROM dataset_items WHERE dataset_id = $1 ORDER BY item_key`
+	if err := r.db.Select(&dataset.Items, query, id); err != nil {
+		return nil, fmt.Errorf("failed to list dataset items: %w", err)
+	}
+
+	return &dataset, nil
+}
+
+// ListDatasets lists a project's datasets, without their items, by name
+func (r *Repository) ListDatasets(projectID string) ([]models.Dataset, error) {
+	datasets := []models.Dataset{}
+
+	if err := r.db.Select(&datasets, `SELECT * FROM datasets WHERE project_id = $1 ORDER BY name`, projectID); err != nil {
+		return nil, fmt.Errorf("failed to list datasets: %w", err)
+	}
+
+	return datasets, nil
+}
+
+// ReplaceDatasetItems replaces the items of a project's dataset. Past runs
+// keep the items they evaluated. It returns nil if the dataset doesn't exist.
+func (r *Repository) ReplaceDatasetItems(projectID string, id int64, items []models.DatasetItem) (*models.Dataset, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var dataset models.Dataset
+	err = tx.QueryRowx(`
+		UPDATE datasets SET updated_at = CURRENT_TIMESTAMP
+		WHERE project_id = $1 AND id = $2
+		RETURNING *
+	`, projectID, id).StructScan(&dataset)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update dataset: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM dataset_items WHERE dataset_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to delete dataset items: %w", err)
+	}
+	if err := insertDatasetItems(tx, id, items); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	dataset.Items = normalizedDatasetItems(items)
+	return &dataset, nil
+}
+
+// DeleteDataset deletes a project's dataset with its items and runs. It
+// reports whether a row was deleted.
+func (r *Repository) DeleteDataset(projectID string, id int64) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM datasets WHERE project_id = $1 AND id = $2`, projectID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete dataset: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete dataset: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// insertDatasetItems adds items to a dataset within a transaction
+func insertDatasetItems(tx *sqlx.Tx, datasetID int64, items []models.DatasetItem) error {
+	for _, item := range normalizedDatasetItems(items) {
+		_, err := tx.Exec(`
+			INSERT INTO dataset_items (dataset_id, item_key, description, metadata)
+			VALUES ($1, $2, $3, $4)
+		`, datasetID, item.ItemKey, item.Description, []byte(item.Metadata))
+		if err != nil {
+			return fmt.Errorf("failed to create dataset item %s: %w", item.ItemKey, err)
+		}
+	}
+	return nil
+}
+
+// normalizedDatasetItems gives items without metadata an empty object
+func normalizedDatasetItems(items []models.DatasetItem) []models.DatasetItem {
+	normalized := make([]models.DatasetItem, len(items))
+	for i, item := range items {
+		if len(item.Metadata) == 0 {
+			item.Metadata = []byte(`{}`)
+		}
+		normalized[i] = item
+	}
+	return normalized
+}
+
+// GetDatasetItemConversations returns the latest conversation of an agent
+// version produced from each dataset item, by item key. Items without one
+// are left out.
+func (r *Repository) GetDatasetItemConversations(projectID, agentVersion string, itemKeys []string) (map[string]string, error) {
+	var rows []struct {
+		ItemKey        string `db:"item_key"`
+		ConversationID string `db:"conversation_id"`
+	}
+	query := `
+		SELECT DISTINCT ON (metadata->>'dataset_item') metadata->>'dataset_item' AS item_key, conversation_id
+		FROM conversations
+		WHERE project_id = $1 AND agent_version = $2 AND metadata ? 'dataset_item'
+			AND metadata->>'dataset_item' = ANY($3)
+		ORDER BY metadata->>'dataset_item', created_at DESC, id DESC
+	`
+	if err := r.db.Select(&rows, query, projectID, agentVersion, pq.Array(itemKeys)); err != nil {
+		return nil, fmt.Errorf("failed to get dataset item conversations: %w", err)
+	}
+
+	conversations := make(map[string]string, len(rows))
+	for _, row := range rows {
+		conversations[row.ItemKey] = row.ConversationID
+	}
+	return conversations, nil
+}
+
+// CreateDatasetRun records a run with the outcome of each of its items so
+// far: pending for items whose evaluation is queued next, missing for items
+// without a conversation
+func (r *Repository) CreateDatasetRun(run *models.DatasetRun, items []models.DatasetRunItem) (*models.DatasetRun, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var result models.DatasetRun
+	err = tx.QueryRowx(`
+		INSERT INTO dataset_runs (dataset_id, agent_version, status, item_count, missing_count, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *
+	`, run.DatasetID, run.AgentVersion, models.DatasetRunRunning, len(items), run.MissingCount, run.CreatedBy).StructScan(&result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dataset run: %w", err)
+	}
+
+	for _, item := range items {
+		_, err := tx.Exec(`
+			INSERT INTO dataset_run_items (run_id, item_key, conversation_id, status)
+			VALUES ($1, $2, $3, $4)
+		`, result.ID, item.ItemKey, item.ConversationID, item.Status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dataset run item %s: %w", item.ItemKey, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &result, nil
+}
+
+// SetDatasetRunItemTask records the task evaluating a run's item, or marks
+// the item failed if taskID is empty because its evaluation couldn't be queued
+func (r *Repository) SetDatasetRunItemTask(runID int64, itemKey, taskID string) error {
+	status := models.DatasetRunItemPending
+	if taskID == "" {
+		status = models.DatasetRunItemFailed
+	}
+
+	_, err := r.db.Exec(`
+		UPDATE dataset_run_items SET task_id = $3, status = $4
+		WHERE run_id = $1 AND item_key = $2
+	`, runID, itemKey, taskID, status)
+	if err != nil {
+		return fmt.Errorf("failed to update dataset run item: %w", err)
+	}
+	return nil
+}
+
+// ListPendingDatasetRunTasks lists the tasks of a run's items still waiting
+// for an evaluation
+func (r *Repository) ListPendingDatasetRunTasks(runID int64) ([]string, error) {
+	taskIDs := []string{}
+	query := `SELECT task_id FROM dataset_run_items WHERE run_id = $1 AND status = $2 AND task_id <> ''`
+
+	if err := r.db.Select(&taskIDs, query, runID, models.DatasetRunItemPending); err != nil {
+		return nil, fmt.Errorf("failed to list pending dataset run tasks: %w", err)
+	}
+
+	return taskIDs, nil
+}
+
+// FailDatasetRunTasks marks the items of a run evaluated by failed tasks as
+// failed
+func (r *Repository) FailDatasetRunTasks(runID int64, taskIDs []string) error {
+	_, err := r.db.Exec(`
+		UPDATE dataset_run_items SET status = $3
+		WHERE run_id = $1 AND task_id = ANY($2) AND status = $4
+	`, runID, pq.Array(taskIDs), models.DatasetRunItemFailed, models.DatasetRunItemPending)
+	if err != nil {
+		return fmt.Errorf("failed to update dataset run items: %w", err)
+	}
+	return nil
+}
+
+// RefreshDatasetRun records the evaluations stored for a run's pending items
+// and aggregates the run's scores over its evaluated items. The run
+// completes once no item is pending. It returns nil if the run doesn't exist.
+func (r *Repository) RefreshDatasetRun(runID int64) (*models.DatasetRun, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE dataset_run_items i SET
+			status = $2,
+			evaluation_id = e.evaluation_id,
+			overall_score = e.overall_score,
+			scores = e.component_scores || jsonb_build_object('overall', e.overall_score),
+			verdict = e.verdict
+		FROM evaluations e
+		WHERE i.run_id = $1 AND i.status = $3 AND i.task_id <> '' AND e.task_id = i.task_id
+	`, runID, models.DatasetRunItemEvaluated, models.DatasetRunItemPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record dataset run evaluations: %w", err)
+	}
+
+	var run models.DatasetRun
+	err = tx.QueryRowx(`
+		UPDATE dataset_runs r SET
+			evaluated_count = counts.evaluated,
+			missing_count = counts.missing,
+			failed_count = counts.failed,
+			mean_scores = COALESCE((
+				SELECT jsonb_object_agg(key, mean) FROM (
+					SELECT s.key, AVG(s.value::float) AS mean
+					FROM dataset_run_items i, jsonb_each_text(i.scores) s
+					WHERE i.run_id = r.id AND i.status = $2
+					GROUP BY s.key
+				) means
+			), '{}'),
+			verdict_counts = COALESCE((
+				SELECT jsonb_object_agg(verdict, n) FROM (
+					SELECT verdict, COUNT(*) AS n FROM dataset_run_items
+					WHERE run_id = r.id AND status = $2 AND verdict <> ''
+					GROUP BY verdict
+				) verdicts
+			), '{}'),
+			pass_rate = (
+				SELECT AVG(CASE WHEN verdict = $6 THEN 1.0 ELSE 0.0 END) FROM dataset_run_items
+				WHERE run_id = r.id AND status = $2
+			),
+			status = CASE WHEN counts.pending = 0 THEN $7 ELSE $8 END,
+			completed_at = CASE WHEN counts.pending = 0 THEN COALESCE(r.completed_at, CURRENT_TIMESTAMP) END
+		FROM (
+			SELECT
+				COUNT(*) FILTER (WHERE status = $2) AS evaluated,
+				COUNT(*) FILTER (WHERE status = $3) AS pending,
+				COUNT(*) FILTER (WHERE status = $4) AS missing,
+				COUNT(*) FILTER (WHERE status = $5) AS failed
+			FROM dataset_run_items WHERE run_id = $1
+		) counts
+		WHERE r.id = $1
+		RETURNING r.*
+	`, runID, models.DatasetRunItemEvaluated, models.DatasetRunItemPending, models.DatasetRunItemMissing,
+		models.DatasetRunItemFailed, models.VerdictPass, models.DatasetRunCompleted, models.DatasetRunRunning,
+	).StructScan(&run)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate dataset run: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &run, nil
+}
+
+// GetDatasetRun retrieves a run of a dataset
+func (r *Repository) GetDatasetRun(datasetID, runID int64) (*models.DatasetRun, error) {
+	var run models.DatasetRun
+	if err := r.db.Get(&run, `SELECT * FROM dataset_runs WHERE dataset_id = $1 AND id = $2`, datasetID, runID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get dataset run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// GetLatestDatasetRun retrieves the latest run of a dataset created before
+// another run, or the latest run if before is 0. Only completed runs that
+// aren't stale are considered if completed is set.
+func (r *Repository) GetLatestDatasetRun(datasetID, before int64, completed bool) (*models.DatasetRun, error) {
+	var run models.DatasetRun
+	query := `
+		SELECT * FROM dataset_runs
+		WHERE dataset_id = $1 AND ($2 = 0 OR id < $2) AND (NOT $3 OR (status = $4 AND stale_at IS NULL))
+		ORDER BY id DESC
+		LIMIT 1
+	`
+	if err := r.db.Get(&run, query, datasetID, before, completed, models.DatasetRunCompleted); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest dataset run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// ListDatasetRuns lists the runs of a dataset, newest first, optionally of
+// one agent version
+func (r *Repository) ListDatasetRuns(datasetID int64, agentVersion string, limit, offset int) ([]models.DatasetRun, error) {
+	runs := []models.DatasetRun{}
+	query := `
+		SELECT * FROM dataset_runs
+		WHERE dataset_id = $1 AND ($2 = '' OR agent_version = $2)
+		ORDER BY id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	if err := r.db.Select(&runs, query, datasetID, agentVersion, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to list dataset runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// ListDatasetRunItems lists the outcomes of a run's items by item key
+func (r *Repository) ListDatasetRunItems(runID int64) ([]models.DatasetRunItem, error) {
+	items := []models.DatasetRunItem{}
+
+	if err := r.db.Select(&items, `SELECT * FROM dataset_run_items WHERE run_id = $1 ORDER BY item_key`, runID); err != nil {
+		return nil, fmt.Errorf("failed to list dataset run items: %w", err)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/lib/pq"
+)
+
+// ErrTooManyConversations is returned when a hard delete or an agent version
+// reassignment selects more conversations than allowed
+var ErrTooManyConversations = errors.New("too many conversations match; narrow the selection")
+
+// conversationDependents are the rows deleted with a conversation, by table.
+// Each condition takes the conversation IDs as $1.
+var conversationDependents = []struct {
+	table     string
+	condition string
+	cascades  bool // Deleted by a foreign key rather than explicitly
+}{
+	{"feedbacks", "conversation_id = ANY($1)", true},
+	{"evaluations", "conversation_id = ANY($1)", true},
+	{"routing_approvals", "conversation_id = ANY($1)", true},
+	{"issue_dismissals", "evaluation_id IN (SELECT evaluation_id FROM evaluations WHERE conversation_id = ANY($1))", true},
+	{"issue_assignments", "evaluation_id IN (SELECT evaluation_id FROM evaluations WHERE conversation_id = ANY($1))", true},
+	{"annotations", "conversation_id = ANY($1)", true},
+	{"external_annotations", "annotation_id IN (SELECT id FROM annotations WHERE conversation_id = ANY($1))", true},
+	{"annotation_tasks", "conversation_id = ANY($1)", true},
+	{"conversation_summaries", "conversation_id = ANY($1)", true},
+	{"conversation_health", "conversation_id = ANY($1)", true},
+	{"comments", "conversation_id = ANY($1)", true},
+	{"reevaluation_samples", "conversation_id = ANY($1)", true},
+	{"dataset_run_items", "conversation_id = ANY($1)", true}, // Kept, with the conversation set to NULL
+	{"pipeline_timings", "conversation_id = ANY($1)", false},
+	{"evaluator_recordings", "conversation_id = ANY($1)", false},
+	{"webhook_deliveries", "evaluation_id IN (SELECT evaluation_id FROM evaluations WHERE conversation_id = ANY($1))", false},
+}
+
+// HardDeleteConversations permanently deletes the selected conversations and
+// the rows that depend on them, in one transaction, returning the number of
+// rows per table and the attachment blobs left unreferenced, which the caller
+// deletes once the rows are gone. On a dry run nothing is deleted. It returns
+// ErrTooManyConversations without deleting anything when more than
+// maxConversations match. Conversation IDs kept in
+// aggregates, such as failure pattern examples, are left as they are.
+func (r *Repository) HardDeleteConversations(req *models.ConversationDeletion, dryRun bool, maxConversations int) (*models.ConversationDeletionResult, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the selection so rows aren't added for it while counting
+	ids := []string{}
+	query := `
+		SELECT conversation_id FROM conversations
+		WHERE (cardinality($1::text[]) = 0 OR conversation_id = ANY($1))
+			AND ($2 = '' OR agent_version = $2)
+			AND ($3 = '' OR left(conversation_id, length($3)) = $3)
+			AND ($4::timestamp IS NULL OR created_at < $4)
+		ORDER BY conversation_id
+		LIMIT $5
+		FOR UPDATE
+	`
+	err = tx.Select(&ids, query, pq.Array(req.ConversationIDs), req.AgentVersion, req.IDPrefix, req.CreatedBefore, maxConversations+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select conversations: %w", err)
+	}
+	if len(ids) > maxConversations {
+		return nil, ErrTooManyConversations
+	}
+
+	result := &models.ConversationDeletionResult{
+		DryRun:          dryRun,
+		ConversationIDs: ids,
+		Rows:            map[string]int64{"conversations": int64(len(ids))},
+	}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	for _, dep := range conversationDependents {
+		var count int64
+		if err := tx.Get(&count, `SELECT COUNT(*) FROM `+dep.table+` WHERE `+dep.condition, pq.Array(ids)); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", dep.table, err)
+		}
+		result.Rows[dep.table] = count
+	}
+
+	// Blobs are shared by content, so only those no other conversation
+	// references go with the selection
+	query = `
+		SELECT DISTINCT a->>'sha256'
+		FROM conversations c
+		CROSS JOIN LATERAL jsonb_array_elements(c.turns) t
+		CROSS JOIN LATERAL jsonb_array_elements(CASE WHEN jsonb_typeof(t->'attachments') = 'array' THEN t->'attachments' ELSE '[]'::jsonb END) a
+		WHERE c.conversation_id = ANY($1) AND COALESCE(a->>'sha256', '') <> ''
+			AND NOT EXISTS (
+				SELECT 1 FROM conversations o
+				WHERE NOT o.conversation_id = ANY($1)
+					AND o.turns @> jsonb_build_array(jsonb_build_object('attachments', jsonb_build_array(jsonb_build_object('sha256', a->>'sha256'))))
+			)
+		ORDER BY 1
+	`
+	result.Attachments = []string{}
+	if err := tx.Select(&result.Attachments, query, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to select attachments: %w", err)
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	for _, dep := range conversationDependents {
+		if dep.cascades {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM `+dep.table+` WHERE `+dep.condition, pq.Array(ids)); err != nil {
+			return nil, fmt.Errorf("failed to delete %s: %w", dep.table, err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM conversations WHERE conversation_id = ANY($1)`, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to delete conversations: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit deletion: %w", err)
+	}
+
+	return result, nil
+}
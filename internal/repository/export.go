@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/lib/pq"
+)
+
+// ListAnnotationExportItems lists conversations with their latest evaluation
+// and human annotations, newest first
+func (r *Repository) ListAnnotationExportItems(agentVersion string, limit, offset int) ([]models.AnnotationExportItem, error) {
+	convs, err := r.ListConversations(agentVersion, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(convs) == 0 {
+		return []models.AnnotationExportItem{}, nil
+	}
+
+	ids := make([]string, len(convs))
+	for i, conv := range convs {
+		ids[i] = conv.ConversationID
+	}
+
+	var evaluations []models.Evaluation
+	query := `
+		SELECT DISTINCT ON (conversation_id) * FROM evaluations
+		WHERE conversation_id = ANY($1)
+		ORDER BY conversation_id, created_at DESC
+	`
+	if err := r.db.Select(&evaluations, query, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to get latest evaluations: %w", err)
+	}
+
+	var annotations []models.Annotation
+	query = `SELECT * FROM annotations WHERE conversation_id = ANY($1) ORDER BY created_at`
+	if err := r.db.Select(&annotations, query, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to get annotations: %w", err)
+	}
+
+	latest := make(map[string]*models.Evaluation, len(evaluations))
+	for i := range evaluations {
+		latest[evaluations[i].ConversationID] = &evaluations[i]
+	}
+	byConversation := make(map[string][]models.Annotation)
+	for _, ann := range annotations {
+		byConversation[ann.ConversationID] = append(byConversation[ann.ConversationID], ann)
+	}
+
+	items := make([]models.AnnotationExportItem, len(convs))
+	for i, conv := range convs {
+		items[i] = models.AnnotationExportItem{
+			Conversation:     conv,
+			LatestEvaluation: latest[conv.ConversationID],
+			Annotations:      byConversation[conv.ConversationID],
+		}
+	}
+
+	return items, nil
+}
+
+// StreamEvaluationExport reads the evaluations matching a filter in creation
+// order and passes each to fn without loading them all into memory. The
+// filters are applied in the query so only matching rows leave the database.
+func (r *Repository) StreamEvaluationExport(filter models.EvaluationExportFilter, fn func(row *models.EvaluationExportRow) error) error {
+	query := `
+		SELECT e.evaluation_id, e.conversation_id, c.agent_version,
+			COALESCE(e.overall_score, 0) AS overall_score,
+			COALESCE(e.response_quality_score, 0) AS response_quality_score,
+			COALESCE(e.tool_accuracy_score, 0) AS tool_accuracy_score,
+			COALESCE(e.coherence_score, 0) AS coherence_score,
+			jsonb_array_length(COALESCE(e.issues_detected, '[]'::jsonb)) AS issue_count,
+			COALESCE(e.evaluator_version, '') AS evaluator_version,
+			e.trigger_source,
+			COALESCE(e.evaluation_duration_ms, 0) AS evaluation_duration_ms,
+			e.created_at, e.verdict
+		FROM evaluations e
+		JOIN conversations c ON c.conversation_id = e.conversation_id
+		WHERE TRUE
+	`
+	args := []interface{}{}
+	query += r.projectFilter("e.project_id", &args)
+	argIndex := len(args) + 1
+
+	if !filter.From.IsZero() {
+		query += fmt.Sprintf(" AND e.created_at >= $%d", argIndex)
+		args = append(args, filter.From)
+		argIndex++
+	}
+	if !filter.To.IsZero() {
+		query += fmt.Sprintf(" AND e.created_at < $%d", argIndex)
+		args = append(args, filter.To)
+		argIndex++
+	}
+	if len(filter.AgentVersions) > 0 {
+		query += fmt.Sprintf(" AND c.agent_version = ANY($%d)", argIndex)
+		args = append(args, pq.Array(filter.AgentVersions))
+		argIndex++
+	}
+	if filter.ConversationID != "" {
+		query += fmt.Sprintf(" AND e.conversation_id = $%d", argIndex)
+		args = append(args, filter.ConversationID)
+		argIndex++
+	}
+	if filter.TriggerSource != "" {
+		query += fmt.Sprintf(" AND e.trigger_source = $%d", argIndex)
+		args = append(args, filter.TriggerSource)
+		argIndex++
+	}
+	if filter.Verdict != "" {
+		query += fmt.Sprintf(" AND e.verdict = $%d", argIndex)
+		args = append(args, filter.Verdict)
+		argIndex++
+	}
+	if filter.MinScore != nil {
+		query += fmt.Sprintf(" AND e.overall_score >= $%d", argIndex)
+		args = append(args, *filter.MinScore)
+		argIndex++
+	}
+	if filter.MaxScore != nil {
+		query += fmt.Sprintf(" AND e.overall_score <= $%d", argIndex)
+		args = append(args, *filter.MaxScore)
+		argIndex++
+	}
+
+	query += " ORDER BY e.created_at, e.id"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, filter.Limit)
+		argIndex++
+	}
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := r.db.Queryx(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query evaluation export: %w", err)
+	}
+	defer rows.Close()
+
+	var row models.EvaluationExportRow
+	for rows.Next() {
+		if err := rows.StructScan(&row); err != nil {
+			return fmt.Errorf("failed to scan evaluation export row: %w", err)
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read evaluation export: %w", err)
+	}
+
+	return nil
+}
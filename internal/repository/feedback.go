@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// ListFeedbackSignals lists feedback with comments since the given time,
+// each with the latest overall score and issue types of its conversation. An
+// empty agentVersion includes every version.
+func (r *Repository) ListFeedbackSignals(since time.Time, agentVersion string) ([]models.FeedbackSignal, error) {
+	args := []interface{}{since, agentVersion}
+	query := `
+		SELECT f.conversation_id, f.sentiment, f.user_rating, f.themes,
+			   s.latest_overall_score AS overall_score,
+			   COALESCE((
+				   SELECT jsonb_agg(DISTINCT i.value->>'type')
+				   FROM evaluations e, jsonb_array_elements(COALESCE(e.issues_detected, '[]'::jsonb)) AS i(value)
+				   WHERE e.evaluation_id = s.latest_evaluation_id AND i.value->>'type' IS NOT NULL
+			   ), '[]'::jsonb) AS issue_types
+		FROM feedbacks f
+		JOIN conversations c ON c.conversation_id = f.conversation_id
+		LEFT JOIN conversation_summaries s ON s.conversation_id = f.conversation_id
+		WHERE f.comment <> '' AND f.created_at >= $1 AND ($2 = '' OR c.agent_version = $2)` +
+		r.projectFilter("c.project_id", &args) + `
+		ORDER BY f.created_at
+	`
+
+	signals := []models.FeedbackSignal{}
+	if err := r.db.Select(&signals, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list feedback signals: %w", err)
+	}
+
+	return signals, nil
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+)
+
+// healthInputRow holds the stored signals health is computed from
+type healthInputRow struct {
+	ConversationID    string          `db:"conversation_id"`
+	AgentVersion      string          `db:"agent_version"`
+	Turns             json.RawMessage `db:"turns"`
+	OverallScore      sql.NullFloat64 `db:"overall_score"`
+	IssuesDetected    json.RawMessage `db:"issues_detected"`
+	AverageUserRating sql.NullFloat64 `db:"average_user_rating"`
+}
+
+// inputs decodes the row into health inputs. With a non-zero asOf, turns
+// timestamped after it are left out of the tool failure count.
+func (row *healthInputRow) inputs(asOf time.Time) (services.HealthInputs, error) {
+	var inputs services.HealthInputs
+
+	var turns []models.Turn
+	if err := json.Unmarshal(row.Turns, &turns); err != nil {
+		return inputs, fmt.Errorf("failed to unmarshal turns: %w", err)
+	}
+	if !asOf.IsZero() {
+		existing := turns[:0]
+		for _, turn := range turns {
+			if !turn.Timestamp.After(asOf) {
+				existing = append(existing, turn)
+			}
+		}
+		turns = existing
+	}
+	inputs.ToolCalls, inputs.FailedToolCalls = services.CountToolFailures(turns)
+
+	if row.OverallScore.Valid {
+		inputs.OverallScore = &row.OverallScore.Float64
+		if len(row.IssuesDetected) > 0 {
+			if err := json.Unmarshal(row.IssuesDetected, &inputs.Issues); err != nil {
+				return inputs, fmt.Errorf("failed to parse issues: %w", err)
+			}
+		}
+	}
+	if row.AverageUserRating.Valid {
+		inputs.AverageUserRating = &row.AverageUserRating.Float64
+	}
+
+	return inputs, nil
+}
+
+// RefreshConversationHealth recomputes and stores the health of a conversation.
+// It returns nil if there is nothing to compute health from yet.
+func (r *Repository) RefreshConversationHealth(conversationID string, policy models.HealthPolicy) (*models.ConversationHealth, error) {
+	conv, err := r.GetConversation(conversationID)
+	if err != nil || conv == nil {
+		return nil, err
+	}
+
+	eval, err := r.GetLatestEvaluationForConversation(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	row := healthInputRow{Turns: conv.Turns}
+	if eval != nil {
+		row.OverallScore = sql.NullFloat64{Float64: eval.OverallScore, Valid: true}
+		row.IssuesDetected = eval.IssuesDetected
+	}
+
+	if err := r.db.Get(&row.AverageUserRating, `SELECT AVG(user_rating) FROM feedbacks WHERE conversation_id = $1 AND user_rating IS NOT NULL`, conversationID); err != nil {
+		return nil, fmt.Errorf("failed to get user rating: %w", err)
+	}
+
+	inputs, err := row.inputs(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	score, components, ok := services.ComputeHealth(inputs, policy)
+	if !ok {
+		return nil, nil
+	}
+	componentsJSON, err := json.Marshal(components)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal health components: %w", err)
+	}
+
+	query := `
+		INSERT INTO conversation_health (conversation_id, health_score, components, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (conversation_id) DO UPDATE SET
+			health_score = EXCLUDED.health_score,
+			components = EXCLUDED.components,
+			updated_at = EXCLUDED.updated_at
+		RETURNING *
+	`
+
+	var health models.ConversationHealth
+	if err := r.db.QueryRowx(query, conversationID, score, componentsJSON, time.Now().UTC()).StructScan(&health); err != nil {
+		return nil, fmt.Errorf("failed to store conversation health: %w", err)
+	}
+
+	return &health, nil
+}
+
+// ListStaleHealthConversations lists conversations evaluated or given feedback
+// since their health was last computed
+func (r *Repository) ListStaleHealthConversations(limit int) ([]string, error) {
+	var ids []string
+	query := `
+		SELECT c.conversation_id FROM conversations c
+		LEFT JOIN conversation_health h ON h.conversation_id = c.conversation_id
+		LEFT JOIN conversation_summaries s ON s.conversation_id = c.conversation_id
+		WHERE (h.conversation_id IS NULL AND (s.conversation_id IS NOT NULL
+				OR EXISTS (SELECT 1 FROM feedbacks f WHERE f.conversation_id = c.conversation_id)))
+			OR s.updated_at > h.updated_at
+			OR EXISTS (SELECT 1 FROM feedbacks f WHERE f.conversation_id = c.conversation_id AND f.created_at > h.updated_at)
+		ORDER BY c.created_at
+		LIMIT $1
+	`
+
+	if err := r.db.Select(&ids, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to list stale conversation health: %w", err)
+	}
+
+	return ids, nil
+}
+
+// GetHealthLeaderboard ranks agent versions by average conversation health
+func (r *Repository) GetHealthLeaderboard(unhealthyThreshold float64, since time.Time, limit int) ([]models.HealthLeaderboardEntry, error) {
+	entries := []models.HealthLeaderboardEntry{}
+	args := []interface{}{unhealthyThreshold, since, limit}
+	query := `
+		SELECT c.agent_version,
+			AVG(h.health_score) AS average_health,
+			COUNT(*) AS conversations,
+			AVG(CASE WHEN h.health_score < $1 THEN 1.0 ELSE 0.0 END) AS unhealthy_fraction
+		FROM conversation_health h
+		JOIN conversations c ON c.conversation_id = h.conversation_id
+		WHERE c.created_at >= $2` + r.projectFilter("c.project_id", &args) + `
+		GROUP BY c.agent_version
+		ORDER BY average_health DESC
+		LIMIT $3
+	`
+
+	if err := r.db.Select(&entries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get health leaderboard: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetHealthLeaderboardAsOf ranks agent versions by average conversation health
+// as it was at asOf. Stored health reflects the latest data, so health is
+// recomputed with the given policy from the evaluations, feedback and turns
+// that existed at asOf.
+func (r *Repository) GetHealthLeaderboardAsOf(policy models.HealthPolicy, unhealthyThreshold float64, since, asOf time.Time, limit int) ([]models.HealthLeaderboardEntry, error) {
+	var rows []healthInputRow
+	args := []interface{}{since, asOf}
+	query := `
+		SELECT c.conversation_id, c.agent_version, c.turns,
+			e.overall_score, e.issues_detected, f.average_user_rating
+		FROM conversations c
+		LEFT JOIN LATERAL (
+			SELECT overall_score, issues_detected FROM evaluations
+			WHERE conversation_id = c.conversation_id AND created_at <= $2
+			ORDER BY created_at DESC LIMIT 1
+		) e ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT AVG(user_rating) AS average_user_rating FROM feedbacks
+			WHERE conversation_id = c.conversation_id AND user_rating IS NOT NULL AND created_at <= $2
+		) f ON TRUE
+		WHERE c.created_at >= $1 AND c.created_at <= $2
+	` + r.projectFilter("c.project_id", &args)
+
+	if err := r.db.Select(&rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get health inputs: %w", err)
+	}
+
+	byVersion := make(map[string]*models.HealthLeaderboardEntry)
+	for i := range rows {
+		inputs, err := rows[i].inputs(asOf)
+		if err != nil {
+			return nil, fmt.Errorf("conversation %s: %w", rows[i].ConversationID, err)
+		}
+		score, _, ok := services.ComputeHealth(inputs, policy)
+		if !ok {
+			continue
+		}
+
+		entry, exists := byVersion[rows[i].AgentVersion]
+		if !exists {
+			entry = &models.HealthLeaderboardEntry{AgentVersion: rows[i].AgentVersion}
+			byVersion[rows[i].AgentVersion] = entry
+		}
+		entry.Conversations++
+		entry.AverageHealth += score
+		if score < unhealthyThreshold {
+			entry.UnhealthyFraction++
+		}
+	}
+
+	entries := make([]models.HealthLeaderboardEntry, 0, len(byVersion))
+	for _, entry := range byVersion {
+		entry.AverageHealth /= float64(entry.Conversations)
+		entry.UnhealthyFraction /= float64(entry.Conversations)
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].AverageHealth != entries[j].AverageHealth {
+			return entries[i].AverageHealth > entries[j].AverageHealth
+		}
+		return entries[i].AgentVersion < entries[j].AgentVersion
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
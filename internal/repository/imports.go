@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// CreateConversationImport records the start of a conversation file import
+func (r *Repository) CreateConversationImport(imp *models.ConversationImport) (*models.ConversationImport, error) {
+	query := `
+		INSERT INTO conversation_imports (import_id, filename, format, size_bytes, auto_evaluate, status, project_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING *
+	`
+
+	var result models.ConversationImport
+	err := r.db.QueryRowx(query, imp.ImportID, imp.Filename, imp.Format, imp.SizeBytes, imp.AutoEvaluate, imp.Status, r.insertProject()).
+		StructScan(&result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation import: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetConversationImport gets a conversation import by ID
+func (r *Repository) GetConversationImport(importID string) (*models.ConversationImport, error) {
+	var imp models.ConversationImport
+	args := []interface{}{importID}
+	query := `SELECT * FROM conversation_imports WHERE import_id = $1` + r.projectFilter("project_id", &args)
+	if err := r.db.Get(&imp, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get conversation import: %w", err)
+	}
+
+	return &imp, nil
+}
+
+// UpdateConversationImportProgress records how many records an import has processed
+func (r *Repository) UpdateConversationImportProgress(importID string, processed, imported, failed int) error {
+	_, err := r.db.Exec(`
+		UPDATE conversation_imports SET processed = $2, imported = $3, failed = $4
+		WHERE import_id = $1
+	`, importID, processed, imported, failed)
+	if err != nil {
+		return fmt.Errorf("failed to update conversation import: %w", err)
+	}
+
+	return nil
+}
+
+// FinishConversationImport records the final state of an import
+func (r *Repository) FinishConversationImport(importID, status string, processed, imported, failed int, errMsg string) error {
+	_, err := r.db.Exec(`
+		UPDATE conversation_imports
+		SET status = $2, processed = $3, imported = $4, failed = $5, error = $6, finished_at = CURRENT_TIMESTAMP
+		WHERE import_id = $1
+	`, importID, status, processed, imported, failed, errMsg)
+	if err != nil {
+		return fmt.Errorf("failed to finish conversation import: %w", err)
+	}
+
+	return nil
+}
+
+// AddConversationImportError records a record that couldn't be imported
+func (r *Repository) AddConversationImportError(importErr *models.ConversationImportError) error {
+	_, err := r.db.Exec(`
+		INSERT INTO conversation_import_errors (import_id, line, conversation_id, error)
+		VALUES ($1, $2, $3, $4)
+	`, importErr.ImportID, importErr.Line, importErr.ConversationID, importErr.Error)
+	if err != nil {
+		return fmt.Errorf("failed to record conversation import error: %w", err)
+	}
+
+	return nil
+}
+
+// ListConversationImportErrors lists the errors of an import in file order
+func (r *Repository) ListConversationImportErrors(importID string) ([]models.ConversationImportError, error) {
+	importErrors := []models.ConversationImportError{}
+	query := `
+		SELECT import_id, line, conversation_id, error FROM conversation_import_errors
+		WHERE import_id = $1
+		ORDER BY line, id
+	`
+	if err := r.db.Select(&importErrors, query, importID); err != nil {
+		return nil, fmt.Errorf("failed to list conversation import errors: %w", err)
+	}
+
+	return importErrors, nil
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/lib/pq"
+)
+
+// ReplaceExternalAnnotations stores the annotations of an annotation made in an
+// external tool, replacing whatever was stored for it before so redelivered
+// and updated events don't duplicate annotations. The annotators' assigned
+// tasks the annotations answer are marked completed. It returns nil, without
+// storing anything, if an annotation is of a conversation outside the
+// repository's project.
+func (r *Repository) ReplaceExternalAnnotations(source, externalID string, anns []models.AnnotationCreate) ([]models.Annotation, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	args := []interface{}{source, externalID}
+	query := `
+		DELETE FROM annotations WHERE id IN (
+			SELECT annotation_id FROM external_annotations WHERE source = $1 AND external_id = $2
+		)` + r.projectFilter("project_id", &args)
+	if _, err = tx.Exec(query, args...); err != nil {
+		return nil, fmt.Errorf("failed to delete previous annotations: %w", err)
+	}
+
+	created := make([]models.Annotation, 0, len(anns))
+	for _, ann := range anns {
+		if err := r.checkConversationProject(tx.Get, ann.ConversationID); err != nil {
+			if err == errConversationNotFound {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to check conversation project: %w", err)
+		}
+
+		labelsJSON, err := json.Marshal(ann.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal labels: %w", err)
+		}
+
+		var result models.Annotation
+		err = tx.QueryRowx(`
+			INSERT INTO annotations (
+				conversation_id, annotator_id, annotation_type, label, labels,
+				score, confidence, notes, time_spent_seconds, project_id
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9,
+				COALESCE((SELECT project_id FROM conversations WHERE conversation_id = $1), $10))
+			RETURNING id, conversation_id, annotator_id, annotation_type, label, labels,
+					  score, confidence, notes, time_spent_seconds, assisted, suggested_labels,
+					  project_id, created_at
+		`,
+			ann.ConversationID, ann.AnnotatorID, ann.AnnotationType, ann.Label, labelsJSON,
+			ann.Score, ann.Confidence, ann.Notes, ann.TimeSpentSeconds, models.DefaultProjectID,
+		).StructScan(&result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create annotation: %w", err)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO external_annotations (source, external_id, annotation_id)
+			VALUES ($1, $2, $3)
+		`, source, externalID, result.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to link external annotation: %w", err)
+		}
+
+		_, err = tx.Exec(`
+			UPDATE annotation_tasks SET status = 'completed', updated_at = CURRENT_TIMESTAMP
+			WHERE conversation_id = $1 AND annotation_type = $2 AND annotator_id = $3
+			  AND status <> 'completed'
+		`, ann.ConversationID, ann.AnnotationType, ann.AnnotatorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to complete annotation task: %w", err)
+		}
+
+		created = append(created, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return created, nil
+}
+
+// DeleteExternalAnnotations deletes the annotations stored for annotations
+// removed in an external tool and returns how many were deleted. Scoped
+// repositories only delete their project's annotations.
+func (r *Repository) DeleteExternalAnnotations(source string, externalIDs []string) (int, error) {
+	args := []interface{}{source, pq.Array(externalIDs)}
+	query := `
+		DELETE FROM annotations WHERE id IN (
+			SELECT annotation_id FROM external_annotations WHERE source = $1 AND external_id = ANY($2)
+		)` + r.projectFilter("project_id", &args)
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete external annotations: %w", err)
+	}
+
+	deleted, _ := res.RowsAffected()
+	return int(deleted), nil
+}
+
+// ListUntrackedPatterns lists unresolved failure patterns without an issue
+// tracker ticket that have one of the given severities and occurred at least
+// minOccurrences times, most frequent first
+func (r *Repository) ListUntrackedPatterns(severities []string, minOccurrences, limit int) ([]models.FailurePattern, error) {
+	patterns := []models.FailurePattern{}
+	query := `
+		SELECT * FROM failure_patterns
+		WHERE NOT resolved AND external_issue_key IS NULL
+			AND severity = ANY($1) AND occurrence_count >= $2
+		ORDER BY occurrence_count DESC
+		LIMIT $3
+	`
+
+	if err := r.db.Select(&patterns, query, pq.Array(severities), minOccurrences, limit); err != nil {
+		return nil, fmt.Errorf("failed to list untracked patterns: %w", err)
+	}
+
+	return patterns, nil
+}
+
+// ListTrackedPatterns lists failure patterns with an issue tracker ticket that
+// are unresolved or changed since they were last synced
+func (r *Repository) ListTrackedPatterns(provider string) ([]models.FailurePattern, error) {
+	patterns := []models.FailurePattern{}
+	query := `
+		SELECT * FROM failure_patterns
+		WHERE external_provider = $1 AND external_issue_key IS NOT NULL
+			AND (NOT resolved OR external_synced_at IS NULL OR updated_at > external_synced_at)
+		ORDER BY updated_at
+	`
+
+	if err := r.db.Select(&patterns, query, provider); err != nil {
+		return nil, fmt.Errorf("failed to list tracked patterns: %w", err)
+	}
+
+	return patterns, nil
+}
+
+// SetPatternExternalIssue records the issue tracker ticket of a failure pattern
+func (r *Repository) SetPatternExternalIssue(patternID, provider, key, url string, syncedAt time.Time) error {
+	query := `
+		UPDATE failure_patterns
+		SET external_provider = $1, external_issue_key = $2, external_issue_url = $3, external_synced_at = $4
+		WHERE pattern_id = $5
+	`
+	if _, err := r.db.Exec(query, provider, key, url, syncedAt, patternID); err != nil {
+		return fmt.Errorf("failed to set pattern issue: %w", err)
+	}
+	return nil
+}
+
+// MarkPatternSynced records when a failure pattern was last synced with its ticket
+func (r *Repository) MarkPatternSynced(patternID string, syncedAt time.Time) error {
+	if _, err := r.db.Exec(`UPDATE failure_patterns SET external_synced_at = $1 WHERE pattern_id = $2`, syncedAt, patternID); err != nil {
+		return fmt.Errorf("failed to mark pattern synced: %w", err)
+	}
+	return nil
+}
+
+// ListUnannouncedPatterns lists unresolved failure patterns not yet announced
+// in Slack that have one of the given severities and occurred at least
+// minOccurrences times, most frequent first
+func (r *Repository) ListUnannouncedPatterns(severities []string, minOccurrences, limit int) ([]models.FailurePattern, error) {
+	patterns := []models.FailurePattern{}
+	query := `
+		SELECT * FROM failure_patterns
+		WHERE NOT resolved AND slack_notified_at IS NULL
+			AND severity = ANY($1) AND occurrence_count >= $2
+		ORDER BY occurrence_count DESC
+		LIMIT $3
+	`
+
+	if err := r.db.Select(&patterns, query, pq.Array(severities), minOccurrences, limit); err != nil {
+		return nil, fmt.Errorf("failed to list unannounced patterns: %w", err)
+	}
+
+	return patterns, nil
+}
+
+// ListPatternProjects lists the projects of a failure pattern's example
+// conversations
+func (r *Repository) ListPatternProjects(pattern *models.FailurePattern) ([]string, error) {
+	projects := []string{}
+	query := `
+		SELECT DISTINCT project_id FROM conversations
+		WHERE conversation_id IN (SELECT jsonb_array_elements_text(COALESCE($1::jsonb, '[]')))
+		ORDER BY project_id
+	`
+	if err := r.db.Select(&projects, query, pattern.ExampleConversations); err != nil {
+		return nil, fmt.Errorf("failed to list pattern projects: %w", err)
+	}
+	return projects, nil
+}
+
+// MarkPatternAnnounced records when a failure pattern was announced in Slack
+func (r *Repository) MarkPatternAnnounced(patternID string, notifiedAt time.Time) error {
+	if _, err := r.db.Exec(`UPDATE failure_patterns SET slack_notified_at = $1 WHERE pattern_id = $2`, notifiedAt, patternID); err != nil {
+		return fmt.Errorf("failed to mark pattern announced: %w", err)
+	}
+	return nil
+}
+
+// SetFailurePatternResolved resolves or reopens a failure pattern. It returns
+// false if the pattern doesn't exist.
+func (r *Repository) SetFailurePatternResolved(patternID string, resolved bool, notes string) (bool, error) {
+	args := []interface{}{resolved, notes, time.Now().UTC(), patternID}
+	query := `
+		UPDATE failure_patterns
+		SET resolved = $1, resolution_notes = COALESCE(NULLIF($2, ''), resolution_notes), updated_at = $3
+		WHERE pattern_id = $4` + r.patternFilter("example_conversations", &args)
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update pattern resolution: %w", err)
+	}
+
+	updated, _ := res.RowsAffected()
+	return updated > 0, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+)
+
+// GetIntentQuality breaks the quality of conversations created since the
+// given time down by intent. A conversation fails when its latest overall
+// score is below lowScoreThreshold or it has critical issues. An empty
+// agentVersion includes every version.
+func (r *Repository) GetIntentQuality(since time.Time, agentVersion string, lowScoreThreshold float64, topIssueTypes int) ([]models.IntentQuality, error) {
+	args := []interface{}{since, agentVersion, lowScoreThreshold, services.IntentUnclassified}
+	query := `
+		SELECT COALESCE(NULLIF(c.intent, ''), $4) AS intent,
+			   COUNT(*) AS conversations,
+			   COUNT(s.conversation_id) AS evaluated,
+			   COUNT(*) FILTER (WHERE s.latest_overall_score < $3 OR s.critical_issue_count > 0) AS failed,
+			   AVG(s.latest_overall_score) AS avg_overall_score,
+			   AVG(e.response_quality_score) AS avg_response_quality_score,
+			   AVG(e.tool_accuracy_score) AS avg_tool_accuracy_score,
+			   AVG(e.coherence_score) AS avg_coherence_score
+		FROM conversations c
+		LEFT JOIN conversation_summaries s ON s.conversation_id = c.conversation_id
+		LEFT JOIN evaluations e ON e.evaluation_id = s.latest_evaluation_id
+		WHERE c.created_at >= $1 AND ($2 = '' OR c.agent_version = $2)` + r.projectFilter("c.project_id", &args) + `
+		GROUP BY 1
+		ORDER BY conversations DESC, intent
+	`
+
+	intents := []models.IntentQuality{}
+	if err := r.db.Select(&intents, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get intent quality: %w", err)
+	}
+
+	var issues []struct {
+		Intent        string `db:"intent"`
+		IssueType     string `db:"issue_type"`
+		Conversations int    `db:"conversations"`
+	}
+	args = []interface{}{since, agentVersion, services.IntentUnclassified}
+	query = `
+		SELECT COALESCE(NULLIF(c.intent, ''), $3) AS intent, i.value->>'type' AS issue_type,
+			   COUNT(DISTINCT c.conversation_id) AS conversations
+		FROM conversations c
+		JOIN conversation_summaries s ON s.conversation_id = c.conversation_id
+		JOIN evaluations e ON e.evaluation_id = s.latest_evaluation_id
+		CROSS JOIN LATERAL jsonb_array_elements(COALESCE(e.issues_detected, '[]'::jsonb)) AS i(value)
+		WHERE c.created_at >= $1 AND ($2 = '' OR c.agent_version = $2) AND i.value->>'type' IS NOT NULL` +
+		r.projectFilter("c.project_id", &args) + `
+		GROUP BY 1, 2
+		ORDER BY 1, conversations DESC, issue_type
+	`
+	if err := r.db.Select(&issues, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get intent issue types: %w", err)
+	}
+
+	byIntent := make(map[string]*models.IntentQuality, len(intents))
+	for i := range intents {
+		intent := &intents[i]
+		intent.TopIssueTypes = []models.IssueTypeCount{}
+		if intent.Evaluated > 0 {
+			rate := float64(intent.Failed) / float64(intent.Evaluated)
+			intent.FailureRate = &rate
+		}
+		byIntent[intent.Intent] = intent
+	}
+	for _, issue := range issues {
+		intent, ok := byIntent[issue.Intent]
+		if ok && len(intent.TopIssueTypes) < topIssueTypes {
+			intent.TopIssueTypes = append(intent.TopIssueTypes, models.IssueTypeCount{
+				IssueType:     issue.IssueType,
+				Conversations: issue.Conversations,
+			})
+		}
+	}
+
+	return intents, nil
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "github.com/ai-agent-eval/internal/database"
+
+// InvalidationScopeRollouts is published when evaluator rollouts start or
+// end, so replicas reload the versions they route new tasks to
+const InvalidationScopeRollouts = "rollouts"
+
+// PublishInvalidation tells every replica to reload the given cache scope
+func (r *Repository) PublishInvalidation(scope string) error {
+	return database.Notify(r.db.DB, database.InvalidationChannel, scope)
+}
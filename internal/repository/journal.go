@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/jmoiron/sqlx"
+)
+
+// transitionJSON is the transitions entry recording a task entering a state
+const transitionJSON = `jsonb_build_array(jsonb_build_object('state', $1::text, 'worker_id', $2::text, 'at', NOW()))`
+
+// JournalTaskDequeued records that a worker took a task from the queue. A
+// task that was already completed is not journaled again: its completed
+// entry is returned so the worker can skip it.
+func (r *Repository) JournalTaskDequeued(entry *models.TaskJournalEntry) (*models.TaskJournalEntry, error) {
+	query := `
+		INSERT INTO task_journal (state, worker_id, transitions, task_id, queue, task_type, conversation_id, attempts, payload)
+		VALUES ($1, $2, ` + transitionJSON + `, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (task_id) DO UPDATE SET
+			state = EXCLUDED.state,
+			worker_id = EXCLUDED.worker_id,
+			attempts = EXCLUDED.attempts,
+			payload = EXCLUDED.payload,
+			transitions = task_journal.transitions || EXCLUDED.transitions,
+			updated_at = NOW()
+		WHERE task_journal.state <> '` + models.TaskStateCompleted + `'
+		RETURNING *
+	`
+
+	var journaled models.TaskJournalEntry
+	err := r.db.QueryRowx(query, models.TaskStateDequeued, entry.WorkerID, entry.TaskID, entry.Queue,
+		entry.TaskType, entry.ConversationID, entry.Attempts, entry.Payload).StructScan(&journaled)
+	if err == sql.ErrNoRows {
+		// Already completed
+		if err := r.db.Get(&journaled, `SELECT * FROM task_journal WHERE task_id = $1`, entry.TaskID); err != nil {
+			return nil, fmt.Errorf("failed to get journaled task: %w", err)
+		}
+		return &journaled, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to journal task: %w", err)
+	}
+
+	return &journaled, nil
+}
+
+// TransitionTask records that a dequeued task was retried, dead-lettered or
+// requeued without completing. Completed tasks keep their state.
+func (r *Repository) TransitionTask(taskID, state, workerID, lastError string) error {
+	query := `
+		UPDATE task_journal SET
+			state = $1, worker_id = $2, last_error = $4,
+			transitions = transitions || ` + transitionJSON + `,
+			updated_at = NOW()
+		WHERE task_id = $3 AND state <> '` + models.TaskStateCompleted + `'
+	`
+	if _, err := r.db.Exec(query, state, workerID, taskID, lastError); err != nil {
+		return fmt.Errorf("failed to update journaled task: %w", err)
+	}
+	return nil
+}
+
+// PersistTaskEvaluation stores the evaluation a task produced and completes
+// the task in one transaction. If the task was already completed nothing is
+// written and the ID of the evaluation it produced is returned instead, so a
+// task delivered twice never stores two evaluations.
+func (r *Repository) PersistTaskEvaluation(taskID, queueName string, eval *models.Evaluation) (string, error) {
+	var evaluationID string
+	err := r.inTaskTransaction(taskID, queueName, eval.ConversationID, &evaluationID, func(tx *sqlx.Tx) (string, error) {
+		if err := r.writeEvaluation(tx, eval); err != nil {
+			return "", fmt.Errorf("failed to store evaluation: %w", err)
+		}
+		return eval.EvaluationID, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if evaluationID == eval.EvaluationID {
+		// Timings are best effort and must not fail the evaluation
+		r.RecordPipelineStage(taskID, models.PipelineStagePersisted, eval.CreatedAt)
+	}
+	return evaluationID, nil
+}
+
+// CompleteTaskEvaluation completes a partial evaluation with the result of
+// a task and completes the task in one transaction. Like
+// PersistTaskEvaluation, a task that was already completed changes nothing.
+// It returns an empty ID if the evaluation doesn't exist.
+func (r *Repository) CompleteTaskEvaluation(taskID, queueName, evaluationID string, retried []string, result *services.EvaluationResult) (string, error) {
+	var completedID string
+	err := r.inTaskTransaction(taskID, queueName, result.ConversationID, &completedID, func(tx *sqlx.Tx) (string, error) {
+		eval, err := r.completeEvaluation(tx, evaluationID, retried, result)
+		if err != nil || eval == nil {
+			return "", err
+		}
+		return eval.EvaluationID, nil
+	})
+	return completedID, err
+}
+
+// inTaskTransaction runs persist in a transaction that locks the task's
+// journal entry and marks it completed with the evaluation ID persist
+// returns. If the entry is already completed, persist isn't run and
+// evaluationID is set to the one it was completed with. Nothing is
+// committed when persist returns an empty ID.
+func (r *Repository) inTaskTransaction(taskID, queueName, conversationID string, evaluationID *string, persist func(tx *sqlx.Tx) (string, error)) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Tasks dequeued by other consumers have no entry yet; creating one
+	// takes the lock the same way
+	_, err = tx.Exec(`
+		INSERT INTO task_journal (task_id, queue, conversation_id, state)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (task_id) DO NOTHING
+	`, taskID, queueName, conversationID, models.TaskStateDequeued)
+	if err != nil {
+		return fmt.Errorf("failed to journal task: %w", err)
+	}
+
+	var journaled models.TaskJournalEntry
+	if err := tx.Get(&journaled, `SELECT * FROM task_journal WHERE task_id = $1 FOR UPDATE`, taskID); err != nil {
+		return fmt.Errorf("failed to lock journaled task: %w", err)
+	}
+	if journaled.State == models.TaskStateCompleted {
+		*evaluationID = journaled.EvaluationID
+		return nil
+	}
+
+	id, err := persist(tx)
+	if err != nil || id == "" {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE task_journal SET
+			state = $1, worker_id = $2, evaluation_id = $4, last_error = '',
+			transitions = transitions || `+transitionJSON+`,
+			updated_at = NOW()
+		WHERE task_id = $3
+	`, models.TaskStateCompleted, journaled.WorkerID, taskID, id)
+	if err != nil {
+		return fmt.Errorf("failed to complete journaled task: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	*evaluationID = id
+	return nil
+}
+
+// RecoverStaleTasks finds tasks of a queue that were dequeued longer ago
+// than staleAfter without reaching another state, which means their worker
+// stopped mid-task, and passes each to requeue. Tasks requeue succeeds for
+// are journaled as recovered. Entries are locked while they are recovered,
+// so each stale task is recovered by one replica.
+func (r *Repository) RecoverStaleTasks(queueName, workerID string, staleAfter time.Duration, requeue func(entry *models.TaskJournalEntry) error) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var stale []models.TaskJournalEntry
+	err = tx.Select(&stale, `
+		SELECT * FROM task_journal
+		WHERE queue = $1 AND state = $2 AND updated_at < $3
+		ORDER BY updated_at
+		LIMIT 100
+		FOR UPDATE SKIP LOCKED
+	`, queueName, models.TaskStateDequeued, time.Now().UTC().Add(-staleAfter))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find stale tasks: %w", err)
+	}
+
+	recovered := 0
+	for i := range stale {
+		if err := requeue(&stale[i]); err != nil {
+			break // Left for the next recovery
+		}
+		_, err = tx.Exec(`
+			UPDATE task_journal SET
+				state = $1, worker_id = $2,
+				transitions = transitions || `+transitionJSON+`,
+				updated_at = NOW()
+			WHERE task_id = $3
+		`, models.TaskStateRecovered, workerID, stale[i].TaskID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to journal recovered task: %w", err)
+		}
+		recovered++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return recovered, nil
+}
+
+// GetJournaledTask returns the journal entry of a task, or nil if the task
+// was never dequeued
+func (r *Repository) GetJournaledTask(taskID string) (*models.TaskJournalEntry, error) {
+	var entry models.TaskJournalEntry
+	if err := r.db.Get(&entry, `SELECT * FROM task_journal WHERE task_id = $1`, taskID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get journaled task: %w", err)
+	}
+	return &entry, nil
+}
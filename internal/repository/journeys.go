@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// journeyKeyColumns maps journey keys to the indexed metadata expressions
+var journeyKeyColumns = map[string]string{
+	models.JourneyKeySubject: "c.metadata->>'subject_id'",
+	models.JourneyKeySession: "c.metadata->>'session_id'",
+}
+
+// StreamJourneyConversations reads the conversations created since the
+// given time that have a journey key, with their latest evaluation, and
+// passes each to fn grouped by journey, oldest first. An empty agentVersion
+// includes every version.
+func (r *Repository) StreamJourneyConversations(since time.Time, agentVersion, key string, fn func(conv *models.JourneyConversation) error) error {
+	column, ok := journeyKeyColumns[key]
+	if !ok {
+		return fmt.Errorf("unsupported journey key %q", key)
+	}
+
+	args := []interface{}{since, agentVersion}
+	query := `
+		SELECT ` + column + ` AS journey_id, c.conversation_id, c.created_at,
+			   COALESCE(c.metadata->'mission_completed' = 'true'::jsonb, FALSE) AS mission_completed,
+			   COALESCE(c.metadata->'escalated' = 'true'::jsonb, FALSE) AS escalated,
+			   s.latest_overall_score AS overall_score,
+			   COALESCE(s.critical_issue_count, 0) AS critical_issue_count
+		FROM conversations c
+		LEFT JOIN conversation_summaries s ON s.conversation_id = c.conversation_id
+		WHERE c.created_at >= $1 AND ($2 = '' OR c.agent_version = $2) AND ` + column + ` <> ''` +
+		r.projectFilter("c.project_id", &args) + `
+		ORDER BY journey_id, c.created_at, c.id
+	`
+
+	rows, err := r.db.Queryx(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query journey conversations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var conv models.JourneyConversation
+		if err := rows.StructScan(&conv); err != nil {
+			return fmt.Errorf("failed to scan journey conversation: %w", err)
+		}
+		if err := fn(&conv); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read journey conversations: %w", err)
+	}
+
+	return nil
+}
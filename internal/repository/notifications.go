@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// UpsertNotificationPreference sets the notification preferences of a project team
+func (r *Repository) UpsertNotificationPreference(projectID, team string, pref *models.NotificationPreferenceUpdate) (*models.NotificationPreference, error) {
+	channelsJSON, err := json.Marshal(pref.Channels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal channels: %w", err)
+	}
+	severities := pref.Severities
+	if severities == nil {
+		severities = []string{}
+	}
+	severitiesJSON, err := json.Marshal(severities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal severities: %w", err)
+	}
+
+	query := `
+		INSERT INTO notification_preferences (
+			project_id, team, channels, severities, digest_frequency,
+			quiet_hours_start, quiet_hours_end, timezone
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (project_id, team) DO UPDATE SET
+			channels = EXCLUDED.channels,
+			severities = EXCLUDED.severities,
+			digest_frequency = EXCLUDED.digest_frequency,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			timezone = EXCLUDED.timezone,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING *
+	`
+
+	var result models.NotificationPreference
+	if err := r.db.QueryRowx(
+		query, projectID, team, channelsJSON, severitiesJSON, pref.DigestFrequency,
+		pref.QuietHoursStart, pref.QuietHoursEnd, pref.Timezone,
+	).StructScan(&result); err != nil {
+		return nil, fmt.Errorf("failed to save notification preference: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListNotificationPreferences lists notification preferences, optionally for one project
+func (r *Repository) ListNotificationPreferences(projectID string) ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+
+	query := `SELECT * FROM notification_preferences`
+	args := []interface{}{}
+	if projectID != "" {
+		query += ` WHERE project_id = $1`
+		args = append(args, projectID)
+	}
+	query += ` ORDER BY project_id, team`
+
+	if err := r.db.Select(&prefs, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list notification preferences: %w", err)
+	}
+
+	return prefs, nil
+}
+
+// GetNotificationPreference retrieves the notification preferences of a project team
+func (r *Repository) GetNotificationPreference(projectID, team string) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+
+	query := `SELECT * FROM notification_preferences WHERE project_id = $1 AND team = $2`
+	if err := r.db.Get(&pref, query, projectID, team); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get notification preference: %w", err)
+	}
+
+	return &pref, nil
+}
+
+// DeleteNotificationPreference deletes a team's notification preferences. It reports whether a row was deleted.
+func (r *Repository) DeleteNotificationPreference(projectID, team string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM notification_preferences WHERE project_id = $1 AND team = $2`, projectID, team)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete notification preference: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete notification preference: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// MarkDigestSent records when a team's digest was last sent
+func (r *Repository) MarkDigestSent(id int64, sentAt time.Time) error {
+	if _, err := r.db.Exec(`UPDATE notification_preferences SET last_digest_at = $1 WHERE id = $2`, sentAt, id); err != nil {
+		return fmt.Errorf("failed to mark digest sent: %w", err)
+	}
+	return nil
+}
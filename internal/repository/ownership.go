@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+)
+
+// AssignIssueOwners assigns owners to the issues of up to limit evaluations
+// whose issues haven't been assigned yet, oldest first. It returns how many
+// issues were assigned.
+func (r *Repository) AssignIssueOwners(policy models.OwnershipPolicy, limit int) (int, error) {
+	var rows []struct {
+		models.Evaluation
+		Turns json.RawMessage `db:"turns"`
+	}
+	query := `
+		SELECT e.*, c.turns FROM evaluations e
+		JOIN conversations c ON c.conversation_id = e.conversation_id
+		WHERE jsonb_array_length(COALESCE(e.issues_detected, '[]'::jsonb)) > 0
+			AND NOT EXISTS (SELECT 1 FROM issue_assignments a WHERE a.evaluation_id = e.evaluation_id)
+		ORDER BY e.created_at
+		LIMIT $1
+	`
+	if err := r.db.Select(&rows, query, limit); err != nil {
+		return 0, fmt.Errorf("failed to list unassigned evaluations: %w", err)
+	}
+
+	assigned := 0
+	for i := range rows {
+		var turns []models.Turn
+		if err := json.Unmarshal(rows[i].Turns, &turns); err != nil {
+			return assigned, fmt.Errorf("failed to unmarshal turns: %w", err)
+		}
+		assignments, err := services.AssignIssues(&rows[i].Evaluation, turns, policy)
+		if err != nil {
+			return assigned, fmt.Errorf("failed to parse issues of %s: %w", rows[i].EvaluationID, err)
+		}
+
+		for _, a := range assignments {
+			_, err := r.db.Exec(`
+				INSERT INTO issue_assignments (
+					evaluation_id, issue_index, conversation_id, issue_type,
+					severity, description, tool, owner, created_at
+				)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				ON CONFLICT (evaluation_id, issue_index) DO NOTHING
+			`, a.EvaluationID, a.IssueIndex, a.ConversationID, a.IssueType,
+				a.Severity, a.Description, a.Tool, a.Owner, rows[i].CreatedAt)
+			if err != nil {
+				return assigned, fmt.Errorf("failed to assign issue: %w", err)
+			}
+			assigned++
+		}
+	}
+
+	return assigned, nil
+}
+
+// AssignPatternOwners assigns owners to failure patterns that have none,
+// including patterns left unowned by an earlier policy. It returns how many
+// patterns were assigned.
+func (r *Repository) AssignPatternOwners(policy models.OwnershipPolicy) (int, error) {
+	var patterns []models.FailurePattern
+	if err := r.db.Select(&patterns, `SELECT * FROM failure_patterns WHERE owner IS NULL OR owner = ''`); err != nil {
+		return 0, fmt.Errorf("failed to list unowned patterns: %w", err)
+	}
+
+	assigned := 0
+	for _, pattern := range patterns {
+		owner := services.PatternOwner(pattern, policy)
+		if owner == "" && pattern.Owner.Valid {
+			continue
+		}
+		if _, err := r.db.Exec(`UPDATE failure_patterns SET owner = $1 WHERE id = $2`, owner, pattern.ID); err != nil {
+			return assigned, fmt.Errorf("failed to assign pattern owner: %w", err)
+		}
+		if owner != "" {
+			assigned++
+		}
+	}
+
+	return assigned, nil
+}
+
+// ListOwnerIssues lists the issues assigned to an owner since a time, newest
+// first. An empty owner lists unowned issues; a non-positive limit lists all.
+func (r *Repository) ListOwnerIssues(owner, severity string, since time.Time, includeDismissed bool, limit, offset int) ([]models.IssueAssignment, error) {
+	issues := []models.IssueAssignment{}
+	query := `
+		SELECT a.*, (d.id IS NOT NULL) AS dismissed
+		FROM issue_assignments a
+		LEFT JOIN issue_dismissals d ON d.evaluation_id = a.evaluation_id AND d.issue_index = a.issue_index
+		WHERE a.owner = $1 AND a.created_at >= $2
+	`
+	args := []interface{}{owner, since}
+	query += r.evaluationFilter("a.evaluation_id", &args)
+	argIndex := len(args) + 1
+
+	if severity != "" {
+		query += fmt.Sprintf(" AND a.severity = $%d", argIndex)
+		args = append(args, severity)
+		argIndex++
+	}
+	if !includeDismissed {
+		query += " AND d.id IS NULL"
+	}
+
+	query += " ORDER BY a.created_at DESC, a.issue_index"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+		args = append(args, limit, offset)
+	}
+
+	if err := r.db.Select(&issues, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list owner issues: %w", err)
+	}
+
+	return issues, nil
+}
+
+// GetOwnerSummaries counts open issues and unresolved patterns per owner.
+// Unowned work is reported under an empty owner.
+func (r *Repository) GetOwnerSummaries(since time.Time) ([]models.OwnerSummary, error) {
+	summaries := []models.OwnerSummary{}
+	args := []interface{}{since}
+	query := `
+		WITH issues AS (
+			SELECT a.owner,
+				COUNT(*) AS open_issues,
+				COUNT(*) FILTER (WHERE a.severity = 'critical') AS critical_issues
+			FROM issue_assignments a
+			WHERE a.created_at >= $1
+				AND NOT EXISTS (
+					SELECT 1 FROM issue_dismissals d
+					WHERE d.evaluation_id = a.evaluation_id AND d.issue_index = a.issue_index
+				)` + r.evaluationFilter("a.evaluation_id", &args) + `
+			GROUP BY a.owner
+		),
+		patterns AS (
+			SELECT COALESCE(owner, '') AS owner, COUNT(*) AS unresolved_patterns
+			FROM failure_patterns
+			WHERE NOT resolved` + r.patternFilter("example_conversations", &args) + `
+			GROUP BY 1
+		)
+		SELECT COALESCE(i.owner, p.owner) AS owner,
+			COALESCE(i.open_issues, 0) AS open_issues,
+			COALESCE(i.critical_issues, 0) AS critical_issues,
+			COALESCE(p.unresolved_patterns, 0) AS unresolved_patterns
+		FROM issues i
+		FULL OUTER JOIN patterns p ON p.owner = i.owner
+		ORDER BY critical_issues DESC, open_issues DESC, owner
+	`
+
+	if err := r.db.Select(&summaries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get owner summaries: %w", err)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// pipelineStageColumns maps pipeline stages to their pipeline_timings column
+var pipelineStageColumns = map[string]string{
+	models.PipelineStageIngested:         "ingested_at",
+	models.PipelineStageQueued:           "queued_at",
+	models.PipelineStageDequeued:         "dequeued_at",
+	models.PipelineStageEvaluatorStarted: "evaluator_started_at",
+	models.PipelineStagePersisted:        "persisted_at",
+}
+
+// RecordPipelineTask records an evaluation task being queued. ingestedAt is
+// when the conversation that triggered it was ingested, or zero if the task
+// wasn't triggered by ingestion.
+func (r *Repository) RecordPipelineTask(taskID, conversationID, triggerSource string, ingestedAt, queuedAt time.Time) error {
+	var ingested *time.Time
+	if !ingestedAt.IsZero() {
+		ingested = &ingestedAt
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO pipeline_timings (task_id, conversation_id, trigger_source, ingested_at, queued_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (task_id) DO UPDATE
+		SET conversation_id = EXCLUDED.conversation_id, trigger_source = EXCLUDED.trigger_source,
+			ingested_at = EXCLUDED.ingested_at, queued_at = EXCLUDED.queued_at
+	`, taskID, conversationID, triggerSource, ingested, queuedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record pipeline task: %w", err)
+	}
+
+	return nil
+}
+
+// RecordPipelineStage records when an evaluation task reached a stage. A task
+// that reaches a stage again, such as when it is retried, keeps the latest time.
+func (r *Repository) RecordPipelineStage(taskID, stage string, at time.Time) error {
+	column, ok := pipelineStageColumns[stage]
+	if !ok {
+		return fmt.Errorf("unknown pipeline stage %q", stage)
+	}
+
+	// The column comes from pipelineStageColumns, never from the caller
+	_, err := r.db.Exec(fmt.Sprintf(`
+		INSERT INTO pipeline_timings (task_id, %[1]s) VALUES ($1, $2)
+		ON CONFLICT (task_id) DO UPDATE SET %[1]s = EXCLUDED.%[1]s
+	`, column), taskID, at.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record pipeline stage: %w", err)
+	}
+
+	return nil
+}
+
+// GetPipelineLatency returns latency percentiles between consecutive pipeline
+// stages, and from the first to the last stage, for tasks queued since the
+// given time. Tasks are only counted for segments whose both ends were
+// recorded. An empty triggerSource includes every task.
+func (r *Repository) GetPipelineLatency(since time.Time, triggerSource string) ([]models.PipelineSegmentLatency, error) {
+	stages := models.PipelineStages
+	first, last := stages[0], stages[len(stages)-1]
+
+	segments := make([]string, 0, len(stages))
+	segment := func(from, to, fromColumn string) string {
+		return fmt.Sprintf(
+			`SELECT '%s' AS from_stage, '%s' AS to_stage, %d AS position,
+				(EXTRACT(EPOCH FROM (%s - %s)) * 1000)::float8 AS ms
+			FROM timings WHERE %[5]s IS NOT NULL AND %[4]s IS NOT NULL`,
+			from, to, len(segments), pipelineStageColumns[to], fromColumn,
+		)
+	}
+	for i := 1; i < len(stages); i++ {
+		segments = append(segments, segment(stages[i-1], stages[i], pipelineStageColumns[stages[i-1]]))
+	}
+	// End to end starts at ingestion, or at queueing for tasks not triggered by it
+	segments = append(segments, segment(first, last, "COALESCE(ingested_at, queued_at)"))
+
+	args := []interface{}{since, triggerSource}
+	query := `
+		WITH timings AS (
+			SELECT * FROM pipeline_timings
+			WHERE queued_at >= $1 AND ($2 = '' OR trigger_source = $2)` + r.conversationFilter("conversation_id", &args) + `
+		),
+		durations AS (
+			` + strings.Join(segments, "\n\t\t\tUNION ALL\n\t\t\t") + `
+		)
+		SELECT from_stage, to_stage, COUNT(*) AS count,
+			   percentile_cont(0.5) WITHIN GROUP (ORDER BY ms) AS p50_ms,
+			   percentile_cont(0.9) WITHIN GROUP (ORDER BY ms) AS p90_ms,
+			   percentile_cont(0.99) WITHIN GROUP (ORDER BY ms) AS p99_ms,
+			   MAX(ms) AS max_ms
+		FROM durations
+		GROUP BY from_stage, to_stage, position
+		ORDER BY position
+	`
+
+	latencies := []models.PipelineSegmentLatency{}
+	if err := r.db.Select(&latencies, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get pipeline latency: %w", err)
+	}
+
+	return latencies, nil
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// ForProject returns a repository whose reads and writes of conversations,
+// evaluations, annotations and suggestions are scoped to a project. The
+// unscoped repository sees every project.
+func (r *Repository) ForProject(projectID string) *Repository {
+	scoped := *r
+	scoped.project = projectID
+	return &scoped
+}
+
+// projectFilter returns a condition restricting column to the repository's
+// project, appending the project to args, or an empty string when the
+// repository is unscoped
+func (r *Repository) projectFilter(column string, args *[]interface{}) string {
+	if r.project == "" {
+		return ""
+	}
+	*args = append(*args, r.project)
+	return fmt.Sprintf(" AND %s = $%d", column, len(*args))
+}
+
+// conversationFilter returns a condition restricting column, a conversation
+// ID, to conversations of the repository's project, for tables without a
+// project of their own
+func (r *Repository) conversationFilter(column string, args *[]interface{}) string {
+	if r.project == "" {
+		return ""
+	}
+	*args = append(*args, r.project)
+	return fmt.Sprintf(" AND %s IN (SELECT conversation_id FROM conversations WHERE project_id = $%d)", column, len(*args))
+}
+
+// evaluationFilter is conversationFilter for evaluation IDs
+func (r *Repository) evaluationFilter(column string, args *[]interface{}) string {
+	if r.project == "" {
+		return ""
+	}
+	*args = append(*args, r.project)
+	return fmt.Sprintf(" AND %s IN (SELECT evaluation_id FROM evaluations WHERE project_id = $%d)", column, len(*args))
+}
+
+// patternFilter restricts failure patterns, which have no project of their
+// own, to those with an example conversation in the repository's project
+func (r *Repository) patternFilter(column string, args *[]interface{}) string {
+	if r.project == "" {
+		return ""
+	}
+	*args = append(*args, r.project)
+	return fmt.Sprintf(" AND jsonb_exists_any(COALESCE(%s, '[]'), ARRAY(SELECT conversation_id FROM conversations WHERE project_id = $%d))", column, len(*args))
+}
+
+// insertProject is the project new conversations are created in
+func (r *Repository) insertProject() string {
+	if r.project == "" {
+		return models.DefaultProjectID
+	}
+	return r.project
+}
+
+// ProvisionProject creates a project with its API keys and disabled
+// placeholder webhooks in one transaction. Keys are bound to the project;
+// their Key is left as given. It returns nil if the project exists.
+func (r *Repository) ProvisionProject(project *models.ProjectCreate, keys []models.APIKeySecret, webhooks []models.ProjectWebhookCreate) (*models.ProjectOnboarding, error) {
+	evaluatorTypesJSON, err := json.Marshal(project.EvaluatorTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal evaluator types: %w", err)
+	}
+	routingJSON, err := json.Marshal(project.Routing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal routing policy: %w", err)
+	}
+	quotasJSON, err := json.Marshal(project.Quotas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal quotas: %w", err)
+	}
+	assistedJSON, err := json.Marshal(project.AssistedLabeling)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal assisted labeling: %w", err)
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	onboarding := &models.ProjectOnboarding{
+		APIKeys:  make([]models.APIKeySecret, len(keys)),
+		Webhooks: make([]models.ProjectWebhook, len(webhooks)),
+	}
+	err = tx.QueryRowx(`
+		INSERT INTO projects (project_id, name, evaluator_types, routing_policy, quotas, assisted_labeling)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (project_id) DO NOTHING
+		RETURNING *
+	`, project.ProjectID, project.Name, evaluatorTypesJSON, routingJSON, quotasJSON, assistedJSON).
+		StructScan(&onboarding.Project)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	for i, key := range keys {
+		onboarding.APIKeys[i].Key = key.Key
+		err := tx.QueryRowx(`
+			INSERT INTO api_keys (id, name, key_prefix, key_hash, scopes, project_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING *
+		`, key.ID, key.Name, key.Prefix, key.KeyHash, key.Scopes, project.ProjectID).StructScan(&onboarding.APIKeys[i].APIKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create API key: %w", err)
+		}
+	}
+
+	for i, hook := range webhooks {
+		filterJSON, err := json.Marshal(hook.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal filter: %w", err)
+		}
+		err = tx.QueryRowx(`
+			INSERT INTO project_webhooks (project_id, url, payload_template, filter, enabled)
+			VALUES ($1, $2, $3, $4, FALSE)
+			RETURNING *
+		`, project.ProjectID, hook.URL, hook.PayloadTemplate, filterJSON).StructScan(&onboarding.Webhooks[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create webhook: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit project: %w", err)
+	}
+
+	return onboarding, nil
+}
+
+// ConversationQuotaReached reports whether the repository's project has
+// created as many conversations since the start of the UTC day as its quota
+// allows. Unscoped repositories and projects without a quota have no limit.
+func (r *Repository) ConversationQuotaReached(now time.Time) (bool, error) {
+	if r.project == "" {
+		return false, nil
+	}
+
+	var usage struct {
+		Quota int `db:"quota"`
+		Used  int `db:"used"`
+	}
+	err := r.db.Get(&usage, `
+		SELECT COALESCE((quotas->>'max_conversations_per_day')::int, 0) AS quota,
+			(SELECT COUNT(*) FROM conversations WHERE project_id = $1 AND created_at >= $2) AS used
+		FROM projects WHERE project_id = $1
+	`, r.project, now.UTC().Truncate(24*time.Hour))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check conversation quota: %w", err)
+	}
+
+	return usage.Quota > 0 && usage.Used >= usage.Quota, nil
+}
+
+// ListProjects lists projects by ID
+func (r *Repository) ListProjects() ([]models.Project, error) {
+	projects := []models.Project{}
+	if err := r.db.Select(&projects, `SELECT * FROM projects ORDER BY project_id`); err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// GetProject gets a project. It returns nil if there is none.
+func (r *Repository) GetProject(projectID string) (*models.Project, error) {
+	var project models.Project
+	if err := r.db.Get(&project, `SELECT * FROM projects WHERE project_id = $1`, projectID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return &project, nil
+}
+
+// SetProjectAssistedLabeling replaces a project's assisted labeling settings.
+// It returns nil if the project doesn't exist.
+func (r *Repository) SetProjectAssistedLabeling(projectID string, settings models.AssistedLabeling) (*models.Project, error) {
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal assisted labeling: %w", err)
+	}
+
+	var project models.Project
+	err = r.db.QueryRowx(`
+		UPDATE projects SET assisted_labeling = $1 WHERE project_id = $2 RETURNING *
+	`, settingsJSON, projectID).StructScan(&project)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to set assisted labeling: %w", err)
+	}
+
+	return &project, nil
+}
+
+// SetProjectPublic opts a project in to or out of the public API. It returns
+// nil if the project doesn't exist.
+func (r *Repository) SetProjectPublic(projectID string, public bool) (*models.Project, error) {
+	var project models.Project
+	err := r.db.QueryRowx(`
+		UPDATE projects SET public = $1 WHERE project_id = $2 RETURNING *
+	`, public, projectID).StructScan(&project)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to set public access: %w", err)
+	}
+
+	return &project, nil
+}
+
+// errConversationNotFound is returned for conversations outside the
+// repository's project
+var errConversationNotFound = errors.New("conversation not found")
+
+// checkConversationProject fails unless a conversation belongs to the
+// repository's project. Unscoped repositories accept any conversation.
+func (r *Repository) checkConversationProject(get getFunc, conversationID string) error {
+	if r.project == "" {
+		return nil
+	}
+	var exists bool
+	err := get(&exists, `
+		SELECT EXISTS (SELECT 1 FROM conversations WHERE conversation_id = $1 AND project_id = $2)
+	`, conversationID, r.project)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errConversationNotFound
+	}
+	return nil
+}
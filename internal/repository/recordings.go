@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// RecordEvaluatorExchange stores a sampled evaluator service exchange
+func (r *Repository) RecordEvaluatorExchange(recording *models.EvaluatorRecording) error {
+	var response interface{}
+	if len(recording.Response) > 0 {
+		response = []byte(recording.Response)
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO evaluator_recordings (
+			conversation_id, evaluator_version, request, response,
+			status_code, error, duration_ms
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, recording.ConversationID, recording.EvaluatorVersion, []byte(recording.Request), response,
+		recording.StatusCode, recording.Error, recording.DurationMS)
+	if err != nil {
+		return fmt.Errorf("failed to record evaluator exchange: %w", err)
+	}
+
+	return nil
+}
+
+// ListReplayableRecordings lists successful recordings since a time, oldest
+// first, optionally only those answered by one evaluator version
+func (r *Repository) ListReplayableRecordings(since time.Time, evaluatorVersion string, limit int) ([]models.EvaluatorRecording, error) {
+	recordings := []models.EvaluatorRecording{}
+	query := `
+		SELECT * FROM evaluator_recordings
+		WHERE created_at >= $1 AND response IS NOT NULL AND error = ''
+	`
+	args := []interface{}{since}
+
+	if evaluatorVersion != "" {
+		query += ` AND evaluator_version = $2`
+		args = append(args, evaluatorVersion)
+	}
+
+	query += fmt.Sprintf(` ORDER BY created_at LIMIT $%d`, len(args)+1)
+	args = append(args, limit)
+
+	if err := r.db.Select(&recordings, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list evaluator recordings: %w", err)
+	}
+
+	return recordings, nil
+}
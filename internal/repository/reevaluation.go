@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/lib/pq"
+)
+
+// GetLatestScoringManifest returns the manifest of the newest evaluation run
+// by the evaluator service, or nil if there is none
+func (r *Repository) GetLatestScoringManifest() (json.RawMessage, error) {
+	var manifest json.RawMessage
+	query := `
+		SELECT manifest FROM evaluations
+		WHERE manifest->>'evaluator_version' <> '' AND COALESCE((manifest->>'external')::boolean, FALSE) = FALSE
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	if err := r.db.Get(&manifest, query); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// RecordReevaluationBaseline records a scoring configuration the first time
+// it is seen, superseding the earlier ones, and reports whether it was new.
+// The first configuration ever seen has nothing to be compared with, so it
+// is recorded without conversations to re-evaluate.
+func (r *Repository) RecordReevaluationBaseline(fingerprint string, manifest json.RawMessage, target int) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Serializes scheduler replicas noticing the same change
+	if _, err := tx.Exec(`LOCK TABLE reevaluation_baselines IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return false, fmt.Errorf("failed to lock reevaluation baselines: %w", err)
+	}
+
+	var known, exists bool
+	query := `
+		SELECT EXISTS (SELECT 1 FROM reevaluation_baselines),
+			   EXISTS (SELECT 1 FROM reevaluation_baselines WHERE fingerprint = $1)
+	`
+	if err := tx.QueryRowx(query, fingerprint).Scan(&known, &exists); err != nil {
+		return false, fmt.Errorf("failed to get reevaluation baselines: %w", err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`UPDATE reevaluation_baselines SET completed_at = NOW() WHERE completed_at IS NULL`); err != nil {
+		return false, fmt.Errorf("failed to supersede reevaluation baselines: %w", err)
+	}
+	if !known {
+		target = 0
+	}
+	query = `
+		INSERT INTO reevaluation_baselines (fingerprint, manifest, target, completed_at)
+		VALUES ($1, $2, $3, CASE WHEN $3 > 0 THEN NULL ELSE NOW() END)
+	`
+	if _, err := tx.Exec(query, fingerprint, manifest, target); err != nil {
+		return false, fmt.Errorf("failed to record reevaluation baseline: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// GetOpenReevaluationBaseline returns the scoring configuration whose
+// sample is still being queued, or nil if there is none
+func (r *Repository) GetOpenReevaluationBaseline() (*models.ReevaluationBaseline, error) {
+	var baseline models.ReevaluationBaseline
+	query := `
+		SELECT *, 0 AS evaluated FROM reevaluation_baselines
+		WHERE completed_at IS NULL
+		ORDER BY detected_at DESC
+		LIMIT 1
+	`
+	if err := r.db.Get(&baseline, query); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get reevaluation baseline: %w", err)
+	}
+	return &baseline, nil
+}
+
+// ListReevaluationBaselines lists scoring configurations, newest first, with
+// how many of their sampled conversations have been evaluated since
+func (r *Repository) ListReevaluationBaselines(limit int) ([]models.ReevaluationBaseline, error) {
+	query := `
+		SELECT b.*,
+			   (SELECT COUNT(*) FROM reevaluation_samples s
+				WHERE s.fingerprint = b.fingerprint
+				  AND EXISTS (SELECT 1 FROM evaluations e WHERE e.conversation_id = s.conversation_id AND e.created_at >= s.enqueued_at)
+			   ) AS evaluated
+		FROM reevaluation_baselines b
+		ORDER BY b.detected_at DESC
+		LIMIT $1
+	`
+
+	baselines := []models.ReevaluationBaseline{}
+	if err := r.db.Select(&baselines, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to list reevaluation baselines: %w", err)
+	}
+	return baselines, nil
+}
+
+// SampleStaleConversations picks conversations created before createdBefore
+// whose latest evaluation is from before evaluatedBefore and that weren't
+// already sampled for the fingerprint. Annotated conversations come first,
+// as they calibrate scores against human judgement; the rest are random.
+func (r *Repository) SampleStaleConversations(fingerprint string, evaluatedBefore, createdBefore time.Time, limit int) ([]models.StaleConversation, error) {
+	query := `
+		SELECT c.conversation_id, c.project_id FROM conversations c
+		WHERE c.created_at < $2
+		  AND (SELECT MAX(e.created_at) FROM evaluations e WHERE e.conversation_id = c.conversation_id) < $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM reevaluation_samples s WHERE s.fingerprint = $3 AND s.conversation_id = c.conversation_id
+		  )
+		ORDER BY EXISTS (SELECT 1 FROM annotations a WHERE a.conversation_id = c.conversation_id) DESC, random()
+		LIMIT $4
+	`
+
+	stale := []models.StaleConversation{}
+	if err := r.db.Select(&stale, query, evaluatedBefore, createdBefore, fingerprint, limit); err != nil {
+		return nil, fmt.Errorf("failed to sample stale conversations: %w", err)
+	}
+	return stale, nil
+}
+
+// RecordReevaluationSamples records conversations queued for re-evaluation
+// under a fingerprint, completing its baseline once the target is reached
+func (r *Repository) RecordReevaluationSamples(fingerprint string, conversationIDs []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO reevaluation_samples (fingerprint, conversation_id)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING
+	`, fingerprint, pq.Array(conversationIDs))
+	if err != nil {
+		return fmt.Errorf("failed to record reevaluation samples: %w", err)
+	}
+	recorded, _ := res.RowsAffected()
+
+	_, err = tx.Exec(`
+		UPDATE reevaluation_baselines
+		SET enqueued = enqueued + $2,
+			completed_at = CASE WHEN enqueued + $2 >= target THEN NOW() END
+		WHERE fingerprint = $1
+	`, fingerprint, recorded)
+	if err != nil {
+		return fmt.Errorf("failed to update reevaluation baseline: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CompleteReevaluationBaseline ends sampling for a fingerprint short of its
+// target, once no stale conversations are left
+func (r *Repository) CompleteReevaluationBaseline(fingerprint string) error {
+	query := `UPDATE reevaluation_baselines SET completed_at = NOW() WHERE fingerprint = $1 AND completed_at IS NULL`
+	if _, err := r.db.Exec(query, fingerprint); err != nil {
+		return fmt.Errorf("failed to complete reevaluation baseline: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// GetReliabilityLabelCounts returns the label counts of conversations that at
+// least two annotators annotated, bucketed by the day or week of their last
+// annotation since the given time. Only each annotator's latest annotation
+// counts. An empty annotationType includes every type.
+func (r *Repository) GetReliabilityLabelCounts(since time.Time, interval, annotationType string) ([]models.ReliabilityLabelCount, error) {
+	args := []interface{}{since, interval, annotationType}
+	query := `
+		WITH latest AS (
+			SELECT DISTINCT ON (conversation_id, annotation_type, annotator_id)
+				conversation_id, annotation_type, annotator_id, label, created_at
+			FROM annotations
+			WHERE conversation_id IS NOT NULL AND ($3 = '' OR annotation_type = $3)` + r.projectFilter("project_id", &args) + `
+			ORDER BY conversation_id, annotation_type, annotator_id, created_at DESC, id DESC
+		),
+		items AS (
+			SELECT conversation_id, annotation_type, date_trunc($2, MAX(created_at)) AS bucket
+			FROM latest
+			GROUP BY conversation_id, annotation_type
+			HAVING COUNT(*) >= 2 AND MAX(created_at) >= $1
+		)
+		SELECT i.annotation_type, i.bucket, i.conversation_id, l.label, COUNT(*) AS annotators
+		FROM items i
+		JOIN latest l ON l.conversation_id = i.conversation_id AND l.annotation_type = i.annotation_type
+		GROUP BY i.annotation_type, i.bucket, i.conversation_id, l.label
+	`
+
+	counts := []models.ReliabilityLabelCount{}
+	if err := r.db.Select(&counts, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get reliability label counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// RecordReliabilityEvents stores reliability events and returns the ones that
+// weren't recorded before
+func (r *Repository) RecordReliabilityEvents(events []models.ReliabilityEvent) ([]models.ReliabilityEvent, error) {
+	recorded := []models.ReliabilityEvent{}
+	for _, event := range events {
+		query := `
+			INSERT INTO reliability_events (annotation_type, bucket, kappa, previous_kappa, percent_agreement, items, threshold)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (annotation_type, bucket) DO NOTHING
+			RETURNING *
+		`
+
+		var stored models.ReliabilityEvent
+		err := r.db.QueryRowx(query, event.AnnotationType, event.Bucket, event.Kappa, event.PreviousKappa,
+			event.PercentAgreement, event.Items, event.Threshold).StructScan(&stored)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return recorded, fmt.Errorf("failed to record reliability event: %w", err)
+		}
+		recorded = append(recorded, stored)
+	}
+
+	return recorded, nil
+}
+
+// ListReliabilityEvents lists reliability events since a time, newest first.
+// An empty annotationType lists every type.
+func (r *Repository) ListReliabilityEvents(annotationType string, since time.Time) ([]models.ReliabilityEvent, error) {
+	events := []models.ReliabilityEvent{}
+	query := `
+		SELECT * FROM reliability_events
+		WHERE bucket >= $1 AND ($2 = '' OR annotation_type = $2)
+		ORDER BY bucket DESC, annotation_type
+	`
+	if err := r.db.Select(&events, query, since, annotationType); err != nil {
+		return nil, fmt.Errorf("failed to list reliability events: %w", err)
+	}
+
+	return events, nil
+}
@@ -6,21 +6,54 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ai-agent-eval/internal/database"
 	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // Repository provides database operations
 type Repository struct {
-	db *sqlx.DB
+	db       *database.DB
+	signer   *services.Signer
+	verdicts func() models.VerdictPolicy // Nil gives every evaluation the default policy's verdict
+	prices   services.TokenPrices        // Prices the cost of conversations as they are stored
+	project  string                      // Empty when unscoped; see ForProject
 }
 
 // New creates a new repository
-func New(db *sqlx.DB) *Repository {
+func New(db *database.DB) *Repository {
 	return &Repository{db: db}
 }
 
-// CreateConversation creates a new conversation
+// SetSigner enables signing of evaluation records as they are created
+func (r *Repository) SetSigner(signer *services.Signer) {
+	r.signer = signer
+}
+
+// SetVerdictPolicy sets where the policy deciding the verdicts of evaluations
+// as they are stored comes from
+func (r *Repository) SetVerdictPolicy(policy func() models.VerdictPolicy) {
+	r.verdicts = policy
+}
+
+// SetTokenPrices sets the prices conversations are costed at as they are
+// stored. Conversations on unpriced models have no cost.
+func (r *Repository) SetTokenPrices(prices services.TokenPrices) {
+	r.prices = prices
+}
+
+// verdictPolicy returns the current verdict policy
+func (r *Repository) verdictPolicy() models.VerdictPolicy {
+	if r.verdicts == nil {
+		return services.DefaultVerdictPolicy(0.4)
+	}
+	return r.verdicts()
+}
+
+// CreateConversation creates a new conversation. It returns nil if a
+// conversation with the same ID already exists, in any project.
 func (r *Repository) CreateConversation(conv *models.ConversationCreate) (*models.Conversation, error) {
 	turnsJSON, err := json.Marshal(conv.Turns)
 	if err != nil {
@@ -28,29 +61,42 @@ func (r *Repository) CreateConversation(conv *models.ConversationCreate) (*model
 	}
 
 	metadataJSON := []byte("{}")
+	var metadata models.ConversationMetadata
 	if conv.Metadata != nil {
 		metadataJSON, err = json.Marshal(conv.Metadata)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal metadata: %w", err)
 		}
+		metadata = *conv.Metadata
+	}
+
+	tokenCounts := services.CountTurns(conv.Turns, metadata)
+	tokenCountsJSON, err := json.Marshal(tokenCounts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal token counts: %w", err)
 	}
+	cost := services.ConversationCost(conv.Turns, metadata, tokenCounts, r.prices)
 
 	query := `
-		INSERT INTO conversations (conversation_id, agent_version, turns, metadata)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, conversation_id, agent_version, turns, metadata, created_at, updated_at
+		INSERT INTO conversations (conversation_id, agent_version, turns, metadata, intent, project_id, token_counts, cost_usd)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (conversation_id) DO NOTHING
+		RETURNING id, conversation_id, agent_version, turns, metadata, intent, project_id, token_counts, cost_usd, created_at, updated_at
 	`
 
 	var result models.Conversation
-	err = r.db.QueryRowx(query, conv.ConversationID, conv.AgentVersion, turnsJSON, metadataJSON).
+	err = r.db.QueryRowx(query, conv.ConversationID, conv.AgentVersion, turnsJSON, metadataJSON, conv.Intent, r.insertProject(), tokenCountsJSON, cost).
 		StructScan(&result)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, fmt.Errorf("failed to create conversation: %w", err)
 	}
 
 	// Create feedback if provided
 	if conv.Feedback != nil {
-		if err := r.createFeedback(conv.ConversationID, conv.Feedback); err != nil {
+		if err := r.AddFeedback(conv.ConversationID, conv.Feedback); err != nil {
 			return nil, err
 		}
 	}
@@ -58,8 +104,8 @@ func (r *Repository) CreateConversation(conv *models.ConversationCreate) (*model
 	return &result, nil
 }
 
-// createFeedback creates feedback for a conversation
-func (r *Repository) createFeedback(conversationID string, feedback *models.Feedback) error {
+// AddFeedback creates feedback for a conversation
+func (r *Repository) AddFeedback(conversationID string, feedback *models.Feedback) error {
 	opsReviewJSON := []byte("null")
 	var err error
 	if feedback.OpsReview != nil {
@@ -74,9 +120,22 @@ func (r *Repository) createFeedback(conversationID string, feedback *models.Feed
 		return fmt.Errorf("failed to marshal annotations: %w", err)
 	}
 
+	// Comments are analyzed on write so theme reports don't reprocess text
+	var sentiment string
+	var sentimentScore interface{}
+	themesJSON := []byte("[]")
+	if feedback.Comment != "" {
+		analysis := services.AnalyzeFeedback(feedback.Comment)
+		sentiment, sentimentScore = analysis.Sentiment, analysis.SentimentScore
+		themesJSON, err = json.Marshal(analysis.Themes)
+		if err != nil {
+			return fmt.Errorf("failed to marshal themes: %w", err)
+		}
+	}
+
 	query := `
-		INSERT INTO feedbacks (conversation_id, user_rating, ops_review, annotations)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO feedbacks (conversation_id, user_rating, ops_review, annotations, comment, sentiment, sentiment_score, themes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	var userRating interface{} = nil
@@ -84,7 +143,8 @@ func (r *Repository) createFeedback(conversationID string, feedback *models.Feed
 		userRating = feedback.UserRating
 	}
 
-	_, err = r.db.Exec(query, conversationID, userRating, opsReviewJSON, annotationsJSON)
+	_, err = r.db.Exec(query, conversationID, userRating, opsReviewJSON, annotationsJSON,
+		feedback.Comment, sentiment, sentimentScore, themesJSON)
 	if err != nil {
 		return fmt.Errorf("failed to create feedback: %w", err)
 	}
@@ -92,12 +152,90 @@ func (r *Repository) createFeedback(conversationID string, feedback *models.Feed
 	return nil
 }
 
+// AppendConversationTurns appends turns newer than the stored ones to an existing
+// conversation. It returns the updated conversation and the turns that were
+// appended, or a nil conversation if it does not exist.
+func (r *Repository) AppendConversationTurns(conv *models.ConversationCreate) (*models.Conversation, []models.Turn, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existing models.Conversation
+	args := []interface{}{conv.ConversationID}
+	query := `SELECT * FROM conversations WHERE conversation_id = $1` + r.projectFilter("project_id", &args) + ` FOR UPDATE`
+	if err := tx.Get(&existing, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	var storedTurns []models.Turn
+	if err := json.Unmarshal(existing.Turns, &storedTurns); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal turns: %w", err)
+	}
+
+	lastTurnID := 0
+	for _, turn := range storedTurns {
+		if turn.TurnID > lastTurnID {
+			lastTurnID = turn.TurnID
+		}
+	}
+
+	newTurns := make([]models.Turn, 0)
+	for _, turn := range conv.Turns {
+		if turn.TurnID > lastTurnID {
+			newTurns = append(newTurns, turn)
+		}
+	}
+
+	if len(newTurns) == 0 {
+		return &existing, newTurns, nil
+	}
+
+	newTurnsJSON, err := json.Marshal(newTurns)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal turns: %w", err)
+	}
+
+	// Counted and costed over every turn so both follow the stored model
+	var metadata models.ConversationMetadata
+	json.Unmarshal(existing.Metadata, &metadata)
+	allTurns := append(storedTurns, newTurns...)
+	tokenCounts := services.CountTurns(allTurns, metadata)
+	tokenCountsJSON, err := json.Marshal(tokenCounts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal token counts: %w", err)
+	}
+	cost := services.ConversationCost(allTurns, metadata, tokenCounts, r.prices)
+
+	var result models.Conversation
+	query = `
+		UPDATE conversations
+		SET turns = turns || $2::jsonb, token_counts = $3, cost_usd = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE conversation_id = $1
+		RETURNING *
+	`
+	if err := tx.QueryRowx(query, conv.ConversationID, newTurnsJSON, tokenCountsJSON, cost).StructScan(&result); err != nil {
+		return nil, nil, fmt.Errorf("failed to append turns: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &result, newTurns, nil
+}
+
 // GetConversation retrieves a conversation by ID
 func (r *Repository) GetConversation(conversationID string) (*models.Conversation, error) {
 	var conv models.Conversation
-	query := `SELECT * FROM conversations WHERE conversation_id = $1`
+	args := []interface{}{conversationID}
+	query := `SELECT * FROM conversations WHERE conversation_id = $1` + r.projectFilter("project_id", &args)
 	
-	if err := r.db.Get(&conv, query, conversationID); err != nil {
+	if err := r.db.Get(&conv, query, args...); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -107,16 +245,50 @@ func (r *Repository) GetConversation(conversationID string) (*models.Conversatio
 	return &conv, nil
 }
 
+// GetConversationAgentVersion returns the agent version of a conversation,
+// or an empty string if it doesn't exist
+func (r *Repository) GetConversationAgentVersion(conversationID string) (string, error) {
+	var agentVersion string
+	args := []interface{}{conversationID}
+	query := `SELECT agent_version FROM conversations WHERE conversation_id = $1` + r.projectFilter("project_id", &args)
+
+	if err := r.db.Get(&agentVersion, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get agent version: %w", err)
+	}
+
+	return agentVersion, nil
+}
+
+// GetConversationProject returns the project of a conversation, or an empty
+// string if it doesn't exist
+func (r *Repository) GetConversationProject(conversationID string) (string, error) {
+	var projectID string
+	args := []interface{}{conversationID}
+	query := `SELECT project_id FROM conversations WHERE conversation_id = $1` + r.projectFilter("project_id", &args)
+
+	if err := r.db.Get(&projectID, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get conversation project: %w", err)
+	}
+
+	return projectID, nil
+}
+
 // ListConversations lists conversations with pagination
 func (r *Repository) ListConversations(agentVersion string, limit, offset int) ([]models.Conversation, error) {
 	var conversations []models.Conversation
 	
-	query := `SELECT * FROM conversations`
 	args := []interface{}{}
-	argIndex := 1
+	query := `SELECT * FROM conversations WHERE 1=1` + r.projectFilter("project_id", &args)
+	argIndex := len(args) + 1
 
 	if agentVersion != "" {
-		query += fmt.Sprintf(" WHERE agent_version = $%d", argIndex)
+		query += fmt.Sprintf(" AND agent_version = $%d", argIndex)
 		args = append(args, agentVersion)
 		argIndex++
 	}
@@ -131,33 +303,229 @@ func (r *Repository) ListConversations(agentVersion string, limit, offset int) (
 	return conversations, nil
 }
 
-// CreateEvaluation creates an evaluation record
+// CreateEvaluation creates an evaluation record.
+// Issues are deduplicated before storage; the original list is kept in RawIssuesDetected.
 func (r *Repository) CreateEvaluation(eval *models.Evaluation) error {
+	if err := r.writeEvaluation(r.db, eval); err != nil {
+		return err
+	}
+
+	if eval.TaskID != "" {
+		// Timings are best effort and must not fail the evaluation
+		r.RecordPipelineStage(eval.TaskID, models.PipelineStagePersisted, eval.CreatedAt)
+	}
+
+	return nil
+}
+
+// writeEvaluation inserts an evaluation and records it as the latest for its
+// conversation, with the database or within a transaction
+func (r *Repository) writeEvaluation(db sqlx.Ext, eval *models.Evaluation) error {
+	if len(eval.RawIssuesDetected) == 0 {
+		eval.RawIssuesDetected = eval.IssuesDetected
+	}
+	deduped, err := services.DeduplicateIssuesJSON(eval.RawIssuesDetected)
+	if err != nil {
+		return err
+	}
+	eval.IssuesDetected = deduped
+	if len(eval.FailedEvaluators) == 0 {
+		eval.FailedEvaluators = json.RawMessage(`[]`)
+	}
+	if len(eval.ComponentScores) == 0 {
+		eval.ComponentScores = json.RawMessage(`{}`)
+	}
+	if len(eval.SkippedEvaluators) == 0 {
+		eval.SkippedEvaluators = json.RawMessage(`[]`)
+	}
+	if len(eval.Manifest) == 0 {
+		eval.Manifest = json.RawMessage(`{}`)
+	}
+	failed, err := services.FailedEvaluatorTypes(eval)
+	if err != nil {
+		return err
+	}
+	eval.Partial = len(failed) > 0
+	if err := services.ApplyVerdict(r.verdictPolicy(), eval); err != nil {
+		return err
+	}
+
+	// The creation time is part of the signed payload, so it is fixed here
+	// at the database's microsecond precision rather than defaulted on insert
+	eval.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	if r.signer != nil {
+		if err := r.signer.Sign(eval); err != nil {
+			return fmt.Errorf("failed to sign evaluation: %w", err)
+		}
+	}
+
 	query := `
 		INSERT INTO evaluations (
 			evaluation_id, conversation_id, overall_score, response_quality_score,
 			tool_accuracy_score, coherence_score, tool_evaluation, issues_detected,
-			improvement_suggestions, evaluator_version, evaluation_duration_ms
+			raw_issues_detected, improvement_suggestions, evaluator_version, evaluation_duration_ms,
+			task_id, trigger_source, failed_evaluators, component_scores, partial,
+			signature, signature_algorithm, created_at, skipped_evaluators, evaluation_cost, manifest, project_id,
+			verdict, verdict_rule
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		RETURNING id, created_at
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23,
+			COALESCE((SELECT project_id FROM conversations WHERE conversation_id = $2), $24), $25, $26)
+		RETURNING id, project_id, created_at
 	`
 
-	return r.db.QueryRowx(
+	if err := db.QueryRowx(
 		query,
 		eval.EvaluationID, eval.ConversationID, eval.OverallScore,
 		eval.ResponseQualityScore, eval.ToolAccuracyScore, eval.CoherenceScore,
-		eval.ToolEvaluation, eval.IssuesDetected, eval.ImprovementSuggestions,
-		eval.EvaluatorVersion, eval.EvaluationDurationMS,
-	).Scan(&eval.ID, &eval.CreatedAt)
+		eval.ToolEvaluation, eval.IssuesDetected, eval.RawIssuesDetected,
+		eval.ImprovementSuggestions, eval.EvaluatorVersion, eval.EvaluationDurationMS,
+		eval.TaskID, eval.TriggerSource, eval.FailedEvaluators, eval.ComponentScores, eval.Partial,
+		eval.Signature, eval.SignatureAlgorithm, eval.CreatedAt, eval.SkippedEvaluators, eval.EvaluationCost,
+		eval.Manifest, models.DefaultProjectID, eval.Verdict, eval.VerdictRule,
+	).Scan(&eval.ID, &eval.ProjectID, &eval.CreatedAt); err != nil {
+		return err
+	}
+
+	return r.upsertConversationSummary(db, eval)
+}
+
+// CompleteEvaluation merges the result of re-running the failed evaluator
+// types of a partial evaluation into it. The evaluation keeps its ID and
+// creation time and is signed again. It returns nil if the evaluation doesn't
+// exist.
+func (r *Repository) CompleteEvaluation(evaluationID string, retried []string, result *services.EvaluationResult) (*models.Evaluation, error) {
+	return r.completeEvaluation(r.db, evaluationID, retried, result)
+}
+
+// completeEvaluation completes an evaluation with the database or within a
+// transaction
+func (r *Repository) completeEvaluation(db sqlx.Ext, evaluationID string, retried []string, result *services.EvaluationResult) (*models.Evaluation, error) {
+	eval, err := r.GetEvaluation(evaluationID)
+	if err != nil || eval == nil {
+		return nil, err
+	}
+
+	if err := services.CompleteEvaluation(eval, retried, result); err != nil {
+		return nil, err
+	}
+	deduped, err := services.DeduplicateIssuesJSON(eval.RawIssuesDetected)
+	if err != nil {
+		return nil, err
+	}
+	eval.IssuesDetected = deduped
+	if err := services.ApplyVerdict(r.verdictPolicy(), eval); err != nil {
+		return nil, err
+	}
+
+	if r.signer != nil {
+		if err := r.signer.Sign(eval); err != nil {
+			return nil, fmt.Errorf("failed to sign evaluation: %w", err)
+		}
+	}
+
+	_, err = db.Exec(`
+		UPDATE evaluations SET
+			overall_score = $2, response_quality_score = $3, tool_accuracy_score = $4,
+			coherence_score = $5, tool_evaluation = $6, issues_detected = $7,
+			raw_issues_detected = $8, improvement_suggestions = $9, failed_evaluators = $10,
+			component_scores = $11, partial = $12, signature = $13, verdict = $14, verdict_rule = $15
+		WHERE evaluation_id = $1
+	`, eval.EvaluationID, eval.OverallScore, eval.ResponseQualityScore, eval.ToolAccuracyScore,
+		eval.CoherenceScore, eval.ToolEvaluation, eval.IssuesDetected,
+		eval.RawIssuesDetected, eval.ImprovementSuggestions, eval.FailedEvaluators,
+		eval.ComponentScores, eval.Partial, eval.Signature, eval.Verdict, eval.VerdictRule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete evaluation: %w", err)
+	}
+
+	// Only takes effect while this is still the conversation's latest evaluation
+	if err := r.upsertConversationSummary(db, eval); err != nil {
+		return nil, err
+	}
+
+	return eval, nil
+}
+
+// upsertConversationSummary records an evaluation as the latest for its conversation
+func (r *Repository) upsertConversationSummary(db sqlx.Execer, eval *models.Evaluation) error {
+	var issues []models.IssueDetected
+	if len(eval.IssuesDetected) > 0 {
+		if err := json.Unmarshal(eval.IssuesDetected, &issues); err != nil {
+			return fmt.Errorf("failed to parse issues: %w", err)
+		}
+	}
+
+	counts := services.SeverityCounts(issues)
+	severityCounts, err := json.Marshal(counts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal severity counts: %w", err)
+	}
+
+	query := `
+		INSERT INTO conversation_summaries (
+			conversation_id, latest_evaluation_id, latest_overall_score,
+			open_issue_count, critical_issue_count, severity_counts, latest_verdict, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (conversation_id) DO UPDATE SET
+			latest_evaluation_id = EXCLUDED.latest_evaluation_id,
+			latest_overall_score = EXCLUDED.latest_overall_score,
+			open_issue_count = EXCLUDED.open_issue_count,
+			critical_issue_count = EXCLUDED.critical_issue_count,
+			severity_counts = EXCLUDED.severity_counts,
+			latest_verdict = EXCLUDED.latest_verdict,
+			updated_at = EXCLUDED.updated_at
+		WHERE conversation_summaries.updated_at <= EXCLUDED.updated_at
+	`
+
+	if _, err := db.Exec(query, eval.ConversationID, eval.EvaluationID, eval.OverallScore,
+		len(issues), counts["critical"], severityCounts, eval.Verdict, eval.CreatedAt); err != nil {
+		return fmt.Errorf("failed to update conversation summary: %w", err)
+	}
+
+	return nil
+}
+
+// GetConversationSummaries returns evaluation summaries keyed by conversation ID
+func (r *Repository) GetConversationSummaries(conversationIDs []string) (map[string]*models.EvaluationSummary, error) {
+	summaries := make(map[string]*models.EvaluationSummary)
+	if len(conversationIDs) == 0 {
+		return summaries, nil
+	}
+
+	var rows []struct {
+		ConversationID string `db:"conversation_id"`
+		models.EvaluationSummary
+	}
+
+	query := `
+		SELECT s.conversation_id, s.latest_evaluation_id, s.latest_overall_score,
+			s.open_issue_count, s.critical_issue_count, s.severity_counts, s.latest_verdict, h.health_score, s.updated_at
+		FROM conversation_summaries s
+		LEFT JOIN conversation_health h ON h.conversation_id = s.conversation_id
+		WHERE s.conversation_id = ANY($1)
+	`
+	args := []interface{}{pq.Array(conversationIDs)}
+	query += r.conversationFilter("s.conversation_id", &args)
+
+	if err := r.db.Select(&rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get conversation summaries: %w", err)
+	}
+
+	for i := range rows {
+		summaries[rows[i].ConversationID] = &rows[i].EvaluationSummary
+	}
+
+	return summaries, nil
 }
 
 // GetEvaluation retrieves an evaluation by ID
 func (r *Repository) GetEvaluation(evaluationID string) (*models.Evaluation, error) {
 	var eval models.Evaluation
-	query := `SELECT * FROM evaluations WHERE evaluation_id = $1`
+	args := []interface{}{evaluationID}
+	query := `SELECT * FROM evaluations WHERE evaluation_id = $1` + r.projectFilter("project_id", &args)
 	
-	if err := r.db.Get(&eval, query, evaluationID); err != nil {
+	if err := r.db.Get(&eval, query, args...); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -168,12 +536,12 @@ func (r *Repository) GetEvaluation(evaluationID string) (*models.Evaluation, err
 }
 
 // ListEvaluations lists evaluations with filtering
-func (r *Repository) ListEvaluations(conversationID string, minScore, maxScore *float64, limit, offset int) ([]models.Evaluation, error) {
+func (r *Repository) ListEvaluations(conversationID, triggerSource, verdict string, minScore, maxScore *float64, limit, offset int) ([]models.Evaluation, error) {
 	var evaluations []models.Evaluation
 	
-	query := `SELECT * FROM evaluations WHERE 1=1`
 	args := []interface{}{}
-	argIndex := 1
+	query := `SELECT * FROM evaluations WHERE 1=1` + r.projectFilter("project_id", &args)
+	argIndex := len(args) + 1
 
 	if conversationID != "" {
 		query += fmt.Sprintf(" AND conversation_id = $%d", argIndex)
@@ -181,6 +549,18 @@ func (r *Repository) ListEvaluations(conversationID string, minScore, maxScore *
 		argIndex++
 	}
 
+	if triggerSource != "" {
+		query += fmt.Sprintf(" AND trigger_source = $%d", argIndex)
+		args = append(args, triggerSource)
+		argIndex++
+	}
+
+	if verdict != "" {
+		query += fmt.Sprintf(" AND verdict = $%d", argIndex)
+		args = append(args, verdict)
+		argIndex++
+	}
+
 	if minScore != nil {
 		query += fmt.Sprintf(" AND overall_score >= $%d", argIndex)
 		args = append(args, *minScore)
@@ -203,23 +583,64 @@ func (r *Repository) ListEvaluations(conversationID string, minScore, maxScore *
 	return evaluations, nil
 }
 
-// CreateAnnotation creates an annotation
+// CreateAnnotation creates an annotation and completes the annotator's task
+// for the conversation, if they were assigned one. It returns nil if the
+// conversation belongs to another project.
 func (r *Repository) CreateAnnotation(ann *models.AnnotationCreate) (*models.Annotation, error) {
+	labelsJSON, err := json.Marshal(ann.Labels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal labels: %w", err)
+	}
+	var suggestedJSON json.RawMessage
+	if ann.SuggestedLabels != nil {
+		if suggestedJSON, err = json.Marshal(ann.SuggestedLabels); err != nil {
+			return nil, fmt.Errorf("failed to marshal suggested labels: %w", err)
+		}
+	}
+
+	// Annotating another project's conversation, and completing its tasks,
+	// is refused as if the conversation didn't exist
+	if err := r.checkConversationProject(r.db.Get, ann.ConversationID); err != nil {
+		if err == errConversationNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check conversation project: %w", err)
+	}
+
+	// Scoped repositories store their own project; unscoped ones the
+	// conversation's
+	var project interface{}
+	if r.project != "" {
+		project = r.insertProject()
+	}
+
 	query := `
-		INSERT INTO annotations (
-			conversation_id, annotator_id, annotation_type, label,
-			score, confidence, notes, time_spent_seconds
+		WITH created AS (
+			INSERT INTO annotations (
+				conversation_id, annotator_id, annotation_type, label, labels,
+				score, confidence, notes, time_spent_seconds, project_id,
+				assisted, suggested_labels
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9,
+				COALESCE($10::varchar, (SELECT project_id FROM conversations WHERE conversation_id = $1), $13),
+				$11, $12)
+			RETURNING id, conversation_id, annotator_id, annotation_type, label, labels,
+					  score, confidence, notes, time_spent_seconds, assisted, suggested_labels,
+					  project_id, created_at
+		), completed AS (
+			UPDATE annotation_tasks SET status = 'completed', updated_at = CURRENT_TIMESTAMP
+			WHERE conversation_id = $1 AND annotator_id = $2 AND annotation_type = $3
+			  AND status <> 'completed'
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, conversation_id, annotator_id, annotation_type, label,
-				  score, confidence, notes, time_spent_seconds, created_at
+		SELECT * FROM created
 	`
 
 	var result models.Annotation
-	err := r.db.QueryRowx(
+	err = r.db.QueryRowx(
 		query,
-		ann.ConversationID, ann.AnnotatorID, ann.AnnotationType, ann.Label,
-		ann.Score, ann.Confidence, ann.Notes, ann.TimeSpentSeconds,
+		ann.ConversationID, ann.AnnotatorID, ann.AnnotationType, ann.Label, labelsJSON,
+		ann.Score, ann.Confidence, ann.Notes, ann.TimeSpentSeconds, project,
+		ann.Assisted, nullJSON(suggestedJSON), models.DefaultProjectID,
 	).StructScan(&result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create annotation: %w", err)
@@ -232,12 +653,12 @@ func (r *Repository) CreateAnnotation(ann *models.AnnotationCreate) (*models.Ann
 func (r *Repository) GetAnnotationsForConversation(conversationID, annotationType string) ([]models.Annotation, error) {
 	var annotations []models.Annotation
 	
-	query := `SELECT * FROM annotations WHERE conversation_id = $1`
 	args := []interface{}{conversationID}
+	query := `SELECT * FROM annotations WHERE conversation_id = $1` + r.projectFilter("project_id", &args)
 
 	if annotationType != "" {
-		query += ` AND annotation_type = $2`
 		args = append(args, annotationType)
+		query += fmt.Sprintf(` AND annotation_type = $%d`, len(args))
 	}
 
 	query += ` ORDER BY created_at DESC`
@@ -249,53 +670,64 @@ func (r *Repository) GetAnnotationsForConversation(conversationID, annotationTyp
 	return annotations, nil
 }
 
-// GetSystemStats returns system statistics
-func (r *Repository) GetSystemStats() (*models.SystemStats, error) {
-	stats := &models.SystemStats{}
+// GetSystemStats returns system statistics over the data that existed at asOf
+func (r *Repository) GetSystemStats(asOf time.Time) (*models.SystemStats, error) {
+	stats := &models.SystemStats{AsOf: asOf}
+
+	// get runs a statistic query restricted to the repository's project
+	get := func(dest interface{}, query string, args ...interface{}) {
+		query += r.projectFilter("project_id", &args)
+		r.db.Get(dest, query, args...)
+	}
 
 	// Total conversations
-	r.db.Get(&stats.TotalConversations, `SELECT COUNT(*) FROM conversations`)
+	get(&stats.TotalConversations, `SELECT COUNT(*) FROM conversations WHERE created_at <= $1`, asOf)
 
 	// Total evaluations
-	r.db.Get(&stats.TotalEvaluations, `SELECT COUNT(*) FROM evaluations`)
+	get(&stats.TotalEvaluations, `SELECT COUNT(*) FROM evaluations WHERE created_at <= $1`, asOf)
 
 	// Total annotations
-	r.db.Get(&stats.TotalAnnotations, `SELECT COUNT(*) FROM annotations`)
+	get(&stats.TotalAnnotations, `SELECT COUNT(*) FROM annotations WHERE created_at <= $1`, asOf)
 
 	// Average quality score
 	var avgScore sql.NullFloat64
-	r.db.Get(&avgScore, `SELECT AVG(overall_score) FROM evaluations`)
+	get(&avgScore, `SELECT AVG(overall_score) FROM evaluations WHERE created_at <= $1`, asOf)
 	if avgScore.Valid {
 		stats.AverageQualityScore = &avgScore.Float64
 	}
 
 	// Average user rating
 	var avgRating sql.NullFloat64
-	r.db.Get(&avgRating, `SELECT AVG(user_rating) FROM feedbacks WHERE user_rating IS NOT NULL`)
+	ratingArgs := []interface{}{asOf}
+	r.db.Get(&avgRating, `SELECT AVG(user_rating) FROM feedbacks WHERE user_rating IS NOT NULL AND created_at <= $1`+
+		r.conversationFilter("conversation_id", &ratingArgs), ratingArgs...)
 	if avgRating.Valid {
 		stats.AverageUserRating = &avgRating.Float64
 	}
 
 	// Open issues (evaluations with issues)
-	r.db.Get(&stats.OpenIssuesCount, `SELECT COUNT(*) FROM evaluations WHERE jsonb_array_length(issues_detected) > 0`)
+	get(&stats.OpenIssuesCount, `SELECT COUNT(*) FROM evaluations WHERE jsonb_array_length(issues_detected) > 0 AND created_at <= $1`, asOf)
 
-	// Pending suggestions
-	r.db.Get(&stats.PendingSuggestionsCount, `SELECT COUNT(*) FROM improvement_suggestions WHERE status = 'pending'`)
+	// Pending suggestions, including ones implemented after asOf
+	get(&stats.PendingSuggestionsCount, `
+		SELECT COUNT(*) FROM improvement_suggestions
+		WHERE created_at <= $1 AND (status = 'pending' OR (status = 'implemented' AND implemented_at > $1))
+	`, asOf)
 
-	// Evaluations in last 24h
-	cutoff := time.Now().Add(-24 * time.Hour)
-	r.db.Get(&stats.EvaluationsLast24H, `SELECT COUNT(*) FROM evaluations WHERE created_at >= $1`, cutoff)
+	// Evaluations in the 24h before asOf
+	cutoff := asOf.Add(-24 * time.Hour)
+	get(&stats.EvaluationsLast24H, `SELECT COUNT(*) FROM evaluations WHERE created_at >= $1 AND created_at <= $2`, cutoff, asOf)
 
 	return stats, nil
 }
 
-// GetFailurePatterns retrieves failure patterns
-func (r *Repository) GetFailurePatterns(resolved *bool, severity string, limit int) ([]models.FailurePattern, error) {
+// GetFailurePatterns retrieves failure patterns. An empty owner doesn't filter by owner.
+func (r *Repository) GetFailurePatterns(resolved *bool, severity, owner string, limit int) ([]models.FailurePattern, error) {
 	var patterns []models.FailurePattern
 	
-	query := `SELECT * FROM failure_patterns WHERE 1=1`
 	args := []interface{}{}
-	argIndex := 1
+	query := `SELECT * FROM failure_patterns WHERE 1=1` + r.patternFilter("example_conversations", &args)
+	argIndex := len(args) + 1
 
 	if resolved != nil {
 		query += fmt.Sprintf(" AND resolved = $%d", argIndex)
@@ -309,6 +741,12 @@ func (r *Repository) GetFailurePatterns(resolved *bool, severity string, limit i
 		argIndex++
 	}
 
+	if owner != "" {
+		query += fmt.Sprintf(" AND owner = $%d", argIndex)
+		args = append(args, owner)
+		argIndex++
+	}
+
 	query += fmt.Sprintf(" ORDER BY occurrence_count DESC LIMIT $%d", argIndex)
 	args = append(args, limit)
 
@@ -319,16 +757,33 @@ func (r *Repository) GetFailurePatterns(resolved *bool, severity string, limit i
 	return patterns, nil
 }
 
+// GetFailurePattern retrieves a failure pattern by ID
+func (r *Repository) GetFailurePattern(patternID string) (*models.FailurePattern, error) {
+	var pattern models.FailurePattern
+	args := []interface{}{patternID}
+	query := `SELECT * FROM failure_patterns WHERE pattern_id = $1` + r.patternFilter("example_conversations", &args)
+
+	if err := r.db.Get(&pattern, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get failure pattern: %w", err)
+	}
+
+	return &pattern, nil
+}
+
 // GetPendingSuggestions retrieves pending suggestions
 func (r *Repository) GetPendingSuggestions(minConfidence float64, suggestionType string) ([]models.StoredSuggestion, error) {
 	var suggestions []models.StoredSuggestion
 	
-	query := `SELECT * FROM improvement_suggestions WHERE status = 'pending' AND confidence >= $1`
 	args := []interface{}{minConfidence}
+	query := `SELECT * FROM improvement_suggestions WHERE status = 'pending' AND confidence >= $1` +
+		r.projectFilter("project_id", &args)
 
 	if suggestionType != "" {
-		query += ` AND suggestion_type = $2`
 		args = append(args, suggestionType)
+		query += fmt.Sprintf(` AND suggestion_type = $%d`, len(args))
 	}
 
 	query += ` ORDER BY confidence DESC`
@@ -347,7 +802,9 @@ func (r *Repository) MarkSuggestionImplemented(suggestionID string, beforeMetric
 		SET status = 'implemented', implemented_at = $1, before_metrics = $2, updated_at = $1
 		WHERE suggestion_id = $3
 	`
-	_, err := r.db.Exec(query, time.Now(), beforeMetrics, suggestionID)
+	args := []interface{}{time.Now(), beforeMetrics, suggestionID}
+	query += r.projectFilter("project_id", &args)
+	_, err := r.db.Exec(query, args...)
 	return err
 }
 
@@ -372,12 +829,28 @@ func (r *Repository) GetEvaluatorCalibration(evaluatorType string) ([]models.Eva
 	return calibrations, nil
 }
 
+// ListConversationEvaluations lists every evaluation of a conversation, oldest first
+func (r *Repository) ListConversationEvaluations(conversationID string) ([]models.Evaluation, error) {
+	evaluations := []models.Evaluation{}
+	args := []interface{}{conversationID}
+	query := `SELECT * FROM evaluations WHERE conversation_id = $1` + r.projectFilter("project_id", &args) +
+		` ORDER BY created_at, id`
+
+	if err := r.db.Select(&evaluations, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list conversation evaluations: %w", err)
+	}
+
+	return evaluations, nil
+}
+
 // GetLatestEvaluationForConversation gets the latest evaluation for a conversation
 func (r *Repository) GetLatestEvaluationForConversation(conversationID string) (*models.Evaluation, error) {
 	var eval models.Evaluation
-	query := `SELECT * FROM evaluations WHERE conversation_id = $1 ORDER BY created_at DESC LIMIT 1`
+	args := []interface{}{conversationID}
+	query := `SELECT * FROM evaluations WHERE conversation_id = $1` + r.projectFilter("project_id", &args) +
+		` ORDER BY created_at DESC LIMIT 1`
 	
-	if err := r.db.Get(&eval, query, conversationID); err != nil {
+	if err := r.db.Get(&eval, query, args...); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -386,3 +859,27 @@ func (r *Repository) GetLatestEvaluationForConversation(conversationID string) (
 
 	return &eval, nil
 }
+
+// ListUnevaluatedConversationIDs lists conversations created before the cutoff
+// that have no evaluation, oldest first
+func (r *Repository) ListUnevaluatedConversationIDs(createdBefore time.Time, limit int) ([]string, error) {
+	var ids []string
+	query := `
+		SELECT c.conversation_id FROM conversations c
+		WHERE c.created_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM evaluations e WHERE e.conversation_id = c.conversation_id)
+	`
+	args := []interface{}{createdBefore}
+	query += r.projectFilter("c.project_id", &args) + ` ORDER BY c.created_at`
+
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	if err := r.db.Select(&ids, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list unevaluated conversations: %w", err)
+	}
+
+	return ids, nil
+}
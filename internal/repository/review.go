@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/jmoiron/sqlx"
+)
+
+// runBulk runs fn for every item in one transaction. Each item runs in its own
+// savepoint so a failed item doesn't affect the others; with atomic set, any
+// failure rolls back the whole batch.
+func (r *Repository) runBulk(ids []string, atomic bool, fn func(tx *sqlx.Tx, id string) error) (*models.BulkActionResponse, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	resp := &models.BulkActionResponse{Results: make([]models.BulkItemResult, 0, len(ids))}
+	for _, id := range ids {
+		if _, err := tx.Exec(`SAVEPOINT bulk_item`); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		result := models.BulkItemResult{ID: id, Success: true}
+		if err := fn(tx, id); err != nil {
+			if _, rbErr := tx.Exec(`ROLLBACK TO SAVEPOINT bulk_item`); rbErr != nil {
+				return nil, fmt.Errorf("failed to roll back item: %w", rbErr)
+			}
+			result.Success = false
+			result.Error = err.Error()
+			resp.Failed++
+		} else {
+			if _, err := tx.Exec(`RELEASE SAVEPOINT bulk_item`); err != nil {
+				return nil, fmt.Errorf("failed to release savepoint: %w", err)
+			}
+			resp.Succeeded++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	if atomic && resp.Failed > 0 {
+		for i := range resp.Results {
+			if resp.Results[i].Success {
+				resp.Results[i].Success = false
+				resp.Results[i].Error = "rolled back because other items failed"
+			}
+		}
+		resp.Failed += resp.Succeeded
+		resp.Succeeded = 0
+		resp.RolledBack = true
+		return resp, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk action: %w", err)
+	}
+
+	return resp, nil
+}
+
+// BulkApproveRouting records approval of each conversation's current routing decision
+func (r *Repository) BulkApproveRouting(req *models.BulkRoutingApproval) (*models.BulkActionResponse, error) {
+	return r.runBulk(req.ConversationIDs, req.Atomic, func(tx *sqlx.Tx, conversationID string) error {
+		var evaluationID string
+		args := []interface{}{conversationID}
+		err := tx.Get(&evaluationID, `
+			SELECT evaluation_id FROM evaluations
+			WHERE conversation_id = $1`+r.projectFilter("project_id", &args)+`
+			ORDER BY created_at DESC LIMIT 1
+		`, args...)
+		if err == sql.ErrNoRows {
+			return errors.New("no evaluation found for conversation")
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO routing_approvals (conversation_id, evaluation_id, reviewer_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (conversation_id, evaluation_id) DO NOTHING
+		`, conversationID, evaluationID, req.ReviewerID)
+		return err
+	})
+}
+
+// BulkDismissIssues dismisses low-severity issues and updates the open issue
+// count of conversations whose latest evaluation they belong to
+func (r *Repository) BulkDismissIssues(req *models.BulkIssueDismissal) (*models.BulkActionResponse, error) {
+	ids := make([]string, len(req.Issues))
+	refs := make(map[string]models.IssueRef, len(req.Issues))
+	for i, ref := range req.Issues {
+		ids[i] = fmt.Sprintf("%s#%d", ref.EvaluationID, ref.IssueIndex)
+		refs[ids[i]] = ref
+	}
+
+	return r.runBulk(ids, req.Atomic, func(tx *sqlx.Tx, id string) error {
+		ref := refs[id]
+
+		var issuesJSON []byte
+		args := []interface{}{ref.EvaluationID}
+		err := tx.Get(&issuesJSON, `SELECT issues_detected FROM evaluations WHERE evaluation_id = $1`+
+			r.projectFilter("project_id", &args), args...)
+		if err == sql.ErrNoRows {
+			return errors.New("evaluation not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		var issues []models.IssueDetected
+		if err := json.Unmarshal(issuesJSON, &issues); err != nil {
+			return fmt.Errorf("failed to unmarshal issues: %w", err)
+		}
+		if ref.IssueIndex >= len(issues) {
+			return fmt.Errorf("evaluation has %d issues", len(issues))
+		}
+		if severity := issues[ref.IssueIndex].Severity; severity != "low" {
+			return fmt.Errorf("only low severity issues can be bulk dismissed, issue is %s", severity)
+		}
+
+		res, err := tx.Exec(`
+			INSERT INTO issue_dismissals (evaluation_id, issue_index, reviewer_id, reason)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (evaluation_id, issue_index) DO NOTHING
+		`, ref.EvaluationID, ref.IssueIndex, req.ReviewerID, req.Reason)
+		if err != nil {
+			return err
+		}
+		if inserted, _ := res.RowsAffected(); inserted == 0 {
+			return nil // Already dismissed
+		}
+
+		_, err = tx.Exec(`
+			UPDATE conversation_summaries
+			SET open_issue_count = GREATEST(open_issue_count - 1, 0)
+			WHERE latest_evaluation_id = $1
+		`, ref.EvaluationID)
+		return err
+	})
+}
+
+// BulkAssignAnnotationTasks assigns an annotation task per conversation to an
+// annotator, inheriting each conversation's routing priority and deadline.
+// Completed tasks are not reassigned. When the annotation type needs
+// a single annotator, the task is moved from whoever it was assigned to;
+// otherwise the annotator is added alongside the other assignees.
+func (r *Repository) BulkAssignAnnotationTasks(req *models.BulkAnnotationAssignment, policy models.RoutingPolicy) (*models.BulkActionResponse, error) {
+	minAnnotators := services.RequiredAnnotators(policy, req.AnnotationType)
+	return r.runBulk(req.ConversationIDs, req.Atomic, func(tx *sqlx.Tx, conversationID string) error {
+		if err := r.checkConversationProject(tx.Get, conversationID); err != nil {
+			return err
+		}
+		priority, dueAt, err := taskRouting(tx.Get, conversationID, policy, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if minAnnotators <= 1 {
+			var completed bool
+			err = tx.Get(&completed, `
+				SELECT EXISTS (
+					SELECT 1 FROM annotation_tasks
+					WHERE conversation_id = $1 AND annotation_type = $2 AND status = 'completed'
+				)
+			`, conversationID, req.AnnotationType)
+			if err != nil {
+				return err
+			}
+			if completed {
+				return errors.New("annotation task is already completed")
+			}
+
+			_, err = tx.Exec(`
+				DELETE FROM annotation_tasks
+				WHERE conversation_id = $1 AND annotation_type = $2 AND annotator_id <> $3
+			`, conversationID, req.AnnotationType, req.AnnotatorID)
+			if err != nil {
+				return err
+			}
+		}
+
+		res, err := tx.Exec(`
+			INSERT INTO annotation_tasks (conversation_id, annotation_type, annotator_id, assigned_by, priority, due_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (conversation_id, annotation_type, annotator_id) DO UPDATE
+			SET assigned_by = EXCLUDED.assigned_by, status = 'assigned', priority = EXCLUDED.priority,
+				due_at = EXCLUDED.due_at, updated_at = CURRENT_TIMESTAMP
+			WHERE annotation_tasks.status <> 'completed'
+		`, conversationID, req.AnnotationType, req.AnnotatorID, req.AssignedBy, priority, dueAt)
+		if err != nil {
+			return err
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return errors.New("annotation task is already completed")
+		}
+		return nil
+	})
+}
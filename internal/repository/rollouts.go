@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+)
+
+// CreateEvaluatorRollout starts an A/B test of a candidate evaluator version,
+// decided on a correlation difference of minMargin unless the rollout sets
+// its own
+func (r *Repository) CreateEvaluatorRollout(rollout *models.EvaluatorRolloutCreate, minMargin float64) (*models.EvaluatorRollout, error) {
+	minSamples := rollout.MinSamples
+	if minSamples <= 0 {
+		minSamples = 30
+	}
+	if rollout.MinMargin != nil {
+		minMargin = *rollout.MinMargin
+	}
+
+	query := `
+		INSERT INTO evaluator_rollouts (evaluator_type, incumbent_version, candidate_version, traffic_percent, min_samples, min_margin)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *
+	`
+
+	var result models.EvaluatorRollout
+	err := r.db.QueryRowx(
+		query,
+		rollout.EvaluatorType, rollout.IncumbentVersion, rollout.CandidateVersion,
+		rollout.TrafficPercent, minSamples, minMargin,
+	).StructScan(&result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rollout: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListEvaluatorRollouts lists rollouts, optionally filtered by status
+func (r *Repository) ListEvaluatorRollouts(status string) ([]models.EvaluatorRollout, error) {
+	var rollouts []models.EvaluatorRollout
+
+	query := `SELECT * FROM evaluator_rollouts`
+	args := []interface{}{}
+
+	if status != "" {
+		query += ` WHERE status = $1`
+		args = append(args, status)
+	}
+
+	query += ` ORDER BY created_at DESC`
+
+	if err := r.db.Select(&rollouts, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list rollouts: %w", err)
+	}
+
+	return rollouts, nil
+}
+
+// GetPromotedEvaluatorVersions returns the most recently promoted version per evaluator type
+func (r *Repository) GetPromotedEvaluatorVersions() (map[string]string, error) {
+	var rows []struct {
+		EvaluatorType    string `db:"evaluator_type"`
+		CandidateVersion string `db:"candidate_version"`
+	}
+	query := `
+		SELECT DISTINCT ON (evaluator_type) evaluator_type, candidate_version
+		FROM evaluator_rollouts
+		WHERE status = $1
+		ORDER BY evaluator_type, decided_at DESC
+	`
+
+	if err := r.db.Select(&rows, query, models.RolloutPromoted); err != nil {
+		return nil, fmt.Errorf("failed to get promoted versions: %w", err)
+	}
+
+	versions := make(map[string]string, len(rows))
+	for _, row := range rows {
+		versions[row.EvaluatorType] = row.CandidateVersion
+	}
+
+	return versions, nil
+}
+
+// GetLatestCalibration returns the latest calibration of an evaluator version
+func (r *Repository) GetLatestCalibration(evaluatorType, evaluatorVersion string) (*models.EvaluatorCalibration, error) {
+	var calibration models.EvaluatorCalibration
+	query := `
+		SELECT * FROM evaluator_calibration
+		WHERE evaluator_type = $1 AND evaluator_version = $2
+		ORDER BY created_at DESC LIMIT 1
+	`
+
+	if err := r.db.Get(&calibration, query, evaluatorType, evaluatorVersion); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get calibration: %w", err)
+	}
+
+	return &calibration, nil
+}
+
+// DecideEvaluatorRollouts promotes or rolls back every active rollout whose
+// candidate and incumbent calibrations are conclusive. It returns the rollouts
+// that changed status, and tells replicas to reload their rollouts if any did.
+func (r *Repository) DecideEvaluatorRollouts() ([]models.EvaluatorRollout, error) {
+	rollouts, err := r.ListEvaluatorRollouts(models.RolloutActive)
+	if err != nil {
+		return nil, err
+	}
+
+	decided := make([]models.EvaluatorRollout, 0)
+	for i := range rollouts {
+		rollout := &rollouts[i]
+
+		incumbent, err := r.GetLatestCalibration(rollout.EvaluatorType, rollout.IncumbentVersion)
+		if err != nil {
+			return nil, err
+		}
+		candidate, err := r.GetLatestCalibration(rollout.EvaluatorType, rollout.CandidateVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		decision, reason := services.DecideRollout(rollout, incumbent, candidate)
+		status := ""
+		switch decision {
+		case services.RolloutDecisionPromote:
+			status = models.RolloutPromoted
+		case services.RolloutDecisionRollback:
+			status = models.RolloutRolledBack
+		default:
+			continue
+		}
+
+		query := `
+			UPDATE evaluator_rollouts
+			SET status = $1, decision_reason = $2, decided_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $3
+			RETURNING *
+		`
+		var updated models.EvaluatorRollout
+		if err := r.db.QueryRowx(query, status, reason, rollout.ID).StructScan(&updated); err != nil {
+			return nil, fmt.Errorf("failed to update rollout: %w", err)
+		}
+		decided = append(decided, updated)
+	}
+
+	// Replicas route new tasks by the active rollouts
+	if len(decided) > 0 {
+		if err := r.PublishInvalidation(InvalidationScopeRollouts); err != nil {
+			log.Printf("Failed to publish rollout invalidation: %v", err)
+		}
+	}
+
+	return decided, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/database"
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// evaluationSearchText joins the texts of an evaluation's search document,
+// for highlighting matches
+const evaluationSearchText = `(
+	SELECT string_agg(t.value, ' ... ')
+	FROM jsonb_array_elements_text(
+		jsonb_path_query_array(COALESCE(e.issues_detected, '[]'::jsonb), '$[*].description') ||
+		jsonb_path_query_array(COALESCE(e.improvement_suggestions, '[]'::jsonb), '$[*].suggestion') ||
+		jsonb_path_query_array(COALESCE(e.improvement_suggestions, '[]'::jsonb), '$[*].rationale')
+	) AS t(value)
+)`
+
+// SearchEvaluations finds evaluations whose issue descriptions or
+// improvement suggestions match a web search style query (words, "quoted
+// phrases", or, -excluded), best match first. An empty verdict includes
+// every verdict.
+func (r *Repository) SearchEvaluations(search, verdict string, limit, offset int) ([]models.EvaluationSearchResult, error) {
+	args := []interface{}{search, verdict, limit, offset}
+	query := `
+		SELECT e.evaluation_id, e.conversation_id, e.overall_score, e.verdict, e.created_at, m.rank,
+			   ts_headline('english', COALESCE(` + evaluationSearchText + `, ''), m.query,
+				   'StartSel=<<, StopSel=>>, MaxFragments=3, MaxWords=20, MinWords=5') AS headline
+		FROM (
+			SELECT e.id, ts_rank(` + database.EvaluationSearchVector + `, q) AS rank, q AS query
+			FROM evaluations e, websearch_to_tsquery('english', $1) AS q
+			WHERE (` + database.EvaluationSearchVector + `) @@ q
+			  AND ($2 = '' OR e.verdict = $2)` + r.projectFilter("e.project_id", &args) + `
+			ORDER BY rank DESC, e.created_at DESC
+			LIMIT $3 OFFSET $4
+		) m
+		JOIN evaluations e ON e.id = m.id
+		ORDER BY m.rank DESC, e.created_at DESC
+	`
+
+	results := []models.EvaluationSearchResult{}
+	if err := r.db.Select(&results, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to search evaluations: %w", err)
+	}
+
+	return results, nil
+}
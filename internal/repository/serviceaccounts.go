@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// CreateServiceAccount stores a service account with the hash of its token.
+// It returns nil if an account with the name already exists, even a revoked one.
+func (r *Repository) CreateServiceAccount(name string, scopes []string, tokenHash string) (*models.ServiceAccount, error) {
+	scopesJSON, err := json.Marshal(scopes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal scopes: %w", err)
+	}
+
+	var account models.ServiceAccount
+	err = r.db.QueryRowx(`
+		INSERT INTO service_accounts (name, token_hash, scopes)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING *
+	`, name, tokenHash, scopesJSON).StructScan(&account)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	return &account, nil
+}
+
+// ListServiceAccounts lists service accounts, including revoked ones
+func (r *Repository) ListServiceAccounts() ([]models.ServiceAccount, error) {
+	accounts := []models.ServiceAccount{}
+	if err := r.db.Select(&accounts, `SELECT * FROM service_accounts ORDER BY name`); err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// RevokeServiceAccount revokes a service account's token. It returns false if
+// no active account has the name.
+func (r *Repository) RevokeServiceAccount(name string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE service_accounts SET revoked_at = CURRENT_TIMESTAMP
+		WHERE name = $1 AND revoked_at IS NULL
+	`, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke service account: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke service account: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetServiceAccountByToken gets the active service account with a token hash
+// and records its use. It returns nil if there is none.
+func (r *Repository) GetServiceAccountByToken(tokenHash string) (*models.ServiceAccount, error) {
+	var account models.ServiceAccount
+	err := r.db.QueryRowx(`
+		UPDATE service_accounts SET last_used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1 AND revoked_at IS NULL
+		RETURNING *
+	`, tokenHash).StructScan(&account)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+
+	return &account, nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/lib/pq"
+)
+
+// GetSnapshotData loads the conversations selected by a snapshot filter with
+// their evaluations and annotations, oldest first. It returns
+// ErrTooManyConversations when more than maxConversations match.
+func (r *Repository) GetSnapshotData(filter *models.SnapshotFilter, maxConversations int) ([]models.Conversation, []models.Evaluation, []models.Annotation, error) {
+	conversations := []models.Conversation{}
+	query := `
+		SELECT * FROM conversations
+		WHERE (cardinality($1::text[]) = 0 OR conversation_id = ANY($1))
+			AND ($2 = '' OR agent_version = $2)
+			AND ($3 = '' OR left(conversation_id, length($3)) = $3)
+			AND ($4::timestamp IS NULL OR created_at >= $4)
+			AND ($5::timestamp IS NULL OR created_at < $5)
+		ORDER BY created_at, conversation_id
+		LIMIT $6
+	`
+	err := r.db.Select(&conversations, query, pq.Array(filter.ConversationIDs), filter.AgentVersion, filter.IDPrefix,
+		filter.CreatedAfter, filter.CreatedBefore, maxConversations+1)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to select snapshot conversations: %w", err)
+	}
+	if len(conversations) > maxConversations {
+		return nil, nil, nil, ErrTooManyConversations
+	}
+
+	ids := make([]string, len(conversations))
+	for i, conv := range conversations {
+		ids[i] = conv.ConversationID
+	}
+
+	evaluations := []models.Evaluation{}
+	query = `SELECT * FROM evaluations WHERE conversation_id = ANY($1) ORDER BY created_at, id`
+	if err := r.db.Select(&evaluations, query, pq.Array(ids)); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to select snapshot evaluations: %w", err)
+	}
+
+	annotations := []models.Annotation{}
+	query = `SELECT * FROM annotations WHERE conversation_id = ANY($1) ORDER BY created_at, id`
+	if err := r.db.Select(&annotations, query, pq.Array(ids)); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to select snapshot annotations: %w", err)
+	}
+
+	return conversations, evaluations, annotations, nil
+}
+
+// RestoreSnapshot loads a snapshot's conversations, evaluations and
+// annotations in one transaction, keeping their IDs, signatures and creation
+// times. Conversations that already exist are skipped with their
+// evaluations and annotations. Evaluations whose ID is taken are skipped too.
+func (r *Repository) RestoreSnapshot(snapshot *models.Snapshot) (*models.SnapshotRestoreResult, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &models.SnapshotRestoreResult{}
+	restored := make(map[string]bool, len(snapshot.Conversations))
+	for _, conv := range snapshot.Conversations {
+		// Snapshots taken before projects existed hold default project data
+		projectID := conv.ProjectID
+		if projectID == "" {
+			projectID = models.DefaultProjectID
+		}
+		res, err := tx.Exec(`
+			INSERT INTO conversations (conversation_id, agent_version, turns, metadata, intent, project_id, cost_usd, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (conversation_id) DO NOTHING
+		`, conv.ConversationID, conv.AgentVersion, conv.Turns, conv.Metadata, conv.Intent, projectID, conv.CostUSD, conv.CreatedAt, conv.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore conversation %s: %w", conv.ConversationID, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			result.SkippedConversations++
+			continue
+		}
+		restored[conv.ConversationID] = true
+		result.Conversations++
+	}
+
+	var evaluations []*models.Evaluation
+	for i := range snapshot.Evaluations {
+		eval := &snapshot.Evaluations[i]
+		if !restored[eval.ConversationID] {
+			continue
+		}
+		res, err := tx.Exec(`
+			INSERT INTO evaluations (
+				evaluation_id, conversation_id, overall_score, response_quality_score,
+				tool_accuracy_score, coherence_score, tool_evaluation, issues_detected,
+				raw_issues_detected, improvement_suggestions, evaluator_version, evaluation_duration_ms,
+				task_id, trigger_source, failed_evaluators, component_scores, partial,
+				signature, signature_algorithm, created_at, skipped_evaluators, evaluation_cost, manifest, project_id,
+				verdict, verdict_rule
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23,
+				(SELECT project_id FROM conversations WHERE conversation_id = $2), $24, $25)
+			ON CONFLICT (evaluation_id) DO NOTHING
+		`,
+			eval.EvaluationID, eval.ConversationID, eval.OverallScore,
+			eval.ResponseQualityScore, eval.ToolAccuracyScore, eval.CoherenceScore,
+			eval.ToolEvaluation, eval.IssuesDetected, eval.RawIssuesDetected,
+			eval.ImprovementSuggestions, eval.EvaluatorVersion, eval.EvaluationDurationMS,
+			eval.TaskID, eval.TriggerSource, eval.FailedEvaluators, eval.ComponentScores,
+			eval.Partial, eval.Signature, eval.SignatureAlgorithm, eval.CreatedAt,
+			defaultJSON(eval.SkippedEvaluators, `[]`), eval.EvaluationCost, defaultJSON(eval.Manifest, `{}`),
+			eval.Verdict, eval.VerdictRule,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore evaluation %s: %w", eval.EvaluationID, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			evaluations = append(evaluations, eval)
+			result.Evaluations++
+		}
+	}
+
+	for _, ann := range snapshot.Annotations {
+		if !restored[ann.ConversationID] {
+			continue
+		}
+		_, err := tx.Exec(`
+			INSERT INTO annotations (conversation_id, annotator_id, annotation_type, label, labels, score, confidence, notes, time_spent_seconds, created_at, assisted, suggested_labels, project_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, (SELECT project_id FROM conversations WHERE conversation_id = $1))
+		`, ann.ConversationID, ann.AnnotatorID, ann.AnnotationType, ann.Label, defaultJSON(ann.Labels, "[]"), ann.Score, ann.Confidence,
+			ann.Notes, ann.TimeSpentSeconds, ann.CreatedAt, ann.Assisted, nullJSON(ann.SuggestedLabels))
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore annotation: %w", err)
+		}
+		result.Annotations++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Rebuild the latest evaluation state, oldest first so the latest wins
+	for _, eval := range evaluations {
+		if err := r.upsertConversationSummary(r.db, eval); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// defaultJSON substitutes a default for a JSON column missing from a
+// snapshot taken before the column existed
+func defaultJSON(raw json.RawMessage, value string) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage(value)
+	}
+	return raw
+}
+
+// nullJSON passes JSON to a nullable column, as NULL when there is none
+func nullJSON(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return []byte(raw)
+}
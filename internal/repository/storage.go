@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/lib/pq"
+)
+
+// jsonbSampleRows is roughly how many rows are sampled per JSONB column
+const jsonbSampleRows = 10000
+
+// GetStorageReport reports the size of every table, the rows each gained over
+// the last growthDays with growth projections, the size distribution of JSONB
+// columns, and suggested storage actions
+func (r *Repository) GetStorageReport(growthDays int) (*models.StorageReport, error) {
+	report := &models.StorageReport{
+		GeneratedAt:  time.Now().UTC(),
+		GrowthDays:   growthDays,
+		JSONBColumns: []models.JSONBColumnStats{},
+	}
+
+	if err := r.db.Get(&report.DatabaseBytes, `SELECT pg_database_size(current_database())`); err != nil {
+		return nil, fmt.Errorf("failed to get database size: %w", err)
+	}
+
+	tables := []models.TableStorage{}
+	query := `
+		SELECT s.relname AS table_name,
+			   GREATEST(c.reltuples, 0)::bigint AS row_estimate,
+			   pg_table_size(c.oid) AS table_bytes,
+			   pg_indexes_size(c.oid) AS index_bytes,
+			   pg_total_relation_size(c.oid) AS total_bytes,
+			   EXISTS (
+				   SELECT 1 FROM information_schema.columns col
+				   WHERE col.table_schema = s.schemaname AND col.table_name = s.relname
+					 AND col.column_name = 'created_at'
+			   ) AS has_created_at
+		FROM pg_stat_user_tables s
+		JOIN pg_class c ON c.oid = s.relid
+		WHERE s.schemaname = current_schema()
+		ORDER BY total_bytes DESC
+	`
+	if err := r.db.Select(&tables, query); err != nil {
+		return nil, fmt.Errorf("failed to get table sizes: %w", err)
+	}
+
+	since := report.GeneratedAt.AddDate(0, 0, -growthDays)
+	for i := range tables {
+		if !tables[i].HasCreated {
+			continue
+		}
+		var recentRows int64
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE created_at >= $1`, pq.QuoteIdentifier(tables[i].Table))
+		if err := r.db.Get(&recentRows, query, since); err != nil {
+			return nil, fmt.Errorf("failed to count recent rows of %s: %w", tables[i].Table, err)
+		}
+		services.ProjectStorageGrowth(&tables[i], recentRows, growthDays)
+	}
+	report.Tables = tables
+
+	var columns []struct {
+		Table  string `db:"table_name"`
+		Column string `db:"column_name"`
+	}
+	query = `
+		SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND data_type = 'jsonb'
+		ORDER BY table_name, ordinal_position
+	`
+	if err := r.db.Select(&columns, query); err != nil {
+		return nil, fmt.Errorf("failed to list JSONB columns: %w", err)
+	}
+
+	rowEstimates := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		rowEstimates[table.Table] = table.RowEstimate
+	}
+	for _, column := range columns {
+		rows := rowEstimates[column.Table]
+		if rows == 0 {
+			continue
+		}
+		// Sample large tables so the report stays cheap
+		percent := math.Min(100, float64(jsonbSampleRows)*100/float64(rows))
+
+		stats := models.JSONBColumnStats{Table: column.Table, Column: column.Column}
+		query := fmt.Sprintf(`
+			SELECT COUNT(*) AS sampled_rows,
+				   COALESCE(AVG(size), 0) AS avg_bytes,
+				   COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY size), 0) AS p50_bytes,
+				   COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY size), 0) AS p90_bytes,
+				   COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY size), 0) AS p99_bytes,
+				   COALESCE(MAX(size), 0)::bigint AS max_bytes
+			FROM (
+				SELECT pg_column_size(%s)::float8 AS size FROM %s TABLESAMPLE BERNOULLI ($1)
+			) sampled
+			WHERE size IS NOT NULL
+		`, pq.QuoteIdentifier(column.Column), pq.QuoteIdentifier(column.Table))
+		if err := r.db.Get(&stats, query, percent); err != nil {
+			return nil, fmt.Errorf("failed to sample %s.%s: %w", column.Table, column.Column, err)
+		}
+		if stats.SampledRows > 0 {
+			report.JSONBColumns = append(report.JSONBColumns, stats)
+		}
+	}
+
+	report.Actions = services.SuggestStorageActions(report.Tables, report.JSONBColumns)
+
+	return report, nil
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/lib/pq"
+)
+
+// annotationTaskColumns selects annotation tasks along with whether they
+// are overdue
+const annotationTaskColumns = `
+	id, conversation_id, annotation_type, annotator_id, assigned_by, status,
+	priority, due_at, COALESCE(due_at < NOW() AND status <> 'completed', FALSE) AS overdue,
+	created_at, updated_at
+`
+
+// annotationTaskOrder surfaces high priority tasks first, then the soonest due
+const annotationTaskOrder = `
+	CASE priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END,
+	due_at NULLS LAST, created_at, id
+`
+
+// getFunc runs a single row query, on the database or in a transaction
+type getFunc func(dest interface{}, query string, args ...interface{}) error
+
+// taskRouting decides the routing priority an annotation task for a
+// conversation inherits, and when it is due if assigned now. Conversations
+// that haven't been evaluated get low priority.
+func taskRouting(get getFunc, conversationID string, policy models.RoutingPolicy, now time.Time) (string, *time.Time, error) {
+	var summary struct {
+		OverallScore   float64         `db:"latest_overall_score"`
+		CriticalIssues int             `db:"critical_issue_count"`
+		SeverityCounts json.RawMessage `db:"severity_counts"`
+		HealthScore    *float64        `db:"health_score"`
+		Verdict        string          `db:"latest_verdict"`
+	}
+	err := get(&summary, `
+		SELECT s.latest_overall_score, s.critical_issue_count, s.severity_counts, h.health_score, s.latest_verdict
+		FROM conversation_summaries s
+		LEFT JOIN conversation_health h ON h.conversation_id = s.conversation_id
+		WHERE s.conversation_id = $1
+	`, conversationID)
+	if err == sql.ErrNoRows {
+		return services.PriorityLow, services.ReviewDeadline(policy, services.PriorityLow, now), nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get conversation summary: %w", err)
+	}
+
+	counts := services.SummarySeverityCounts(summary.SeverityCounts, summary.CriticalIssues)
+	_, priority, _ := services.DecideRouting(summary.OverallScore, counts, summary.HealthScore, summary.Verdict, policy)
+	return priority, services.ReviewDeadline(policy, priority, now), nil
+}
+
+// ListAnnotationTasks lists annotation tasks, highest priority and soonest
+// due first. Empty filters match every annotator; status is "open" for tasks
+// not yet completed, a task status, or empty for every task.
+func (r *Repository) ListAnnotationTasks(annotatorID, status string, limit int) ([]models.AnnotationTask, error) {
+	tasks := []models.AnnotationTask{}
+	args := []interface{}{annotatorID, status, limit}
+	query := `
+		SELECT ` + annotationTaskColumns + `
+		FROM annotation_tasks
+		WHERE ($1 = '' OR annotator_id = $1)
+		  AND ($2 = '' OR ($2 = 'open' AND status <> 'completed') OR status = $2)` +
+		r.conversationFilter("conversation_id", &args) + `
+		ORDER BY ` + annotationTaskOrder + `
+		LIMIT $3
+	`
+
+	if err := r.db.Select(&tasks, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list annotation tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// ListOverdueAnnotationTasks lists open annotation tasks past their deadline,
+// most overdue first. An empty priority matches every priority.
+func (r *Repository) ListOverdueAnnotationTasks(priority string, limit int) ([]models.AnnotationTask, error) {
+	tasks := []models.AnnotationTask{}
+	args := []interface{}{priority, limit}
+	query := `
+		SELECT ` + annotationTaskColumns + `
+		FROM annotation_tasks
+		WHERE status <> 'completed' AND due_at < NOW()
+		  AND ($1 = '' OR priority = $1)` + r.conversationFilter("conversation_id", &args) + `
+		ORDER BY due_at, id
+		LIMIT $2
+	`
+
+	if err := r.db.Select(&tasks, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list overdue annotation tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// ListOpenAnnotationTasks lists every annotation task not yet completed,
+// oldest first
+func (r *Repository) ListOpenAnnotationTasks() ([]models.AnnotationTask, error) {
+	tasks := []models.AnnotationTask{}
+	args := []interface{}{}
+	query := `
+		SELECT ` + annotationTaskColumns + `
+		FROM annotation_tasks
+		WHERE status <> 'completed'` + r.conversationFilter("conversation_id", &args) + `
+		ORDER BY created_at, id
+	`
+
+	if err := r.db.Select(&tasks, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list open annotation tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// EscalateAnnotationTasks raises open annotation tasks to high priority,
+// keeping their deadlines. It returns how many were raised.
+func (r *Repository) EscalateAnnotationTasks(ids []int64) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	args := []interface{}{pq.Array(ids), services.PriorityHigh}
+	query := `
+		UPDATE annotation_tasks SET priority = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ANY($1) AND status <> 'completed' AND priority <> $2` +
+		r.conversationFilter("conversation_id", &args)
+
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to escalate annotation tasks: %w", err)
+	}
+	escalated, _ := result.RowsAffected()
+	return int(escalated), nil
+}
+
+// RecomputeTaskPriorities re-decides the routing priority of open annotation
+// tasks for conversations evaluated since a time whose latest evaluation no
+// reviewer has approved, and moves their deadlines to match, counted from
+// when each task was assigned. It returns how many tasks changed.
+func (r *Repository) RecomputeTaskPriorities(since time.Time, policy models.RoutingPolicy) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var tasks []models.AnnotationTask
+	args := []interface{}{since}
+	query := `
+		SELECT ` + annotationTaskColumns + `
+		FROM annotation_tasks t
+		WHERE t.status <> 'completed'
+		  AND EXISTS (
+			SELECT 1 FROM conversation_summaries s
+			WHERE s.conversation_id = t.conversation_id AND s.updated_at >= $1
+			  AND NOT EXISTS (
+				SELECT 1 FROM routing_approvals a
+				WHERE a.conversation_id = s.conversation_id AND a.evaluation_id = s.latest_evaluation_id
+			  )
+		  )` + r.conversationFilter("t.conversation_id", &args) + `
+		ORDER BY t.id
+		FOR UPDATE
+	`
+	if err := tx.Select(&tasks, query, args...); err != nil {
+		return 0, fmt.Errorf("failed to list undecided annotation tasks: %w", err)
+	}
+
+	changed := 0
+	for _, task := range tasks {
+		priority, dueAt, err := taskRouting(tx.Get, task.ConversationID, policy, task.CreatedAt)
+		if err != nil {
+			return 0, err
+		}
+		if priority == task.Priority {
+			continue
+		}
+		_, err = tx.Exec(`
+			UPDATE annotation_tasks SET priority = $2, due_at = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, task.ID, priority, dueAt)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update annotation task: %w", err)
+		}
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// GetTokenUsage sums the estimated token counts of conversations created
+// since the given time per agent version and model. Conversations ingested
+// before token counting are left out.
+func (r *Repository) GetTokenUsage(since time.Time, agentVersion string) ([]models.TokenUsage, error) {
+	args := []interface{}{since, agentVersion}
+	query := `
+		SELECT agent_version, COALESCE(metadata->>'model', '') AS model,
+			   COUNT(*) AS conversations,
+			   COALESCE(SUM(jsonb_array_length(token_counts->'turns')), 0) AS turns,
+			   COALESCE(SUM((token_counts->>'content')::bigint), 0) AS content_tokens,
+			   COALESCE(SUM((token_counts->>'tool_calls')::bigint), 0) AS tool_call_tokens,
+			   COALESCE(SUM((token_counts->>'tool_results')::bigint), 0) AS tool_result_tokens,
+			   COALESCE(SUM((token_counts->>'total')::bigint), 0) AS total_tokens,
+			   COALESCE(AVG((token_counts->>'total')::bigint), 0) AS avg_tokens
+		FROM conversations
+		WHERE created_at >= $1 AND ($2 = '' OR agent_version = $2) AND token_counts ? 'total'` +
+		r.projectFilter("project_id", &args) + `
+		GROUP BY 1, 2
+		ORDER BY total_tokens DESC
+	`
+
+	usage := []models.TokenUsage{}
+	if err := r.db.Select(&usage, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get token usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// CostGroupings are the ways conversation costs can be grouped, keyed by
+// name with the columns each selects
+var CostGroupings = map[string]string{
+	"agent_version": "c.agent_version AS agent_version, NULL::timestamp AS day",
+	"day":           "'' AS agent_version, date_trunc('day', c.created_at) AS day",
+}
+
+// GetCosts sums what running the agent cost for conversations created since
+// the given time, grouped by one of CostGroupings, along with the count and
+// budgeted cost of their evaluations. Days without conversations are left
+// out.
+func (r *Repository) GetCosts(groupBy string, since time.Time, agentVersion string) ([]models.CostBucket, error) {
+	columns, ok := CostGroupings[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported cost grouping %q", groupBy)
+	}
+
+	args := []interface{}{since, agentVersion}
+	query := `
+		SELECT ` + columns + `,
+			   COUNT(*) AS conversations,
+			   COUNT(c.cost_usd) AS priced_conversations,
+			   COALESCE(SUM((c.token_counts->>'input_tokens')::bigint), 0) AS input_tokens,
+			   COALESCE(SUM((c.token_counts->>'output_tokens')::bigint), 0) AS output_tokens,
+			   COALESCE(SUM(c.cost_usd), 0) AS cost_usd,
+			   AVG(c.cost_usd) AS avg_cost_usd,
+			   COALESCE(SUM(e.evaluations), 0) AS evaluations,
+			   COALESCE(SUM(e.evaluation_cost), 0) AS evaluation_cost
+		FROM conversations c
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS evaluations, SUM(evaluation_cost) AS evaluation_cost
+			FROM evaluations
+			WHERE conversation_id = c.conversation_id
+		) e ON TRUE
+		WHERE c.created_at >= $1 AND ($2 = '' OR c.agent_version = $2)` +
+		r.projectFilter("c.project_id", &args) + `
+		GROUP BY 1, 2
+		ORDER BY 2, cost_usd DESC
+	`
+
+	buckets := []models.CostBucket{}
+	if err := r.db.Select(&buckets, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get costs: %w", err)
+	}
+
+	return buckets, nil
+}
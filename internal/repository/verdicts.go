@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+)
+
+// RecomputeVerdicts decides the verdicts of evaluations stored since a time
+// again under a policy, and the latest verdicts of their conversations. It
+// returns how many evaluations' verdicts changed. Verdicts aren't signed, so
+// signatures stay valid.
+func (r *Repository) RecomputeVerdicts(since time.Time, policy models.VerdictPolicy) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var evals []models.Evaluation
+	args := []interface{}{since}
+	query := `SELECT * FROM evaluations WHERE created_at >= $1` + r.projectFilter("project_id", &args) + `
+		ORDER BY id
+		FOR UPDATE
+	`
+	if err := tx.Select(&evals, query, args...); err != nil {
+		return 0, fmt.Errorf("failed to list evaluations: %w", err)
+	}
+
+	changed := 0
+	for i := range evals {
+		eval := &evals[i]
+		verdict, rule, err := services.DecideVerdict(policy, eval)
+		if err != nil {
+			return 0, fmt.Errorf("evaluation %s: %w", eval.EvaluationID, err)
+		}
+		if verdict == eval.Verdict && rule == eval.VerdictRule {
+			continue
+		}
+		if _, err := tx.Exec(`
+			UPDATE evaluations SET verdict = $2, verdict_rule = $3 WHERE evaluation_id = $1
+		`, eval.EvaluationID, verdict, rule); err != nil {
+			return 0, fmt.Errorf("failed to update evaluation verdict: %w", err)
+		}
+		if _, err := tx.Exec(`
+			UPDATE conversation_summaries SET latest_verdict = $2 WHERE latest_evaluation_id = $1
+		`, eval.EvaluationID, verdict); err != nil {
+			return 0, fmt.Errorf("failed to update conversation summary: %w", err)
+		}
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return changed, nil
+}
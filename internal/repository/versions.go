@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/lib/pq"
+)
+
+// versionMatrixFrom joins conversations to their latest evaluation
+const versionMatrixFrom = `
+	FROM conversations c
+	JOIN conversation_summaries s ON s.conversation_id = c.conversation_id
+	JOIN evaluations e ON e.evaluation_id = s.latest_evaluation_id
+`
+
+// versionMatrixToolVersions is the tool_versions metadata object, or an
+// empty object when a conversation doesn't record one
+const versionMatrixToolVersions = `
+	CASE WHEN jsonb_typeof(c.metadata->'tool_versions') = 'object' THEN c.metadata->'tool_versions' ELSE '{}'::jsonb END
+`
+
+// GetVersionMatrix crosses agent version, model and tool version against the
+// latest scores of conversations created since the given time. Cells with
+// fewer than minEvaluations evaluations are left out. Empty agentVersion and
+// tool include every agent version and tool.
+func (r *Repository) GetVersionMatrix(since time.Time, agentVersion, tool string, minEvaluations int) ([]models.VersionMatrixCell, error) {
+	args := []interface{}{since, agentVersion, tool, minEvaluations}
+	query := `
+		SELECT c.agent_version, COALESCE(c.metadata->>'model', '') AS model,
+			   COALESCE(t.tool, '') AS tool, COALESCE(t.version, '') AS tool_version,
+			   COUNT(*) AS evaluations,
+			   AVG(e.overall_score) AS avg_overall_score,
+			   AVG(e.response_quality_score) AS avg_response_quality_score,
+			   AVG(e.tool_accuracy_score) AS avg_tool_accuracy_score,
+			   AVG(e.coherence_score) AS avg_coherence_score
+	` + versionMatrixFrom + `
+		LEFT JOIN LATERAL jsonb_each_text(` + versionMatrixToolVersions + `) AS t(tool, version) ON TRUE
+		WHERE c.created_at >= $1 AND ($2 = '' OR c.agent_version = $2) AND ($3 = '' OR t.tool = $3)` +
+		r.projectFilter("c.project_id", &args) + `
+		GROUP BY 1, 2, 3, 4
+		HAVING COUNT(*) >= $4
+		ORDER BY agent_version, model, tool, tool_version
+	`
+
+	cells := []models.VersionMatrixCell{}
+	if err := r.db.Select(&cells, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get version matrix: %w", err)
+	}
+
+	return cells, nil
+}
+
+// GetVersionMarginals averages the latest scores of conversations created
+// since the given time per agent version, per model and per version of each
+// tool. Each conversation counts once per value, however many tools it uses.
+func (r *Repository) GetVersionMarginals(since time.Time, agentVersion, tool string) ([]models.VersionMarginal, error) {
+	args := []interface{}{since, agentVersion, tool,
+		services.VersionDimensionAgent, services.VersionDimensionModel, services.VersionDimensionTool}
+	project := r.projectFilter("c.project_id", &args)
+	query := `
+		SELECT d.dimension, ''::text AS tool, d.value, COUNT(*) AS evaluations, AVG(e.overall_score) AS avg_overall_score
+	` + versionMatrixFrom + `
+		CROSS JOIN LATERAL (VALUES ($4::text, c.agent_version), ($5::text, COALESCE(c.metadata->>'model', ''))) AS d(dimension, value)
+		WHERE c.created_at >= $1 AND ($2 = '' OR c.agent_version = $2)
+		  AND ($3 = '' OR (` + versionMatrixToolVersions + `) ? $3)` + project + `
+		GROUP BY 1, 2, 3
+		UNION ALL
+		SELECT $6::text, t.tool, t.version, COUNT(*), AVG(e.overall_score)
+	` + versionMatrixFrom + `
+		CROSS JOIN LATERAL jsonb_each_text(` + versionMatrixToolVersions + `) AS t(tool, version)
+		WHERE c.created_at >= $1 AND ($2 = '' OR c.agent_version = $2) AND ($3 = '' OR t.tool = $3)` + project + `
+		GROUP BY 1, 2, 3
+		ORDER BY dimension, tool, value
+	`
+
+	marginals := []models.VersionMarginal{}
+	err := r.db.Select(&marginals, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version marginals: %w", err)
+	}
+
+	return marginals, nil
+}
+
+// GetVersionScores returns the latest overall and component scores of
+// conversations of each agent version created since the given time, by
+// version and metric
+func (r *Repository) GetVersionScores(since time.Time, agentVersions []string) (map[string]map[string][]float64, error) {
+	args := []interface{}{since, pq.Array(agentVersions)}
+	query := `
+		SELECT c.agent_version, m.key AS metric, m.value::float AS score
+	` + versionMatrixFrom + `
+		CROSS JOIN LATERAL jsonb_each_text(e.component_scores || jsonb_build_object('overall', e.overall_score)) AS m(key, value)
+		WHERE c.created_at >= $1 AND c.agent_version = ANY($2)` + r.projectFilter("c.project_id", &args) + `
+	`
+
+	var rows []struct {
+		AgentVersion string  `db:"agent_version"`
+		Metric       string  `db:"metric"`
+		Score        float64 `db:"score"`
+	}
+	if err := r.db.Select(&rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get version scores: %w", err)
+	}
+
+	scores := make(map[string]map[string][]float64, len(agentVersions))
+	for _, version := range agentVersions {
+		scores[version] = make(map[string][]float64)
+	}
+	for _, row := range rows {
+		scores[row.AgentVersion][row.Metric] = append(scores[row.AgentVersion][row.Metric], row.Score)
+	}
+
+	return scores, nil
+}
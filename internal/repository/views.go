@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+	services "github.com/ai-agent-eval/internal/service"
+)
+
+// viewSortColumns maps the sort fields of saved view resources to columns
+var viewSortColumns = map[string]map[string]string{
+	models.ViewResourceConversations: {
+		services.ViewSortCreatedAt: "c.created_at",
+		services.ViewSortUpdatedAt: "c.updated_at",
+		services.ViewSortScore:     "s.latest_overall_score",
+	},
+	models.ViewResourceEvaluations: {
+		services.ViewSortCreatedAt: "created_at",
+		services.ViewSortScore:     "overall_score",
+	},
+}
+
+// CreateSavedView saves a view for a project. It returns nil if the project
+// already has a view with the name.
+func (r *Repository) CreateSavedView(projectID string, view *models.SavedViewCreate) (*models.SavedView, error) {
+	filtersJSON, err := json.Marshal(view.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal view filters: %w", err)
+	}
+
+	query := `
+		INSERT INTO saved_views (project_id, name, description, resource, filters, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (project_id, name) DO NOTHING
+		RETURNING *
+	`
+
+	var result models.SavedView
+	err = r.db.QueryRowx(query, projectID, view.Name, view.Description, view.Resource, filtersJSON, view.CreatedBy).StructScan(&result)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create saved view: %w", err)
+	}
+
+	return &result, nil
+}
+
+// UpdateSavedView changes a project's saved view. It returns nil if the view
+// doesn't exist.
+func (r *Repository) UpdateSavedView(projectID, name string, view *models.SavedViewUpdate) (*models.SavedView, error) {
+	filtersJSON, err := json.Marshal(view.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal view filters: %w", err)
+	}
+
+	query := `
+		UPDATE saved_views
+		SET description = $1, resource = $2, filters = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE project_id = $4 AND name = $5
+		RETURNING *
+	`
+
+	var result models.SavedView
+	err = r.db.QueryRowx(query, view.Description, view.Resource, filtersJSON, projectID, name).StructScan(&result)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update saved view: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListSavedViews lists a project's saved views by name, optionally of one resource
+func (r *Repository) ListSavedViews(projectID, resource string) ([]models.SavedView, error) {
+	views := []models.SavedView{}
+
+	query := `SELECT * FROM saved_views WHERE project_id = $1`
+	args := []interface{}{projectID}
+	if resource != "" {
+		query += ` AND resource = $2`
+		args = append(args, resource)
+	}
+	query += ` ORDER BY name`
+
+	if err := r.db.Select(&views, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list saved views: %w", err)
+	}
+
+	return views, nil
+}
+
+// GetSavedView retrieves a project's saved view by name
+func (r *Repository) GetSavedView(projectID, name string) (*models.SavedView, error) {
+	var view models.SavedView
+
+	query := `SELECT * FROM saved_views WHERE project_id = $1 AND name = $2`
+	if err := r.db.Get(&view, query, projectID, name); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get saved view: %w", err)
+	}
+
+	return &view, nil
+}
+
+// DeleteSavedView deletes a project's saved view. It reports whether a row was deleted.
+func (r *Repository) DeleteSavedView(projectID, name string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM saved_views WHERE project_id = $1 AND name = $2`, projectID, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved view: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved view: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// ListConversationsForView lists the conversations a saved view selects.
+// Scores and issues are those of each conversation's latest evaluation.
+func (r *Repository) ListConversationsForView(filters models.ViewFilters, limit, offset int) ([]models.Conversation, error) {
+	conversations := []models.Conversation{}
+
+	args := []interface{}{}
+	query := `
+		SELECT c.* FROM conversations c
+		LEFT JOIN conversation_summaries s ON s.conversation_id = c.conversation_id
+		WHERE 1=1` + r.projectFilter("c.project_id", &args)
+
+	if filters.AgentVersion != "" {
+		args = append(args, filters.AgentVersion)
+		query += fmt.Sprintf(" AND c.agent_version = $%d", len(args))
+	}
+	if filters.Intent != "" {
+		args = append(args, filters.Intent)
+		query += fmt.Sprintf(" AND c.intent = $%d", len(args))
+	}
+	if filters.MinScore != nil {
+		args = append(args, *filters.MinScore)
+		query += fmt.Sprintf(" AND s.latest_overall_score >= $%d", len(args))
+	}
+	if filters.MaxScore != nil {
+		args = append(args, *filters.MaxScore)
+		query += fmt.Sprintf(" AND s.latest_overall_score <= $%d", len(args))
+	}
+	if filters.Verdict != "" {
+		args = append(args, filters.Verdict)
+		query += fmt.Sprintf(" AND s.latest_verdict = $%d", len(args))
+	}
+	if issue, ok := issueContainment(filters); ok {
+		args = append(args, issue)
+		query += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM evaluations e
+			WHERE e.evaluation_id = s.latest_evaluation_id AND e.issues_detected @> $%d::jsonb
+		)`, len(args))
+	}
+	if filters.WithinDays > 0 {
+		args = append(args, time.Now().UTC().AddDate(0, 0, -filters.WithinDays))
+		query += fmt.Sprintf(" AND c.created_at >= $%d", len(args))
+	}
+
+	query += viewOrder(models.ViewResourceConversations, filters, "c.id")
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	if err := r.db.Select(&conversations, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list view conversations: %w", err)
+	}
+
+	return conversations, nil
+}
+
+// ListEvaluationsForView lists the evaluations a saved view selects
+func (r *Repository) ListEvaluationsForView(filters models.ViewFilters, limit, offset int) ([]models.Evaluation, error) {
+	evaluations := []models.Evaluation{}
+
+	args := []interface{}{}
+	query := `SELECT * FROM evaluations WHERE 1=1` + r.projectFilter("project_id", &args)
+
+	var convConditions []string
+	if filters.AgentVersion != "" {
+		args = append(args, filters.AgentVersion)
+		convConditions = append(convConditions, fmt.Sprintf("agent_version = $%d", len(args)))
+	}
+	if filters.Intent != "" {
+		args = append(args, filters.Intent)
+		convConditions = append(convConditions, fmt.Sprintf("intent = $%d", len(args)))
+	}
+	if len(convConditions) > 0 {
+		query += " AND conversation_id IN (SELECT conversation_id FROM conversations WHERE " + strings.Join(convConditions, " AND ") + ")"
+	}
+
+	if filters.TriggerSource != "" {
+		args = append(args, filters.TriggerSource)
+		query += fmt.Sprintf(" AND trigger_source = $%d", len(args))
+	}
+	if filters.MinScore != nil {
+		args = append(args, *filters.MinScore)
+		query += fmt.Sprintf(" AND overall_score >= $%d", len(args))
+	}
+	if filters.MaxScore != nil {
+		args = append(args, *filters.MaxScore)
+		query += fmt.Sprintf(" AND overall_score <= $%d", len(args))
+	}
+	if filters.Verdict != "" {
+		args = append(args, filters.Verdict)
+		query += fmt.Sprintf(" AND verdict = $%d", len(args))
+	}
+	if issue, ok := issueContainment(filters); ok {
+		args = append(args, issue)
+		query += fmt.Sprintf(" AND issues_detected @> $%d::jsonb", len(args))
+	}
+	if filters.WithinDays > 0 {
+		args = append(args, time.Now().UTC().AddDate(0, 0, -filters.WithinDays))
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+
+	query += viewOrder(models.ViewResourceEvaluations, filters, "id")
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	if err := r.db.Select(&evaluations, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list view evaluations: %w", err)
+	}
+
+	return evaluations, nil
+}
+
+// issueContainment returns the JSONB an issues_detected array contains when
+// it has an issue of the filtered type and severity, if either is filtered
+func issueContainment(filters models.ViewFilters) (string, bool) {
+	issue := map[string]string{}
+	if filters.IssueType != "" {
+		issue["type"] = filters.IssueType
+	}
+	if filters.IssueSeverity != "" {
+		issue["severity"] = filters.IssueSeverity
+	}
+	if len(issue) == 0 {
+		return "", false
+	}
+	data, _ := json.Marshal([]map[string]string{issue})
+	return string(data), true
+}
+
+// viewOrder returns the ORDER BY clause of a saved view. Unscored items sort
+// last, and tie breaks on idColumn keep pages stable.
+func viewOrder(resource string, filters models.ViewFilters, idColumn string) string {
+	column, ok := viewSortColumns[resource][filters.SortBy]
+	if !ok {
+		column = viewSortColumns[resource][services.ViewSortCreatedAt]
+	}
+	direction := "DESC"
+	if filters.SortOrder == "asc" {
+		direction = "ASC"
+	}
+	return fmt.Sprintf(" ORDER BY %s %s NULLS LAST, %s %s", column, direction, idColumn, direction)
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// CreateProjectWebhook creates a webhook for a project
+func (r *Repository) CreateProjectWebhook(projectID string, hook *models.ProjectWebhookCreate) (*models.ProjectWebhook, error) {
+	filterJSON, err := json.Marshal(hook.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal filter: %w", err)
+	}
+
+	query := `
+		INSERT INTO project_webhooks (project_id, url, payload_template, filter)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *
+	`
+
+	var result models.ProjectWebhook
+	if err := r.db.QueryRowx(query, projectID, hook.URL, hook.PayloadTemplate, filterJSON).StructScan(&result); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return &result, nil
+}
+
+// UpdateProjectWebhook replaces a project webhook's URL, template and filter
+// and enables it. It returns nil if there is no such webhook.
+func (r *Repository) UpdateProjectWebhook(projectID string, id int64, hook *models.ProjectWebhookCreate) (*models.ProjectWebhook, error) {
+	filterJSON, err := json.Marshal(hook.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal filter: %w", err)
+	}
+
+	query := `
+		UPDATE project_webhooks
+		SET url = $3, payload_template = $4, filter = $5, enabled = TRUE, updated_at = CURRENT_TIMESTAMP
+		WHERE project_id = $1 AND id = $2
+		RETURNING *
+	`
+
+	var result models.ProjectWebhook
+	if err := r.db.QueryRowx(query, projectID, id, hook.URL, hook.PayloadTemplate, filterJSON).StructScan(&result); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListProjectWebhooks lists the webhooks of a project
+func (r *Repository) ListProjectWebhooks(projectID string, enabledOnly bool) ([]models.ProjectWebhook, error) {
+	var hooks []models.ProjectWebhook
+
+	query := `SELECT * FROM project_webhooks WHERE project_id = $1`
+	if enabledOnly {
+		query += ` AND enabled = TRUE`
+	}
+	query += ` ORDER BY id`
+
+	if err := r.db.Select(&hooks, query, projectID); err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	return hooks, nil
+}
+
+// DeleteProjectWebhook deletes a project webhook. It reports whether a webhook was deleted.
+func (r *Repository) DeleteProjectWebhook(projectID string, id int64) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM project_webhooks WHERE project_id = $1 AND id = $2`, projectID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// CreateWebhookDeliveries queues deliveries to be sent
+func (r *Repository) CreateWebhookDeliveries(deliveries []models.WebhookDelivery) error {
+	for _, delivery := range deliveries {
+		_, err := r.db.Exec(`
+			INSERT INTO webhook_deliveries (webhook_id, event, evaluation_id, payload)
+			VALUES ($1, $2, $3, $4)
+		`, delivery.WebhookID, delivery.Event, delivery.EvaluationID, delivery.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClaimWebhookDeliveries claims up to limit pending deliveries of enabled
+// webhooks that are due, counting an attempt for each. A claimed delivery
+// isn't due again until lease has passed, so a sender that stops mid-attempt
+// leaves it to be retried rather than lost, and concurrent senders never
+// claim the same delivery.
+func (r *Repository) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]models.PendingWebhookDelivery, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries SET
+				attempts = attempts + 1,
+				next_attempt_at = NOW() + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT d.id FROM webhook_deliveries d
+				JOIN project_webhooks w ON w.id = d.webhook_id
+				WHERE d.status = $3 AND d.next_attempt_at <= NOW() AND w.enabled = TRUE
+				ORDER BY d.next_attempt_at
+				LIMIT $1
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT c.*, w.url, w.secret
+		FROM claimed c
+		JOIN project_webhooks w ON w.id = c.webhook_id
+		ORDER BY c.id
+	`
+
+	deliveries := []models.PendingWebhookDelivery{}
+	if err := r.db.Select(&deliveries, query, limit, lease.Seconds(), models.WebhookDeliveryPending); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookDeliveryAttempt records the outcome of sending a claimed
+// delivery. A pending status schedules it to be sent again at nextAttemptAt.
+func (r *Repository) RecordWebhookDeliveryAttempt(id int64, status string, responseStatus *int, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE webhook_deliveries SET
+			status = $2, response_status = $3, last_error = $4, next_attempt_at = $5,
+			delivered_at = CASE WHEN $2 = '` + models.WebhookDeliveryDelivered + `' THEN NOW() END
+		WHERE id = $1
+	`
+	if _, err := r.db.Exec(query, id, status, responseStatus, lastError, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries lists a project webhook's deliveries, newest first.
+// An empty status matches every status.
+func (r *Repository) ListWebhookDeliveries(projectID string, webhookID int64, status string, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT d.* FROM webhook_deliveries d
+		JOIN project_webhooks w ON w.id = d.webhook_id
+		WHERE w.project_id = $1 AND d.webhook_id = $2 AND ($3 = '' OR d.status = $3)
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $4
+	`
+
+	deliveries := []models.WebhookDelivery{}
+	if err := r.db.Select(&deliveries, query, projectID, webhookID, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
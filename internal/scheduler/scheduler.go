@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a unit of periodic work
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs registered jobs on fixed intervals
+type Scheduler struct {
+	jobs   []Job
+	paused func() bool // Runs are skipped while it reports true
+}
+
+// New creates a new scheduler
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add registers a job. Jobs with a non-positive interval are ignored.
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		log.Printf("Scheduler job %s disabled (interval %s)", job.Name, job.Interval)
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// SkipWhile makes jobs skip their runs while paused reports true, such as
+// during maintenance. Skipped runs aren't made up; jobs resume on their next
+// tick.
+func (s *Scheduler) SkipWhile(paused func() bool) {
+	s.paused = paused
+}
+
+// Run starts all jobs and blocks until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.runJob(ctx, job)
+		}(job)
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// runJob runs a single job every interval until ctx is cancelled
+func (s *Scheduler) runJob(ctx context.Context, job Job) {
+	log.Printf("Scheduler job %s running every %s", job.Name, job.Interval)
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.paused != nil && s.paused() {
+				log.Printf("Scheduler job %s skipped while paused", job.Name)
+				continue
+			}
+			start := time.Now()
+			if err := job.Run(ctx); err != nil {
+				log.Printf("Scheduler job %s failed: %v", job.Name, err)
+				continue
+			}
+			log.Printf("Scheduler job %s completed in %s", job.Name, time.Since(start))
+		}
+	}
+}
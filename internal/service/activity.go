@@ -0,0 +1,79 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// ActivityTypes are the activity event types clients can filter on
+var ActivityTypes = []string{
+	models.ActivityConversationIngested,
+	models.ActivityEvaluationCompleted,
+	models.ActivityAnnotationCreated,
+	models.ActivityCommentCreated,
+}
+
+// ValidateActivityFilter checks that a filter names known severities and
+// event types
+func ValidateActivityFilter(filter models.ActivityFilter) error {
+	if _, ok := severityRank[filter.MinSeverity]; filter.MinSeverity != "" && !ok {
+		return fmt.Errorf("unknown severity %q", filter.MinSeverity)
+	}
+	for _, eventType := range filter.Types {
+		known := false
+		for _, t := range ActivityTypes {
+			known = known || t == eventType
+		}
+		if !known {
+			return fmt.Errorf("unknown activity type %q", eventType)
+		}
+	}
+	return nil
+}
+
+// ActivityMatches reports whether an event passes a filter. Evaluations
+// without issues don't reach any minimum severity.
+func ActivityMatches(filter models.ActivityFilter, event models.ActivityEvent) bool {
+	if filter.AgentVersion != "" && event.AgentVersion != filter.AgentVersion {
+		return false
+	}
+	if len(filter.Types) > 0 {
+		matched := false
+		for _, eventType := range filter.Types {
+			matched = matched || eventType == event.Type
+		}
+		if !matched {
+			return false
+		}
+	}
+	if filter.MinSeverity != "" && event.Type == models.ActivityEvaluationCompleted {
+		return severityRank[event.Severity] >= severityRank[filter.MinSeverity]
+	}
+	return true
+}
+
+// EvaluationActivity describes a stored evaluation as an activity event
+func EvaluationActivity(eval *models.Evaluation, agentVersion string) models.ActivityEvent {
+	event := models.ActivityEvent{
+		Type:           models.ActivityEvaluationCompleted,
+		ProjectID:      eval.ProjectID,
+		ConversationID: eval.ConversationID,
+		AgentVersion:   agentVersion,
+		EvaluationID:   eval.EvaluationID,
+		OverallScore:   &eval.OverallScore,
+		At:             time.Now().UTC(),
+	}
+
+	var issues []models.IssueDetected
+	json.Unmarshal(eval.IssuesDetected, &issues)
+	event.IssueCount = len(issues)
+	for _, issue := range issues {
+		if severityRank[issue.Severity] > severityRank[event.Severity] {
+			event.Severity = issue.Severity
+		}
+	}
+	return event
+}
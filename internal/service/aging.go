@@ -0,0 +1,120 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// reviewAgeBuckets are the age ranges open annotation tasks are counted in,
+// by the age they reach up to. The last bucket has no upper bound.
+var reviewAgeBuckets = []struct {
+	label string
+	upTo  time.Duration
+}{
+	{"<4h", 4 * time.Hour},
+	{"4h-24h", 24 * time.Hour},
+	{"1d-3d", 72 * time.Hour},
+	{"3d-7d", 7 * 24 * time.Hour},
+	{">7d", 0},
+}
+
+// ageBucket returns the label of the bucket a task of an age is counted in
+func ageBucket(age time.Duration) string {
+	for _, bucket := range reviewAgeBuckets {
+		if bucket.upTo == 0 || age < bucket.upTo {
+			return bucket.label
+		}
+	}
+	return reviewAgeBuckets[len(reviewAgeBuckets)-1].label
+}
+
+// ReviewBreached reports whether an open task is past its deadline
+func ReviewBreached(task models.AnnotationTask, now time.Time) bool {
+	return task.DueAt != nil && now.After(*task.DueAt)
+}
+
+// ReviewAtRisk reports whether an open task is still within its deadline but
+// has less than atRiskFraction of the time it was given left
+func ReviewAtRisk(task models.AnnotationTask, now time.Time, atRiskFraction float64) bool {
+	if task.DueAt == nil || ReviewBreached(task, now) {
+		return false
+	}
+	given := task.DueAt.Sub(task.CreatedAt)
+	return given > 0 && float64(task.DueAt.Sub(now)) < atRiskFraction*float64(given)
+}
+
+// ReviewAging reports the age, deadline status and per-annotator load of
+// open annotation tasks
+func ReviewAging(tasks []models.AnnotationTask, now time.Time, atRiskFraction float64) *models.ReviewAgingReport {
+	report := &models.ReviewAgingReport{
+		GeneratedAt:        now,
+		Open:               len(tasks),
+		Buckets:            make([]models.ReviewAgeBucket, len(reviewAgeBuckets)),
+		BreachedByPriority: map[string]int{},
+		Annotators:         []models.AnnotatorReviewLoad{},
+	}
+	buckets := make(map[string]*models.ReviewAgeBucket, len(reviewAgeBuckets))
+	for i, bucket := range reviewAgeBuckets {
+		report.Buckets[i] = models.ReviewAgeBucket{Bucket: bucket.label, ByPriority: map[string]int{}}
+		buckets[bucket.label] = &report.Buckets[i]
+	}
+
+	annotators := make(map[string]*models.AnnotatorReviewLoad)
+	for _, task := range tasks {
+		age := now.Sub(task.CreatedAt)
+		bucket := buckets[ageBucket(age)]
+		bucket.Total++
+		bucket.ByPriority[task.Priority]++
+
+		load := annotators[task.AnnotatorID]
+		if load == nil {
+			load = &models.AnnotatorReviewLoad{AnnotatorID: task.AnnotatorID}
+			annotators[task.AnnotatorID] = load
+		}
+		load.Open++
+		if hours := age.Hours(); hours > load.OldestAgeHours {
+			load.OldestAgeHours = hours
+		}
+
+		switch {
+		case ReviewBreached(task, now):
+			report.Breached++
+			report.BreachedByPriority[task.Priority]++
+			load.Breached++
+		case ReviewAtRisk(task, now, atRiskFraction):
+			report.AtRisk++
+			load.AtRisk++
+		}
+	}
+
+	for _, load := range annotators {
+		report.Annotators = append(report.Annotators, *load)
+	}
+	// Annotators with the most pressing work first
+	sort.Slice(report.Annotators, func(i, j int) bool {
+		a, b := report.Annotators[i], report.Annotators[j]
+		if a.Breached+a.AtRisk != b.Breached+b.AtRisk {
+			return a.Breached+a.AtRisk > b.Breached+b.AtRisk
+		}
+		if a.Open != b.Open {
+			return a.Open > b.Open
+		}
+		return a.AnnotatorID < b.AnnotatorID
+	})
+
+	return report
+}
+
+// TasksToEscalate returns the IDs of open tasks at risk of missing their
+// deadline that aren't high priority yet
+func TasksToEscalate(tasks []models.AnnotationTask, now time.Time, atRiskFraction float64) []int64 {
+	var ids []int64
+	for _, task := range tasks {
+		if task.Priority != PriorityHigh && ReviewAtRisk(task, now, atRiskFraction) {
+			ids = append(ids, task.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// AnnotatorProfile is the inferred performance of a single annotator
+type AnnotatorProfile struct {
+	AnnotatorID     string
+	AgreementRate   float64
+	Specializations []string
+}
+
+// InferSpecializations derives annotator specializations from their agreement
+// with the majority label per annotation type. An annotator specializes in a
+// type once they have at least minSamples annotations with accuracy >= minAccuracy.
+func InferSpecializations(accuracies []models.AnnotatorTypeAccuracy, minSamples int, minAccuracy float64) []AnnotatorProfile {
+	type totals struct {
+		total, agreed   int
+		specializations []string
+	}
+	byAnnotator := make(map[string]*totals)
+
+	for _, acc := range accuracies {
+		t, ok := byAnnotator[acc.AnnotatorID]
+		if !ok {
+			t = &totals{specializations: []string{}}
+			byAnnotator[acc.AnnotatorID] = t
+		}
+		t.total += acc.Total
+		t.agreed += acc.Agreed
+
+		if acc.Total >= minSamples && float64(acc.Agreed)/float64(acc.Total) >= minAccuracy {
+			t.specializations = append(t.specializations, acc.AnnotationType)
+		}
+	}
+
+	profiles := make([]AnnotatorProfile, 0, len(byAnnotator))
+	for annotatorID, t := range byAnnotator {
+		sort.Strings(t.specializations)
+		profile := AnnotatorProfile{
+			AnnotatorID:     annotatorID,
+			Specializations: t.specializations,
+		}
+		if t.total > 0 {
+			profile.AgreementRate = float64(t.agreed) / float64(t.total)
+		}
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].AnnotatorID < profiles[j].AnnotatorID })
+
+	return profiles
+}
+
+// PickAnnotators picks up to n candidates not in exclude for an annotation
+// task, preferring specialists, then the least loaded, then the best agreement.
+// Picked candidates have their open task count increased so repeated calls
+// spread tasks across annotators.
+func PickAnnotators(candidates []models.AnnotatorCandidate, exclude []string, n int) []string {
+	excluded := make(map[string]bool, len(exclude))
+	for _, annotatorID := range exclude {
+		excluded[annotatorID] = true
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Specialized != b.Specialized {
+			return a.Specialized
+		}
+		if a.OpenTasks != b.OpenTasks {
+			return a.OpenTasks < b.OpenTasks
+		}
+		return a.AgreementRate > b.AgreementRate
+	})
+
+	picked := []string{}
+	for i := range candidates {
+		if len(picked) == n {
+			break
+		}
+		if excluded[candidates[i].AnnotatorID] {
+			continue
+		}
+		picked = append(picked, candidates[i].AnnotatorID)
+		candidates[i].OpenTasks++
+	}
+
+	return picked
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Quality metrics watched for anomalies
+const (
+	MetricAvgScore  = "avg_score"
+	MetricIssueRate = "issue_rate"
+)
+
+// AnomalyPolicy configures anomaly detection over hourly quality series
+type AnomalyPolicy struct {
+	ZThreshold     float64 // Deviations from the baseline, in standard deviations, that count as anomalous
+	MinEvaluations int     // Hours with fewer evaluations are skipped
+	MinStdDev      float64 // Floor on the deviation so flat series don't alert on noise
+	WarmupHours    int     // Hours of history needed before anything is flagged
+	LevelAlpha     float64 // EWMA weight of the newest value in the level and variance
+	SeasonalGamma  float64 // EWMA weight of the newest value in the hour-of-day offset
+}
+
+// DefaultAnomalyPolicy flags 3-sigma moves after two days of history
+var DefaultAnomalyPolicy = AnomalyPolicy{
+	ZThreshold:     3,
+	MinEvaluations: 5,
+	MinStdDev:      0.02,
+	WarmupHours:    48,
+	LevelAlpha:     0.1,
+	SeasonalGamma:  0.2,
+}
+
+// anomalyMetric describes a watched metric and which direction is bad
+type anomalyMetric struct {
+	name  string
+	value func(models.QualityBucket) float64
+	drop  bool // A drop is bad; otherwise a rise is bad
+}
+
+var anomalyMetrics = []anomalyMetric{
+	{name: MetricAvgScore, value: func(b models.QualityBucket) float64 { return b.AvgScore }, drop: true},
+	{name: MetricIssueRate, value: func(b models.QualityBucket) float64 { return b.IssueRate }, drop: false},
+}
+
+// DetectAnomalies flags hours where a quality metric moved unusually far in
+// the bad direction. The baseline is an EWMA level plus an EWMA offset per
+// hour of day, so daily traffic patterns aren't flagged; the deviation is an
+// EWMA of the residual variance. Buckets must be hourly and in order; gaps
+// are fine.
+func DetectAnomalies(buckets []models.QualityBucket, policy AnomalyPolicy) []models.AnomalyEvent {
+	var events []models.AnomalyEvent
+	for _, metric := range anomalyMetrics {
+		events = append(events, detectMetricAnomalies(buckets, metric, policy)...)
+	}
+	return events
+}
+
+func detectMetricAnomalies(buckets []models.QualityBucket, metric anomalyMetric, policy AnomalyPolicy) []models.AnomalyEvent {
+	var (
+		events   []models.AnomalyEvent
+		level    float64
+		variance float64
+		seasonal [24]float64
+		seen     int
+	)
+
+	for _, bucket := range buckets {
+		if bucket.Evaluations < policy.MinEvaluations {
+			continue
+		}
+		value := metric.value(bucket)
+		hour := bucket.Bucket.UTC().Hour()
+
+		if seen == 0 {
+			level = value
+			seen++
+			continue
+		}
+
+		expected := level + seasonal[hour]
+		residual := value - expected
+
+		if seen >= policy.WarmupHours {
+			stdDev := math.Max(math.Sqrt(variance), policy.MinStdDev)
+			z := residual / stdDev
+			if (metric.drop && z <= -policy.ZThreshold) || (!metric.drop && z >= policy.ZThreshold) {
+				severity := "high"
+				if math.Abs(z) >= 2*policy.ZThreshold {
+					severity = "critical"
+				}
+				events = append(events, models.AnomalyEvent{
+					Metric:      metric.name,
+					Bucket:      bucket.Bucket,
+					Value:       value,
+					Expected:    expected,
+					ZScore:      z,
+					Severity:    severity,
+					Evaluations: bucket.Evaluations,
+				})
+			}
+		}
+
+		// Anomalous values still update the baseline so a lasting shift
+		// becomes the new normal instead of alerting every hour
+		level += policy.LevelAlpha * (value - seasonal[hour] - level)
+		seasonal[hour] += policy.SeasonalGamma * (value - level - seasonal[hour])
+		variance = (1 - policy.LevelAlpha) * (variance + policy.LevelAlpha*residual*residual)
+		seen++
+	}
+
+	return events
+}
+
+// AnomalyAlert is an anomaly event as published to the alerting channel
+type AnomalyAlert struct {
+	models.AnomalyEvent
+	Alert string `json:"alert"`
+}
+
+// NewAnomalyAlert describes an anomaly event for alerting
+func NewAnomalyAlert(event models.AnomalyEvent) AnomalyAlert {
+	return AnomalyAlert{
+		AnomalyEvent: event,
+		Alert: fmt.Sprintf("Quality anomaly: %s %.3f vs expected %.3f (z=%.1f, %d evaluations) in hour %s",
+			event.Metric, event.Value, event.Expected, event.ZScore, event.Evaluations, event.Bucket.Format(time.RFC3339)),
+	}
+}
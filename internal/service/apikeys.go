@@ -0,0 +1,82 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+)
+
+// API key scopes
+const (
+	APIKeyScopeIngest = "ingest" // Create and change data, such as conversations and annotations
+	APIKeyScopeRead   = "read"   // Read data and analytics
+	APIKeyScopeAdmin  = "admin"  // Admin endpoints and API key management; implies the other scopes
+)
+
+// APIKeyScopes are the scopes API keys may be granted
+var APIKeyScopes = []string{APIKeyScopeIngest, APIKeyScopeRead, APIKeyScopeAdmin}
+
+// apiKeyPrefix marks API keys so leaked ones are easy to recognise
+const apiKeyPrefix = "ak_"
+
+// apiKeyDisplayLength is how much of a key is kept to identify it
+const apiKeyDisplayLength = 11
+
+// NewAPIKey generates a random API key, the prefix that identifies it and
+// the hash it is stored as
+func NewAPIKey() (key, prefix, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	key = apiKeyPrefix + hex.EncodeToString(buf)
+	return key, key[:apiKeyDisplayLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the stored form of an API key. Keys are random, so an
+// unsalted hash is enough to keep them out of the database.
+func HashAPIKey(key string) string {
+	return HashServiceAccountToken(key)
+}
+
+// ValidateAPIKeyScopes checks that every scope is known
+func ValidateAPIKeyScopes(scopes []string) error {
+	for _, scope := range scopes {
+		known := false
+		for _, s := range APIKeyScopes {
+			known = known || s == scope
+		}
+		if !known {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	return nil
+}
+
+// RequiredAPIKeyScope returns the scope a request to a route needs: admin for
+// admin-only routes, read for reads and ingest otherwise, annotation and
+// review work included
+func RequiredAPIKeyScope(method, route string) string {
+	switch RouteAccess(method, route) {
+	case RouteAccessAdmin:
+		return APIKeyScopeAdmin
+	case RouteAccessRead:
+		return APIKeyScopeRead
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return APIKeyScopeRead
+	}
+	return APIKeyScopeIngest
+}
+
+// APIKeyGrants reports whether scopes include the required scope
+func APIKeyGrants(scopes []string, required string) bool {
+	for _, scope := range scopes {
+		if scope == required || scope == APIKeyScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// suggestionSource names where annotation suggestions come from
+const suggestionSource = "automated_evaluation"
+
+// ParseAssistedLabeling reads a project's assisted labeling settings.
+// Projects without settings don't use assisted labeling.
+func ParseAssistedLabeling(raw json.RawMessage) models.AssistedLabeling {
+	var settings models.AssistedLabeling
+	if len(raw) > 0 {
+		json.Unmarshal(raw, &settings)
+	}
+	return settings
+}
+
+// ValidateAssistedLabeling checks assisted labeling settings
+func ValidateAssistedLabeling(settings models.AssistedLabeling) error {
+	if settings.AssistedFraction < 0 || settings.AssistedFraction > 1 {
+		return errors.New("assisted_fraction must be between 0 and 1")
+	}
+	if settings.Enabled && settings.AssistedFraction == 0 {
+		return errors.New("assisted_fraction must be above 0 when assisted labeling is enabled")
+	}
+	return nil
+}
+
+// AssistedArm decides whether an annotator's form for a conversation is
+// pre-filled. The split is a hash of the pair rather than a coin flip, so
+// the form and the annotation submitted from it fall in the same arm, and
+// each annotator works in both arms across conversations.
+func AssistedArm(settings models.AssistedLabeling, conversationID, annotatorID string) bool {
+	if !settings.Enabled {
+		return false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(conversationID))
+	h.Write([]byte{0})
+	h.Write([]byte(annotatorID))
+	return float64(h.Sum64()%10000)/10000 < settings.AssistedFraction
+}
+
+// NewAnnotationSuggestion builds the machine suggestion of an evaluation:
+// the types of the issues it found as labels, its overall score, and the
+// issues themselves for forms to highlight
+func NewAnnotationSuggestion(eval *models.Evaluation) *models.AnnotationSuggestion {
+	resp := models.NewEvaluationResponse(eval)
+	issues := resp.IssuesDetected
+	if issues == nil {
+		issues = []models.IssueDetected{}
+	}
+	return &models.AnnotationSuggestion{
+		MachineSuggested: true,
+		Source:           suggestionSource,
+		EvaluationID:     resp.EvaluationID,
+		EvaluatorVersion: resp.EvaluatorVersion,
+		Labels:           issueTypes(issues),
+		Score:            resp.Scores.Overall,
+		Issues:           issues,
+	}
+}
+
+// CompareAssistedLabeling measures how far the annotations of each arm of
+// assisted labeling agree with the machine suggestion, overall and by
+// annotation type. Annotations without an arm or a suggestion are skipped.
+func CompareAssistedLabeling(projectID string, since time.Time, settings models.AssistedLabeling, anns []models.Annotation) models.AssistedLabelingReport {
+	report := models.AssistedLabelingReport{
+		ProjectID: projectID,
+		Since:     since,
+		Settings:  settings,
+		ByType:    make(map[string]models.AssistedLabelingBias),
+	}
+
+	var all []models.Annotation
+	byType := make(map[string][]models.Annotation)
+	for _, ann := range anns {
+		if !ann.Assisted.Valid || len(ann.SuggestedLabels) == 0 {
+			continue
+		}
+		all = append(all, ann)
+		byType[ann.AnnotationType] = append(byType[ann.AnnotationType], ann)
+	}
+	report.Assisted, report.Control, report.Bias = compareArms(all)
+	for annotationType, typeAnns := range byType {
+		var bias models.AssistedLabelingBias
+		bias.Assisted, bias.Control, bias.Bias = compareArms(typeAnns)
+		report.ByType[annotationType] = bias
+	}
+	return report
+}
+
+// compareArms summarizes both arms and the difference in their agreement
+// with the suggestion. The difference is 0 until both arms have annotations.
+func compareArms(anns []models.Annotation) (assisted, control models.AssistedLabelingArm, bias float64) {
+	var assistedAnns, controlAnns []models.Annotation
+	for _, ann := range anns {
+		if ann.Assisted.Bool {
+			assistedAnns = append(assistedAnns, ann)
+		} else {
+			controlAnns = append(controlAnns, ann)
+		}
+	}
+	assisted, control = summarizeArm(assistedAnns), summarizeArm(controlAnns)
+	if assisted.Annotations > 0 && control.Annotations > 0 {
+		bias = assisted.SuggestionAgreement - control.SuggestionAgreement
+	}
+	return assisted, control, bias
+}
+
+// summarizeArm averages the agreement with the suggestion of an arm's
+// annotations. Labels are expanded with their ancestors, as for agreement
+// between annotators.
+func summarizeArm(anns []models.Annotation) models.AssistedLabelingArm {
+	arm := models.AssistedLabelingArm{Annotations: len(anns)}
+	if len(anns) == 0 {
+		return arm
+	}
+
+	var agreement, exact, timeSpent float64
+	for _, ann := range anns {
+		var suggestedLabels []string
+		json.Unmarshal(ann.SuggestedLabels, &suggestedLabels)
+		labels, suggested := expandLabels(AnnotationLabels(ann)), expandLabels(suggestedLabels)
+		similarity := jaccard(labels, suggested)
+		agreement += similarity
+		if similarity == 1 {
+			exact++
+		}
+		timeSpent += float64(ann.TimeSpentSeconds.Int32)
+	}
+	n := float64(len(anns))
+	arm.SuggestionAgreement = agreement / n
+	arm.ExactMatchRate = exact / n
+	arm.MeanTimeSpentSeconds = timeSpent / n
+	return arm
+}
+
+// expandLabels returns the set of labels and their ancestor paths
+func expandLabels(labels []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, label := range labels {
+		for _, path := range labelAncestors(label) {
+			set[path] = true
+		}
+	}
+	return set
+}
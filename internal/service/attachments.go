@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/blobstore"
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// AttachmentBlobScheme prefixes the URL of attachments whose content is in
+// the attachment blob store; the rest of the URL is the content's SHA-256
+const AttachmentBlobScheme = "blob://"
+
+// attachmentURLSchemes are the schemes an attachment's URL may point to
+var attachmentURLSchemes = map[string]bool{
+	"http": true, "https": true, "s3": true, "gs": true, "blob": true,
+}
+
+// AttachmentStore validates turn attachments at ingestion and keeps their
+// inline content in a blob store. Without a blob store inline content is
+// validated and then dropped, keeping only its metadata.
+type AttachmentStore struct {
+	blobs    blobstore.Store
+	maxBytes int64
+}
+
+// NewAttachmentStore creates an attachment store limiting attachments to
+// maxBytes
+func NewAttachmentStore(blobs blobstore.Store, maxBytes int64) *AttachmentStore {
+	return &AttachmentStore{blobs: blobs, maxBytes: maxBytes}
+}
+
+// Validate checks every attachment of the turns: a URL must use a supported
+// scheme, inline data must decode and fit the size limit, and a declared
+// size, hash or media type must match the content
+func (a *AttachmentStore) Validate(turns []models.Turn) error {
+	for _, turn := range turns {
+		for i, attachment := range turn.Attachments {
+			if err := a.validate(&attachment); err != nil {
+				return fmt.Errorf("turn %d: attachment %d: %w", turn.TurnID, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (a *AttachmentStore) validate(attachment *models.Attachment) error {
+	if attachment.SHA256 != "" && !isSHA256(attachment.SHA256) {
+		return fmt.Errorf("sha256 must be a hex SHA-256 digest")
+	}
+	if attachment.SizeBytes < 0 {
+		return fmt.Errorf("size_bytes must not be negative")
+	}
+	if a.maxBytes > 0 && attachment.SizeBytes > a.maxBytes {
+		return fmt.Errorf("attachment is %d bytes, over the %d byte limit", attachment.SizeBytes, a.maxBytes)
+	}
+
+	if attachment.Data == "" {
+		if attachment.URL == "" {
+			return nil
+		}
+		return validateAttachmentURL(attachment.URL)
+	}
+
+	if attachment.URL != "" {
+		return fmt.Errorf("attachment may have url or data, not both")
+	}
+	content, err := base64.StdEncoding.DecodeString(attachment.Data)
+	if err != nil {
+		return fmt.Errorf("data is not valid base64: %w", err)
+	}
+	if a.maxBytes > 0 && int64(len(content)) > a.maxBytes {
+		return fmt.Errorf("attachment is %d bytes, over the %d byte limit", len(content), a.maxBytes)
+	}
+	if attachment.SizeBytes != 0 && attachment.SizeBytes != int64(len(content)) {
+		return fmt.Errorf("size_bytes is %d but data is %d bytes", attachment.SizeBytes, len(content))
+	}
+	if attachment.SHA256 != "" && !strings.EqualFold(attachment.SHA256, sha256Hex(content)) {
+		return fmt.Errorf("sha256 does not match data")
+	}
+	if sniffed := http.DetectContentType(content); !attachmentTypeMatches(attachment.Type, sniffed) {
+		return fmt.Errorf("data looks like %s, not %s content", sniffed, attachment.Type)
+	}
+	return nil
+}
+
+// Store moves the inline content of validated attachments into the blob
+// store. Each attachment with inline content gets the content's size, hash
+// and sniffed media type if none was given, and a blob:// URL in place of its
+// data once stored.
+func (a *AttachmentStore) Store(ctx context.Context, turns []models.Turn) error {
+	for t := range turns {
+		for i := range turns[t].Attachments {
+			attachment := &turns[t].Attachments[i]
+			if attachment.Data == "" {
+				continue
+			}
+
+			content, err := base64.StdEncoding.DecodeString(attachment.Data)
+			if err != nil {
+				return fmt.Errorf("turn %d: attachment %d: data is not valid base64: %w", turns[t].TurnID, i, err)
+			}
+			if attachment.MimeType == "" {
+				attachment.MimeType = http.DetectContentType(content)
+			}
+			attachment.SHA256 = sha256Hex(content)
+			attachment.SizeBytes = int64(len(content))
+			attachment.Data = ""
+			if a.blobs == nil {
+				continue
+			}
+
+			if err := a.blobs.Put(ctx, attachment.SHA256, attachment.MimeType, content); err != nil {
+				return fmt.Errorf("failed to store attachment: %w", err)
+			}
+			attachment.URL = AttachmentBlobScheme + attachment.SHA256
+		}
+	}
+	return nil
+}
+
+// Get returns a stored attachment's content and media type
+func (a *AttachmentStore) Get(ctx context.Context, hash string) ([]byte, string, error) {
+	if a.blobs == nil || !isSHA256(hash) {
+		return nil, "", blobstore.ErrNotFound
+	}
+	return a.blobs.Get(ctx, strings.ToLower(hash))
+}
+
+// Delete removes a stored attachment. It does nothing without a blob store.
+func (a *AttachmentStore) Delete(ctx context.Context, hash string) error {
+	if a.blobs == nil || !isSHA256(hash) {
+		return nil
+	}
+	return a.blobs.Delete(ctx, strings.ToLower(hash))
+}
+
+// validateAttachmentURL checks an attachment's URL uses a supported scheme.
+// blob:// URLs must name a content hash.
+func validateAttachmentURL(raw string) error {
+	if strings.HasPrefix(raw, AttachmentBlobScheme) {
+		if !isSHA256(strings.TrimPrefix(raw, AttachmentBlobScheme)) {
+			return fmt.Errorf("blob url must name a hex SHA-256 digest")
+		}
+		return nil
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || !attachmentURLSchemes[parsed.Scheme] || parsed.Host == "" {
+		return fmt.Errorf("url must be an http, https, s3, gs or blob url")
+	}
+	return nil
+}
+
+// attachmentTypeMatches reports whether sniffed content fits the declared
+// attachment type. Images are always recognized by sniffing; audio and video
+// only sometimes, and containers like MP4 hold either, so they are refused
+// only when the content is an image or text.
+func attachmentTypeMatches(attachmentType, sniffed string) bool {
+	medium := strings.SplitN(sniffed, "/", 2)[0]
+	switch attachmentType {
+	case "image":
+		return medium == "image"
+	case "audio", "video":
+		return medium != "image" && medium != "text"
+	}
+	return true
+}
+
+// EvaluatorAttachments maps attachments to the references passed to
+// multimodal evaluators. Stored attachments get a URL the evaluator can
+// fetch them from under baseURL, and content is never inlined.
+func EvaluatorAttachments(attachments []models.Attachment, baseURL string) []models.Attachment {
+	refs := make([]models.Attachment, len(attachments))
+	for i, attachment := range attachments {
+		attachment.Data = ""
+		if strings.HasPrefix(attachment.URL, AttachmentBlobScheme) {
+			attachment.URL = strings.TrimRight(baseURL, "/") + "/api/v1/attachments/" + strings.TrimPrefix(attachment.URL, AttachmentBlobScheme)
+		}
+		refs[i] = attachment
+	}
+	return refs
+}
+
+func isSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SetAttachmentBaseURL sets the API base URL multimodal evaluators fetch
+// stored attachments from
+func (s *EvaluatorService) SetAttachmentBaseURL(baseURL string) {
+	s.attachmentBaseURL = baseURL
+}
+
+// withAttachmentReferences returns the request with its turns' attachments
+// mapped to evaluator references if the evaluators judge multimodal content,
+// or left out if they don't. The request is copied only if it has
+// attachments.
+func (s *EvaluatorService) withAttachmentReferences(req *EvaluationRequest, multimodal bool) *EvaluationRequest {
+	var turns []map[string]interface{}
+	for i, entry := range req.Turns {
+		attachments, ok := entry["attachments"].([]models.Attachment)
+		if !ok {
+			continue
+		}
+		if turns == nil {
+			turns = append([]map[string]interface{}(nil), req.Turns...)
+		}
+
+		copied := make(map[string]interface{}, len(entry))
+		for key, value := range entry {
+			copied[key] = value
+		}
+		if multimodal {
+			copied["attachments"] = EvaluatorAttachments(attachments, s.attachmentBaseURL)
+		} else {
+			delete(copied, "attachments")
+		}
+		turns[i] = copied
+	}
+	if turns == nil {
+		return req
+	}
+
+	out := *req
+	out.Turns = turns
+	return &out
+}
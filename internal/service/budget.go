@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// DefaultEvaluationBudget is unlimited, but estimates each evaluator's cost
+// relative to the LLM judge and runs the cheapest evaluators first, so setting
+// a maximum cost or an early exit is enough to start saving
+var DefaultEvaluationBudget = models.EvaluationBudget{
+	Costs: map[string]float64{
+		"heuristic": 0.01,
+		"tool_call": 0.1,
+		"coherence": 0.5,
+		"llm_judge": 1,
+	},
+	Order: []string{"heuristic", "tool_call", "coherence", "llm_judge"},
+}
+
+// BudgetActive reports whether a budget changes how evaluators run
+func BudgetActive(budget *models.EvaluationBudget) bool {
+	return budget != nil && (budget.MaxCost > 0 || budget.EarlyExitScore > 0 || budget.EarlyExitOnCritical)
+}
+
+// BudgetOrder orders evaluator types by the budget's order, keeping the
+// requested order of types it doesn't list
+func BudgetOrder(evaluatorTypes []string, order []string) []string {
+	requested := make(map[string]bool, len(evaluatorTypes))
+	for _, evaluatorType := range evaluatorTypes {
+		requested[evaluatorType] = true
+	}
+
+	ordered := make([]string, 0, len(evaluatorTypes))
+	listed := make(map[string]bool, len(order))
+	for _, evaluatorType := range order {
+		if requested[evaluatorType] && !listed[evaluatorType] {
+			ordered = append(ordered, evaluatorType)
+		}
+		listed[evaluatorType] = true
+	}
+	for _, evaluatorType := range evaluatorTypes {
+		if !listed[evaluatorType] {
+			ordered = append(ordered, evaluatorType)
+		}
+	}
+	return ordered
+}
+
+// EvaluateBudgeted evaluates a conversation one evaluator type at a time in
+// the budget's order. Types whose cost would take the total past the
+// budget's maximum are skipped, and once an evaluator reaches a confident
+// failing verdict the remaining types are skipped too. Skipped types are
+// reported in SkippedEvaluators and the estimated cost of the types that ran,
+// including any that failed, in Cost. Inactive budgets evaluate as
+// EvaluateContext does.
+func (s *EvaluatorService) EvaluateBudgeted(ctx context.Context, req *EvaluationRequest, timeouts map[string]time.Duration, budget *models.EvaluationBudget) (*EvaluationResult, error) {
+	if !BudgetActive(budget) || len(req.EvaluatorTypes) == 0 {
+		return s.EvaluateContext(ctx, req, timeouts)
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultEvaluationTimeout)
+		defer cancel()
+	}
+
+	segments := s.segments.split(req.Turns)
+	var ran []string
+	var results []*EvaluationResult
+	var errs []error
+	var skipped []models.SkippedEvaluator
+	var spent float64
+	var exitDetail string
+	for _, evaluatorType := range BudgetOrder(req.EvaluatorTypes, budget.Order) {
+		if exitDetail != "" {
+			skipped = append(skipped, models.SkippedEvaluator{
+				EvaluatorType: evaluatorType,
+				Reason:        models.SkipReasonEarlyExit,
+				Detail:        exitDetail,
+			})
+			continue
+		}
+
+		cost := budget.Costs[evaluatorType]
+		if budget.MaxCost > 0 && spent+cost > budget.MaxCost {
+			skipped = append(skipped, models.SkippedEvaluator{
+				EvaluatorType: evaluatorType,
+				Reason:        models.SkipReasonBudget,
+				Detail:        fmt.Sprintf("costs %g with %g of the %g budget left", cost, budget.MaxCost-spent, budget.MaxCost),
+			})
+			continue
+		}
+
+		spent += cost
+		result, err := s.evaluateBudgetedType(ctx, req, evaluatorType, segments, timeouts[evaluatorType])
+		ran = append(ran, evaluatorType)
+		results = append(results, result)
+		errs = append(errs, err)
+		if err == nil {
+			exitDetail = earlyExitDetail(budget, evaluatorType, result)
+		}
+	}
+	if len(ran) == 0 {
+		return nil, fmt.Errorf("no evaluator fits within the budget of %g", budget.MaxCost)
+	}
+
+	ranReq := *req
+	ranReq.EvaluatorTypes = ran
+	merged, err := mergeComponents(&ranReq, results, errs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The evaluators ran one after another
+	merged.EvaluationDurationMS = 0
+	for _, result := range results {
+		if result != nil {
+			merged.EvaluationDurationMS += result.EvaluationDurationMS
+		}
+	}
+	merged.SkippedEvaluators = skipped
+	merged.Cost = spent
+
+	if err := s.addToolIssues(req, merged); err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// evaluateBudgetedType evaluates a request with a single evaluator type
+// within its timeout, in segments when the conversation is too long
+func (s *EvaluatorService) evaluateBudgetedType(ctx context.Context, req *EvaluationRequest, evaluatorType string, segments []segment, timeout time.Duration) (*EvaluationResult, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	typeReq := *req
+	typeReq.EvaluatorTypes = []string{evaluatorType}
+	typeReq.EvaluatorVersions = nil
+	if version, ok := req.EvaluatorVersions[evaluatorType]; ok {
+		typeReq.EvaluatorVersions = map[string]string{evaluatorType: version}
+	}
+
+	if len(segments) > 1 {
+		return s.evaluateSegmented(ctx, &typeReq, segments, nil)
+	}
+	return s.evaluateType(ctx, &typeReq)
+}
+
+// earlyExitDetail explains why an evaluator's result is a confident failing
+// verdict, or returns an empty string if it isn't
+func earlyExitDetail(budget *models.EvaluationBudget, evaluatorType string, result *EvaluationResult) string {
+	if score, ok := result.Scores["overall"]; ok && budget.EarlyExitScore > 0 && score <= budget.EarlyExitScore {
+		return fmt.Sprintf("%s scored %g, at or below the early exit score of %g", evaluatorType, score, budget.EarlyExitScore)
+	}
+	if budget.EarlyExitOnCritical {
+		for _, issue := range result.IssuesDetected {
+			if issue["severity"] == "critical" {
+				return fmt.Sprintf("%s reported a critical issue", evaluatorType)
+			}
+		}
+	}
+	return ""
+}
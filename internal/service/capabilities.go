@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Evaluator warning codes
+const (
+	WarningUnknownEvaluator = "unknown_evaluator"
+	WarningMissingAPIKey    = "missing_api_key"
+)
+
+// EvaluatorCapability describes an evaluator registered with the evaluator service
+type EvaluatorCapability struct {
+	Type        string
+	RequiresLLM bool
+	Multimodal  bool // Judges turn attachments as well as text
+}
+
+// evaluatorCapabilities lists the evaluators the evaluator service provides
+var evaluatorCapabilities = map[string]EvaluatorCapability{
+	"llm_judge": {Type: "llm_judge", RequiresLLM: true, Multimodal: true},
+	"tool_call": {Type: "tool_call"},
+	"coherence": {Type: "coherence"},
+	"heuristic": {Type: "heuristic"},
+}
+
+// MultimodalRequested reports whether any of the requested evaluator service
+// evaluators judges attachments. No types means the service's defaults, which
+// may.
+func MultimodalRequested(types []string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, evaluatorType := range types {
+		if evaluatorCapabilities[evaluatorType].Multimodal {
+			return true
+		}
+	}
+	return false
+}
+
+// HasLLMCredentials reports whether an API key is configured for the LLM
+// provider. A key for another provider doesn't count, nor does any key when
+// the provider is unknown.
+func HasLLMCredentials(cfg *config.Config) bool {
+	switch cfg.LLMProvider {
+	case "openai":
+		return cfg.OpenAIAPIKey != ""
+	case "anthropic":
+		return cfg.AnthropicAPIKey != ""
+	default:
+		return false
+	}
+}
+
+// CheckEvaluatorTypes splits requested evaluators into those that can run
+// and warnings for those that cannot
+func CheckEvaluatorTypes(types []string, hasLLMCredentials bool) ([]string, []models.EvaluatorWarning) {
+	accepted := make([]string, 0, len(types))
+	warnings := []models.EvaluatorWarning{}
+	seen := make(map[string]bool)
+
+	for _, evaluatorType := range types {
+		if seen[evaluatorType] {
+			continue
+		}
+		seen[evaluatorType] = true
+
+		capability, ok := evaluatorCapabilities[evaluatorType]
+		if _, registered := Evaluators.Lookup(evaluatorType); !ok && registered {
+			accepted = append(accepted, evaluatorType)
+			continue
+		}
+		if !ok {
+			warnings = append(warnings, models.EvaluatorWarning{
+				EvaluatorType: evaluatorType,
+				Code:          WarningUnknownEvaluator,
+				Message:       fmt.Sprintf("evaluator %q is not registered", evaluatorType),
+			})
+			continue
+		}
+
+		if capability.RequiresLLM && !hasLLMCredentials {
+			warnings = append(warnings, models.EvaluatorWarning{
+				EvaluatorType: evaluatorType,
+				Code:          WarningMissingAPIKey,
+				Message:       fmt.Sprintf("evaluator %q requires an LLM API key", evaluatorType),
+			})
+			continue
+		}
+
+		accepted = append(accepted, evaluatorType)
+	}
+
+	return accepted, warnings
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// mentionPattern matches @name mentions that start a word, so email
+// addresses aren't taken for mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@(\w[\w.-]*)`)
+
+// ParseMentions returns the names mentioned with @name in a comment body,
+// lowercased, in order of first mention and without repeats
+func ParseMentions(body string) []string {
+	mentions := []string{}
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(strings.TrimRight(match[1], ".-")) // Drop sentence punctuation
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		mentions = append(mentions, name)
+	}
+	return mentions
+}
+
+// CommentThreads groups a conversation's comments, oldest first, into
+// threads and keeps those the filter selects. Replies whose thread's
+// comment is missing are left out.
+func CommentThreads(comments []models.Comment, filter models.CommentFilter) []models.CommentThread {
+	threads := []models.CommentThread{}
+	index := make(map[int64]int)
+	for _, comment := range comments {
+		if comment.ParentID != nil {
+			continue
+		}
+		if !commentMatches(comment, filter) {
+			continue
+		}
+		index[comment.ID] = len(threads)
+		threads = append(threads, models.CommentThread{Comment: comment, Replies: []models.Comment{}})
+	}
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			continue
+		}
+		if i, ok := index[*comment.ParentID]; ok {
+			threads[i].Replies = append(threads[i].Replies, comment)
+		}
+	}
+	return threads
+}
+
+func commentMatches(comment models.Comment, filter models.CommentFilter) bool {
+	if filter.TurnID != nil && (comment.TurnID == nil || *comment.TurnID != *filter.TurnID) {
+		return false
+	}
+	if filter.EvaluationID != "" && (comment.EvaluationID == nil || *comment.EvaluationID != filter.EvaluationID) {
+		return false
+	}
+	if filter.Resolved != nil && comment.Resolved != *filter.Resolved {
+		return false
+	}
+	return true
+}
+
+// CommentMentions decodes the names a stored comment mentions
+func CommentMentions(comment *models.Comment) []string {
+	var mentions []string
+	_ = json.Unmarshal(comment.Mentions, &mentions)
+	return mentions
+}
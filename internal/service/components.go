@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// evaluatorComponents maps evaluator types to the score they produce and its
+// weight in the overall score, mirroring the Python orchestrator
+var evaluatorComponents = map[string]struct {
+	score  string
+	weight float64
+}{
+	"llm_judge": {"response_quality", 0.3},
+	"tool_call": {"tool_accuracy", 0.3},
+	"coherence": {"coherence", 0.2},
+	"heuristic": {"heuristic", 0.2},
+}
+
+// evaluateComponents evaluates a request, calling the Python service once per
+// evaluator type when any type has a timeout so a slow or failing evaluator
+// only loses its own component. Requests with registered evaluator types are
+// split the same way, so those types go to their own evaluators.
+func (s *EvaluatorService) evaluateComponents(ctx context.Context, req *EvaluationRequest, timeouts map[string]time.Duration) (*EvaluationResult, error) {
+	if len(req.EvaluatorTypes) == 0 || (len(timeouts) == 0 && !Evaluators.includesAny(req.EvaluatorTypes)) {
+		return s.evaluateRequest(ctx, req)
+	}
+
+	results := make([]*EvaluationResult, len(req.EvaluatorTypes))
+	errs := make([]error, len(req.EvaluatorTypes))
+	var wg sync.WaitGroup
+	for i, evaluatorType := range req.EvaluatorTypes {
+		wg.Add(1)
+		go func(i int, evaluatorType string) {
+			defer wg.Done()
+
+			typeCtx := ctx
+			if timeout, ok := timeouts[evaluatorType]; ok {
+				var cancel context.CancelFunc
+				typeCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			typeReq := *req
+			typeReq.EvaluatorTypes = []string{evaluatorType}
+			if version, ok := req.EvaluatorVersions[evaluatorType]; ok {
+				typeReq.EvaluatorVersions = map[string]string{evaluatorType: version}
+			}
+			results[i], errs[i] = s.evaluateType(typeCtx, &typeReq)
+		}(i, evaluatorType)
+	}
+	wg.Wait()
+
+	return mergeComponents(req, results, errs)
+}
+
+// mergeComponents combines single evaluator results into one, recomputing the
+// overall score from the components that succeeded
+func mergeComponents(req *EvaluationRequest, results []*EvaluationResult, errs []error) (*EvaluationResult, error) {
+	merged := &EvaluationResult{
+		ConversationID: req.ConversationID,
+		Scores:         make(map[string]float64),
+	}
+
+	var overall, totalWeight float64
+	var messages []string
+	for i, evaluatorType := range req.EvaluatorTypes {
+		if errs[i] != nil {
+			merged.FailedEvaluators = append(merged.FailedEvaluators, models.EvaluatorFailure{
+				EvaluatorType: evaluatorType,
+				Error:         errs[i].Error(),
+				TimedOut:      errors.Is(errs[i], context.DeadlineExceeded),
+			})
+			messages = append(messages, fmt.Sprintf("%s: %v", evaluatorType, errs[i]))
+			continue
+		}
+
+		result := results[i]
+		if merged.EvaluationID == "" {
+			merged.EvaluationID = result.EvaluationID
+			merged.EvaluatorVersion = result.EvaluatorVersion
+		}
+		if result.EvaluationDurationMS > merged.EvaluationDurationMS {
+			merged.EvaluationDurationMS = result.EvaluationDurationMS // The calls ran in parallel
+		}
+
+		// A single evaluator's overall score is its component score
+		component, ok := evaluatorComponents[evaluatorType]
+		if ok {
+			score := result.Scores["overall"]
+			merged.Scores[component.score] = score
+			overall += score * component.weight
+			totalWeight += component.weight
+		} else if score, ok := result.Scores["overall"]; ok {
+			merged.Scores[evaluatorType] = score // Custom evaluators don't count toward the overall score
+		}
+		if evaluatorType == "tool_call" {
+			merged.ToolEvaluation = result.ToolEvaluation
+		}
+		merged.IssuesDetected = append(merged.IssuesDetected, result.IssuesDetected...)
+		merged.ImprovementSuggestions = append(merged.ImprovementSuggestions, result.ImprovementSuggestions...)
+	}
+
+	if len(merged.FailedEvaluators) == len(req.EvaluatorTypes) {
+		return nil, fmt.Errorf("all evaluators failed: %s", strings.Join(messages, "; "))
+	}
+	if totalWeight > 0 {
+		merged.Scores["overall"] = math.Round(overall/totalWeight*1000) / 1000
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// TokenPrices prices the tokens of LLM calls in USD per million tokens, by
+// model name or name prefix; the longest matching prefix wins. Models
+// without an output price are charged their input price for output tokens.
+type TokenPrices struct {
+	Input  map[string]float64
+	Output map[string]float64
+}
+
+// Cost returns what a model charges for input and output tokens. ok is
+// false when the model has no price.
+func (p TokenPrices) Cost(model string, inputTokens, outputTokens int) (cost float64, ok bool) {
+	inputPrice, ok := modelPrice(p.Input, model)
+	outputPrice, hasOutput := modelPrice(p.Output, model)
+	switch {
+	case !ok && !hasOutput:
+		return 0, false
+	case !hasOutput:
+		outputPrice = inputPrice
+	case !ok:
+		if inputTokens > 0 {
+			return 0, false
+		}
+	}
+	return (float64(inputTokens)*inputPrice + float64(outputTokens)*outputPrice) / 1e6, true
+}
+
+// ConversationCost returns what running the agent cost for a conversation.
+// Token usage the agent reported for its turns is priced by each turn's
+// model, or the conversation's; reported totals in its metadata come next,
+// and failing both the estimated tokens are priced as input. Nil when a model
+// involved has no price.
+func ConversationCost(turns []models.Turn, metadata models.ConversationMetadata, counts *models.TokenCounts, prices TokenPrices) *float64 {
+	var cost float64
+	reported := false
+	for _, turn := range turns {
+		if turn.InputTokens == 0 && turn.OutputTokens == 0 {
+			continue
+		}
+		reported = true
+		model := turn.Model
+		if model == "" {
+			model = metadata.Model
+		}
+		turnCost, ok := prices.Cost(model, turn.InputTokens, turn.OutputTokens)
+		if !ok {
+			return nil
+		}
+		cost += turnCost
+	}
+	if reported {
+		return &cost
+	}
+
+	var ok bool
+	if metadata.InputTokens > 0 || metadata.OutputTokens > 0 {
+		cost, ok = prices.Cost(metadata.Model, metadata.InputTokens, metadata.OutputTokens)
+	} else if counts != nil {
+		cost, ok = prices.Cost(metadata.Model, counts.Total, 0)
+	}
+	if !ok {
+		return nil
+	}
+	return &cost
+}
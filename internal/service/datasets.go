@@ -0,0 +1,144 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// DefaultRegressionThreshold is how far an item's score may drop between
+// dataset runs before the item counts as regressed
+const DefaultRegressionThreshold = 0.05
+
+// verdictRank orders verdicts from best to worst
+var verdictRank = map[string]int{
+	models.VerdictPass:        1,
+	models.VerdictNeedsReview: 2,
+	models.VerdictFail:        3,
+}
+
+// ValidateDatasetItems checks that a dataset's item keys are unique and its
+// items' metadata are JSON objects
+func ValidateDatasetItems(items []models.DatasetItem) error {
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if seen[item.ItemKey] {
+			return fmt.Errorf("duplicate item_key %q", item.ItemKey)
+		}
+		seen[item.ItemKey] = true
+		if len(item.Metadata) == 0 {
+			continue
+		}
+		var metadata map[string]interface{}
+		if err := json.Unmarshal(item.Metadata, &metadata); err != nil || metadata == nil {
+			return fmt.Errorf("item %s: metadata must be a JSON object", item.ItemKey)
+		}
+	}
+	return nil
+}
+
+// CompareDatasetRuns compares the items of a candidate run of a dataset to
+// those of a base run. Only evaluated items are compared; items evaluated
+// in one run only are reported as added or removed.
+func CompareDatasetRuns(base, candidate *models.DatasetRun, threshold float64) models.DatasetRunComparison {
+	comparison := models.DatasetRunComparison{
+		Base:        *base,
+		Candidate:   *candidate,
+		Threshold:   threshold,
+		ScoreDeltas: make(map[string]float64),
+		Items:       []models.DatasetItemChange{},
+	}
+	comparison.Base.Items = nil
+	comparison.Candidate.Items = nil
+
+	var baseMeans, candidateMeans map[string]float64
+	json.Unmarshal(base.MeanScores, &baseMeans)
+	json.Unmarshal(candidate.MeanScores, &candidateMeans)
+	for score, mean := range candidateMeans {
+		if baseMean, ok := baseMeans[score]; ok {
+			comparison.ScoreDeltas[score] = mean - baseMean
+		}
+	}
+	if base.PassRate != nil && candidate.PassRate != nil {
+		delta := *candidate.PassRate - *base.PassRate
+		comparison.PassRateDelta = &delta
+	}
+
+	baseItems := make(map[string]models.DatasetRunItem, len(base.Items))
+	for _, item := range base.Items {
+		if item.Status == models.DatasetRunItemEvaluated {
+			baseItems[item.ItemKey] = item
+		}
+	}
+	compared := make(map[string]bool, len(candidate.Items))
+	for _, item := range candidate.Items {
+		if item.Status != models.DatasetRunItemEvaluated {
+			continue
+		}
+		compared[item.ItemKey] = true
+
+		change := models.DatasetItemChange{
+			ItemKey:          item.ItemKey,
+			CandidateScore:   item.OverallScore,
+			CandidateVerdict: item.Verdict,
+			Change:           models.DatasetItemAdded,
+		}
+		if prev, ok := baseItems[item.ItemKey]; ok {
+			change.BaseScore = prev.OverallScore
+			change.BaseVerdict = prev.Verdict
+			change.Change = datasetItemChange(prev, item, threshold)
+			if prev.OverallScore != nil && item.OverallScore != nil {
+				delta := *item.OverallScore - *prev.OverallScore
+				change.Delta = &delta
+			}
+		}
+		comparison.Items = append(comparison.Items, change)
+	}
+	for _, item := range base.Items {
+		if item.Status != models.DatasetRunItemEvaluated || compared[item.ItemKey] {
+			continue
+		}
+		comparison.Items = append(comparison.Items, models.DatasetItemChange{
+			ItemKey:     item.ItemKey,
+			BaseScore:   item.OverallScore,
+			BaseVerdict: item.Verdict,
+			Change:      models.DatasetItemRemoved,
+		})
+	}
+
+	for _, change := range comparison.Items {
+		switch change.Change {
+		case models.DatasetItemRegressed:
+			comparison.Regressions++
+		case models.DatasetItemImproved:
+			comparison.Improvements++
+		}
+	}
+
+	return comparison
+}
+
+// datasetItemChange decides how an item evaluated in both runs changed. A
+// worse verdict is a regression even if the score held up.
+func datasetItemChange(base, candidate models.DatasetRunItem, threshold float64) string {
+	baseRank, candidateRank := verdictRank[base.Verdict], verdictRank[candidate.Verdict]
+	if baseRank > 0 && candidateRank > 0 && candidateRank != baseRank {
+		if candidateRank > baseRank {
+			return models.DatasetItemRegressed
+		}
+		return models.DatasetItemImproved
+	}
+
+	if base.OverallScore == nil || candidate.OverallScore == nil {
+		return models.DatasetItemUnchanged
+	}
+	delta := *candidate.OverallScore - *base.OverallScore
+	switch {
+	case delta < -threshold:
+		return models.DatasetItemRegressed
+	case delta > threshold:
+		return models.DatasetItemImproved
+	}
+	return models.DatasetItemUnchanged
+}
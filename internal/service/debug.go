@@ -0,0 +1,68 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// DebugEvaluatorTypes are the evaluators fast enough to give feedback after
+// every turn of a debugging session
+var DebugEvaluatorTypes = []string{"heuristic", "coherence"}
+
+// DebugSession evaluates a conversation turn by turn while a developer
+// tests an agent, without storing anything. Each turn is evaluated with the
+// conversation so far, and only issues that weren't reported after an
+// earlier turn are reported again.
+type DebugSession struct {
+	conversationID string
+	turns          []models.Turn
+	reported       map[string]bool
+}
+
+// NewDebugSession starts a debugging session for a conversation
+func NewDebugSession(conversationID string) *DebugSession {
+	return &DebugSession{
+		conversationID: conversationID,
+		reported:       make(map[string]bool),
+	}
+}
+
+// Add appends a turn, numbering it if it has no turn ID, and returns the
+// request evaluating the conversation so far. Invalid turns are not added.
+func (d *DebugSession) Add(turn models.Turn) (models.Turn, *EvaluationRequest, error) {
+	if turn.TurnID == 0 {
+		turn.TurnID = len(d.turns) + 1
+	}
+	if err := ValidateTurns([]models.Turn{turn}); err != nil {
+		return turn, nil, err
+	}
+	d.turns = append(d.turns, turn)
+
+	return turn, &EvaluationRequest{
+		ConversationID: d.conversationID,
+		Turns:          EvaluatorTurns(d.turns),
+		Metadata:       map[string]interface{}{},
+		EvaluatorTypes: DebugEvaluatorTypes,
+	}, nil
+}
+
+// Feedback reports the scores of an evaluation after a turn, with the issues
+// it found that no earlier evaluation did
+func (d *DebugSession) Feedback(turnID int, result *EvaluationResult) models.DebugTurnFeedback {
+	feedback := models.DebugTurnFeedback{
+		TurnID:           turnID,
+		Scores:           result.Scores,
+		NewIssues:        []map[string]interface{}{},
+		FailedEvaluators: result.FailedEvaluators,
+		DurationMS:       result.EvaluationDurationMS,
+	}
+	for _, issue := range result.IssuesDetected {
+		key := fmt.Sprint(issue["type"], "|", issue["severity"], "|", issue["turn_id"], "|", issue["description"])
+		if !d.reported[key] {
+			d.reported[key] = true
+			feedback.NewIssues = append(feedback.NewIssues, issue)
+		}
+	}
+	return feedback
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// issueSimilarityThreshold is the minimum token overlap for two descriptions
+// to be considered the same issue
+const issueSimilarityThreshold = 0.8
+
+var severityRank = map[string]int{
+	"low":      1,
+	"medium":   2,
+	"high":     3,
+	"critical": 4,
+}
+
+// SeveritiesAtLeast lists the severities at least as severe as min
+func SeveritiesAtLeast(min string) []string {
+	var severities []string
+	for severity, rank := range severityRank {
+		if rank >= severityRank[min] {
+			severities = append(severities, severity)
+		}
+	}
+	return severities
+}
+
+// DeduplicateIssues collapses near-duplicate issues reported for the same
+// type and turn. When duplicates are found the most severe one is kept.
+func DeduplicateIssues(issues []models.IssueDetected) []models.IssueDetected {
+	deduped := make([]models.IssueDetected, 0, len(issues))
+
+	for _, issue := range issues {
+		merged := false
+		for i, existing := range deduped {
+			if existing.Type != issue.Type || existing.TurnID != issue.TurnID {
+				continue
+			}
+			if descriptionSimilarity(existing.Description, issue.Description) < issueSimilarityThreshold {
+				continue
+			}
+			if severityRank[issue.Severity] > severityRank[existing.Severity] {
+				deduped[i] = issue
+			}
+			merged = true
+			break
+		}
+		if !merged {
+			deduped = append(deduped, issue)
+		}
+	}
+
+	return deduped
+}
+
+// DeduplicateIssuesJSON applies DeduplicateIssues to a JSON encoded issue list
+func DeduplicateIssuesJSON(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return raw, nil
+	}
+
+	var issues []models.IssueDetected
+	if err := json.Unmarshal(raw, &issues); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal issues: %w", err)
+	}
+
+	deduped, err := json.Marshal(DeduplicateIssues(issues))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal issues: %w", err)
+	}
+
+	return deduped, nil
+}
+
+// descriptionSimilarity returns the Jaccard similarity of the word sets of a and b
+func descriptionSimilarity(a, b string) float64 {
+	tokensA := tokenSet(a)
+	tokensB := tokenSet(b)
+
+	if len(tokensA) == 0 && len(tokensB) == 0 {
+		return 1.0
+	}
+
+	intersection := 0
+	for token := range tokensA {
+		if tokensB[token] {
+			intersection++
+		}
+	}
+	union := len(tokensA) + len(tokensB) - intersection
+
+	return float64(intersection) / float64(union)
+}
+
+func tokenSet(s string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		tokens[field] = true
+	}
+	return tokens
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-eval/internal/faults"
+)
+
+// RampPolicy controls how a dispatch limit, such as a concurrency or a rate,
+// ramps up from Start to Max and backs off when evaluations fail
+type RampPolicy struct {
+	Start        float64
+	Max          float64
+	Step         float64       // Added to the limit after StepEvery successes in a row
+	StepEvery    int           // Defaults to 10
+	Window       int           // Recent outcomes the error rate is measured over; defaults to 20
+	MaxErrorRate float64       // Back off when the recent error rate exceeds this
+	Backoff      float64       // Multiplies the limit when backing off; defaults to 0.5
+	Cooldown     time.Duration // Pause after backing off
+}
+
+// AdaptiveLimit ramps a dispatch limit up gradually and cuts it back, pausing
+// dispatch, when the recent error rate climbs, so a burst of tasks doesn't
+// overwhelm a cold or struggling evaluator service
+type AdaptiveLimit struct {
+	mu          sync.Mutex
+	policy      RampPolicy
+	limit       float64
+	outcomes    []bool // Ring of recent outcomes, true for a failure
+	next        int
+	failures    int
+	streak      int // Successes since the limit last changed
+	pausedUntil time.Time
+}
+
+// NewAdaptiveLimit creates a limit starting at policy.Start
+func NewAdaptiveLimit(policy RampPolicy) *AdaptiveLimit {
+	if policy.Max <= 0 {
+		policy.Max = 1
+	}
+	if policy.Start <= 0 || policy.Start > policy.Max {
+		policy.Start = policy.Max
+	}
+	if policy.Step <= 0 {
+		policy.Step = 1
+	}
+	if policy.StepEvery <= 0 {
+		policy.StepEvery = 10
+	}
+	if policy.Window <= 0 {
+		policy.Window = 20
+	}
+	if policy.Backoff <= 0 || policy.Backoff >= 1 {
+		policy.Backoff = 0.5
+	}
+	return &AdaptiveLimit{policy: policy, limit: policy.Start}
+}
+
+// Limit returns the current limit
+func (a *AdaptiveLimit) Limit() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.limit
+}
+
+// PausedUntil returns when dispatch may resume after a backoff
+func (a *AdaptiveLimit) PausedUntil() time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.pausedUntil
+}
+
+// Record records the outcome of a dispatched task and adjusts the limit. It
+// returns true if the limit backed off.
+func (a *AdaptiveLimit) Record(failed bool, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.outcomes) < a.policy.Window {
+		a.outcomes = append(a.outcomes, failed)
+	} else {
+		if a.outcomes[a.next] {
+			a.failures--
+		}
+		a.outcomes[a.next] = failed
+		a.next = (a.next + 1) % a.policy.Window
+	}
+	if failed {
+		a.failures++
+		a.streak = 0
+	} else {
+		a.streak++
+	}
+
+	// Wait for enough outcomes that a couple of failures aren't a trend
+	if len(a.outcomes) >= a.policy.Window/2 &&
+		float64(a.failures)/float64(len(a.outcomes)) > a.policy.MaxErrorRate {
+		floor := a.policy.Start
+		if floor > 1 {
+			floor = 1
+		}
+		a.limit *= a.policy.Backoff
+		if a.limit < floor {
+			a.limit = floor
+		}
+		a.pausedUntil = now.Add(a.policy.Cooldown)
+		a.outcomes = a.outcomes[:0]
+		a.next = 0
+		a.failures = 0
+		a.streak = 0
+		return true
+	}
+
+	if a.streak >= a.policy.StepEvery && a.limit < a.policy.Max {
+		a.limit += a.policy.Step
+		if a.limit > a.policy.Max {
+			a.limit = a.policy.Max
+		}
+		a.streak = 0
+	}
+	return false
+}
+
+// Wait blocks until a backoff pause is over or ctx is done
+func (a *AdaptiveLimit) Wait(ctx context.Context) error {
+	pause := time.Until(a.PausedUntil())
+	if pause <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(pause)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// EvaluatorHealth is what the evaluator service reports about itself
+type EvaluatorHealth struct {
+	Status         string `json:"status"`
+	Version        string `json:"version"`
+	MaxConcurrency int    `json:"max_concurrency,omitempty"` // 0 when the service doesn't advertise its capacity
+}
+
+// Probe checks that the evaluator service is healthy and returns the
+// capacity it advertises
+func (s *EvaluatorService) Probe(ctx context.Context) (*EvaluatorHealth, error) {
+	if err := s.faults.Inject(ctx, faults.TargetEvaluator); err != nil {
+		return nil, fmt.Errorf("evaluator service is unhealthy: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/health", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := s.evalClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call evaluator service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("evaluator service returned status %d", resp.StatusCode)
+	}
+	var health EvaluatorHealth
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if health.Status != "healthy" {
+		return nil, fmt.Errorf("evaluator service reports status %q", health.Status)
+	}
+
+	return &health, nil
+}
+
+// Warm opens up to conns connections to the evaluator service by probing it
+// concurrently, so the first evaluations don't all pay for connection setup
+func (s *EvaluatorService) Warm(ctx context.Context, conns int) error {
+	errs := make([]error, conns)
+	var wg sync.WaitGroup
+	for i := 0; i < conns; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.Probe(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
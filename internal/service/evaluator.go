@@ -2,75 +2,246 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
+
+	"github.com/ai-agent-eval/internal/faults"
+	"github.com/ai-agent-eval/internal/models"
 )
 
 // EvaluatorService handles communication with Python evaluator service
 type EvaluatorService struct {
 	baseURL    string
 	httpClient *http.Client
+	evalClient *http.Client // Deadlines come from the request context
+	tools      *ToolRegistry
+	segments   SegmentPolicy
+	recorder   ExchangeRecorder
+	sampleRate float64
+	faults     *faults.Injector
+
+	attachmentBaseURL string
 }
 
+// maxIdleEvaluatorConns is how many connections to the evaluator service
+// are kept open between evaluations, so concurrent workers reuse them
+const maxIdleEvaluatorConns = 64
+
 // NewEvaluatorService creates a new evaluator service client
-func NewEvaluatorService(baseURL string) *EvaluatorService {
+func NewEvaluatorService(baseURL string, tools *ToolRegistry) *EvaluatorService {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdleEvaluatorConns
+
 	return &EvaluatorService{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: 5 * time.Minute,
 		},
+		evalClient: &http.Client{Transport: transport},
+		tools:      tools,
 	}
 }
 
+// SetSegmentPolicy configures how conversations too long for a single
+// evaluation are split
+func (s *EvaluatorService) SetSegmentPolicy(policy SegmentPolicy) {
+	s.segments = policy
+}
+
+// SegmentPolicy returns how conversations too long for a single evaluation
+// are split
+func (s *EvaluatorService) SegmentPolicy() SegmentPolicy {
+	return s.segments
+}
+
+// SetFaults injects faults into evaluation calls, which fail as if the
+// evaluator service returned a 500
+func (s *EvaluatorService) SetFaults(injector *faults.Injector) {
+	s.faults = injector
+}
+
 // EvaluationRequest represents a request to evaluate a conversation
 type EvaluationRequest struct {
-	ConversationID string                 `json:"conversation_id"`
-	Turns          []map[string]interface{} `json:"turns"`
-	Metadata       map[string]interface{}   `json:"metadata"`
-	EvaluatorTypes []string               `json:"evaluator_types"`
+	ConversationID    string                   `json:"conversation_id"`
+	Turns             []map[string]interface{} `json:"turns"`
+	Metadata          map[string]interface{}   `json:"metadata"`
+	EvaluatorTypes    []string                 `json:"evaluator_types"`
+	EvaluatorVersions map[string]string        `json:"evaluator_versions,omitempty"`
 }
 
 // EvaluationResult represents the evaluation result from Python service
 type EvaluationResult struct {
-	EvaluationID           string                   `json:"evaluation_id"`
-	ConversationID         string                   `json:"conversation_id"`
-	Scores                 map[string]float64       `json:"scores"`
-	ToolEvaluation         map[string]interface{}   `json:"tool_evaluation"`
-	IssuesDetected         []map[string]interface{} `json:"issues_detected"`
-	ImprovementSuggestions []map[string]interface{} `json:"improvement_suggestions"`
-	EvaluatorVersion       string                   `json:"evaluator_version"`
-	EvaluationDurationMS   int                      `json:"evaluation_duration_ms"`
+	EvaluationID           string                    `json:"evaluation_id"`
+	ConversationID         string                    `json:"conversation_id"`
+	Scores                 map[string]float64        `json:"scores"`
+	ToolEvaluation         map[string]interface{}    `json:"tool_evaluation"`
+	IssuesDetected         []map[string]interface{}  `json:"issues_detected"`
+	ImprovementSuggestions []map[string]interface{}  `json:"improvement_suggestions"`
+	EvaluatorVersion       string                    `json:"evaluator_version"`
+	EvaluationDurationMS   int                       `json:"evaluation_duration_ms"`
+	Segments               int                       `json:"segments,omitempty"` // Set when the conversation was split
+	FailedEvaluators       []models.EvaluatorFailure `json:"failed_evaluators,omitempty"`
+	SkippedEvaluators      []models.SkippedEvaluator `json:"skipped_evaluators,omitempty"` // Set when evaluated within a budget
+	Cost                   float64                   `json:"cost,omitempty"`
 }
 
-// Evaluate sends a conversation to the Python service for evaluation
+// defaultEvaluationTimeout bounds evaluations whose context has no deadline
+const defaultEvaluationTimeout = 5 * time.Minute
+
+// Evaluate sends a conversation to the Python service for evaluation.
+// Conversations longer than the segment policy allows are evaluated in
+// segments and the results aggregated.
 func (s *EvaluatorService) Evaluate(req *EvaluationRequest) (*EvaluationResult, error) {
+	return s.EvaluateContext(context.Background(), req, nil)
+}
+
+// EvaluateContext evaluates a conversation within ctx's deadline. When
+// timeouts are given, each evaluator type is evaluated separately within its
+// timeout, and types that fail or time out are reported in FailedEvaluators
+// while the others' results are kept. It fails only if every type fails.
+func (s *EvaluatorService) EvaluateContext(ctx context.Context, req *EvaluationRequest, timeouts map[string]time.Duration) (*EvaluationResult, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultEvaluationTimeout)
+		defer cancel()
+	}
+
+	var result *EvaluationResult
+	var err error
+	if segments := s.segments.split(req.Turns); len(segments) > 1 {
+		result, err = s.evaluateSegmented(ctx, req, segments, timeouts)
+	} else {
+		result, err = s.evaluateComponents(ctx, req, timeouts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.addToolIssues(req, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// evaluateType evaluates a request for a single evaluator type with its
+// registered evaluator, or with the Python service if it has none
+func (s *EvaluatorService) evaluateType(ctx context.Context, req *EvaluationRequest) (*EvaluationResult, error) {
+	if evaluator, ok := Evaluators.Lookup(req.EvaluatorTypes[0]); ok {
+		return evaluateRegistered(ctx, s.withAttachmentReferences(req, true), evaluator)
+	}
+	return s.evaluateRequest(ctx, req)
+}
+
+// evaluateRequest makes a single call to the Python evaluation endpoint
+func (s *EvaluatorService) evaluateRequest(ctx context.Context, req *EvaluationRequest) (*EvaluationResult, error) {
+	req = s.withAttachmentReferences(req, MultimodalRequested(req.EvaluatorTypes))
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := s.httpClient.Post(
-		s.baseURL+"/evaluate",
-		"application/json",
-		bytes.NewBuffer(body),
-	)
+	record := s.sampled()
+	start := time.Now()
+	result, raw, status, err := s.post(ctx, body)
+	if record {
+		s.record(req.ConversationID, body, raw, status, time.Since(start), result, err)
+	}
+
+	return result, err
+}
+
+// EvaluateRaw sends an already encoded evaluation request, such as a recorded
+// one, without segmenting it or adding tool latency issues
+func (s *EvaluatorService) EvaluateRaw(body json.RawMessage) (*EvaluationResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultEvaluationTimeout)
+	defer cancel()
+
+	result, _, _, err := s.post(ctx, body)
+	return result, err
+}
+
+// post sends an encoded request to the evaluation endpoint. It returns the
+// raw response body and status code alongside the decoded result so the
+// exchange can be recorded.
+func (s *EvaluatorService) post(ctx context.Context, body []byte) (*EvaluationResult, []byte, int, error) {
+	if err := s.faults.Inject(ctx, faults.TargetEvaluator); err != nil {
+		return nil, nil, http.StatusInternalServerError, fmt.Errorf("evaluator service returned status %d: %w", http.StatusInternalServerError, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/evaluate", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.evalClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call evaluator service: %w", err)
+		return nil, nil, 0, fmt.Errorf("failed to call evaluator service: %w", err)
 	}
 	defer resp.Body.Close()
 
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("evaluator service returned status %d", resp.StatusCode)
+		return nil, raw, resp.StatusCode, fmt.Errorf("evaluator service returned status %d", resp.StatusCode)
 	}
 
 	var result EvaluationResult
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, raw, resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, raw, resp.StatusCode, nil
+}
+
+// addToolIssues appends SLA breaches for recorded tool call latencies and,
+// when calls match golden examples, the golden score and mismatches
+func (s *EvaluatorService) addToolIssues(req *EvaluationRequest, result *EvaluationResult) error {
+	if s.tools == nil {
+		return nil
+	}
+
+	turnsJSON, err := json.Marshal(req.Turns)
+	if err != nil {
+		return fmt.Errorf("failed to marshal turns: %w", err)
+	}
+	var turns []models.Turn
+	if err := json.Unmarshal(turnsJSON, &turns); err != nil {
+		return fmt.Errorf("failed to unmarshal turns: %w", err)
+	}
+
+	issues := s.tools.DetectLatencyIssues(turns)
+
+	if check := s.tools.CheckGoldens(turns); check.Checked > 0 {
+		if result.Scores == nil {
+			result.Scores = make(map[string]float64)
+		}
+		result.Scores[ToolGoldenScore] = check.Score()
+		if result.ToolEvaluation == nil {
+			result.ToolEvaluation = make(map[string]interface{})
+		}
+		result.ToolEvaluation["golden"] = check
+		issues = append(issues, check.Issues...)
+	}
+
+	for _, issue := range issues {
+		result.IssuesDetected = append(result.IssuesDetected, map[string]interface{}{
+			"type":        issue.Type,
+			"severity":    issue.Severity,
+			"description": issue.Description,
+			"turn_id":     issue.TurnID,
+		})
 	}
 
-	return &result, nil
+	return nil
 }
 
 // AnalyzePatterns calls the Python service to analyze patterns
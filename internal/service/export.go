@@ -0,0 +1,208 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Label Studio control names used by exported tasks. A project importing the
+// tasks needs a labeling config with a Paragraphs tag named "dialogue", a
+// Number tag named "overall_score", a Choices tag named "issues" and a
+// Choices tag per annotation type, all pointing at "dialogue".
+const (
+	labelStudioDialogue = "dialogue"
+	labelStudioScore    = "overall_score"
+	labelStudioIssues   = "issues"
+)
+
+// LabelStudioTasks builds Label Studio import tasks. The latest automated
+// evaluation becomes a prediction and each annotator's labels become a
+// completed annotation.
+func LabelStudioTasks(items []models.AnnotationExportItem) ([]models.LabelStudioTask, error) {
+	tasks := make([]models.LabelStudioTask, 0, len(items))
+	for _, item := range items {
+		turns, err := exportTurns(item.Conversation)
+		if err != nil {
+			return nil, err
+		}
+
+		dialogue := make([]map[string]string, 0, len(turns))
+		for _, turn := range turns {
+			dialogue = append(dialogue, map[string]string{
+				"author": turn.Role,
+				"text":   turnText(turn),
+			})
+		}
+
+		task := models.LabelStudioTask{
+			Data: map[string]interface{}{
+				"conversation_id":   item.Conversation.ConversationID,
+				"agent_version":     item.Conversation.AgentVersion,
+				"text":              Transcript(turns),
+				labelStudioDialogue: dialogue,
+			},
+		}
+
+		if eval := item.LatestEvaluation; eval != nil {
+			resp := models.NewEvaluationResponse(eval)
+			score := resp.Scores.Overall
+			result := []models.LabelStudioResult{{
+				ID:       resp.EvaluationID + "-score",
+				FromName: labelStudioScore,
+				ToName:   labelStudioDialogue,
+				Type:     "number",
+				Value:    map[string]interface{}{"number": score},
+			}}
+			if types := issueTypes(resp.IssuesDetected); len(types) > 0 {
+				result = append(result, models.LabelStudioResult{
+					ID:       resp.EvaluationID + "-issues",
+					FromName: labelStudioIssues,
+					ToName:   labelStudioDialogue,
+					Type:     "choices",
+					Value:    map[string]interface{}{"choices": types},
+				})
+			}
+			task.Predictions = []models.LabelStudioAnnotation{{
+				ModelVersion: resp.EvaluatorVersion,
+				Score:        &score,
+				Result:       result,
+			}}
+		}
+
+		// One completed annotation per annotator, in order of first annotation
+		byAnnotator := make(map[string]int)
+		for _, ann := range item.Annotations {
+			index, ok := byAnnotator[ann.AnnotatorID]
+			if !ok {
+				index = len(task.Annotations)
+				byAnnotator[ann.AnnotatorID] = index
+				task.Annotations = append(task.Annotations, models.LabelStudioAnnotation{
+					CompletedBy: ann.AnnotatorID,
+				})
+			}
+			task.Annotations[index].Result = append(task.Annotations[index].Result, models.LabelStudioResult{
+				ID:       fmt.Sprintf("annotation-%d", ann.ID),
+				FromName: ann.AnnotationType,
+				ToName:   labelStudioDialogue,
+				Type:     "choices",
+				Value:    map[string]interface{}{"choices": AnnotationLabels(ann)},
+			})
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// ProdigyTasks builds Prodigy choice tasks. Every task offers the issue types
+// seen across the export, and the issues found by the latest automated
+// evaluation are pre-selected.
+func ProdigyTasks(items []models.AnnotationExportItem) ([]models.ProdigyTask, error) {
+	tasks := make([]models.ProdigyTask, 0, len(items))
+	seen := make(map[string]bool)
+
+	for _, item := range items {
+		turns, err := exportTurns(item.Conversation)
+		if err != nil {
+			return nil, err
+		}
+
+		task := models.ProdigyTask{
+			Text:   Transcript(turns),
+			Accept: []string{},
+			Meta: map[string]interface{}{
+				"conversation_id": item.Conversation.ConversationID,
+				"agent_version":   item.Conversation.AgentVersion,
+			},
+		}
+
+		if eval := item.LatestEvaluation; eval != nil {
+			resp := models.NewEvaluationResponse(eval)
+			task.Accept = issueTypes(resp.IssuesDetected)
+			task.Meta["evaluation_id"] = resp.EvaluationID
+			task.Meta["evaluator_version"] = resp.EvaluatorVersion
+			task.Meta["overall_score"] = resp.Scores.Overall
+		}
+		for _, issueType := range task.Accept {
+			seen[issueType] = true
+		}
+
+		if len(item.Annotations) > 0 {
+			labels := make(map[string][]string)
+			for _, ann := range item.Annotations {
+				labels[ann.AnnotationType] = append(labels[ann.AnnotationType], ann.Label)
+			}
+			task.Meta["human_labels"] = labels
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	options := make([]string, 0, len(seen))
+	for issueType := range seen {
+		options = append(options, issueType)
+	}
+	sort.Strings(options)
+	for i := range tasks {
+		tasks[i].Options = make([]models.ProdigyOption, len(options))
+		for j, option := range options {
+			tasks[i].Options[j] = models.ProdigyOption{ID: option, Text: option}
+		}
+	}
+
+	return tasks, nil
+}
+
+// Transcript renders turns as plain text, one "role: text" block per turn
+func Transcript(turns []models.Turn) string {
+	blocks := make([]string, 0, len(turns))
+	for _, turn := range turns {
+		blocks = append(blocks, turn.Role+": "+turnText(turn))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// turnText renders a turn's content followed by its tool calls
+func turnText(turn models.Turn) string {
+	lines := make([]string, 0, 1+len(turn.ToolCalls))
+	if turn.Content != "" {
+		lines = append(lines, turn.Content)
+	}
+	for _, call := range turn.ToolCalls {
+		params, _ := json.Marshal(call.Parameters)
+		lines = append(lines, fmt.Sprintf("[tool call] %s(%s)", call.ToolName, params))
+	}
+	if turn.Content == "" && turn.Result != nil {
+		result, _ := json.Marshal(turn.Result)
+		lines = append(lines, string(result))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// exportTurns decodes the stored turns of a conversation
+func exportTurns(conv models.Conversation) ([]models.Turn, error) {
+	var turns []models.Turn
+	if err := json.Unmarshal(conv.Turns, &turns); err != nil {
+		return nil, fmt.Errorf("conversation %s: failed to decode turns: %w", conv.ConversationID, err)
+	}
+	return turns, nil
+}
+
+// issueTypes returns the distinct issue types, in order of first occurrence
+func issueTypes(issues []models.IssueDetected) []string {
+	types := []string{}
+	seen := make(map[string]bool)
+	for _, issue := range issues {
+		if issue.Type == "" || seen[issue.Type] {
+			continue
+		}
+		seen[issue.Type] = true
+		types = append(types, issue.Type)
+	}
+	return types
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/queue"
+)
+
+// FairnessPolicy decides how the tasks of fair queues are shared between
+// projects: projects are dequeued from in proportion to their weights, and
+// none has more than its cap of tasks in flight at once
+type FairnessPolicy struct {
+	Weights            map[string]int // By project; unlisted projects weigh 1
+	MaxInFlight        map[string]int // By project
+	DefaultMaxInFlight int            // For unlisted projects; 0 for no cap
+}
+
+// NewFairnessPolicy returns the configured fairness policy
+func NewFairnessPolicy(cfg *config.Config) FairnessPolicy {
+	return FairnessPolicy{
+		Weights:            cfg.ProjectQueueWeights,
+		MaxInFlight:        cfg.ProjectMaxInFlight,
+		DefaultMaxInFlight: cfg.DefaultProjectMaxInFlight,
+	}
+}
+
+// Weight returns a project's share weight
+func (p FairnessPolicy) Weight(projectID string) int {
+	if weight := p.Weights[projectID]; weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+// InFlightCap returns how many of a project's tasks may be in flight at
+// once, or 0 for no cap
+func (p FairnessPolicy) InFlightCap(projectID string) int {
+	if limit, ok := p.MaxInFlight[projectID]; ok {
+		return limit
+	}
+	return p.DefaultMaxInFlight
+}
+
+// FairScheduler orders projects for dequeueing by smooth weighted round
+// robin: each round every waiting project earns credit by its weight, and
+// the project a task is taken from spends a round's worth. Over time each
+// project gets its weighted share, interleaved rather than in bursts.
+type FairScheduler struct {
+	mu     sync.Mutex
+	policy FairnessPolicy
+	credit map[string]int
+}
+
+// NewFairScheduler creates a scheduler sharing by a fairness policy
+func NewFairScheduler(policy FairnessPolicy) *FairScheduler {
+	return &FairScheduler{policy: policy, credit: make(map[string]int)}
+}
+
+// Order starts a round between the projects with waiting tasks and returns
+// them in the order to try, most credit first. Projects that stopped
+// waiting lose their credit.
+func (f *FairScheduler) Order(projects []string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	waiting := make(map[string]bool, len(projects))
+	for _, projectID := range projects {
+		waiting[projectID] = true
+		f.credit[projectID] += f.policy.Weight(projectID)
+	}
+	for projectID := range f.credit {
+		if !waiting[projectID] {
+			delete(f.credit, projectID)
+		}
+	}
+
+	order := append([]string(nil), projects...)
+	sort.SliceStable(order, func(i, j int) bool { return f.credit[order[i]] > f.credit[order[j]] })
+	return order
+}
+
+// Charge records that a round's task was taken from a project
+func (f *FairScheduler) Charge(projectID string, projects []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	total := 0
+	for _, p := range projects {
+		total += f.policy.Weight(p)
+	}
+	f.credit[projectID] -= total
+}
+
+// ProjectFairness is a project's share of a fair queue against its weight
+type ProjectFairness struct {
+	queue.ProjectQueueStats
+	Weight      int     `json:"weight"`
+	MaxInFlight int     `json:"max_in_flight,omitempty"`
+	Share       float64 `json:"share"`      // Of the queue's recent dequeues
+	FairShare   float64 `json:"fair_share"` // By weight, among projects with waiting tasks
+}
+
+// QueueFairness describes how evenly a fair queue is shared between projects
+type QueueFairness struct {
+	Queue    string            `json:"queue"`
+	Projects []ProjectFairness `json:"projects"`
+	// Jain's index of dequeue rates per weight of the projects with waiting
+	// tasks: 1 when each gets its weighted share, 1/n when one gets it all
+	FairnessIndex float64 `json:"fairness_index"`
+}
+
+// CheckQueueFairness compares each project's recent dequeues with its
+// weighted share
+func CheckQueueFairness(queueName string, stats []queue.ProjectQueueStats, policy FairnessPolicy) QueueFairness {
+	fairness := QueueFairness{Queue: queueName, Projects: make([]ProjectFairness, 0, len(stats)), FairnessIndex: 1}
+
+	totalRate, waitingWeight := 0.0, 0
+	for _, s := range stats {
+		totalRate += s.DequeuedPerMinute
+		if s.Length > 0 {
+			waitingWeight += policy.Weight(s.ProjectID)
+		}
+	}
+
+	var sum, sumSquares float64
+	waiting := 0
+	for _, s := range stats {
+		p := ProjectFairness{
+			ProjectQueueStats: s,
+			Weight:            policy.Weight(s.ProjectID),
+			MaxInFlight:       policy.InFlightCap(s.ProjectID),
+		}
+		if totalRate > 0 {
+			p.Share = s.DequeuedPerMinute / totalRate
+		}
+		if s.Length > 0 {
+			p.FairShare = float64(p.Weight) / float64(waitingWeight)
+			x := s.DequeuedPerMinute / float64(p.Weight)
+			sum += x
+			sumSquares += x * x
+			waiting++
+		}
+		fairness.Projects = append(fairness.Projects, p)
+	}
+	if sumSquares > 0 {
+		fairness.FairnessIndex = sum * sum / (float64(waiting) * sumSquares)
+	}
+	return fairness
+}
+
+// CollectQueueFairness gathers per-project stats for the fair queues and
+// compares them with the fairness policy
+func CollectQueueFairness(q *queue.RedisQueue, policy FairnessPolicy) ([]QueueFairness, error) {
+	fairness := make([]QueueFairness, 0, len(queue.FairQueues))
+	for _, name := range queue.FairQueues {
+		stats, err := q.ProjectStats(name)
+		if err != nil {
+			return nil, fmt.Errorf("queue %s: %w", name, err)
+		}
+		fairness = append(fairness, CheckQueueFairness(name, stats, policy))
+	}
+	return fairness, nil
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Feedback sentiments
+const (
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+)
+
+// feedbackTheme is a recurring topic of feedback comments, recognised by
+// keywords, with the issue types automated evaluation reports for it
+type feedbackTheme struct {
+	name       string
+	keywords   []string
+	issueTypes []string
+}
+
+// feedbackThemes are matched against lower-cased comments. Multi-word
+// keywords match as phrases; single words match whole words or, when ending
+// in '*', word prefixes.
+var feedbackThemes = []feedbackTheme{
+	{
+		name:       "speed",
+		keywords:   []string{"slow*", "took forever", "took too long", "wait*", "lag*", "timeout*", "timed out", "fast", "quick*"},
+		issueTypes: []string{"latency", "tool_latency_exceeded"},
+	},
+	{
+		name:       "accuracy",
+		keywords:   []string{"wrong", "incorrect", "inaccurate", "mistake*", "made up", "false", "not true", "hallucinat*", "accurate", "correct"},
+		issueTypes: []string{"hallucinated_parameters", "ungrounded_parameters", "potential_contradiction", "response_quality"},
+	},
+	{
+		name:       "tool_failure",
+		keywords:   []string{"error*", "fail*", "broken", "crash*", "didn't work", "doesn't work", "did not work", "not working", "couldn't book", "could not book"},
+		issueTypes: []string{"tool_execution_failure", "unknown_tool", "missing_required_parameters", "tool_call_format", "tool_schema"},
+	},
+	{
+		name:       "understanding",
+		keywords:   []string{"understand*", "misunderst*", "confus*", "forgot", "forget*", "repeat*", "ignored", "already told", "already said", "irrelevant"},
+		issueTypes: []string{"context_loss", "information_not_retained", "unclear_reference", "coherence"},
+	},
+	{
+		name:       "formatting",
+		keywords:   []string{"format*", "unreadable", "messy", "missing information", "incomplete"},
+		issueTypes: []string{"format_violation", "missing_fields"},
+	},
+	{
+		name:       "helpfulness",
+		keywords:   []string{"helpful", "unhelpful", "useless", "pointless", "solved", "resolved", "useful"},
+		issueTypes: []string{"response_quality"},
+	},
+	{
+		name:     "tone",
+		keywords: []string{"rude", "polite", "friendly", "robotic", "annoying", "patient", "condescending"},
+	},
+}
+
+// Sentiment lexicon; words prefixed with a negation flip polarity
+var (
+	positiveWords = map[string]bool{
+		"good": true, "great": true, "excellent": true, "helpful": true, "useful": true, "fast": true,
+		"quick": true, "easy": true, "perfect": true, "love": true, "loved": true, "thanks": true,
+		"thank": true, "amazing": true, "accurate": true, "correct": true, "polite": true,
+		"friendly": true, "solved": true, "resolved": true, "nice": true, "awesome": true, "happy": true,
+	}
+	negativeWords = map[string]bool{
+		"bad": true, "terrible": true, "awful": true, "horrible": true, "useless": true, "unhelpful": true,
+		"slow": true, "wrong": true, "incorrect": true, "broken": true, "error": true, "failed": true,
+		"fail": true, "frustrating": true, "frustrated": true, "annoying": true, "rude": true,
+		"confusing": true, "confused": true, "hate": true, "worst": true, "poor": true, "disappointed": true,
+		"disappointing": true, "pointless": true, "inaccurate": true, "crashed": true, "unreadable": true,
+	}
+	negations = map[string]bool{
+		"not": true, "no": true, "never": true, "isn't": true, "wasn't": true, "didn't": true,
+		"doesn't": true, "don't": true, "hardly": true,
+	}
+)
+
+// sentimentThreshold is the net score beyond which a comment is not neutral
+const sentimentThreshold = 0.2
+
+// AnalyzeFeedback extracts the sentiment and themes of a feedback comment with
+// keyword rules. Empty comments have no sentiment or themes.
+func AnalyzeFeedback(comment string) models.FeedbackAnalysis {
+	analysis := models.FeedbackAnalysis{Themes: []string{}}
+	text := strings.ToLower(strings.TrimSpace(comment))
+	if text == "" {
+		return analysis
+	}
+
+	padded, words := keywordText(text)
+
+	var positive, negative int
+	for i, word := range words {
+		polarity := 0
+		if positiveWords[word] {
+			polarity = 1
+		} else if negativeWords[word] {
+			polarity = -1
+		}
+		if polarity != 0 && i > 0 && negations[words[i-1]] {
+			polarity = -polarity
+		}
+		switch polarity {
+		case 1:
+			positive++
+		case -1:
+			negative++
+		}
+	}
+
+	analysis.Sentiment = SentimentNeutral
+	if total := positive + negative; total > 0 {
+		analysis.SentimentScore = float64(positive-negative) / float64(total)
+		switch {
+		case analysis.SentimentScore > sentimentThreshold:
+			analysis.Sentiment = SentimentPositive
+		case analysis.SentimentScore < -sentimentThreshold:
+			analysis.Sentiment = SentimentNegative
+		}
+	}
+
+	for _, theme := range feedbackThemes {
+		if matchesAnyKeyword(padded, words, theme.keywords) {
+			analysis.Themes = append(analysis.Themes, theme.name)
+		}
+	}
+
+	return analysis
+}
+
+// keywordText splits lower-cased text into words for keyword matching, and
+// joins them back with single spaces and a space at each end
+func keywordText(text string) (padded string, words []string) {
+	words = strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	return " " + strings.Join(words, " ") + " ", words
+}
+
+// matchesAnyKeyword reports whether text split by keywordText contains any
+// keyword
+func matchesAnyKeyword(padded string, words []string, keywords []string) bool {
+	for _, keyword := range keywords {
+		switch {
+		case strings.Contains(keyword, " "):
+			if strings.Contains(padded, " "+keyword+" ") {
+				return true
+			}
+		case strings.HasSuffix(keyword, "*"):
+			prefix := strings.TrimSuffix(keyword, "*")
+			for _, word := range words {
+				if strings.HasPrefix(word, prefix) {
+					return true
+				}
+			}
+		default:
+			if strings.Contains(padded, " "+keyword+" ") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AggregateFeedbackThemes summarizes feedback by theme against the automated
+// evaluation of the same conversations. For each theme it reports how often
+// the latest evaluation detected an issue type related to the theme, so
+// complaints automated evaluation misses stand out, and how the theme's
+// average score compares with that of all commented conversations.
+func AggregateFeedbackThemes(signals []models.FeedbackSignal, topIssueTypes int) []models.FeedbackThemeSummary {
+	type totals struct {
+		feedback, negative int
+		ratingSum          float64
+		ratings            int
+		conversations      map[string]bool
+		scoreSum           float64
+		scored, matched    int
+		issueCounts        map[string]int
+	}
+	byTheme := make(map[string]*totals)
+
+	var baselineSum float64
+	var baselineCount int
+	seen := make(map[string]bool)
+	for _, signal := range signals {
+		var themes, issueTypes []string
+		json.Unmarshal(signal.Themes, &themes)
+		json.Unmarshal(signal.IssueTypes, &issueTypes)
+
+		newConversation := !seen[signal.ConversationID]
+		seen[signal.ConversationID] = true
+		if newConversation && signal.OverallScore.Valid {
+			baselineSum += signal.OverallScore.Float64
+			baselineCount++
+		}
+
+		for _, theme := range themes {
+			t, ok := byTheme[theme]
+			if !ok {
+				t = &totals{conversations: make(map[string]bool), issueCounts: make(map[string]int)}
+				byTheme[theme] = t
+			}
+			t.feedback++
+			if signal.Sentiment == SentimentNegative {
+				t.negative++
+			}
+			if signal.UserRating.Valid {
+				t.ratingSum += float64(signal.UserRating.Int32)
+				t.ratings++
+			}
+
+			// Evaluation figures count each conversation once per theme
+			if t.conversations[signal.ConversationID] {
+				continue
+			}
+			t.conversations[signal.ConversationID] = true
+			if signal.OverallScore.Valid {
+				t.scoreSum += signal.OverallScore.Float64
+				t.scored++
+			}
+			related := themeIssueTypes(theme)
+			matched := false
+			for _, issueType := range uniqueStrings(issueTypes) {
+				t.issueCounts[issueType]++
+				matched = matched || related[issueType]
+			}
+			if matched {
+				t.matched++
+			}
+		}
+	}
+
+	summaries := make([]models.FeedbackThemeSummary, 0, len(byTheme))
+	for theme, t := range byTheme {
+		summary := models.FeedbackThemeSummary{
+			Theme:         theme,
+			FeedbackCount: t.feedback,
+			Conversations: len(t.conversations),
+			NegativeShare: float64(t.negative) / float64(t.feedback),
+			TopIssueTypes: []models.IssueTypeCount{},
+		}
+		if t.ratings > 0 {
+			avg := t.ratingSum / float64(t.ratings)
+			summary.AvgUserRating = &avg
+		}
+		if t.scored > 0 {
+			avg := t.scoreSum / float64(t.scored)
+			summary.AvgOverallScore = &avg
+			if baselineCount > 0 {
+				delta := avg - baselineSum/float64(baselineCount)
+				summary.ScoreDelta = &delta
+			}
+		}
+		if len(themeIssueTypes(theme)) > 0 {
+			rate := float64(t.matched) / float64(len(t.conversations))
+			summary.MatchedIssueRate = &rate
+		}
+
+		for issueType, count := range t.issueCounts {
+			summary.TopIssueTypes = append(summary.TopIssueTypes, models.IssueTypeCount{IssueType: issueType, Conversations: count})
+		}
+		sort.Slice(summary.TopIssueTypes, func(i, j int) bool {
+			a, b := summary.TopIssueTypes[i], summary.TopIssueTypes[j]
+			if a.Conversations != b.Conversations {
+				return a.Conversations > b.Conversations
+			}
+			return a.IssueType < b.IssueType
+		})
+		if len(summary.TopIssueTypes) > topIssueTypes {
+			summary.TopIssueTypes = summary.TopIssueTypes[:topIssueTypes]
+		}
+
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].FeedbackCount != summaries[j].FeedbackCount {
+			return summaries[i].FeedbackCount > summaries[j].FeedbackCount
+		}
+		return summaries[i].Theme < summaries[j].Theme
+	})
+
+	return summaries
+}
+
+// themeIssueTypes returns the issue types related to a theme
+func themeIssueTypes(name string) map[string]bool {
+	related := make(map[string]bool)
+	for _, theme := range feedbackThemes {
+		if theme.name == name {
+			for _, issueType := range theme.issueTypes {
+				related[issueType] = true
+			}
+		}
+	}
+	return related
+}
+
+// uniqueStrings returns values without duplicates, in first-seen order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
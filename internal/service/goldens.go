@@ -0,0 +1,279 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// ToolGoldenScore is the component score of the share of golden tool
+// examples that recorded calls passed. It is computed without the LLM judge,
+// so the same conversation always scores the same.
+const ToolGoldenScore = "tool_golden"
+
+// toolGolden is a golden example prepared for checking, with its user
+// message compiled and its values as encoding/json decodes them
+type toolGolden struct {
+	models.ToolGolden
+	userMessage *regexp.Regexp
+}
+
+// ToolGoldenCheck is the outcome of checking a conversation's tool calls
+// against the golden examples
+type ToolGoldenCheck struct {
+	Checked int                    `json:"checked"` // Calls compared, once for each golden scenario they matched
+	Passed  int                    `json:"passed"`
+	Issues  []models.IssueDetected `json:"-"`
+}
+
+// Score returns the share of checked calls that passed
+func (c ToolGoldenCheck) Score() float64 {
+	if c.Checked == 0 {
+		return 0
+	}
+	return float64(c.Passed) / float64(c.Checked)
+}
+
+// ValidateToolGoldens checks that golden examples have unique names, a
+// valid user message pattern and something to expect
+func ValidateToolGoldens(goldens []models.ToolGolden) error {
+	names := make(map[string]bool, len(goldens))
+	for _, golden := range goldens {
+		if golden.Name == "" || golden.Tool == "" {
+			return fmt.Errorf("golden examples need a name and a tool")
+		}
+		if names[golden.Name] {
+			return fmt.Errorf("golden example %q is defined twice", golden.Name)
+		}
+		names[golden.Name] = true
+
+		if _, err := compileUserMessage(golden.Scenario.UserMessage); err != nil {
+			return fmt.Errorf("golden example %q: invalid user_message: %w", golden.Name, err)
+		}
+		if len(golden.ExpectedParameters) == 0 && len(golden.ExpectedResult) == 0 {
+			return fmt.Errorf("golden example %q expects neither parameters nor a result", golden.Name)
+		}
+	}
+	return nil
+}
+
+// SetGoldens replaces the golden examples. Examples that fail validation
+// are skipped.
+func (r *ToolRegistry) SetGoldens(goldens []models.ToolGolden) {
+	byTool := make(map[string][]toolGolden)
+	for _, golden := range goldens {
+		if err := ValidateToolGoldens([]models.ToolGolden{golden}); err != nil {
+			continue
+		}
+		userMessage, _ := compileUserMessage(golden.Scenario.UserMessage)
+
+		prepared := toolGolden{ToolGolden: golden, userMessage: userMessage}
+		prepared.Scenario.Parameters = jsonObject(golden.Scenario.Parameters)
+		prepared.ExpectedParameters = jsonObject(golden.ExpectedParameters)
+		prepared.ExpectedResult = jsonObject(golden.ExpectedResult)
+		byTool[golden.Tool] = append(byTool[golden.Tool], prepared)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.goldens = byTool
+}
+
+// Goldens returns the golden examples sorted by name
+func (r *ToolRegistry) Goldens() []models.ToolGolden {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	goldens := []models.ToolGolden{}
+	for _, toolGoldens := range r.goldens {
+		for _, golden := range toolGoldens {
+			goldens = append(goldens, golden.ToolGolden)
+		}
+	}
+	sort.Slice(goldens, func(i, j int) bool { return goldens[i].Name < goldens[j].Name })
+	return goldens
+}
+
+// CheckGoldens compares every recorded tool call with the golden examples
+// of its tool whose scenario it matches. A call's result is its own, or
+// else that of the tool turn answering it. Each mismatch is reported as a
+// tool_golden_mismatch issue.
+func (r *ToolRegistry) CheckGoldens(turns []models.Turn) ToolGoldenCheck {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var check ToolGoldenCheck
+	if len(r.goldens) == 0 {
+		return check
+	}
+
+	answers := make(map[string]map[string]interface{})
+	for _, turn := range turns {
+		if turn.ToolCallID != "" {
+			answers[turn.ToolCallID] = toolTurnResult(turn)
+		}
+	}
+
+	lastUserMessage := ""
+	for _, turn := range turns {
+		if turn.Role == "user" {
+			lastUserMessage = turn.Content
+		}
+		for _, call := range turn.ToolCalls {
+			result := call.Result
+			if result == nil && call.ID != "" {
+				result = answers[call.ID]
+			}
+
+			for _, golden := range r.goldens[call.ToolName] {
+				if !golden.matches(lastUserMessage, call.Parameters) {
+					continue
+				}
+				check.Checked++
+
+				mismatch := golden.mismatch(call.Parameters, result)
+				if mismatch == "" {
+					check.Passed++
+					continue
+				}
+				check.Issues = append(check.Issues, models.IssueDetected{
+					Type:        "tool_golden_mismatch",
+					Severity:    "high",
+					Description: fmt.Sprintf("Call to %s doesn't match golden example %q: %s", call.ToolName, golden.Name, mismatch),
+					TurnID:      turn.TurnID,
+				})
+			}
+		}
+	}
+
+	return check
+}
+
+// matches reports whether a call falls under the golden example's scenario
+func (g *toolGolden) matches(userMessage string, parameters map[string]interface{}) bool {
+	if g.userMessage != nil && !g.userMessage.MatchString(userMessage) {
+		return false
+	}
+	_, _, ok := containsValues(jsonObject(parameters), g.Scenario.Parameters, "")
+	return ok
+}
+
+// mismatch describes how a call differs from the golden example, or returns
+// an empty string if it doesn't
+func (g *toolGolden) mismatch(parameters, result map[string]interface{}) string {
+	if path, got, ok := containsValues(jsonObject(parameters), g.ExpectedParameters, ""); !ok {
+		return fmt.Sprintf("parameter %s is %s, expected %s", path, describeValue(got), describeValue(lookupPath(g.ExpectedParameters, path)))
+	}
+	if len(g.ExpectedResult) == 0 {
+		return ""
+	}
+	if result == nil {
+		return "no result was recorded"
+	}
+	if path, got, ok := containsValues(jsonObject(result), g.ExpectedResult, ""); !ok {
+		return fmt.Sprintf("result %s is %s, expected %s", path, describeValue(got), describeValue(lookupPath(g.ExpectedResult, path)))
+	}
+	return ""
+}
+
+// containsValues reports whether actual has every value of expected, with
+// nested objects compared the same way. Otherwise it returns the dotted path
+// of the first differing value and the actual value there, nil if missing.
+func containsValues(actual, expected map[string]interface{}, prefix string) (string, interface{}, bool) {
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := prefix + key
+		got, present := actual[key]
+		if !present {
+			return path, nil, false
+		}
+		if want, ok := expected[key].(map[string]interface{}); ok {
+			gotObject, ok := got.(map[string]interface{})
+			if !ok {
+				return path, got, false
+			}
+			if subPath, subGot, ok := containsValues(gotObject, want, path+"."); !ok {
+				return subPath, subGot, false
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, expected[key]) {
+			return path, got, false
+		}
+	}
+	return "", nil, true
+}
+
+// lookupPath returns the value at a dotted path of an object
+func lookupPath(object map[string]interface{}, path string) interface{} {
+	var value interface{} = object
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+// describeValue formats a value for an issue description
+func describeValue(value interface{}) string {
+	if value == nil {
+		return "missing"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// toolTurnResult returns the result of a tool turn: its result object, or
+// its content if that is a JSON object
+func toolTurnResult(turn models.Turn) map[string]interface{} {
+	if turn.Result != nil {
+		return turn.Result
+	}
+	var result map[string]interface{}
+	if json.Unmarshal([]byte(turn.Content), &result) == nil {
+		return result
+	}
+	return nil
+}
+
+// jsonObject returns an object with its values as encoding/json decodes
+// them, so values configured in YAML compare equal to recorded ones
+func jsonObject(object map[string]interface{}) map[string]interface{} {
+	if object == nil {
+		return nil
+	}
+	data, err := json.Marshal(object)
+	if err != nil {
+		return object
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return object
+	}
+	return decoded
+}
+
+// compileUserMessage compiles a scenario's user message pattern, returning
+// nil for an empty one
+func compileUserMessage(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Health components
+const (
+	HealthAutomatedScore = "automated_score"
+	HealthUserRating     = "user_rating"
+	HealthIssueSeverity  = "issue_severity"
+	HealthToolFailure    = "tool_failure"
+)
+
+// DefaultHealthPolicy weights automated scores highest and treats a single
+// critical issue as most of the issue component
+var DefaultHealthPolicy = models.HealthPolicy{
+	AutomatedScoreWeight: 0.4,
+	UserRatingWeight:     0.2,
+	IssueSeverityWeight:  0.25,
+	ToolFailureWeight:    0.15,
+	SeverityPenalties: map[string]float64{
+		"low":      0.05,
+		"medium":   0.15,
+		"high":     0.3,
+		"critical": 0.6,
+	},
+}
+
+// HealthInputs are the signals combined into conversation health.
+// Nil fields are unavailable and left out of the weighted mean.
+type HealthInputs struct {
+	OverallScore      *float64
+	Issues            []models.IssueDetected // Only used when OverallScore is set
+	AverageUserRating *float64               // 1-5 scale
+	ToolCalls         int
+	FailedToolCalls   int
+}
+
+// ComputeHealth combines the available inputs into a 0-1 health score. Each
+// component is normalized to 0-1 (higher is healthier) and the score is their
+// weighted mean over the components that are available. It returns false when
+// no component is available.
+func ComputeHealth(in HealthInputs, policy models.HealthPolicy) (float64, map[string]float64, bool) {
+	components := make(map[string]float64)
+	weights := make(map[string]float64)
+
+	if in.OverallScore != nil {
+		components[HealthAutomatedScore] = clamp01(*in.OverallScore)
+		weights[HealthAutomatedScore] = policy.AutomatedScoreWeight
+
+		penalty := 0.0
+		for _, issue := range in.Issues {
+			penalty += policy.SeverityPenalties[issue.Severity]
+		}
+		components[HealthIssueSeverity] = clamp01(1 - penalty)
+		weights[HealthIssueSeverity] = policy.IssueSeverityWeight
+	}
+	if in.AverageUserRating != nil {
+		components[HealthUserRating] = clamp01((*in.AverageUserRating - 1) / 4)
+		weights[HealthUserRating] = policy.UserRatingWeight
+	}
+	if in.ToolCalls > 0 {
+		components[HealthToolFailure] = clamp01(1 - float64(in.FailedToolCalls)/float64(in.ToolCalls))
+		weights[HealthToolFailure] = policy.ToolFailureWeight
+	}
+
+	var total, weightSum float64
+	for name, value := range components {
+		total += value * weights[name]
+		weightSum += weights[name]
+	}
+	if weightSum <= 0 {
+		return 0, components, false
+	}
+
+	return total / weightSum, components, true
+}
+
+// HealthFormula describes how the policy computes conversation health
+func HealthFormula(policy models.HealthPolicy) string {
+	severities := make([]string, 0, len(policy.SeverityPenalties))
+	for severity := range policy.SeverityPenalties {
+		severities = append(severities, severity)
+	}
+	sort.Strings(severities)
+	penalties := make([]string, 0, len(severities))
+	for _, severity := range severities {
+		penalties = append(penalties, fmt.Sprintf("%s=%g", severity, policy.SeverityPenalties[severity]))
+	}
+
+	return fmt.Sprintf(
+		"health = (%g*automated_score + %g*user_rating + %g*issue_severity + %g*tool_failure) / sum of weights of available components; "+
+			"automated_score = latest overall score; user_rating = (average rating - 1) / 4; "+
+			"issue_severity = 1 - sum of issue penalties (%s), floored at 0; tool_failure = 1 - failed tool calls / tool calls",
+		policy.AutomatedScoreWeight, policy.UserRatingWeight, policy.IssueSeverityWeight, policy.ToolFailureWeight,
+		strings.Join(penalties, ", "),
+	)
+}
+
+// CountToolFailures counts tool calls with a recorded result and how many of
+// them failed. Tool turns answering a call that already has a result are not
+// counted again.
+func CountToolFailures(turns []models.Turn) (calls, failed int) {
+	record := func(result map[string]interface{}) {
+		if result == nil {
+			return
+		}
+		calls++
+		if status, ok := result["status"].(string); ok && status != "success" {
+			failed++
+		} else if _, ok := result["error"]; ok {
+			failed++
+		}
+	}
+
+	answered := make(map[string]bool)
+	for _, turn := range turns {
+		for _, call := range turn.ToolCalls {
+			record(call.Result)
+			if call.ID != "" && call.Result != nil {
+				answered[call.ID] = true
+			}
+		}
+	}
+	for _, turn := range turns {
+		if (turn.Role == models.RoleTool || turn.Role == models.RoleFunction) && !answered[turn.ToolCallID] {
+			record(turn.Result)
+		}
+	}
+
+	return calls, failed
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// IntentUnclassified is reported for conversations stored without an intent,
+// such as those ingested before classification existed
+const IntentUnclassified = "unclassified"
+
+// DefaultIntentTaxonomy is used until a taxonomy is configured
+var DefaultIntentTaxonomy = models.IntentTaxonomy{
+	Intents: []models.IntentDefinition{
+		{Name: "search", Keywords: []string{"search*", "find", "look for", "looking for", "available", "options", "show me"}, Tools: []string{"flight_search", "hotel_search"}},
+		{Name: "booking", Keywords: []string{"book*", "reserve*", "reservation", "purchase", "buy"}},
+		{Name: "modification", Keywords: []string{"change", "modify", "reschedule", "upgrade", "update my"}},
+		{Name: "cancellation", Keywords: []string{"cancel*", "refund*"}},
+		{Name: "billing", Keywords: []string{"payment", "charge*", "invoice", "receipt", "billing", "price", "cost"}},
+		{Name: "account", Keywords: []string{"account", "password", "login", "log in", "sign in", "profile"}},
+		{Name: "troubleshooting", Keywords: []string{"error", "not working", "doesn't work", "broken", "problem", "issue", "help with"}},
+	},
+	Fallback: "other",
+}
+
+// Intent scoring weights. The first user turn usually states what the user
+// wants, so its keywords count more than later ones.
+const (
+	intentFirstTurnWeight = 2.0
+	intentLaterTurnWeight = 1.0
+	intentToolWeight      = 2.0
+)
+
+// ClassifyIntent assigns a conversation the taxonomy intent whose keywords
+// and tools it matches most. Each keyword or tool counts once per turn. Ties
+// go to the intent listed first, and conversations matching nothing get the
+// taxonomy's fallback.
+func ClassifyIntent(taxonomy models.IntentTaxonomy, turns []models.Turn) string {
+	scores := make([]float64, len(taxonomy.Intents))
+	firstUserTurn := true
+	for _, turn := range turns {
+		switch turn.Role {
+		case "user":
+			weight := intentLaterTurnWeight
+			if firstUserTurn {
+				weight = intentFirstTurnWeight
+				firstUserTurn = false
+			}
+			padded, words := keywordText(strings.ToLower(turn.Content))
+			for i, intent := range taxonomy.Intents {
+				for _, keyword := range intent.Keywords {
+					if matchesAnyKeyword(padded, words, []string{strings.ToLower(keyword)}) {
+						scores[i] += weight
+					}
+				}
+			}
+		case "assistant":
+			for _, call := range turn.ToolCalls {
+				for i, intent := range taxonomy.Intents {
+					for _, tool := range intent.Tools {
+						if tool == call.ToolName {
+							scores[i] += intentToolWeight
+						}
+					}
+				}
+			}
+		}
+	}
+
+	best, bestScore := taxonomy.Fallback, 0.0
+	for i, intent := range taxonomy.Intents {
+		if scores[i] > bestScore {
+			best, bestScore = intent.Name, scores[i]
+		}
+	}
+	return best
+}
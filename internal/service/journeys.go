@@ -0,0 +1,237 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// DefaultJourneyContacts is how many conversations the completion funnel
+// follows a journey through
+const DefaultJourneyContacts = 5
+
+// journeyChainSamples is how many of the longest escalation chains are listed
+const journeyChainSamples = 10
+
+// Quality funnel steps. Each step keeps the journeys of the one before it
+// that also pass it.
+const (
+	JourneyStepStarted                   = "started"
+	JourneyStepEvaluated                 = "evaluated" // Every conversation evaluated
+	JourneyStepPassing                   = "passing"   // No conversation low scoring or with critical issues
+	JourneyStepResolved                  = "resolved"
+	JourneyStepResolvedWithoutEscalation = "resolved_without_escalation"
+)
+
+// journeyFunnelSteps lists the quality funnel steps in order
+var journeyFunnelSteps = []string{
+	JourneyStepStarted,
+	JourneyStepEvaluated,
+	JourneyStepPassing,
+	JourneyStepResolved,
+	JourneyStepResolvedWithoutEscalation,
+}
+
+// JourneyAnalyzer aggregates journey outcomes from conversations added in
+// journey order, each journey's conversations oldest first, so only one
+// journey is held in memory at a time
+type JourneyAnalyzer struct {
+	lowScoreThreshold float64
+	maxContacts       int
+
+	current              []models.JourneyConversation
+	analysis             models.JourneyAnalysis
+	reached              []int
+	resolvedAt           []int
+	funnel               []int
+	chainLengths         map[int]int
+	contactsToResolution int
+	scoreSum             float64
+	scoredJourneys       int
+}
+
+// NewJourneyAnalyzer creates an analyzer. A conversation fails the quality
+// funnel when its latest score is below lowScoreThreshold or it has critical
+// issues, as in the intent breakdown. The completion funnel follows journeys
+// through maxContacts conversations.
+func NewJourneyAnalyzer(lowScoreThreshold float64, maxContacts int) *JourneyAnalyzer {
+	if maxContacts < 1 {
+		maxContacts = DefaultJourneyContacts
+	}
+	return &JourneyAnalyzer{
+		lowScoreThreshold: lowScoreThreshold,
+		maxContacts:       maxContacts,
+		reached:           make([]int, maxContacts),
+		resolvedAt:        make([]int, maxContacts),
+		funnel:            make([]int, len(journeyFunnelSteps)),
+		chainLengths:      make(map[int]int),
+		analysis: models.JourneyAnalysis{
+			LongestEscalationChains: []models.EscalationChain{},
+		},
+	}
+}
+
+// Add adds the next conversation
+func (a *JourneyAnalyzer) Add(conv *models.JourneyConversation) {
+	if len(a.current) > 0 && a.current[0].JourneyID != conv.JourneyID {
+		a.finishJourney()
+	}
+	a.current = append(a.current, *conv)
+}
+
+// Result returns the analysis of every journey added
+func (a *JourneyAnalyzer) Result() models.JourneyAnalysis {
+	if len(a.current) > 0 {
+		a.finishJourney()
+	}
+
+	result := a.analysis
+	if result.Journeys > 0 {
+		rate := float64(result.Resolved) / float64(result.Journeys)
+		result.ResolutionRate = &rate
+	}
+	if result.Resolved > 0 {
+		avg := float64(a.contactsToResolution) / float64(result.Resolved)
+		result.AvgContactsToResolution = &avg
+	}
+	if a.scoredJourneys > 0 {
+		avg := a.scoreSum / float64(a.scoredJourneys)
+		result.AvgJourneyScore = &avg
+	}
+
+	result.CompletionFunnel = make([]models.JourneyContactStep, a.maxContacts)
+	resolved := 0
+	for i := range result.CompletionFunnel {
+		resolved += a.resolvedAt[i]
+		result.CompletionFunnel[i] = models.JourneyContactStep{
+			Contact:                  i + 1,
+			Reached:                  a.reached[i],
+			Resolved:                 a.resolvedAt[i],
+			CumulativeResolutionRate: journeyShare(resolved, result.Journeys),
+		}
+	}
+
+	result.QualityFunnel = make([]models.JourneyFunnelStep, len(journeyFunnelSteps))
+	for i, step := range journeyFunnelSteps {
+		result.QualityFunnel[i] = models.JourneyFunnelStep{
+			Step:     step,
+			Journeys: a.funnel[i],
+			Rate:     journeyShare(a.funnel[i], result.Journeys),
+		}
+	}
+
+	result.EscalationChainLengths = make([]models.EscalationChainLength, 0, len(a.chainLengths))
+	for length, journeys := range a.chainLengths {
+		result.EscalationChainLengths = append(result.EscalationChainLengths, models.EscalationChainLength{Length: length, Journeys: journeys})
+	}
+	sort.Slice(result.EscalationChainLengths, func(i, j int) bool {
+		return result.EscalationChainLengths[i].Length < result.EscalationChainLengths[j].Length
+	})
+
+	return result
+}
+
+// finishJourney adds the outcome of the current journey to the analysis
+func (a *JourneyAnalyzer) finishJourney() {
+	journey := a.current
+	a.current = nil
+	n := len(journey)
+
+	a.analysis.Journeys++
+	a.analysis.Conversations += n
+
+	resolved := journey[n-1].MissionCompleted
+	if resolved {
+		a.analysis.Resolved++
+		a.contactsToResolution += n
+		if n == 1 {
+			a.analysis.FirstContactResolutions++
+		}
+	}
+	for _, conv := range journey[:n-1] {
+		if conv.MissionCompleted {
+			a.analysis.Reopened++
+			break
+		}
+	}
+
+	contacts := n
+	if contacts > a.maxContacts {
+		contacts = a.maxContacts
+	}
+	for i := 0; i < contacts; i++ {
+		a.reached[i]++
+	}
+	if resolved {
+		a.resolvedAt[contacts-1]++
+	}
+
+	evaluated, passing := true, true
+	scoreSum, scored := 0.0, 0
+	chainStart, chainLength, longestStart, longest := 0, 0, 0, 0
+	for i, conv := range journey {
+		if conv.OverallScore == nil {
+			evaluated = false
+		} else {
+			scoreSum += *conv.OverallScore
+			scored++
+			if *conv.OverallScore < a.lowScoreThreshold {
+				passing = false
+			}
+		}
+		if conv.CriticalIssueCount > 0 {
+			passing = false
+		}
+
+		if !conv.Escalated {
+			chainLength = 0
+			continue
+		}
+		if chainLength == 0 {
+			chainStart = i
+		}
+		chainLength++
+		if chainLength > longest {
+			longestStart, longest = chainStart, chainLength
+		}
+	}
+	if scored > 0 {
+		a.scoreSum += scoreSum / float64(scored)
+		a.scoredJourneys++
+	}
+
+	passed := []bool{true, evaluated, passing, resolved, longest == 0}
+	for i := range journeyFunnelSteps {
+		if !passed[i] {
+			break
+		}
+		a.funnel[i]++
+	}
+
+	if longest == 0 {
+		return
+	}
+	a.analysis.Escalated++
+	a.chainLengths[longest]++
+
+	chain := models.EscalationChain{JourneyID: journey[0].JourneyID, Resolved: resolved}
+	for _, conv := range journey[longestStart : longestStart+longest] {
+		chain.ConversationIDs = append(chain.ConversationIDs, conv.ConversationID)
+	}
+	chains := append(a.analysis.LongestEscalationChains, chain)
+	sort.SliceStable(chains, func(i, j int) bool {
+		return len(chains[i].ConversationIDs) > len(chains[j].ConversationIDs)
+	})
+	if len(chains) > journeyChainSamples {
+		chains = chains[:journeyChainSamples]
+	}
+	a.analysis.LongestEscalationChains = chains
+}
+
+// journeyShare returns n as a share of the journeys, 0 when there are none
+func journeyShare(n, journeys int) float64 {
+	if journeys == 0 {
+		return 0
+	}
+	return float64(n) / float64(journeys)
+}
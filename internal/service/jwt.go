@@ -0,0 +1,124 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JWT roles
+const (
+	RoleAdmin     = "admin"     // Everything, including admin, improvement and meta-evaluation endpoints
+	RoleAnnotator = "annotator" // Annotation and review endpoints, and reads
+	RoleViewer    = "viewer"    // Reads
+	RoleIngestor  = "ingestor"  // Ingesting conversations and feedback, and reads
+)
+
+// Roles are the roles a JWT may claim
+var Roles = []string{RoleAdmin, RoleAnnotator, RoleViewer, RoleIngestor}
+
+// jwtLeeway tolerates clock skew between the token issuer and the API
+const jwtLeeway = 30 * time.Second
+
+// JWTClaims are the claims read from a bearer token
+type JWTClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss,omitempty"`
+	Roles     []string `json:"roles"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+}
+
+// ParseJWT verifies an HS256 signed token and returns its claims. Tokens must
+// expire, and must come from issuer when one is given.
+func ParseJWT(token, secret, issuer string, now time.Time) (*JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	// Only accept the algorithm we sign with, so "none" or an asymmetric
+	// algorithm can't be substituted
+	if header.Alg != "HS256" {
+		return nil, fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("invalid token signature")
+	}
+
+	var claims JWTClaims
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	if claims.ExpiresAt == 0 {
+		return nil, errors.New("token has no expiry")
+	}
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(jwtLeeway)) {
+		return nil, errors.New("token has expired")
+	}
+	if claims.NotBefore != 0 && now.Add(jwtLeeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, errors.New("token is not valid yet")
+	}
+	if issuer != "" && claims.Issuer != issuer {
+		return nil, fmt.Errorf("token issuer %q is not trusted", claims.Issuer)
+	}
+
+	return &claims, nil
+}
+
+// decodeJWTSegment decodes a base64url JSON token segment into v
+func decodeJWTSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// AllowedRoles returns the roles that may call a route. Admin may call
+// everything and is left out.
+func AllowedRoles(method, route string) []string {
+	switch RouteAccess(method, route) {
+	case RouteAccessAdmin:
+		return nil
+	case RouteAccessAnnotate:
+		return []string{RoleAnnotator}
+	case RouteAccessRead:
+		return []string{RoleViewer, RoleAnnotator, RoleIngestor}
+	}
+	return []string{RoleIngestor}
+}
+
+// RolesGrant reports whether any of roles may call a route
+func RolesGrant(roles []string, method, route string) bool {
+	allowed := AllowedRoles(method, route)
+	for _, role := range roles {
+		if role == RoleAdmin {
+			return true
+		}
+		for _, a := range allowed {
+			if role == a {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Annotation labels may be hierarchical paths, such as tool_error/timeout.
+// An annotation's single label is its labels joined by labelSeparator, which
+// is how multi-choice Label Studio results were always stored.
+const (
+	labelPathSeparator = "/"
+	labelSeparator     = ","
+)
+
+// maxLabelLength is the size of the annotations.label column
+const maxLabelLength = 255
+
+// Agreement methods
+const (
+	AgreementMajority = "majority" // Every annotation has a single label
+	AgreementJaccard  = "jaccard"  // Mean pairwise Jaccard similarity of label sets
+)
+
+// NormalizeAnnotationLabels validates an annotation's label and labels,
+// merges them into Labels and sets Label to the joined labels
+func NormalizeAnnotationLabels(ann *models.AnnotationCreate) error {
+	given := ann.Labels
+	if ann.Label != "" {
+		given = append([]string{ann.Label}, given...)
+	}
+
+	labels := make([]string, 0, len(given))
+	seen := make(map[string]bool)
+	for _, label := range given {
+		normalized, err := normalizeLabelPath(label)
+		if err != nil {
+			return err
+		}
+		if !seen[normalized] {
+			seen[normalized] = true
+			labels = append(labels, normalized)
+		}
+	}
+	if len(labels) == 0 {
+		return errors.New("label or labels is required")
+	}
+
+	joined := strings.Join(labels, labelSeparator)
+	if len(joined) > maxLabelLength {
+		return fmt.Errorf("labels exceed %d characters", maxLabelLength)
+	}
+	ann.Label = joined
+	ann.Labels = labels
+	return nil
+}
+
+// normalizeLabelPath trims a label and each segment of its path
+func normalizeLabelPath(label string) (string, error) {
+	if strings.Contains(label, labelSeparator) {
+		return "", fmt.Errorf("label %q may not contain %q", label, labelSeparator)
+	}
+	segments := strings.Split(strings.TrimSpace(label), labelPathSeparator)
+	for i, segment := range segments {
+		segments[i] = strings.TrimSpace(segment)
+		if segments[i] == "" {
+			return "", fmt.Errorf("label %q has an empty path segment", label)
+		}
+	}
+	return strings.Join(segments, labelPathSeparator), nil
+}
+
+// AnnotationLabels returns the labels of a stored annotation, falling back to
+// its single label for annotations stored before labels existed
+func AnnotationLabels(ann models.Annotation) []string {
+	var labels []string
+	json.Unmarshal(ann.Labels, &labels)
+	if len(labels) == 0 && ann.Label != "" {
+		labels = strings.Split(ann.Label, labelSeparator)
+	}
+	return labels
+}
+
+// labelAncestors returns a label path and every ancestor path, so that
+// tool_error/timeout and tool_error/rate_limit partially agree on tool_error
+func labelAncestors(label string) []string {
+	segments := strings.Split(label, labelPathSeparator)
+	paths := make([]string, len(segments))
+	for i := range segments {
+		paths[i] = strings.Join(segments[:i+1], labelPathSeparator)
+	}
+	return paths
+}
+
+// LabelAgreement is how far annotators agree on a conversation's labels
+type LabelAgreement struct {
+	Method         string
+	Score          float64
+	MajorityLabel  string
+	MajorityLabels []string
+}
+
+// ComputeLabelAgreement measures agreement between the label sets of several
+// annotations. Single-label annotations agree by majority vote. Once any
+// annotation has several labels, agreement is the mean pairwise Jaccard
+// similarity of the label sets expanded with their ancestors, and the
+// majority labels are those more than half the annotators chose.
+func ComputeLabelAgreement(labelSets [][]string) LabelAgreement {
+	multiLabel := false
+	for _, labels := range labelSets {
+		multiLabel = multiLabel || len(labels) > 1
+	}
+	if !multiLabel {
+		return majorityAgreement(labelSets)
+	}
+
+	agreement := LabelAgreement{Method: AgreementJaccard, Score: 1}
+	if len(labelSets) > 1 {
+		expanded := make([]map[string]bool, len(labelSets))
+		for i, labels := range labelSets {
+			expanded[i] = make(map[string]bool)
+			for _, label := range labels {
+				for _, path := range labelAncestors(label) {
+					expanded[i][path] = true
+				}
+			}
+		}
+
+		total, pairs := 0.0, 0
+		for i := range expanded {
+			for j := i + 1; j < len(expanded); j++ {
+				total += jaccard(expanded[i], expanded[j])
+				pairs++
+			}
+		}
+		agreement.Score = total / float64(pairs)
+	}
+
+	counts := make(map[string]int)
+	for _, labels := range labelSets {
+		for _, label := range labels {
+			counts[label]++
+		}
+	}
+	for label, count := range counts {
+		if count*2 > len(labelSets) {
+			agreement.MajorityLabels = append(agreement.MajorityLabels, label)
+		}
+	}
+	sort.Strings(agreement.MajorityLabels)
+	agreement.MajorityLabel = strings.Join(agreement.MajorityLabels, labelSeparator)
+
+	return agreement
+}
+
+// majorityAgreement is the share of annotations with the most common label.
+// Ties go to the alphabetically first label.
+func majorityAgreement(labelSets [][]string) LabelAgreement {
+	counts := make(map[string]int)
+	for _, labels := range labelSets {
+		if len(labels) > 0 {
+			counts[labels[0]]++
+		}
+	}
+
+	agreement := LabelAgreement{Method: AgreementMajority, Score: 1}
+	maxCount := 0
+	for label, count := range counts {
+		if count > maxCount || (count == maxCount && label < agreement.MajorityLabel) {
+			maxCount = count
+			agreement.MajorityLabel = label
+		}
+	}
+	if agreement.MajorityLabel != "" {
+		agreement.MajorityLabels = []string{agreement.MajorityLabel}
+	}
+	if len(labelSets) > 1 {
+		agreement.Score = float64(maxCount) / float64(len(labelSets))
+	}
+
+	return agreement
+}
+
+// jaccard is the size of the intersection of two sets over their union
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	intersection := 0
+	for label := range a {
+		if b[label] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// labelStudioAnnotatorPrefix namespaces annotator IDs of Label Studio users
+// that have no email in the webhook payload
+const labelStudioAnnotatorPrefix = "labelstudio:"
+
+// LabelStudioAnnotations maps a completed Label Studio annotation to internal
+// annotations, one per control (from_name). Choice-like results become the
+// label and number or rating results the score; text areas become notes on
+// every annotation. The conversation comes from the task's conversation_id,
+// as set by the annotation export.
+func LabelStudioAnnotations(task *models.LabelStudioWebhookTask, ann *models.LabelStudioWebhookAnnotation) ([]models.AnnotationCreate, error) {
+	if task == nil || ann == nil {
+		return nil, errors.New("event has no task or annotation")
+	}
+	conversationID, _ := task.Data["conversation_id"].(string)
+	if conversationID == "" {
+		return nil, fmt.Errorf("task %d has no conversation_id", task.ID)
+	}
+	annotatorID, err := labelStudioAnnotator(ann.CompletedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	byControl := make(map[string]*models.AnnotationCreate)
+	var order []string
+	var notes []string
+	for _, result := range ann.Result {
+		if result.Type == "textarea" {
+			notes = append(notes, resultStrings(result.Value["text"])...)
+			continue
+		}
+
+		labels, score, ok := resultValue(result)
+		if !ok {
+			continue
+		}
+		created, exists := byControl[result.FromName]
+		if !exists {
+			created = &models.AnnotationCreate{
+				ConversationID:   conversationID,
+				AnnotatorID:      annotatorID,
+				AnnotationType:   result.FromName,
+				TimeSpentSeconds: int(math.Round(ann.LeadTime)),
+			}
+			byControl[result.FromName] = created
+			order = append(order, result.FromName)
+		}
+		if len(labels) > 0 {
+			created.Labels = labels
+		}
+		if score != nil {
+			created.Score = score
+		}
+	}
+
+	annotations := make([]models.AnnotationCreate, 0, len(order))
+	for _, control := range order {
+		created := byControl[control]
+		if len(created.Labels) == 0 && created.Score != nil {
+			created.Labels = []string{strconv.FormatFloat(*created.Score, 'g', -1, 64)}
+		}
+		if err := NormalizeAnnotationLabels(created); err != nil {
+			return nil, fmt.Errorf("invalid labels for %s: %w", control, err)
+		}
+		created.Notes = strings.Join(notes, "\n")
+		annotations = append(annotations, *created)
+	}
+
+	return annotations, nil
+}
+
+// resultValue extracts the labels or score of a Label Studio result
+func resultValue(result models.LabelStudioResult) ([]string, *float64, bool) {
+	switch result.Type {
+	case "choices", "labels", "taxonomy":
+		labels := resultStrings(result.Value[result.Type])
+		if len(labels) == 0 {
+			return nil, nil, false
+		}
+		return labels, nil, true
+	case "number", "rating":
+		score, ok := result.Value[result.Type].(float64)
+		if !ok {
+			return nil, nil, false
+		}
+		return nil, &score, true
+	}
+	return nil, nil, false
+}
+
+// resultStrings flattens a result value that may be a string, a list of
+// strings or, for taxonomies, a list of paths
+func resultStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			if path := resultStrings(item); len(path) > 0 {
+				out = append(out, strings.Join(path, "/"))
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// labelStudioAnnotator resolves the annotator ID of a completed_by value,
+// preferring the user's email
+func labelStudioAnnotator(raw json.RawMessage) (string, error) {
+	var id int64
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return labelStudioAnnotatorPrefix + strconv.FormatInt(id, 10), nil
+	}
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(raw, &user); err != nil || (user.ID == 0 && user.Email == "") {
+		return "", errors.New("annotation has no completed_by user")
+	}
+	if user.Email != "" {
+		return user.Email, nil
+	}
+	return labelStudioAnnotatorPrefix + strconv.FormatInt(user.ID, 10), nil
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// ManifestSource is what influenced an evaluation, as known to whoever ran
+// it. Request is nil for results reported by external consumers.
+type ManifestSource struct {
+	Request    *EvaluationRequest
+	Result     *EvaluationResult
+	Segments   SegmentPolicy
+	JudgeModel string
+	FromTurnID int
+	Budget     *models.EvaluationBudget
+	TimeoutsMS map[string]int
+}
+
+// NewEvaluationManifest builds the manifest stored with an evaluation
+func NewEvaluationManifest(src ManifestSource) (json.RawMessage, error) {
+	manifest := models.EvaluationManifest{
+		EvaluatorVersions:   map[string]string{},
+		EvaluatorVersion:    src.Result.EvaluatorVersion,
+		ScoreWeights:        map[string]float64{},
+		JudgeModel:          src.JudgeModel,
+		Tokenizer:           src.Segments.Tokenizer.Name,
+		Budget:              src.Budget,
+		EvaluatorTimeoutsMS: src.TimeoutsMS,
+		External:            src.Request == nil,
+		Truncation: models.ManifestTruncation{
+			FromTurnID:          src.FromTurnID,
+			SegmentMaxTurns:     src.Segments.MaxTurns,
+			SegmentOverlapTurns: src.Segments.OverlapTurns,
+			SegmentMaxTokens:    src.Segments.MaxTokens,
+			Segments:            src.Result.Segments,
+		},
+	}
+
+	if src.Request != nil {
+		manifest.EvaluatorTypes = src.Request.EvaluatorTypes
+		for evaluatorType, version := range src.Request.EvaluatorVersions {
+			manifest.EvaluatorVersions[evaluatorType] = version
+		}
+		manifest.RubricID, _ = src.Request.Metadata["rubric_id"].(string)
+		manifest.Truncation.Turns = len(src.Request.Turns)
+	} else {
+		// Only the components the consumer reported are known
+		for name := range src.Result.Scores {
+			for evaluatorType, component := range evaluatorComponents {
+				if component.score == name {
+					manifest.EvaluatorTypes = append(manifest.EvaluatorTypes, evaluatorType)
+				}
+			}
+		}
+		sort.Strings(manifest.EvaluatorTypes)
+	}
+	if len(manifest.EvaluatorTypes) == 0 {
+		manifest.EvaluatorTypes = DefaultEvaluatorTypes
+	}
+	for _, evaluatorType := range manifest.EvaluatorTypes {
+		if component, ok := evaluatorComponents[evaluatorType]; ok {
+			manifest.ScoreWeights[component.score] = component.weight
+		}
+	}
+
+	hash, err := manifestConfigHash(manifest)
+	if err != nil {
+		return nil, err
+	}
+	manifest.ConfigHash = hash
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return data, nil
+}
+
+// manifestConfigHash hashes the scoring configuration of a manifest, leaving
+// out what depends on the conversation or the evaluator's response
+func manifestConfigHash(manifest models.EvaluationManifest) (string, error) {
+	manifest.ConfigHash = ""
+	manifest.EvaluatorVersion = ""
+	manifest.RubricID = ""
+	manifest.Truncation.FromTurnID = 0
+	manifest.Truncation.Turns = 0
+	manifest.Truncation.Segments = 0
+
+	// Maps marshal with sorted keys, so equal configurations hash equally
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ScoringFingerprint hashes what in a manifest decides how conversations
+// score: the evaluator service's version, the evaluator types and the
+// versions requested of them, the score weights and the judge model. Scores
+// under different fingerprints aren't comparable; budgets, timeouts and
+// truncation don't change the fingerprint. It is empty for manifests
+// without an evaluator version, such as those of external evaluations.
+func ScoringFingerprint(manifest json.RawMessage) (string, error) {
+	var parsed models.EvaluationManifest
+	if err := json.Unmarshal(defaultManifest(manifest), &parsed); err != nil {
+		return "", fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if parsed.EvaluatorVersion == "" || parsed.External {
+		return "", nil
+	}
+
+	evaluatorTypes := append([]string(nil), parsed.EvaluatorTypes...)
+	sort.Strings(evaluatorTypes)
+	// Absent and empty maps fingerprint alike
+	if len(parsed.EvaluatorVersions) == 0 {
+		parsed.EvaluatorVersions = nil
+	}
+	data, err := json.Marshal(models.EvaluationManifest{
+		EvaluatorVersion:  parsed.EvaluatorVersion,
+		EvaluatorTypes:    evaluatorTypes,
+		EvaluatorVersions: parsed.EvaluatorVersions,
+		ScoreWeights:      parsed.ScoreWeights,
+		JudgeModel:        parsed.JudgeModel,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ManifestChanges lists the top-level fields in which a manifest differs
+// from an earlier one, by field name. Manifests of evaluations stored before
+// manifests existed are empty, so every field they have is reported.
+func ManifestChanges(previous, current json.RawMessage) ([]models.ManifestChange, error) {
+	var from, to map[string]interface{}
+	if err := json.Unmarshal(defaultManifest(previous), &from); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if err := json.Unmarshal(defaultManifest(current), &to); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	fields := make(map[string]bool, len(to))
+	for field := range from {
+		fields[field] = true
+	}
+	for field := range to {
+		fields[field] = true
+	}
+	delete(fields, "config_hash")
+
+	changes := []models.ManifestChange{}
+	for field := range fields {
+		if !reflect.DeepEqual(from[field], to[field]) {
+			changes = append(changes, models.ManifestChange{Field: field, From: from[field], To: to[field]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// defaultManifest returns an empty manifest for evaluations without one
+func defaultManifest(manifest json.RawMessage) json.RawMessage {
+	if len(manifest) == 0 {
+		return json.RawMessage(`{}`)
+	}
+	return manifest
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Masking profiles, from least to most restrictive
+const (
+	MaskingFull         = "full"          // Transcripts as ingested
+	MaskingPII          = "pii_masked"    // Emails, phone numbers, card and social security numbers and IP addresses replaced
+	MaskingMetadataOnly = "metadata_only" // Content and tool payloads removed; roles, tools, timings and scores remain
+)
+
+// MaskingProfiles lists the masking profiles from least to most restrictive
+var MaskingProfiles = []string{MaskingFull, MaskingPII, MaskingMetadataOnly}
+
+// piiPatterns are replaced in masked text, in order, so card numbers are
+// replaced before their digits could be taken for a phone number
+var piiPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[EMAIL]"},
+	{regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), "[CARD]"},
+	{regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), "[SSN]"},
+	{regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)|\b\d{3})[\s.-]?\d{3}[\s.-]?\d{4}\b`), "[PHONE]"},
+	{regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), "[IP]"},
+}
+
+// MaskingProfileFor returns the masking profile of a caller with the given
+// JWT roles or API key scopes: the least restrictive profile any of them is
+// mapped to, where unmapped ones see everything. Unknown profile names are
+// treated as the most restrictive.
+func MaskingProfileFor(grants []string, profiles map[string]string) string {
+	best := len(MaskingProfiles) - 1
+	for _, grant := range grants {
+		rank := 0
+		if profile, ok := profiles[grant]; ok {
+			rank = maskingRank(profile)
+		}
+		if rank < best {
+			best = rank
+		}
+	}
+	if len(grants) == 0 {
+		best = 0
+	}
+	return MaskingProfiles[best]
+}
+
+// maskingRank returns a profile's position in MaskingProfiles, or the most
+// restrictive position if it is unknown
+func maskingRank(profile string) int {
+	for i, p := range MaskingProfiles {
+		if p == profile {
+			return i
+		}
+	}
+	return len(MaskingProfiles) - 1
+}
+
+// ValidateMaskingProfiles checks that roles and scopes are mapped to known
+// profiles
+func ValidateMaskingProfiles(profiles map[string]string) error {
+	for grant, profile := range profiles {
+		known := false
+		for _, p := range MaskingProfiles {
+			known = known || p == profile
+		}
+		if !known {
+			return fmt.Errorf("unknown masking profile %q for %s", profile, grant)
+		}
+	}
+	return nil
+}
+
+// MaskTurns applies a masking profile to turns in place
+func MaskTurns(turns []models.Turn, profile string) {
+	switch maskingRank(profile) {
+	case 0:
+		return
+	case 1:
+		for i := range turns {
+			turn := &turns[i]
+			turn.Content = MaskPII(turn.Content)
+			for j := range turn.ToolCalls {
+				turn.ToolCalls[j].Parameters = maskPayload(turn.ToolCalls[j].Parameters)
+				turn.ToolCalls[j].Result = maskPayload(turn.ToolCalls[j].Result)
+			}
+			turn.Result = maskPayload(turn.Result)
+			for j := range turn.Attachments {
+				// URLs and digests may grant access to the attachment itself
+				turn.Attachments[j].Name = MaskPII(turn.Attachments[j].Name)
+				turn.Attachments[j].URL = ""
+				turn.Attachments[j].SHA256 = ""
+			}
+		}
+	default:
+		for i := range turns {
+			turn := &turns[i]
+			turn.Content = ""
+			for j := range turn.ToolCalls {
+				turn.ToolCalls[j].Parameters = nil
+				turn.ToolCalls[j].Result = nil
+			}
+			turn.Result = nil
+			for j := range turn.Attachments {
+				turn.Attachments[j].Name = ""
+				turn.Attachments[j].URL = ""
+				turn.Attachments[j].SHA256 = ""
+			}
+		}
+	}
+}
+
+// MaskTurnsJSON applies a masking profile to JSON encoded turns
+func MaskTurnsJSON(raw json.RawMessage, profile string) (json.RawMessage, error) {
+	if profile == MaskingFull || len(raw) == 0 {
+		return raw, nil
+	}
+
+	var turns []models.Turn
+	if err := json.Unmarshal(raw, &turns); err != nil {
+		return nil, fmt.Errorf("failed to parse turns: %w", err)
+	}
+	MaskTurns(turns, profile)
+
+	masked, err := json.Marshal(turns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal turns: %w", err)
+	}
+	return masked, nil
+}
+
+// MaskPII replaces personal data in text with placeholders
+func MaskPII(text string) string {
+	for _, p := range piiPatterns {
+		text = p.pattern.ReplaceAllString(text, p.replacement)
+	}
+	return text
+}
+
+// maskPayload masks the personal data in every string of a tool payload
+func maskPayload(payload map[string]interface{}) map[string]interface{} {
+	if payload == nil {
+		return nil
+	}
+	masked, _ := maskValue(payload).(map[string]interface{})
+	return masked
+}
+
+// maskValue masks the personal data in the strings of a JSON value
+func maskValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return MaskPII(v)
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			masked[key] = maskValue(item)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, item := range v {
+			masked[i] = maskValue(item)
+		}
+		return masked
+	}
+	return value
+}
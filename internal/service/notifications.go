@@ -0,0 +1,201 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Digest frequencies
+const (
+	DigestNone   = "none"
+	DigestHourly = "hourly"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// digestIntervals maps digest frequencies to their period
+var digestIntervals = map[string]time.Duration{
+	DigestHourly: time.Hour,
+	DigestDaily:  24 * time.Hour,
+	DigestWeekly: 7 * 24 * time.Hour,
+}
+
+// Notification channels
+const (
+	ChannelEmail   = "email"
+	ChannelSlack   = "slack"
+	ChannelWebhook = "webhook"
+)
+
+// quietHoursLayout is the clock format used for quiet hours
+const quietHoursLayout = "15:04"
+
+// AlertDelivery is an alert addressed to a team's notification channels. An
+// alert no team has preferences for is addressed to no team in particular
+// and has no channels.
+type AlertDelivery struct {
+	ProjectID string      `json:"project_id,omitempty"`
+	Team      string      `json:"team,omitempty"`
+	Channels  []string    `json:"channels,omitempty"`
+	Severity  string      `json:"severity"`
+	Message   string      `json:"message"` // One line description of the alert
+	Alert     interface{} `json:"alert"`
+}
+
+// NormalizeNotificationPreference fills defaults and validates quiet hours and timezone
+func NormalizeNotificationPreference(pref *models.NotificationPreferenceUpdate) error {
+	if pref.DigestFrequency == "" {
+		pref.DigestFrequency = DigestNone
+	}
+	if pref.Timezone == "" {
+		pref.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(pref.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q", pref.Timezone)
+	}
+
+	if (pref.QuietHoursStart == "") != (pref.QuietHoursEnd == "") {
+		return fmt.Errorf("quiet_hours_start and quiet_hours_end must be set together")
+	}
+	for _, value := range []string{pref.QuietHoursStart, pref.QuietHoursEnd} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(quietHoursLayout, value); err != nil {
+			return fmt.Errorf("invalid quiet hours %q: expected HH:MM", value)
+		}
+	}
+
+	return nil
+}
+
+// InQuietHours reports whether now falls inside the preference's quiet hours.
+// Quiet hours may wrap past midnight (e.g. 22:00-07:00).
+func InQuietHours(pref *models.NotificationPreference, now time.Time) bool {
+	if pref.QuietHoursStart == "" || pref.QuietHoursEnd == "" {
+		return false
+	}
+
+	start, err := time.Parse(quietHoursLayout, pref.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(quietHoursLayout, pref.QuietHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	loc, err := time.LoadLocation(pref.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	if startMinute <= endMinute {
+		return minute >= startMinute && minute < endMinute
+	}
+	return minute >= startMinute || minute < endMinute
+}
+
+// AcceptsSeverity reports whether the preference accepts alerts of the given
+// severity. An empty severity list accepts every severity.
+func AcceptsSeverity(pref *models.NotificationPreference, severity string) bool {
+	var severities []string
+	if len(pref.Severities) > 0 {
+		if err := json.Unmarshal(pref.Severities, &severities); err != nil {
+			return false
+		}
+	}
+	if len(severities) == 0 {
+		return true
+	}
+
+	for _, s := range severities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldNotify reports whether an alert of the given severity should be sent
+// immediately. Critical alerts bypass quiet hours.
+func ShouldNotify(pref *models.NotificationPreference, severity string, now time.Time) bool {
+	if !AcceptsSeverity(pref, severity) {
+		return false
+	}
+	return severity == "critical" || !InQuietHours(pref, now)
+}
+
+// PreferenceChannels lists the channels a team is notified on
+func PreferenceChannels(pref *models.NotificationPreference) []string {
+	var channels []string
+	if len(pref.Channels) > 0 {
+		json.Unmarshal(pref.Channels, &channels)
+	}
+	return channels
+}
+
+// HasChannel reports whether a team is notified on channel
+func HasChannel(pref *models.NotificationPreference, channel string) bool {
+	for _, c := range PreferenceChannels(pref) {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// RouteAlert addresses an alert to every team whose preferences accept its
+// severity now. When no team has preferences the alert is sent once, to no
+// team in particular, as it was before teams could set preferences.
+func RouteAlert(prefs []models.NotificationPreference, severity, message string, alert interface{}, now time.Time) []AlertDelivery {
+	if len(prefs) == 0 {
+		return []AlertDelivery{{Severity: severity, Message: message, Alert: alert}}
+	}
+
+	var deliveries []AlertDelivery
+	for i := range prefs {
+		pref := &prefs[i]
+		if !ShouldNotify(pref, severity, now) {
+			continue
+		}
+		deliveries = append(deliveries, AlertDelivery{
+			ProjectID: pref.ProjectID,
+			Team:      pref.Team,
+			Channels:  PreferenceChannels(pref),
+			Severity:  severity,
+			Message:   message,
+			Alert:     alert,
+		})
+	}
+	return deliveries
+}
+
+// DigestDue reports whether a digest should be sent for the preference
+func DigestDue(pref *models.NotificationPreference, now time.Time) bool {
+	interval, ok := digestIntervals[pref.DigestFrequency]
+	if !ok {
+		return false
+	}
+	if !pref.LastDigestAt.Valid {
+		return true
+	}
+	return now.Sub(pref.LastDigestAt.Time) >= interval
+}
+
+// DigestSince returns the start of the period a due digest covers: the last
+// digest, or one digest interval back for the first one
+func DigestSince(pref *models.NotificationPreference, now time.Time) time.Time {
+	if pref.LastDigestAt.Valid {
+		return pref.LastDigestAt.Time
+	}
+	return now.Add(-digestIntervals[pref.DigestFrequency])
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// openAIContentPart is one part of a message's array content
+type openAIContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Refusal  string `json:"refusal"`
+	ImageURL *struct {
+		URL string `json:"url"`
+	} `json:"image_url"`
+	File *struct {
+		Filename string `json:"filename"`
+		FileID   string `json:"file_id"`
+	} `json:"file"`
+}
+
+// ConvertOpenAIConversation converts a conversation in the OpenAI chat
+// completions message format into the ingestion format. Messages become
+// turns numbered from 1; text content parts are joined, and image and file
+// parts become attachments. Tool call arguments are decoded into parameters,
+// and tool messages whose content is a JSON object carry it as their result.
+// Developer messages are stored as system turns.
+func ConvertOpenAIConversation(conv *models.OpenAIConversation) (*models.ConversationCreate, error) {
+	result := &models.ConversationCreate{
+		ConversationID: conv.ConversationID,
+		AgentVersion:   conv.AgentVersion,
+		Turns:          make([]models.Turn, 0, len(conv.Messages)),
+		Feedback:       conv.Feedback,
+		Metadata:       conv.Metadata,
+		Intent:         conv.Intent,
+	}
+
+	for i, message := range conv.Messages {
+		turn := models.Turn{
+			TurnID:     i + 1,
+			Role:       message.Role,
+			Name:       message.Name,
+			ToolCallID: message.ToolCallID,
+			Timestamp:  message.Timestamp,
+		}
+		if turn.Role == "developer" {
+			turn.Role = models.RoleSystem
+		}
+
+		content, attachments, err := openAIContent(message.Content)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		turn.Content = content
+		turn.Attachments = attachments
+
+		for _, call := range message.ToolCalls {
+			parameters, err := openAIArguments(call.Function.Arguments)
+			if err != nil {
+				return nil, fmt.Errorf("message %d: tool call %s: %w", i, call.Function.Name, err)
+			}
+			turn.ToolCalls = append(turn.ToolCalls, models.ToolCall{
+				ID:         call.ID,
+				ToolName:   call.Function.Name,
+				Parameters: parameters,
+			})
+		}
+		if call := message.FunctionCall; call != nil {
+			parameters, err := openAIArguments(call.Arguments)
+			if err != nil {
+				return nil, fmt.Errorf("message %d: function call %s: %w", i, call.Name, err)
+			}
+			turn.ToolCalls = append(turn.ToolCalls, models.ToolCall{
+				ToolName:   call.Name,
+				Parameters: parameters,
+			})
+		}
+
+		if turn.Role == models.RoleTool || turn.Role == models.RoleFunction {
+			var object map[string]interface{}
+			if json.Unmarshal([]byte(content), &object) == nil {
+				turn.Result = object
+			}
+		}
+
+		result.Turns = append(result.Turns, turn)
+	}
+
+	return result, nil
+}
+
+// openAIContent reads a message's content, a string, an array of content
+// parts or null
+func openAIContent(raw json.RawMessage) (string, []models.Attachment, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", nil, nil
+	}
+	if raw[0] == '"' {
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return "", nil, fmt.Errorf("invalid content: %w", err)
+		}
+		return text, nil, nil
+	}
+
+	var parts []openAIContentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", nil, fmt.Errorf("content must be a string or an array of content parts: %w", err)
+	}
+
+	var texts []string
+	var attachments []models.Attachment
+	for _, part := range parts {
+		switch {
+		case part.Type == "text":
+			texts = append(texts, part.Text)
+		case part.Type == "refusal":
+			texts = append(texts, part.Refusal)
+		case part.Type == "image_url" && part.ImageURL != nil:
+			attachments = append(attachments, openAIImage(part.ImageURL.URL))
+		case part.Type == "file" && part.File != nil:
+			name := part.File.Filename
+			if name == "" {
+				name = part.File.FileID
+			}
+			attachments = append(attachments, models.Attachment{Type: "file", Name: name})
+		}
+	}
+	return strings.Join(texts, "\n"), attachments, nil
+}
+
+// openAIImage describes an image part. The content of base64 data URLs is
+// carried as inline attachment data; other data URLs keep only their MIME
+// type.
+func openAIImage(url string) models.Attachment {
+	if !strings.HasPrefix(url, "data:") {
+		return models.Attachment{Type: "image", URL: url}
+	}
+	header, data, _ := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+	mimeType, params, _ := strings.Cut(header, ";")
+	attachment := models.Attachment{Type: "image", MimeType: mimeType}
+	if params == "base64" || strings.HasSuffix(params, ";base64") {
+		attachment.Data = data
+	}
+	return attachment
+}
+
+// openAIArguments decodes a call's JSON encoded arguments. Empty arguments
+// decode to no parameters.
+func openAIArguments(arguments string) (map[string]interface{}, error) {
+	parameters := map[string]interface{}{}
+	if strings.TrimSpace(arguments) == "" {
+		return parameters, nil
+	}
+	if err := json.Unmarshal([]byte(arguments), &parameters); err != nil {
+		return nil, fmt.Errorf("arguments are not a JSON object: %w", err)
+	}
+	return parameters, nil
+}
@@ -0,0 +1,493 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// The OTLP/HTTP JSON encoding of traces. Trace and span IDs are hex strings
+// and 64-bit integers may be strings, as in the protobuf JSON mapping.
+
+type otlpTraceRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []struct {
+		Spans []otlpSpan `json:"spans"`
+	} `json:"scopeSpans"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	Name              string          `json:"name"`
+	StartTimeUnixNano otlpInt         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   otlpInt         `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue"`
+	BoolValue   *bool    `json:"boolValue"`
+	IntValue    *otlpInt `json:"intValue"`
+	DoubleValue *float64 `json:"doubleValue"`
+	ArrayValue  *struct {
+		Values []otlpAnyValue `json:"values"`
+	} `json:"arrayValue"`
+}
+
+// otlpStatusError is the status code of a span that failed
+const otlpStatusError = 2
+
+// otlpInt is a 64-bit integer encoded as a JSON string or number
+type otlpInt int64
+
+func (i *otlpInt) UnmarshalJSON(data []byte) error {
+	n, err := strconv.ParseInt(string(bytes.Trim(data, `"`)), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*i = otlpInt(n)
+	return nil
+}
+
+// value returns the Go value of an attribute value
+func (v otlpAnyValue) value() interface{} {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.IntValue != nil:
+		return int64(*v.IntValue)
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	case v.ArrayValue != nil:
+		values := make([]interface{}, len(v.ArrayValue.Values))
+		for i, value := range v.ArrayValue.Values {
+			values[i] = value.value()
+		}
+		return values
+	}
+	return nil
+}
+
+// otlpAttributes holds the attributes of a span, falling back to those of
+// its resource
+type otlpAttributes struct {
+	span, resource map[string]interface{}
+}
+
+func attributeMap(attributes []otlpAttribute) map[string]interface{} {
+	m := make(map[string]interface{}, len(attributes))
+	for _, attribute := range attributes {
+		m[attribute.Key] = attribute.Value.value()
+	}
+	return m
+}
+
+// get returns an attribute's value as a string, or an empty string
+func (a otlpAttributes) get(keys ...string) string {
+	for _, key := range keys {
+		for _, m := range []map[string]interface{}{a.span, a.resource} {
+			switch v := m[key].(type) {
+			case string:
+				if v != "" {
+					return v
+				}
+			case nil:
+			default:
+				return fmt.Sprint(v)
+			}
+		}
+	}
+	return ""
+}
+
+// has reports whether the span has an attribute
+func (a otlpAttributes) has(key string) bool {
+	_, ok := a.span[key]
+	return ok
+}
+
+// otlpTraceSpan is a span with its attributes resolved
+type otlpTraceSpan struct {
+	otlpSpan
+	attributes otlpAttributes
+}
+
+func (s *otlpTraceSpan) start() time.Time {
+	return time.Unix(0, int64(s.StartTimeUnixNano)).UTC()
+}
+
+func (s *otlpTraceSpan) end() time.Time {
+	return time.Unix(0, int64(s.EndTimeUnixNano)).UTC()
+}
+
+// isLLMCall reports whether the span records the messages of a model call
+func (s *otlpTraceSpan) isLLMCall() bool {
+	a := s.attributes
+	return a.has("gen_ai.input.messages") || a.has("gen_ai.output.messages") ||
+		a.has("gen_ai.prompt.0.role") || a.has("gen_ai.completion.0.role")
+}
+
+// isToolCall reports whether the span records the execution of a tool
+func (s *otlpTraceSpan) isToolCall() bool {
+	return s.attributes.get("gen_ai.operation.name") == "execute_tool" || s.attributes.has("gen_ai.tool.name")
+}
+
+// OTLPConversation is a conversation assembled from trace spans. Err is set
+// when the spans couldn't be turned into a conversation.
+type OTLPConversation struct {
+	ConversationID string
+	Conversation   *models.ConversationCreate
+	Spans          int
+	Err            error
+}
+
+// ConvertOTLPTraces assembles conversations from an OTLP/HTTP JSON trace
+// export, following the OpenTelemetry generative AI conventions.
+//
+// Spans are grouped into a conversation by their gen_ai.conversation.id or
+// session.id attribute, or else by trace. Model call spans contribute their
+// messages, from gen_ai.input.messages and gen_ai.output.messages or the
+// indexed gen_ai.prompt and gen_ai.completion attributes; since each call's
+// input repeats the history, only messages beyond the transcript so far are
+// added. Tool spans (gen_ai.operation.name execute_tool) give the call they
+// match by gen_ai.tool.call.id, or else by gen_ai.tool.name, its latency and
+// missing result. The agent version is the resource's service.version, or
+// defaultAgentVersion.
+func ConvertOTLPTraces(data []byte, defaultAgentVersion string) ([]OTLPConversation, error) {
+	var req otlpTraceRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("invalid OTLP JSON: %w", err)
+	}
+
+	var order []string
+	byConversation := make(map[string][]*otlpTraceSpan)
+	for _, resourceSpans := range req.ResourceSpans {
+		resource := attributeMap(resourceSpans.Resource.Attributes)
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			for _, span := range scopeSpans.Spans {
+				traceSpan := &otlpTraceSpan{
+					otlpSpan:   span,
+					attributes: otlpAttributes{span: attributeMap(span.Attributes), resource: resource},
+				}
+				id := traceSpan.attributes.get("gen_ai.conversation.id", "session.id")
+				if id == "" {
+					id = span.TraceID
+				}
+				if _, ok := byConversation[id]; !ok {
+					order = append(order, id)
+				}
+				byConversation[id] = append(byConversation[id], traceSpan)
+			}
+		}
+	}
+
+	conversations := make([]OTLPConversation, 0, len(order))
+	for _, id := range order {
+		spans := byConversation[id]
+		conv, err := otlpConversation(id, spans, defaultAgentVersion)
+		conversations = append(conversations, OTLPConversation{
+			ConversationID: id,
+			Conversation:   conv,
+			Spans:          len(spans),
+			Err:            err,
+		})
+	}
+	return conversations, nil
+}
+
+// otlpConversation assembles one conversation from its spans
+func otlpConversation(id string, spans []*otlpTraceSpan, defaultAgentVersion string) (*models.ConversationCreate, error) {
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].StartTimeUnixNano < spans[j].StartTimeUnixNano })
+
+	var turns []models.Turn
+	var tools []*otlpTraceSpan
+	metadata := &models.ConversationMetadata{}
+	agentVersion := ""
+	first, last := spans[0].start(), spans[0].end()
+
+	for _, span := range spans {
+		if span.start().Before(first) {
+			first = span.start()
+		}
+		if span.end().After(last) {
+			last = span.end()
+		}
+		if agentVersion == "" {
+			agentVersion = span.attributes.get("service.version")
+		}
+		if metadata.SessionID == "" {
+			metadata.SessionID = span.attributes.get("session.id")
+		}
+		if metadata.SubjectID == "" {
+			metadata.SubjectID = span.attributes.get("user.id", "enduser.id")
+		}
+
+		switch {
+		case span.isLLMCall():
+			if model := span.attributes.get("gen_ai.response.model", "gen_ai.request.model"); model != "" {
+				metadata.Model = model
+			}
+			input, output, err := otlpMessages(span)
+			if err != nil {
+				return nil, fmt.Errorf("span %s: %w", span.SpanID, err)
+			}
+			if len(input) > len(turns) {
+				for _, turn := range input[len(turns):] {
+					turn.Timestamp = span.start()
+					turns = append(turns, turn)
+				}
+			}
+			for _, turn := range output {
+				turn.Timestamp = span.end()
+				turns = append(turns, turn)
+			}
+		case span.isToolCall():
+			tools = append(tools, span)
+		}
+	}
+
+	if len(turns) == 0 {
+		return nil, fmt.Errorf("no model call spans with message content; enable gen_ai message content capture in the instrumentation")
+	}
+	if agentVersion == "" {
+		agentVersion = defaultAgentVersion
+	}
+	if agentVersion == "" {
+		return nil, fmt.Errorf("no agent version: set the service.version resource attribute or the agent_version query parameter")
+	}
+
+	for i := range turns {
+		turns[i].TurnID = i + 1
+	}
+	for _, span := range tools {
+		otlpApplyToolSpan(turns, span)
+	}
+	metadata.TotalLatencyMS = int(last.Sub(first).Milliseconds())
+
+	return &models.ConversationCreate{
+		ConversationID: id,
+		AgentVersion:   agentVersion,
+		Turns:          turns,
+		Metadata:       metadata,
+	}, nil
+}
+
+// otlpApplyToolSpan records a tool span's latency, and its result or error
+// if the call has none, on the tool call it executed
+func otlpApplyToolSpan(turns []models.Turn, span *otlpTraceSpan) {
+	callID := span.attributes.get("gen_ai.tool.call.id")
+	name := span.attributes.get("gen_ai.tool.name")
+
+	var call *models.ToolCall
+	for i := range turns {
+		for j := range turns[i].ToolCalls {
+			candidate := &turns[i].ToolCalls[j]
+			if callID != "" && candidate.ID == callID {
+				call = candidate
+			} else if callID == "" && call == nil && candidate.ToolName == name && candidate.LatencyMS == 0 {
+				call = candidate
+			}
+		}
+	}
+	if call == nil {
+		return
+	}
+
+	call.LatencyMS = int(span.end().Sub(span.start()).Milliseconds())
+	if call.Result != nil {
+		return
+	}
+	if result, ok := otlpObject(span.attributes.get("gen_ai.tool.call.result")); ok {
+		call.Result = result
+	} else if span.Status.Code == otlpStatusError {
+		call.Result = map[string]interface{}{"error": span.Status.Message}
+	}
+}
+
+// otlpMessages reads the input and output messages of a model call span
+func otlpMessages(span *otlpTraceSpan) ([]models.Turn, []models.Turn, error) {
+	a := span.attributes
+	if !a.has("gen_ai.input.messages") && !a.has("gen_ai.output.messages") {
+		return otlpIndexedMessages(a, "gen_ai.prompt"), otlpIndexedMessages(a, "gen_ai.completion"), nil
+	}
+
+	input, err := otlpPartMessages(a.get("gen_ai.input.messages"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid gen_ai.input.messages: %w", err)
+	}
+	if instructions := a.get("gen_ai.system_instructions"); instructions != "" {
+		system, err := otlpParts(json.RawMessage(instructions))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid gen_ai.system_instructions: %w", err)
+		}
+		system.Role = models.RoleSystem
+		input = append([]models.Turn{system}, input...)
+	}
+	output, err := otlpPartMessages(a.get("gen_ai.output.messages"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid gen_ai.output.messages: %w", err)
+	}
+	return input, output, nil
+}
+
+// otlpPartMessages reads messages made of typed parts, encoded as JSON
+func otlpPartMessages(raw string) ([]models.Turn, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var messages []struct {
+		Role  string          `json:"role"`
+		Name  string          `json:"name"`
+		Parts json.RawMessage `json:"parts"`
+	}
+	if err := json.Unmarshal([]byte(raw), &messages); err != nil {
+		return nil, err
+	}
+
+	turns := make([]models.Turn, 0, len(messages))
+	for _, message := range messages {
+		turn, err := otlpParts(message.Parts)
+		if err != nil {
+			return nil, err
+		}
+		turn.Role = otlpRole(message.Role)
+		if turn.Name == "" {
+			turn.Name = message.Name
+		}
+		turns = append(turns, turn)
+	}
+	return turns, nil
+}
+
+// otlpParts builds a turn from message parts: text, tool calls and tool call
+// responses
+func otlpParts(raw json.RawMessage) (models.Turn, error) {
+	var turn models.Turn
+	var parts []struct {
+		Type      string          `json:"type"`
+		Content   string          `json:"content"`
+		ID        string          `json:"id"`
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+		Response  json.RawMessage `json:"response"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return turn, err
+	}
+
+	var texts []string
+	for _, part := range parts {
+		switch part.Type {
+		case "text":
+			texts = append(texts, part.Content)
+		case "tool_call":
+			turn.ToolCalls = append(turn.ToolCalls, models.ToolCall{
+				ID:         part.ID,
+				ToolName:   part.Name,
+				Parameters: otlpArguments(part.Arguments),
+			})
+		case "tool_call_response":
+			turn.ToolCallID = part.ID
+			content := otlpJSONText(part.Response)
+			if result, ok := otlpObject(content); ok {
+				turn.Result = result
+			}
+			texts = append(texts, content)
+		}
+	}
+	turn.Content = strings.Join(texts, "\n")
+	return turn, nil
+}
+
+// otlpIndexedMessages reads messages recorded as indexed attributes, such as
+// gen_ai.prompt.0.role and gen_ai.completion.0.tool_calls.0.name
+func otlpIndexedMessages(a otlpAttributes, prefix string) []models.Turn {
+	var turns []models.Turn
+	for i := 0; a.has(fmt.Sprintf("%s.%d.role", prefix, i)); i++ {
+		key := fmt.Sprintf("%s.%d", prefix, i)
+		turn := models.Turn{
+			Role:       otlpRole(a.get(key + ".role")),
+			Content:    a.get(key + ".content"),
+			ToolCallID: a.get(key + ".tool_call_id"),
+		}
+		for j := 0; a.has(fmt.Sprintf("%s.tool_calls.%d.name", key, j)); j++ {
+			callKey := fmt.Sprintf("%s.tool_calls.%d", key, j)
+			turn.ToolCalls = append(turn.ToolCalls, models.ToolCall{
+				ID:         a.get(callKey + ".id"),
+				ToolName:   a.get(callKey + ".name"),
+				Parameters: otlpArguments(json.RawMessage(a.get(callKey + ".arguments"))),
+			})
+		}
+		if turn.Role == models.RoleTool {
+			if result, ok := otlpObject(turn.Content); ok {
+				turn.Result = result
+			}
+		}
+		turns = append(turns, turn)
+	}
+	return turns
+}
+
+// otlpRole maps a message role to a turn role
+func otlpRole(role string) string {
+	if role == "developer" {
+		return models.RoleSystem
+	}
+	return role
+}
+
+// otlpArguments reads tool call arguments, an object or an object encoded as
+// a JSON string. Arguments that aren't an object are kept under "arguments".
+func otlpArguments(raw json.RawMessage) map[string]interface{} {
+	text := otlpJSONText(raw)
+	if text == "" {
+		return map[string]interface{}{}
+	}
+	if parameters, ok := otlpObject(text); ok {
+		return parameters
+	}
+	return map[string]interface{}{"arguments": text}
+}
+
+// otlpJSONText returns a JSON string's value, or other JSON as text
+func otlpJSONText(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	var text string
+	if len(raw) > 0 && raw[0] == '"' && json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	return string(raw)
+}
+
+// otlpObject decodes text that is a JSON object
+func otlpObject(text string) (map[string]interface{}, bool) {
+	var object map[string]interface{}
+	if json.Unmarshal([]byte(text), &object) != nil || object == nil {
+		return nil, false
+	}
+	return object, true
+}
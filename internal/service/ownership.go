@@ -0,0 +1,121 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// maxDigestIssues caps how many issues are listed in a digest; the counts
+// still cover every issue
+const maxDigestIssues = 20
+
+// IssueOwner resolves the owner of an issue and the tool it was attributed
+// to. Tools used in the issue's turn are checked first, then the issue type,
+// then the default owner.
+func IssueOwner(issue models.IssueDetected, turns []models.Turn, policy models.OwnershipPolicy) (owner, tool string) {
+	if issue.TurnID != 0 {
+		for _, turn := range turns {
+			if turn.TurnID != issue.TurnID {
+				continue
+			}
+			for _, name := range turnTools(turn) {
+				if owner, ok := policy.Tools[name]; ok {
+					return owner, name
+				}
+				if tool == "" {
+					tool = name
+				}
+			}
+		}
+	}
+
+	if owner, ok := policy.IssueTypes[issue.Type]; ok {
+		return owner, tool
+	}
+	return policy.DefaultOwner, tool
+}
+
+// PatternOwner resolves the owner of a failure pattern from its type, which
+// is either an issue type or a tool name
+func PatternOwner(pattern models.FailurePattern, policy models.OwnershipPolicy) string {
+	if owner, ok := policy.IssueTypes[pattern.PatternType]; ok {
+		return owner
+	}
+	if owner, ok := policy.Tools[pattern.PatternType]; ok {
+		return owner
+	}
+	return policy.DefaultOwner
+}
+
+// AssignIssues resolves the owner of every issue of an evaluation
+func AssignIssues(eval *models.Evaluation, turns []models.Turn, policy models.OwnershipPolicy) ([]models.IssueAssignment, error) {
+	var issues []models.IssueDetected
+	if len(eval.IssuesDetected) > 0 {
+		if err := json.Unmarshal(eval.IssuesDetected, &issues); err != nil {
+			return nil, err
+		}
+	}
+
+	assignments := make([]models.IssueAssignment, len(issues))
+	for i, issue := range issues {
+		owner, tool := IssueOwner(issue, turns, policy)
+		assignments[i] = models.IssueAssignment{
+			EvaluationID:   eval.EvaluationID,
+			IssueIndex:     i,
+			ConversationID: eval.ConversationID,
+			IssueType:      issue.Type,
+			Severity:       issue.Severity,
+			Description:    issue.Description,
+			Tool:           tool,
+			Owner:          owner,
+		}
+	}
+
+	return assignments, nil
+}
+
+// BuildOwnerDigest summarizes a team's issues and patterns of the severities
+// it accepts for a digest
+func BuildOwnerDigest(pref *models.NotificationPreference, issues []models.IssueAssignment, patterns []models.FailurePattern, since, until time.Time) models.OwnerDigest {
+	digest := models.OwnerDigest{
+		ProjectID:          pref.ProjectID,
+		Team:               pref.Team,
+		Channels:           PreferenceChannels(pref),
+		Since:              since,
+		Until:              until,
+		SeverityCounts:     make(map[string]int),
+		Issues:             []models.IssueAssignment{},
+		UnresolvedPatterns: []models.FailurePattern{},
+	}
+	for _, issue := range issues {
+		if AcceptsSeverity(pref, issue.Severity) {
+			digest.Issues = append(digest.Issues, issue)
+			digest.SeverityCounts[issue.Severity]++
+		}
+	}
+	for _, pattern := range patterns {
+		if AcceptsSeverity(pref, pattern.Severity) {
+			digest.UnresolvedPatterns = append(digest.UnresolvedPatterns, pattern)
+		}
+	}
+	digest.IssueCount = len(digest.Issues)
+	if len(digest.Issues) > maxDigestIssues {
+		digest.Issues = digest.Issues[:maxDigestIssues]
+	}
+
+	return digest
+}
+
+// turnTools lists the tools a turn calls or reports a result for
+func turnTools(turn models.Turn) []string {
+	var tools []string
+	for _, call := range turn.ToolCalls {
+		tools = append(tools, call.ToolName)
+	}
+	if (turn.Role == models.RoleTool || turn.Role == models.RoleFunction) && turn.Name != "" {
+		tools = append(tools, turn.Name)
+	}
+	return tools
+}
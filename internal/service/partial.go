@@ -0,0 +1,184 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// NewEvaluation builds the evaluation stored for an evaluator service result.
+// Results missing evaluator types that failed are stored as partial, keeping
+// their component scores so a follow-up task can complete them.
+func NewEvaluation(result *EvaluationResult, taskID, triggerSource string) (*models.Evaluation, error) {
+	eval := &models.Evaluation{
+		EvaluationID:         result.EvaluationID,
+		ConversationID:       result.ConversationID,
+		OverallScore:         result.Scores["overall"],
+		ResponseQualityScore: result.Scores["response_quality"],
+		ToolAccuracyScore:    result.Scores["tool_accuracy"],
+		CoherenceScore:       result.Scores["coherence"],
+		EvaluatorVersion:     result.EvaluatorVersion,
+		EvaluationDurationMS: result.EvaluationDurationMS,
+		TaskID:               taskID,
+		TriggerSource:        triggerSource,
+		Partial:              len(result.FailedEvaluators) > 0,
+		EvaluationCost:       result.Cost,
+	}
+
+	components := make(map[string]float64, len(result.Scores))
+	for name, score := range result.Scores {
+		if name != "overall" {
+			components[name] = score
+		}
+	}
+	failed := result.FailedEvaluators
+	if failed == nil {
+		failed = []models.EvaluatorFailure{}
+	}
+	skipped := result.SkippedEvaluators
+	if skipped == nil {
+		skipped = []models.SkippedEvaluator{}
+	}
+
+	err := encodeJSONFields([]jsonField{
+		{result.ToolEvaluation, &eval.ToolEvaluation},
+		{result.IssuesDetected, &eval.IssuesDetected},
+		{result.ImprovementSuggestions, &eval.ImprovementSuggestions},
+		{failed, &eval.FailedEvaluators},
+		{components, &eval.ComponentScores},
+		{skipped, &eval.SkippedEvaluators},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return eval, nil
+}
+
+// FailedEvaluatorTypes returns the evaluator types missing from a partial
+// evaluation
+func FailedEvaluatorTypes(eval *models.Evaluation) ([]string, error) {
+	var failures []models.EvaluatorFailure
+	if len(eval.FailedEvaluators) > 0 {
+		if err := json.Unmarshal(eval.FailedEvaluators, &failures); err != nil {
+			return nil, fmt.Errorf("failed to parse failed evaluators: %w", err)
+		}
+	}
+
+	types := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		types = append(types, failure.EvaluatorType)
+	}
+	return types, nil
+}
+
+// CompleteEvaluation merges the result of re-running a partial evaluation's
+// failed evaluator types into it. Types that fail again stay listed as
+// failed, and the overall score is recomputed from every component present.
+// Issues are appended to the raw issues; the caller deduplicates them.
+func CompleteEvaluation(eval *models.Evaluation, retried []string, result *EvaluationResult) error {
+	components := make(map[string]float64)
+	if len(eval.ComponentScores) > 0 {
+		if err := json.Unmarshal(eval.ComponentScores, &components); err != nil {
+			return fmt.Errorf("failed to parse component scores: %w", err)
+		}
+	}
+
+	stillFailed := make(map[string]bool, len(result.FailedEvaluators))
+	for _, failure := range result.FailedEvaluators {
+		stillFailed[failure.EvaluatorType] = true
+	}
+	for _, evaluatorType := range retried {
+		if stillFailed[evaluatorType] {
+			continue
+		}
+		component, ok := evaluatorComponents[evaluatorType]
+		if !ok {
+			if score, ok := result.Scores[evaluatorType]; ok {
+				components[evaluatorType] = score // A custom evaluator's component
+			}
+			continue
+		}
+		score, ok := result.Scores[component.score]
+		if len(retried) == 1 || !ok {
+			// A single evaluator's overall score is its component score
+			score = result.Scores["overall"]
+		}
+		components[component.score] = score
+	}
+
+	var overall, totalWeight float64
+	for _, component := range evaluatorComponents {
+		if score, ok := components[component.score]; ok {
+			overall += score * component.weight
+			totalWeight += component.weight
+		}
+	}
+	if totalWeight > 0 {
+		eval.OverallScore = math.Round(overall/totalWeight*1000) / 1000
+	}
+	eval.ResponseQualityScore = components["response_quality"]
+	eval.ToolAccuracyScore = components["tool_accuracy"]
+	eval.CoherenceScore = components["coherence"]
+
+	var rawIssues, suggestions []map[string]interface{}
+	if err := unmarshalList(eval.RawIssuesDetected, &rawIssues); err != nil {
+		return err
+	}
+	if err := unmarshalList(eval.ImprovementSuggestions, &suggestions); err != nil {
+		return err
+	}
+	rawIssues = append(rawIssues, result.IssuesDetected...)
+	suggestions = append(suggestions, result.ImprovementSuggestions...)
+
+	failed := result.FailedEvaluators
+	if failed == nil {
+		failed = []models.EvaluatorFailure{}
+	}
+
+	fields := []jsonField{
+		{rawIssues, &eval.RawIssuesDetected},
+		{suggestions, &eval.ImprovementSuggestions},
+		{failed, &eval.FailedEvaluators},
+		{components, &eval.ComponentScores},
+	}
+	if result.ToolEvaluation != nil && !stillFailed["tool_call"] {
+		fields = append(fields, jsonField{result.ToolEvaluation, &eval.ToolEvaluation})
+	}
+	if err := encodeJSONFields(fields); err != nil {
+		return err
+	}
+	eval.Partial = len(failed) > 0
+
+	return nil
+}
+
+// jsonField is a value to encode into a JSON column of an evaluation
+type jsonField struct {
+	value interface{}
+	dest  *json.RawMessage
+}
+
+func encodeJSONFields(fields []jsonField) error {
+	for _, field := range fields {
+		raw, err := json.Marshal(field.value)
+		if err != nil {
+			return fmt.Errorf("failed to encode evaluation: %w", err)
+		}
+		*field.dest = raw
+	}
+	return nil
+}
+
+// unmarshalList decodes a stored JSON list, treating an empty value as empty
+func unmarshalList(raw json.RawMessage, dest *[]map[string]interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, dest); err != nil {
+		return fmt.Errorf("failed to parse evaluation JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Weights of the signals combined into pattern similarity
+const (
+	patternDescriptionWeight = 0.7
+	patternExampleWeight     = 0.3
+)
+
+// FindSimilarPatterns ranks candidates by similarity to target, combining token
+// overlap of the descriptions with overlap of their example conversations.
+// Patterns scoring below minSimilarity are dropped.
+func FindSimilarPatterns(target *models.FailurePattern, candidates []models.FailurePattern, minSimilarity float64, limit int) []models.SimilarPattern {
+	targetExamples := exampleSet(target.ExampleConversations)
+
+	matches := make([]models.SimilarPattern, 0)
+	for _, candidate := range candidates {
+		if candidate.PatternID == target.PatternID {
+			continue
+		}
+
+		similarity := patternDescriptionWeight*descriptionSimilarity(target.Description, candidate.Description) +
+			patternExampleWeight*setSimilarity(targetExamples, exampleSet(candidate.ExampleConversations))
+
+		if similarity >= minSimilarity {
+			matches = append(matches, models.SimilarPattern{Pattern: candidate, Similarity: similarity})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Similarity > matches[j].Similarity })
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
+
+// exampleSet decodes a JSON list of example conversation IDs into a set
+func exampleSet(raw json.RawMessage) map[string]bool {
+	set := make(map[string]bool)
+	var ids []string
+	if err := json.Unmarshal(raw, &ids); err != nil {
+		return set
+	}
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// setSimilarity returns the Jaccard similarity of two sets. Two empty sets
+// share no evidence, so they score 0.
+func setSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for key := range a {
+		if b[key] {
+			intersection++
+		}
+	}
+
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+// CorrectAffectedVersions corrects the affected versions of a failure pattern
+// after some of its example conversations were reassigned from the versions
+// in from to version to. A previous version is dropped once none of the
+// pattern's examples has it any more; versions the pattern recorded from
+// other evidence are kept. It reports whether anything changed.
+func CorrectAffectedVersions(affected json.RawMessage, from, exampleVersions map[string]bool, to string) (json.RawMessage, bool, error) {
+	var versions []string
+	if len(affected) > 0 {
+		if err := json.Unmarshal(affected, &versions); err != nil {
+			return nil, false, err
+		}
+	}
+
+	corrected := make([]string, 0, len(versions)+1)
+	hasTarget := false
+	for _, version := range versions {
+		if from[version] && !exampleVersions[version] {
+			continue
+		}
+		hasTarget = hasTarget || version == to
+		corrected = append(corrected, version)
+	}
+	if !hasTarget {
+		corrected = append(corrected, to)
+	}
+
+	if len(corrected) == len(versions) && hasTarget {
+		return affected, false, nil
+	}
+	data, err := json.Marshal(corrected)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
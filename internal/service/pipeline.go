@@ -0,0 +1,213 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// DefaultEvaluatorTypes are run when a task does not request specific evaluators
+var DefaultEvaluatorTypes = []string{"llm_judge", "tool_call", "coherence", "heuristic"}
+
+// ConfigStore holds the live pipeline configuration
+type ConfigStore struct {
+	mu    sync.RWMutex
+	cfg   models.PipelineConfig
+	tools *ToolRegistry
+}
+
+// NewConfigStore creates a config store seeded from the application config
+func NewConfigStore(cfg *config.Config, tools *ToolRegistry) *ConfigStore {
+	return &ConfigStore{
+		cfg: models.PipelineConfig{
+			DefaultEvaluatorTypes: DefaultEvaluatorTypes,
+			Routing: models.RoutingPolicy{
+				LowScoreThreshold:   0.4,
+				AgreementThreshold:  cfg.AnnotatorAgreementThreshold,
+				HealthThreshold:     0.5,
+				MinAnnotators:       cfg.AnnotationMinAnnotators,
+				ReviewDeadlineHours: DefaultReviewDeadlineHours,
+				SeverityPriorities:  DefaultSeverityPriorities,
+			},
+			LatencyThresholdMS: cfg.LatencyThresholdMS,
+			ToolLatencySLAs:    tools.Latencies(),
+			Health:             DefaultHealthPolicy,
+			Intents:            DefaultIntentTaxonomy,
+			Budget:             DefaultEvaluationBudget,
+			Verdicts:           DefaultVerdictPolicy(0.4),
+			Alerts: models.AlertRules{
+				QueueLagThresholds:        cfg.QueueLagThresholds,
+				AnomalyZThreshold:         cfg.AnomalyZThreshold,
+				AnomalyMinEvaluations:     cfg.AnomalyMinEvaluations,
+				ReliabilityKappaThreshold: cfg.ReliabilityKappaThreshold,
+				ReliabilityMinItems:       cfg.ReliabilityMinItems,
+			},
+		},
+		tools: tools,
+	}
+}
+
+// Get returns a copy of the current pipeline configuration
+func (s *ConfigStore) Get() models.PipelineConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cfg := s.cfg
+	cfg.DefaultEvaluatorTypes = append([]string(nil), s.cfg.DefaultEvaluatorTypes...)
+	cfg.ToolLatencySLAs = s.tools.Latencies()
+	cfg.ToolGoldens = s.tools.Goldens()
+	if s.cfg.Routing.MinAnnotators != nil {
+		cfg.Routing.MinAnnotators = make(map[string]int, len(s.cfg.Routing.MinAnnotators))
+		for annotationType, n := range s.cfg.Routing.MinAnnotators {
+			cfg.Routing.MinAnnotators[annotationType] = n
+		}
+	}
+	if s.cfg.Routing.ReviewDeadlineHours != nil {
+		cfg.Routing.ReviewDeadlineHours = make(map[string]int, len(s.cfg.Routing.ReviewDeadlineHours))
+		for priority, hours := range s.cfg.Routing.ReviewDeadlineHours {
+			cfg.Routing.ReviewDeadlineHours[priority] = hours
+		}
+	}
+	if s.cfg.Routing.SeverityPriorities != nil {
+		cfg.Routing.SeverityPriorities = make(map[string]string, len(s.cfg.Routing.SeverityPriorities))
+		for severity, priority := range s.cfg.Routing.SeverityPriorities {
+			cfg.Routing.SeverityPriorities[severity] = priority
+		}
+	}
+	cfg.Intents.Intents = append([]models.IntentDefinition(nil), s.cfg.Intents.Intents...)
+	cfg.Budget.Costs = make(map[string]float64, len(s.cfg.Budget.Costs))
+	for evaluatorType, cost := range s.cfg.Budget.Costs {
+		cfg.Budget.Costs[evaluatorType] = cost
+	}
+	cfg.Budget.Order = append([]string(nil), s.cfg.Budget.Order...)
+	cfg.Verdicts.Rules = make([]models.VerdictRule, len(s.cfg.Verdicts.Rules))
+	for i, rule := range s.cfg.Verdicts.Rules {
+		if rule.ComponentsBelow != nil {
+			rule.ComponentsBelow = make(map[string]float64, len(s.cfg.Verdicts.Rules[i].ComponentsBelow))
+			for component, threshold := range s.cfg.Verdicts.Rules[i].ComponentsBelow {
+				rule.ComponentsBelow[component] = threshold
+			}
+		}
+		rule.IssueTypes = append([]string(nil), rule.IssueTypes...)
+		cfg.Verdicts.Rules[i] = rule
+	}
+	cfg.Alerts.QueueLagThresholds = make(map[string]int, len(s.cfg.Alerts.QueueLagThresholds))
+	for queueName, seconds := range s.cfg.Alerts.QueueLagThresholds {
+		cfg.Alerts.QueueLagThresholds[queueName] = seconds
+	}
+	return cfg
+}
+
+// Set replaces the pipeline configuration
+func (s *ConfigStore) Set(cfg models.PipelineConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(cfg.DefaultEvaluatorTypes) == 0 {
+		cfg.DefaultEvaluatorTypes = DefaultEvaluatorTypes
+	}
+	if cfg.Health.SeverityPenalties == nil {
+		// Bundles exported before health weights existed
+		cfg.Health = s.cfg.Health
+	}
+	if cfg.Routing.MinAnnotators == nil {
+		// Bundles exported before minimum annotator counts existed
+		cfg.Routing.MinAnnotators = s.cfg.Routing.MinAnnotators
+	}
+	if cfg.Routing.ReviewDeadlineHours == nil {
+		// Bundles exported before review deadlines existed
+		cfg.Routing.ReviewDeadlineHours = s.cfg.Routing.ReviewDeadlineHours
+	}
+	if cfg.Routing.SeverityPriorities == nil {
+		// Bundles exported before severity priorities were configurable
+		cfg.Routing.SeverityPriorities = s.cfg.Routing.SeverityPriorities
+	}
+	if len(cfg.Intents.Intents) == 0 {
+		// Bundles exported before intent classification existed
+		cfg.Intents = s.cfg.Intents
+	}
+	if cfg.Budget.Costs == nil {
+		// Bundles exported before evaluation budgets existed
+		cfg.Budget = s.cfg.Budget
+	}
+	if cfg.Verdicts.Default == "" {
+		// Bundles exported before verdicts existed
+		cfg.Verdicts = s.cfg.Verdicts
+	}
+	if cfg.Alerts.QueueLagThresholds == nil {
+		// Bundles exported before alert rules existed
+		cfg.Alerts = s.cfg.Alerts
+	}
+	if cfg.LatencyThresholdMS <= 0 {
+		cfg.LatencyThresholdMS = s.cfg.LatencyThresholdMS
+	}
+	s.cfg = cfg
+	s.tools.SetDefaultLatency(cfg.LatencyThresholdMS)
+	if cfg.ToolLatencySLAs != nil {
+		s.tools.SetLatencies(cfg.ToolLatencySLAs)
+	}
+	if cfg.ToolGoldens != nil {
+		// Bundles exported before golden examples existed keep the current ones
+		s.tools.SetGoldens(cfg.ToolGoldens)
+	}
+}
+
+// MergePipelineConfig applies a partial configuration, as decoded from a
+// bundle, over current. Sections missing from patch keep their current
+// values; nested sections are merged field by field, while lists and
+// mappings such as tool latency SLAs are replaced whole.
+func MergePipelineConfig(current models.PipelineConfig, patch map[string]interface{}) (models.PipelineConfig, error) {
+	data, err := json.Marshal(current)
+	if err != nil {
+		return current, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	var base map[string]interface{}
+	if err := json.Unmarshal(data, &base); err != nil {
+		return current, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+
+	merged := mergeSection(base, patch, reflect.TypeOf(current))
+	if data, err = json.Marshal(merged); err != nil {
+		return current, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	var cfg models.PipelineConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return current, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// mergeSection merges patch over base when both are objects decoding into a
+// struct of type t, and otherwise returns patch
+func mergeSection(base, patch interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	baseFields, baseOK := base.(map[string]interface{})
+	patchFields, patchOK := patch.(map[string]interface{})
+	if t.Kind() != reflect.Struct || !baseOK || !patchOK {
+		return patch
+	}
+
+	fieldTypes := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fieldTypes[name] = t.Field(i).Type
+	}
+
+	merged := make(map[string]interface{}, len(baseFields)+len(patchFields))
+	for key, value := range baseFields {
+		merged[key] = value
+	}
+	for key, value := range patchFields {
+		if fieldType, ok := fieldTypes[key]; ok {
+			merged[key] = mergeSection(baseFields[key], value, fieldType)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// annotatorPseudonymPrefix marks pseudonymous annotator IDs
+const annotatorPseudonymPrefix = "annotator_"
+
+// annotatorPseudonymLength is how many hex characters of the HMAC a
+// pseudonym keeps
+const annotatorPseudonymLength = 16
+
+// NewAnnotatorSalt generates a random salt for a project's annotator
+// pseudonyms
+func NewAnnotatorSalt() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// AnnotatorPseudonymizer replaces annotator IDs in exports and reports with
+// pseudonyms. Pseudonyms are an HMAC of the ID keyed by the project's salt,
+// so an annotator keeps the same pseudonym across a project's exports but
+// can't be linked across projects or recovered without the salt.
+type AnnotatorPseudonymizer struct {
+	salt []byte
+}
+
+// NewAnnotatorPseudonymizer creates a pseudonymizer for a project's salt
+func NewAnnotatorPseudonymizer(salt string) *AnnotatorPseudonymizer {
+	return &AnnotatorPseudonymizer{salt: []byte(salt)}
+}
+
+// Pseudonym returns an annotator ID's pseudonym. Empty IDs stay empty.
+func (p *AnnotatorPseudonymizer) Pseudonym(annotatorID string) string {
+	if annotatorID == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, p.salt)
+	mac.Write([]byte(annotatorID))
+	return annotatorPseudonymPrefix + hex.EncodeToString(mac.Sum(nil))[:annotatorPseudonymLength]
+}
+
+// ExportItems pseudonymizes the annotators of annotation export items
+func (p *AnnotatorPseudonymizer) ExportItems(items []models.AnnotationExportItem) {
+	for i := range items {
+		for j := range items[i].Annotations {
+			items[i].Annotations[j].AnnotatorID = p.Pseudonym(items[i].Annotations[j].AnnotatorID)
+		}
+	}
+}
+
+// Performance pseudonymizes annotator performance
+func (p *AnnotatorPseudonymizer) Performance(performance []models.AnnotatorPerformance) {
+	for i := range performance {
+		performance[i].AnnotatorID = p.Pseudonym(performance[i].AnnotatorID)
+	}
+}
+
+// ReviewAging pseudonymizes the annotators of a review aging report
+func (p *AnnotatorPseudonymizer) ReviewAging(report *models.ReviewAgingReport) {
+	for i := range report.Annotators {
+		report.Annotators[i].AnnotatorID = p.Pseudonym(report.Annotators[i].AnnotatorID)
+	}
+}
+
+// Resolve maps pseudonyms back to the annotator IDs they were made from,
+// trying each of a project's known annotator IDs. Pseudonyms that match none
+// are left out.
+func (p *AnnotatorPseudonymizer) Resolve(annotatorIDs, pseudonyms []string) map[string]string {
+	wanted := make(map[string]bool, len(pseudonyms))
+	for _, pseudonym := range pseudonyms {
+		wanted[pseudonym] = true
+	}
+
+	resolved := make(map[string]string, len(pseudonyms))
+	for _, annotatorID := range annotatorIDs {
+		if pseudonym := p.Pseudonym(annotatorID); wanted[pseudonym] {
+			resolved[pseudonym] = annotatorID
+		}
+	}
+	return resolved
+}
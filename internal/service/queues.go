@@ -0,0 +1,58 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/queue"
+)
+
+// QueueLagSeverity is the severity lagging queues are alerted at
+const QueueLagSeverity = "high"
+
+// QueueHealth is a queue's stats evaluated against its lag policy
+type QueueHealth struct {
+	queue.QueueStats
+	LagThresholdSeconds int    `json:"lag_threshold_seconds,omitempty"`
+	Lagging             bool   `json:"lagging"`
+	Alert               string `json:"alert,omitempty"`
+}
+
+// CheckQueueLag flags queues whose oldest pending task is older than the
+// queue's threshold. Queues without a threshold are never flagged.
+func CheckQueueLag(stats []queue.QueueStats, thresholdsSeconds map[string]int) []QueueHealth {
+	health := make([]QueueHealth, 0, len(stats))
+	for _, s := range stats {
+		h := QueueHealth{QueueStats: s, LagThresholdSeconds: thresholdsSeconds[s.Queue]}
+		if h.LagThresholdSeconds > 0 && s.OldestTaskAgeSeconds > float64(h.LagThresholdSeconds) {
+			h.Lagging = true
+			h.Alert = fmt.Sprintf("Queue %s lag %.0fs exceeds %ds (%d pending, %.1f/min in, %.1f/min out)",
+				s.Queue, s.OldestTaskAgeSeconds, h.LagThresholdSeconds, s.Length, s.EnqueuedPerMinute, s.DequeuedPerMinute)
+		}
+		health = append(health, h)
+	}
+	return health
+}
+
+// BacklogSource measures the backlogs kept in the database
+type BacklogSource interface {
+	GetBacklogStats(window time.Duration) ([]queue.QueueStats, error)
+}
+
+// CollectQueueHealth gathers stats for the monitored queues and the database
+// backlogs and applies the lag policy
+func CollectQueueHealth(q *queue.RedisQueue, backlogs BacklogSource, thresholdsSeconds map[string]int) ([]QueueHealth, error) {
+	stats := make([]queue.QueueStats, 0, len(queue.MonitoredQueues))
+	for _, name := range queue.MonitoredQueues {
+		s, err := q.Stats(name)
+		if err != nil {
+			return nil, fmt.Errorf("queue %s: %w", name, err)
+		}
+		stats = append(stats, *s)
+	}
+	backlogStats, err := backlogs.GetBacklogStats(queue.RateWindow)
+	if err != nil {
+		return nil, err
+	}
+	return CheckQueueLag(append(stats, backlogStats...), thresholdsSeconds), nil
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Evaluator evaluates a conversation for a custom evaluator type. Its
+// "overall" score, or the mean of its scores if it reports none, is stored
+// as the evaluator type's component score. Custom components are reported
+// alongside the built-in ones but don't count toward the overall score.
+type Evaluator interface {
+	Evaluate(ctx context.Context, req *EvaluationRequest) (map[string]float64, []map[string]interface{}, error)
+}
+
+// EvaluatorFunc adapts a function to the Evaluator interface
+type EvaluatorFunc func(ctx context.Context, req *EvaluationRequest) (map[string]float64, []map[string]interface{}, error)
+
+// Evaluate calls f
+func (f EvaluatorFunc) Evaluate(ctx context.Context, req *EvaluationRequest) (map[string]float64, []map[string]interface{}, error) {
+	return f(ctx, req)
+}
+
+// EvaluatorRegistry maps custom evaluator types to their implementations.
+// Evaluator types that aren't registered are sent to the Python service.
+type EvaluatorRegistry struct {
+	mu         sync.RWMutex
+	evaluators map[string]Evaluator
+}
+
+// NewEvaluatorRegistry creates an empty registry
+func NewEvaluatorRegistry() *EvaluatorRegistry {
+	return &EvaluatorRegistry{evaluators: make(map[string]Evaluator)}
+}
+
+// Evaluators is the registry used by the API and the worker. Register Go
+// evaluators with it before they start.
+var Evaluators = NewEvaluatorRegistry()
+
+// Register registers an evaluator for a type, replacing any registered
+// before. The Python service's evaluator types can't be replaced.
+func (r *EvaluatorRegistry) Register(evaluatorType string, evaluator Evaluator) error {
+	if evaluatorType == "" {
+		return fmt.Errorf("evaluator type is required")
+	}
+	if _, ok := evaluatorCapabilities[evaluatorType]; ok {
+		return fmt.Errorf("evaluator %q is provided by the evaluator service", evaluatorType)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evaluators[evaluatorType] = evaluator
+	return nil
+}
+
+// Lookup returns the evaluator registered for a type
+func (r *EvaluatorRegistry) Lookup(evaluatorType string) (Evaluator, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	evaluator, ok := r.evaluators[evaluatorType]
+	return evaluator, ok
+}
+
+// Types lists the registered evaluator types
+func (r *EvaluatorRegistry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.evaluators))
+	for evaluatorType := range r.evaluators {
+		types = append(types, evaluatorType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// includesAny reports whether any of the evaluator types is registered
+func (r *EvaluatorRegistry) includesAny(evaluatorTypes []string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, evaluatorType := range evaluatorTypes {
+		if _, ok := r.evaluators[evaluatorType]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterRemoteEvaluators registers a RemoteEvaluator for each evaluator
+// type and URL
+func RegisterRemoteEvaluators(registry *EvaluatorRegistry, urls map[string]string) error {
+	for evaluatorType, url := range urls {
+		if err := registry.Register(evaluatorType, NewRemoteEvaluator(url)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoteEvaluator is a custom evaluator served over HTTP. It is sent the
+// same request as the Python service, limited to its own evaluator type,
+// and answers with the same response shape, of which only scores and
+// issues_detected are used.
+type RemoteEvaluator struct {
+	url        string
+	httpClient *http.Client // Deadlines come from the request context
+}
+
+// NewRemoteEvaluator creates an evaluator that posts to url
+func NewRemoteEvaluator(url string) *RemoteEvaluator {
+	return &RemoteEvaluator{url: url, httpClient: &http.Client{}}
+}
+
+// Evaluate posts the request to the remote evaluator
+func (e *RemoteEvaluator) Evaluate(ctx context.Context, req *EvaluationRequest) (map[string]float64, []map[string]interface{}, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to call evaluator: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, nil, fmt.Errorf("evaluator returned status %d", resp.StatusCode)
+	}
+
+	var result EvaluationResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Scores, result.IssuesDetected, nil
+}
+
+// evaluateRegistered evaluates a single evaluator type request with a
+// registered evaluator
+func evaluateRegistered(ctx context.Context, req *EvaluationRequest, evaluator Evaluator) (*EvaluationResult, error) {
+	start := time.Now()
+	scores, issues, err := evaluator.Evaluate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EvaluationResult{
+		ConversationID:       req.ConversationID,
+		Scores:               make(map[string]float64, len(scores)+1),
+		IssuesDetected:       issues,
+		EvaluationDurationMS: int(time.Since(start).Milliseconds()),
+	}
+	var sum float64
+	for name, score := range scores {
+		result.Scores[name] = score
+		sum += score
+	}
+	if _, ok := result.Scores["overall"]; !ok && len(scores) > 0 {
+		result.Scores["overall"] = sum / float64(len(scores))
+	}
+	if len(req.EvaluatorTypes) == 1 {
+		result.EvaluatorVersion = req.EvaluatorVersions[req.EvaluatorTypes[0]]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Reliability trend periods
+const (
+	ReliabilityDaily  = "day"
+	ReliabilityWeekly = "week"
+)
+
+// ReliabilityPolicy decides when inter-rater reliability is too low
+type ReliabilityPolicy struct {
+	KappaThreshold float64
+	MinItems       int // Periods with fewer items are reported but never flagged
+}
+
+// ReliabilityTrend computes the inter-rater reliability of each annotation
+// type per period: the share of annotator pairs that agree on an item, and
+// Fleiss' kappa, which discounts the agreement expected by chance given how
+// often each label is used. Points are ordered by type, then period.
+func ReliabilityTrend(counts []models.ReliabilityLabelCount, policy ReliabilityPolicy) []models.ReliabilityPoint {
+	type period struct {
+		annotationType string
+		bucket         time.Time
+	}
+	items := make(map[period]map[string]map[string]int)
+	for _, count := range counts {
+		key := period{count.AnnotationType, count.Bucket}
+		if items[key] == nil {
+			items[key] = make(map[string]map[string]int)
+		}
+		if items[key][count.ConversationID] == nil {
+			items[key][count.ConversationID] = make(map[string]int)
+		}
+		items[key][count.ConversationID][count.Label] += count.Annotators
+	}
+
+	points := make([]models.ReliabilityPoint, 0, len(items))
+	for key, byConversation := range items {
+		point := models.ReliabilityPoint{AnnotationType: key.annotationType, Bucket: key.bucket}
+		labelTotals := make(map[string]int)
+		var agreementSum float64
+		for _, labels := range byConversation {
+			n, agreeingPairs := 0, 0
+			for label, c := range labels {
+				n += c
+				agreeingPairs += c * (c - 1)
+				labelTotals[label] += c
+			}
+			if n < 2 {
+				continue
+			}
+			agreementSum += float64(agreeingPairs) / float64(n*(n-1))
+			point.Items++
+			point.Annotations += n
+		}
+		if point.Items == 0 {
+			continue
+		}
+		point.PercentAgreement = agreementSum / float64(point.Items)
+
+		var expected float64
+		for _, c := range labelTotals {
+			p := float64(c) / float64(point.Annotations)
+			expected += p * p
+		}
+		if expected < 1 {
+			kappa := (point.PercentAgreement - expected) / (1 - expected)
+			point.Kappa = &kappa
+			point.BelowThreshold = point.Items >= policy.MinItems && kappa < policy.KappaThreshold
+		}
+
+		points = append(points, point)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].AnnotationType != points[j].AnnotationType {
+			return points[i].AnnotationType < points[j].AnnotationType
+		}
+		return points[i].Bucket.Before(points[j].Bucket)
+	})
+
+	return points
+}
+
+// DetectReliabilityDrops returns an event for each annotation type whose
+// latest period starting before the given time is below the threshold. Periods
+// still in progress are left out so partial data doesn't alert.
+func DetectReliabilityDrops(points []models.ReliabilityPoint, before time.Time, policy ReliabilityPolicy) []models.ReliabilityEvent {
+	latest := make(map[string]int)
+	previous := make(map[string]int)
+	for i, point := range points {
+		if !point.Bucket.Before(before) {
+			continue
+		}
+		if j, ok := latest[point.AnnotationType]; ok {
+			previous[point.AnnotationType] = j
+		}
+		latest[point.AnnotationType] = i
+	}
+
+	events := []models.ReliabilityEvent{}
+	for annotationType, i := range latest {
+		point := points[i]
+		if !point.BelowThreshold {
+			continue
+		}
+		event := models.ReliabilityEvent{
+			AnnotationType:   annotationType,
+			Bucket:           point.Bucket,
+			Kappa:            *point.Kappa,
+			PercentAgreement: point.PercentAgreement,
+			Items:            point.Items,
+			Threshold:        policy.KappaThreshold,
+		}
+		if j, ok := previous[annotationType]; ok {
+			event.PreviousKappa = points[j].Kappa
+		}
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].AnnotationType < events[j].AnnotationType })
+
+	return events
+}
+
+// ReliabilityPeriodStart returns the start of the period containing t, as
+// Postgres date_trunc computes it
+func ReliabilityPeriodStart(interval string, t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if interval == ReliabilityWeekly {
+		// Weeks start on Monday
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return day
+}
+
+// ReliabilityAlertSeverity is the severity reliability drops are alerted at
+const ReliabilityAlertSeverity = "medium"
+
+// ReliabilityAlert is a reliability event as published to the alerting channel
+type ReliabilityAlert struct {
+	models.ReliabilityEvent
+	Alert string `json:"alert"`
+}
+
+// NewReliabilityAlert describes a reliability event for alerting
+func NewReliabilityAlert(event models.ReliabilityEvent) ReliabilityAlert {
+	alert := fmt.Sprintf("Annotation reliability drop: %s kappa %.2f below %.2f (%.0f%% pairwise agreement, %d items) in period %s",
+		event.AnnotationType, event.Kappa, event.Threshold, event.PercentAgreement*100, event.Items, event.Bucket.Format("2006-01-02"))
+	if event.PreviousKappa != nil {
+		alert += fmt.Sprintf(", down from %.2f", *event.PreviousKappa)
+	}
+	return ReliabilityAlert{ReliabilityEvent: event, Alert: alert}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// ExchangeRecorder stores sampled evaluator service exchanges
+type ExchangeRecorder interface {
+	RecordEvaluatorExchange(recording *models.EvaluatorRecording) error
+}
+
+// SetRecorder records the given fraction of evaluator service exchanges so
+// they can be replayed against other evaluator builds. A rate of zero turns
+// recording off.
+func (s *EvaluatorService) SetRecorder(recorder ExchangeRecorder, sampleRate float64) {
+	s.recorder = recorder
+	s.sampleRate = sampleRate
+}
+
+// sampled decides whether the next exchange is recorded
+func (s *EvaluatorService) sampled() bool {
+	return s.recorder != nil && s.sampleRate > 0 && rand.Float64() < s.sampleRate
+}
+
+// record stores one exchange. Failures are logged rather than failing the
+// evaluation.
+func (s *EvaluatorService) record(conversationID string, request, response []byte, status int, duration time.Duration, result *EvaluationResult, callErr error) {
+	recording := &models.EvaluatorRecording{
+		ConversationID: conversationID,
+		Request:        request,
+		StatusCode:     status,
+		DurationMS:     int(duration.Milliseconds()),
+	}
+	if json.Valid(response) {
+		recording.Response = response
+	}
+	if result != nil {
+		recording.EvaluatorVersion = result.EvaluatorVersion
+	}
+	if callErr != nil {
+		recording.Error = callErr.Error()
+	}
+
+	if err := s.recorder.RecordEvaluatorExchange(recording); err != nil {
+		log.Printf("Failed to record evaluator exchange for %s: %v", conversationID, err)
+	}
+}
+
+// ReplayDiff compares the recorded response to a request with the response
+// of a candidate evaluator build
+type ReplayDiff struct {
+	RecordingID      int64              `json:"recording_id"`
+	ConversationID   string             `json:"conversation_id"`
+	BaselineVersion  string             `json:"baseline_version"`
+	CandidateVersion string             `json:"candidate_version,omitempty"`
+	ScoreDeltas      map[string]float64 `json:"score_deltas,omitempty"` // Candidate minus baseline, beyond the tolerance
+	MissingScores    []string           `json:"missing_scores,omitempty"`
+	NewScores        []string           `json:"new_scores,omitempty"`
+	AddedIssues      []string           `json:"added_issues,omitempty"` // Issue types, once per extra occurrence
+	RemovedIssues    []string           `json:"removed_issues,omitempty"`
+	Error            string             `json:"error,omitempty"`
+}
+
+// Changed reports whether the candidate's output differs from the recording
+func (d *ReplayDiff) Changed() bool {
+	return len(d.ScoreDeltas) > 0 || len(d.MissingScores) > 0 || len(d.NewScores) > 0 ||
+		len(d.AddedIssues) > 0 || len(d.RemovedIssues) > 0
+}
+
+// DiffRecording compares a recording's response with a candidate result.
+// Score changes within tolerance are ignored.
+func DiffRecording(recording *models.EvaluatorRecording, candidate *EvaluationResult, tolerance float64) (*ReplayDiff, error) {
+	var baseline EvaluationResult
+	if err := json.Unmarshal(recording.Response, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to decode recorded response: %w", err)
+	}
+
+	diff := &ReplayDiff{
+		RecordingID:      recording.ID,
+		ConversationID:   recording.ConversationID,
+		BaselineVersion:  baseline.EvaluatorVersion,
+		CandidateVersion: candidate.EvaluatorVersion,
+	}
+
+	for name, before := range baseline.Scores {
+		after, ok := candidate.Scores[name]
+		if !ok {
+			diff.MissingScores = append(diff.MissingScores, name)
+			continue
+		}
+		if delta := after - before; math.Abs(delta) > tolerance {
+			if diff.ScoreDeltas == nil {
+				diff.ScoreDeltas = make(map[string]float64)
+			}
+			diff.ScoreDeltas[name] = delta
+		}
+	}
+	for name := range candidate.Scores {
+		if _, ok := baseline.Scores[name]; !ok {
+			diff.NewScores = append(diff.NewScores, name)
+		}
+	}
+	sort.Strings(diff.MissingScores)
+	sort.Strings(diff.NewScores)
+
+	diff.AddedIssues, diff.RemovedIssues = diffIssueTypes(baseline.IssuesDetected, candidate.IssuesDetected)
+
+	return diff, nil
+}
+
+// diffIssueTypes compares issue type counts, returning the types the
+// candidate reports more and less often than the baseline
+func diffIssueTypes(baseline, candidate []map[string]interface{}) (added, removed []string) {
+	counts := make(map[string]int)
+	for _, issue := range baseline {
+		counts[fmt.Sprint(issue["type"])]--
+	}
+	for _, issue := range candidate {
+		counts[fmt.Sprint(issue["type"])]++
+	}
+
+	for issueType, n := range counts {
+		for ; n > 0; n-- {
+			added = append(added, issueType)
+		}
+		for ; n < 0; n++ {
+			removed = append(removed, issueType)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// ReplaySummary aggregates the diffs of a replay run
+type ReplaySummary struct {
+	Replayed       int                `json:"replayed"`
+	Changed        int                `json:"changed"`
+	Failed         int                `json:"failed"`
+	MeanScoreDelta map[string]float64 `json:"mean_score_delta"` // Over all replayed recordings, including unchanged ones
+	AddedIssues    map[string]int     `json:"added_issues"`
+	RemovedIssues  map[string]int     `json:"removed_issues"`
+}
+
+// SummarizeReplay aggregates replay diffs
+func SummarizeReplay(diffs []*ReplayDiff) ReplaySummary {
+	summary := ReplaySummary{
+		MeanScoreDelta: make(map[string]float64),
+		AddedIssues:    make(map[string]int),
+		RemovedIssues:  make(map[string]int),
+	}
+
+	for _, diff := range diffs {
+		if diff.Error != "" {
+			summary.Failed++
+			continue
+		}
+		summary.Replayed++
+		if diff.Changed() {
+			summary.Changed++
+		}
+		for name, delta := range diff.ScoreDeltas {
+			summary.MeanScoreDelta[name] += delta
+		}
+		for _, issueType := range diff.AddedIssues {
+			summary.AddedIssues[issueType]++
+		}
+		for _, issueType := range diff.RemovedIssues {
+			summary.RemovedIssues[issueType]++
+		}
+	}
+
+	if summary.Replayed > 0 {
+		for name := range summary.MeanScoreDelta {
+			summary.MeanScoreDelta[name] /= float64(summary.Replayed)
+		}
+	}
+
+	return summary
+}
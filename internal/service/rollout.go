@@ -0,0 +1,71 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Rollout decisions
+const (
+	RolloutDecisionPromote  = "promote"
+	RolloutDecisionRollback = "rollback"
+)
+
+// ChooseEvaluatorVersions picks the evaluator version to use for each evaluator
+// type. Promoted versions are used by default; active rollouts route
+// TrafficPercent of tasks to their candidate version.
+func ChooseEvaluatorVersions(evaluatorTypes []string, promoted map[string]string, rollouts []models.EvaluatorRollout) map[string]string {
+	versions := make(map[string]string)
+	requested := make(map[string]bool, len(evaluatorTypes))
+	for _, evaluatorType := range evaluatorTypes {
+		requested[evaluatorType] = true
+		if version, ok := promoted[evaluatorType]; ok {
+			versions[evaluatorType] = version
+		}
+	}
+
+	for _, rollout := range rollouts {
+		if !requested[rollout.EvaluatorType] || rollout.Status != models.RolloutActive {
+			continue
+		}
+		if rand.Float64()*100 < rollout.TrafficPercent {
+			versions[rollout.EvaluatorType] = rollout.CandidateVersion
+		} else {
+			versions[rollout.EvaluatorType] = rollout.IncumbentVersion
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil
+	}
+	return versions
+}
+
+// DecideRollout compares the candidate's correlation with human labels against
+// the incumbent's. It returns an empty decision until both versions have
+// enough calibration samples, and while the correlations differ by no more
+// than the rollout's minimum margin.
+func DecideRollout(rollout *models.EvaluatorRollout, incumbent, candidate *models.EvaluatorCalibration) (string, string) {
+	if incumbent == nil || candidate == nil {
+		return "", ""
+	}
+	if !incumbent.CorrelationWithHuman.Valid || !candidate.CorrelationWithHuman.Valid {
+		return "", ""
+	}
+	if incumbent.CalibrationSamples < rollout.MinSamples || candidate.CalibrationSamples < rollout.MinSamples {
+		return "", ""
+	}
+
+	incumbentCorr := incumbent.CorrelationWithHuman.Float64
+	candidateCorr := candidate.CorrelationWithHuman.Float64
+
+	switch {
+	case candidateCorr-incumbentCorr > rollout.MinMargin:
+		return RolloutDecisionPromote, fmt.Sprintf("candidate correlation %.3f exceeds incumbent %.3f by more than %.3f", candidateCorr, incumbentCorr, rollout.MinMargin)
+	case incumbentCorr-candidateCorr > rollout.MinMargin:
+		return RolloutDecisionRollback, fmt.Sprintf("candidate correlation %.3f is below incumbent %.3f by more than %.3f", candidateCorr, incumbentCorr, rollout.MinMargin)
+	}
+	return "", ""
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Route access levels, shared by API key scopes and JWT roles
+const (
+	RouteAccessAdmin    = "admin"    // Admins only
+	RouteAccessAnnotate = "annotate" // Annotation and review work
+	RouteAccessRead     = "read"
+	RouteAccessWrite    = "write"
+)
+
+// routePolicy gives routes containing pattern, or ending in it when suffix
+// is set, an access level
+type routePolicy struct {
+	pattern string
+	suffix  bool
+	access  string
+}
+
+// routePolicies are checked in order and the first match decides. Routes
+// matching none are reads for safe methods and writes otherwise.
+var routePolicies = []routePolicy{
+	{pattern: "/admin/", access: RouteAccessAdmin},
+	{pattern: "/apikeys", access: RouteAccessAdmin},
+	{pattern: "/projects", suffix: true, access: RouteAccessAdmin},
+	{pattern: "/improvements/", access: RouteAccessAdmin},
+	{pattern: "/meta-evaluation/", access: RouteAccessAdmin},
+	{pattern: "/annotations", access: RouteAccessAnnotate},
+	{pattern: "/review/", access: RouteAccessAnnotate},
+}
+
+// RouteAccess returns the access level a request to a route needs
+func RouteAccess(method, route string) string {
+	for _, policy := range routePolicies {
+		if (policy.suffix && strings.HasSuffix(route, policy.pattern)) ||
+			(!policy.suffix && strings.Contains(route, policy.pattern)) {
+			return policy.access
+		}
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return RouteAccessRead
+	}
+	return RouteAccessWrite
+}
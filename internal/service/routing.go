@@ -0,0 +1,179 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Routing priorities
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
+// DefaultReviewDeadlineHours gives high priority reviews a day and medium
+// priority ones three days; low priority reviews have no deadline
+var DefaultReviewDeadlineHours = map[string]int{
+	PriorityHigh:   24,
+	PriorityMedium: 72,
+}
+
+// priorityRank orders routing priorities
+var priorityRank = map[string]int{
+	PriorityLow:    1,
+	PriorityMedium: 2,
+	PriorityHigh:   3,
+}
+
+// DefaultSeverityPriorities sends conversations with critical issues to
+// review at high priority; issues of other severities don't route
+var DefaultSeverityPriorities = map[string]string{
+	"critical": PriorityHigh,
+}
+
+// ValidateSeverityPriorities checks that a severity to priority mapping maps
+// known severities to known priorities
+func ValidateSeverityPriorities(mapping map[string]string) error {
+	for severity, priority := range mapping {
+		if _, ok := severityRank[severity]; !ok {
+			return fmt.Errorf("unknown severity %q", severity)
+		}
+		if _, ok := priorityRank[priority]; !ok {
+			return fmt.Errorf("unknown priority %q for severity %s", priority, severity)
+		}
+	}
+	return nil
+}
+
+// DecideRouting decides whether an evaluation needs human review.
+// severityCounts counts the evaluation's issues by severity; severities the
+// policy maps to a priority route the conversation at that priority. health
+// is the conversation health score, or nil if it hasn't been computed.
+// Failing verdicts route at high priority and verdicts needing review at
+// least at medium priority.
+func DecideRouting(overallScore float64, severityCounts map[string]int, health *float64, verdict string, policy models.RoutingPolicy) (bool, string, []string) {
+	needsReview := false
+	priority := PriorityLow
+	reasons := []string{}
+
+	if overallScore < policy.LowScoreThreshold {
+		needsReview = true
+		reasons = append(reasons, "Low quality score")
+		priority = PriorityHigh
+	}
+
+	switch verdict {
+	case models.VerdictFail:
+		needsReview = true
+		reasons = append(reasons, "Failing verdict")
+		priority = PriorityHigh
+	case models.VerdictNeedsReview:
+		needsReview = true
+		reasons = append(reasons, "Verdict needs review")
+		if priority == PriorityLow {
+			priority = PriorityMedium
+		}
+	}
+
+	// Most severe first, so reasons are listed in a stable order
+	severities := make([]string, 0, len(policy.SeverityPriorities))
+	for severity := range policy.SeverityPriorities {
+		severities = append(severities, severity)
+	}
+	sort.Slice(severities, func(i, j int) bool { return severityRank[severities[i]] > severityRank[severities[j]] })
+	for _, severity := range severities {
+		if severityCounts[severity] == 0 {
+			continue
+		}
+		needsReview = true
+		reasons = append(reasons, strings.ToUpper(severity[:1])+severity[1:]+" issues detected")
+		if mapped := policy.SeverityPriorities[severity]; priorityRank[mapped] > priorityRank[priority] {
+			priority = mapped
+		}
+	}
+
+	if health != nil && *health < policy.HealthThreshold {
+		needsReview = true
+		reasons = append(reasons, "Low conversation health")
+		if priority == PriorityLow {
+			priority = PriorityMedium
+		}
+	}
+
+	return needsReview, priority, reasons
+}
+
+// SeverityCounts counts issues by severity
+func SeverityCounts(issues []models.IssueDetected) map[string]int {
+	counts := make(map[string]int)
+	for _, issue := range issues {
+		if issue.Severity != "" {
+			counts[issue.Severity]++
+		}
+	}
+	return counts
+}
+
+// SummarySeverityCounts reads the issue counts by severity stored with a
+// conversation summary. Summaries written before counts were stored only
+// have their critical issue count.
+func SummarySeverityCounts(raw json.RawMessage, criticalIssues int) map[string]int {
+	counts := make(map[string]int)
+	if len(raw) > 0 {
+		json.Unmarshal(raw, &counts)
+	}
+	if len(counts) == 0 && criticalIssues > 0 {
+		counts["critical"] = criticalIssues
+	}
+	return counts
+}
+
+// ReviewDeadline returns when a review of a priority is due if it is
+// assigned at now, or nil if reviews of the priority have no deadline
+func ReviewDeadline(policy models.RoutingPolicy, priority string, now time.Time) *time.Time {
+	hours := policy.ReviewDeadlineHours[priority]
+	if hours <= 0 {
+		return nil
+	}
+	due := now.Add(time.Duration(hours) * time.Hour)
+	return &due
+}
+
+// RequiredAnnotators returns how many independent annotations an annotation
+// type needs before it is adjudicated or auto-labelled
+func RequiredAnnotators(policy models.RoutingPolicy, annotationType string) int {
+	if n := policy.MinAnnotators[annotationType]; n > 1 {
+		return n
+	}
+	return 1
+}
+
+// AnnotationCoverage reports how far a conversation is from the independent
+// annotations its annotation type requires. annotators are those who have
+// annotated it and assigned those with a task for it.
+func AnnotationCoverage(policy models.RoutingPolicy, annotationType string, annotators, assigned []string) models.AnnotationCoverage {
+	completed := make(map[string]bool, len(annotators))
+	for _, annotatorID := range annotators {
+		completed[annotatorID] = true
+	}
+
+	coverage := models.AnnotationCoverage{
+		RequiredAnnotators:  RequiredAnnotators(policy, annotationType),
+		CompletedAnnotators: len(completed),
+		PendingAnnotators:   []string{},
+	}
+	for _, annotatorID := range assigned {
+		if !completed[annotatorID] {
+			coverage.PendingAnnotators = append(coverage.PendingAnnotators, annotatorID)
+		}
+	}
+	coverage.Complete = coverage.CompletedAnnotators >= coverage.RequiredAnnotators
+
+	return coverage
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SegmentPolicy controls how long conversations are split for evaluation.
+//
+// Conversations with more than MaxTurns turns are split into consecutive
+// segments of at most MaxTurns turns. Each segment after the first repeats
+// the last OverlapTurns turns of the previous one as context. A segment
+// "owns" only its non-overlapping turns: issues reported on overlap turns
+// are dropped (the previous segment already reported them), and scores are
+// aggregated as the mean of segment scores weighted by owned turns, so every
+// turn contributes to the conversation score exactly once.
+//
+// When MaxTokens is set, segments are also cut short before their turns
+// exceed MaxTokens as estimated by Tokenizer, so long tool payloads don't
+// overflow the judge model's context. A segment always owns at least one
+// turn, however long it is.
+type SegmentPolicy struct {
+	MaxTurns     int
+	OverlapTurns int
+	MaxTokens    int
+	Tokenizer    Tokenizer
+}
+
+// segment is a contiguous slice of turns evaluated on its own
+type segment struct {
+	start int // Index of the first turn in the conversation
+	owned int // Number of turns not shared with the previous segment
+	turns []map[string]interface{}
+}
+
+// enabled reports whether the policy splits anything
+func (p SegmentPolicy) enabled() bool {
+	if p.OverlapTurns < 0 {
+		return false
+	}
+	if p.MaxTurns > 0 {
+		return p.OverlapTurns < p.MaxTurns
+	}
+	return p.MaxTokens > 0
+}
+
+// split breaks turns into overlapping segments. Conversations within the
+// limits come back as a single segment.
+func (p SegmentPolicy) split(turns []map[string]interface{}) []segment {
+	var tokens []int
+	if p.enabled() && p.MaxTokens > 0 {
+		tokens = make([]int, len(turns))
+		for i, turn := range turns {
+			tokens[i] = p.Tokenizer.countEvaluatorTurn(turn)
+		}
+	}
+	if !p.enabled() || ((p.MaxTurns <= 0 || len(turns) <= p.MaxTurns) && sumTokens(tokens) <= p.MaxTokens) {
+		return []segment{{start: 0, owned: len(turns), turns: turns}}
+	}
+
+	var segments []segment
+	ownedFrom := 0
+	for ownedFrom < len(turns) {
+		start := ownedFrom
+		if len(segments) > 0 {
+			start = ownedFrom - p.OverlapTurns
+		}
+		end := len(turns)
+		if p.MaxTurns > 0 && start+p.MaxTurns < end {
+			end = start + p.MaxTurns
+		}
+		if tokens != nil {
+			budget := sumTokens(tokens[start:ownedFrom])
+			for i := ownedFrom; i < end; i++ {
+				budget += tokens[i]
+				if budget > p.MaxTokens && i > ownedFrom {
+					end = i
+					break
+				}
+			}
+		}
+
+		segments = append(segments, segment{
+			start: start,
+			owned: end - ownedFrom,
+			turns: turns[start:end],
+		})
+		ownedFrom = end
+	}
+
+	return segments
+}
+
+// sumTokens adds up token counts
+func sumTokens(tokens []int) int {
+	total := 0
+	for _, n := range tokens {
+		total += n
+	}
+	return total
+}
+
+// evaluateSegmented evaluates each segment separately and aggregates the results
+func (s *EvaluatorService) evaluateSegmented(ctx context.Context, req *EvaluationRequest, segments []segment, timeouts map[string]time.Duration) (*EvaluationResult, error) {
+	results := make([]*EvaluationResult, 0, len(segments))
+	for i, seg := range segments {
+		segReq := *req
+		segReq.Turns = seg.turns
+
+		result, err := s.evaluateComponents(ctx, &segReq, timeouts)
+		if err != nil {
+			return nil, fmt.Errorf("segment %d of %d: %w", i+1, len(segments), err)
+		}
+		results = append(results, result)
+	}
+
+	return aggregateSegments(segments, results), nil
+}
+
+// aggregateSegments combines per-segment results following SegmentPolicy
+func aggregateSegments(segments []segment, results []*EvaluationResult) *EvaluationResult {
+	aggregated := &EvaluationResult{
+		EvaluationID:     results[0].EvaluationID,
+		ConversationID:   results[0].ConversationID,
+		Scores:           make(map[string]float64),
+		ToolEvaluation:   make(map[string]interface{}),
+		EvaluatorVersion: results[0].EvaluatorVersion,
+		Segments:         len(segments),
+	}
+
+	scoreWeights := make(map[string]float64)
+	toolWeights := make(map[string]float64)
+	for i, result := range results {
+		seg := segments[i]
+		weight := float64(seg.owned)
+		overlap := len(seg.turns) - seg.owned
+
+		for name, score := range result.Scores {
+			aggregated.Scores[name] += score * weight
+			scoreWeights[name] += weight
+		}
+
+		for key, value := range result.ToolEvaluation {
+			switch v := value.(type) {
+			case float64:
+				current, _ := aggregated.ToolEvaluation[key].(float64)
+				aggregated.ToolEvaluation[key] = current + v*weight
+				toolWeights[key] += weight
+			case bool:
+				// A failure in any segment fails the conversation
+				current, seen := aggregated.ToolEvaluation[key].(bool)
+				aggregated.ToolEvaluation[key] = v && (current || !seen)
+			default:
+				aggregated.ToolEvaluation[key] = value
+			}
+		}
+
+		for _, issue := range result.IssuesDetected {
+			position, ok := issue["turn_id"].(float64)
+			if ok {
+				if int(position) <= overlap {
+					continue // Owned by the previous segment
+				}
+				issue["turn_id"] = seg.start + int(position)
+			}
+			aggregated.IssuesDetected = append(aggregated.IssuesDetected, issue)
+		}
+
+		aggregated.ImprovementSuggestions = append(aggregated.ImprovementSuggestions, result.ImprovementSuggestions...)
+
+		// A component that failed in any segment is reported once
+		for _, failure := range result.FailedEvaluators {
+			reported := false
+			for _, existing := range aggregated.FailedEvaluators {
+				reported = reported || existing.EvaluatorType == failure.EvaluatorType
+			}
+			if !reported {
+				aggregated.FailedEvaluators = append(aggregated.FailedEvaluators, failure)
+			}
+		}
+		aggregated.EvaluationDurationMS += result.EvaluationDurationMS
+	}
+
+	for name, weight := range scoreWeights {
+		if weight > 0 {
+			aggregated.Scores[name] /= weight
+		}
+	}
+	for key, weight := range toolWeights {
+		if weight > 0 {
+			aggregated.ToolEvaluation[key] = aggregated.ToolEvaluation[key].(float64) / weight
+		}
+	}
+
+	return aggregated
+}
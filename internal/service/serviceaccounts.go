@@ -0,0 +1,64 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Service account scopes
+const (
+	// ScopeEvaluationsWrite allows pushing evaluation results
+	ScopeEvaluationsWrite = "evaluations:write"
+)
+
+// ServiceAccountScopes are the scopes service accounts may be granted
+var ServiceAccountScopes = []string{ScopeEvaluationsWrite}
+
+// serviceAccountTokenPrefix marks service account tokens so leaked ones are
+// easy to recognise
+const serviceAccountTokenPrefix = "sa_"
+
+// NewServiceAccountToken generates a random service account token and the
+// hash it is stored as
+func NewServiceAccountToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token = serviceAccountTokenPrefix + hex.EncodeToString(buf)
+	return token, HashServiceAccountToken(token), nil
+}
+
+// HashServiceAccountToken returns the stored form of a token. Tokens are
+// random, so an unsalted hash is enough to keep them out of the database.
+func HashServiceAccountToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateServiceAccountScopes checks that every scope is known
+func ValidateServiceAccountScopes(scopes []string) error {
+	for _, scope := range scopes {
+		known := false
+		for _, s := range ServiceAccountScopes {
+			known = known || s == scope
+		}
+		if !known {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	return nil
+}
+
+// EvaluationCallback is an evaluation result pushed by the evaluator service
+// for a queued task. Results that complete a partial evaluation name it and
+// the evaluator types that were re-run.
+type EvaluationCallback struct {
+	TaskID                string           `json:"task_id,omitempty"`
+	TriggerSource         string           `json:"trigger_source,omitempty"`
+	CompletesEvaluationID string           `json:"completes_evaluation_id,omitempty"`
+	RetriedEvaluators     []string         `json:"retried_evaluators,omitempty"`
+	Result                EvaluationResult `json:"result"`
+}
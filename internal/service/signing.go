@@ -0,0 +1,192 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Supported evaluation signing algorithms
+const (
+	SigningHMACSHA256 = "hmac-sha256"
+	SigningEd25519    = "ed25519"
+)
+
+// Signer signs stored evaluation records so later modifications can be detected
+type Signer struct {
+	algorithm  string
+	hmacKey    []byte
+	privateKey ed25519.PrivateKey
+}
+
+// NewSigner creates a signer for the given algorithm. An empty key disables
+// signing and returns a nil signer. Ed25519 keys are base64 encoded 32 byte
+// seeds or 64 byte private keys.
+func NewSigner(algorithm, key string) (*Signer, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	switch algorithm {
+	case SigningHMACSHA256:
+		return &Signer{algorithm: algorithm, hmacKey: []byte(key)}, nil
+	case SigningEd25519:
+		raw, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode ed25519 key: %w", err)
+		}
+		switch len(raw) {
+		case ed25519.SeedSize:
+			return &Signer{algorithm: algorithm, privateKey: ed25519.NewKeyFromSeed(raw)}, nil
+		case ed25519.PrivateKeySize:
+			return &Signer{algorithm: algorithm, privateKey: ed25519.PrivateKey(raw)}, nil
+		default:
+			return nil, fmt.Errorf("ed25519 key must be %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(raw))
+		}
+	default:
+		return nil, fmt.Errorf("unknown signing algorithm %q", algorithm)
+	}
+}
+
+// Algorithm returns the signing algorithm name
+func (s *Signer) Algorithm() string {
+	return s.algorithm
+}
+
+// PublicKey returns the base64 encoded verification key for asymmetric
+// algorithms, or an empty string for HMAC
+func (s *Signer) PublicKey() string {
+	if s.privateKey == nil {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(s.privateKey.Public().(ed25519.PublicKey))
+}
+
+// Sign computes the signature of an evaluation and stores it on the record
+func (s *Signer) Sign(eval *models.Evaluation) error {
+	payload, err := SigningPayload(eval)
+	if err != nil {
+		return err
+	}
+
+	var signature []byte
+	if s.privateKey != nil {
+		signature = ed25519.Sign(s.privateKey, payload)
+	} else {
+		mac := hmac.New(sha256.New, s.hmacKey)
+		mac.Write(payload)
+		signature = mac.Sum(nil)
+	}
+
+	eval.Signature = base64.StdEncoding.EncodeToString(signature)
+	eval.SignatureAlgorithm = s.algorithm
+	return nil
+}
+
+// Verify checks the stored signature of an evaluation against its contents
+func (s *Signer) Verify(eval *models.Evaluation) *models.SignatureVerification {
+	result := &models.SignatureVerification{
+		EvaluationID: eval.EvaluationID,
+		Signed:       eval.Signature != "",
+		Algorithm:    eval.SignatureAlgorithm,
+		PublicKey:    s.PublicKey(),
+	}
+
+	if !result.Signed {
+		result.Reason = "evaluation was stored without a signature"
+		return result
+	}
+	if eval.SignatureAlgorithm != s.algorithm {
+		result.Reason = fmt.Sprintf("evaluation was signed with %s but the configured algorithm is %s", eval.SignatureAlgorithm, s.algorithm)
+		return result
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(eval.Signature)
+	if err != nil {
+		result.Reason = "stored signature is not valid base64"
+		return result
+	}
+	payload, err := SigningPayload(eval)
+	if err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+
+	if s.privateKey != nil {
+		result.Valid = ed25519.Verify(s.privateKey.Public().(ed25519.PublicKey), payload, signature)
+	} else {
+		mac := hmac.New(sha256.New, s.hmacKey)
+		mac.Write(payload)
+		result.Valid = subtle.ConstantTimeCompare(mac.Sum(nil), signature) == 1
+	}
+	if !result.Valid {
+		result.Reason = "signature does not match evaluation contents"
+	}
+
+	return result
+}
+
+// signedEvaluation lists the evaluation fields covered by the signature
+type signedEvaluation struct {
+	EvaluationID           string      `json:"evaluation_id"`
+	ConversationID         string      `json:"conversation_id"`
+	OverallScore           float64     `json:"overall_score"`
+	ResponseQualityScore   float64     `json:"response_quality_score"`
+	ToolAccuracyScore      float64     `json:"tool_accuracy_score"`
+	CoherenceScore         float64     `json:"coherence_score"`
+	ToolEvaluation         interface{} `json:"tool_evaluation"`
+	IssuesDetected         interface{} `json:"issues_detected"`
+	RawIssuesDetected      interface{} `json:"raw_issues_detected"`
+	ImprovementSuggestions interface{} `json:"improvement_suggestions"`
+	EvaluatorVersion       string      `json:"evaluator_version"`
+	EvaluationDurationMS   int         `json:"evaluation_duration_ms"`
+	TaskID                 string      `json:"task_id"`
+	TriggerSource          string      `json:"trigger_source"`
+	CreatedAt              string      `json:"created_at"`
+}
+
+// SigningPayload returns the canonical bytes signed for an evaluation.
+// JSON columns are decoded and re-encoded so formatting changes made by the
+// database do not invalidate signatures.
+func SigningPayload(eval *models.Evaluation) ([]byte, error) {
+	payload := signedEvaluation{
+		EvaluationID:         eval.EvaluationID,
+		ConversationID:       eval.ConversationID,
+		OverallScore:         eval.OverallScore,
+		ResponseQualityScore: eval.ResponseQualityScore,
+		ToolAccuracyScore:    eval.ToolAccuracyScore,
+		CoherenceScore:       eval.CoherenceScore,
+		EvaluatorVersion:     eval.EvaluatorVersion,
+		EvaluationDurationMS: eval.EvaluationDurationMS,
+		TaskID:               eval.TaskID,
+		TriggerSource:        eval.TriggerSource,
+		CreatedAt:            eval.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+
+	fields := []struct {
+		raw  json.RawMessage
+		dest *interface{}
+	}{
+		{eval.ToolEvaluation, &payload.ToolEvaluation},
+		{eval.IssuesDetected, &payload.IssuesDetected},
+		{eval.RawIssuesDetected, &payload.RawIssuesDetected},
+		{eval.ImprovementSuggestions, &payload.ImprovementSuggestions},
+	}
+	for _, field := range fields {
+		if len(field.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(field.raw, field.dest); err != nil {
+			return nil, fmt.Errorf("failed to canonicalize evaluation JSON: %w", err)
+		}
+	}
+
+	return json.Marshal(payload)
+}
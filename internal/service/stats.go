@@ -0,0 +1,164 @@
+package services
+
+import (
+	"math"
+	"sort"
+)
+
+// meanStdDev returns the mean and sample standard deviation of values
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)-1))
+}
+
+// welchTTest returns Welch's t statistic of b against a and its two-sided
+// p-value. ok is false when either sample has fewer than two values or both
+// have no variance.
+func welchTTest(a, b []float64) (t, p float64, ok bool) {
+	if len(a) < 2 || len(b) < 2 {
+		return 0, 0, false
+	}
+	meanA, sdA := meanStdDev(a)
+	meanB, sdB := meanStdDev(b)
+	varA, varB := sdA*sdA/float64(len(a)), sdB*sdB/float64(len(b))
+	se := math.Sqrt(varA + varB)
+	if se == 0 {
+		return 0, 0, false
+	}
+
+	t = (meanB - meanA) / se
+	// Welch-Satterthwaite degrees of freedom
+	df := (varA + varB) * (varA + varB) /
+		(varA*varA/float64(len(a)-1) + varB*varB/float64(len(b)-1))
+	p = regularizedIncompleteBeta(df/(df+t*t), df/2, 0.5)
+	return t, math.Min(p, 1), true
+}
+
+// mannWhitneyU returns the Mann-Whitney U statistic of b against a and its
+// two-sided p-value by the normal approximation, corrected for ties and
+// continuity. ok is false when either sample is empty or every value ties.
+func mannWhitneyU(a, b []float64) (u, p float64, ok bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return 0, 0, false
+	}
+
+	type sample struct {
+		value float64
+		fromB bool
+	}
+	all := make([]sample, 0, len(a)+len(b))
+	for _, v := range a {
+		all = append(all, sample{v, false})
+	}
+	for _, v := range b {
+		all = append(all, sample{v, true})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// Tied values share the average of their ranks
+	var rankSumB, tieTerm float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromB {
+				rankSumB += rank
+			}
+		}
+		ties := float64(j - i)
+		tieTerm += ties*ties*ties - ties
+		i = j
+	}
+
+	nA, nB := float64(len(a)), float64(len(b))
+	n := nA + nB
+	u = rankSumB - nB*(nB+1)/2
+	variance := nA * nB / 12 * ((n + 1) - tieTerm/(n*(n-1)))
+	if variance <= 0 {
+		return u, 0, false
+	}
+
+	diff := math.Abs(u-nA*nB/2) - 0.5
+	if diff < 0 {
+		diff = 0
+	}
+	z := diff / math.Sqrt(variance)
+	return u, math.Min(math.Erfc(z/math.Sqrt2), 1), true
+}
+
+// regularizedIncompleteBeta returns I_x(a, b), evaluated by its continued
+// fraction
+func regularizedIncompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lgA, _ := math.Lgamma(a)
+	lgB, _ := math.Lgamma(b)
+	lgAB, _ := math.Lgamma(a + b)
+	front := math.Exp(lgAB - lgA - lgB + a*math.Log(x) + b*math.Log(1-x))
+
+	// The continued fraction converges quickly only below the mean
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaContinuedFraction(1-x, b, a)/b
+	}
+	return front * betaContinuedFraction(x, a, b) / a
+}
+
+// betaContinuedFraction evaluates the incomplete beta function's continued
+// fraction by the modified Lentz method
+func betaContinuedFraction(x, a, b float64) float64 {
+	const (
+		maxIterations = 200
+		epsilon       = 1e-12
+		tiny          = 1e-300
+	)
+
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		for _, numerator := range []float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + numerator*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + numerator/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			h *= d * c
+		}
+		if math.Abs(d*c-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
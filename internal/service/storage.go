@@ -0,0 +1,102 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Storage action kinds
+const (
+	StorageActionRetention    = "retention"
+	StorageActionPartition    = "partition"
+	StorageActionReviewIndex  = "review_indexes"
+	StorageActionOffloadJSONB = "offload_payloads"
+)
+
+// Thresholds above which storage actions are suggested
+const (
+	storagePartitionBytes = 10 << 30  // Tables this large are worth partitioning
+	storageRetentionBytes = 1 << 30   // Tables projected past this within 90 days need retention
+	storageIndexRatio     = 1.5       // Index size as a multiple of table size
+	storageIndexMinBytes  = 100 << 20 // Ignore index ratios on small tables
+	storageLargeJSONB     = 1 << 20   // p99 payload size that is worth moving out of the row
+)
+
+// ProjectStorageGrowth fills in the growth projections of a table from the
+// rows it gained over the growth window
+func ProjectStorageGrowth(table *models.TableStorage, recentRows int64, growthDays int) {
+	if growthDays <= 0 || !table.HasCreated {
+		return
+	}
+
+	table.RowsPerDay = float64(recentRows) / float64(growthDays)
+	if table.RowEstimate > 0 {
+		bytesPerRow := float64(table.TotalBytes) / float64(table.RowEstimate)
+		table.BytesPerDay = table.RowsPerDay * bytesPerRow
+	}
+	table.Projected30 = table.TotalBytes + int64(table.BytesPerDay*30)
+	table.Projected90 = table.TotalBytes + int64(table.BytesPerDay*90)
+}
+
+// SuggestStorageActions suggests retention, partitioning and index changes
+// from a storage report's tables and JSONB columns
+func SuggestStorageActions(tables []models.TableStorage, columns []models.JSONBColumnStats) []models.StorageAction {
+	actions := []models.StorageAction{}
+
+	for _, table := range tables {
+		if table.HasCreated && table.TotalBytes >= storagePartitionBytes {
+			actions = append(actions, models.StorageAction{
+				Table:  table.Table,
+				Action: StorageActionPartition,
+				Reason: fmt.Sprintf("%s on disk; partitioning by created_at month makes retention a partition drop",
+					formatBytes(table.TotalBytes)),
+			})
+		}
+
+		if table.HasCreated && table.BytesPerDay > 0 && table.Projected90 >= storageRetentionBytes {
+			actions = append(actions, models.StorageAction{
+				Table:  table.Table,
+				Action: StorageActionRetention,
+				Reason: fmt.Sprintf("Growing %s/day (%.0f rows/day), projected %s in 90 days; delete or archive old rows",
+					formatBytes(int64(table.BytesPerDay)), table.RowsPerDay, formatBytes(table.Projected90)),
+			})
+		}
+
+		if table.IndexBytes >= storageIndexMinBytes && float64(table.IndexBytes) > storageIndexRatio*float64(table.TableBytes) {
+			actions = append(actions, models.StorageAction{
+				Table:  table.Table,
+				Action: StorageActionReviewIndex,
+				Reason: fmt.Sprintf("Indexes use %s against %s of data; drop unused indexes or reindex bloated ones",
+					formatBytes(table.IndexBytes), formatBytes(table.TableBytes)),
+			})
+		}
+	}
+
+	for _, column := range columns {
+		if column.P99Bytes >= storageLargeJSONB {
+			actions = append(actions, models.StorageAction{
+				Table:  column.Table,
+				Action: StorageActionOffloadJSONB,
+				Reason: fmt.Sprintf("%s p99 payload is %s; move large payloads to object storage or trim them",
+					column.Column, formatBytes(int64(column.P99Bytes))),
+			})
+		}
+	}
+
+	return actions
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Tokenizer estimates how many tokens a model's tokenizer splits text into,
+// without loading vocabularies or calling the provider. Text is split the way
+// BPE tokenizers pre-tokenize it (words with their leading space, digit
+// groups, punctuation runs, whitespace) and each piece is priced by the
+// family's typical piece lengths. Counts are estimates good enough for cost
+// attribution and context budgeting, not exact billing figures.
+type Tokenizer struct {
+	Name string
+
+	wordChars    int     // ASCII letters per token in words longer than one token
+	foreignChars int     // Letters per token in non-ASCII scripts written with spaces
+	ideographs   float64 // Tokens per CJK character
+}
+
+// messageOverheadTokens is what chat formats add around each message for its
+// role and delimiters
+const messageOverheadTokens = 4
+
+// tokenizerFamilies maps model name prefixes to their tokenizer. The longest
+// matching prefix wins; unknown models use cl100k.
+var tokenizerFamilies = map[string]Tokenizer{
+	"gpt-4o":  o200kTokenizer,
+	"gpt-4.1": o200kTokenizer,
+	"gpt-5":   o200kTokenizer,
+	"o1":      o200kTokenizer,
+	"o3":      o200kTokenizer,
+	"o4":      o200kTokenizer,
+	"gpt-4":   cl100kTokenizer,
+	"gpt-3.5": cl100kTokenizer,
+	"claude":  {Name: "claude", wordChars: 5, foreignChars: 3, ideographs: 1.3},
+	"llama":   sentencePieceTokenizer,
+	"mistral": sentencePieceTokenizer,
+	"gemini":  sentencePieceTokenizer,
+}
+
+var (
+	cl100kTokenizer        = Tokenizer{Name: "cl100k", wordChars: 6, foreignChars: 3, ideographs: 1.5}
+	o200kTokenizer         = Tokenizer{Name: "o200k", wordChars: 7, foreignChars: 4, ideographs: 1}
+	sentencePieceTokenizer = Tokenizer{Name: "sentencepiece", wordChars: 5, foreignChars: 3, ideographs: 1.2}
+)
+
+// TokenizerFor returns the tokenizer of a model, by name
+func TokenizerFor(model string) Tokenizer {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:] // Provider-qualified names such as openai/gpt-4o
+	}
+
+	prefixes := make([]string, 0, len(tokenizerFamilies))
+	for prefix := range tokenizerFamilies {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(model, prefix) {
+			return tokenizerFamilies[prefix]
+		}
+	}
+	return cl100kTokenizer
+}
+
+// Count estimates the number of tokens in text
+func (t Tokenizer) Count(text string) int {
+	tokens := 0.0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		n := i + size
+		switch {
+		case r == ' ' && n < len(text) && isWordRune(text[n:]):
+			// A single space is part of the word after it
+		case unicode.IsSpace(r):
+			for n < len(text) {
+				next, size := utf8.DecodeRuneInString(text[n:])
+				if !unicode.IsSpace(next) {
+					break
+				}
+				n += size
+			}
+			tokens++
+		case unicode.IsDigit(r):
+			n = scanRun(text, n, unicode.IsDigit)
+			tokens += math.Ceil(float64(utf8.RuneCountInString(text[i:n])) / 3)
+		case isIdeograph(r):
+			tokens += t.ideographs
+		case unicode.IsLetter(r):
+			n = scanRun(text, n, func(r rune) bool { return unicode.IsLetter(r) && !isIdeograph(r) })
+			word := text[i:n]
+			if chars := utf8.RuneCountInString(word); chars == len(word) {
+				tokens += math.Ceil(float64(chars) / float64(t.wordChars))
+			} else {
+				tokens += math.Ceil(float64(chars) / float64(t.foreignChars))
+			}
+		case r < utf8.RuneSelf:
+			n = scanRun(text, n, func(r rune) bool { return r < utf8.RuneSelf && (unicode.IsPunct(r) || unicode.IsSymbol(r)) })
+			tokens += math.Ceil(float64(n-i) / 2)
+		default:
+			tokens += 2 // Emoji and other symbols take a few byte-level tokens
+		}
+		i = n
+	}
+	return int(math.Ceil(tokens))
+}
+
+// CountJSON estimates the number of tokens of a value as it is sent to a
+// model, serialized as JSON
+func (t Tokenizer) CountJSON(value interface{}) int {
+	if value == nil {
+		return 0
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return t.Count(string(data))
+}
+
+// CountTurn estimates the tokens of a turn: its content, the names and
+// arguments of the tools it calls, and the tool results it carries
+func (t Tokenizer) CountTurn(turn models.Turn) models.TurnTokenCount {
+	count := models.TurnTokenCount{
+		TurnID:  turn.TurnID,
+		Role:    turn.Role,
+		Content: t.Count(turn.Content),
+	}
+	for _, call := range turn.ToolCalls {
+		count.ToolCalls += t.Count(call.ToolName) + t.CountJSON(call.Parameters)
+		if call.Result != nil {
+			count.ToolResults += t.CountJSON(call.Result)
+		}
+	}
+	if turn.Result != nil {
+		count.ToolResults += t.CountJSON(turn.Result)
+	}
+	count.Total = count.Content + count.ToolCalls + count.ToolResults + messageOverheadTokens
+	return count
+}
+
+// CountTurns estimates the tokens of each turn of a conversation and their
+// totals, using the tokenizer of the model the agent ran on, and sums the
+// tokens the agent reported for its turns or else in its metadata
+func CountTurns(turns []models.Turn, metadata models.ConversationMetadata) *models.TokenCounts {
+	t := TokenizerFor(metadata.Model)
+	counts := &models.TokenCounts{
+		Tokenizer: t.Name,
+		Turns:     make([]models.TurnTokenCount, len(turns)),
+	}
+	for i, turn := range turns {
+		count := t.CountTurn(turn)
+		counts.Turns[i] = count
+		counts.Content += count.Content
+		counts.ToolCalls += count.ToolCalls
+		counts.ToolResults += count.ToolResults
+		counts.Total += count.Total
+		counts.InputTokens += turn.InputTokens
+		counts.OutputTokens += turn.OutputTokens
+	}
+	if counts.InputTokens == 0 && counts.OutputTokens == 0 {
+		counts.InputTokens, counts.OutputTokens = metadata.InputTokens, metadata.OutputTokens
+	}
+	return counts
+}
+
+// countEvaluatorTurn estimates the tokens of a turn as sent to the evaluator
+func (t Tokenizer) countEvaluatorTurn(turn map[string]interface{}) int {
+	tokens := messageOverheadTokens
+	if content, ok := turn["content"].(string); ok {
+		tokens += t.Count(content)
+	}
+	tokens += t.CountJSON(turn["tool_calls"])
+	return tokens
+}
+
+// PriceTokenUsage sets the estimated cost of token usage rows whose model has
+// a price. Prices are in USD per million tokens, by model name or name
+// prefix; the longest matching prefix wins.
+func PriceTokenUsage(usage []models.TokenUsage, prices map[string]float64) {
+	for i := range usage {
+		price, ok := modelPrice(prices, usage[i].Model)
+		if !ok {
+			continue
+		}
+		cost := float64(usage[i].TotalTokens) * price / 1e6
+		usage[i].EstimatedCostUSD = &cost
+	}
+}
+
+// modelPrice returns the price of the longest prefix of prices matching
+// model
+func modelPrice(prices map[string]float64, model string) (float64, bool) {
+	model = strings.ToLower(model)
+	matched := ""
+	for prefix := range prices {
+		if strings.HasPrefix(model, strings.ToLower(prefix)) && len(prefix) > len(matched) {
+			matched = prefix
+		}
+	}
+	if matched == "" {
+		return 0, false
+	}
+	return prices[matched], true
+}
+
+// scanRun returns the end of the run of runes matching in starting at i
+func scanRun(text string, i int, in func(rune) bool) int {
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !in(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// isWordRune reports whether text starts with a letter or digit
+func isWordRune(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isIdeograph reports whether r belongs to a script that tokenizers split
+// character by character
+func isIdeograph(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Thai)
+}
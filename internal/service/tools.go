@@ -0,0 +1,131 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// ToolSpec describes a tool the agent is allowed to call
+type ToolSpec struct {
+	Name              string `json:"name"`
+	ExpectedLatencyMS int    `json:"expected_latency_ms"`
+}
+
+// ToolRegistry holds the known tools and their expectations
+type ToolRegistry struct {
+	mu               sync.RWMutex
+	tools            map[string]ToolSpec
+	defaultLatencyMS int
+	goldens          map[string][]toolGolden // By tool name
+}
+
+// NewToolRegistry creates a registry from a tool name to expected latency mapping.
+// Tools that are not registered fall back to defaultLatencyMS.
+func NewToolRegistry(latencies map[string]int, defaultLatencyMS int) *ToolRegistry {
+	r := &ToolRegistry{
+		tools:            make(map[string]ToolSpec),
+		defaultLatencyMS: defaultLatencyMS,
+	}
+	for name, latency := range latencies {
+		r.Register(ToolSpec{Name: name, ExpectedLatencyMS: latency})
+	}
+	return r
+}
+
+// Register adds or replaces a tool spec
+func (r *ToolRegistry) Register(spec ToolSpec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools[spec.Name] = spec
+}
+
+// SetLatencies replaces the expected latencies of all registered tools
+func (r *ToolRegistry) SetLatencies(latencies map[string]int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools = make(map[string]ToolSpec, len(latencies))
+	for name, latency := range latencies {
+		r.tools[name] = ToolSpec{Name: name, ExpectedLatencyMS: latency}
+	}
+}
+
+// SetDefaultLatency replaces the latency SLA of tools without one of their own
+func (r *ToolRegistry) SetDefaultLatency(latencyMS int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaultLatencyMS = latencyMS
+}
+
+// Latencies returns the tool name to expected latency mapping
+func (r *ToolRegistry) Latencies() map[string]int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	latencies := make(map[string]int, len(r.tools))
+	for name, spec := range r.tools {
+		latencies[name] = spec.ExpectedLatencyMS
+	}
+	return latencies
+}
+
+// Get returns the spec for a tool
+func (r *ToolRegistry) Get(name string) (ToolSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.tools[name]
+	return spec, ok
+}
+
+// Tools returns all registered tools sorted by name
+func (r *ToolRegistry) Tools() []ToolSpec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	specs := make([]ToolSpec, 0, len(r.tools))
+	for _, spec := range r.tools {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+// ExpectedLatency returns the latency SLA in milliseconds for a tool
+func (r *ToolRegistry) ExpectedLatency(name string) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if spec, ok := r.tools[name]; ok && spec.ExpectedLatencyMS > 0 {
+		return spec.ExpectedLatencyMS
+	}
+	return r.defaultLatencyMS
+}
+
+// DetectLatencyIssues returns a tool_latency_exceeded issue for every tool call
+// whose recorded latency breaches the tool's SLA
+func (r *ToolRegistry) DetectLatencyIssues(turns []models.Turn) []models.IssueDetected {
+	var issues []models.IssueDetected
+
+	for _, turn := range turns {
+		for _, call := range turn.ToolCalls {
+			expected := r.ExpectedLatency(call.ToolName)
+			if call.LatencyMS <= 0 || expected <= 0 || call.LatencyMS <= expected {
+				continue
+			}
+
+			severity := "medium"
+			if call.LatencyMS > 2*expected {
+				severity = "high"
+			}
+
+			issues = append(issues, models.IssueDetected{
+				Type:     "tool_latency_exceeded",
+				Severity: severity,
+				Description: fmt.Sprintf("Tool %s took %dms, exceeding its %dms SLA",
+					call.ToolName, call.LatencyMS, expected),
+				TurnID: turn.TurnID,
+			})
+		}
+	}
+
+	return issues
+}
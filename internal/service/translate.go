@@ -0,0 +1,263 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ai-agent-eval/internal/llm"
+)
+
+// maxLanguageTagLength bounds the language tags passed on to the LLM
+const maxLanguageTagLength = 35
+
+// maxTranslationTokens caps the completion of a batch of translations
+const maxTranslationTokens = 4000
+
+// translationSystemPrompt is the system prompt of LLM translations
+const translationSystemPrompt = "You are a professional translator. Always respond in valid JSON format."
+
+// TranslationBackend translates texts into a language, keeping their order
+type TranslationBackend interface {
+	Translate(ctx context.Context, texts []string, language string) ([]string, error)
+}
+
+// Translator localizes stored issue and suggestion text through a backend,
+// caching translations in memory
+type Translator struct {
+	backend    TranslationBackend
+	source     string
+	maxEntries int
+
+	mu    sync.Mutex
+	cache map[translationKey]string
+}
+
+type translationKey struct {
+	language string
+	text     string
+}
+
+// NewTranslator creates a translator for text written in sourceLanguage that
+// caches up to cacheSize translations
+func NewTranslator(backend TranslationBackend, sourceLanguage string, cacheSize int) *Translator {
+	return &Translator{
+		backend:    backend,
+		source:     sourceLanguage,
+		maxEntries: cacheSize,
+		cache:      make(map[translationKey]string),
+	}
+}
+
+// Language returns the language an Accept-Language header prefers, or an
+// empty string when the client prefers the source language, accepts any
+// language or names no valid one
+func (t *Translator) Language(acceptLanguage string) string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 || !validLanguageTag(tag) {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	preferred := tags[0].tag
+	if preferred == "*" || strings.EqualFold(primaryLanguage(preferred), primaryLanguage(t.source)) {
+		return ""
+	}
+	return preferred
+}
+
+// validLanguageTag reports whether tag looks like a BCP 47 language tag
+func validLanguageTag(tag string) bool {
+	if tag == "*" {
+		return true
+	}
+	if tag == "" || len(tag) > maxLanguageTagLength {
+		return false
+	}
+	for _, r := range tag {
+		if !(r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// primaryLanguage returns the primary subtag of a language tag, so en-GB
+// matches en
+func primaryLanguage(tag string) string {
+	primary, _, _ := strings.Cut(tag, "-")
+	return primary
+}
+
+// Translate translates texts into language, in order. Empty texts are kept
+// as they are and only texts missing from the cache are sent to the
+// backend, once each.
+func (t *Translator) Translate(ctx context.Context, language string, texts []string) ([]string, error) {
+	result := make([]string, len(texts))
+	var missing []string
+	seen := make(map[string]bool)
+
+	t.mu.Lock()
+	for i, text := range texts {
+		if text == "" {
+			continue
+		}
+		if translated, ok := t.cache[translationKey{language, text}]; ok {
+			result[i] = translated
+			continue
+		}
+		if !seen[text] {
+			seen[text] = true
+			missing = append(missing, text)
+		}
+	}
+	t.mu.Unlock()
+
+	if len(missing) > 0 {
+		translations, err := t.backend.Translate(ctx, missing, language)
+		if err != nil {
+			return nil, err
+		}
+
+		t.mu.Lock()
+		if len(t.cache)+len(missing) > t.maxEntries {
+			// Start over rather than track recency
+			t.cache = make(map[translationKey]string)
+		}
+		for i, text := range missing {
+			t.cache[translationKey{language, text}] = translations[i]
+		}
+		for i, text := range texts {
+			if text != "" && result[i] == "" {
+				result[i] = t.cache[translationKey{language, text}]
+			}
+		}
+		t.mu.Unlock()
+	}
+
+	return result, nil
+}
+
+// Translate asks the evaluator service to translate texts into language
+// with its LLM
+func (s *EvaluatorService) Translate(ctx context.Context, texts []string, language string) ([]string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"texts":           texts,
+		"target_language": language,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/translate", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.evalClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call evaluator service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("evaluator service returned status %d", resp.StatusCode)
+	}
+	var result struct {
+		Translations []string `json:"translations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.Translations) != len(texts) {
+		return nil, fmt.Errorf("evaluator service returned %d translations for %d texts", len(result.Translations), len(texts))
+	}
+
+	return result.Translations, nil
+}
+
+// LLMTranslation translates with the API's own LLM client rather than the
+// evaluator service's, so translations fall back across providers and count
+// towards the client's usage
+type LLMTranslation struct {
+	client *llm.Client
+}
+
+// NewLLMTranslation creates a translation backend completing with client
+func NewLLMTranslation(client *llm.Client) *LLMTranslation {
+	return &LLMTranslation{client: client}
+}
+
+// Translate asks the LLM to translate texts into language, as a JSON object
+// holding one translation per text
+func (l *LLMTranslation) Translate(ctx context.Context, texts []string, language string) ([]string, error) {
+	if len(texts) == 0 {
+		return []string{}, nil
+	}
+	textsJSON, err := json.Marshal(texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal texts: %w", err)
+	}
+
+	prompt := fmt.Sprintf(`Translate each text in the JSON array below into the language with BCP 47 tag "%s".
+Keep tool names, identifiers and numbers unchanged.
+
+Texts:
+%s
+
+Respond with a JSON object of the form {"translations": ["..."]} with exactly %d translations, in the same order.`, language, textsJSON, len(texts))
+
+	temperature := 0.0
+	resp, err := l.client.Complete(ctx, &llm.Request{
+		System:      translationSystemPrompt,
+		Messages:    []llm.Message{{Role: "user", Content: prompt}},
+		MaxTokens:   maxTranslationTokens,
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Models sometimes wrap the object in prose or a code fence
+	content := resp.Content
+	if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+		content = content[start : end+1]
+	}
+	var result struct {
+		Translations []string `json:"translations"`
+	}
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		return nil, fmt.Errorf("could not parse LLM response as JSON: %w", err)
+	}
+	if len(result.Translations) != len(texts) {
+		return nil, fmt.Errorf("LLM returned %d translations for %d texts", len(result.Translations), len(texts))
+	}
+
+	return result.Translations, nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// ValidateTurns checks role-specific constraints that struct tags can't express
+func ValidateTurns(turns []models.Turn) error {
+	for _, turn := range turns {
+		switch turn.Role {
+		case models.RoleAssistant:
+			continue
+		case models.RoleTool, models.RoleFunction:
+			if turn.ToolCallID == "" && turn.Name == "" {
+				return fmt.Errorf("turn %d: %s turns require tool_call_id or name", turn.TurnID, turn.Role)
+			}
+		default:
+			if turn.ToolCallID != "" || turn.Result != nil {
+				return fmt.Errorf("turn %d: only tool turns may carry a tool result", turn.TurnID)
+			}
+		}
+		if len(turn.ToolCalls) > 0 {
+			return fmt.Errorf("turn %d: only assistant turns may make tool calls", turn.TurnID)
+		}
+	}
+	return nil
+}
+
+// EvaluatorTurns maps turns to the payload expected by the Python evaluators.
+// Function turns are reported as tool turns, and standalone tool results are
+// also copied onto the assistant tool call they answer, since the evaluators
+// read results from tool_calls.
+func EvaluatorTurns(turns []models.Turn) []map[string]interface{} {
+	// Locate tool calls by ID and by name so results can be attached
+	type callRef struct{ turn, call int }
+	byID := make(map[string]callRef)
+	byName := make(map[string]callRef)
+	for i, turn := range turns {
+		for j, call := range turn.ToolCalls {
+			if call.ID != "" {
+				byID[call.ID] = callRef{i, j}
+			}
+			byName[call.ToolName] = callRef{i, j}
+		}
+	}
+
+	results := make(map[callRef]map[string]interface{})
+	for _, turn := range turns {
+		if turn.Role != models.RoleTool && turn.Role != models.RoleFunction {
+			continue
+		}
+		ref, ok := byID[turn.ToolCallID]
+		if !ok && turn.ToolCallID == "" {
+			ref, ok = byName[turn.Name]
+		}
+		if ok && turn.Result != nil {
+			results[ref] = turn.Result
+		}
+	}
+
+	payload := make([]map[string]interface{}, 0, len(turns))
+	for i, turn := range turns {
+		entry := map[string]interface{}{
+			"turn_id":   turn.TurnID,
+			"role":      turn.Role,
+			"content":   turn.Content,
+			"timestamp": turn.Timestamp,
+		}
+		if turn.Role == models.RoleFunction {
+			entry["role"] = models.RoleTool
+		}
+
+		if len(turn.ToolCalls) > 0 {
+			calls := make([]map[string]interface{}, 0, len(turn.ToolCalls))
+			for j, call := range turn.ToolCalls {
+				result := call.Result
+				if result == nil {
+					result = results[callRef{i, j}]
+				}
+				calls = append(calls, map[string]interface{}{
+					"id":         call.ID,
+					"tool_name":  call.ToolName,
+					"parameters": call.Parameters,
+					"result":     result,
+					"latency_ms": call.LatencyMS,
+				})
+			}
+			entry["tool_calls"] = calls
+		}
+		if turn.ToolCallID != "" {
+			entry["tool_call_id"] = turn.ToolCallID
+		}
+		if turn.Name != "" {
+			entry["name"] = turn.Name
+		}
+		if turn.Result != nil {
+			entry["result"] = turn.Result
+		}
+		if len(turn.Attachments) > 0 {
+			entry["attachments"] = turn.Attachments
+		}
+
+		payload = append(payload, entry)
+	}
+
+	return payload
+}
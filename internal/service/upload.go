@@ -0,0 +1,221 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// UploadRecord is one conversation read from an uploaded file. Err is set
+// when the record couldn't be parsed, in which case Conversation may be nil.
+type UploadRecord struct {
+	Line           int // First line of the record in the file
+	ConversationID string
+	Conversation   *models.ConversationCreate
+	Err            error
+}
+
+// UploadFormat infers the format of an uploaded file from its name. It
+// returns an empty string for unrecognised extensions.
+func UploadFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jsonl", ".ndjson":
+		return models.ImportFormatJSONL
+	case ".csv":
+		return models.ImportFormatCSV
+	}
+	return ""
+}
+
+// ParseUpload streams conversations out of an uploaded file, calling fn for
+// each one. Records that fail to parse are passed to fn with Err set. Parsing
+// stops at the first error returned by fn or from reading r.
+func ParseUpload(r io.Reader, format string, fn func(UploadRecord) error) error {
+	switch format {
+	case models.ImportFormatJSONL:
+		return parseUploadJSONL(r, fn)
+	case models.ImportFormatCSV:
+		return parseUploadCSV(r, fn)
+	}
+	return fmt.Errorf("unsupported upload format %q", format)
+}
+
+// parseUploadJSONL reads one conversation per line, in the same shape as the
+// conversation ingestion API. Blank lines are skipped.
+func parseUploadJSONL(r io.Reader, fn func(UploadRecord) error) error {
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("failed to read line %d: %w", line, readErr)
+		}
+
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			record := UploadRecord{Line: line}
+			var conv models.ConversationCreate
+			if err := json.Unmarshal(data, &conv); err != nil {
+				record.Err = fmt.Errorf("invalid JSON: %w", err)
+			} else {
+				record.Conversation = &conv
+			}
+			record.ConversationID = conv.ConversationID
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+}
+
+// uploadRequiredColumns must appear in the header of CSV uploads
+var uploadRequiredColumns = []string{"conversation_id", "agent_version", "role", "content"}
+
+// parseUploadCSV reads one turn per row; consecutive rows with the same
+// conversation_id form a conversation. Besides the required columns, rows may
+// set turn_id, timestamp (RFC3339), name, tool_call_id, and JSON tool_calls,
+// result and metadata. Turns without a turn_id are numbered in order, and
+// metadata is read from the first row of each conversation that sets it.
+func parseUploadCSV(r io.Reader, fn func(UploadRecord) error) error {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, name := range uploadRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("CSV header is missing required column %q", name)
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var current *UploadRecord
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		record := *current
+		current = nil
+		return fn(record)
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return fmt.Errorf("failed to read CSV: %w", err)
+			}
+			// Malformed rows are reported on their own and reading continues
+			if err := flush(); err != nil {
+				return err
+			}
+			if err := fn(UploadRecord{Line: parseErr.StartLine, Err: parseErr}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		conversationID := field(row, "conversation_id")
+		if current == nil || current.ConversationID != conversationID {
+			if err := flush(); err != nil {
+				return err
+			}
+			current = &UploadRecord{
+				Line:           line,
+				ConversationID: conversationID,
+				Conversation: &models.ConversationCreate{
+					ConversationID: conversationID,
+					AgentVersion:   field(row, "agent_version"),
+				},
+			}
+		}
+		if current.Err != nil {
+			continue
+		}
+
+		turn, err := csvTurn(row, field, len(current.Conversation.Turns)+1)
+		if err == nil && current.Conversation.Metadata == nil {
+			if raw := field(row, "metadata"); raw != "" {
+				var metadata models.ConversationMetadata
+				if jsonErr := json.Unmarshal([]byte(raw), &metadata); jsonErr != nil {
+					err = fmt.Errorf("invalid metadata: %w", jsonErr)
+				}
+				current.Conversation.Metadata = &metadata
+			}
+		}
+		if err != nil {
+			current.Err = fmt.Errorf("line %d: %w", line, err)
+			continue
+		}
+		current.Conversation.Turns = append(current.Conversation.Turns, turn)
+	}
+
+	return flush()
+}
+
+// csvTurn builds a turn from a CSV row, numbering it defaultID when the row
+// has no turn_id
+func csvTurn(row []string, field func([]string, string) string, defaultID int) (models.Turn, error) {
+	turn := models.Turn{
+		TurnID:     defaultID,
+		Role:       field(row, "role"),
+		Content:    field(row, "content"),
+		Name:       field(row, "name"),
+		ToolCallID: field(row, "tool_call_id"),
+	}
+
+	if raw := field(row, "turn_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			return turn, fmt.Errorf("invalid turn_id %q", raw)
+		}
+		turn.TurnID = id
+	}
+	if raw := field(row, "timestamp"); raw != "" {
+		ts, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return turn, fmt.Errorf("invalid timestamp %q: expected RFC3339", raw)
+		}
+		turn.Timestamp = ts
+	}
+	if raw := field(row, "tool_calls"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &turn.ToolCalls); err != nil {
+			return turn, fmt.Errorf("invalid tool_calls: %w", err)
+		}
+	}
+	if raw := field(row, "result"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &turn.Result); err != nil {
+			return turn, fmt.Errorf("invalid result: %w", err)
+		}
+	}
+
+	return turn, nil
+}
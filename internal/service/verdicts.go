@@ -0,0 +1,142 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// verdicts are the verdicts a policy may give
+var verdicts = map[string]bool{
+	models.VerdictPass:        true,
+	models.VerdictFail:        true,
+	models.VerdictNeedsReview: true,
+}
+
+// DefaultVerdictPolicy fails evaluations with critical issues or an overall
+// score below lowScore, sends partial evaluations to review and passes the
+// rest, matching how conversations are routed by default
+func DefaultVerdictPolicy(lowScore float64) models.VerdictPolicy {
+	partial := true
+	return models.VerdictPolicy{
+		Rules: []models.VerdictRule{
+			{Name: "critical_issue", Verdict: models.VerdictFail, MinIssueSeverity: "critical"},
+			{Name: "low_score", Verdict: models.VerdictFail, OverallBelow: &lowScore},
+			{Name: "partial", Verdict: models.VerdictNeedsReview, Partial: &partial},
+		},
+		Default: models.VerdictPass,
+	}
+}
+
+// IsVerdict reports whether a verdict is one a policy may give
+func IsVerdict(verdict string) bool {
+	return verdicts[verdict]
+}
+
+// ValidateVerdictPolicy checks that a policy's rules are named uniquely and
+// give known verdicts on known severities, and that its default is a verdict
+func ValidateVerdictPolicy(policy models.VerdictPolicy) error {
+	if !verdicts[policy.Default] {
+		return fmt.Errorf("unknown default verdict %q", policy.Default)
+	}
+	names := make(map[string]bool, len(policy.Rules))
+	for i, rule := range policy.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d: name is required", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rule %s: duplicate name", rule.Name)
+		}
+		names[rule.Name] = true
+		if !verdicts[rule.Verdict] {
+			return fmt.Errorf("rule %s: unknown verdict %q", rule.Name, rule.Verdict)
+		}
+		if _, ok := severityRank[rule.MinIssueSeverity]; rule.MinIssueSeverity != "" && !ok {
+			return fmt.Errorf("rule %s: unknown severity %q", rule.Name, rule.MinIssueSeverity)
+		}
+	}
+	return nil
+}
+
+// DecideVerdict returns an evaluation's verdict under a policy and the name
+// of the rule that decided it, empty when no rule matched
+func DecideVerdict(policy models.VerdictPolicy, eval *models.Evaluation) (string, string, error) {
+	var issues []models.IssueDetected
+	if len(eval.IssuesDetected) > 0 {
+		if err := json.Unmarshal(eval.IssuesDetected, &issues); err != nil {
+			return "", "", fmt.Errorf("failed to parse issues: %w", err)
+		}
+	}
+	components := make(map[string]float64)
+	if len(eval.ComponentScores) > 0 {
+		if err := json.Unmarshal(eval.ComponentScores, &components); err != nil {
+			return "", "", fmt.Errorf("failed to parse component scores: %w", err)
+		}
+	}
+
+	for _, rule := range policy.Rules {
+		if verdictRuleMatches(rule, eval, issues, components) {
+			return rule.Verdict, rule.Name, nil
+		}
+	}
+	if policy.Default == "" {
+		return models.VerdictPass, "", nil
+	}
+	return policy.Default, "", nil
+}
+
+// ApplyVerdict sets an evaluation's verdict under a policy
+func ApplyVerdict(policy models.VerdictPolicy, eval *models.Evaluation) error {
+	verdict, rule, err := DecideVerdict(policy, eval)
+	if err != nil {
+		return err
+	}
+	eval.Verdict, eval.VerdictRule = verdict, rule
+	return nil
+}
+
+func verdictRuleMatches(rule models.VerdictRule, eval *models.Evaluation, issues []models.IssueDetected, components map[string]float64) bool {
+	if rule.Partial != nil && eval.Partial != *rule.Partial {
+		return false
+	}
+	if rule.OverallBelow != nil && eval.OverallScore >= *rule.OverallBelow {
+		return false
+	}
+	if len(rule.ComponentsBelow) > 0 {
+		below := false
+		for component, threshold := range rule.ComponentsBelow {
+			// Components that weren't scored, like those of failed evaluators, never match
+			if score, ok := components[component]; ok && score < threshold {
+				below = true
+				break
+			}
+		}
+		if !below {
+			return false
+		}
+	}
+	if rule.MinIssueSeverity != "" || len(rule.IssueTypes) > 0 {
+		if !verdictIssueMatches(rule, issues) {
+			return false
+		}
+	}
+	return true
+}
+
+func verdictIssueMatches(rule models.VerdictRule, issues []models.IssueDetected) bool {
+	types := make(map[string]bool, len(rule.IssueTypes))
+	for _, issueType := range rule.IssueTypes {
+		types[issueType] = true
+	}
+	for _, issue := range issues {
+		if rule.MinIssueSeverity != "" && severityRank[issue.Severity] < severityRank[rule.MinIssueSeverity] {
+			continue
+		}
+		if len(types) > 0 && !types[issue.Type] {
+			continue
+		}
+		return true
+	}
+	return false
+}
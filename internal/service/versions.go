@@ -0,0 +1,126 @@
+package services
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Version matrix dimensions
+const (
+	VersionDimensionAgent = "agent_version"
+	VersionDimensionModel = "model"
+	VersionDimensionTool  = "tool_version"
+)
+
+// VersionEffects ranks version dimensions by how much the average overall
+// score varies between their values, largest spread first. Tool versions are
+// compared per tool. Values with fewer than minEvaluations evaluations are
+// ignored, and dimensions left with a single value have no effect.
+func VersionEffects(marginals []models.VersionMarginal, minEvaluations int) []models.VersionEffect {
+	type key struct{ dimension, tool string }
+	groups := make(map[key][]models.VersionMarginal)
+	var order []key
+	for _, marginal := range marginals {
+		if marginal.Evaluations < minEvaluations {
+			continue
+		}
+		k := key{marginal.Dimension, marginal.Tool}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], marginal)
+	}
+
+	effects := []models.VersionEffect{}
+	for _, k := range order {
+		values := groups[k]
+		if len(values) < 2 {
+			continue
+		}
+
+		best, worst := values[0], values[0]
+		for _, value := range values[1:] {
+			if value.AvgOverallScore > best.AvgOverallScore {
+				best = value
+			}
+			if value.AvgOverallScore < worst.AvgOverallScore {
+				worst = value
+			}
+		}
+		effects = append(effects, models.VersionEffect{
+			Dimension: k.dimension,
+			Tool:      k.tool,
+			Spread:    math.Round((best.AvgOverallScore-worst.AvgOverallScore)*1000) / 1000,
+			Best:      best.Value,
+			Worst:     worst.Value,
+			Values:    len(values),
+		})
+	}
+
+	sort.SliceStable(effects, func(i, j int) bool {
+		return effects[i].Spread > effects[j].Spread
+	})
+	return effects
+}
+
+// DefaultVersionComparisonAlpha is the p-value below which a difference
+// between agent versions counts as significant
+const DefaultVersionComparisonAlpha = 0.05
+
+// CompareVersionScores compares the scores of agent version b to those of a
+// for every metric either version has. A difference is significant only when
+// both Welch's t-test and the Mann-Whitney U test reject at alpha, so skewed
+// or bounded scores don't mislead the t-test alone. Metrics are ordered by
+// name, with overall first.
+func CompareVersionScores(a, b map[string][]float64, alpha float64) []models.VersionMetricComparison {
+	metrics := make([]string, 0, len(a)+len(b))
+	seen := make(map[string]bool, len(a)+len(b))
+	for _, scores := range []map[string][]float64{a, b} {
+		for metric := range scores {
+			if !seen[metric] {
+				seen[metric] = true
+				metrics = append(metrics, metric)
+			}
+		}
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if (metrics[i] == "overall") != (metrics[j] == "overall") {
+			return metrics[i] == "overall"
+		}
+		return metrics[i] < metrics[j]
+	})
+
+	comparisons := make([]models.VersionMetricComparison, 0, len(metrics))
+	for _, metric := range metrics {
+		scoresA, scoresB := a[metric], b[metric]
+		comparison := models.VersionMetricComparison{
+			Metric: metric,
+			CountA: len(scoresA),
+			CountB: len(scoresB),
+		}
+		comparison.MeanA, comparison.StdDevA = meanStdDev(scoresA)
+		comparison.MeanB, comparison.StdDevB = meanStdDev(scoresB)
+		if len(scoresA) > 0 && len(scoresB) > 0 {
+			comparison.Delta = comparison.MeanB - comparison.MeanA
+		}
+
+		if t, p, ok := welchTTest(scoresA, scoresB); ok {
+			comparison.TTest = &models.StatTest{Statistic: t, PValue: p}
+		}
+		if u, p, ok := mannWhitneyU(scoresA, scoresB); ok && len(scoresA) >= 2 && len(scoresB) >= 2 {
+			comparison.MannWhitney = &models.StatTest{Statistic: u, PValue: p}
+		}
+		if comparison.TTest != nil && comparison.MannWhitney != nil &&
+			comparison.TTest.PValue < alpha && comparison.MannWhitney.PValue < alpha {
+			comparison.Significant = true
+			comparison.Better = "version_b"
+			if comparison.Delta < 0 {
+				comparison.Better = "version_a"
+			}
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	return comparisons
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// DefaultViewCollapseBytes is how long a payload may be before the viewer
+// collapses it to a preview
+const DefaultViewCollapseBytes = 2048
+
+// Payloads that an expansion token can refer to
+const (
+	viewPayloadTurnResult = "result"
+	viewPayloadParameters = "parameters"
+	viewPayloadCallResult = "call_result"
+)
+
+// BuildConversationView prepares turns for display: content has raw HTML
+// escaped, tool payloads are pretty-printed and collapsed past collapseBytes,
+// and issues are marked on the turns they refer to
+func BuildConversationView(conv *models.Conversation, turns []models.Turn, eval *models.Evaluation, collapseBytes int) (*models.ConversationView, error) {
+	view := &models.ConversationView{
+		ConversationID: conv.ConversationID,
+		AgentVersion:   conv.AgentVersion,
+		Turns:          make([]models.ViewTurn, 0, len(turns)),
+		Issues:         []models.ViewIssue{},
+	}
+
+	byTurn := make(map[int]int, len(turns))
+	for i, turn := range turns {
+		viewTurn := models.ViewTurn{
+			TurnID:      turn.TurnID,
+			Role:        turn.Role,
+			Content:     html.EscapeString(turn.Content),
+			ToolCallID:  turn.ToolCallID,
+			Name:        turn.Name,
+			Attachments: turn.Attachments,
+			Issues:      []models.ViewIssue{},
+			Timestamp:   turn.Timestamp,
+		}
+		if turn.Result != nil {
+			viewTurn.Result = viewPayload(turn.Result, collapseBytes, turn.TurnID, viewPayloadTurnResult, 0)
+		}
+		for j, call := range turn.ToolCalls {
+			viewCall := models.ViewToolCall{
+				ID:        call.ID,
+				ToolName:  call.ToolName,
+				LatencyMS: call.LatencyMS,
+			}
+			if call.Parameters != nil {
+				viewCall.Parameters = viewPayload(call.Parameters, collapseBytes, turn.TurnID, viewPayloadParameters, j)
+			}
+			if call.Result != nil {
+				viewCall.Result = viewPayload(call.Result, collapseBytes, turn.TurnID, viewPayloadCallResult, j)
+			}
+			viewTurn.ToolCalls = append(viewTurn.ToolCalls, viewCall)
+		}
+
+		view.Turns = append(view.Turns, viewTurn)
+		byTurn[turn.TurnID] = i
+	}
+
+	if eval == nil {
+		return view, nil
+	}
+	view.EvaluationID = eval.EvaluationID
+
+	var issues []models.IssueDetected
+	if len(eval.IssuesDetected) > 0 {
+		if err := json.Unmarshal(eval.IssuesDetected, &issues); err != nil {
+			return nil, fmt.Errorf("failed to parse issues: %w", err)
+		}
+	}
+	for _, issue := range issues {
+		marker := models.ViewIssue{
+			Type:        issue.Type,
+			Severity:    issue.Severity,
+			Description: issue.Description,
+		}
+		if i, ok := byTurn[issue.TurnID]; ok && issue.TurnID != 0 {
+			view.Turns[i].Issues = append(view.Turns[i].Issues, marker)
+		} else {
+			view.Issues = append(view.Issues, marker)
+		}
+	}
+
+	return view, nil
+}
+
+// ExpandViewPayload returns the full text of the payload an expansion token
+// refers to. It returns nil if the payload doesn't exist.
+func ExpandViewPayload(turns []models.Turn, token string) (*models.ViewPayload, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expansion token")
+	}
+	parts := strings.Split(string(decoded), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid expansion token")
+	}
+	turnID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid expansion token")
+	}
+	call, err := strconv.Atoi(parts[2])
+	if err != nil || call < 0 {
+		return nil, fmt.Errorf("invalid expansion token")
+	}
+
+	for _, turn := range turns {
+		if turn.TurnID != turnID {
+			continue
+		}
+		var payload map[string]interface{}
+		switch parts[1] {
+		case viewPayloadTurnResult:
+			payload = turn.Result
+		case viewPayloadParameters, viewPayloadCallResult:
+			if call >= len(turn.ToolCalls) {
+				return nil, nil
+			}
+			payload = turn.ToolCalls[call].Parameters
+			if parts[1] == viewPayloadCallResult {
+				payload = turn.ToolCalls[call].Result
+			}
+		default:
+			return nil, fmt.Errorf("invalid expansion token")
+		}
+		if payload == nil {
+			return nil, nil
+		}
+		return viewPayload(payload, 0, turnID, parts[1], call), nil
+	}
+
+	return nil, nil
+}
+
+// viewPayload pretty-prints a payload, collapsing it to a preview when it is
+// longer than collapseBytes. A collapseBytes of 0 never collapses.
+func viewPayload(payload map[string]interface{}, collapseBytes, turnID int, kind string, call int) *models.ViewPayload {
+	pretty, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		// Payloads were decoded from JSON, so this only guards against NaNs
+		pretty = []byte(fmt.Sprint(payload))
+	}
+
+	text := string(pretty)
+	view := &models.ViewPayload{
+		Language: "json",
+		Text:     text,
+		Bytes:    len(text),
+	}
+	if collapseBytes <= 0 || len(text) <= collapseBytes {
+		return view
+	}
+
+	// Cut at the last line that fits, or mid-line at a rune boundary when
+	// the first line is already too long
+	preview := text[:collapseBytes]
+	if cut := strings.LastIndexByte(preview, '\n'); cut > 0 {
+		preview = preview[:cut]
+	} else {
+		for len(preview) > 0 && !utf8.ValidString(preview) {
+			preview = preview[:len(preview)-1]
+		}
+	}
+
+	view.Text = preview + "\n…"
+	view.Collapsed = true
+	view.ExpansionToken = base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d.%s.%d", turnID, kind, call)))
+	return view
+}
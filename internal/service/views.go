@@ -0,0 +1,79 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Saved view sort fields
+const (
+	ViewSortCreatedAt = "created_at"
+	ViewSortUpdatedAt = "updated_at"
+	ViewSortScore     = "score"
+)
+
+// viewSortFields lists the fields each saved view resource can be sorted by
+var viewSortFields = map[string][]string{
+	models.ViewResourceConversations: {ViewSortCreatedAt, ViewSortUpdatedAt, ViewSortScore},
+	models.ViewResourceEvaluations:   {ViewSortCreatedAt, ViewSortScore},
+}
+
+// NormalizeViewFilters fills the default sort, newest first, and checks
+// that the filters apply to the view's resource
+func NormalizeViewFilters(resource string, filters *models.ViewFilters) error {
+	sortFields, ok := viewSortFields[resource]
+	if !ok {
+		return fmt.Errorf("unknown resource %q", resource)
+	}
+
+	if filters.SortBy == "" {
+		filters.SortBy = ViewSortCreatedAt
+	}
+	if !containsString(sortFields, filters.SortBy) {
+		return fmt.Errorf("%s cannot be sorted by %q: expected one of %s", resource, filters.SortBy, strings.Join(sortFields, ", "))
+	}
+	if filters.SortOrder == "" {
+		filters.SortOrder = "desc"
+	}
+
+	if resource == models.ViewResourceConversations && filters.TriggerSource != "" {
+		return fmt.Errorf("trigger_source only filters evaluations")
+	}
+	for _, score := range []*float64{filters.MinScore, filters.MaxScore} {
+		if score != nil && (*score < 0 || *score > 1) {
+			return fmt.Errorf("scores must be between 0 and 1")
+		}
+	}
+	if filters.MinScore != nil && filters.MaxScore != nil && *filters.MinScore > *filters.MaxScore {
+		return fmt.Errorf("min_score must not be above max_score")
+	}
+	if filters.WithinDays < 0 {
+		return fmt.Errorf("within_days must not be negative")
+	}
+
+	return nil
+}
+
+// ParseViewFilters reads the stored filters of a saved view
+func ParseViewFilters(raw json.RawMessage) (models.ViewFilters, error) {
+	var filters models.ViewFilters
+	if len(raw) == 0 {
+		return filters, nil
+	}
+	if err := json.Unmarshal(raw, &filters); err != nil {
+		return filters, fmt.Errorf("invalid view filters: %w", err)
+	}
+	return filters, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,354 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/repository"
+	services "github.com/ai-agent-eval/internal/service"
+)
+
+// maxListedIssues is how many critical issues a message lists before
+// summarizing the rest
+const maxListedIssues = 5
+
+// Thresholds decide which failure patterns are announced
+type Thresholds struct {
+	MinSeverity    string
+	MinOccurrences int
+}
+
+// Notifier posts critical evaluations and recurring failure patterns to a
+// Slack incoming webhook
+type Notifier struct {
+	webhookURL  string
+	linkBaseURL string // Links in messages point at this API
+	thresholds  Thresholds
+	repo        *repository.Repository
+	httpClient  *http.Client
+}
+
+// New creates a notifier posting to the configured Slack webhook. It returns
+// nil when no webhook is configured; a nil notifier posts nothing.
+func New(cfg *config.Config, repo *repository.Repository) *Notifier {
+	if cfg.SlackWebhookURL == "" {
+		return nil
+	}
+	return &Notifier{
+		webhookURL:  cfg.SlackWebhookURL,
+		linkBaseURL: strings.TrimRight(cfg.SlackLinkBaseURL, "/"),
+		thresholds: Thresholds{
+			MinSeverity:    cfg.SlackPatternMinSeverity,
+			MinOccurrences: cfg.SlackPatternMinOccurrences,
+		},
+		repo:       repo,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// message is a Slack message: text is shown in notifications and where
+// blocks can't be rendered
+type message struct {
+	Text   string  `json:"text"`
+	Blocks []block `json:"blocks"`
+}
+
+type block struct {
+	Type string `json:"type"`
+	Text *text  `json:"text,omitempty"`
+}
+
+type text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func section(markdown string) block {
+	return block{Type: "section", Text: &text{Type: "mrkdwn", Text: markdown}}
+}
+
+// NotifyEvaluation posts an evaluation that has critical issues. Evaluations
+// without critical issues are not posted, nor are those of projects whose
+// teams all turned critical Slack alerts off.
+func (n *Notifier) NotifyEvaluation(ctx context.Context, eval *models.Evaluation) error {
+	if n == nil {
+		return nil
+	}
+
+	var issues []models.IssueDetected
+	if len(eval.IssuesDetected) > 0 {
+		if err := json.Unmarshal(eval.IssuesDetected, &issues); err != nil {
+			return fmt.Errorf("failed to parse issues: %w", err)
+		}
+	}
+	critical := []models.IssueDetected{}
+	for _, issue := range issues {
+		if issue.Severity == "critical" {
+			critical = append(critical, issue)
+		}
+	}
+	if len(critical) == 0 {
+		return nil
+	}
+
+	prefs, err := n.repo.ListNotificationPreferences(eval.ProjectID)
+	if err != nil {
+		return err
+	}
+	if post, _ := deliverable(prefs, "critical", time.Now().UTC()); !post {
+		return nil
+	}
+
+	summary := fmt.Sprintf("%d critical issue(s) in conversation %s (project %s)", len(critical), eval.ConversationID, eval.ProjectID)
+	var b strings.Builder
+	fmt.Fprintf(&b, ":rotating_light: *%s*\n", escape(summary))
+	fmt.Fprintf(&b, "Overall score: %.2f\n", eval.OverallScore)
+	for i, issue := range critical {
+		if i == maxListedIssues {
+			fmt.Fprintf(&b, "• …and %d more\n", len(critical)-maxListedIssues)
+			break
+		}
+		fmt.Fprintf(&b, "• `%s` %s", escape(issue.Type), escape(truncate(issue.Description, 200)))
+		if issue.TurnID > 0 {
+			fmt.Fprintf(&b, " (turn %d)", issue.TurnID)
+		}
+		b.WriteString("\n")
+	}
+
+	links := fmt.Sprintf("%s  |  %s",
+		link(n.conversationURL(eval.ConversationID), "Conversation"),
+		link(n.linkBaseURL+"/api/v1/evaluations/"+url.PathEscape(eval.EvaluationID), "Evaluation"))
+
+	return n.post(ctx, message{
+		Text:   summary,
+		Blocks: []block{section(b.String()), section(links)},
+	})
+}
+
+// NotifyPatterns posts unresolved failure patterns that crossed the
+// thresholds since the last run and marks them announced, so each pattern
+// is posted once. Patterns owned by a team are posted as its notification
+// preferences allow: held back during its quiet hours, and marked announced
+// without being posted if it doesn't want them in Slack. It returns how many
+// were posted; failures on individual patterns are logged and retried on
+// the next run.
+func (n *Notifier) NotifyPatterns(ctx context.Context, limit int) (int, error) {
+	if n == nil {
+		return 0, nil
+	}
+
+	patterns, err := n.repo.ListUnannouncedPatterns(services.SeveritiesAtLeast(n.thresholds.MinSeverity), n.thresholds.MinOccurrences, limit)
+	if err != nil {
+		return 0, err
+	}
+	prefs, err := n.repo.ListNotificationPreferences("")
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for i := range patterns {
+		pattern := &patterns[i]
+		now := time.Now().UTC()
+		projects, err := n.repo.ListPatternProjects(pattern)
+		if err != nil {
+			return posted, err
+		}
+		post, later := deliverable(teamPreferences(prefs, projects, pattern.Owner.String), pattern.Severity, now)
+		if !post {
+			if !later {
+				if err := n.repo.MarkPatternAnnounced(pattern.PatternID, now); err != nil {
+					return posted, err
+				}
+			}
+			continue
+		}
+		if err := n.post(ctx, n.patternMessage(pattern)); err != nil {
+			log.Printf("Failed to post pattern %s to Slack: %v", pattern.PatternID, err)
+			continue
+		}
+		if err := n.repo.MarkPatternAnnounced(pattern.PatternID, time.Now().UTC()); err != nil {
+			return posted, err
+		}
+		posted++
+	}
+	return posted, nil
+}
+
+// deliverable reports whether an alert of the given severity may be posted
+// now to the teams with the given preferences, and if not, whether it may be
+// later, once quiet hours are over. Alerts concerning no team with
+// preferences are always posted.
+func deliverable(prefs []models.NotificationPreference, severity string, now time.Time) (post, later bool) {
+	if len(prefs) == 0 {
+		return true, true
+	}
+	for i := range prefs {
+		pref := &prefs[i]
+		if !services.HasChannel(pref, services.ChannelSlack) || !services.AcceptsSeverity(pref, severity) {
+			continue
+		}
+		if services.ShouldNotify(pref, severity, now) {
+			return true, true
+		}
+		later = true
+	}
+	return false, later
+}
+
+// teamPreferences selects the preferences a team set in the given projects.
+// Teams of the same name in other projects are different teams. An empty
+// team has none.
+func teamPreferences(prefs []models.NotificationPreference, projects []string, team string) []models.NotificationPreference {
+	var selected []models.NotificationPreference
+	if team == "" {
+		return selected
+	}
+	for _, pref := range prefs {
+		if pref.Team != team {
+			continue
+		}
+		for _, project := range projects {
+			if pref.ProjectID == project {
+				selected = append(selected, pref)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// NotifyAlert posts an alert addressed to Slack, by a team's preferences or
+// to no team in particular
+func (n *Notifier) NotifyAlert(ctx context.Context, delivery *services.AlertDelivery) error {
+	if n == nil {
+		return nil
+	}
+
+	summary := delivery.Message
+	if delivery.Team != "" {
+		summary = fmt.Sprintf("%s (team %s, project %s)", summary, delivery.Team, delivery.ProjectID)
+	}
+	return n.post(ctx, message{
+		Text:   summary,
+		Blocks: []block{section(fmt.Sprintf(":bell: *%s alert* %s", escape(delivery.Severity), escape(summary)))},
+	})
+}
+
+// NotifyDigest posts a team's digest of the issues and failure patterns it
+// owns
+func (n *Notifier) NotifyDigest(ctx context.Context, digest *models.OwnerDigest) error {
+	if n == nil {
+		return nil
+	}
+
+	summary := fmt.Sprintf("Digest for team %s (project %s): %d issue(s), %d unresolved failure pattern(s) since %s",
+		digest.Team, digest.ProjectID, digest.IssueCount, len(digest.UnresolvedPatterns), digest.Since.Format(time.RFC3339))
+	var b strings.Builder
+	fmt.Fprintf(&b, ":memo: *%s*\n", escape(summary))
+	for _, severity := range []string{"critical", "high", "medium", "low"} {
+		if count := digest.SeverityCounts[severity]; count > 0 {
+			fmt.Fprintf(&b, "• %s: %d\n", severity, count)
+		}
+	}
+	for i, pattern := range digest.UnresolvedPatterns {
+		if i == maxListedIssues {
+			fmt.Fprintf(&b, "• …and %d more patterns\n", len(digest.UnresolvedPatterns)-maxListedIssues)
+			break
+		}
+		fmt.Fprintf(&b, "• `%s` (%s, %d occurrences) %s\n", escape(pattern.PatternType), escape(pattern.Severity),
+			pattern.OccurrenceCount, escape(truncate(pattern.Description, 200)))
+	}
+
+	return n.post(ctx, message{Text: summary, Blocks: []block{section(b.String())}})
+}
+
+// patternMessage describes a failure pattern, linking its most recent
+// example conversations
+func (n *Notifier) patternMessage(pattern *models.FailurePattern) message {
+	summary := fmt.Sprintf("Failure pattern %s (%s) reached %d occurrences", pattern.PatternType, pattern.Severity, pattern.OccurrenceCount)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, ":warning: *%s*\n", escape(summary))
+	fmt.Fprintf(&b, "%s\n", escape(truncate(pattern.Description, 500)))
+	fmt.Fprintf(&b, "First seen %s, last seen %s", pattern.FirstSeen.Format(time.RFC3339), pattern.LastSeen.Format(time.RFC3339))
+	if pattern.Owner.Valid && pattern.Owner.String != "" {
+		fmt.Fprintf(&b, ", owned by %s", escape(pattern.Owner.String))
+	}
+	blocks := []block{section(b.String())}
+
+	var examples []string
+	json.Unmarshal(pattern.ExampleConversations, &examples)
+	if len(examples) > 0 {
+		if len(examples) > maxListedIssues {
+			examples = examples[len(examples)-maxListedIssues:]
+		}
+		links := make([]string, len(examples))
+		for i, conversationID := range examples {
+			links[i] = link(n.conversationURL(conversationID), conversationID)
+		}
+		blocks = append(blocks, section("Example conversations: "+strings.Join(links, ", ")))
+	}
+
+	return message{Text: summary, Blocks: blocks}
+}
+
+// conversationURL links the rendered view of a conversation
+func (n *Notifier) conversationURL(conversationID string) string {
+	return n.linkBaseURL + "/api/v1/conversations/" + url.PathEscape(conversationID) + "/view"
+}
+
+// post sends a message to the Slack webhook
+func (n *Notifier) post(ctx context.Context, msg message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("slack webhook returned status %d: %s", resp.StatusCode, detail)
+	}
+	return nil
+}
+
+// link formats a Slack link
+func link(target, label string) string {
+	return "<" + target + "|" + escape(label) + ">"
+}
+
+// escape escapes the characters Slack treats as markup
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// truncate shortens s to at most max runes
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,277 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// Metric names
+const (
+	MetricEvaluationScore = "agent_eval.evaluation.score"
+	MetricEvaluations     = "agent_eval.evaluations"
+	MetricIssues          = "agent_eval.issues"
+)
+
+// scopeName is the instrumentation scope metrics are reported under
+const scopeName = "github.com/ai-agent-eval"
+
+// maxPendingScores caps the score points kept while the collector is
+// unreachable; the oldest are dropped first
+const maxPendingScores = 10000
+
+// Exporter pushes completed evaluations to an OpenTelemetry collector as
+// metrics over OTLP/HTTP: each score of each evaluation as a gauge point,
+// and cumulative counts of evaluations and of issues by type and severity.
+// Counts are kept per process, which is told apart by service.instance.id.
+type Exporter struct {
+	url        string
+	headers    map[string]string
+	interval   time.Duration
+	resource   []attribute
+	start      time.Time
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	scores      []dataPoint // Not yet exported
+	evaluations map[string]*count
+	issues      map[string]*count
+}
+
+// count is a cumulative counter of one attribute set
+type count struct {
+	attributes []attribute
+	value      int64
+}
+
+// New creates an exporter pushing to the configured OTLP endpoint. It
+// returns nil when no endpoint is configured; a nil exporter records
+// nothing.
+func New(cfg *config.Config) *Exporter {
+	if cfg.OTelEndpoint == "" {
+		return nil
+	}
+	interval := cfg.OTelExportInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	hostname, _ := os.Hostname()
+	return &Exporter{
+		url:      strings.TrimRight(cfg.OTelEndpoint, "/") + "/v1/metrics",
+		headers:  cfg.OTelHeaders,
+		interval: interval,
+		resource: []attribute{
+			stringAttribute("service.name", cfg.OTelServiceName),
+			stringAttribute("service.instance.id", fmt.Sprintf("%s-%d", hostname, os.Getpid())),
+		},
+		start:       time.Now(),
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		evaluations: make(map[string]*count),
+		issues:      make(map[string]*count),
+	}
+}
+
+// Record adds a stored or completed evaluation to the metrics
+func (e *Exporter) Record(eval *models.Evaluation, agentVersion string) {
+	if e == nil {
+		return
+	}
+
+	var issues []models.IssueDetected
+	json.Unmarshal(eval.IssuesDetected, &issues)
+
+	at := eval.CreatedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	base := []attribute{
+		stringAttribute("agent_version", agentVersion),
+		stringAttribute("project_id", eval.ProjectID),
+	}
+	scores := []struct {
+		component string
+		value     float64
+	}{
+		{"overall", eval.OverallScore},
+		{"response_quality", eval.ResponseQualityScore},
+		{"tool_accuracy", eval.ToolAccuracyScore},
+		{"coherence", eval.CoherenceScore},
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, score := range scores {
+		value := score.value
+		e.scores = append(e.scores, dataPoint{
+			Attributes:   withAttributes(base, stringAttribute("component", score.component)),
+			TimeUnixNano: unixNano(at.UnixNano()),
+			AsDouble:     &value,
+		})
+	}
+	if excess := len(e.scores) - maxPendingScores; excess > 0 {
+		e.scores = e.scores[excess:]
+	}
+
+	increment(e.evaluations, base)
+	for _, issue := range issues {
+		increment(e.issues, withAttributes(base,
+			stringAttribute("type", issue.Type),
+			stringAttribute("severity", issue.Severity)))
+	}
+}
+
+// Run exports the metrics every interval until ctx is cancelled, then
+// exports once more so nothing recorded is left behind
+func (e *Exporter) Run(ctx context.Context) {
+	if e == nil {
+		return
+	}
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := e.Flush(flushCtx); err != nil {
+				log.Printf("Failed to export final metrics: %v", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := e.Flush(ctx); err != nil {
+				log.Printf("Failed to export metrics: %v", err)
+			}
+		}
+	}
+}
+
+// Flush exports the score points recorded since the last export and the
+// current counts. Score points that fail to export are kept for the next.
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	scores := e.scores
+	e.scores = nil
+	now := unixNano(time.Now().UnixNano())
+	evaluations := e.cumulative(e.evaluations, now)
+	issues := e.cumulative(e.issues, now)
+	e.mu.Unlock()
+
+	if len(evaluations) == 0 {
+		return nil // Nothing recorded yet
+	}
+
+	var metrics []metric
+	if len(scores) > 0 {
+		metrics = append(metrics, metric{
+			Name:        MetricEvaluationScore,
+			Description: "Scores of each evaluation, by component",
+			Unit:        "1",
+			Gauge:       &gauge{DataPoints: scores},
+		})
+	}
+	metrics = append(metrics, metric{
+		Name:        MetricEvaluations,
+		Description: "Evaluations stored or completed",
+		Unit:        "{evaluation}",
+		Sum:         &sum{AggregationTemporality: aggregationCumulative, IsMonotonic: true, DataPoints: evaluations},
+	})
+	if len(issues) > 0 {
+		metrics = append(metrics, metric{
+			Name:        MetricIssues,
+			Description: "Issues detected by evaluations",
+			Unit:        "{issue}",
+			Sum:         &sum{AggregationTemporality: aggregationCumulative, IsMonotonic: true, DataPoints: issues},
+		})
+	}
+
+	err := e.post(ctx, exportRequest{ResourceMetrics: []resourceMetrics{{
+		Resource:     resource{Attributes: e.resource},
+		ScopeMetrics: []scopeMetrics{{Scope: scope{Name: scopeName}, Metrics: metrics}},
+	}}})
+	if err != nil {
+		e.mu.Lock()
+		e.scores = append(scores, e.scores...)
+		if excess := len(e.scores) - maxPendingScores; excess > 0 {
+			e.scores = e.scores[excess:]
+		}
+		e.mu.Unlock()
+	}
+	return err
+}
+
+// cumulative returns the data points of counters as of now
+func (e *Exporter) cumulative(counts map[string]*count, now string) []dataPoint {
+	points := make([]dataPoint, 0, len(counts))
+	for _, c := range counts {
+		points = append(points, dataPoint{
+			Attributes:        c.attributes,
+			StartTimeUnixNano: unixNano(e.start.UnixNano()),
+			TimeUnixNano:      now,
+			AsInt:             fmt.Sprint(c.value),
+		})
+	}
+	return points
+}
+
+// post sends an export request to the collector
+func (e *Exporter) post(ctx context.Context, body exportRequest) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("otlp request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("otlp collector returned status %d: %s", resp.StatusCode, detail)
+	}
+	return nil
+}
+
+// increment adds one to the counter of an attribute set
+func increment(counts map[string]*count, attributes []attribute) {
+	values := make([]string, len(attributes))
+	for i, a := range attributes {
+		values[i] = a.Value.StringValue
+	}
+	key := strings.Join(values, "\x00")
+
+	c, ok := counts[key]
+	if !ok {
+		c = &count{attributes: attributes}
+		counts[key] = c
+	}
+	c.value++
+}
+
+// withAttributes returns base with more attributes, without changing base
+func withAttributes(base []attribute, more ...attribute) []attribute {
+	return append(append(make([]attribute, 0, len(base)+len(more)), base...), more...)
+}
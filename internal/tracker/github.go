@@ -0,0 +1,129 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// githubLabel tags issues opened by the pipeline
+const githubLabel = "ai-agent-eval"
+
+// github files failure patterns as GitHub issues in one repository.
+// Issue keys are the issue numbers.
+type github struct {
+	apiURL     string
+	repo       string
+	token      string
+	httpClient *http.Client
+}
+
+func newGitHub(cfg *config.Config) *github {
+	return &github{
+		apiURL:     strings.TrimRight(cfg.GitHubAPIURL, "/"),
+		repo:       cfg.GitHubRepo,
+		token:      cfg.GitHubToken,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (g *github) Provider() string { return ProviderGitHub }
+
+// githubIssue is the part of a GitHub issue the tracker reads
+type githubIssue struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+}
+
+func (i *githubIssue) issue() *Issue {
+	return &Issue{Key: fmt.Sprint(i.Number), URL: i.HTMLURL, Closed: i.State == "closed"}
+}
+
+// Create opens a GitHub issue for a pattern
+func (g *github) Create(ctx context.Context, pattern *models.FailurePattern) (*Issue, error) {
+	payload := map[string]interface{}{
+		"title":  title(pattern),
+		"body":   body(pattern),
+		"labels": []string{githubLabel},
+	}
+
+	var created githubIssue
+	if err := g.do(ctx, http.MethodPost, "/issues", payload, &created); err != nil {
+		return nil, err
+	}
+	return created.issue(), nil
+}
+
+// Update refreshes the title and body of a pattern's issue
+func (g *github) Update(ctx context.Context, key string, pattern *models.FailurePattern) error {
+	payload := map[string]interface{}{
+		"title": title(pattern),
+		"body":  body(pattern),
+	}
+	return g.do(ctx, http.MethodPatch, "/issues/"+key, payload, nil)
+}
+
+// Get fetches an issue
+func (g *github) Get(ctx context.Context, key string) (*Issue, error) {
+	var issue githubIssue
+	if err := g.do(ctx, http.MethodGet, "/issues/"+key, nil, &issue); err != nil {
+		return nil, err
+	}
+	return issue.issue(), nil
+}
+
+// SetClosed closes an issue as completed, or reopens it
+func (g *github) SetClosed(ctx context.Context, key string, closed bool) error {
+	payload := map[string]interface{}{"state": "open"}
+	if closed {
+		payload = map[string]interface{}{"state": "closed", "state_reason": "completed"}
+	}
+	return g.do(ctx, http.MethodPatch, "/issues/"+key, payload, nil)
+}
+
+// do sends a request to the repository's GitHub API and decodes the response into out
+func (g *github) do(ctx context.Context, method, path string, payload, out interface{}) error {
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	url := g.apiURL + "/repos/" + g.repo + path
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("github request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("github %s %s returned status %d: %s", method, path, resp.StatusCode, detail)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
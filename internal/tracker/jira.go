@@ -0,0 +1,160 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/models"
+)
+
+// jiraLabel tags tickets opened by the pipeline
+const jiraLabel = "ai-agent-eval"
+
+// jira files failure patterns as Jira issues through the REST API v2
+type jira struct {
+	baseURL    string
+	email      string
+	token      string
+	projectKey string
+	issueType  string
+	httpClient *http.Client
+}
+
+func newJira(cfg *config.Config) *jira {
+	return &jira{
+		baseURL:    strings.TrimRight(cfg.JiraBaseURL, "/"),
+		email:      cfg.JiraEmail,
+		token:      cfg.JiraAPIToken,
+		projectKey: cfg.JiraProjectKey,
+		issueType:  cfg.JiraIssueType,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (j *jira) Provider() string { return ProviderJira }
+
+// Create opens a Jira issue for a pattern
+func (j *jira) Create(ctx context.Context, pattern *models.FailurePattern) (*Issue, error) {
+	payload := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": j.projectKey},
+			"issuetype":   map[string]string{"name": j.issueType},
+			"summary":     title(pattern),
+			"description": body(pattern),
+			"labels":      []string{jiraLabel},
+		},
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := j.do(ctx, http.MethodPost, "/rest/api/2/issue", payload, &created); err != nil {
+		return nil, err
+	}
+
+	return &Issue{Key: created.Key, URL: j.baseURL + "/browse/" + created.Key}, nil
+}
+
+// Update refreshes the summary and description of a pattern's issue
+func (j *jira) Update(ctx context.Context, key string, pattern *models.FailurePattern) error {
+	payload := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"summary":     title(pattern),
+			"description": body(pattern),
+		},
+	}
+	return j.do(ctx, http.MethodPut, "/rest/api/2/issue/"+key, payload, nil)
+}
+
+// Get fetches an issue. Issues in the "done" status category are closed.
+func (j *jira) Get(ctx context.Context, key string) (*Issue, error) {
+	var issue struct {
+		Fields struct {
+			Status struct {
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
+	if err := j.do(ctx, http.MethodGet, "/rest/api/2/issue/"+key+"?fields=status", nil, &issue); err != nil {
+		return nil, err
+	}
+
+	return &Issue{
+		Key:    key,
+		URL:    j.baseURL + "/browse/" + key,
+		Closed: issue.Fields.Status.StatusCategory.Key == "done",
+	}, nil
+}
+
+// SetClosed moves an issue through the first available transition into, or
+// out of, the "done" status category
+func (j *jira) SetClosed(ctx context.Context, key string, closed bool) error {
+	var transitions struct {
+		Transitions []struct {
+			ID string `json:"id"`
+			To struct {
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := j.do(ctx, http.MethodGet, "/rest/api/2/issue/"+key+"/transitions", nil, &transitions); err != nil {
+		return err
+	}
+
+	for _, t := range transitions.Transitions {
+		done := t.To.StatusCategory.Key == "done"
+		if done == closed {
+			payload := map[string]interface{}{"transition": map[string]string{"id": t.ID}}
+			return j.do(ctx, http.MethodPost, "/rest/api/2/issue/"+key+"/transitions", payload, nil)
+		}
+	}
+	return fmt.Errorf("no transition available to change %s", key)
+}
+
+// do sends a request to the Jira API and decodes the response into out
+func (j *jira) do(ctx context.Context, method, path string, payload, out interface{}) error {
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, j.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(j.email, j.token)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("jira request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("jira %s %s returned status %d: %s", method, path, resp.StatusCode, detail)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
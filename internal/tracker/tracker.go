@@ -0,0 +1,197 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/repository"
+	services "github.com/ai-agent-eval/internal/service"
+)
+
+// Supported issue trackers
+const (
+	ProviderJira   = "jira"
+	ProviderGitHub = "github"
+)
+
+// Issue is a ticket in an external issue tracker
+type Issue struct {
+	Key    string
+	URL    string
+	Closed bool
+}
+
+// Tracker creates and updates tickets for failure patterns
+type Tracker interface {
+	Provider() string
+	Create(ctx context.Context, pattern *models.FailurePattern) (*Issue, error)
+	Update(ctx context.Context, key string, pattern *models.FailurePattern) error
+	Get(ctx context.Context, key string) (*Issue, error)
+	SetClosed(ctx context.Context, key string, closed bool) error
+}
+
+// New creates the tracker selected in the configuration. It returns nil
+// when no tracker is configured.
+func New(cfg *config.Config) (Tracker, error) {
+	switch cfg.TrackerProvider {
+	case "":
+		return nil, nil
+	case ProviderJira:
+		if cfg.JiraBaseURL == "" || cfg.JiraProjectKey == "" {
+			return nil, fmt.Errorf("jira tracker requires JIRA_BASE_URL and JIRA_PROJECT_KEY")
+		}
+		return newJira(cfg), nil
+	case ProviderGitHub:
+		if cfg.GitHubRepo == "" || cfg.GitHubToken == "" {
+			return nil, fmt.Errorf("github tracker requires GITHUB_REPO and GITHUB_TOKEN")
+		}
+		return newGitHub(cfg), nil
+	}
+	return nil, fmt.Errorf("unsupported tracker provider %q", cfg.TrackerProvider)
+}
+
+// Thresholds decide which failure patterns get a ticket
+type Thresholds struct {
+	MinSeverity    string
+	MinOccurrences int
+}
+
+// SyncResult reports what a sync changed
+type SyncResult struct {
+	Created        int `json:"created"`
+	Updated        int `json:"updated"`
+	ClosedRemote   int `json:"closed_remote"`
+	ReopenedRemote int `json:"reopened_remote"`
+	ResolvedLocal  int `json:"resolved_local"`
+}
+
+// Syncer keeps failure patterns and their tickets in step
+type Syncer struct {
+	repo       *repository.Repository
+	tracker    Tracker
+	thresholds Thresholds
+}
+
+// NewSyncer creates a new syncer
+func NewSyncer(repo *repository.Repository, tracker Tracker, thresholds Thresholds) *Syncer {
+	return &Syncer{repo: repo, tracker: tracker, thresholds: thresholds}
+}
+
+// Sync opens tickets for patterns that crossed the thresholds and syncs
+// existing tickets both ways. Resolving a pattern closes its ticket, closing
+// a ticket resolves its pattern, and a resolved pattern that recurs reopens
+// its ticket. Failures on individual patterns are logged and skipped.
+func (s *Syncer) Sync(ctx context.Context, limit int) (*SyncResult, error) {
+	result := &SyncResult{}
+
+	untracked, err := s.repo.ListUntrackedPatterns(services.SeveritiesAtLeast(s.thresholds.MinSeverity), s.thresholds.MinOccurrences, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range untracked {
+		pattern := &untracked[i]
+		issue, err := s.tracker.Create(ctx, pattern)
+		if err != nil {
+			log.Printf("Failed to create %s issue for pattern %s: %v", s.tracker.Provider(), pattern.PatternID, err)
+			continue
+		}
+		if err := s.repo.SetPatternExternalIssue(pattern.PatternID, s.tracker.Provider(), issue.Key, issue.URL, time.Now().UTC()); err != nil {
+			return result, err
+		}
+		result.Created++
+	}
+
+	tracked, err := s.repo.ListTrackedPatterns(s.tracker.Provider())
+	if err != nil {
+		return result, err
+	}
+	for i := range tracked {
+		if err := s.syncPattern(ctx, &tracked[i], result); err != nil {
+			log.Printf("Failed to sync pattern %s with %s: %v", tracked[i].PatternID, tracked[i].ExternalIssueKey.String, err)
+		}
+	}
+
+	return result, nil
+}
+
+// syncPattern reconciles one pattern with its ticket
+func (s *Syncer) syncPattern(ctx context.Context, pattern *models.FailurePattern, result *SyncResult) error {
+	key := pattern.ExternalIssueKey.String
+	issue, err := s.tracker.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	changed := !pattern.ExternalSyncedAt.Valid || pattern.UpdatedAt.After(pattern.ExternalSyncedAt.Time)
+	switch {
+	case pattern.Resolved && !issue.Closed:
+		if err := s.tracker.SetClosed(ctx, key, true); err != nil {
+			return err
+		}
+		result.ClosedRemote++
+	case !pattern.Resolved && issue.Closed && changed:
+		// The pattern changed after the ticket was closed, so it recurred
+		if err := s.tracker.Update(ctx, key, pattern); err != nil {
+			return err
+		}
+		if err := s.tracker.SetClosed(ctx, key, false); err != nil {
+			return err
+		}
+		result.ReopenedRemote++
+	case !pattern.Resolved && issue.Closed:
+		if _, err := s.repo.SetFailurePatternResolved(pattern.PatternID, true, "Resolved in "+key); err != nil {
+			return err
+		}
+		result.ResolvedLocal++
+	case changed:
+		if err := s.tracker.Update(ctx, key, pattern); err != nil {
+			return err
+		}
+		result.Updated++
+	default:
+		return nil
+	}
+
+	return s.repo.MarkPatternSynced(pattern.PatternID, time.Now().UTC())
+}
+
+// title is the ticket title of a pattern
+func title(pattern *models.FailurePattern) string {
+	return fmt.Sprintf("[%s] %s: %s", pattern.Severity, pattern.PatternType, truncate(pattern.Description, 180))
+}
+
+// body is the ticket description of a pattern
+func body(pattern *models.FailurePattern) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Failure pattern %s detected by the agent evaluation pipeline.\n\n", pattern.PatternID)
+	fmt.Fprintf(&b, "Type: %s\n", pattern.PatternType)
+	fmt.Fprintf(&b, "Severity: %s\n", pattern.Severity)
+	fmt.Fprintf(&b, "Occurrences: %d\n", pattern.OccurrenceCount)
+	fmt.Fprintf(&b, "First seen: %s\n", pattern.FirstSeen.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Last seen: %s\n", pattern.LastSeen.Format(time.RFC3339))
+	if pattern.Owner.Valid && pattern.Owner.String != "" {
+		fmt.Fprintf(&b, "Owner: %s\n", pattern.Owner.String)
+	}
+	if len(pattern.AffectedVersions) > 0 {
+		fmt.Fprintf(&b, "Affected versions: %s\n", pattern.AffectedVersions)
+	}
+	if len(pattern.ExampleConversations) > 0 {
+		fmt.Fprintf(&b, "Example conversations: %s\n", pattern.ExampleConversations)
+	}
+	fmt.Fprintf(&b, "\n%s\n", pattern.Description)
+	return b.String()
+}
+
+// truncate shortens s to at most max runes
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
@@ -0,0 +1,305 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+	"github.com/ai-agent-eval/internal/repository"
+)
+
+// templateFuncs are available inside payload templates
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// defaultTemplate sends the full evaluation response
+const defaultTemplate = `{{json .}}`
+
+// ParseTemplate parses a payload template, falling back to the default template
+func ParseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = defaultTemplate
+	}
+	tmpl, err := template.New("payload").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Render renders a webhook payload for an evaluation
+func Render(hook *models.ProjectWebhook, resp *models.EvaluationResponse) ([]byte, error) {
+	tmpl, err := ParseTemplate(hook.PayloadTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, resp); err != nil {
+		return nil, fmt.Errorf("failed to render payload: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Matches reports whether an evaluation passes a webhook's event filter
+func Matches(hook *models.ProjectWebhook, resp *models.EvaluationResponse) bool {
+	_, ok := Event(hook, resp)
+	return ok
+}
+
+// Event returns the event an evaluation is delivered to a webhook as, the
+// most specific one the webhook subscribes to, and whether the evaluation
+// passes the webhook's event filter at all
+func Event(hook *models.ProjectWebhook, resp *models.EvaluationResponse) (string, bool) {
+	var filter models.WebhookFilter
+	if len(hook.Filter) > 0 {
+		if err := json.Unmarshal(hook.Filter, &filter); err != nil {
+			return "", false
+		}
+	}
+
+	if filter.MaxScore != nil && resp.Scores.Overall >= *filter.MaxScore {
+		return "", false
+	}
+
+	critical := false
+	for _, issue := range resp.IssuesDetected {
+		if issue.Severity == "critical" {
+			critical = true
+			break
+		}
+	}
+	if filter.CriticalOnly && !critical {
+		return "", false
+	}
+
+	events := []string{models.WebhookEventEvaluationCompleted}
+	if critical {
+		events = []string{models.WebhookEventCriticalIssue, models.WebhookEventEvaluationCompleted}
+	}
+	if len(filter.Events) == 0 {
+		return events[0], true
+	}
+	for _, event := range events {
+		for _, subscribed := range filter.Events {
+			if event == subscribed {
+				return event, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Sign returns the signature of a delivery: the hex HMAC-SHA256, keyed by
+// the webhook's secret, of its timestamp and payload joined by a dot.
+// Receivers recompute it to check a delivery came from us, and reject old
+// timestamps to stop replays.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliveryLease is how long a claimed delivery is left to its sender before
+// it is due again
+const deliveryLease = time.Minute
+
+// deliveryBatchSize caps the deliveries sent per run
+const deliveryBatchSize = 100
+
+// Dispatcher delivers evaluation results to project webhooks. Deliveries
+// are stored and sent asynchronously by Deliver, so they survive restarts
+// and are retried with backoff until they succeed or run out of attempts.
+type Dispatcher struct {
+	repo       *repository.Repository
+	retry      queue.RetryPolicy
+	httpClient *http.Client
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(cfg *config.Config, repo *repository.Repository) *Dispatcher {
+	return &Dispatcher{
+		repo: repo,
+		retry: queue.RetryPolicy{
+			MaxAttempts: cfg.WebhookMaxAttempts,
+			BaseDelay:   cfg.WebhookRetryBaseDelay,
+			MaxDelay:    cfg.WebhookRetryMaxDelay,
+		},
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Dispatch queues a delivery of an evaluation to every matching webhook of
+// its project
+func (d *Dispatcher) Dispatch(eval *models.Evaluation) error {
+	projectID := eval.ProjectID
+	if projectID == "" {
+		projectID = models.DefaultProjectID
+	}
+	hooks, err := d.repo.ListProjectWebhooks(projectID, true)
+	if err != nil {
+		return err
+	}
+
+	resp := models.NewEvaluationResponse(eval)
+	var deliveries []models.WebhookDelivery
+	for i := range hooks {
+		hook := &hooks[i]
+		event, ok := Event(hook, resp)
+		if !ok {
+			continue
+		}
+
+		payload, err := Render(hook, resp)
+		if err != nil {
+			log.Printf("Webhook %d: %v", hook.ID, err)
+			continue
+		}
+
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:    hook.ID,
+			Event:        event,
+			EvaluationID: eval.EvaluationID,
+			Payload:      string(payload),
+		})
+	}
+
+	return d.repo.CreateWebhookDeliveries(deliveries)
+}
+
+// DispatchNotification queues a delivery of an alert or digest to every
+// enabled webhook of a project that lists the event in its filter. The
+// payload is sent as JSON; payload templates apply to evaluations only. It
+// returns how many deliveries were queued.
+func (d *Dispatcher) DispatchNotification(projectID, event string, payload interface{}) (int, error) {
+	hooks, err := d.repo.ListProjectWebhooks(projectID, true)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	var deliveries []models.WebhookDelivery
+	for i := range hooks {
+		if !subscribes(&hooks[i], event) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID: hooks[i].ID,
+			Event:     event,
+			Payload:   string(data),
+		})
+	}
+
+	return len(deliveries), d.repo.CreateWebhookDeliveries(deliveries)
+}
+
+// subscribes reports whether a webhook's filter lists an event
+func subscribes(hook *models.ProjectWebhook, event string) bool {
+	var filter models.WebhookFilter
+	if len(hook.Filter) > 0 {
+		if err := json.Unmarshal(hook.Filter, &filter); err != nil {
+			return false
+		}
+	}
+	for _, subscribed := range filter.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Deliver sends the deliveries that are due and records their outcomes,
+// returning how many were delivered and how many were given up on
+func (d *Dispatcher) Deliver(ctx context.Context) (delivered, failed int, err error) {
+	pending, err := d.repo.ClaimWebhookDeliveries(deliveryBatchSize, deliveryLease)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for i := range pending {
+		if ctx.Err() != nil {
+			break // Claimed deliveries are retried once their lease ends
+		}
+
+		delivery := &pending[i]
+		responseStatus, sendErr := d.send(ctx, delivery)
+
+		status, lastError, next := models.WebhookDeliveryDelivered, "", time.Now().UTC()
+		if sendErr != nil {
+			status, lastError = models.WebhookDeliveryPending, sendErr.Error()
+			if delivery.Attempts >= d.retry.MaxAttempts {
+				status = models.WebhookDeliveryFailed
+			} else {
+				next = next.Add(d.retry.Backoff(delivery.Attempts))
+			}
+		}
+		if err := d.repo.RecordWebhookDeliveryAttempt(delivery.ID, status, responseStatus, lastError, next); err != nil {
+			return delivered, failed, err
+		}
+
+		switch status {
+		case models.WebhookDeliveryDelivered:
+			delivered++
+		case models.WebhookDeliveryFailed:
+			failed++
+			log.Printf("Webhook %d delivery %d failed after %d attempts: %s", delivery.WebhookID, delivery.ID, delivery.Attempts, lastError)
+		}
+	}
+
+	return delivered, failed, nil
+}
+
+// send posts a signed delivery to its webhook URL, returning the response
+// status if there was a response
+func (d *Dispatcher) send(ctx context.Context, delivery *models.PendingWebhookDelivery) (*int, error) {
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", Sign(delivery.Secret, timestamp, payload))
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 300 {
+		return &resp.StatusCode, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return &resp.StatusCode, nil
+}
@@ -0,0 +1,654 @@
+// Package worker consumes evaluation tasks queued by the API: it evaluates
+// each task's conversation with the evaluator service and stores the result.
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-eval/internal/config"
+	"github.com/ai-agent-eval/internal/database"
+	"github.com/ai-agent-eval/internal/faults"
+	"github.com/ai-agent-eval/internal/models"
+	"github.com/ai-agent-eval/internal/queue"
+	"github.com/ai-agent-eval/internal/repository"
+	services "github.com/ai-agent-eval/internal/service"
+	"github.com/ai-agent-eval/internal/slack"
+	"github.com/ai-agent-eval/internal/telemetry"
+	"github.com/ai-agent-eval/internal/webhook"
+	"github.com/google/uuid"
+)
+
+// pollInterval is how often a consumer with nothing to dequeue checks again
+const pollInterval = 250 * time.Millisecond
+
+// dequeueRetryDelay is how long a consumer waits after the queue fails
+const dequeueRetryDelay = time.Second
+
+// Evaluator warm-up: the service is probed until healthy, waiting up to
+// maxProbeDelay between probes
+const (
+	initialProbeDelay = time.Second
+	maxProbeDelay     = 30 * time.Second
+)
+
+// promoteInterval is how often delayed tasks that are due, retries and
+// scheduled tasks alike, are queued
+const promoteInterval = time.Second
+
+// idleConsumerDelay is how often a consumer above the ramped concurrency
+// checks whether it may start
+const idleConsumerDelay = time.Second
+
+// maintenanceCheckInterval is how long a worker may take to notice that
+// maintenance mode was turned on or off
+const maintenanceCheckInterval = 2 * time.Second
+
+// recoverInterval is how often the task journal is checked for tasks whose
+// worker stopped mid-task
+const recoverInterval = time.Minute
+
+// Worker evaluates tasks from the evaluations queue. Every dequeued task is
+// journaled in Postgres, and a task's evaluation is stored in the same
+// transaction that completes its journal entry, so a task that is delivered
+// again after a crash or a recovery never stores a second evaluation.
+type Worker struct {
+	id           string // Identifies this worker in the task journal
+	repo         *repository.Repository
+	queue        *queue.RedisQueue
+	evaluatorSvc *services.EvaluatorService
+	pipeline     *services.ConfigStore
+	databaseURL  string // Listened on for configuration changes
+	concurrency  int
+	ramp         services.RampPolicy
+	retry        queue.RetryPolicy
+	recoverAfter time.Duration
+	fairness     services.FairnessPolicy
+	fair         *services.FairScheduler
+	webhooks     *webhook.Dispatcher
+	slack        *slack.Notifier         // Nil when Slack isn't configured
+	metrics      *telemetry.Exporter     // Nil unless metrics are exported
+	judgeModel   string                  // Recorded in evaluation manifests
+	limit        *services.AdaptiveLimit // Set by Run once the evaluator is warm
+	maintenance  *queue.MaintenanceCache
+}
+
+// New creates a worker. Evaluations are signed and evaluator exchanges
+// recorded with the same settings as the API.
+func New(cfg *config.Config, db *database.DB, redisQueue *queue.RedisQueue) *Worker {
+	repo := repository.New(db)
+
+	signer, err := services.NewSigner(cfg.SigningAlgorithm, cfg.SigningKey)
+	if err != nil {
+		log.Printf("Evaluation signing disabled: %v", err)
+	}
+	repo.SetSigner(signer)
+
+	tools := services.NewToolRegistry(cfg.ToolLatencySLAs, cfg.LatencyThresholdMS)
+	pipeline := services.NewConfigStore(cfg, tools)
+	repo.SetVerdictPolicy(func() models.VerdictPolicy { return pipeline.Get().Verdicts })
+	repo.SetTokenPrices(services.TokenPrices{Input: cfg.ModelTokenPrices, Output: cfg.ModelOutputTokenPrices})
+	evaluatorSvc := services.NewEvaluatorService(cfg.EvaluatorServiceURL, tools)
+	evaluatorSvc.SetSegmentPolicy(services.SegmentPolicy{
+		MaxTurns:     cfg.SegmentMaxTurns,
+		OverlapTurns: cfg.SegmentOverlapTurns,
+		MaxTokens:    cfg.SegmentMaxTokens,
+		Tokenizer:    services.TokenizerFor(cfg.LLMModel),
+	})
+	evaluatorSvc.SetRecorder(repo, cfg.EvaluatorRecordSampleRate)
+	evaluatorSvc.SetAttachmentBaseURL(cfg.AttachmentBaseURL)
+
+	concurrency := cfg.WorkerConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &Worker{
+		id:           fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		repo:         repo,
+		queue:        redisQueue,
+		evaluatorSvc: evaluatorSvc,
+		pipeline:     pipeline,
+		databaseURL:  cfg.DatabaseURL,
+		concurrency:  concurrency,
+		ramp: services.RampPolicy{
+			Start:        1,
+			Step:         1,
+			MaxErrorRate: cfg.DispatchErrorRateThreshold,
+			Cooldown:     cfg.DispatchBackoffCooldown,
+		},
+		retry: queue.RetryPolicy{
+			MaxAttempts: cfg.TaskMaxAttempts,
+			BaseDelay:   cfg.TaskRetryBaseDelay,
+			MaxDelay:    cfg.TaskRetryMaxDelay,
+		},
+		recoverAfter: cfg.TaskRecoverAfter,
+		fairness:     services.NewFairnessPolicy(cfg),
+		fair:         services.NewFairScheduler(services.NewFairnessPolicy(cfg)),
+		judgeModel:   cfg.LLMModel,
+		webhooks:     webhook.NewDispatcher(cfg, repo),
+		slack:        slack.New(cfg, repo),
+		maintenance:  queue.NewMaintenanceCache(redisQueue, maintenanceCheckInterval),
+	}
+}
+
+// SetFaults injects faults into the worker's evaluator calls
+func (w *Worker) SetFaults(injector *faults.Injector) {
+	w.evaluatorSvc.SetFaults(injector)
+}
+
+// SetMetrics exports the worker's evaluations as metrics
+func (w *Worker) SetMetrics(metrics *telemetry.Exporter) {
+	w.metrics = metrics
+}
+
+// Run consumes tasks until ctx is cancelled. Once the evaluator service is
+// healthy, consumers start one at a time up to the configured concurrency,
+// or the service's advertised capacity if lower, and are cut back while the
+// evaluator error rate is elevated. Failed tasks are retried after a
+// backoff until they are dead-lettered. Tasks interrupted by the
+// cancellation are queued again, and tasks left unfinished by workers that
+// stopped without doing so are recovered from the task journal. Nothing is
+// taken or recovered while the API is in maintenance mode.
+func (w *Worker) Run(ctx context.Context) error {
+	w.reloadConfig()
+	go func() {
+		// The API publishes the config scope when the configuration changes
+		handler := func(scope string) {
+			if scope == "" || scope == "config" {
+				w.reloadConfig()
+			}
+		}
+		if err := database.Listen(ctx, w.databaseURL, database.InvalidationChannel, handler); err != nil {
+			log.Printf("Configuration listener stopped: %v", err)
+		}
+	}()
+
+	maxConcurrency, err := w.warmUp(ctx)
+	if err != nil {
+		return nil // Cancelled while waiting for the evaluator
+	}
+	ramp := w.ramp
+	ramp.Max = float64(maxConcurrency)
+	w.limit = services.NewAdaptiveLimit(ramp)
+
+	log.Printf("Worker consuming %s with concurrency up to %d", queue.QueueEvaluations, maxConcurrency)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		w.promoteDelayed(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		w.recoverTasks(ctx)
+	}()
+	for i := 0; i < maxConcurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w.consume(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// reloadConfig loads the pipeline configuration, including the golden tool
+// examples and the verdict policy, from the latest stored configuration
+// bundle
+func (w *Worker) reloadConfig() {
+	bundle, err := w.repo.GetLatestConfigBundle()
+	if err != nil {
+		log.Printf("Failed to load pipeline configuration: %v", err)
+		return
+	}
+	if bundle != nil {
+		w.pipeline.Set(bundle.Config)
+	}
+}
+
+// warmUp waits for the evaluator service to report healthy, then opens
+// connections to it. It returns the concurrency to ramp up to.
+func (w *Worker) warmUp(ctx context.Context) (int, error) {
+	delay := initialProbeDelay
+	for {
+		health, err := w.evaluatorSvc.Probe(ctx)
+		if err == nil {
+			concurrency := w.concurrency
+			if health.MaxConcurrency > 0 && health.MaxConcurrency < concurrency {
+				concurrency = health.MaxConcurrency
+			}
+			if err := w.evaluatorSvc.Warm(ctx, concurrency); err != nil {
+				log.Printf("Failed to warm evaluator connections: %v", err)
+			}
+			return concurrency, nil
+		}
+
+		log.Printf("Waiting %s for the evaluator service: %v", delay, err)
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxProbeDelay {
+			delay = maxProbeDelay
+		}
+	}
+}
+
+// consume processes tasks one at a time until ctx is cancelled. Consumer n
+// idles while the ramped concurrency is n or less.
+func (w *Worker) consume(ctx context.Context, n int) {
+	for ctx.Err() == nil {
+		if err := w.limit.Wait(ctx); err != nil {
+			return
+		}
+		// Consumers above the ramped limit and every consumer during
+		// maintenance wait without taking tasks
+		if n >= int(w.limit.Limit()) || w.maintenance.Get() != nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(idleConsumerDelay):
+			}
+			continue
+		}
+
+		task, err := w.dequeue()
+		if err != nil {
+			log.Printf("Failed to dequeue evaluation task: %v", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(dequeueRetryDelay):
+			}
+			continue
+		}
+		if task == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+
+		w.handle(ctx, task)
+		if err := w.queue.Release(queue.QueueEvaluations, task); err != nil {
+			log.Printf("Failed to release evaluation task %s: %v", task.ID, err)
+		}
+	}
+}
+
+// dequeue takes the next evaluation task. Projects with waiting tasks are
+// tried in weighted round robin order, skipping those at their in-flight
+// cap; tasks queued before queues were split by project come last. It
+// returns nil if no task can be taken now.
+func (w *Worker) dequeue() (*queue.Task, error) {
+	projects, err := w.queue.QueueProjects(queue.QueueEvaluations)
+	if err != nil {
+		return nil, err
+	}
+	for _, projectID := range w.fair.Order(projects) {
+		task, err := w.queue.DequeueProject(queue.QueueEvaluations, projectID, w.fairness.InFlightCap(projectID), w.recoverAfter)
+		if err != nil {
+			return nil, err
+		}
+		if task != nil {
+			w.fair.Charge(projectID, projects)
+			return task, nil
+		}
+	}
+	return w.queue.DequeueUnassigned(queue.QueueEvaluations)
+}
+
+// handle journals, evaluates and completes a dequeued task, retrying it if
+// it fails and queueing it again if it is interrupted
+func (w *Worker) handle(ctx context.Context, task *queue.Task) {
+	journaled, err := w.journal(task)
+	if err != nil {
+		w.retryFailed(task, err)
+		return
+	}
+	if journaled.State == models.TaskStateCompleted {
+		log.Printf("Skipping evaluation task %s: already completed with evaluation %s", task.ID, journaled.EvaluationID)
+		if err := w.queue.MarkTaskCompleted(task, journaled.EvaluationID); err != nil {
+			log.Printf("Failed to update status of task %s: %v", task.ID, err)
+		}
+		return
+	}
+
+	// Status updates are best effort and must not fail the task
+	if err := w.queue.MarkTaskRunning(task); err != nil {
+		log.Printf("Failed to update status of task %s: %v", task.ID, err)
+	}
+
+	evaluationID, err := w.process(ctx, task)
+	if err != nil && ctx.Err() != nil {
+		w.requeue(task)
+		return
+	}
+	if err != nil {
+		w.retryFailed(task, err)
+		return
+	}
+	if err := w.queue.MarkTaskCompleted(task, evaluationID); err != nil {
+		log.Printf("Failed to update status of task %s: %v", task.ID, err)
+	}
+}
+
+// journal records that this worker dequeued a task. It returns the task's
+// completed entry if it was already completed.
+func (w *Worker) journal(task *queue.Task) (*models.TaskJournalEntry, error) {
+	payload, err := json.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task: %w", err)
+	}
+	return w.repo.JournalTaskDequeued(&models.TaskJournalEntry{
+		TaskID:         task.ID,
+		Queue:          queue.QueueEvaluations,
+		TaskType:       task.Type,
+		ConversationID: task.ConversationID,
+		Attempts:       task.Attempts,
+		Payload:        payload,
+		WorkerID:       w.id,
+	})
+}
+
+// retryFailed schedules a retry of a failed task, or dead-letters it once
+// it has used up its attempts
+func (w *Worker) retryFailed(task *queue.Task, taskErr error) {
+	deadLettered, err := w.queue.Retry(queue.QueueEvaluations, task, taskErr, w.retry)
+	switch {
+	case err != nil:
+		// Still journaled as dequeued, so it is recovered later
+		log.Printf("Evaluation task %s for conversation %s failed and could not be retried: %v (%v)", task.ID, task.ConversationID, taskErr, err)
+		if err := w.queue.MarkTaskFailed(task, taskErr); err != nil {
+			log.Printf("Failed to update status of task %s: %v", task.ID, err)
+		}
+		return
+	case deadLettered:
+		log.Printf("Evaluation task %s for conversation %s dead-lettered after %d attempts: %v", task.ID, task.ConversationID, task.Attempts, taskErr)
+		w.transition(task, models.TaskStateDeadLettered, taskErr)
+	default:
+		log.Printf("Evaluation task %s for conversation %s failed (attempt %d of %d), retrying: %v", task.ID, task.ConversationID, task.Attempts, w.retry.MaxAttempts, taskErr)
+		w.transition(task, models.TaskStateRetrying, taskErr)
+	}
+}
+
+// transition records a task leaving the dequeued state without completing.
+// A task whose transition isn't journaled is recovered later, which its
+// journal entry makes harmless.
+func (w *Worker) transition(task *queue.Task, state string, taskErr error) {
+	lastError := ""
+	if taskErr != nil {
+		lastError = taskErr.Error()
+	}
+	if err := w.repo.TransitionTask(task.ID, state, w.id, lastError); err != nil {
+		log.Printf("Failed to journal task %s as %s: %v", task.ID, state, err)
+	}
+}
+
+// recoverTasks queues again, until ctx is cancelled, the tasks whose worker
+// stopped without finishing them
+func (w *Worker) recoverTasks(ctx context.Context) {
+	ticker := time.NewTicker(recoverInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if w.maintenance.Get() != nil {
+				continue
+			}
+			recovered, err := w.repo.RecoverStaleTasks(queue.QueueEvaluations, w.id, w.recoverAfter, func(entry *models.TaskJournalEntry) error {
+				var task queue.Task
+				if err := json.Unmarshal(entry.Payload, &task); err != nil {
+					return fmt.Errorf("failed to parse task %s: %w", entry.TaskID, err)
+				}
+				return w.queue.Enqueue(queue.QueueEvaluations, &task)
+			})
+			if err != nil {
+				log.Printf("Failed to recover evaluation tasks: %v", err)
+			}
+			if recovered > 0 {
+				log.Printf("Recovered %d evaluation tasks left unfinished by stopped workers", recovered)
+			}
+		}
+	}
+}
+
+// promoteDelayed queues retries and scheduled tasks as they fall due, until
+// ctx is cancelled
+func (w *Worker) promoteDelayed(ctx context.Context) {
+	ticker := time.NewTicker(promoteInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.queue.PromoteDelayed(queue.QueueEvaluations); err != nil {
+				log.Printf("Failed to queue delayed evaluation tasks: %v", err)
+			}
+		}
+	}
+}
+
+// requeue puts back a task that was interrupted by shutdown
+func (w *Worker) requeue(task *queue.Task) {
+	if err := w.queue.Enqueue(queue.QueueEvaluations, task); err != nil {
+		log.Printf("Failed to requeue evaluation task %s: %v", task.ID, err)
+		return
+	}
+	w.transition(task, models.TaskStateRequeued, nil)
+	log.Printf("Requeued interrupted evaluation task %s", task.ID)
+}
+
+// process evaluates a task and stores the result, returning the ID of the
+// stored evaluation. If the task was completed meanwhile, the result is
+// dropped and the ID of the evaluation it was completed with is returned.
+func (w *Worker) process(ctx context.Context, task *queue.Task) (string, error) {
+	w.recordStage(task, models.PipelineStageDequeued)
+
+	switch task.Type {
+	case queue.TaskEvaluate, "":
+		req, result, err := w.evaluate(ctx, task)
+		if err != nil {
+			return "", err
+		}
+		if result.EvaluationID == "" {
+			result.EvaluationID = uuid.New().String()
+		}
+		if result.ConversationID == "" {
+			result.ConversationID = task.ConversationID
+		}
+
+		eval, err := services.NewEvaluation(result, task.ID, task.TriggerSource)
+		if err != nil {
+			return "", err
+		}
+		eval.Manifest, err = services.NewEvaluationManifest(services.ManifestSource{
+			Request:    req,
+			Result:     result,
+			Segments:   w.evaluatorSvc.SegmentPolicy(),
+			JudgeModel: w.judgeModel,
+			FromTurnID: task.FromTurnID,
+			Budget:     task.Budget,
+			TimeoutsMS: task.EvaluatorTimeoutsMS,
+		})
+		if err != nil {
+			return "", err
+		}
+		evaluationID, err := w.repo.PersistTaskEvaluation(task.ID, queue.QueueEvaluations, eval)
+		if err != nil {
+			return "", err
+		}
+		if evaluationID != eval.EvaluationID {
+			log.Printf("Dropped duplicate evaluation of task %s: already completed with evaluation %s", task.ID, evaluationID)
+			return evaluationID, nil
+		}
+		w.publishEvaluation(eval)
+		w.dispatchWebhooks(eval)
+		w.notifySlack(eval)
+		return evaluationID, nil
+
+	case queue.TaskCompleteEvaluation:
+		evaluationID, _ := task.Payload["evaluation_id"].(string)
+		if evaluationID == "" {
+			return "", fmt.Errorf("task has no evaluation_id to complete")
+		}
+
+		_, result, err := w.evaluate(ctx, task)
+		if err != nil {
+			return "", err
+		}
+
+		completedID, err := w.repo.CompleteTaskEvaluation(task.ID, queue.QueueEvaluations, evaluationID, task.EvaluatorTypes, result)
+		if err != nil {
+			return "", err
+		}
+		if completedID == "" {
+			return "", fmt.Errorf("evaluation %s no longer exists", evaluationID)
+		}
+		w.recordStage(task, models.PipelineStagePersisted)
+		if eval, err := w.repo.GetEvaluation(completedID); err == nil && eval != nil {
+			w.publishEvaluation(eval)
+			w.dispatchWebhooks(eval)
+			w.notifySlack(eval)
+		}
+		return completedID, nil
+	}
+
+	return "", fmt.Errorf("unknown task type %q", task.Type)
+}
+
+// evaluate sends the task's conversation to the evaluator service within the
+// task's deadlines, returning the request it sent with the result
+func (w *Worker) evaluate(ctx context.Context, task *queue.Task) (*services.EvaluationRequest, *services.EvaluationResult, error) {
+	conv, err := w.repo.GetConversation(task.ConversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if conv == nil {
+		return nil, nil, fmt.Errorf("conversation %s no longer exists", task.ConversationID)
+	}
+
+	req, err := evaluationRequest(conv, task)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	taskCtx, cancel := task.Context(ctx)
+	defer cancel()
+
+	w.recordStage(task, models.PipelineStageEvaluatorStarted)
+	var result *services.EvaluationResult
+	if task.Budget != nil {
+		result, err = w.evaluatorSvc.EvaluateBudgeted(taskCtx, req, task.EvaluatorTimeouts(), task.Budget)
+	} else {
+		result, err = w.evaluatorSvc.EvaluateContext(taskCtx, req, task.EvaluatorTimeouts())
+	}
+	if ctx.Err() == nil {
+		w.recordOutcome(err)
+	}
+	return req, result, err
+}
+
+// recordOutcome feeds an evaluator call's outcome into the ramped concurrency
+func (w *Worker) recordOutcome(err error) {
+	if w.limit == nil {
+		return
+	}
+	if w.limit.Record(err != nil, time.Now()) {
+		log.Printf("Evaluator error rate elevated; cutting concurrency to %d for %s",
+			int(w.limit.Limit()), w.ramp.Cooldown)
+	}
+}
+
+// evaluationRequest builds the evaluator service request for a task,
+// dropping turns before the task's first turn
+func evaluationRequest(conv *models.Conversation, task *queue.Task) (*services.EvaluationRequest, error) {
+	var turns []models.Turn
+	if err := json.Unmarshal(conv.Turns, &turns); err != nil {
+		return nil, fmt.Errorf("failed to parse turns: %w", err)
+	}
+	if task.FromTurnID > 0 {
+		kept := turns[:0]
+		for _, turn := range turns {
+			if turn.TurnID >= task.FromTurnID {
+				kept = append(kept, turn)
+			}
+		}
+		turns = kept
+	}
+
+	metadata := map[string]interface{}{}
+	if len(conv.Metadata) > 0 {
+		if err := json.Unmarshal(conv.Metadata, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to parse metadata: %w", err)
+		}
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+	}
+
+	return &services.EvaluationRequest{
+		ConversationID:    conv.ConversationID,
+		Turns:             services.EvaluatorTurns(turns),
+		Metadata:          metadata,
+		EvaluatorTypes:    task.EvaluatorTypes,
+		EvaluatorVersions: task.EvaluatorVersions,
+	}, nil
+}
+
+// publishEvaluation publishes the activity event and metrics of an
+// evaluation being stored or completed. Both are best effort and must not
+// fail the task.
+func (w *Worker) publishEvaluation(eval *models.Evaluation) {
+	agentVersion, err := w.repo.GetConversationAgentVersion(eval.ConversationID)
+	if err != nil {
+		log.Printf("Failed to look up agent version of %s: %v", eval.ConversationID, err)
+	}
+	w.metrics.Record(eval, agentVersion)
+	if err := w.queue.Publish(queue.ActivityChannel, services.EvaluationActivity(eval, agentVersion)); err != nil {
+		log.Printf("Failed to publish evaluation activity: %v", err)
+	}
+}
+
+// dispatchWebhooks queues deliveries of an evaluation to its project's
+// webhooks. Deliveries are best effort and must not fail the task.
+func (w *Worker) dispatchWebhooks(eval *models.Evaluation) {
+	if err := w.webhooks.Dispatch(eval); err != nil {
+		log.Printf("Failed to dispatch webhooks for evaluation %s: %v", eval.EvaluationID, err)
+	}
+}
+
+// notifySlack posts an evaluation with critical issues to Slack in the
+// background, so a slow or failing Slack doesn't hold up or fail the task
+func (w *Worker) notifySlack(eval *models.Evaluation) {
+	if w.slack == nil {
+		return
+	}
+	go func() {
+		if err := w.slack.NotifyEvaluation(context.Background(), eval); err != nil {
+			log.Printf("Failed to post evaluation %s to Slack: %v", eval.EvaluationID, err)
+		}
+	}()
+}
+
+// recordStage records that a task reached a pipeline stage. Timings are best
+// effort and must not fail the task.
+func (w *Worker) recordStage(task *queue.Task, stage string) {
+	if err := w.repo.RecordPipelineStage(task.ID, stage, time.Now()); err != nil {
+		log.Printf("Failed to record pipeline stage %s for task %s: %v", stage, task.ID, err)
+	}
+}
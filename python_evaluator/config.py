@@ -10,6 +10,7 @@ class Settings(BaseSettings):
     # Service
     service_host: str = "0.0.0.0"
     service_port: int = 8081
+    max_concurrency: int = 0  # Advertised on /health so callers can size dispatch; 0 for unknown
     debug: bool = False
     
     # Database
@@ -10,6 +10,7 @@ from python_evaluator.config import get_settings
 from python_evaluator.evaluators import EvaluationOrchestrator
 from python_evaluator.services.self_improvement import SelfImprovementService
 from python_evaluator.services.meta_evaluation import MetaEvaluationService
+from python_evaluator.services.translation import TranslationService
 
 settings = get_settings()
 
@@ -29,6 +30,7 @@ app.add_middleware(
 
 # Initialize services
 orchestrator = EvaluationOrchestrator()
+translator = TranslationService()
 
 
 class EvaluationRequest(BaseModel):
@@ -51,13 +53,25 @@ class EvaluationResponse(BaseModel):
     evaluation_duration_ms: int
 
 
+class TranslationRequest(BaseModel):
+    """Request model for translation"""
+    texts: List[str]
+    target_language: str
+
+
+class TranslationResponse(BaseModel):
+    """Response model for translation"""
+    translations: List[str]
+
+
 @app.get("/health")
 async def health_check():
     """Health check endpoint"""
     return {
         "status": "healthy",
         "timestamp": datetime.utcnow().isoformat(),
-        "version": "1.0.0"
+        "version": "1.0.0",
+        "max_concurrency": settings.max_concurrency
     }
 
 
@@ -119,6 +133,21 @@ async def calibrate_evaluators(lookback_days: int = Query(30, ge=7, le=180)):
     return result
 
 
+@app.post("/translate", response_model=TranslationResponse)
+async def translate_texts(request: TranslationRequest):
+    """
+    Translate issue and suggestion text with the configured LLM
+    
+    - **texts**: Texts to translate
+    - **target_language**: BCP 47 language tag, such as de or pt-BR
+    """
+    try:
+        translations = await translator.translate(request.texts, request.target_language)
+    except ValueError as e:
+        raise HTTPException(status_code=503, detail=str(e))
+    return TranslationResponse(translations=translations)
+
+
 @app.get("/evaluators")
 async def list_evaluators():
     """List available evaluators"""
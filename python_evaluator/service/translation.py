@@ -0,0 +1,76 @@
+"""Translation service for localizing issue and suggestion text"""
+import json
+import re
+from typing import List
+from openai import AsyncOpenAI
+from anthropic import AsyncAnthropic
+from python_evaluator.config import get_settings
+
+
+class TranslationService:
+    """Translate evaluation text with the configured LLM"""
+
+    def __init__(self):
+        self.settings = get_settings()
+
+        if self.settings.llm_provider == "openai" and self.settings.openai_api_key:
+            self.client = AsyncOpenAI(api_key=self.settings.openai_api_key)
+            self.provider = "openai"
+        elif self.settings.anthropic_api_key:
+            self.client = AsyncAnthropic(api_key=self.settings.anthropic_api_key)
+            self.provider = "anthropic"
+        else:
+            self.client = None
+            self.provider = None
+
+    async def translate(self, texts: List[str], target_language: str) -> List[str]:
+        """
+        Translate texts into target_language, keeping their order
+
+        Raises ValueError if no LLM is configured or the response can't be used
+        """
+        if not texts:
+            return []
+        if not self.client:
+            raise ValueError("No LLM provider configured")
+
+        prompt = f"""Translate each text in the JSON array below into the language with BCP 47 tag "{target_language}".
+Keep tool names, identifiers and numbers unchanged.
+
+Texts:
+{json.dumps(texts, ensure_ascii=False)}
+
+Respond with a JSON object of the form {{"translations": ["..."]}} with exactly {len(texts)} translations, in the same order."""
+
+        if self.provider == "openai":
+            response = await self.client.chat.completions.create(
+                model=self.settings.llm_model,
+                messages=[
+                    {"role": "system", "content": "You are a professional translator. Always respond in valid JSON format."},
+                    {"role": "user", "content": prompt}
+                ],
+                temperature=0,
+                response_format={"type": "json_object"}
+            )
+            content = response.choices[0].message.content
+        else:
+            response = await self.client.messages.create(
+                model=self.settings.llm_model,
+                max_tokens=4000,
+                temperature=0,
+                messages=[{"role": "user", "content": prompt}]
+            )
+            content = response.content[0].text
+
+        try:
+            result = json.loads(content)
+        except json.JSONDecodeError:
+            json_match = re.search(r'\{.*\}', content, re.DOTALL)
+            if not json_match:
+                raise ValueError("Could not parse LLM response as JSON")
+            result = json.loads(json_match.group())
+
+        translations = result.get("translations")
+        if not isinstance(translations, list) or len(translations) != len(texts):
+            raise ValueError("LLM returned the wrong number of translations")
+        return [str(t) for t in translations]